@@ -1,18 +1,38 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
-
+	"text/template"
+	"time"
+
+	"github.com/1broseidon/promptext/internal/cache"
+	"github.com/1broseidon/promptext/internal/clipboard"
+	"github.com/1broseidon/promptext/internal/config"
+	"github.com/1broseidon/promptext/internal/gha"
+	"github.com/1broseidon/promptext/internal/help"
+	"github.com/1broseidon/promptext/internal/hook"
+	"github.com/1broseidon/promptext/internal/httpapi"
 	"github.com/1broseidon/promptext/internal/initializer"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/longpath"
+	"github.com/1broseidon/promptext/internal/mcp"
 	"github.com/1broseidon/promptext/internal/processor"
+	"github.com/1broseidon/promptext/internal/token"
 	"github.com/1broseidon/promptext/internal/update"
+	"github.com/1broseidon/promptext/internal/upload"
 	"github.com/1broseidon/promptext/pkg/promptext"
-	"github.com/atotto/clipboard"
+	"github.com/1broseidon/promptext/pkg/promptext/prompts"
 	"github.com/spf13/pflag"
 )
 
@@ -29,151 +49,555 @@ func customUsage() {
 	customUsageWithWriter(os.Stdout)
 }
 
+// customUsageWithWriter prints the whole help: every section of every topic
 func customUsageWithWriter(w io.Writer) {
-	fmt.Fprintf(w, `promptext %s - Smart code context extractor for AI assistants
-
-USAGE:
-    prx [OPTIONS] [DIRECTORY]
-    promptext [OPTIONS] [DIRECTORY]
-
-DESCRIPTION:
-    promptext analyzes your codebase, filters relevant files, estimates token 
-    usage using tiktoken (GPT-3.5/4 compatible), and provides formatted output 
-    suitable for AI prompts. Output is automatically copied to clipboard unless 
-    disabled with --no-copy.
-
-INPUT OPTIONS:
-    -d, --directory DIR        Directory to process (default: current directory)
-    -e, --extension LIST       File extensions to include, comma-separated
-                               Examples: .go  or  .go,.js,.ts,.py
-    -g, --gitignore           Use .gitignore patterns for filtering (default: true)
-    -u, --use-default-rules   Use built-in filtering rules for common files (default: true)
+	fmt.Fprintf(w, "promptext %s - %s\n\n", version, helpSummary)
+	help.Write(w, helpPage()...)
+	fmt.Fprintf(w, "VERSION: %s (%s)\nHOME:    %s\nDOCS:    %s\n\n", version, date, homeURL, docsURL)
+}
+
+// helpSummary is what promptext does, in a line
+const helpSummary = "Smart code context extractor for AI assistants"
+
+const (
+	homeURL = "https://github.com/1broseidon/promptext"
+	docsURL = "https://1broseidon.github.io/promptext/"
+)
+
+// The sections of the help, in the order --help prints them by helpPage.
+// prx help TOPIC prints those of one topic, and prx man all of them.
+var (
+	usageHelp = help.Section{
+		Title: "USAGE",
+		Text: `
+	prx [OPTIONS] [DIRECTORY]
+	promptext [OPTIONS] [DIRECTORY]
+	prx COMMAND [ARGS]
+	prx help [TOPIC]`,
+	}
+	descriptionHelp = help.Section{
+		Title: "DESCRIPTION",
+		Text: `promptext analyzes your codebase, filters relevant files, estimates token
+usage using tiktoken (GPT-3.5/4 compatible), and provides formatted output
+suitable for AI prompts. Output is automatically copied to clipboard unless
+disabled with --no-copy.`,
+	}
+	inputHelp = help.Section{
+		Title: "INPUT OPTIONS",
+		Entries: []help.Entry{
+			{Name: "-d, --directory DIR", Desc: "Directory to process (default: current directory)\n" +
+				"Comma-separate several to combine them: -d services/auth,libs/shared\n" +
+				"A .zip, .tar, .tar.gz or .tgz file is read without unpacking it"},
+			{Name: "--ref REF", Desc: "Read files as committed at a git branch, tag, or commit instead of the " +
+				"working tree, e.g. --ref v1.2.0 (uncommitted changes are left out)"},
+			{Name: "-e, --extension LIST", Desc: "File extensions to include, comma-separated\n" +
+				"Examples: .go or .go,.js,.ts,.py"},
+			{Name: "-g, --gitignore", Desc: "Use .gitignore patterns for filtering (default: true)"},
+			{Name: "-u, --use-default-rules", Desc: "Use built-in filtering rules for common files (default: true)"},
+		},
+	}
+	filteringHelp = help.Section{
+		Title: "FILTERING OPTIONS",
+		Entries: []help.Entry{
+			{Name: "-x, --exclude LIST", Desc: "Patterns to exclude, comma-separated\n" +
+				"Examples: vendor/,node_modules/ or *.test.go,dist/"},
+			{Name: "--include LIST", Desc: "Path globs to include, comma-separated (gitignore syntax)\n" +
+				"Examples: internal/processor/** or cmd/*/main.go,*.proto\n" +
+				"Excludes and .gitignore still apply to included paths"},
+			{Name: "--files-from FILE", Desc: "Process exactly the paths listed in FILE, one per line, instead of " +
+				"walking the directory (\"-\" reads stdin); filters still apply"},
+			{Name: "--exclude-content RE", Desc: "Skip files whose content matches a regular expression (repeatable)"},
+			{Name: "--extra-file PATH", Desc: "Also include a file from outside the directory (repeatable), such as " +
+				"../shared/api.proto; listed under (external) and marked in the manifest"},
+			{Name: "--symlinks POLICY", Desc: "What to do with symlinks: skip (default), follow, or follow-in-root " +
+				"(only links pointing inside the directory); links that loop back are " +
+				"never followed. --dry-run lists the links left out"},
+			{Name: "--max-file-size SIZE", Desc: "Skip files larger than SIZE, such as 1MB or 512KB, as " +
+				"too large (default and maximum: 10MB)"},
+			{Name: "--max-total-size SIZE", Desc: "Stop before reading anything when the files to read total more than " +
+				"SIZE, naming the largest (default: 200MB; 0 for no limit)"},
+			{Name: "--skip-generated", Desc: "Skip generated and minified files detected by content (default: true)\n" +
+				"Matches \"Code generated ... DO NOT EDIT\", protoc and @generated headers"},
+			{Name: "--skipped-stubs", Desc: "List skipped binary, oversized and generated files (path, size, " +
+				"kind) in the output so the AI knows they exist"},
+		},
+	}
+	outputHelp = help.Section{
+		Title: "OUTPUT OPTIONS",
+		Entries: []help.Entry{
+			{Name: "-f, --format FORMAT", Desc: "Output format (default: ptx; see FORMATS)"},
+			{Name: "--template FILE", Desc: "Render the output through a Go text/template file, with the project as dot " +
+				"({{range .Files}}{{.Path}}{{end}}) and the helpers tokens TEXT, wrapCode " +
+				"PATH CONTENT and rel BASE PATH; implies --format template"},
+			{Name: "--toc", Desc: "Markdown: start with a table of contents linking to each file"},
+			{Name: "--collapse N", Desc: "Markdown: fold files longer than N lines into collapsible <details> blocks"},
+			{Name: "-o, --output FILE", Desc: "Write output to file instead of clipboard; a name ending " +
+				"in .gz, such as context.ptx.gz, gzips it"},
+			{Name: "--gzip", Desc: "Gzip the output (requires --output unless --base64 is given)"},
+			{Name: "--base64", Desc: "Armor the output in base64, for systems that mangle unicode or " +
+				"binary data; with --gzip, decode with base64 -d | gunzip"},
+			{Name: "--split N", Desc: "Split output into FILE-part1, FILE-part2, ... of at most N tokens each; " +
+				"every part repeats the manifest and lists the other parts"},
+			{Name: "--deterministic", Desc: "Render unchanged files to byte-identical output, leaving out the git branch, " +
+				"commit and message (default: on with --output, off otherwise)"},
+			{Name: "--hashes", Desc: "Add each file's SHA-256 and a content hash to the PTX, JSONL, JSON or XML " +
+				"manifest, so prx verify can tell which files changed since"},
+			{Name: "-n, --no-copy", Desc: "Don't copy output to clipboard"},
+			{Name: "--clipboard-backend NAME", Desc: "How to copy: auto (default), system, wl-copy, xclip, xsel, or osc52 " +
+				"(terminal escape sequence; works over SSH). auto uses osc52 in SSH " +
+				"sessions without a display and falls back to it when others fail"},
+			{Name: "--clipboard-max-bytes N", Desc: "Write output larger than N bytes to a temp file and print its path " +
+				"instead of copying (default 2097152); 0 copies any size"},
+			{Name: "-i, --info", Desc: "Show only project summary (no file contents)"},
+			{Name: "--tree", Desc: "Print only the directory tree, with file counts and token totals per " +
+				"directory, to choose subtrees before extracting (ignores budgets)"},
+			{Name: "--verbose", Desc: "Display full content in terminal"},
+			{Name: "--prompt NAME", Desc: "Wrap the output in a prompt template: code-review, refactor, document, " +
+				"test-generation, migration, or NAME from .promptext/prompts/NAME.tmpl"},
+			{Name: "--prompt-var K=V", Desc: "Set a template variable (repeatable), e.g. focus=security for " +
+				"code-review or from=python2,to=python3 for migration"},
+		},
+	}
+	processingHelp = help.Section{
+		Title: "PROCESSING OPTIONS",
+		Entries: []help.Entry{
+			{Name: "--dry-run", Desc: "Preview files that would be processed without reading content"},
+			{Name: "--no-cache", Desc: "Don't reuse or update the file cache, which is on by default"},
+			{Name: "-j, --jobs N", Desc: "Files to read and tokenize in parallel (default: one per CPU)"},
+			{Name: "--progress", Desc: "Show a progress bar on stderr (files scanned, files " +
+				"processed, tokens counted); silent with --quiet"},
+			{Name: "--profile NAME", Desc: "Apply a named profile from .promptext.yml (see CONFIGURATION)"},
+			{Name: "--redact", Desc: "Replace secrets (API keys, tokens, passwords) with placeholders"},
+			{Name: "--strip-comments", Desc: "Remove comments from source (language-aware; strings are kept)"},
+			{Name: "--squash-blank-lines", Desc: "Collapse runs of blank lines into one"},
+			{Name: "--notebook-outputs", Desc: "Keep cell outputs (text only) when flattening Jupyter notebooks, which " +
+				"are otherwise reduced to their code and markdown cells"},
+			{Name: "--summarize-lockfiles", Desc: "Replace lockfiles and minified JS/CSS that get past the filters with " +
+				"summaries (package counts, direct dependency versions; default: true)"},
+			{Name: "--sample-rows N", Desc: "Keep only the header and first N rows of CSV, TSV and JSON Lines " +
+				"files; sampled files are marked with their total row count"},
+			{Name: "--owners", Desc: "Attach CODEOWNERS owners (GitHub or GitLab format) to each file in the " +
+				"manifest and count files and tokens per owner in the metadata"},
+			{Name: "--todos", Desc: "List the TODO, FIXME and HACK comments of the included files (path, " +
+				"line, text) in an issues section, for tech-debt review"},
+			{Name: "--license-scan", Desc: "Inventory LICENSE files, SPDX headers and copyright notices in the metadata, flagging " +
+				"source files whose header is missing or conflicts with their LICENSE file"},
+			{Name: "--metrics", Desc: "Add a metrics section with each source file's lines, functions and cyclomatic complexity " +
+				"(exact for Go, estimated otherwise), listing the top complexity hotspots"},
+			{Name: "--audit-deps", Desc: "Look the manifest's dependencies pinned to exact versions up in the OSV database " +
+				"(api.osv.dev, or $PROMPTEXT_OSV_URL) and list their known vulnerabilities in the " +
+				"metadata; this sends dependency names and versions over the network"},
+			{Name: "--audit-report FILE", Desc: "List the vulnerabilities of osv-scanner JSON report FILE instead of " +
+				"querying OSV (implies --audit-deps; works offline)"},
+			{Name: "--package NAME", Desc: "In a workspace (go.work, pnpm-workspace.yaml, package.json workspaces, " +
+				"Cargo workspace, Nx), extract only member NAME (by name, directory or last " +
+				"path element) and the members it depends on, transitively"},
+			{Name: "--transform-cmd CMD", Desc: "Pipe each file's content through shell command CMD and use its output, before " +
+				"the other transforms; the file's path is in $PROMPTEXT_FILE.\n" +
+				"A failing command stops the run; the file cache is not used"},
+			{Name: "--compact[=indent]", Desc: "Trim trailing whitespace and collapse blank lines; =indent also shrinks indentation to one " +
+				"space per level. Compacted files are marked in the manifest as not byte-exact"},
+			{Name: "-q, --quiet", Desc: "Suppress non-essential output for scripting"},
+			{Name: "--summary-json[=FILE]", Desc: "Write a JSON run summary (files, tokens, budget, format, output, " +
+				"timing) to FILE, or to stdout in place of the status line"},
+			{Name: "--stats[=json]", Desc: "Print the included files' tokens by top-level directory and by " +
+				"extension, with percentages, in place of the output (-o writes it to a " +
+				"file); =json prints it as JSON. Same as prx stats [--json] [OPTIONS]"},
+			{Name: "--fail-over-tokens N", Desc: "Exit with status 3 if the output exceeds N tokens (for CI)"},
+			{Name: "--fail-if-empty", Desc: "Exit with status 4 if no files match (for CI)"},
+		},
+	}
+	relevanceHelp = help.Section{
+		Title: "RELEVANCE & TOKEN BUDGET",
+		Entries: []help.Entry{
+			{Name: "-r, --relevant KEYWORDS", Desc: "Filter and prioritize files by keyword relevance (comma or space separated)\n" +
+				"Automatically excludes files with no keyword matches\n" +
+				"Negate a keyword to drop files whose path has it (\"auth -test " +
+				"-mock\"); mentions in content lower a file's score\n" +
+				"Scoring weights: filename (10x), directory (5x), imports (3x), content (1x)\n" +
+				"Keywords also match their stems and synonyms (\"auth\" finds login.go) at half " +
+				"weight; add synonyms under relevance.synonyms in .promptext.yml"},
+			{Name: "--exact", Desc: "Match --relevant keywords as given, without stemming or synonyms"},
+			{Name: "--boost-recent", Desc: "Rank files changed recently and often in git higher (last 300 " +
+				"commits); with --relevant only matching files are boosted"},
+			{Name: "--snippets", Desc: "Cut files matched by --relevant down to the functions, types and " +
+				"classes whose name contains a keyword (Go, JS/TS, Python, Rust, Java, " +
+				"C#, ...); files are marked as partial in the manifest"},
+			{Name: "--snippet-context N", Desc: "Lines kept around each --snippets definition (default: 3)"},
+			{Name: "--include-tests MODE", Desc: "With \"paired\", also include the tests of files --relevant selects (foo.go " +
+				"-> foo_test.go, x.ts -> x.spec.ts), ranked right behind them"},
+			{Name: "--follow-imports N", Desc: "Also include files imported by highly relevant files, up to N hops (Go, " +
+				"JS/TS, Python), ranked ahead of weaker keyword matches"},
+			{Name: "--explain-selection", Desc: "Add a selection report to the output: every candidate file in priority order " +
+				"with its score, score factors, and why it was included or excluded"},
+			{Name: "--max-tokens NUMBER", Desc: "Maximum token budget for output (excludes lower-priority files when exceeded)\n" +
+				"Combines with --relevant to include highest-scoring files within budget"},
+			{Name: "--budget-weight K=F", Desc: "Reserve fraction F of --max-tokens for directory or extension K " +
+				"(repeatable), e.g. --budget-weight internal/=0.6 --budget-weight .md=0.1"},
+			{Name: "--tokenizer NAME", Desc: "Token counting for budgets: cl100k (default), o200k, claude, chars, or a local model: " +
+				"ollama:MODEL (server at OLLAMA_HOST) or lmstudio:MODEL (server at LMSTUDIO_HOST)"},
+			{Name: "--model NAME", Desc: "Budget for a model's context window (claude-sonnet-4, gpt-4o, gemini-2.5-pro, ...)\n" +
+				"Sets --max-tokens and --tokenizer unless given; warns if output won't fit"},
+			{Name: "--reserve-tokens N", Desc: "Tokens of the --model window kept for the response (default: 1/4, max 8192)"},
+		},
+	}
+	debugHelp = help.Section{
+		Title: "DEBUG OPTIONS",
+		Entries: []help.Entry{
+			{Name: "-D, --debug", Desc: "Enable debug logging and timing information"},
+			{Name: "--log-level LEVEL", Desc: "Lowest level logged to stderr: error, warn (default), info, " +
+				"or debug; -D logs everything and adds timing"},
+			{Name: "--log-format FORMAT", Desc: "Log lines as text (default) or json, one object per line"},
+			{Name: "-h, --help", Desc: "Show this help message"},
+			{Name: "-v, --version", Desc: "Show version information"},
+		},
+	}
+	updateHelp = help.Section{
+		Title: "UPDATE OPTIONS",
+		Entries: []help.Entry{
+			{Name: "--update", Desc: "Update promptext to the latest version from GitHub"},
+			{Name: "--check-update", Desc: "Check if a new version is available without updating"},
+		},
+	}
+	schemaHelp = help.Section{
+		Title: "SCHEMA",
+		Entries: []help.Entry{
+			{Name: "schema --format json", Desc: "Print the JSON Schema for --format json output"},
+			{Name: "schema --format jsonl", Desc: "Print the JSON Schema each line of --format jsonl output follows"},
+			{Name: "schema --format xml", Desc: "Print the XML Schema (XSD) for --format xml output"},
+		},
+	}
+	verifyHelp = help.Section{
+		Title: "VERIFY",
+		Entries: []help.Entry{
+			{Name: "verify CONTEXT", Desc: "Re-hash the files a context written with --hashes lists and report which changed or " +
+				"went missing, e.g. prx verify context.ptx [-d DIR]; exits 1 if any did"},
+		},
+	}
+	hooksHelp = help.Section{
+		Title: "HOOKS",
+		Entries: []help.Entry{
+			{Name: "hook install [HOOK]", Desc: "Install the git pre-commit or pre-push hook (default: the hooks under hooks: " +
+				"in .promptext.yml, or pre-commit); --force replaces another hook"},
+			{Name: "hook uninstall [HOOK]", Desc: "Remove the hooks promptext installed"},
+			{Name: "hook run HOOK", Desc: "Check the staged or pushed changes as the hook does: secrets in the changed " +
+				"files, and the max-tokens and max-growth settings of the hook"},
+		},
+	}
+	completionHelp = help.Section{
+		Title: "COMPLETION",
+		Entries: []help.Entry{
+			{Name: "completion SHELL", Desc: "Print the bash, zsh, fish or powershell completion script, which " +
+				"completes flags, subcommands, formats, profiles and prompts, e.g. " +
+				"source <(prx completion bash) in ~/.bashrc"},
+		},
+	}
+	planHelp = help.Section{
+		Title: "PLAN",
+		Entries: []help.Entry{
+			{Name: "plan --max-tokens N", Desc: "Suggest excludes (tests, test data, docs, data files, whole directories " +
+				"without an entry point) that bring the output under N tokens, e.g. prx plan " +
+				"--max-tokens 50000 (or --model; takes the usual options)"},
+			{Name: "--apply", Desc: "Add the suggested excludes to .promptext.yml"},
+		},
+	}
+	explainHelp = help.Section{
+		Title: "EXPLAIN",
+		Entries: []help.Entry{
+			{Name: "explain FILE...", Desc: "Show which rule includes or excludes each file, e.g. prx explain " +
+				"-e .go web/dist/app.js (takes the usual options)"},
+		},
+	}
+	compareHelp = help.Section{
+		Title: "COMPARE",
+		Entries: []help.Entry{
+			{Name: "compare --from REF", Desc: "Report the files added, removed and modified since REF, with token " +
+				"deltas, e.g. prx compare --from v1.0.0 --to v1.1.0 (takes the usual " +
+				"options). REF is a git branch, tag, or commit, or a directory"},
+			{Name: "--to REF", Desc: "The other side (default: the working tree)"},
+			{Name: "--diff", Desc: "Include unified diffs of the changed files\n" +
+				"The report is PTX or Markdown (-f markdown), printed or written to -o"},
+		},
+	}
+	askHelp = help.Section{
+		Title: "ASK",
+		Entries: []help.Entry{
+			{Name: "ask QUESTION", Desc: "Answer a question about the code with a language model, e.g. prx ask " +
+				"\"where is auth handled?\" (takes the usual options)\n" +
+				"Files are chosen by keywords from the question within 32k tokens " +
+				"unless --relevant, --max-tokens or --model say otherwise\n" +
+				"Provider: PROMPTEXT_LLM_PROVIDER=openai|anthropic|ollama, or the first of " +
+				"ANTHROPIC_API_KEY, OPENAI_API_KEY, OLLAMA_HOST that is set\n" +
+				"PROMPTEXT_LLM_MODEL picks the model; keys are never stored"},
+		},
+	}
+	pushHelp = help.Section{
+		Title: "PUSH",
+		Entries: []help.Entry{
+			{Name: "push", Desc: "Upload the output to a files API and print the file id, so prompts " +
+				"can reference it instead of pasting the text, e.g. prx push -r auth " +
+				"(takes the usual options; -o also writes a file)"},
+			{Name: "--provider NAME", Desc: "anthropic or openai (default: the first of ANTHROPIC_API_KEY and OPENAI_API_KEY that " +
+				"is set); ANTHROPIC_BASE_URL and OPENAI_BASE_URL change the endpoint"},
+		},
+	}
+	ghaHelp = help.Section{
+		Title: "GITHUB ACTIONS",
+		Entries: []help.Entry{
+			{Name: "gha", Desc: "Extract the files the pull request changes (the whole directory for other events) to -o " +
+				"(default: promptext-context.EXT), and report them in the job summary and step outputs; " +
+				"takes the usual options, with --fail-over-tokens as the budget"},
+			{Name: "--comment", Desc: "Also post the summary on the pull request (needs GITHUB_TOKEN)"},
+		},
+	}
+	configHelp = help.Section{
+		Title: "CONFIG",
+		Entries: []help.Entry{
+			{Name: "config lint [PATH]", Desc: "Check .promptext.yml for unknown keys and invalid values\n" +
+				"PATH is a config file or project directory (default: .)"},
+			{Name: "config init --global", Desc: "Create the global config (~/.config/promptext/config.yml)"},
+			{Name: "config get [KEY]", Desc: "Print a setting as the project sees it, or every setting and where " +
+				"it comes from, built-in defaults included; --global reads the global config alone"},
+			{Name: "config set KEY VALUE", Desc: "Change a setting in ./.promptext.yml, or the global config with " +
+				"--global; lists are comma separated (prx config set format markdown " +
+				"--global). Flags > project config > global config"},
+		},
+	}
+	serveHelp = help.Section{
+		Title: "SERVER",
+		Entries: []help.Entry{
+			{Name: "serve --mcp", Desc: "Run as an MCP server over stdio (prx serve --mcp [-d DIR])\n" +
+				"Tools: extract_context, search_relevant_files, project_info"},
+			{Name: "serve --http ADDR", Desc: "Run the REST API (prx serve --http :8080 [-d DIR])\n" +
+				"Endpoints: POST /extract, GET /info, GET /formats"},
+		},
+	}
+	initHelp = help.Section{
+		Title: "INITIALIZATION OPTIONS",
+		Entries: []help.Entry{
+			{Name: "--init", Desc: "Initialize a new .promptext.yml config file with smart defaults\n" +
+				"Detects project type and suggests framework-specific settings"},
+			{Name: "--force", Desc: "Force overwrite of existing config (use with --init)"},
+			{Name: "-y, --yes", Desc: "With --init, don't prompt: write the config from the detected project " +
+				"types and these answers (an existing config needs --force)"},
+			{Name: "--exclude-tests", Desc: "With --init --yes, exclude test files (default: true)\n" +
+				"-f, -e and -x set the config's format, extensions and extra excludes"},
+			{Name: "--update", Desc: "With --init, add the extensions and excludes now suggested for the " +
+				"detected project types to the existing config, keeping its other settings " +
+				"and comments; shows a diff and asks first (skip with --yes)"},
+		},
+	}
+	helpHelp = help.Section{
+		Title: "HELP",
+		Entries: []help.Entry{
+			{Name: "help [TOPIC]", Desc: "Show one part of this help, e.g. prx help filtering; prx help alone lists the topics"},
+			{Name: "man", Desc: "Print this help as a man page, e.g. prx man > ~/.local/share/man/man1/promptext.1"},
+		},
+	}
+	cacheHelp = help.Section{
+		Title: "CACHE",
+		Text: `The cache is on by default: processed files are kept in the user cache
+directory (such as ~/.cache/promptext on Linux), one cache per project, so
+unchanged files are not re-read or re-tokenized on the next run. The cache
+holds copies of file contents; --no-cache leaves it alone.`,
+		Entries: []help.Entry{
+			{Name: "cache clear", Desc: "Remove the project's cache (prx cache clear [-d DIR])"},
+		},
+	}
+	examplesHelp = help.Section{
+		Title: "EXAMPLES",
+		Text: `
+	# Basic usage - process current directory, copy to clipboard
+	prx
+
+	# Process specific project with Go files only
+	prx -d /path/to/project -e .go
 
-FILTERING OPTIONS:
-    -x, --exclude LIST        Patterns to exclude, comma-separated
-                              Examples: vendor/,node_modules/  or  *.test.go,dist/
+	# Quick project overview without file contents
+	prx -i
 
-OUTPUT OPTIONS:
-    -f, --format FORMAT       Output format (default: ptx)
-                              • ptx, toon: PTX v2.0 format with enhanced manifest (TOON-based) [default]
-                              • jsonl: Machine-friendly JSONL (one JSON object per line)
-                              • toon-strict: TOON v1.3 strict compliance (escaped strings)
-                              • markdown, md: Human-readable markdown
-                              • xml: Machine-parseable XML
-    -o, --output FILE         Write output to file instead of clipboard
-    -n, --no-copy            Don't copy output to clipboard
-    -i, --info               Show only project summary (no file contents)
-        --verbose            Display full content in terminal
-
-PROCESSING OPTIONS:
-        --dry-run            Preview files that would be processed without reading content
-    -q, --quiet              Suppress non-essential output for scripting
-
-RELEVANCE & TOKEN BUDGET:
-    -r, --relevant KEYWORDS  Filter and prioritize files by keyword relevance (comma or space separated)
-                             Automatically excludes files with no keyword matches
-                             Scoring weights: filename (10x), directory (5x), imports (3x), content (1x)
-        --max-tokens NUMBER  Maximum token budget for output (excludes lower-priority files when exceeded)
-                             Combines with --relevant to include highest-scoring files within budget
+	# Combine monorepo slices into one output, paths namespaced per root
+	prx -d services/auth,services/billing,libs/shared -e .go
 
-DEBUG OPTIONS:
-    -D, --debug              Enable debug logging and timing information
-    -h, --help               Show this help message
-    -v, --version            Show version information
+	# Read a codebase received as an archive, without unpacking it
+	prx client-project.zip
 
-UPDATE OPTIONS:
-        --update             Update promptext to the latest version from GitHub
-        --check-update       Check if a new version is available without updating
+	# Export specific file types to XML with debug info
+	prx -e .js,.ts,.json -f xml -o project.xml -D
 
-INITIALIZATION OPTIONS:
-        --init               Initialize a new .promptext.yml config file with smart defaults
-                             Detects project type and suggests framework-specific settings
-        --force              Force overwrite of existing config (use with --init)
+	# Use PTX v2.0 format for AI-optimized structure with enhanced manifest
+	prx -f ptx -o project.ptx
 
-EXAMPLES:
-    # Basic usage - process current directory, copy to clipboard
-    prx
+	# Use JSONL for machine-friendly processing and pipelines
+	prx -f jsonl -o project.jsonl
 
-    # Process specific project with Go files only
-    prx -d /path/to/project -e .go
+	# Use strict TOON v1.3 for maximum token compression
+	prx -f toon-strict -o project.toon
 
-    # Quick project overview without file contents
-    prx -i
+	# Split a large project into parts that each fit a 100k-token context
+	prx --split 100000 -o context.ptx
 
-    # Export specific file types to XML with debug info
-    prx -e .js,.ts,.json -f xml -o project.xml -D
+	# Share a browsable HTML page with teammates
+	prx -o review.html
 
-    # Use PTX v2.0 format for AI-optimized structure with enhanced manifest
-    prx -f ptx -o project.ptx
+	# Process with custom exclusions and see output in terminal
+	prx -x "vendor/,*.test.go,dist/" -v
 
-    # Use JSONL for machine-friendly processing and pipelines
-    prx -f jsonl -o project.jsonl
+	# Only the processor package and command entry points
+	prx --include "internal/processor/**,cmd/*/main.go"
 
-    # Use strict TOON v1.3 for maximum token compression
-    prx -f toon-strict -o project.toon
+	# Only the files changed on this branch
+	git diff --name-only main | prx --files-from -
 
-    # Process with custom exclusions and see output in terminal
-    prx -x "vendor/,*.test.go,dist/" -v
+	# Analyze without using .gitignore patterns
+	prx -g=false -x "node_modules/,target/,build/"
 
-    # Analyze without using .gitignore patterns
-    prx -g=false -x "node_modules/,target/,build/"
+	# Full analysis with debug logging for performance tuning
+	prx -D -v -x "test/,spec/,__tests__/"
 
-    # Full analysis with debug logging for performance tuning
-    prx -D -v -x "test/,spec/,__tests__/"
+	# Preview files that would be processed without reading them
+	prx --dry-run -e .go,.js
 
-    # Preview files that would be processed without reading them
-    prx --dry-run -e .go,.js
+	# Quiet mode for use in scripts (minimal output)
+	prx -q -f xml -o output.xml
 
-    # Quiet mode for use in scripts (minimal output)
-    prx -q -f xml -o output.xml
+	# Auto-detect format from output file extension
+	prx -o context.ptx                     # Automatically uses PTX format
+	prx -o context.toon                    # Automatically uses PTX format (backward compat)
+	prx -o context.md                      # Automatically uses markdown format
 
-    # Auto-detect format from output file extension
-    prx -o context.ptx                     # Automatically uses PTX format
-    prx -o context.toon                    # Automatically uses PTX format (backward compat)
-    prx -o context.md                      # Automatically uses markdown format
+	# Filter to only authentication-related files
+	prx --relevant "auth login OAuth"
 
-    # Filter to only authentication-related files
-    prx --relevant "auth login OAuth"
+	# Authentication files plus the packages they import
+	prx --relevant auth --follow-imports 1
 
-    # Filter to database files, limit to 8000 tokens
-    prx --relevant "database" --max-tokens 8000
+	# Filter to database files, limit to 8000 tokens
+	prx --relevant "database" --max-tokens 8000
 
-    # Filter to API files, limit to top 5000 tokens worth
-    prx -r "api routes handlers" --max-tokens 5000 -o api-context.toon
+	# Filter to API files, limit to top 5000 tokens worth
+	prx -r "api routes handlers" --max-tokens 5000 -o api-context.toon
 
-    # Check for updates and install latest version
-    prx --check-update                         # Check only
-    prx --update                               # Update to latest version
+	# Count the budget with the tokenizer of the target model
+	prx --max-tokens 100000 --tokenizer claude
 
-    # Initialize config file with smart defaults based on project type
-    prx --init                                 # Interactive mode
-    prx --init --force                         # Overwrite existing config
+	# Count with a model served by a local Ollama
+	prx --max-tokens 8000 --tokenizer ollama:llama3
 
-CONFIGURATION:
-    Create a .promptext.yml file in your project root for persistent settings:
+	# Fill a model's context window, keeping 16K tokens for the answer
+	prx -r "auth" --model claude-sonnet-4 --reserve-tokens 16000
 
-    extensions:
-      - .go
-      - .js
-      - .py
-    excludes:
-      - vendor/
-      - node_modules/
-    format: toon
-    verbose: false
+	# Check for updates and install latest version
+	prx --check-update                         # Check only
+	prx --update                               # Update to latest version
+
+	# Initialize config file with smart defaults based on project type
+	prx --init                                 # Interactive mode
+	prx --init --force                         # Overwrite existing config
+	prx --init --yes --exclude-tests=false -f jsonl -e .go,.proto  # Non-interactive, for scripts
+	prx --init --update                        # Merge new suggestions into .promptext.yml`,
+	}
+	configurationHelp = help.Section{
+		Title: "CONFIGURATION",
+		Text: `Create a .promptext.yml file in your project root for persistent settings:
+
+	extensions:
+	  - .go
+	  - .js
+	  - .py
+	excludes:
+	  - vendor/
+	  - node_modules/
+	format: toon
+	verbose: false
+	profiles:
+	  docs:
+	    extensions: [.md]
+	  review:
+	    excludes: [testdata/]
+	    format: markdown
+
+CLI flags override configuration file settings; --profile NAME applies
+a profile on top of the top-level settings.`,
+	}
+	tokensHelp = help.Section{
+		Title: "TOKEN ESTIMATION",
+		Text: `Token counts are estimated using tiktoken (GPT-3.5/GPT-4 compatible) to help
+you understand context window usage. Use --info to see token estimates without
+full file contents.`,
+	}
+)
 
-    CLI flags override configuration file settings.
+// formatHelpNotes add to the descriptions of formats in the help
+var formatHelpNotes = map[string]string{
+	"json": "see prx schema --format json",
+	"pdf":  "requires --output",
+}
 
-TOKEN ESTIMATION:
-    Token counts are estimated using tiktoken (GPT-3.5/GPT-4 compatible) to help
-    you understand context window usage. Use --info to see token estimates without
-    full file contents.
+// formatsHelp lists the output formats, registered ones included
+func formatsHelp() help.Section {
+	section := help.Section{
+		Title: "FORMATS",
+		Text: `-f picks the format; without it, -o picks the format by the file's extension.
+prx formats lists the formats with their file extensions and MIME types.`,
+	}
+	for _, info := range promptext.Formatters() {
+		desc := info.Description
+		if !info.BuiltIn {
+			desc = strings.TrimSpace("[registered] " + desc)
+		}
+		if note := formatHelpNotes[info.Name]; note != "" {
+			desc += "; " + note
+		}
+		section.Entries = append(section.Entries, help.Entry{Name: strings.Join(append([]string{info.Name}, info.Aliases...), ", "), Desc: desc})
+	}
+	section.Entries = append(section.Entries, help.Entry{Name: "template", Desc: "Your own Go text/template (requires --template)"})
+	return section
+}
 
-VERSION: %s (%s)
-HOME:    https://github.com/1broseidon/promptext
-DOCS:    https://1broseidon.github.io/promptext/
+// helpPage returns every section of the help, in order
+func helpPage() []help.Section {
+	return []help.Section{
+		usageHelp, descriptionHelp, inputHelp, filteringHelp, outputHelp, formatsHelp(), processingHelp,
+		relevanceHelp, debugHelp, updateHelp, helpHelp, cacheHelp, schemaHelp, verifyHelp, hooksHelp,
+		completionHelp, planHelp, explainHelp, compareHelp, askHelp, pushHelp, ghaHelp, configHelp,
+		serveHelp, initHelp, examplesHelp, configurationHelp, tokensHelp,
+	}
+}
 
-`, version, version, date)
+// helpTopics returns the topics of prx help, in the order of the page.
+// Each command is an alias of the topic describing it.
+func helpTopics() []help.Topic {
+	return []help.Topic{
+		{Name: "input", Summary: "Directories, archives and git refs to read", Sections: []help.Section{inputHelp}},
+		{Name: "filtering", Aliases: []string{"filters", "exclude", "include"}, Summary: "Which files are included or excluded",
+			Sections: []help.Section{filteringHelp}},
+		{Name: "output", Summary: "Where the output goes: files, the clipboard, parts and templates", Sections: []help.Section{outputHelp}},
+		{Name: "formats", Aliases: []string{"format", "schema"}, Summary: "The output formats and their schemas",
+			Sections: []help.Section{formatsHelp(), schemaHelp}},
+		{Name: "processing", Summary: "Transforming and annotating files, and the exit status for CI",
+			Sections: []help.Section{processingHelp}},
+		{Name: "relevance", Aliases: []string{"budget", "tokens"}, Summary: "Keyword relevance, token budgets and tokenizers",
+			Sections: []help.Section{relevanceHelp, tokensHelp}},
+		{Name: "debug", Aliases: []string{"update", "logging"}, Summary: "Logging, versions and updates",
+			Sections: []help.Section{debugHelp, updateHelp}},
+		{Name: "help", Aliases: []string{"man"}, Summary: "This help and the man page", Sections: []help.Section{helpHelp}},
+		{Name: "cache", Summary: "The file cache, on by default", Sections: []help.Section{cacheHelp}},
+		{Name: "verify", Summary: "Checking a context against the files it came from", Sections: []help.Section{verifyHelp}},
+		{Name: "hooks", Aliases: []string{"hook"}, Summary: "Git pre-commit and pre-push checks", Sections: []help.Section{hooksHelp}},
+		{Name: "completion", Summary: "Shell completion scripts", Sections: []help.Section{completionHelp}},
+		{Name: "plan", Summary: "Suggesting excludes that fit a token budget", Sections: []help.Section{planHelp}},
+		{Name: "explain", Summary: "Why a file is included or excluded", Sections: []help.Section{explainHelp}},
+		{Name: "compare", Summary: "What changed between two versions, in tokens", Sections: []help.Section{compareHelp}},
+		{Name: "ask", Summary: "Asking a language model about the code", Sections: []help.Section{askHelp}},
+		{Name: "push", Summary: "Uploading the output to a files API", Sections: []help.Section{pushHelp}},
+		{Name: "gha", Aliases: []string{"github-actions", "actions"}, Summary: "Running as a GitHub Actions step",
+			Sections: []help.Section{ghaHelp}},
+		{Name: "config", Aliases: []string{"configuration", "profiles", "init"}, Summary: "The .promptext.yml settings, profiles and prx config",
+			Sections: []help.Section{configHelp, initHelp, configurationHelp}},
+		{Name: "serve", Aliases: []string{"server", "mcp"}, Summary: "The MCP server and REST API", Sections: []help.Section{serveHelp}},
+		{Name: "examples", Summary: "Example command lines", Sections: []help.Section{examplesHelp}},
+	}
 }
 
 type initializerRunner interface {
@@ -182,70 +606,497 @@ type initializerRunner interface {
 
 type initializerFactory func(root string, force bool, quiet bool) initializerRunner
 
-type processorFunc func(dirPath string, extension string, exclude string, noCopy bool, infoOnly bool, verbose bool, outputFormat string, outFile string, debug bool, gitignore bool, useDefaultRules bool, dryRun bool, quiet bool, relevanceKeywords string, maxTokens int, explainSelection bool) error
+type extractFunc func(opts processor.RunOptions) error
+
+// updateConfig runs the initializer's update of the config in root
+func updateConfig(root string, assumeYes bool, quiet bool) error {
+	return initializer.NewInitializer(root, false, quiet).RunUpdate(assumeYes)
+}
+
+// flagError reports a flag the command rejected, followed by the command's
+// usage, and returns the exit status of a usage error
+func flagError(deps cliDeps, err error, usage string) int {
+	fmt.Fprintf(deps.stderr, "Error: %v\n%s\n", err, usage)
+	return 2
+}
+
+// runWithLibrary runs an extraction through the promptext library, as a thin
+// CLI wrapper around it.
+func runWithLibrary(runOpts processor.RunOptions) error {
+	dirPath, outputFormat, outFile, quiet := runOpts.DirPath, runOpts.OutputFormat, runOpts.OutFile, runOpts.Quiet
+	dirs := strings.Split(dirPath, ",")
+	fromArchive := len(dirs) == 1 && isArchivePath(dirPath)
+	if fromArchive && (runOpts.DryRun || runOpts.SplitTokens > 0) {
+		return fmt.Errorf("--dry-run and --split don't support archives; unpack %s first", dirPath)
+	}
+	if runOpts.GitRef != "" && (fromArchive || runOpts.Files != nil) {
+		return fmt.Errorf("--ref cannot be combined with an archive or --files-from")
+	}
+
+	if runOpts.Ask != "" {
+		if len(dirs) > 1 || fromArchive {
+			return fmt.Errorf("ask takes a single directory")
+		}
+		if runOpts.DryRun || runOpts.TreeOnly || runOpts.InfoOnly || runOpts.SplitTokens > 0 || runOpts.Prompt != "" {
+			return fmt.Errorf("ask cannot be combined with --dry-run, --tree, --info, --split, or --prompt")
+		}
+	}
+
+	if runOpts.SummaryJSON != "" && (runOpts.DryRun || runOpts.Ask != "" || len(runOpts.Explain) > 0) {
+		return fmt.Errorf("--summary-json cannot be combined with --dry-run, ask, or explain")
+	}
+	if runOpts.Stats != "" && (runOpts.DryRun || runOpts.TreeOnly || runOpts.InfoOnly || runOpts.SplitTokens > 0 ||
+		runOpts.Prompt != "" || runOpts.Ask != "" || len(runOpts.Explain) > 0 || runOpts.CompareFrom != "") {
+		return fmt.Errorf("--stats cannot be combined with --dry-run, --tree, --info, --split, --prompt, ask, explain, or compare")
+	}
+	if (runOpts.FailOverTokens > 0 || runOpts.FailIfEmpty) && (runOpts.Ask != "" || len(runOpts.Explain) > 0) {
+		return fmt.Errorf("--fail-over-tokens and --fail-if-empty cannot be combined with ask or explain")
+	}
+	if runOpts.Plan {
+		if len(dirs) > 1 || fromArchive {
+			return fmt.Errorf("plan takes a single directory")
+		}
+		if runOpts.DryRun || runOpts.TreeOnly || runOpts.InfoOnly || runOpts.SplitTokens > 0 || runOpts.Prompt != "" ||
+			runOpts.Stats != "" || runOpts.SummaryJSON != "" || runOpts.Ask != "" || len(runOpts.Explain) > 0 || runOpts.CompareFrom != "" {
+			return fmt.Errorf("plan cannot be combined with --dry-run, --tree, --info, --split, --prompt, --stats, --summary-json, ask, explain, or compare")
+		}
+	}
+	if runOpts.CompareFrom != "" {
+		if len(dirs) > 1 || fromArchive {
+			return fmt.Errorf("compare takes a single directory")
+		}
+		if runOpts.DryRun || runOpts.TreeOnly || runOpts.InfoOnly || runOpts.SplitTokens > 0 || runOpts.Prompt != "" ||
+			runOpts.SummaryJSON != "" || runOpts.FailOverTokens > 0 || runOpts.FailIfEmpty || runOpts.GitRef != "" {
+			return fmt.Errorf("compare cannot be combined with --dry-run, --tree, --info, --split, --prompt, --summary-json, --fail-*, or --ref")
+		}
+	}
+	if runOpts.Push {
+		if len(dirs) > 1 || fromArchive {
+			return fmt.Errorf("push takes a single directory")
+		}
+		if runOpts.DryRun || runOpts.TreeOnly || runOpts.InfoOnly || runOpts.SplitTokens > 0 || runOpts.Stats != "" ||
+			runOpts.Gzip || runOpts.Base64 || runOpts.Ask != "" || len(runOpts.Explain) > 0 || runOpts.CompareFrom != "" || runOpts.Plan {
+			return fmt.Errorf("push cannot be combined with --dry-run, --tree, --info, --split, --stats, --gzip, --base64, ask, explain, compare, or plan")
+		}
+	}
+	if runOpts.GHA {
+		if len(dirs) > 1 || fromArchive {
+			return fmt.Errorf("gha takes a single directory")
+		}
+		if runOpts.Files != nil || runOpts.GitRef != "" {
+			return fmt.Errorf("gha lists the pull request's changed files itself; drop --files-from and --ref")
+		}
+		if runOpts.DryRun || runOpts.TreeOnly || runOpts.InfoOnly || runOpts.SplitTokens > 0 || runOpts.Stats != "" ||
+			runOpts.Ask != "" || len(runOpts.Explain) > 0 || runOpts.CompareFrom != "" || runOpts.Plan || runOpts.Push {
+			return fmt.Errorf("gha cannot be combined with --dry-run, --tree, --info, --split, --stats, ask, explain, compare, plan, or push")
+		}
+	}
+
+	if (runOpts.MarkdownTOC || runOpts.MarkdownCollapse != 0) && outputFormat != "markdown" && outputFormat != "md" {
+		return fmt.Errorf("--toc and --collapse need the markdown format (-f markdown)")
+	}
+	if runOpts.MarkdownCollapse < 0 {
+		return fmt.Errorf("--collapse must be a positive number of lines")
+	}
+
+	if runOpts.DryRun && len(dirs) > 1 {
+		return fmt.Errorf("--dry-run takes a single directory")
+	}
+
+	if runOpts.TreeOnly && runOpts.SplitTokens > 0 {
+		return fmt.Errorf("--tree and --split cannot be combined")
+	}
+
+	// Split output goes to numbered files next to --output
+	if runOpts.SplitTokens > 0 && !runOpts.InfoOnly {
+		if outFile == "" {
+			return fmt.Errorf("--split requires --output FILE")
+		}
+		if len(dirs) > 1 {
+			return fmt.Errorf("--split takes a single directory")
+		}
+	}
+
+	// A PDF is a document, not something to paste
+	if outputFormat == "pdf" && outFile == "" && !runOpts.NoCopy && !runOpts.InfoOnly && !runOpts.TreeOnly && !runOpts.Push && !runOpts.GHA {
+		return fmt.Errorf("--format pdf requires --output FILE")
+	}
+
+	// Gzipped output is binary unless armored, so it can't be pasted either
+	if runOpts.Gzip && !runOpts.Base64 && outFile == "" && !runOpts.NoCopy && !runOpts.InfoOnly && !runOpts.TreeOnly && !runOpts.GHA {
+		return fmt.Errorf("--gzip output is binary; write it to --output FILE or add --base64")
+	}
+	if (runOpts.Gzip || runOpts.Base64) && (runOpts.DryRun || runOpts.Stats != "" || runOpts.Plan ||
+		runOpts.Ask != "" || len(runOpts.Explain) > 0 || runOpts.CompareFrom != "") {
+		return fmt.Errorf("--gzip and --base64 cannot be combined with --dry-run, --stats, plan, ask, explain, or compare")
+	}
 
-// runWithLibrary uses the promptext library for extraction instead of calling processor.Run() directly.
-// This provides a thin CLI wrapper around the library while maintaining backward compatibility.
-func runWithLibrary(dirPath string, extension string, exclude string, noCopy bool, infoOnly bool, verbose bool, outputFormat string, outFile string, debug bool, gitignore bool, useDefaultRules bool, dryRun bool, quiet bool, relevanceKeywords string, maxTokens int, explainSelection bool) error {
-	// For dry-run and explain-selection modes, fall back to processor.Run() as they use internal-only features
-	if dryRun || explainSelection {
-		return processor.Run(dirPath, extension, exclude, noCopy, infoOnly, verbose, outputFormat, outFile, debug, gitignore, useDefaultRules, dryRun, quiet, relevanceKeywords, maxTokens, explainSelection)
+	// A prompt template wraps one complete text output
+	if runOpts.Prompt != "" {
+		switch {
+		case runOpts.TreeOnly, runOpts.SplitTokens > 0:
+			return fmt.Errorf("--prompt cannot be combined with --tree or --split")
+		case outputFormat == "pdf":
+			return fmt.Errorf("--prompt needs a text format, not pdf")
+		}
 	}
 
 	// Build library options from CLI flags
 	opts := []promptext.Option{}
 
 	// Extensions
-	if extension != "" {
-		exts := strings.Split(extension, ",")
+	if runOpts.Extension != "" {
+		exts := strings.Split(runOpts.Extension, ",")
 		opts = append(opts, promptext.WithExtensions(exts...))
 	}
 
 	// Excludes
-	if exclude != "" {
-		excludes := strings.Split(exclude, ",")
+	if runOpts.Exclude != "" {
+		excludes := strings.Split(runOpts.Exclude, ",")
 		opts = append(opts, promptext.WithExcludes(excludes...))
 	}
 
+	// Include path globs
+	if runOpts.Include != "" {
+		includes := strings.Split(runOpts.Include, ",")
+		opts = append(opts, promptext.WithIncludes(includes...))
+	}
+
+	// An explicit file list replaces the directory walk
+	if runOpts.Files != nil {
+		if len(dirs) > 1 {
+			return fmt.Errorf("--files-from takes a single directory")
+		}
+		opts = append(opts, promptext.WithFileList(runOpts.Files))
+	}
+	if runOpts.GitRef != "" {
+		opts = append(opts, promptext.WithGitRef(runOpts.GitRef))
+	}
+
+	// Content excludes and generated-file detection
+	if len(runOpts.ContentExcludes) > 0 {
+		opts = append(opts, promptext.WithContentExcludes(runOpts.ContentExcludes...))
+	}
+	opts = append(opts, promptext.WithSkipGenerated(runOpts.SkipGenerated))
+	if runOpts.SkippedFileStubs {
+		opts = append(opts, promptext.WithSkippedFileStubs(true))
+	}
+
 	// GitIgnore
-	opts = append(opts, promptext.WithGitIgnore(gitignore))
+	opts = append(opts, promptext.WithGitIgnore(runOpts.GitIgnore))
 
 	// Default rules
-	opts = append(opts, promptext.WithDefaultRules(useDefaultRules))
+	opts = append(opts, promptext.WithDefaultRules(runOpts.UseDefaultRules))
 
 	// Relevance keywords
-	if relevanceKeywords != "" {
-		keywords := strings.FieldsFunc(relevanceKeywords, func(r rune) bool {
+	if runOpts.RelevanceKeywords != "" {
+		keywords := strings.FieldsFunc(runOpts.RelevanceKeywords, func(r rune) bool {
 			return r == ',' || r == ' '
 		})
 		opts = append(opts, promptext.WithRelevance(keywords...))
 	}
+	if runOpts.ExactKeywords {
+		opts = append(opts, promptext.WithExactKeywords(true))
+	}
+	if runOpts.BoostRecent {
+		opts = append(opts, promptext.WithRecencyBoost(true))
+	}
+	if runOpts.FollowImports > 0 {
+		opts = append(opts, promptext.WithFollowImports(runOpts.FollowImports))
+	}
+	if runOpts.IncludeTests != "" {
+		opts = append(opts, promptext.WithIncludeTests(promptext.TestInclusion(runOpts.IncludeTests)))
+	}
+	if runOpts.Snippets {
+		opts = append(opts, promptext.WithSnippets(runOpts.SnippetContext))
+	}
+	if runOpts.Owners {
+		opts = append(opts, promptext.WithOwners(true))
+	}
+	if runOpts.TodoScan {
+		opts = append(opts, promptext.WithTodoScan(true))
+	}
+	if runOpts.LicenseScan {
+		opts = append(opts, promptext.WithLicenseScan(true))
+	}
+	if runOpts.Metrics {
+		opts = append(opts, promptext.WithMetrics(true))
+	}
+	if runOpts.AuditDeps {
+		opts = append(opts, promptext.WithAuditDeps(true))
+	}
+	if runOpts.AuditReport != "" {
+		opts = append(opts, promptext.WithAuditReport(runOpts.AuditReport))
+	}
+	if runOpts.WorkspacePackage != "" {
+		opts = append(opts, promptext.WithWorkspacePackage(runOpts.WorkspacePackage))
+	}
+	if runOpts.Deterministic {
+		opts = append(opts, promptext.WithDeterministic(true))
+	}
+	if runOpts.Hashes {
+		opts = append(opts, promptext.WithHashes(true))
+	}
+	if runOpts.ExplainSelection {
+		opts = append(opts, promptext.WithExplainSelection(true))
+	}
+	if runOpts.TreeOnly {
+		opts = append(opts, promptext.WithTreeOnly(true))
+	}
 
 	// Token budget
-	if maxTokens > 0 {
-		opts = append(opts, promptext.WithTokenBudget(maxTokens))
+	if runOpts.MaxTokens > 0 {
+		opts = append(opts, promptext.WithTokenBudget(runOpts.MaxTokens))
+	}
+	if len(runOpts.BudgetWeights) > 0 {
+		opts = append(opts, promptext.WithBudgetWeights(runOpts.BudgetWeights))
+	}
+
+	// Tokenizer
+	if runOpts.Tokenizer != "" {
+		opts = append(opts, promptext.WithTokenizer(promptext.Tokenizer(runOpts.Tokenizer)))
+	}
+
+	// Target model
+	if runOpts.Model != "" {
+		opts = append(opts, promptext.WithModel(runOpts.Model), promptext.WithResponseReserve(runOpts.ResponseReserve))
 	}
 
 	// Format
 	opts = append(opts, promptext.WithFormat(promptext.Format(outputFormat)))
+	if runOpts.Template != "" {
+		tmpl, err := template.New(filepath.Base(runOpts.Template)).Funcs(promptext.TemplateFuncs()).ParseFiles(runOpts.Template)
+		if err != nil {
+			return fmt.Errorf("--template: %w", err)
+		}
+		opts = append(opts, promptext.WithTemplate(tmpl))
+	}
+	if runOpts.MarkdownTOC || runOpts.MarkdownCollapse > 0 {
+		opts = append(opts, promptext.WithMarkdown(promptext.MarkdownOptions{TOC: runOpts.MarkdownTOC, CollapseLines: runOpts.MarkdownCollapse}))
+	}
+	if runOpts.Gzip {
+		opts = append(opts, promptext.WithCompression(promptext.CompressionGzip))
+	}
+	if runOpts.Base64 {
+		opts = append(opts, promptext.WithEncoding(promptext.EncodingBase64))
+	}
+
+	// The global and project config files, and the profile over them
+	if !fromArchive {
+		opts = append(opts, promptext.WithConfigFiles(true))
+	}
+	if runOpts.Profile != "" {
+		opts = append(opts, promptext.WithProfile(runOpts.Profile))
+	}
+
+	// Files from outside the directory
+	if len(runOpts.ExtraFiles) > 0 {
+		opts = append(opts, promptext.WithExtraFiles(runOpts.ExtraFiles...))
+	}
+	if runOpts.Symlinks != "" {
+		opts = append(opts, promptext.WithSymlinks(promptext.SymlinkPolicy(runOpts.Symlinks)))
+	}
+
+	// Size caps
+	if runOpts.MaxFileSize > 0 {
+		opts = append(opts, promptext.WithMaxFileSize(runOpts.MaxFileSize))
+	}
+	if runOpts.MaxTotalSize > 0 {
+		opts = append(opts, promptext.WithMaxTotalSize(runOpts.MaxTotalSize))
+	}
+
+	// Jupyter notebook outputs
+	if runOpts.NotebookOutputs {
+		opts = append(opts, promptext.WithNotebookOutputs(true))
+	}
+
+	// Lockfile and minified bundle summaries
+	opts = append(opts, promptext.WithSummarizeLockfiles(runOpts.SummarizeAssets))
+
+	// Data file sampling
+	if runOpts.SampleRows > 0 {
+		opts = append(opts, promptext.WithDataSampling(runOpts.SampleRows))
+	}
+
+	// External content transform
+	if runOpts.TransformCmd != "" {
+		opts = append(opts, promptext.WithTransform(processor.CommandTransform(runOpts.TransformCmd)))
+	}
+
+	// Secret redaction
+	if runOpts.Redact {
+		opts = append(opts, promptext.WithRedaction(true))
+	}
+
+	// Comment and blank-line stripping
+	if runOpts.StripComments {
+		opts = append(opts, promptext.WithStripComments(true))
+	}
+	if runOpts.SquashBlankLines {
+		opts = append(opts, promptext.WithSquashBlankLines(true))
+	}
+	if runOpts.Compact != "" {
+		opts = append(opts, promptext.WithCompact(promptext.CompactMode(runOpts.Compact)))
+	}
+
+	// File cache and parallelism
+	if !runOpts.NoCache {
+		opts = append(opts, promptext.WithUserCache(true))
+	}
+	if runOpts.Concurrency > 0 {
+		opts = append(opts, promptext.WithConcurrency(runOpts.Concurrency))
+	}
+	if runOpts.Progress && !quiet {
+		opts = append(opts, promptext.WithProgress(newProgressBar(os.Stderr)))
+	}
 
 	// Verbose and debug
-	if debug {
+	if runOpts.Debug {
 		opts = append(opts, promptext.WithDebug(true))
-	} else if verbose {
+	} else if runOpts.Verbose {
 		opts = append(opts, promptext.WithVerbose(true))
 	}
 
-	// Extract using the library
-	result, err := promptext.Extract(dirPath, opts...)
+	if runOpts.DryRun {
+		return previewExtraction(dirPath, runOpts, opts)
+	}
+
+	if len(runOpts.Explain) > 0 {
+		if len(dirs) > 1 || fromArchive {
+			return fmt.Errorf("explain takes a single directory")
+		}
+		explanations, err := promptext.Explain(dirPath, runOpts.Explain, opts...)
+		if err != nil {
+			return err
+		}
+		for _, explanation := range explanations {
+			fmt.Println(explanation)
+		}
+		return nil
+	}
+
+	if runOpts.Ask != "" {
+		return askQuestion(dirPath, runOpts.Ask, outFile, quiet, opts)
+	}
+
+	if runOpts.CompareFrom != "" {
+		return compareVersions(dirPath, runOpts, opts)
+	}
+	if runOpts.Plan {
+		return planExcludes(dirPath, runOpts, opts)
+	}
+	if runOpts.Push {
+		return pushContext(dirPath, outputFormat, runOpts, opts)
+	}
+	if runOpts.GHA {
+		return runGitHubAction(dirPath, outputFormat, runOpts, opts)
+	}
+
+	// The run summary describes one extraction
+	if runOpts.SummaryJSON != "" {
+		switch {
+		case runOpts.SplitTokens > 0 && !runOpts.InfoOnly:
+			return fmt.Errorf("--summary-json cannot be combined with --split")
+		case runOpts.SummaryJSON == "-" && runOpts.TreeOnly && outFile == "":
+			return fmt.Errorf("--tree prints to stdout; write --summary-json to a FILE or the tree to --output")
+		}
+	}
+	// Status lines give way to a summary written to stdout
+	status := func(format string, args ...interface{}) {
+		if runOpts.SummaryJSON != "-" {
+			fmt.Printf(format, args...)
+		}
+	}
+
+	if runOpts.SplitTokens > 0 && !runOpts.InfoOnly {
+		parts, err := writeSplitOutput(dirPath, outFile, outputFormat, quiet, append(opts, promptext.WithSplitTokens(runOpts.SplitTokens)))
+		if err != nil {
+			if runOpts.FailIfEmpty && errors.Is(err, promptext.ErrNoFilesMatched) {
+				return processor.CheckPolicy(runOpts, 0, 0)
+			}
+			return err
+		}
+		files, tokens := 0, 0
+		for _, part := range parts {
+			files += len(part.ProjectOutput.Files)
+			tokens += part.TokenCount
+		}
+		return processor.CheckPolicy(runOpts, files, tokens)
+	}
+
+	// Extract using the library; several directories are combined into one output
+	var result *promptext.Result
+	var err error
+	if len(dirs) > 1 {
+		result, err = promptext.ExtractMulti(dirs, opts...)
+	} else if fromArchive {
+		result, err = extractArchiveFile(dirPath, opts)
+	} else {
+		result, err = promptext.Extract(dirPath, opts...)
+	}
 	if err != nil {
+		if runOpts.FailIfEmpty && errors.Is(err, promptext.ErrNoFilesMatched) {
+			return processor.CheckPolicy(runOpts, 0, 0)
+		}
+		if errors.Is(err, promptext.ErrTotalSizeExceeded) {
+			return fmt.Errorf("%w\nExclude them with -x PATTERN, or raise --max-total-size (0 for no limit)", err)
+		}
 		return err
 	}
+	// Once the output is out, --fail-over-tokens and --fail-if-empty decide the exit status
+	finish := func(output string) error {
+		if err := writeRunSummary(runOpts.SummaryJSON, result, output); err != nil {
+			return err
+		}
+		return processor.CheckPolicy(runOpts, len(result.ProjectOutput.Files), result.TokenCount)
+	}
+
+	if !quiet {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: %s\n", warning)
+		}
+	}
+
+	// Stats take the place of the output
+	if runOpts.Stats != "" {
+		text, err := renderStats(result.Stats(), runOpts.Stats)
+		if err != nil {
+			return err
+		}
+		if outFile == "" {
+			fmt.Print(text)
+			return finish("stdout")
+		}
+		if err := os.WriteFile(longpath.Fix(outFile), []byte(text), 0644); err != nil {
+			return fmt.Errorf("error writing to output file: %w", err)
+		}
+		status("\033[32m✓ Token stats written to %s\033[0m\n", outFile)
+		return finish(outFile)
+	}
+
+	// The annotated tree is for reading: print it unless it goes to a file
+	if runOpts.TreeOnly {
+		if outFile == "" {
+			if _, err := result.WriteTo(os.Stdout); err != nil {
+				return err
+			}
+			return finish("stdout")
+		}
+		if err := writeOutputFile(outFile, result); err != nil {
+			return err
+		}
+		if quiet {
+			status("written=%s files=%d tokens=%d\n", outFile, result.Tree.Files, result.Tree.Tokens)
+		} else {
+			status("\033[32m✓ Directory tree written to %s (%d files, ~%s tokens)\033[0m\n", outFile, result.Tree.Files, formatTokenCount(result.Tree.Tokens))
+		}
+		return finish(outFile)
+	}
 
 	// Handle info-only mode
-	if infoOnly {
+	if runOpts.InfoOnly {
 		if quiet {
-			fmt.Printf("files=%d tokens=%d\n", len(result.ProjectOutput.Files), result.TokenCount)
+			status("files=%d tokens=%d\n", len(result.ProjectOutput.Files), result.TokenCount)
 		} else {
 			// Format project info display
 			var info strings.Builder
@@ -274,9 +1125,15 @@ func runWithLibrary(dirPath string, extension string, exclude string, noCopy boo
 					fileCount, formatTokenCount(result.TokenCount)))
 			}
 
-			fmt.Printf("\033[32m%s\033[0m\n", info.String())
+			status("\033[32m%s\033[0m\n", info.String())
+		}
+		return finish("")
+	}
+
+	if runOpts.Prompt != "" {
+		if result.FormattedOutput, err = result.RenderPrompt(runOpts.Prompt, runOpts.PromptVars); err != nil {
+			return err
 		}
-		return nil
 	}
 
 	// Build exclusion message if files were excluded
@@ -345,17 +1202,39 @@ func runWithLibrary(dirPath string, extension string, exclude string, noCopy boo
 	infoFormatted := info.String()
 
 	// Handle output
+	destination := ""
 	if outFile != "" {
-		if err := os.WriteFile(outFile, []byte(result.FormattedOutput), 0644); err != nil {
-			return fmt.Errorf("error writing to output file: %w", err)
+		if err := writeOutputFile(outFile, result); err != nil {
+			return err
 		}
+		destination = outFile
 		if quiet {
-			fmt.Printf("written=%s format=%s files=%d tokens=%d%s\n", outFile, outputFormat, len(result.ProjectOutput.Files), result.TokenCount, exclusionMsg)
+			status("written=%s format=%s files=%d tokens=%d%s\n", outFile, outputFormat, len(result.ProjectOutput.Files), result.TokenCount, exclusionMsg)
 		} else {
-			fmt.Printf("\033[32m%s%s\n\n✓ Code context written to %s (%s format)\033[0m\n", infoFormatted, exclusionMsg, outFile, outputFormat)
+			status("\033[32m%s%s\n\n✓ Code context written to %s (%s format)\033[0m\n", infoFormatted, exclusionMsg, outFile, outputFormat)
 		}
-	} else if !noCopy {
-		if err := clipboard.WriteAll(result.FormattedOutput); err != nil {
+	} else if !runOpts.NoCopy {
+		text := result.FormattedOutput
+		if runOpts.Base64 {
+			var armored strings.Builder
+			if _, err := result.WriteTo(&armored); err != nil {
+				return err
+			}
+			text = armored.String()
+		}
+		if limit := runOpts.ClipboardMaxBytes; limit > 0 && len(text) > limit {
+			// Too big for some clipboard managers: leave it in a file instead
+			path, err := writeClipboardFallback(runOpts, outputFormat, result)
+			if err != nil {
+				return err
+			}
+			destination = path
+			if quiet {
+				status("written=%s format=%s files=%d tokens=%d clipboard=too-large%s\n", path, outputFormat, len(result.ProjectOutput.Files), result.TokenCount, exclusionMsg)
+			} else {
+				status("\033[32m%s%s\n\n✓ Output is %d bytes, over the %d-byte clipboard limit; written to %s instead\033[0m\n(--clipboard-max-bytes 0 copies it anyway)\n", infoFormatted, exclusionMsg, len(text), limit, path)
+			}
+		} else if backend, err := clipboard.Write(runOpts.ClipboardBackend, text); err != nil {
 			if !quiet {
 				fmt.Printf("Warning: Failed to copy to clipboard: %v\n", err)
 			}
@@ -363,37 +1242,580 @@ func runWithLibrary(dirPath string, extension string, exclude string, noCopy boo
 				return fmt.Errorf("clipboard copy failed")
 			}
 		} else {
+			destination = "clipboard"
+			via := ""
+			if backend != clipboard.BackendSystem {
+				via = " via " + backend
+			}
 			if quiet {
-				fmt.Printf("clipboard=ok format=%s files=%d tokens=%d%s\n", outputFormat, len(result.ProjectOutput.Files), result.TokenCount, exclusionMsg)
+				status("clipboard=ok format=%s files=%d tokens=%d%s\n", outputFormat, len(result.ProjectOutput.Files), result.TokenCount, exclusionMsg)
 			} else {
-				fmt.Printf("\033[32m%s%s\n\n✓ Copied to clipboard!\033[0m\n", infoFormatted, exclusionMsg)
+				status("\033[32m%s%s\n\n✓ Copied to clipboard%s!\033[0m\n", infoFormatted, exclusionMsg, via)
 			}
 		}
 	}
 
-	return nil
+	return finish(destination)
 }
 
-// formatTokenCount formats token count with comma separators for readability
-func formatTokenCount(tokens int) string {
-	if tokens < 1000 {
-		return fmt.Sprintf("%d", tokens)
-	}
-	// Add comma separators for thousands
-	str := fmt.Sprintf("%d", tokens)
-	var result strings.Builder
-	for i, c := range str {
-		if i > 0 && (len(str)-i)%3 == 0 {
-			result.WriteRune(',')
+const statsUsage = "Usage: promptext stats [--json] [OPTIONS] [DIRECTORY]"
+
+// renderStats renders stats as JSON, or as two tables, by directory and by
+// extension
+func renderStats(stats promptext.Stats, mode string) (string, error) {
+	if mode == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error encoding stats: %w", err)
 		}
-		result.WriteRune(c)
+		return string(data) + "\n", nil
 	}
-	return result.String()
-}
+
+	var sb strings.Builder
+	table := func(title string, groups []promptext.StatsGroup) {
+		width := len(title)
+		for _, group := range groups {
+			width = max(width, len(group.Name))
+		}
+		fmt.Fprintf(&sb, "%-*s %6s %10s %6s\n", width, title, "FILES", "TOKENS", "%")
+		for _, group := range groups {
+			fmt.Fprintf(&sb, "%-*s %6d %10s %5.1f%%\n", width, group.Name, group.Files, formatTokenCount(group.Tokens), group.Percent)
+		}
+		fmt.Fprintf(&sb, "%-*s %6d %10s %5.1f%%\n", width, "total", stats.Files, formatTokenCount(stats.Tokens), 100.0)
+	}
+	table("DIRECTORY", stats.Directories)
+	sb.WriteString("\n")
+	table("EXTENSION", stats.Extensions)
+	if stats.ExcludedFiles > 0 {
+		fmt.Fprintf(&sb, "\n%d excluded files (~%s tokens) are not counted\n", stats.ExcludedFiles, formatTokenCount(stats.ExcludedTokens))
+	}
+	return sb.String(), nil
+}
+
+// writeRunSummary writes result's summary as JSON to path, or to stdout for
+// "-"; output records where the formatted output went. An empty path writes
+// nothing.
+func writeRunSummary(path string, result *promptext.Result, output string) error {
+	if path == "" {
+		return nil
+	}
+	summary := result.Summary()
+	summary.Output = output
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(longpath.Fix(path), data, 0644); err != nil {
+		return fmt.Errorf("error writing summary: %w", err)
+	}
+	return nil
+}
+
+// askQuestion answers question about dirPath with the language model the
+// environment configures, streaming the answer to stdout and, with outFile,
+// also writing it there
+func askQuestion(dirPath, question, outFile string, quiet bool, opts []promptext.Option) error {
+	model, err := promptext.LLMFromEnv()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	extractor := promptext.NewExtractor(append(opts, promptext.WithLLM(model), promptext.WithAnswerWriter(os.Stdout))...)
+	answer, err := extractor.Ask(ctx, dirPath, question)
+	if answer != nil && answer.Text != "" && !strings.HasSuffix(answer.Text, "\n") {
+		fmt.Println()
+	}
+	if err != nil {
+		return err
+	}
+
+	if outFile != "" {
+		if err := os.WriteFile(longpath.Fix(outFile), []byte(answer.Text), 0644); err != nil {
+			return fmt.Errorf("error writing to output file: %w", err)
+		}
+	}
+	if !quiet {
+		for _, warning := range answer.Context.Warnings {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: %s\n", warning)
+		}
+		fmt.Fprintf(os.Stderr, "\033[32m✓ Answered by %v from %d files (~%s tokens of context)\033[0m\n",
+			model, len(answer.Context.ProjectOutput.Files), formatTokenCount(answer.Context.TokenCount))
+	}
+	return nil
+}
+
+// pushContext extracts dirPath and uploads the output to a provider's files
+// API, printing the file id on stdout so scripts can pass it to the next
+// prompt. With an output file, the output is written there as well.
+func pushContext(dirPath, outputFormat string, runOpts processor.RunOptions, opts []promptext.Option) error {
+	uploader, provider, err := upload.FromEnv(runOpts.PushProvider, os.Getenv)
+	if err != nil {
+		return err
+	}
+	result, err := promptext.Extract(dirPath, opts...)
+	if err != nil {
+		return err
+	}
+	if runOpts.Prompt != "" {
+		if result.FormattedOutput, err = result.RenderPrompt(runOpts.Prompt, runOpts.PromptVars); err != nil {
+			return err
+		}
+	}
+	if runOpts.OutFile != "" {
+		if err := writeOutputFile(runOpts.OutFile, result); err != nil {
+			return err
+		}
+	}
+
+	name := getProjectDisplayName(dirPath) + "-context" + outputExtension(runOpts, outputFormat)
+	mediaType := "text/plain"
+	if outputFormat == "pdf" {
+		mediaType = "application/pdf"
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	file, err := uploader.Upload(ctx, name, mediaType, []byte(result.FormattedOutput))
+	if err != nil {
+		return fmt.Errorf("error uploading to %s: %w", provider, err)
+	}
+
+	fmt.Println(file.ID)
+	if !runOpts.Quiet {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: %s\n", warning)
+		}
+		fmt.Fprintf(os.Stderr, "\033[32m✓ Uploaded %s to %s: %d files, ~%s tokens, %d bytes\033[0m\n",
+			name, provider, len(result.ProjectOutput.Files), formatTokenCount(result.TokenCount), len(result.FormattedOutput))
+	}
+	return nil
+}
+
+// ghaContextFile is the file gha writes the context to without --output
+const ghaContextFile = "promptext-context"
+
+// ghaTopFiles is the number of files the gha summary lists by tokens
+const ghaTopFiles = 10
+
+// runGitHubAction runs as a GitHub Actions step: it extracts the files the
+// pull request changes (the whole directory for other events), writes the
+// context file for upload as an artifact, reports through the job summary,
+// the step outputs and optionally a pull request comment, and fails over
+// --fail-over-tokens once everything is reported.
+func runGitHubAction(dirPath, outputFormat string, runOpts processor.RunOptions, opts []promptext.Option) error {
+	env, err := gha.EnvFrom(os.Getenv)
+	if err != nil {
+		return err
+	}
+	pr, err := env.PullRequest()
+	if err != nil {
+		return err
+	}
+	var changed []string
+	if pr != nil {
+		if changed, err = gha.ChangedFiles(dirPath, pr.BaseSHA, pr.HeadSHA); err != nil {
+			return err
+		}
+		if len(changed) == 0 {
+			return ghaNoChanges(env, pr, runOpts)
+		}
+		opts = append(opts, promptext.WithFileList(changed))
+	}
+
+	result, err := promptext.Extract(dirPath, opts...)
+	if err != nil {
+		return err
+	}
+	if runOpts.Prompt != "" {
+		if result.FormattedOutput, err = result.RenderPrompt(runOpts.Prompt, runOpts.PromptVars); err != nil {
+			return err
+		}
+	}
+	contextFile := runOpts.OutFile
+	if contextFile == "" {
+		contextFile = ghaContextFile + outputExtension(runOpts, outputFormat)
+	}
+	if err := writeOutputFile(contextFile, result); err != nil {
+		return err
+	}
+
+	if abs, err := filepath.Abs(contextFile); err == nil {
+		contextFile = abs // For the upload step, which runs elsewhere
+	}
+
+	files := len(result.ProjectOutput.Files)
+	overBudget := runOpts.FailOverTokens > 0 && result.TokenCount > runOpts.FailOverTokens
+	summary := ghaSummary(pr, changed, result, runOpts.FailOverTokens)
+	if err := env.AppendSummary(summary); err != nil {
+		return fmt.Errorf("error writing the job summary: %w", err)
+	}
+	if runOpts.GHAComment {
+		if pr == nil {
+			fmt.Fprintln(os.Stderr, "⚠️  Warning: not a pull request event; skipping the comment")
+		} else {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			if err := env.Comment(ctx, pr.Number, summary); err != nil {
+				return fmt.Errorf("error commenting on pull request #%d: %w", pr.Number, err)
+			}
+		}
+	}
+	if err := env.SetOutputs(map[string]string{
+		"context-file":  contextFile,
+		"files":         strconv.Itoa(files),
+		"tokens":        strconv.Itoa(result.TokenCount),
+		"over-budget":   strconv.FormatBool(overBudget),
+		"changed-files": strings.Join(changed, "\n"),
+	}); err != nil {
+		return fmt.Errorf("error writing the step outputs: %w", err)
+	}
+
+	if !runOpts.Quiet {
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: %s\n", warning)
+		}
+		scope := "the directory"
+		if pr != nil {
+			scope = fmt.Sprintf("pull request #%d (%d changed files)", pr.Number, len(changed))
+		}
+		fmt.Printf("✓ Context of %s written to %s: %d files, ~%s tokens\n", scope, contextFile, files, formatTokenCount(result.TokenCount))
+	}
+	return processor.CheckPolicy(runOpts, files, result.TokenCount)
+}
+
+// ghaNoChanges reports a pull request that changes nothing under the
+// directory: there is no context file, and only --fail-if-empty fails
+func ghaNoChanges(env gha.Env, pr *gha.PullRequest, runOpts processor.RunOptions) error {
+	summary := fmt.Sprintf("### promptext context\n\n#%d changes no files in this directory.\n", pr.Number)
+	if err := env.AppendSummary(summary); err != nil {
+		return fmt.Errorf("error writing the job summary: %w", err)
+	}
+	if err := env.SetOutputs(map[string]string{"context-file": "", "files": "0", "tokens": "0", "over-budget": "false", "changed-files": ""}); err != nil {
+		return fmt.Errorf("error writing the step outputs: %w", err)
+	}
+	if !runOpts.Quiet {
+		fmt.Printf("✓ Pull request #%d changes no files in the directory\n", pr.Number)
+	}
+	return processor.CheckPolicy(runOpts, 0, 0)
+}
+
+// ghaSummary renders the markdown the gha step adds to the job summary and
+// posts as a comment: totals against the budget, the largest files, and the
+// files left out to fit
+func ghaSummary(pr *gha.PullRequest, changed []string, result *promptext.Result, budget int) string {
+	var sb strings.Builder
+	sb.WriteString("### promptext context\n\n")
+	if pr != nil {
+		fmt.Fprintf(&sb, "Context of the %d files changed in #%d: ", len(changed), pr.Number)
+	} else {
+		sb.WriteString("Context of the repository: ")
+	}
+	files := result.ProjectOutput.Files
+	fmt.Fprintf(&sb, "**%d files, ~%s tokens**", len(files), formatTokenCount(result.TokenCount))
+	switch {
+	case budget <= 0:
+		sb.WriteString(".\n")
+	case result.TokenCount > budget:
+		fmt.Fprintf(&sb, ", over the %s-token budget.\n", formatTokenCount(budget))
+	default:
+		fmt.Fprintf(&sb, ", within the %s-token budget.\n", formatTokenCount(budget))
+	}
+
+	if len(files) > 0 {
+		largest := make([]promptext.FileInfo, len(files))
+		copy(largest, files)
+		slices.SortStableFunc(largest, func(a, b promptext.FileInfo) int { return b.Tokens - a.Tokens })
+		if len(largest) > ghaTopFiles {
+			largest = largest[:ghaTopFiles]
+		}
+		sb.WriteString("\n| File | Tokens |\n| --- | ---: |\n")
+		for _, file := range largest {
+			fmt.Fprintf(&sb, "| `%s` | %s |\n", strings.ReplaceAll(file.Path, "|", "\\|"), formatTokenCount(file.Tokens))
+		}
+		if len(files) > ghaTopFiles {
+			fmt.Fprintf(&sb, "\n…and %d more files.\n", len(files)-ghaTopFiles)
+		}
+	}
+
+	if len(result.ExcludedFileList) > 0 {
+		fmt.Fprintf(&sb, "\n%d files left out to fit:\n\n", len(result.ExcludedFileList))
+		for _, file := range result.ExcludedFileList {
+			fmt.Fprintf(&sb, "- `%s` (~%s tokens, %s)\n", file.Path, formatTokenCount(file.Tokens), file.Reason)
+		}
+	}
+	return sb.String()
+}
+
+// compareVersions prints the comparison report of two git refs or
+// directories, or writes it to the output file
+func compareVersions(dirPath string, runOpts processor.RunOptions, opts []promptext.Option) error {
+	to := runOpts.CompareTo
+	if to == "" {
+		to = dirPath // The working tree
+	}
+	if runOpts.CompareDiffs {
+		opts = append(opts, promptext.WithCompareDiffs(true))
+	}
+	comparison, err := promptext.NewExtractor(opts...).CompareAt(dirPath, runOpts.CompareFrom, to)
+	if err != nil {
+		return err
+	}
+
+	if runOpts.OutFile == "" {
+		fmt.Print(comparison.FormattedOutput)
+		if !strings.HasSuffix(comparison.FormattedOutput, "\n") {
+			fmt.Println()
+		}
+		return nil
+	}
+	if err := os.WriteFile(longpath.Fix(runOpts.OutFile), []byte(comparison.FormattedOutput), 0644); err != nil {
+		return fmt.Errorf("error writing to output file: %w", err)
+	}
+	if !runOpts.Quiet {
+		fmt.Printf("\033[32m✓ Comparison written to %s: %d added, %d removed, %d modified (%+d tokens)\033[0m\n",
+			runOpts.OutFile, comparison.Added, comparison.Removed, comparison.Modified, comparison.TokenDelta)
+	}
+	return nil
+}
+
+// planExcludes prints the excludes that bring dirPath within the token
+// budget and, with --apply, adds them to its .promptext.yml
+func planExcludes(dirPath string, runOpts processor.RunOptions, opts []promptext.Option) error {
+	plan, err := promptext.Plan(dirPath, opts...)
+	if errors.Is(err, promptext.ErrNoTokenBudget) {
+		return fmt.Errorf("plan needs a target: pass --max-tokens N or --model NAME")
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d files, ~%s tokens; target %s tokens\n", plan.Files, formatTokenCount(plan.Tokens), formatTokenCount(plan.Target))
+	if plan.Tokens <= plan.Target {
+		fmt.Println("Already within budget; nothing to exclude")
+		return nil
+	}
+	fmt.Printf("Over by ~%s tokens. Suggested excludes:\n", formatTokenCount(plan.Tokens-plan.Target))
+	for _, s := range plan.Suggestions {
+		fmt.Printf("  exclude %-28s to save ~%s tokens (%d files, %s)\n", s.Pattern, formatTokenCount(s.Tokens), s.Files, s.Kind)
+	}
+	if plan.Fits() {
+		fmt.Printf("\033[32m✓ With these excludes: %d files, ~%s tokens\033[0m\n", plan.RemainingFiles, formatTokenCount(plan.Remaining))
+	} else {
+		fmt.Printf("\033[33m⚠️  Still ~%s tokens over with these excludes (%d files, ~%s tokens); narrow with --relevant or --extension\033[0m\n",
+			formatTokenCount(plan.Remaining-plan.Target), plan.RemainingFiles, formatTokenCount(plan.Remaining))
+	}
+
+	if !runOpts.PlanApply || len(plan.Suggestions) == 0 {
+		return nil
+	}
+	added, err := initializer.AddExcludes(dirPath, plan.Excludes())
+	if err != nil {
+		return err
+	}
+	if len(added) == 0 {
+		fmt.Println("The excludes are already in .promptext.yml")
+		return nil
+	}
+	fmt.Printf("\033[32m✓ Added %d excludes to %s\033[0m\n", len(added), filepath.Join(dirPath, ".promptext.yml"))
+	return nil
+}
+
+// previewExtraction prints what extracting dirPath would process, without
+// reading any file content, and applies the --fail-* policy to the estimate
+func previewExtraction(dirPath string, runOpts processor.RunOptions, opts []promptext.Option) error {
+	preview, err := promptext.Preview(dirPath, opts...)
+	if err != nil {
+		if runOpts.FailIfEmpty && errors.Is(err, promptext.ErrNoFilesMatched) {
+			return processor.CheckPolicy(runOpts, 0, 0)
+		}
+		return fmt.Errorf("error during dry-run preview: %w", err)
+	}
+	if runOpts.Quiet {
+		fmt.Printf("files=%d tokens=%d\n", len(preview.Files), preview.EstimatedTokens)
+	} else {
+		for _, warning := range preview.Warnings {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: %s\n", warning)
+		}
+		fmt.Println(preview)
+	}
+	return processor.CheckPolicy(runOpts, len(preview.Files), preview.EstimatedTokens)
+}
+
+// writeSplitOutput extracts dirPath in parts and writes each to a numbered
+// file derived from outFile (context.ptx -> context-part1.ptx, ...),
+// returning the parts written
+func writeSplitOutput(dirPath, outFile, outputFormat string, quiet bool, opts []promptext.Option) ([]promptext.Result, error) {
+	parts, err := promptext.ExtractSplit(dirPath, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !quiet {
+		// Each part repeats the extraction's warnings and adds its own
+		seen := make(map[string]bool)
+		for _, part := range parts {
+			for _, warning := range part.Warnings {
+				if !seen[warning] {
+					seen[warning] = true
+					fmt.Fprintf(os.Stderr, "⚠️  Warning: %s\n", warning)
+				}
+			}
+		}
+	}
+
+	var summary strings.Builder
+	for i, part := range parts {
+		name := splitFileName(outFile, i+1)
+		if err := writeOutputFile(name, &parts[i]); err != nil {
+			return nil, err
+		}
+		if quiet {
+			fmt.Printf("written=%s part=%d/%d format=%s files=%d tokens=%d\n", name, i+1, len(parts), outputFormat, len(part.ProjectOutput.Files), part.TokenCount)
+			continue
+		}
+		summary.WriteString(fmt.Sprintf("\n• %s: %d files • ~%s tokens", name, len(part.ProjectOutput.Files), formatTokenCount(part.TokenCount)))
+	}
+	if !quiet {
+		fmt.Printf("\033[32m📦 %s%s\n\n✓ Code context split into %d parts (%s format)\033[0m\n", getProjectDisplayName(dirPath), summary.String(), len(parts), outputFormat)
+	}
+	return parts, nil
+}
+
+// splitFileName numbers a split part by inserting -partN before the
+// extension, or before both of a gzipped file's (context.ptx.gz)
+func splitFileName(outFile string, part int) string {
+	ext := filepath.Ext(outFile)
+	if strings.EqualFold(ext, ".gz") {
+		ext = filepath.Ext(strings.TrimSuffix(outFile, ext)) + ext
+	}
+	return fmt.Sprintf("%s-part%d%s", strings.TrimSuffix(outFile, ext), part, ext)
+}
+
+// writeOutputFile writes the output of result to path, compressed and
+// encoded as the extraction was configured to
+func writeOutputFile(path string, result *promptext.Result) error {
+	f, err := os.OpenFile(longpath.Fix(path), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing to output file: %w", err)
+	}
+	if _, err := result.WriteTo(f); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing to output file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing to output file: %w", err)
+	}
+	return nil
+}
+
+// writeClipboardFallback writes output too large to copy to a new temp file,
+// named for its format, and returns the file's path
+func writeClipboardFallback(runOpts processor.RunOptions, outputFormat string, result *promptext.Result) (string, error) {
+	f, err := os.CreateTemp("", "promptext-*"+outputExtension(runOpts, outputFormat))
+	if err != nil {
+		return "", fmt.Errorf("error creating a file for output too large to copy: %w", err)
+	}
+	f.Close()
+	if err := writeOutputFile(f.Name(), result); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// outputExtension returns the file extension of output in outputFormat,
+// with .gz and .b64 added for gzipped and armored output
+func outputExtension(runOpts processor.RunOptions, outputFormat string) string {
+	ext := ".txt"
+	for _, info := range promptext.Formatters() {
+		if formatNamed(info, outputFormat) && len(info.Extensions) > 0 {
+			ext = info.Extensions[0]
+		}
+	}
+	if runOpts.Gzip {
+		ext += ".gz"
+	}
+	if runOpts.Base64 {
+		ext += ".b64"
+	}
+	return ext
+}
+
+// progressRedraw is the least time between redraws of the progress bar
+const progressRedraw = 100 * time.Millisecond
+
+// progressBarWidth is the number of cells in the progress bar
+const progressBarWidth = 24
+
+// newProgressBar returns a WithProgress callback drawing a one-line progress
+// bar to w: a file count while scanning, then a bar of processed files with
+// the tokens counted so far. Redraws are throttled, and the line is cleared
+// once files are loaded so the output that follows starts on a clean line.
+func newProgressBar(w io.Writer) func(promptext.ProgressEvent) {
+	var last time.Time
+	return func(event promptext.ProgressEvent) {
+		if event.Stage == promptext.ProgressDone {
+			if !last.IsZero() {
+				fmt.Fprint(w, "\r\033[K")
+			}
+			return
+		}
+		// Always draw the last file, so a slow final step shows the full bar
+		final := event.Stage == promptext.ProgressProcessing && event.FilesProcessed == event.FilesScanned
+		if now := time.Now(); now.Sub(last) >= progressRedraw || final {
+			last = now
+			fmt.Fprintf(w, "\r\033[K%s", formatProgress(event))
+		}
+	}
+}
+
+// formatProgress renders one progress event as a status line
+func formatProgress(event promptext.ProgressEvent) string {
+	if event.Stage == promptext.ProgressScanning {
+		return fmt.Sprintf("Scanning... %d files found", event.FilesScanned)
+	}
+	filled := 0
+	if event.FilesScanned > 0 {
+		filled = event.FilesProcessed * progressBarWidth / event.FilesScanned
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	return fmt.Sprintf("%s %d/%d files, %s tokens", bar, event.FilesProcessed, event.FilesScanned, formatTokenCount(event.Tokens))
+}
+
+// formatTokenCount formats token count with comma separators for readability
+func formatTokenCount(tokens int) string {
+	if tokens < 1000 {
+		return fmt.Sprintf("%d", tokens)
+	}
+	// Add comma separators for thousands
+	str := fmt.Sprintf("%d", tokens)
+	var result strings.Builder
+	for i, c := range str {
+		if i > 0 && (len(str)-i)%3 == 0 {
+			result.WriteRune(',')
+		}
+		result.WriteRune(c)
+	}
+	return result.String()
+}
 
 // getProjectDisplayName returns the proper display name for a project directory.
-// It resolves "." to the actual directory name.
+// It resolves "." to the actual directory name. Comma-separated directories
+// are listed by name.
 func getProjectDisplayName(dirPath string) string {
+	if dirs := strings.Split(dirPath, ","); len(dirs) > 1 {
+		names := make([]string, len(dirs))
+		for i, dir := range dirs {
+			names[i] = getProjectDisplayName(dir)
+		}
+		return strings.Join(names, ", ")
+	}
+
 	// If dirPath is "." or relative, resolve to absolute path
 	absPath, err := filepath.Abs(dirPath)
 	if err != nil {
@@ -403,7 +1825,34 @@ func getProjectDisplayName(dirPath string) string {
 	return filepath.Base(absPath)
 }
 
+// archiveExtensions are the file types prx reads as a project in place of a directory
+var archiveExtensions = []string{".zip", ".tar", ".tar.gz", ".tgz"}
+
+// isArchivePath reports whether path is an archive file to extract in place
+// of a directory
+func isArchivePath(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			stat, err := os.Stat(path)
+			return err == nil && stat.Mode().IsRegular()
+		}
+	}
+	return false
+}
+
+// extractArchiveFile extracts the project held in the archive at path
+func extractArchiveFile(path string, opts []promptext.Option) (*promptext.Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening archive: %w", err)
+	}
+	defer file.Close()
+	return promptext.ExtractArchive(file, opts...)
+}
+
 type cliDeps struct {
+	stdin          io.Reader
 	stdout         io.Writer
 	stderr         io.Writer
 	usage          func()
@@ -411,12 +1860,16 @@ type cliDeps struct {
 	updater        func(string, bool) error
 	notifyUpdate   func(string)
 	newInitializer initializerFactory
-	processorRun   processorFunc
+	runInit        func(initializer.Options) error
+	updateInit     func(root string, assumeYes bool, quiet bool) error
+	extract        extractFunc
 	absPath        func(string) (string, error)
+	listenAndServe func(string, http.Handler) error
 }
 
 func defaultCLIDeps() cliDeps {
 	return cliDeps{
+		stdin:          os.Stdin,
 		stdout:         os.Stdout,
 		stderr:         os.Stderr,
 		usage:          customUsage,
@@ -426,12 +1879,18 @@ func defaultCLIDeps() cliDeps {
 		newInitializer: func(root string, force bool, quiet bool) initializerRunner {
 			return initializer.NewInitializer(root, force, quiet)
 		},
-		processorRun: runWithLibrary, // Use library instead of processor.Run
-		absPath:      filepath.Abs,
+		runInit:        initializer.RunWithOptions,
+		updateInit:     updateConfig,
+		extract:        runWithLibrary,
+		absPath:        filepath.Abs,
+		listenAndServe: listenAndServe,
 	}
 }
 
 func run(args []string, deps cliDeps) int {
+	if deps.stdin == nil {
+		deps.stdin = os.Stdin
+	}
 	if deps.stdout == nil {
 		deps.stdout = os.Stdout
 	}
@@ -455,12 +1914,95 @@ func run(args []string, deps cliDeps) int {
 			return initializer.NewInitializer(root, force, quiet)
 		}
 	}
-	if deps.processorRun == nil {
-		deps.processorRun = processor.Run
+	if deps.runInit == nil {
+		deps.runInit = initializer.RunWithOptions
+	}
+	if deps.updateInit == nil {
+		deps.updateInit = updateConfig
+	}
+	if deps.extract == nil {
+		deps.extract = runWithLibrary
 	}
 	if deps.absPath == nil {
 		deps.absPath = filepath.Abs
 	}
+	if deps.listenAndServe == nil {
+		deps.listenAndServe = listenAndServe
+	}
+
+	// The completion scripts call back with the words typed so far; the
+	// answer needs the flags defined below, so no other command runs
+	var completeWords []string
+	completing := len(args) > 0 && args[0] == completeCommand
+	if completing {
+		completeWords, args = args[1:], nil
+	}
+
+	if len(args) > 0 && args[0] == "completion" {
+		return runCompletionCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "help" {
+		return runHelpCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "man" {
+		return runManCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "cache" {
+		return runCacheCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "serve" {
+		return runServeCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "schema" {
+		return runSchemaCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "config" {
+		return runConfigCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "formats" {
+		return runFormatsCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "verify" {
+		return runVerifyCommand(args[1:], deps)
+	}
+	if len(args) > 0 && args[0] == "hook" {
+		return runHookCommand(args[1:], deps)
+	}
+	// "explain FILE..." takes the usual options, with files in place of the directory
+	explainFiles := len(args) > 0 && args[0] == "explain"
+	if explainFiles {
+		args = args[1:]
+	}
+	// So does "ask QUESTION...", with the words of the question
+	asking := len(args) > 0 && args[0] == "ask"
+	if asking {
+		args = args[1:]
+	}
+	// And "compare --from REF", with the directory as usual
+	comparing := len(args) > 0 && args[0] == "compare"
+	if comparing {
+		args = args[1:]
+	}
+	// "stats" is --stats, taking the usual options
+	statsMode := len(args) > 0 && args[0] == "stats"
+	if statsMode {
+		args = args[1:]
+	}
+	// As does "plan", with --max-tokens or --model as the target
+	planning := len(args) > 0 && args[0] == "plan"
+	if planning {
+		args = args[1:]
+	}
+	// And "push", uploading the output in place of copying it
+	pushing := len(args) > 0 && args[0] == "push"
+	if pushing {
+		args = args[1:]
+	}
+	// And "gha", the GitHub Actions step extracting a pull request's changes
+	ghaMode := len(args) > 0 && args[0] == "gha"
+	if ghaMode {
+		args = args[1:]
+	}
 
 	flagSet := pflag.NewFlagSet("promptext", pflag.ContinueOnError)
 	flagSet.SetOutput(deps.stderr)
@@ -470,39 +2012,119 @@ func run(args []string, deps cliDeps) int {
 	showVersion := flagSet.BoolP("version", "v", false, "Show version information and exit")
 
 	checkUpdate := flagSet.Bool("check-update", false, "Check if a new version is available")
-	doUpdate := flagSet.Bool("update", false, "Update to the latest version from GitHub")
+	doUpdate := flagSet.Bool("update", false, "Update to the latest version from GitHub (with --init: update the config)")
 
 	initConfig := flagSet.Bool("init", false, "Initialize a new .promptext.yml config file with smart defaults")
 	forceInit := flagSet.Bool("force", false, "Force overwrite of existing config (use with --init)")
+	assumeYes := flagSet.BoolP("yes", "y", false, "With --init, write the config without prompting")
+	excludeTests := flagSet.Bool("exclude-tests", true, "With --init --yes, exclude test files from the config")
 
-	dirPath := flagSet.StringP("directory", "d", ".", "Directory to process (default: current directory)")
+	dirPath := flagSet.StringP("directory", "d", ".", "Directory to process; comma-separate several to combine them (default: current directory)")
 	extension := flagSet.StringP("extension", "e", "", "File extensions to include (comma-separated, e.g., .go,.js,.py)")
 	gitignore := flagSet.BoolP("gitignore", "g", true, "Use .gitignore patterns for filtering")
 	useDefaultRules := flagSet.BoolP("use-default-rules", "u", true, "Use built-in filtering rules for common files")
 
 	exclude := flagSet.StringP("exclude", "x", "", "Patterns to exclude (comma-separated, e.g., vendor/,*.test.go)")
-
-	format := flagSet.StringP("format", "f", "ptx", "Output format: ptx, toon, jsonl, toon-strict, markdown, md, or xml (default: ptx)")
-	outFile := flagSet.StringP("output", "o", "", "Write output to file instead of clipboard")
+	include := flagSet.String("include", "", "Path globs to include (comma-separated, e.g., internal/processor/**,cmd/*/main.go)")
+	filesFrom := flagSet.String("files-from", "", "Process exactly the paths listed in this file, one per line (- for stdin)")
+	gitRef := flagSet.String("ref", "", "Read files as committed at this git branch, tag, or commit")
+	planApply := flagSet.Bool("apply", false, "Plan: add the suggested excludes to .promptext.yml")
+	pushProvider := flagSet.String("provider", "", "Push: the files API to upload to: anthropic or openai (default: the first with an API key set)")
+	ghaComment := flagSet.Bool("comment", false, "GitHub Action: post the summary as a pull request comment")
+	statsJSON := flagSet.Bool("json", false, "Stats: print the statistics as JSON")
+	compareFrom := flagSet.String("from", "", "Compare: the git ref or directory to compare from")
+	compareTo := flagSet.String("to", "", "Compare: the git ref or directory to compare to (default: the working tree)")
+	compareDiffs := flagSet.Bool("diff", false, "Compare: include unified diffs of changed files")
+	excludeContent := flagSet.StringArray("exclude-content", nil, "Skip files whose content matches this regular expression (repeatable)")
+	extraFiles := flagSet.StringArray("extra-file", nil, "Also include this file from outside the directory (repeatable)")
+	symlinks := flagSet.String("symlinks", processor.SymlinksSkip, "Symlink policy: skip, follow, or follow-in-root")
+	maxFileSize := flagSet.String("max-file-size", "", "Skip files larger than this size, such as 1MB (default: 10MB)")
+	maxTotalSize := flagSet.String("max-total-size", defaultMaxTotalSize, "Fail before reading when the files total more than this size (0 = no limit)")
+	transformCmd := flagSet.String("transform-cmd", "", "Pipe each file's content through this shell command before processing")
+	notebookOutputs := flagSet.Bool("notebook-outputs", false, "Keep cell outputs when flattening Jupyter notebooks")
+	summarizeLockfiles := flagSet.Bool("summarize-lockfiles", true, "Replace lockfiles and minified bundles with summaries")
+	sampleRows := flagSet.Int("sample-rows", 0, "Keep only the header and first N rows of CSV, TSV and JSON Lines files")
+	skipGenerated := flagSet.Bool("skip-generated", true, "Skip generated and minified files detected by their content")
+	skippedStubs := flagSet.Bool("skipped-stubs", false, "List skipped binary, oversized and generated files as stubs in the output")
+	owners := flagSet.Bool("owners", false, "Attach CODEOWNERS owners to files and summarize them per owner")
+	todos := flagSet.Bool("todos", false, "List TODO, FIXME and HACK comments in an issues section")
+	licenseScan := flagSet.Bool("license-scan", false, "Inventory licenses and flag missing or conflicting license headers")
+	metrics := flagSet.Bool("metrics", false, "Add per-file line, function and complexity metrics with the top hotspots")
+	auditDeps := flagSet.Bool("audit-deps", false, "List known vulnerabilities of the dependencies from the OSV database")
+	auditReport := flagSet.String("audit-report", "", "List vulnerabilities from this osv-scanner JSON report instead of querying OSV")
+	workspacePackage := flagSet.String("package", "", "Extract only this workspace package and the packages it depends on")
+
+	format := flagSet.StringP("format", "f", "ptx", "Output format: ptx, toon, jsonl, json, toon-strict, markdown, md, xml, html, pdf, or template (default: ptx)")
+	templatePath := flagSet.String("template", "", "Go template file to render the output through (implies --format template)")
+	outFile := flagSet.StringP("output", "o", "", "Write output to file instead of clipboard (gzipped for a name ending in .gz)")
+	gzipOutput := flagSet.Bool("gzip", false, "Gzip the output")
+	base64Output := flagSet.Bool("base64", false, "Armor the output in base64, after any --gzip")
+	deterministic := flagSet.Bool("deterministic", false, "Byte-identical output for unchanged files, without git HEAD info (default: on with --output)")
+	hashes := flagSet.Bool("hashes", false, "Add file hashes and a content hash to the manifest, for prx verify")
+	toc := flagSet.Bool("toc", false, "Markdown: start with a table of contents linking to each file")
+	collapse := flagSet.Int("collapse", 0, "Markdown: fold files longer than this many lines into <details> blocks")
 	noCopy := flagSet.BoolP("no-copy", "n", false, "Don't copy output to clipboard")
+	clipboardBackend := flagSet.String("clipboard-backend", clipboard.BackendAuto, "Clipboard backend: auto, system, wl-copy, xclip, xsel, or osc52")
+	clipboardMaxBytes := flagSet.Int("clipboard-max-bytes", clipboard.DefaultMaxBytes, "Write larger output to a temp file instead of copying (0 = no limit)")
 	infoOnly := flagSet.BoolP("info", "i", false, "Show only project summary without file contents")
+	treeOnly := flagSet.Bool("tree", false, "Print only the directory tree with file counts and token totals per directory")
 	verbose := flagSet.Bool("verbose", false, "Display full content in terminal while processing")
+	prompt := flagSet.String("prompt", "", "Wrap the output in a prompt template (code-review, refactor, document, test-generation, migration)")
+	promptVars := flagSet.StringToString("prompt-var", nil, "Prompt template variable as KEY=VALUE (repeatable)")
 
 	dryRun := flagSet.Bool("dry-run", false, "Preview files that would be processed without reading content")
+	noCache := flagSet.Bool("no-cache", false, "Don't read or write the file cache, which is on by default")
+	jobs := flagSet.IntP("jobs", "j", 0, "Files to read and tokenize in parallel (0 = one per CPU)")
+	showProgress := flagSet.Bool("progress", false, "Show a progress bar on stderr while files are scanned and processed")
+	profile := flagSet.String("profile", "", "Apply a named profile from .promptext.yml")
+	redactSecrets := flagSet.Bool("redact", false, "Replace detected secrets with placeholders in the output")
+	stripComments := flagSet.Bool("strip-comments", false, "Remove comments from source files")
+	squashBlankLines := flagSet.Bool("squash-blank-lines", false, "Collapse runs of blank lines into one")
+	compact := flagSet.String("compact", "", "Compact whitespace: whitespace (no value) or indent")
+	flagSet.Lookup("compact").NoOptDefVal = processor.CompactWhitespace
 	quiet := flagSet.BoolP("quiet", "q", false, "Suppress non-essential output for scripting")
+	summaryJSON := flagSet.String("summary-json", "", "Write a JSON run summary to this file (- or no value for stdout)")
+	flagSet.Lookup("summary-json").NoOptDefVal = "-"
+	stats := flagSet.String("stats", "", "Print token usage by directory and extension instead of the output (table, or json)")
+	flagSet.Lookup("stats").NoOptDefVal = "table"
+	failOverTokens := flagSet.Int("fail-over-tokens", 0, "Exit with status 3 if the output exceeds N tokens")
+	failIfEmpty := flagSet.Bool("fail-if-empty", false, "Exit with status 4 if no files match")
 
 	relevant := flagSet.StringP("relevant", "r", "", "Keywords to prioritize files (comma or space separated, multi-factor scoring)")
+	exactKeywords := flagSet.Bool("exact", false, "Match relevance keywords as given, without stemming or synonyms")
+	boostRecent := flagSet.Bool("boost-recent", false, "Rank files changed recently and often in git higher")
+	snippets := flagSet.Bool("snippets", false, "Keep only the definitions whose name matches a relevance keyword")
+	snippetContext := flagSet.Int("snippet-context", 3, "Lines kept around each definition with --snippets")
+	includeTests := flagSet.String("include-tests", "", "With \"paired\", include the tests of files selected by relevance")
+	followImports := flagSet.Int("follow-imports", 0, "Include files imported by highly relevant files, up to N import hops")
 	maxTokens := flagSet.Int("max-tokens", 0, "Maximum token budget for output (excludes lower-priority files when exceeded)")
-	explainSelection := flagSet.Bool("explain-selection", false, "Show detailed priority scoring breakdown for file selection")
+	budgetWeight := flagSet.StringToString("budget-weight", nil, "Share of --max-tokens for a directory or extension as KEY=FRACTION (repeatable)")
+	splitTokens := flagSet.Int("split", 0, "Split output into numbered files of at most N tokens each (requires --output)")
+	tokenizer := flagSet.String("tokenizer", "", "Tokenizer for token counts: cl100k, o200k, claude, chars, ollama:MODEL, or lmstudio:MODEL")
+	model := flagSet.String("model", "", "Target model; sets the token budget from its context window")
+	reserveTokens := flagSet.Int("reserve-tokens", 0, "Tokens of the model's context window reserved for the response")
+	explainSelection := flagSet.Bool("explain-selection", false, "Add a selection report (scores, score factors, inclusion decisions) to the output")
 
 	debug := flagSet.BoolP("debug", "D", false, "Enable debug logging and timing information")
+	logLevel := flagSet.String("log-level", "warn", "Lowest level logged to stderr: error, warn, info, or debug")
+	logFormat := flagSet.String("log-format", log.FormatText, "Log line format on stderr: text or json")
+
+	if completing {
+		for _, candidate := range completeArgs(flagSet, completeWords) {
+			fmt.Fprintln(deps.stdout, candidate)
+		}
+		return 0
+	}
 
 	if err := flagSet.Parse(args); err != nil {
 		if errors.Is(err, pflag.ErrHelp) {
 			deps.usage()
 			return 0
 		}
-		return 2
+		if statsMode {
+			return flagError(deps, err, statsUsage)
+		}
+		return flagError(deps, err, "Run 'promptext --help' for the available options.")
 	}
 
 	if *help {
@@ -529,7 +2151,7 @@ func run(args []string, deps cliDeps) int {
 		return 0
 	}
 
-	if *doUpdate {
+	if *doUpdate && !*initConfig {
 		if err := deps.updater(version, true); err != nil {
 			fmt.Fprintf(deps.stderr, "Error updating: %v\n", err)
 			return 1
@@ -544,6 +2166,30 @@ func run(args []string, deps cliDeps) int {
 			return 1
 		}
 
+		if *doUpdate {
+			if err := deps.updateInit(absPath, *assumeYes, *quiet); err != nil {
+				fmt.Fprintf(deps.stderr, "Error updating config: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
+		if *assumeYes {
+			if err := deps.runInit(initializer.Options{
+				RootPath:     absPath,
+				Force:        *forceInit,
+				Quiet:        *quiet,
+				IncludeTests: !*excludeTests,
+				Format:       *format,
+				Extensions:   processor.ParseCommaSeparated(*extension),
+				Excludes:     processor.ParseCommaSeparated(*exclude),
+			}); err != nil {
+				fmt.Fprintf(deps.stderr, "Error initializing config: %v\n", err)
+				return 1
+			}
+			return 0
+		}
+
 		init := deps.newInitializer(absPath, *forceInit, *quiet)
 		if err := init.Run(); err != nil {
 			fmt.Fprintf(deps.stderr, "Error initializing config: %v\n", err)
@@ -557,41 +2203,1207 @@ func run(args []string, deps cliDeps) int {
 	}
 
 	positional := flagSet.Args()
-	if len(positional) > 0 {
+	var explain []string
+	var question string
+	if explainFiles {
+		if len(positional) == 0 {
+			fmt.Fprintln(deps.stderr, "Usage: promptext explain [OPTIONS] FILE...")
+			return 2
+		}
+		for _, path := range positional {
+			absFile, err := deps.absPath(path)
+			if err != nil {
+				fmt.Fprintf(deps.stderr, "Error resolving path: %v\n", err)
+				return 1
+			}
+			explain = append(explain, absFile)
+		}
+	} else if asking {
+		question = strings.TrimSpace(strings.Join(positional, " "))
+		if question == "" {
+			fmt.Fprintln(deps.stderr, "Usage: promptext ask [OPTIONS] QUESTION")
+			return 2
+		}
+	}
+	if comparing != (*compareFrom != "") || (!comparing && (*compareTo != "" || *compareDiffs)) {
+		fmt.Fprintln(deps.stderr, "Usage: promptext compare --from REF [--to REF] [--diff] [OPTIONS] [DIRECTORY]")
+		return 2
+	}
+	if !explainFiles && !asking && len(positional) > 0 {
 		*dirPath = positional[0]
 	}
 
+	if *planApply && !planning {
+		fmt.Fprintln(deps.stderr, "Usage: promptext plan --max-tokens N [--apply] [OPTIONS] [DIRECTORY]")
+		return 2
+	}
+	if *pushProvider != "" && !pushing {
+		fmt.Fprintln(deps.stderr, "Usage: promptext push [--provider anthropic|openai] [OPTIONS] [DIRECTORY]")
+		return 2
+	}
+	if *ghaComment && !ghaMode {
+		fmt.Fprintln(deps.stderr, "Usage: promptext gha [--comment] [OPTIONS] [DIRECTORY]")
+		return 2
+	}
+
+	if *statsJSON && !statsMode {
+		fmt.Fprintln(deps.stderr, statsUsage)
+		return 2
+	}
+	if statsMode && *statsJSON {
+		*stats = "json"
+	} else if statsMode && *stats == "" {
+		*stats = "table"
+	}
+	if *stats != "" && *stats != "table" && *stats != "json" {
+		fmt.Fprintf(deps.stderr, "Unknown --stats mode %q (use table or json)\n", *stats)
+		return 2
+	}
+
+	// Files written to disk are often committed or cached, so they stay stable by default
+	if !flagSet.Lookup("deterministic").Changed {
+		*deterministic = *outFile != ""
+	}
+
+	if *templatePath != "" {
+		if flagSet.Lookup("format").Changed && *format != string(promptext.FormatTemplate) {
+			fmt.Fprintf(deps.stderr, "--template renders the template format; drop --format %s\n", *format)
+			return 2
+		}
+		*format = string(promptext.FormatTemplate)
+	} else if *format == string(promptext.FormatTemplate) {
+		fmt.Fprintln(deps.stderr, "--format template needs --template FILE")
+		return 2
+	}
+
+	formatChosen := flagSet.Lookup("format").Changed || *templatePath != ""
 	if *outFile != "" {
-		ext := strings.ToLower(filepath.Ext(*outFile))
-		detectedFormat := ""
-		switch ext {
-		case ".ptx":
-			detectedFormat = "ptx"
-		case ".toon":
-			detectedFormat = "toon"
-		case ".md", ".markdown":
-			detectedFormat = "markdown"
-		case ".xml":
-			detectedFormat = "xml"
-		}
-
-		if detectedFormat != "" && *format != detectedFormat {
+		// context.ptx.gz is gzipped PTX
+		name := *outFile
+		if strings.EqualFold(filepath.Ext(name), ".gz") {
+			*gzipOutput = true
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		ext := strings.ToLower(filepath.Ext(name))
+		detected, ok := formatForExtension(ext)
+		if ok && !formatNamed(detected, *format) && *templatePath == "" {
 			formatFlag := flagSet.Lookup("format")
 			if formatFlag != nil && formatFlag.Changed {
 				fmt.Fprintf(deps.stderr, "⚠️  Warning: format flag '%s' conflicts with output extension '%s' - using '%s' (flag takes precedence)\n", *format, ext, *format)
 			} else {
-				*format = detectedFormat
+				*format = detected.Name
+			}
+		}
+		formatChosen = formatChosen || ok
+	}
+	// Otherwise the config files choose the format, as prx config get reports it
+	if dir := strings.Split(*dirPath, ",")[0]; !formatChosen && !isArchivePath(dir) {
+		if absDir, err := deps.absPath(dir); err == nil {
+			if configured := configuredFormat(absDir, *profile); configured != "" {
+				*format = configured
 			}
 		}
 	}
 
-	if err := deps.processorRun(*dirPath, *extension, *exclude, *noCopy, *infoOnly, *verbose, *format, *outFile, *debug, *gitignore, *useDefaultRules, *dryRun, *quiet, *relevant, *maxTokens, *explainSelection); err != nil {
+	if !slices.Contains(clipboard.Backends, *clipboardBackend) {
+		fmt.Fprintf(deps.stderr, "Unknown --clipboard-backend %q (use %s)\n", *clipboardBackend, strings.Join(clipboard.Backends, ", "))
+		return 2
+	}
+	if *clipboardMaxBytes < 0 {
+		fmt.Fprintf(deps.stderr, "--clipboard-max-bytes must be 0 (no limit) or more, got %d\n", *clipboardMaxBytes)
+		return 2
+	}
+	if err := processor.ValidSymlinkPolicy(*symlinks); err != nil {
+		fmt.Fprintf(deps.stderr, "--symlinks: %v\n", err)
+		return 2
+	}
+	var maxFileBytes int64
+	if *maxFileSize != "" {
+		var err error
+		if maxFileBytes, err = parseByteSize(*maxFileSize); err != nil {
+			fmt.Fprintf(deps.stderr, "--max-file-size: %v\n", err)
+			return 2
+		}
+	}
+	maxTotalBytes, err := parseByteSize(*maxTotalSize)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "--max-total-size: %v\n", err)
+		return 2
+	}
+	if *compact != "" && *compact != processor.CompactWhitespace && *compact != processor.CompactIndent {
+		fmt.Fprintf(deps.stderr, "Unknown --compact mode %q (use %s or %s)\n", *compact, processor.CompactWhitespace, processor.CompactIndent)
+		return 2
+	}
+	level, err := log.ParseLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Invalid --log-level: %v\n", err)
+		return 2
+	}
+	if err := log.SetFormat(*logFormat); err != nil {
+		fmt.Fprintf(deps.stderr, "Invalid --log-format: %v\n", err)
+		return 2
+	}
+	log.SetLevel(level)
+	log.SetQuiet(*quiet)
+
+	budgetWeights := make(map[string]float64, len(*budgetWeight))
+	for key, value := range *budgetWeight {
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight < 0 || weight > 1 {
+			fmt.Fprintf(deps.stderr, "Invalid --budget-weight %s=%s (use a fraction between 0 and 1)\n", key, value)
+			return 2
+		}
+		budgetWeights[key] = weight
+	}
+
+	var files []string
+	if *filesFrom != "" {
+		var err error
+		if files, err = readFileList(*filesFrom, deps.stdin); err != nil {
+			fmt.Fprintf(deps.stderr, "Error reading file list: %v\n", err)
+			return 1
+		}
+	}
+
+	if err := deps.extract(processor.RunOptions{
+		DirPath:           *dirPath,
+		Extension:         *extension,
+		Exclude:           *exclude,
+		Include:           *include,
+		NoCopy:            *noCopy,
+		ClipboardBackend:  *clipboardBackend,
+		ClipboardMaxBytes: *clipboardMaxBytes,
+		InfoOnly:          *infoOnly,
+		TreeOnly:          *treeOnly,
+		Files:             files,
+		GitRef:            *gitRef,
+		CompareFrom:       *compareFrom,
+		Plan:              planning,
+		PlanApply:         *planApply,
+		Push:              pushing,
+		PushProvider:      *pushProvider,
+		GHA:               ghaMode,
+		GHAComment:        *ghaComment,
+		CompareTo:         *compareTo,
+		CompareDiffs:      *compareDiffs,
+		Verbose:           *verbose,
+		OutputFormat:      *format,
+		OutFile:           *outFile,
+		Debug:             *debug,
+		GitIgnore:         *gitignore,
+		UseDefaultRules:   *useDefaultRules,
+		DryRun:            *dryRun,
+		Quiet:             *quiet,
+		SummaryJSON:       *summaryJSON,
+		Stats:             *stats,
+		FailOverTokens:    *failOverTokens,
+		FailIfEmpty:       *failIfEmpty,
+		RelevanceKeywords: *relevant,
+		ExactKeywords:     *exactKeywords,
+		BoostRecent:       *boostRecent,
+		MaxTokens:         *maxTokens,
+		ExplainSelection:  *explainSelection,
+		NoCache:           *noCache,
+		Concurrency:       *jobs,
+		Progress:          *showProgress,
+		Explain:           explain,
+		Ask:               question,
+		Profile:           *profile,
+		Redact:            *redactSecrets,
+		StripComments:     *stripComments,
+		SquashBlankLines:  *squashBlankLines,
+		Compact:           *compact,
+		Tokenizer:         *tokenizer,
+		Model:             *model,
+		ResponseReserve:   *reserveTokens,
+		ContentExcludes:   *excludeContent,
+		SkipGenerated:     *skipGenerated,
+		SkippedFileStubs:  *skippedStubs,
+		Owners:            *owners,
+		TodoScan:          *todos,
+		LicenseScan:       *licenseScan,
+		Metrics:           *metrics,
+		AuditDeps:         *auditDeps,
+		AuditReport:       *auditReport,
+		WorkspacePackage:  *workspacePackage,
+		Deterministic:     *deterministic,
+		Hashes:            *hashes,
+		MarkdownTOC:       *toc,
+		MarkdownCollapse:  *collapse,
+		Template:          *templatePath,
+		Gzip:              *gzipOutput,
+		Base64:            *base64Output,
+		SplitTokens:       *splitTokens,
+		FollowImports:     *followImports,
+		Snippets:          *snippets,
+		IncludeTests:      *includeTests,
+		SnippetContext:    *snippetContext,
+		Prompt:            *prompt,
+		PromptVars:        *promptVars,
+		BudgetWeights:     budgetWeights,
+		ExtraFiles:        *extraFiles,
+		Symlinks:          *symlinks,
+		MaxFileSize:       maxFileBytes,
+		MaxTotalSize:      maxTotalBytes,
+		TransformCmd:      *transformCmd,
+		NotebookOutputs:   *notebookOutputs,
+		SummarizeAssets:   *summarizeLockfiles,
+		SampleRows:        *sampleRows,
+	}); err != nil {
 		fmt.Fprintf(deps.stderr, "%v\n", err)
+		var policy *processor.PolicyError
+		if errors.As(err, &policy) {
+			return policy.ExitCode
+		}
 		return 1
 	}
 	return 0
 }
 
+// readFileList reads the paths of --files-from, one per line, from path or,
+// for "-", from stdin. Blank lines are skipped.
+func readFileList(path string, stdin io.Reader) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// defaultMaxTotalSize is the --max-total-size the CLI applies unless told
+// otherwise, well above any source tree an LLM context could hold
+const defaultMaxTotalSize = "200MB"
+
+// parseByteSize reads a size given as bytes or as a whole number with a
+// binary KB, MB, GB or TB unit, case-insensitive and with the B optional:
+// "4096", "512KB", "1MB", "2g"
+func parseByteSize(s string) (int64, error) {
+	text := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	multiplier := int64(1)
+	for i, unit := range "KMGT" {
+		if strings.HasSuffix(text, string(unit)) {
+			multiplier = 1 << (10 * (i + 1))
+			text = strings.TrimSpace(strings.TrimSuffix(text, string(unit)))
+			break
+		}
+	}
+	size, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || size < 0 || size > (1<<63-1)/multiplier {
+		return 0, fmt.Errorf("invalid size %q (use bytes or a number with KB, MB or GB, such as 1MB)", s)
+	}
+	return size * multiplier, nil
+}
+
 func main() {
 	os.Exit(run(os.Args[1:], defaultCLIDeps()))
 }
+
+// runCacheCommand handles "promptext cache <action>"
+func runCacheCommand(args []string, deps cliDeps) int {
+	flagSet := pflag.NewFlagSet("promptext cache", pflag.ContinueOnError)
+	flagSet.SetOutput(deps.stderr)
+	dirPath := flagSet.StringP("directory", "d", ".", "Project directory whose cache to manage")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		return flagError(deps, err, "Usage: promptext cache clear [-d DIRECTORY]")
+	}
+
+	positional := flagSet.Args()
+	if len(positional) == 0 || positional[0] != "clear" {
+		fmt.Fprintln(deps.stderr, "Usage: promptext cache clear [-d DIRECTORY]")
+		return 2
+	}
+	if len(positional) > 1 {
+		*dirPath = positional[1]
+	}
+
+	absPath, err := deps.absPath(*dirPath)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error resolving directory path: %v\n", err)
+		return 1
+	}
+	cacheDir, err := cache.UserDir(absPath)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error locating cache: %v\n", err)
+		return 1
+	}
+	// Earlier versions kept the cache in the project
+	for _, dir := range []string{cacheDir, filepath.Join(absPath, cache.DirName)} {
+		if err := cache.Clear(dir); err != nil {
+			fmt.Fprintf(deps.stderr, "Error clearing cache: %v\n", err)
+			return 1
+		}
+	}
+	fmt.Fprintf(deps.stdout, "Cleared cache at %s\n", cacheDir)
+	return 0
+}
+
+// runSchemaCommand implements "promptext schema", printing the JSON Schema or
+// XSD that downstream tools can validate --format json, jsonl or xml output against
+func runSchemaCommand(args []string, deps cliDeps) int {
+	flagSet := pflag.NewFlagSet("promptext schema", pflag.ContinueOnError)
+	flagSet.SetOutput(deps.stderr)
+	formatName := flagSet.StringP("format", "f", "json", "Output format whose schema to print")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		return flagError(deps, err, "Usage: promptext schema [--format json|jsonl|xml]")
+	}
+
+	schema, err := promptext.Schema(promptext.Format(*formatName))
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "No schema for format %q (available: json, jsonl, xml)\n", *formatName)
+		return 2
+	}
+	fmt.Fprint(deps.stdout, schema)
+	return 0
+}
+
+// runVerifyCommand implements "promptext verify CONTEXT", reporting which of
+// the files a context written with --hashes lists changed since. It exits 1
+// when any changed or went missing, or the context's manifest was edited.
+func runVerifyCommand(args []string, deps cliDeps) int {
+	flagSet := pflag.NewFlagSet("promptext verify", pflag.ContinueOnError)
+	flagSet.SetOutput(deps.stderr)
+	dirPath := flagSet.StringP("directory", "d", ".", "Project directory the context was generated from")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		return flagError(deps, err, "Usage: promptext verify CONTEXT [-d DIRECTORY]")
+	}
+	if flagSet.NArg() != 1 {
+		fmt.Fprintln(deps.stderr, "Usage: promptext verify CONTEXT [-d DIRECTORY]")
+		return 2
+	}
+
+	absPath, err := deps.absPath(*dirPath)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error resolving directory path: %v\n", err)
+		return 1
+	}
+	v, err := promptext.Verify(flagSet.Arg(0), absPath)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error verifying context: %v\n", err)
+		if errors.Is(err, promptext.ErrNoHashes) {
+			fmt.Fprintln(deps.stderr, "Regenerate it with --hashes in the ptx, jsonl or json format")
+		}
+		return 1
+	}
+
+	for _, path := range v.Changed {
+		fmt.Fprintf(deps.stdout, "changed  %s\n", path)
+	}
+	for _, path := range v.Missing {
+		fmt.Fprintf(deps.stdout, "missing  %s\n", path)
+	}
+	if v.Tampered {
+		fmt.Fprintln(deps.stdout, "The file hashes in the context don't match its content hash; the context was edited")
+	}
+	fmt.Fprintf(deps.stdout, "%d unchanged, %d changed, %d missing (content %s)\n", len(v.Unchanged), len(v.Changed), len(v.Missing), v.Content)
+	if !v.OK() {
+		return 1
+	}
+	return 0
+}
+
+// completeCommand is the hidden command the completion scripts run, with
+// the words of the command line up to the cursor
+const completeCommand = "__complete"
+
+// completionModes are the commands that take the usual options
+var completionModes = []string{"ask", "compare", "explain", "gha", "plan", "push", "stats"}
+
+// completionCommands are the commands with options of their own, and the
+// actions each takes
+var completionCommands = map[string][]string{
+	"cache":      {"clear"},
+	"completion": {"bash", "zsh", "fish", "powershell"},
+	"config":     {"lint", "init", "get", "set"},
+	"formats":    nil,
+	"help":       help.Names(helpTopics()),
+	"hook":       {"install", "uninstall", "run"},
+	"man":        nil,
+	"schema":     nil,
+	"serve":      nil,
+	"verify":     nil,
+}
+
+// completeArgs returns the completions of the last of words, the command
+// line after the program name: commands, the flags of flagSet, or the values
+// of the flag before it. Nil leaves the shell to complete file names.
+func completeArgs(flagSet *pflag.FlagSet, words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current, before := words[len(words)-1], words[:len(words)-1]
+
+	if len(before) > 0 {
+		if actions, ok := completionCommands[before[0]]; ok {
+			switch {
+			case len(before) == 1:
+				return matching(actions, current)
+			case len(before) == 2 && before[0] == "hook":
+				return matching(hook.Names, current)
+			case len(before) == 2 && before[0] == "config" && (before[1] == "get" || before[1] == "set"):
+				return matching(config.Keys(), current)
+			}
+			return nil
+		}
+	}
+
+	// A value after the flag, as --format=md or as the next word
+	if name, value, ok := strings.Cut(current, "="); ok && strings.HasPrefix(name, "--") {
+		flag := flagSet.Lookup(name[2:])
+		if flag == nil {
+			return nil
+		}
+		var candidates []string
+		for _, candidate := range matching(flagValues(flag.Name, before), value) {
+			candidates = append(candidates, name+"="+candidate)
+		}
+		return candidates
+	}
+	if len(before) > 0 {
+		if flag := lookupWord(flagSet, before[len(before)-1]); flag != nil && flag.NoOptDefVal == "" {
+			return matching(flagValues(flag.Name, before), current)
+		}
+	}
+
+	if strings.HasPrefix(current, "-") {
+		var flags []string
+		flagSet.VisitAll(func(flag *pflag.Flag) {
+			if flag.Hidden || flag.Deprecated != "" {
+				return
+			}
+			flags = append(flags, "--"+flag.Name)
+			if flag.Shorthand != "" && !strings.HasPrefix(current, "--") {
+				flags = append(flags, "-"+flag.Shorthand)
+			}
+		})
+		return matching(flags, current)
+	}
+	if len(before) == 0 && current != "" {
+		commands := append([]string{}, completionModes...)
+		for name := range completionCommands {
+			commands = append(commands, name)
+		}
+		slices.Sort(commands)
+		return matching(commands, current)
+	}
+	return nil
+}
+
+// lookupWord returns the flag a word such as --format or -f names, or nil
+func lookupWord(flagSet *pflag.FlagSet, word string) *pflag.Flag {
+	switch {
+	case strings.HasPrefix(word, "--") && !strings.Contains(word, "="):
+		return flagSet.Lookup(word[2:])
+	case len(word) == 2 && word[0] == '-' && word[1] != '-':
+		return flagSet.ShorthandLookup(word[1:])
+	}
+	return nil
+}
+
+// flagValues returns the values to complete for a flag: formats from the
+// formatter registry, and profiles and prompt templates from the project
+// the words name with -d, or the working directory
+func flagValues(name string, words []string) []string {
+	dir := "."
+	for i, word := range words {
+		if (word == "-d" || word == "--directory") && i+1 < len(words) {
+			dir = words[i+1]
+		} else if value, ok := strings.CutPrefix(word, "--directory="); ok {
+			dir = value
+		}
+	}
+
+	switch name {
+	case "format":
+		var formats []string
+		for _, info := range promptext.Formatters() {
+			formats = append(formats, info.Name)
+			formats = append(formats, info.Aliases...)
+		}
+		return formats
+	case "profile":
+		var profiles []string
+		for _, load := range []func() (*config.FileConfig, error){func() (*config.FileConfig, error) { return config.LoadConfig(dir) }, config.LoadGlobalConfig} {
+			if cfg, err := load(); err == nil {
+				for profile := range cfg.Profiles {
+					if !slices.Contains(profiles, profile) {
+						profiles = append(profiles, profile)
+					}
+				}
+			}
+		}
+		slices.Sort(profiles)
+		return profiles
+	case "prompt":
+		return prompts.Names(dir)
+	case "tokenizer":
+		return append(token.Tokenizers(), token.TokenizerOllamaPrefix, token.TokenizerLMStudioPrefix)
+	case "model":
+		var models []string
+		for _, model := range token.Models() {
+			models = append(models, model.Name)
+		}
+		return models
+	case "symlinks":
+		return []string{processor.SymlinksSkip, processor.SymlinksFollow, processor.SymlinksFollowInRoot}
+	case "clipboard-backend":
+		return clipboard.Backends
+	case "provider":
+		return upload.Providers()
+	case "log-level":
+		return []string{"error", "warn", "info", "debug"}
+	case "log-format":
+		return []string{log.FormatText, log.FormatJSON}
+	}
+	return nil
+}
+
+// matching returns the candidates starting with prefix
+func matching(candidates []string, prefix string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// runHelpCommand implements "promptext help [TOPIC]": the sections of one
+// topic, or the list of topics
+func runHelpCommand(args []string, deps cliDeps) int {
+	topics := helpTopics()
+	if len(args) > 1 {
+		fmt.Fprintln(deps.stderr, "Usage: promptext help [TOPIC]")
+		return 2
+	}
+	if len(args) == 0 {
+		list := help.Section{Title: "TOPICS"}
+		for _, topic := range topics {
+			list.Entries = append(list.Entries, help.Entry{Name: topic.Name, Desc: topic.Summary})
+		}
+		fmt.Fprintf(deps.stdout, "promptext %s - %s\n\n", version, helpSummary)
+		help.Write(deps.stdout, usageHelp, list)
+		fmt.Fprintln(deps.stdout, "Run prx help TOPIC to show one, or prx --help to show them all.")
+		return 0
+	}
+
+	topic, ok := help.Find(topics, args[0])
+	if !ok {
+		fmt.Fprintf(deps.stderr, "Unknown help topic %q (available: %s)\n", args[0], strings.Join(help.Names(topics), ", "))
+		return 2
+	}
+	help.Write(deps.stdout, topic.Sections...)
+	return 0
+}
+
+// runManCommand implements "promptext man", printing the whole help as a
+// man page
+func runManCommand(args []string, deps cliDeps) int {
+	if len(args) > 0 {
+		fmt.Fprintln(deps.stderr, "Usage: promptext man")
+		return 2
+	}
+	sections := helpPage()
+	sections[0].Title = "SYNOPSIS"
+	sections = append(sections, help.Section{Title: "SEE ALSO", Text: docsURL + "\n\n" + homeURL})
+	page := help.Page{
+		Name:     "promptext",
+		Section:  1,
+		Source:   "promptext " + version,
+		Summary:  strings.ToLower(helpSummary[:1]) + helpSummary[1:],
+		Aliases:  []string{"prx"},
+		Sections: sections,
+	}
+	if date != "unknown" {
+		page.Date = date
+	}
+	help.WriteMan(deps.stdout, page)
+	return 0
+}
+
+// runCompletionCommand implements "promptext completion SHELL", printing
+// the shell's completion script. The scripts complete through the hidden
+// __complete command, so they follow the flags, formats and profiles of the
+// binary and project at hand.
+func runCompletionCommand(args []string, deps cliDeps) int {
+	if len(args) != 1 {
+		fmt.Fprintln(deps.stderr, completionUsage)
+		return 2
+	}
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		fmt.Fprintln(deps.stderr, completionUsage)
+		return 2
+	}
+	fmt.Fprint(deps.stdout, script)
+	return 0
+}
+
+// completionUsage is the usage of "promptext completion"
+const completionUsage = `Usage: promptext completion bash|zsh|fish|powershell
+Load it in the current shell, e.g. source <(prx completion bash)`
+
+// completionScripts are the completion scripts of each shell, for both the
+// prx and the promptext command
+var completionScripts = map[string]string{
+	"bash": `# bash completion for prx and promptext
+# Load with: source <(prx completion bash)
+_promptext() {
+    local line="${COMP_LINE:0:COMP_POINT}" cur="${COMP_WORDS[COMP_CWORD]}" words
+    read -ra words <<< "$line"
+    [[ $line == *[[:space:]] ]] && words+=("")
+    # prx is often an alias, which the subshell below doesn't expand
+    local program="${words[0]}"
+    type -P "$program" >/dev/null || program=promptext
+    local IFS=$'\n'
+    COMPREPLY=($("$program" __complete "${words[@]:1}" 2>/dev/null))
+    # bash completes after the "=" of --flag=value on its own
+    local last="${words[${#words[@]}-1]}"
+    if [[ $last == *=* && $cur != "$last" ]]; then
+        COMPREPLY=("${COMPREPLY[@]#*=}")
+    fi
+}
+complete -o default -F _promptext prx promptext
+`,
+	"zsh": `#compdef prx promptext
+# zsh completion for prx and promptext
+# Load with: source <(prx completion zsh), or save as _prx in your $fpath
+_promptext() {
+    local -a candidates program=${words[1]}
+    (( $+commands[$program] )) || program=promptext
+    candidates=("${(@f)$("$program" __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ -z ${candidates[1]} ]]; then
+        _files
+        return
+    fi
+    compadd -Q -- "${candidates[@]}"
+}
+if [[ ${funcstack[1]} == _promptext ]]; then
+    _promptext "$@"
+else
+    compdef _promptext prx promptext
+fi
+`,
+	"fish": `# fish completion for prx and promptext
+# Load with: prx completion fish | source
+function __promptext_complete
+    set -l words (commandline -opc) (commandline -ct)
+    set -l candidates ($words[1] __complete $words[2..-1] 2>/dev/null)
+    if test (count $candidates) -eq 0
+        __fish_complete_path (commandline -ct)
+    else
+        printf '%s\n' $candidates
+    end
+end
+complete -c prx -f -a '(__promptext_complete)'
+complete -c promptext -f -a '(__promptext_complete)'
+`,
+	"powershell": `# PowerShell completion for prx and promptext
+# Load with: prx completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName prx, promptext -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $PSNativeCommandArgumentPassing = 'Standard'
+    $words = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition -or $_.Extent.Text -eq $wordToComplete } | ForEach-Object { $_.Extent.Text })
+    $program = $words[0]
+    $arguments = @($words | Select-Object -Skip 1)
+    if ($wordToComplete -eq '') {
+        $arguments += ''
+    }
+    & $program __complete @arguments 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`,
+}
+
+// runFormatsCommand implements "promptext formats", listing the built-in
+// and registered output formats with their file extensions
+func runFormatsCommand(args []string, deps cliDeps) int {
+	flagSet := pflag.NewFlagSet("promptext formats", pflag.ContinueOnError)
+	flagSet.SetOutput(deps.stderr)
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		return flagError(deps, err, "Usage: promptext formats")
+	}
+	if flagSet.NArg() > 0 {
+		fmt.Fprintln(deps.stderr, "Usage: promptext formats")
+		return 2
+	}
+
+	fmt.Fprintf(deps.stdout, "%-24s %-16s %-22s %s\n", "FORMAT", "EXTENSIONS", "MIME TYPE", "DESCRIPTION")
+	for _, info := range promptext.Formatters() {
+		name := info.Name
+		if len(info.Aliases) > 0 {
+			name += " (" + strings.Join(info.Aliases, ", ") + ")"
+		}
+		extensions := strings.Join(info.Extensions, ", ")
+		if extensions == "" {
+			extensions = "-"
+		}
+		mimeType := info.MimeType
+		if mimeType == "" {
+			mimeType = "-"
+		}
+		description := info.Description
+		if !info.BuiltIn {
+			description = strings.TrimSpace("[registered] " + description)
+		}
+		fmt.Fprintf(deps.stdout, "%-24s %-16s %-22s %s\n", name, extensions, mimeType, description)
+	}
+	return 0
+}
+
+// formatForExtension returns the format an output file extension such as
+// ".md" selects, from the extensions formats declare
+func formatForExtension(ext string) (promptext.FormatterInfo, bool) {
+	if ext == "" {
+		return promptext.FormatterInfo{}, false
+	}
+	for _, info := range promptext.Formatters() {
+		for _, candidate := range info.Extensions {
+			if strings.EqualFold(candidate, ext) {
+				return info, true
+			}
+		}
+	}
+	return promptext.FormatterInfo{}, false
+}
+
+// formatNamed reports whether name is info's name or one of its aliases
+func formatNamed(info promptext.FormatterInfo, name string) bool {
+	return name == info.Name || slices.Contains(info.Aliases, name)
+}
+
+// configUsage lists the forms of "promptext config"
+const configUsage = `Usage: promptext config lint [FILE|DIRECTORY]
+       promptext config init --global [--force]
+       promptext config get [KEY] [--global] [-d DIRECTORY]
+       promptext config set KEY VALUE [--global] [-d DIRECTORY]`
+
+// runConfigCommand implements "promptext config": lint checks a config file,
+// init creates the global config, and get and set read and change settings
+// of the project or, with --global, the global config
+func runConfigCommand(args []string, deps cliDeps) int {
+	flagSet := pflag.NewFlagSet("promptext config", pflag.ContinueOnError)
+	flagSet.SetOutput(deps.stderr)
+	global := flagSet.Bool("global", false, "Use the global config instead of the project's")
+	force := flagSet.Bool("force", false, "Overwrite an existing global config (with init)")
+	dirPath := flagSet.StringP("directory", "d", ".", "Project directory whose config to use")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		return flagError(deps, err, configUsage)
+	}
+
+	positional := flagSet.Args()
+	if len(positional) == 0 {
+		fmt.Fprintln(deps.stderr, configUsage)
+		return 2
+	}
+	switch action, operands := positional[0], positional[1:]; {
+	case action == "lint" && len(operands) <= 1:
+		configPath := "."
+		if len(operands) == 1 {
+			configPath = operands[0]
+		}
+		return lintConfig(configPath, deps)
+	case action == "init" && len(operands) == 0:
+		if !*global {
+			fmt.Fprintln(deps.stderr, "config init creates the global config and needs --global; use --init for a project config")
+			return 2
+		}
+		path, err := config.InitGlobalConfig(*force)
+		if err != nil {
+			fmt.Fprintf(deps.stderr, "Error creating global config: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(deps.stdout, "Created global config at %s\n", path)
+		return 0
+	case action == "get" && len(operands) <= 1:
+		return getConfig(operands, *global, *dirPath, deps)
+	case action == "set" && len(operands) == 2:
+		configPath := config.GlobalConfigPath()
+		if !*global {
+			absPath, err := deps.absPath(*dirPath)
+			if err != nil {
+				fmt.Fprintf(deps.stderr, "Error resolving directory path: %v\n", err)
+				return 1
+			}
+			configPath = filepath.Join(absPath, ".promptext.yml")
+		}
+		if configPath == "" {
+			fmt.Fprintln(deps.stderr, "Error setting config: no home directory for the global config")
+			return 1
+		}
+		if err := config.SetValue(configPath, operands[0], operands[1]); err != nil {
+			fmt.Fprintf(deps.stderr, "Error setting config: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(deps.stdout, "Set %s in %s\n", operands[0], configPath)
+		return 0
+	}
+	fmt.Fprintln(deps.stderr, configUsage)
+	return 2
+}
+
+// lintConfig reports problems in a config file, or a directory's
+// .promptext.yml, with their line numbers. It returns 1 if any are found.
+func lintConfig(configPath string, deps cliDeps) int {
+	if info, err := os.Stat(configPath); err == nil && info.IsDir() {
+		configPath = filepath.Join(configPath, ".promptext.yml")
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error reading config: %v\n", err)
+		return 1
+	}
+	problems := config.Validate(data)
+	for _, problem := range problems {
+		if problem.Line > 0 {
+			fmt.Fprintf(deps.stdout, "%s:%d: %s\n", configPath, problem.Line, problem.Message)
+		} else {
+			fmt.Fprintf(deps.stdout, "%s: %s\n", configPath, problem.Message)
+		}
+	}
+	if len(problems) > 0 {
+		return 1
+	}
+	fmt.Fprintf(deps.stdout, "%s: no problems found\n", configPath)
+	return 0
+}
+
+// getConfig prints a setting, or with no key every setting, as the project
+// in dir sees it: the project config over the global config. With global it
+// reads the global config alone. Listing all settings shows where each
+// value comes from.
+func getConfig(keys []string, global bool, dir string, deps cliDeps) int {
+	globalConfig, err := config.LoadGlobalConfig()
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error loading global config: %v\n", err)
+		return 1
+	}
+	projectConfig := &config.FileConfig{}
+	if !global {
+		absPath, err := deps.absPath(dir)
+		if err != nil {
+			fmt.Fprintf(deps.stderr, "Error resolving directory path: %v\n", err)
+			return 1
+		}
+		if projectConfig, err = config.LoadConfig(absPath); err != nil {
+			fmt.Fprintf(deps.stderr, "Error loading config: %v\n", err)
+			return 1
+		}
+	}
+	effective := globalConfig.Overlay(projectConfig)
+
+	if len(keys) == 1 {
+		value, set, err := config.GetValue(effective, keys[0])
+		if err != nil {
+			fmt.Fprintf(deps.stderr, "Error reading config: %v\n", err)
+			return 1
+		}
+		if !set {
+			// Unset keys report the built-in value, or that there is none
+			if value, set = config.DefaultValue(keys[0]); !set {
+				fmt.Fprintf(deps.stderr, "%s is not set\n", keys[0])
+				return 0
+			}
+		}
+		fmt.Fprintln(deps.stdout, value)
+		return 0
+	}
+
+	for _, key := range config.Keys() {
+		value, set, _ := config.GetValue(effective, key)
+		if !set {
+			if value, set = config.DefaultValue(key); set {
+				fmt.Fprintf(deps.stdout, "%-18s %s  (default)\n", key, value)
+			}
+			continue
+		}
+		_, inGlobal, _ := config.GetValue(globalConfig, key)
+		_, inProject, _ := config.GetValue(projectConfig, key)
+		source := "project"
+		switch {
+		case inGlobal && inProject && (key == "excludes" || key == "annotations" || key == "relevance"):
+			source = "global + project"
+		case inGlobal && !inProject:
+			source = "global"
+		}
+		fmt.Fprintf(deps.stdout, "%-18s %s  (%s)\n", key, value, source)
+	}
+	return 0
+}
+
+// configuredFormat returns the format the global and project config files
+// of dir set, with profile over them, or "" when they set none. Files that
+// can't be read are left for the extraction to report.
+func configuredFormat(dir, profile string) string {
+	globalConfig, err := config.LoadGlobalConfig()
+	if err != nil {
+		return ""
+	}
+	projectConfig, err := config.LoadConfig(dir)
+	if err != nil {
+		return ""
+	}
+	settings := globalConfig.Overlay(projectConfig)
+	if profile != "" {
+		if profileConfig, err := config.FindProfile(profile, projectConfig, globalConfig); err == nil {
+			settings = settings.Overlay(profileConfig)
+		}
+	}
+	return settings.Format
+}
+
+// hookUsage is the usage of "promptext hook"
+const hookUsage = `Usage: promptext hook install [HOOK...] [--force] [-d DIRECTORY]
+       promptext hook uninstall [HOOK...] [-d DIRECTORY]
+       promptext hook run HOOK [-d DIRECTORY]
+HOOK is pre-commit or pre-push`
+
+// runHookCommand implements "promptext hook": install and uninstall set up
+// and remove the git hooks, by default those configured under hooks: in
+// .promptext.yml (or pre-commit), and run checks a change as the hook does
+func runHookCommand(args []string, deps cliDeps) int {
+	flagSet := pflag.NewFlagSet("promptext hook", pflag.ContinueOnError)
+	flagSet.SetOutput(deps.stderr)
+	force := flagSet.Bool("force", false, "Replace an existing hook promptext didn't install (with install)")
+	dirPath := flagSet.StringP("directory", "d", ".", "Repository whose hooks to manage")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		return flagError(deps, err, hookUsage)
+	}
+
+	positional := flagSet.Args()
+	if len(positional) == 0 {
+		fmt.Fprintln(deps.stderr, hookUsage)
+		return 2
+	}
+	action, names := positional[0], positional[1:]
+	for _, name := range names {
+		if !slices.Contains(hook.Names, name) {
+			fmt.Fprintf(deps.stderr, "Unknown hook %q (supported: %s)\n", name, strings.Join(hook.Names, ", "))
+			return 2
+		}
+	}
+	absPath, err := deps.absPath(*dirPath)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error resolving directory path: %v\n", err)
+		return 1
+	}
+	cfg, err := config.LoadConfig(absPath)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error loading config: %v\n", err)
+		return 1
+	}
+
+	switch {
+	case action == "run" && len(names) == 1:
+		return runHook(names[0], absPath, cfg.Hooks.Hook(names[0]), deps)
+	case action == "install" || action == "uninstall":
+		if len(names) == 0 {
+			if names = cfg.Hooks.Configured(); len(names) == 0 {
+				names = []string{hook.PreCommit}
+			}
+		}
+		for _, name := range names {
+			if action == "install" {
+				path, err := hook.Install(absPath, name, *force)
+				if err != nil {
+					fmt.Fprintf(deps.stderr, "Error installing %s hook: %v\n", name, err)
+					return 1
+				}
+				fmt.Fprintf(deps.stdout, "Installed %s hook at %s\n", name, path)
+				continue
+			}
+			path, removed, err := hook.Uninstall(absPath, name)
+			switch {
+			case err != nil:
+				fmt.Fprintf(deps.stderr, "Error removing %s hook: %v\n", name, err)
+				return 1
+			case removed:
+				fmt.Fprintf(deps.stdout, "Removed %s hook at %s\n", name, path)
+			default:
+				fmt.Fprintf(deps.stdout, "No promptext %s hook at %s\n", name, path)
+			}
+		}
+		return 0
+	}
+	fmt.Fprintln(deps.stderr, hookUsage)
+	return 2
+}
+
+// runHook checks the change a git hook is about to let through: the staged
+// files for pre-commit, each pushed ref for pre-push. It fails on secrets
+// in the changed files, a context over max-tokens, or a change growing the
+// context by more than max-growth tokens.
+func runHook(name, dir string, settings config.HookConfig, deps cliDeps) int {
+	var changes []hook.Change
+	if name == hook.PreCommit {
+		change, err := hook.Staged(dir)
+		if err != nil {
+			fmt.Fprintf(deps.stderr, "promptext %s: %v\n", name, err)
+			return 1
+		}
+		changes = []hook.Change{change}
+	} else {
+		var err error
+		if changes, err = hook.Pushed(dir, deps.stdin); err != nil {
+			fmt.Fprintf(deps.stderr, "promptext %s: %v\n", name, err)
+			return 1
+		}
+	}
+
+	failed := false
+	fail := func(format string, args ...interface{}) {
+		fmt.Fprintf(deps.stderr, "✗ promptext %s: %s\n", name, fmt.Sprintf(format, args...))
+		failed = true
+	}
+	for _, change := range changes {
+		if settings.ScanSecrets() {
+			findings, err := hook.ScanSecrets(dir, change)
+			if err != nil {
+				fmt.Fprintf(deps.stderr, "promptext %s: %v\n", name, err)
+				return 1
+			}
+			for _, finding := range findings {
+				fail("%s holds %d possible secrets (%s)", finding.Path, finding.Count, strings.Join(finding.Rules, ", "))
+			}
+		}
+		if settings.MaxTokens <= 0 && settings.MaxGrowth <= 0 {
+			continue
+		}
+
+		tokens, err := hookContextTokens(dir, change.Rev, settings.Profile)
+		if err != nil {
+			fmt.Fprintf(deps.stderr, "promptext %s: %v\n", name, err)
+			return 1
+		}
+		if settings.MaxTokens > 0 && tokens > settings.MaxTokens {
+			fail("after %s the context is %s tokens, over the %s-token limit (max-tokens)",
+				change.Desc, formatTokenCount(tokens), formatTokenCount(settings.MaxTokens))
+		}
+		if settings.MaxGrowth > 0 && change.Base != "" {
+			before, err := hookContextTokens(dir, change.Base, settings.Profile)
+			if err != nil {
+				fmt.Fprintf(deps.stderr, "promptext %s: %v\n", name, err)
+				return 1
+			}
+			if growth := tokens - before; growth > settings.MaxGrowth {
+				fail("%s grow the context by %s tokens, over the %s-token limit (max-growth)",
+					change.Desc, formatTokenCount(growth), formatTokenCount(settings.MaxGrowth))
+			}
+		}
+	}
+
+	if failed {
+		verb := "commit"
+		if name == hook.PrePush {
+			verb = "push"
+		}
+		fmt.Fprintf(deps.stderr, "Fix the problems above, adjust hooks.%s in .promptext.yml, or skip the checks with git %s --no-verify\n", name, verb)
+		return 1
+	}
+	return 0
+}
+
+// hookContextTokens returns the tokens of the project's context as
+// committed at rev
+func hookContextTokens(dir, rev, profile string) (int, error) {
+	opts := []promptext.Option{promptext.WithGitRef(rev), promptext.WithConfigFiles(true)}
+	if profile != "" {
+		opts = append(opts, promptext.WithProfile(profile))
+	}
+	result, err := promptext.Extract(dir, opts...)
+	if errors.Is(err, promptext.ErrNoFilesMatched) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return result.TokenCount, nil
+}
+
+// runServeCommand implements "promptext serve", exposing promptext to other
+// programs over MCP (stdio) or HTTP. Requested directories are resolved
+// against -d and may not escape it.
+func runServeCommand(args []string, deps cliDeps) int {
+	flagSet := pflag.NewFlagSet("promptext serve", pflag.ContinueOnError)
+	flagSet.SetOutput(deps.stderr)
+	dirPath := flagSet.StringP("directory", "d", ".", "Root directory the server may read")
+	useMCP := flagSet.Bool("mcp", false, "Serve the Model Context Protocol over stdin/stdout")
+	httpAddr := flagSet.String("http", "", "Serve the REST API on this address (e.g. :8080)")
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return 0
+		}
+		return flagError(deps, err, "Usage: promptext serve (--mcp | --http ADDR) [-d DIRECTORY]")
+	}
+
+	if *useMCP == (*httpAddr != "") {
+		fmt.Fprintln(deps.stderr, "Usage: promptext serve (--mcp | --http ADDR) [-d DIRECTORY]")
+		return 2
+	}
+
+	absPath, err := deps.absPath(*dirPath)
+	if err != nil {
+		fmt.Fprintf(deps.stderr, "Error resolving directory path: %v\n", err)
+		return 1
+	}
+
+	if *httpAddr != "" {
+		fmt.Fprintf(deps.stderr, "Serving %s on %s (POST /extract, GET /info, GET /formats)\n", absPath, *httpAddr)
+		if err := deps.listenAndServe(*httpAddr, httpapi.NewHandler(absPath)); err != nil {
+			fmt.Fprintf(deps.stderr, "Error serving HTTP: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	// stdout carries the protocol, so diagnostics must stay on stderr
+	server := mcp.NewServer(absPath, version)
+	if err := server.Serve(deps.stdin, deps.stdout); err != nil {
+		fmt.Fprintf(deps.stderr, "Error serving MCP: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listenAndServe runs the HTTP API until the listener fails
+func listenAndServe(addr string, handler http.Handler) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
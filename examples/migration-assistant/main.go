@@ -294,7 +294,9 @@ func extractDependencies(result *promptext.Result) []string {
 	deps := make([]string, 0)
 
 	if result.ProjectOutput.Metadata != nil {
-		deps = result.ProjectOutput.Metadata.Dependencies
+		for _, dep := range result.ProjectOutput.Metadata.Dependencies {
+			deps = append(deps, strings.TrimSpace(dep.Name+" "+dep.Version))
+		}
 	}
 
 	return deps
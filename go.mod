@@ -3,11 +3,13 @@ module github.com/1broseidon/promptext
 go 1.22.4
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/atotto/clipboard v0.1.4
 	github.com/jedib0t/go-pretty/v6 v6.6.5
 	github.com/pkoukk/tiktoken-go v0.1.7
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.8.4
+	golang.org/x/mod v0.17.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
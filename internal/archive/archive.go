@@ -0,0 +1,167 @@
+// Package archive opens zip and tar archives as read-only file systems, so a
+// project received as an archive can be processed without unpacking it to disk.
+// OpenGitRef does the same for a commit of a git repository.
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ErrUnsupported is returned by Open for data that is not a zip, tar, or
+// gzip-compressed tar archive
+var ErrUnsupported = errors.New("unsupported archive format (want zip, tar, or tar.gz)")
+
+// MaxSize is the most bytes Open reads: of the archive itself, of a
+// gzip-compressed tar once decompressed, and of the files of a zip archive
+// together. Past it, Open fails with ErrTooLarge rather than filling memory.
+const MaxSize = 1 << 30
+
+// ErrTooLarge is returned by Open for an archive larger than MaxSize
+var ErrTooLarge = errors.New("archive too large")
+
+// maxSize is MaxSize, lowered by tests
+var maxSize int64 = MaxSize
+
+// Open reads an archive from r and returns its contents as a file system.
+// The format is detected from the data, not a file name. When every entry
+// sits below one top-level directory, as in source archives downloaded from
+// GitHub, the file system is rooted at that directory and name is its name;
+// otherwise name is empty. Symlinks, entries with paths escaping the archive,
+// and macOS resource forks (__MACOSX/) are left out.
+func Open(r io.Reader) (fsys fs.FS, name string, err error) {
+	data, err := readLimited(r, maxSize)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading archive: %w", err)
+	}
+
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", fmt.Errorf("error reading gzip archive: %w", err)
+		}
+		if data, err = readLimited(gz, maxSize); err != nil {
+			return nil, "", fmt.Errorf("error reading gzip archive: %w", err)
+		}
+		if !isTar(data) {
+			return nil, "", ErrUnsupported
+		}
+	}
+
+	var mem *memFS
+	switch {
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")):
+		mem, err = readZip(data)
+	case isTar(data):
+		mem, err = readTar(data)
+	default:
+		return nil, "", ErrUnsupported
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Unwrap a single top-level directory
+	if root := mem.entries["."]; len(root.children) == 1 {
+		if top := root.children[0]; mem.entries[top].dir {
+			sub, err := fs.Sub(mem, top)
+			if err != nil {
+				return nil, "", err
+			}
+			return sub, top, nil
+		}
+	}
+	return mem, "", nil
+}
+
+// readLimited reads r to the end, failing with ErrTooLarge past limit bytes
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, maxSize)
+	}
+	return data, nil
+}
+
+// isTar reports whether data starts with a POSIX or GNU tar header
+func isTar(data []byte) bool {
+	return len(data) >= 262 && string(data[257:262]) == "ustar"
+}
+
+// readZip loads the files and directories of a zip archive
+func readZip(data []byte) (*memFS, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading zip archive: %w", err)
+	}
+
+	mem := newMemFS()
+	remaining := maxSize
+	for _, file := range zr.File {
+		info := file.FileInfo()
+		switch {
+		case info.IsDir():
+			mem.addDir(file.Name, info.ModTime())
+		case info.Mode().IsRegular():
+			rc, err := file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s from zip archive: %w", file.Name, err)
+			}
+			content, err := readLimited(rc, remaining)
+			rc.Close()
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s from zip archive: %w", file.Name, err)
+			}
+			remaining -= int64(len(content))
+			mem.addFile(file.Name, content, info.Mode(), info.ModTime())
+		}
+	}
+	return mem, nil
+}
+
+// readTar loads the regular files and directories of an uncompressed tar archive
+func readTar(data []byte) (*memFS, error) {
+	tr := tar.NewReader(bytes.NewReader(data))
+	mem := newMemFS()
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return mem, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading tar archive: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			mem.addDir(header.Name, header.ModTime)
+		case tar.TypeReg:
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("error reading %s from tar archive: %w", header.Name, err)
+			}
+			mem.addFile(header.Name, content, header.FileInfo().Mode(), header.ModTime)
+		}
+	}
+}
+
+// cleanName turns an archive entry name into an fs.FS path, or "" for names
+// to leave out
+func cleanName(name string) string {
+	name = path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, `\`, "/"), "/"))
+	if name == "." || !fs.ValidPath(name) || name == "__MACOSX" || strings.HasPrefix(name, "__MACOSX/") {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,102 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func zipArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func tarGzArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestOpenZip(t *testing.T) {
+	data := zipArchive(t, map[string]string{
+		"main.go":            "package main\n",
+		"internal/db/db.go":  "package db\n",
+		"../escape.go":       "package escape\n",
+		"__MACOSX/._main.go": "resource fork",
+	})
+
+	fsys, name, err := Open(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Empty(t, name, "two top-level entries keep the archive root")
+	require.NoError(t, fstest.TestFS(fsys, "main.go", "internal/db/db.go"))
+
+	content, err := fs.ReadFile(fsys, "internal/db/db.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package db\n", string(content))
+
+	entries, err := fs.ReadDir(fsys, ".")
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"internal", "main.go"}, names)
+}
+
+func TestOpenTarGzUnwrapsTopDirectory(t *testing.T) {
+	data := tarGzArchive(t, map[string]string{
+		"project-main/go.mod":      "module example.com/project\n",
+		"project-main/cmd/main.go": "package main\n",
+	})
+
+	fsys, name, err := Open(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, "project-main", name)
+	require.NoError(t, fstest.TestFS(fsys, "go.mod", "cmd/main.go"))
+}
+
+func TestOpenUnsupported(t *testing.T) {
+	_, _, err := Open(strings.NewReader("just some text, not an archive"))
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestOpenTooLarge(t *testing.T) {
+	defer func(size int64) { maxSize = size }(maxSize)
+	maxSize = 1024
+
+	// The archive itself, a tar.gz once decompressed, and the files of a zip
+	big := strings.Repeat("a", 2048)
+	_, _, err := Open(strings.NewReader(big))
+	assert.ErrorIs(t, err, ErrTooLarge)
+	_, _, err = Open(bytes.NewReader(tarGzArchive(t, map[string]string{"big.txt": big})))
+	assert.ErrorIs(t, err, ErrTooLarge)
+	_, _, err = Open(bytes.NewReader(zipArchive(t, map[string]string{"a.txt": big[:600], "b.txt": big[:600]})))
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, _, err = Open(bytes.NewReader(zipArchive(t, map[string]string{"a.txt": big[:300]})))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,53 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+// ErrUnknownRef is returned by OpenGitRef when ref names no commit in the
+// repository
+var ErrUnknownRef = errors.New("unknown git ref")
+
+// OpenGitRef returns the files committed at ref (a branch, tag, or commit) in
+// the git repository holding dir, as a file system rooted at dir. The working
+// tree is not read, so uncommitted changes and untracked files are left out.
+// It runs git archive, which also leaves out submodules and symlinks.
+func OpenGitRef(dir, ref string) (fs.FS, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("%w %q", ErrUnknownRef, ref)
+	}
+	if _, err := git(dir, "rev-parse", "--git-dir"); err != nil {
+		return nil, fmt.Errorf("error reading %s from git: %w", ref, err)
+	}
+	if _, err := git(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("%w %q", ErrUnknownRef, ref)
+	}
+
+	// Run from dir, git archive holds only that subdirectory of the repository
+	data, err := git(dir, "archive", "--format=tar", ref)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s from git: %w", ref, err)
+	}
+	return readTar(data)
+}
+
+// git runs a git command in dir and returns its standard output
+func git(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,87 @@
+package archive
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitRepo(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	run("init", "-q")
+	write("main.go", "package main // v1\n")
+	write("pkg/lib.go", "package pkg // v1\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "v1")
+	run("tag", "v1.0.0")
+
+	write("main.go", "package main // v2\n")
+	write("new.go", "package main\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "v2")
+	write("main.go", "package main // uncommitted\n")
+	return dir
+}
+
+func TestOpenGitRef(t *testing.T) {
+	dir := gitRepo(t)
+
+	fsys, err := OpenGitRef(dir, "v1.0.0")
+	require.NoError(t, err)
+	data, err := fs.ReadFile(fsys, "main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main // v1\n", string(data))
+	_, err = fs.Stat(fsys, "new.go")
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "new.go was added after v1.0.0")
+
+	fsys, err = OpenGitRef(dir, "HEAD")
+	require.NoError(t, err)
+	data, err = fs.ReadFile(fsys, "main.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package main // v2\n", string(data), "uncommitted changes are not read")
+}
+
+func TestOpenGitRefSubdirectory(t *testing.T) {
+	dir := gitRepo(t)
+
+	fsys, err := OpenGitRef(filepath.Join(dir, "pkg"), "v1.0.0")
+	require.NoError(t, err)
+	data, err := fs.ReadFile(fsys, "lib.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package pkg // v1\n", string(data))
+}
+
+func TestOpenGitRefUnknown(t *testing.T) {
+	dir := gitRepo(t)
+
+	for _, ref := range []string{"v9.9.9", "--output=x", ""} {
+		_, err := OpenGitRef(dir, ref)
+		assert.ErrorIs(t, err, ErrUnknownRef, ref)
+	}
+
+	_, err := OpenGitRef(t.TempDir(), "HEAD")
+	assert.ErrorContains(t, err, "not a git repository")
+}
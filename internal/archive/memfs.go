@@ -0,0 +1,171 @@
+package archive
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// memFS is a read-only in-memory file system holding an archive's entries
+type memFS struct {
+	entries map[string]*memEntry // Keyed by slash-separated path, "." for the root
+}
+
+// memEntry is a file or directory of a memFS
+type memEntry struct {
+	name     string
+	dir      bool
+	data     []byte
+	mode     fs.FileMode
+	modTime  time.Time
+	children []string // Paths of the entries in a directory, sorted
+}
+
+func newMemFS() *memFS {
+	return &memFS{entries: map[string]*memEntry{".": {name: ".", dir: true, mode: fs.ModeDir | 0755}}}
+}
+
+// addDir adds a directory, and any missing parents, to the file system
+func (m *memFS) addDir(name string, modTime time.Time) {
+	if name = cleanName(name); name != "" {
+		m.dir(name).modTime = modTime
+	}
+}
+
+// addFile adds a regular file, creating its parent directories. A later
+// entry with the same name replaces an earlier one, as when unpacking.
+func (m *memFS) addFile(name string, data []byte, mode fs.FileMode, modTime time.Time) {
+	name = cleanName(name)
+	if name == "" {
+		return
+	}
+	if existing, ok := m.entries[name]; ok && existing.dir {
+		return
+	}
+	if _, ok := m.entries[name]; !ok {
+		m.link(name)
+	}
+	m.entries[name] = &memEntry{name: path.Base(name), data: data, mode: mode.Perm() | 0444, modTime: modTime}
+}
+
+// dir returns the directory at name, creating it and its parents as needed
+func (m *memFS) dir(name string) *memEntry {
+	if entry, ok := m.entries[name]; ok && entry.dir {
+		return entry
+	}
+	if _, ok := m.entries[name]; !ok {
+		m.link(name)
+	}
+	entry := &memEntry{name: path.Base(name), dir: true, mode: fs.ModeDir | 0755}
+	m.entries[name] = entry
+	return entry
+}
+
+// link records name as a child of its parent directory, keeping children sorted
+func (m *memFS) link(name string) {
+	parent := m.dir(path.Dir(name))
+	i := sort.SearchStrings(parent.children, name)
+	parent.children = append(parent.children, "")
+	copy(parent.children[i+1:], parent.children[i:])
+	parent.children[i] = name
+}
+
+// Open implements fs.FS
+func (m *memFS) Open(name string) (fs.File, error) {
+	entry, err := m.lookup("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return &memFile{fsys: m, path: name, entry: entry, reader: bytes.NewReader(entry.data)}, nil
+}
+
+// ReadDir implements fs.ReadDirFS
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := m.lookup("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return m.dirEntries(entry.children), nil
+}
+
+// Stat implements fs.StatFS
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	entry, err := m.lookup("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return memInfo{entry}, nil
+}
+
+func (m *memFS) lookup(op, name string) (*memEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := m.entries[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return entry, nil
+}
+
+func (m *memFS) dirEntries(paths []string) []fs.DirEntry {
+	entries := make([]fs.DirEntry, len(paths))
+	for i, p := range paths {
+		entries[i] = fs.FileInfoToDirEntry(memInfo{m.entries[p]})
+	}
+	return entries
+}
+
+// memFile is an open file or directory of a memFS
+type memFile struct {
+	fsys   *memFS
+	path   string
+	entry  *memEntry
+	reader *bytes.Reader
+	read   int // Directory entries returned by ReadDir so far
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return memInfo{f.entry}, nil }
+
+func (f *memFile) Close() error { return nil }
+
+func (f *memFile) Read(b []byte) (int, error) {
+	if f.entry.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrInvalid}
+	}
+	return f.reader.Read(b)
+}
+
+// ReadDir implements fs.ReadDirFile
+func (f *memFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !f.entry.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: f.path, Err: fs.ErrInvalid}
+	}
+	remaining := f.entry.children[f.read:]
+	if n > 0 {
+		if len(remaining) == 0 {
+			return nil, io.EOF
+		}
+		if n < len(remaining) {
+			remaining = remaining[:n]
+		}
+	}
+	f.read += len(remaining)
+	return f.fsys.dirEntries(remaining), nil
+}
+
+// memInfo describes a memEntry
+type memInfo struct{ entry *memEntry }
+
+func (i memInfo) Name() string       { return i.entry.name }
+func (i memInfo) Size() int64        { return int64(len(i.entry.data)) }
+func (i memInfo) Mode() fs.FileMode  { return i.entry.mode }
+func (i memInfo) ModTime() time.Time { return i.entry.modTime }
+func (i memInfo) IsDir() bool        { return i.entry.dir }
+func (i memInfo) Sys() any           { return nil }
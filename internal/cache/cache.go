@@ -0,0 +1,174 @@
+// Package cache persists processed file contents and token counts between runs,
+// so unchanged files are neither re-read nor re-tokenized.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DirName is the name of a cache directory kept inside a project. Walks skip
+// directories of this name whatever the filter rules say, so a cache is never
+// read back as project content.
+const DirName = ".promptext-cache"
+
+// UserDir returns the cache directory of the project at root under the user's
+// cache directory (os.UserCacheDir), named after root and a hash of its path
+func UserDir(root string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(base, "promptext", filepath.Base(root)+"-"+hex.EncodeToString(sum[:6])), nil
+}
+
+// indexFile holds all entries of a cache directory
+const indexFile = "files.json"
+
+// formatVersion is bumped whenever the on-disk layout changes; caches written
+// by another version are discarded
+const formatVersion = 2
+
+// Entry is the cached result of processing one file
+type Entry struct {
+	Size       int64          `json:"size"`
+	ModTime    int64          `json:"mtime"`   // Unix nanoseconds
+	Variant    string         `json:"variant"` // Processing settings the entry was produced with
+	Content    string         `json:"content"`
+	Tokens     int            `json:"tokens"`
+	Imports    []string       `json:"imports,omitempty"`
+	Redactions map[string]int `json:"redactions,omitempty"` // Secrets replaced per redaction rule
+	Compaction string         `json:"compaction,omitempty"` // Compaction mode that changed the content
+	Sample     []int          `json:"sample,omitempty"`     // Rows kept, total rows and columns of a sampled data file
+	License    string         `json:"license,omitempty"`    // SPDX expression of the file's license header
+	Copyright  string         `json:"copyright,omitempty"`  // Copyright notice in the file's header
+	Hash       string         `json:"hash"`                 // SHA-256 of the file as read, before transforms
+}
+
+// Cache maps project-relative file paths to processed entries. An entry is
+// only returned while the file's size and modification time are unchanged and
+// it was produced with the same processing variant (tokenizer, transforms).
+type Cache struct {
+	mu      sync.Mutex
+	dir     string
+	root    string
+	entries map[string]Entry
+	seen    map[string]bool
+	dirty   bool
+}
+
+type index struct {
+	Version int              `json:"version"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// Open loads the cache stored in dir for the project at root. A missing or
+// unreadable index starts an empty cache; the directory is created on Save.
+func Open(dir, root string) *Cache {
+	c := &Cache{
+		dir:     dir,
+		root:    root,
+		entries: make(map[string]Entry),
+		seen:    make(map[string]bool),
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, indexFile))
+	if err != nil {
+		return c
+	}
+	var idx index
+	if json.Unmarshal(data, &idx) == nil && idx.Version == formatVersion && idx.Entries != nil {
+		c.entries = idx.Entries
+	}
+	return c
+}
+
+// Dir returns the directory the cache is stored in
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Get returns the entry for relPath if the file is unchanged since it was
+// cached and the entry was produced with the given variant
+func (c *Cache) Get(relPath string, info os.FileInfo, variant string) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seen[relPath] = true
+	entry, ok := c.entries[relPath]
+	if !ok || entry.Variant != variant || entry.Size != info.Size() || entry.ModTime != info.ModTime().UnixNano() {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Put stores the processed result for relPath, stamped with the file's
+// current size and modification time
+func (c *Cache) Put(relPath string, info os.FileInfo, entry Entry) {
+	entry.Size = info.Size()
+	entry.ModTime = info.ModTime().UnixNano()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen[relPath] = true
+	c.entries[relPath] = entry
+	c.dirty = true
+}
+
+// Save writes the cache to disk if anything changed. Entries for files that
+// were not visited and no longer exist are dropped.
+func (c *Cache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for relPath := range c.entries {
+		if c.seen[relPath] {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(c.root, relPath)); os.IsNotExist(err) {
+			delete(c.entries, relPath)
+			c.dirty = true
+		}
+	}
+	if !c.dirty {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	// Keep the cache out of version control without touching the project's .gitignore
+	gitignore := filepath.Join(c.dir, ".gitignore")
+	if _, err := os.Stat(gitignore); os.IsNotExist(err) {
+		_ = os.WriteFile(gitignore, []byte("*\n"), 0644)
+	}
+
+	data, err := json.Marshal(index{Version: formatVersion, Entries: c.entries})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+	// Write to a temp file and rename so an interrupted run never leaves a torn index
+	tmp := filepath.Join(c.dir, indexFile+".tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(c.dir, indexFile)); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	c.dirty = false
+	return nil
+}
+
+// Clear removes the cache directory and everything in it
+func Clear(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
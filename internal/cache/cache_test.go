@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, DirName)
+	file := filepath.Join(root, "main.go")
+	require.NoError(t, os.WriteFile(file, []byte("package main\n"), 0644))
+	stat, err := os.Stat(file)
+	require.NoError(t, err)
+
+	c := Open(dir, root)
+	_, ok := c.Get("main.go", stat, "v")
+	assert.False(t, ok, "empty cache should miss")
+
+	c.Put("main.go", stat, Entry{Variant: "v", Content: "package main\n", Tokens: 3})
+	require.NoError(t, c.Save())
+	assert.FileExists(t, filepath.Join(dir, ".gitignore"))
+
+	reopened := Open(dir, root)
+	entry, ok := reopened.Get("main.go", stat, "v")
+	require.True(t, ok)
+	assert.Equal(t, "package main\n", entry.Content)
+	assert.Equal(t, 3, entry.Tokens)
+
+	_, ok = reopened.Get("main.go", stat, "other")
+	assert.False(t, ok, "a different variant should miss")
+
+	// Touching the file invalidates the entry
+	later := stat.ModTime().Add(time.Second)
+	require.NoError(t, os.Chtimes(file, later, later))
+	touched, err := os.Stat(file)
+	require.NoError(t, err)
+	_, ok = reopened.Get("main.go", touched, "v")
+	assert.False(t, ok, "a changed mtime should miss")
+}
+
+func TestCacheSaveDropsDeletedFiles(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, DirName)
+	file := filepath.Join(root, "gone.go")
+	require.NoError(t, os.WriteFile(file, []byte("package gone\n"), 0644))
+	stat, err := os.Stat(file)
+	require.NoError(t, err)
+
+	c := Open(dir, root)
+	c.Put("gone.go", stat, Entry{Variant: "v", Content: "package gone\n"})
+	require.NoError(t, c.Save())
+	require.NoError(t, os.Remove(file))
+
+	// A run that never visits the file prunes it
+	require.NoError(t, Open(dir, root).Save())
+	assert.Empty(t, Open(dir, root).entries)
+}
+
+func TestClear(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, DirName)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, indexFile), []byte("{}"), 0644))
+
+	require.NoError(t, Clear(dir))
+	assert.NoDirExists(t, dir)
+	assert.NoError(t, Clear(dir), "clearing a missing cache is not an error")
+}
+
+func TestUserDir(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	a, err := UserDir("/work/app")
+	require.NoError(t, err)
+	b, err := UserDir("/other/app")
+	require.NoError(t, err)
+	assert.Contains(t, filepath.Base(a), "app-")
+	assert.NotEqual(t, a, b, "projects of the same name get their own caches")
+}
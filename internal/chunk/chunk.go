@@ -0,0 +1,69 @@
+// Package chunk splits file content into overlapping, line-aligned windows
+// sized in tokens, for embedding and retrieval pipelines.
+package chunk
+
+import "strings"
+
+// Window is one chunk of a file's content
+type Window struct {
+	StartLine int // 1-based, inclusive
+	EndLine   int // 1-based, inclusive
+	Content   string
+	Tokens    int
+}
+
+// Split cuts content into windows of at most maxTokens tokens, as measured by
+// count. Windows break only between lines, and each window after the first
+// repeats up to overlap tokens of trailing lines from the one before it. A
+// single line longer than maxTokens becomes a window of its own.
+//
+// overlap is clamped to [0, maxTokens/2] so every window advances. Split
+// returns nil when maxTokens is not positive or content is empty.
+func Split(content string, maxTokens, overlap int, count func(string) int) []Window {
+	if maxTokens <= 0 || content == "" {
+		return nil
+	}
+	if overlap < 0 {
+		overlap = 0
+	}
+	if overlap > maxTokens/2 {
+		overlap = maxTokens / 2
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	costs := make([]int, len(lines))
+	for i, line := range lines {
+		costs[i] = count(line)
+	}
+
+	var windows []Window
+	for start := 0; start < len(lines); {
+		end, tokens := start, 0
+		for end < len(lines) && (end == start || tokens+costs[end] <= maxTokens) {
+			tokens += costs[end]
+			end++
+		}
+		windows = append(windows, Window{
+			StartLine: start + 1,
+			EndLine:   end,
+			Content:   strings.Join(lines[start:end], ""),
+			Tokens:    tokens,
+		})
+		if end == len(lines) {
+			break
+		}
+
+		// Back up over trailing lines that fit in the overlap, but always
+		// move past the previous start
+		next, kept := end, 0
+		for next-1 > start && kept+costs[next-1] <= overlap {
+			next--
+			kept += costs[next]
+		}
+		start = next
+	}
+	return windows
+}
@@ -0,0 +1,61 @@
+package chunk
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// words counts whitespace-separated words, giving predictable token costs
+func words(s string) int {
+	return len(strings.Fields(s))
+}
+
+func numberedLines(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestSplitWithoutOverlap(t *testing.T) {
+	windows := Split(numberedLines(5), 4, 0, words)
+	require.Len(t, windows, 3)
+
+	assert.Equal(t, Window{StartLine: 1, EndLine: 2, Content: "line 1\nline 2\n", Tokens: 4}, windows[0])
+	assert.Equal(t, 3, windows[1].StartLine)
+	assert.Equal(t, 4, windows[1].EndLine)
+	assert.Equal(t, Window{StartLine: 5, EndLine: 5, Content: "line 5\n", Tokens: 2}, windows[2])
+}
+
+func TestSplitWithOverlap(t *testing.T) {
+	windows := Split(numberedLines(6), 6, 2, words)
+	require.Len(t, windows, 3)
+
+	// Each window repeats the last line of the previous one
+	assert.Equal(t, [2]int{1, 3}, [2]int{windows[0].StartLine, windows[0].EndLine})
+	assert.Equal(t, [2]int{3, 5}, [2]int{windows[1].StartLine, windows[1].EndLine})
+	assert.Equal(t, [2]int{5, 6}, [2]int{windows[2].StartLine, windows[2].EndLine})
+	for _, w := range windows {
+		assert.LessOrEqual(t, w.Tokens, 6)
+	}
+}
+
+func TestSplitLongLineAndEdgeCases(t *testing.T) {
+	content := "short\n" + strings.Repeat("word ", 10) + "\nend"
+	windows := Split(content, 3, 1, words)
+	require.Len(t, windows, 3)
+	assert.Equal(t, 10, windows[1].Tokens, "an oversized line is its own window")
+	assert.Equal(t, "end", windows[2].Content, "a final line without newline is kept")
+
+	assert.Nil(t, Split("", 10, 0, words))
+	assert.Nil(t, Split("a\n", 0, 0, words))
+
+	// An overlap as large as the window still advances
+	windows = Split(numberedLines(4), 4, 100, words)
+	assert.Equal(t, 4, windows[len(windows)-1].EndLine)
+}
@@ -0,0 +1,141 @@
+// Package clipboard copies output to the clipboard through whichever backend
+// works where promptext runs: the native clipboard, wl-copy on Wayland,
+// xclip or xsel on X11, or the OSC 52 terminal escape sequence, which reaches
+// the local clipboard through SSH sessions.
+package clipboard
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	system "github.com/atotto/clipboard"
+)
+
+// Backends accepted by Write
+const (
+	BackendAuto   = "auto"   // Pick one from the environment (see Detect)
+	BackendSystem = "system" // The native clipboard: pbcopy, clip.exe, xclip/xsel, termux
+	BackendWlCopy = "wl-copy"
+	BackendXclip  = "xclip"
+	BackendXsel   = "xsel"
+	BackendOSC52  = "osc52" // Terminal escape sequence, for SSH and headless sessions
+)
+
+// Backends lists the backend names Write accepts
+var Backends = []string{BackendAuto, BackendSystem, BackendWlCopy, BackendXclip, BackendXsel, BackendOSC52}
+
+// DefaultMaxBytes is the largest output promptext copies unless told
+// otherwise; some clipboard managers hang or crash on larger payloads
+const DefaultMaxBytes = 2 << 20
+
+// ErrUnknownBackend is returned for a backend name not in Backends
+var ErrUnknownBackend = errors.New("unknown clipboard backend")
+
+// Indirections replaced in tests
+var (
+	getenv     = os.Getenv
+	lookPath   = exec.LookPath
+	runCommand = func(text string, name string, args ...string) error {
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	writeSystem = system.WriteAll
+	openTTY     = func() (io.WriteCloser, error) { return os.OpenFile("/dev/tty", os.O_WRONLY, 0) }
+)
+
+// Write copies text with backend, returning the backend that did the copy.
+// In auto mode the backends Detect picks are tried in order, falling back to
+// OSC 52 when none of them works.
+func Write(backend, text string) (string, error) {
+	if backend == "" || backend == BackendAuto {
+		var errs []error
+		for _, candidate := range Detect() {
+			err := write(candidate, text)
+			if err == nil {
+				return candidate, nil
+			}
+			errs = append(errs, err)
+		}
+		return "", errors.Join(errs...)
+	}
+
+	for _, known := range Backends {
+		if backend == known {
+			return backend, write(backend, text)
+		}
+	}
+	return "", fmt.Errorf("%w %q (use %s)", ErrUnknownBackend, backend, strings.Join(Backends, ", "))
+}
+
+// Detect returns the backends auto mode tries, in order. Over SSH without a
+// forwarded display only the terminal can reach the user's clipboard, so OSC
+// 52 comes first; otherwise wl-copy (Wayland) and xclip or xsel (X11) are
+// preferred when installed, then the native clipboard, with OSC 52 last.
+func Detect() []string {
+	if (getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != "") && getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == "" {
+		return []string{BackendOSC52}
+	}
+
+	var backends []string
+	if getenv("WAYLAND_DISPLAY") != "" && installed(BackendWlCopy) {
+		backends = append(backends, BackendWlCopy)
+	}
+	if getenv("DISPLAY") != "" {
+		if installed(BackendXclip) {
+			backends = append(backends, BackendXclip)
+		} else if installed(BackendXsel) {
+			backends = append(backends, BackendXsel)
+		}
+	}
+	return append(backends, BackendSystem, BackendOSC52)
+}
+
+func installed(command string) bool {
+	_, err := lookPath(command)
+	return err == nil
+}
+
+func write(backend, text string) error {
+	switch backend {
+	case BackendSystem:
+		return writeSystem(text)
+	case BackendWlCopy:
+		return runCommand(text, "wl-copy")
+	case BackendXclip:
+		return runCommand(text, "xclip", "-in", "-selection", "clipboard")
+	case BackendXsel:
+		return runCommand(text, "xsel", "--input", "--clipboard")
+	case BackendOSC52:
+		tty, err := openTTY()
+		if err != nil {
+			return fmt.Errorf("osc52: no terminal: %w", err)
+		}
+		defer tty.Close()
+		_, err = io.WriteString(tty, osc52(text, getenv("TMUX") != "", strings.HasPrefix(getenv("TERM"), "screen")))
+		return err
+	}
+	return fmt.Errorf("%w %q", ErrUnknownBackend, backend)
+}
+
+// osc52 builds the escape sequence that sets the terminal's clipboard to
+// text, wrapped in a passthrough sequence for tmux or screen so it reaches
+// the outer terminal
+func osc52(text string, tmux, screen bool) string {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	switch {
+	case tmux:
+		return "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case screen:
+		return "\x1bP" + sequence + "\x1b\\"
+	}
+	return sequence
+}
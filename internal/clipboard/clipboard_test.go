@@ -0,0 +1,117 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopCloser adapts a buffer to the tty writer
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+// fakeEnv replaces the environment, installed commands, and backends, and
+// records which backends were run
+func fakeEnv(t *testing.T, env map[string]string, commands ...string) (*[]string, *bytes.Buffer) {
+	t.Helper()
+	oldGetenv, oldLookPath, oldRun, oldSystem, oldTTY := getenv, lookPath, runCommand, writeSystem, openTTY
+	t.Cleanup(func() {
+		getenv, lookPath, runCommand, writeSystem, openTTY = oldGetenv, oldLookPath, oldRun, oldSystem, oldTTY
+	})
+
+	var ran []string
+	tty := &bytes.Buffer{}
+	getenv = func(key string) string { return env[key] }
+	lookPath = func(name string) (string, error) {
+		for _, command := range commands {
+			if command == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	runCommand = func(text, name string, args ...string) error {
+		ran = append(ran, name)
+		return nil
+	}
+	writeSystem = func(string) error {
+		ran = append(ran, BackendSystem)
+		return errors.New("no clipboard utilities available")
+	}
+	openTTY = func() (io.WriteCloser, error) { return nopCloser{tty}, nil }
+	return &ran, tty
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		commands []string
+		want     []string
+	}{
+		{"ssh", map[string]string{"SSH_TTY": "/dev/pts/1"}, []string{"xclip"}, []string{BackendOSC52}},
+		{"ssh with forwarded X", map[string]string{"SSH_CONNECTION": "1.2.3.4 5 6.7.8.9 22", "DISPLAY": "localhost:10.0"}, []string{"xclip"}, []string{BackendXclip, BackendSystem, BackendOSC52}},
+		{"wayland", map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}, []string{"wl-copy", "xsel"}, []string{BackendWlCopy, BackendXsel, BackendSystem, BackendOSC52}},
+		{"wayland without wl-copy", map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, nil, []string{BackendSystem, BackendOSC52}},
+		{"desktop", map[string]string{}, nil, []string{BackendSystem, BackendOSC52}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeEnv(t, tt.env, tt.commands...)
+			assert.Equal(t, tt.want, Detect())
+		})
+	}
+}
+
+func TestWriteAuto(t *testing.T) {
+	ran, _ := fakeEnv(t, map[string]string{"WAYLAND_DISPLAY": "wayland-0"}, "wl-copy")
+	backend, err := Write(BackendAuto, "context")
+	require.NoError(t, err)
+	assert.Equal(t, BackendWlCopy, backend)
+	assert.Equal(t, []string{"wl-copy"}, *ran)
+
+	// When the native clipboard fails, auto mode falls back to OSC 52
+	ran, tty := fakeEnv(t, map[string]string{})
+	backend, err = Write("", "context")
+	require.NoError(t, err)
+	assert.Equal(t, BackendOSC52, backend)
+	assert.Equal(t, []string{BackendSystem}, *ran)
+	assert.Equal(t, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte("context"))+"\a", tty.String())
+}
+
+func TestWriteBackend(t *testing.T) {
+	ran, _ := fakeEnv(t, map[string]string{})
+	backend, err := Write(BackendXclip, "context")
+	require.NoError(t, err)
+	assert.Equal(t, BackendXclip, backend)
+	assert.Equal(t, []string{"xclip"}, *ran)
+
+	_, err = Write(BackendSystem, "context")
+	assert.Error(t, err, "an explicit backend does not fall back")
+
+	_, err = Write("pbcopy", "context")
+	assert.ErrorIs(t, err, ErrUnknownBackend)
+
+	openTTY = func() (io.WriteCloser, error) { return nil, errors.New("no such device") }
+	_, err = Write(BackendOSC52, "context")
+	assert.ErrorContains(t, err, "no terminal")
+}
+
+func TestOSC52(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte("hi"))
+	assert.Equal(t, "\x1b]52;c;"+encoded+"\a", osc52("hi", false, false))
+	assert.Equal(t, "\x1bPtmux;\x1b\x1b]52;c;"+encoded+"\a\x1b\\", osc52("hi", true, true))
+	assert.Equal(t, "\x1bP\x1b]52;c;"+encoded+"\a\x1b\\", osc52("hi", false, true))
+
+	_, tty := fakeEnv(t, map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"})
+	_, err := Write(BackendOSC52, "hi")
+	require.NoError(t, err)
+	assert.Equal(t, osc52("hi", true, false), tty.String())
+}
@@ -0,0 +1,133 @@
+// Package codeowners reads CODEOWNERS files in the GitHub and GitLab formats
+// and looks up the owners of a path.
+package codeowners
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"github.com/1broseidon/promptext/internal/filter/types"
+)
+
+// Paths are the places GitHub and GitLab look for a CODEOWNERS file,
+// relative to the repository root, in the order they are tried
+var Paths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// sectionHeader matches a GitLab section line such as "[Docs]",
+// "^[Optional]" or "[Reviewers][2] @team", capturing the name and the
+// section's default owners
+var sectionHeader = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?(.*)$`)
+
+// File is a parsed CODEOWNERS file
+type File struct {
+	sections []section
+}
+
+// section is the rules under one GitLab section header; rules before any
+// header, and every rule of a GitHub file, are in a section without a name
+type section struct {
+	name     string
+	defaults []string
+	rules    []rule
+}
+
+// rule is a pattern line with its owners; a rule without owners marks the
+// matching paths as unowned
+type rule struct {
+	match  types.Rule
+	owners []string
+}
+
+// Parse parses the content of a CODEOWNERS file, ignoring blank lines and
+// comments
+func Parse(content string) *File {
+	f := &File{sections: []section{{}}}
+	for _, line := range strings.Split(content, "\n") {
+		line = stripComment(line)
+		if line == "" {
+			continue
+		}
+
+		if m := sectionHeader.FindStringSubmatch(line); m != nil {
+			f.sections = append(f.sections, section{
+				name:     strings.TrimSpace(m[1]),
+				defaults: owners(strings.Fields(m[2])),
+			})
+			continue
+		}
+
+		fields := strings.Fields(line)
+		current := &f.sections[len(f.sections)-1]
+		current.rules = append(current.rules, rule{
+			match:  matcher(fields[0]),
+			owners: owners(fields[1:]),
+		})
+	}
+	return f
+}
+
+// stripComment removes a "#" comment from line, unless the "#" is escaped,
+// and trims the rest
+func stripComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '#' && (i == 0 || line[i-1] != '\\') {
+			line = line[:i]
+			break
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// owners keeps the fields that name an owner: "@user", "@org/team" or an
+// email address
+func owners(fields []string) []string {
+	var found []string
+	for _, field := range fields {
+		if strings.Contains(field, "@") {
+			found = append(found, field)
+		}
+	}
+	return found
+}
+
+// matcher compiles a CODEOWNERS pattern. Patterns follow .gitignore rules,
+// except that a trailing "/*" matches the files directly in the directory
+// but not those in its subdirectories.
+func matcher(pattern string) types.Rule {
+	patterns := []string{pattern}
+	if strings.HasSuffix(pattern, "/*") {
+		patterns = append(patterns, "!"+pattern+"/*")
+	}
+	return rules.NewGitPatternRule(patterns, types.Include)
+}
+
+// Owners returns the owners of path, a slash-separated path relative to the
+// repository root. In each section the last matching rule wins, and a rule
+// without owners in a GitLab section falls back to the section's default
+// owners. The owners of all sections are combined in order; nil means the
+// path is unowned.
+func (f *File) Owners(path string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, s := range f.sections {
+		for i := len(s.rules) - 1; i >= 0; i-- {
+			r := s.rules[i]
+			if !r.match.Match(path) {
+				continue
+			}
+			found := r.owners
+			if len(found) == 0 && s.name != "" {
+				found = s.defaults
+			}
+			for _, owner := range found {
+				if !seen[owner] {
+					seen[owner] = true
+					result = append(result, owner)
+				}
+			}
+			break
+		}
+	}
+	return result
+}
@@ -0,0 +1,62 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnersGitHub(t *testing.T) {
+	f := Parse(`# Default owners
+*                @org/core
+
+*.js             @org/frontend   # inline comment
+/docs/           docs@example.com
+apps/            @org/apps
+/scripts/*       @ops
+/vendor/
+/build/logs/     @infra @ops
+\#notes.txt      @notes
+`)
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"main.go", []string{"@org/core"}},
+		{"web/app.js", []string{"@org/frontend"}},
+		{"docs/guide/intro.md", []string{"docs@example.com"}},
+		{"src/apps/x.go", []string{"@org/apps"}},
+		{"scripts/deploy.sh", []string{"@ops"}},
+		{"scripts/lib/util.sh", []string{"@org/core"}},
+		{"vendor/lib.go", nil},
+		{"build/logs/today.log", []string{"@infra", "@ops"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, f.Owners(tt.path))
+		})
+	}
+}
+
+func TestOwnersGitLabSections(t *testing.T) {
+	f := Parse(`* @default
+
+[Docs] @docs-team
+*.md
+/README.md @lead
+
+^[Optional][2] @reviewers
+/internal/
+`)
+
+	assert.Equal(t, []string{"@default", "@docs-team"}, f.Owners("guide.md"))
+	assert.Equal(t, []string{"@default", "@lead"}, f.Owners("README.md"))
+	assert.Equal(t, []string{"@default", "@docs-team", "@reviewers"}, f.Owners("internal/notes.md"))
+	assert.Equal(t, []string{"@default"}, f.Owners("main.go"))
+}
+
+func TestOwnersEmpty(t *testing.T) {
+	assert.Nil(t, Parse("").Owners("main.go"))
+	assert.Nil(t, Parse("# only comments\n").Owners("main.go"))
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/1broseidon/promptext/internal/log"
@@ -18,6 +21,152 @@ type FileConfig struct {
 	Debug           *bool    `yaml:"debug"`             // Use pointer to distinguish nil (unset) from false
 	GitIgnore       *bool    `yaml:"gitignore"`         // Use .gitignore patterns
 	UseDefaultRules *bool    `yaml:"use-default-rules"` // Use default filtering rules (true by default)
+	CoreDirs        []string `yaml:"core-dirs"`         // Directories holding core code (replaces the defaults)
+	EntryPoints     []string `yaml:"entry-points"`      // Globs naming the files that start the project (replaces the defaults)
+
+	// BudgetWeights splits the token budget by directory ("internal/") or
+	// extension (".md"), e.g. budget-weights: {internal/: 0.6, docs/: 0.1}
+	BudgetWeights map[string]float64 `yaml:"budget-weights"`
+
+	// Annotations attach notes to the files matching gitignore-style globs,
+	// rendered with each file, e.g. annotations: {internal/legacy/**: Deprecated}
+	Annotations map[string]string `yaml:"annotations"`
+
+	// Relevance tunes keyword matching, e.g.
+	// relevance: {synonyms: {auth: [sso, saml]}}
+	Relevance RelevanceConfig `yaml:"relevance"`
+
+	// Hooks configure the checks of the git hooks prx hook install sets up,
+	// e.g. hooks: {pre-commit: {max-growth: 5000}}. Only the project config
+	// and not its profiles sets them.
+	Hooks HooksConfig `yaml:"hooks"`
+
+	// Profiles are named sets of overrides selected with --profile, e.g.
+	// profiles: {review: {...}, docs: {...}}
+	Profiles map[string]*FileConfig `yaml:"profiles"`
+}
+
+// HooksConfig holds the hooks settings of .promptext.yml, one per git hook
+type HooksConfig struct {
+	PreCommit *HookConfig `yaml:"pre-commit" json:"pre-commit,omitempty"`
+	PrePush   *HookConfig `yaml:"pre-push" json:"pre-push,omitempty"`
+}
+
+// HookConfig holds the checks one git hook runs on the change it is about
+// to let through
+type HookConfig struct {
+	Secrets   *bool  `yaml:"secrets" json:"secrets,omitempty"`       // Fail when changed files hold secrets (default: true)
+	MaxTokens int    `yaml:"max-tokens" json:"max-tokens,omitempty"` // Fail when the context is over this many tokens
+	MaxGrowth int    `yaml:"max-growth" json:"max-growth,omitempty"` // Fail when the change grows the context by more than this many tokens
+	Profile   string `yaml:"profile" json:"profile,omitempty"`       // Profile the context is extracted with
+}
+
+// HookNames lists the git hooks the hooks settings configure
+var HookNames = []string{"pre-commit", "pre-push"}
+
+// Hook returns the settings of the named git hook, or the defaults when the
+// config leaves it unset
+func (h HooksConfig) Hook(name string) HookConfig {
+	var hook *HookConfig
+	switch name {
+	case "pre-commit":
+		hook = h.PreCommit
+	case "pre-push":
+		hook = h.PrePush
+	}
+	if hook == nil {
+		return HookConfig{}
+	}
+	return *hook
+}
+
+// Configured lists the git hooks the config has settings for
+func (h HooksConfig) Configured() []string {
+	var names []string
+	if h.PreCommit != nil {
+		names = append(names, "pre-commit")
+	}
+	if h.PrePush != nil {
+		names = append(names, "pre-push")
+	}
+	return names
+}
+
+// ScanSecrets reports whether the hook fails on secrets in changed files
+func (h HookConfig) ScanSecrets() bool {
+	return h.Secrets == nil || *h.Secrets
+}
+
+// RelevanceConfig holds the relevance settings of .promptext.yml
+type RelevanceConfig struct {
+	// Synonyms are extra words each keyword also matches, added to the
+	// built-in ones
+	Synonyms map[string][]string `yaml:"synonyms" json:"synonyms"`
+}
+
+// ErrUnknownProfile is returned when a requested profile is not defined
+var ErrUnknownProfile = errors.New("unknown config profile")
+
+// FindProfile returns the named profile from the first config that defines it.
+// Pass the project config before the global config so project profiles win.
+func FindProfile(name string, configs ...*FileConfig) (*FileConfig, error) {
+	var defined []string
+	for _, cfg := range configs {
+		if cfg == nil {
+			continue
+		}
+		if profile, ok := cfg.Profiles[name]; ok && profile != nil {
+			return profile, nil
+		}
+		for profileName := range cfg.Profiles {
+			defined = append(defined, profileName)
+		}
+	}
+
+	defined = mergeAndDedupe(defined)
+	sort.Strings(defined)
+	if len(defined) == 0 {
+		return nil, fmt.Errorf("%w %q: no profiles defined", ErrUnknownProfile, name)
+	}
+	return nil, fmt.Errorf("%w %q (defined: %s)", ErrUnknownProfile, name, strings.Join(defined, ", "))
+}
+
+// Overlay returns a copy of fc with the settings of other applied on top, the
+// same way a project config overrides the global config: settings other
+// defines replace fc's, except excludes, which are merged.
+func (fc *FileConfig) Overlay(other *FileConfig) *FileConfig {
+	merged := *fc
+	merged.Excludes = mergeAndDedupe(fc.Excludes, other.Excludes)
+	if len(other.Extensions) > 0 {
+		merged.Extensions = other.Extensions
+	}
+	if other.Verbose != nil {
+		merged.Verbose = other.Verbose
+	}
+	if other.Format != "" {
+		merged.Format = other.Format
+	}
+	if other.Debug != nil {
+		merged.Debug = other.Debug
+	}
+	if other.GitIgnore != nil {
+		merged.GitIgnore = other.GitIgnore
+	}
+	if other.UseDefaultRules != nil {
+		merged.UseDefaultRules = other.UseDefaultRules
+	}
+	if len(other.CoreDirs) > 0 {
+		merged.CoreDirs = other.CoreDirs
+	}
+	if len(other.EntryPoints) > 0 {
+		merged.EntryPoints = other.EntryPoints
+	}
+	if len(other.BudgetWeights) > 0 {
+		merged.BudgetWeights = other.BudgetWeights
+	}
+	merged.Annotations = MergeAnnotations(fc, other)
+	merged.Relevance.Synonyms = MergeSynonyms(fc, other)
+	return &merged
 }
 
 // getGlobalConfigPaths returns potential global config file paths in order of preference
@@ -63,6 +212,7 @@ func LoadGlobalConfig() (*FileConfig, error) {
 		if err := yaml.Unmarshal(data, &config); err != nil {
 			return nil, err
 		}
+		warnProblems(configPath, data)
 
 		return &config, nil
 	}
@@ -71,6 +221,14 @@ func LoadGlobalConfig() (*FileConfig, error) {
 	return &FileConfig{}, nil
 }
 
+// warnProblems reports what Validate finds in a config file being loaded, so
+// typos are not silently ignored
+func warnProblems(configPath string, data []byte) {
+	for _, problem := range Validate(data) {
+		log.Warn("%s: %s", configPath, problem)
+	}
+}
+
 // LoadConfig attempts to load and parse the .promptext.yml file
 func LoadConfig(dirPath string) (*FileConfig, error) {
 	configPath := filepath.Join(dirPath, ".promptext.yml")
@@ -89,6 +247,7 @@ func LoadConfig(dirPath string) (*FileConfig, error) {
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	warnProblems(configPath, data)
 
 	return &config, nil
 }
@@ -138,6 +297,79 @@ func MergeConfigs(globalConfig, projectConfig *FileConfig, flagExt, flagExclude
 	return extensions, excludes, verbose, debug, useGitIgnore, useDefaultRules
 }
 
+// MergeCoreDirs returns the configured core directories, preferring the project
+// config over the global config. Nil means the built-in defaults apply.
+func MergeCoreDirs(globalConfig, projectConfig *FileConfig) []string {
+	if len(projectConfig.CoreDirs) > 0 {
+		return projectConfig.CoreDirs
+	}
+	if len(globalConfig.CoreDirs) > 0 {
+		return globalConfig.CoreDirs
+	}
+	return nil
+}
+
+// MergeEntryPoints returns the configured entry point globs, preferring the
+// project config over the global config. Nil means the built-in defaults apply.
+func MergeEntryPoints(globalConfig, projectConfig *FileConfig) []string {
+	if len(projectConfig.EntryPoints) > 0 {
+		return projectConfig.EntryPoints
+	}
+	if len(globalConfig.EntryPoints) > 0 {
+		return globalConfig.EntryPoints
+	}
+	return nil
+}
+
+// MergeBudgetWeights returns the configured budget weights, preferring the
+// project config over the global config. The two are not combined: a project
+// that sets weights replaces the global ones.
+func MergeBudgetWeights(globalConfig, projectConfig *FileConfig) map[string]float64 {
+	if len(projectConfig.BudgetWeights) > 0 {
+		return projectConfig.BudgetWeights
+	}
+	return globalConfig.BudgetWeights
+}
+
+// MergeAnnotations returns the annotations of both configs. Unlike budget
+// weights they are combined; for a glob both define, the project note wins.
+func MergeAnnotations(globalConfig, projectConfig *FileConfig) map[string]string {
+	if len(globalConfig.Annotations) == 0 {
+		return projectConfig.Annotations
+	}
+	if len(projectConfig.Annotations) == 0 {
+		return globalConfig.Annotations
+	}
+	merged := make(map[string]string, len(globalConfig.Annotations)+len(projectConfig.Annotations))
+	for glob, note := range globalConfig.Annotations {
+		merged[glob] = note
+	}
+	for glob, note := range projectConfig.Annotations {
+		merged[glob] = note
+	}
+	return merged
+}
+
+// MergeSynonyms returns the relevance synonyms of the global config with
+// those of the project config; the project's list replaces the global one
+// for the same keyword.
+func MergeSynonyms(globalConfig, projectConfig *FileConfig) map[string][]string {
+	if len(globalConfig.Relevance.Synonyms) == 0 {
+		return projectConfig.Relevance.Synonyms
+	}
+	if len(projectConfig.Relevance.Synonyms) == 0 {
+		return globalConfig.Relevance.Synonyms
+	}
+	merged := make(map[string][]string, len(globalConfig.Relevance.Synonyms)+len(projectConfig.Relevance.Synonyms))
+	for keyword, synonyms := range globalConfig.Relevance.Synonyms {
+		merged[keyword] = synonyms
+	}
+	for keyword, synonyms := range projectConfig.Relevance.Synonyms {
+		merged[keyword] = synonyms
+	}
+	return merged
+}
+
 // mergeExtensions handles extension merging logic
 func (fc *FileConfig) mergeExtensions(flagExt string) []string {
 	if flagExt != "" {
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -318,6 +320,181 @@ func TestLoadConfigReadsProjectFile(t *testing.T) {
 	}
 }
 
+func TestMergeCoreDirs(t *testing.T) {
+	dir := t.TempDir()
+	content := "core-dirs:\n  - app\n  - domain\n"
+	if err := os.WriteFile(filepath.Join(dir, ".promptext.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+
+	projectConfig, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	globalConfig := &FileConfig{CoreDirs: []string{"services"}}
+
+	if got := MergeCoreDirs(globalConfig, projectConfig); len(got) != 2 || got[0] != "app" || got[1] != "domain" {
+		t.Fatalf("expected project core dirs to win, got %v", got)
+	}
+	if got := MergeCoreDirs(globalConfig, &FileConfig{}); len(got) != 1 || got[0] != "services" {
+		t.Fatalf("expected global core dirs as fallback, got %v", got)
+	}
+	if got := MergeCoreDirs(&FileConfig{}, &FileConfig{}); got != nil {
+		t.Fatalf("expected nil core dirs when unset, got %v", got)
+	}
+}
+
+func TestMergeEntryPoints(t *testing.T) {
+	dir := t.TempDir()
+	content := "entry-points:\n  - \"cmd/*/main.go\"\n  - \"functions/*/handler.ts\"\n"
+	if err := os.WriteFile(filepath.Join(dir, ".promptext.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+
+	projectConfig, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	globalConfig := &FileConfig{EntryPoints: []string{"main.go"}}
+
+	if got := MergeEntryPoints(globalConfig, projectConfig); len(got) != 2 || got[0] != "cmd/*/main.go" || got[1] != "functions/*/handler.ts" {
+		t.Fatalf("expected project entry points to win, got %v", got)
+	}
+	if got := MergeEntryPoints(globalConfig, &FileConfig{}); len(got) != 1 || got[0] != "main.go" {
+		t.Fatalf("expected global entry points as fallback, got %v", got)
+	}
+	if got := MergeEntryPoints(&FileConfig{}, &FileConfig{}); got != nil {
+		t.Fatalf("expected nil entry points when unset, got %v", got)
+	}
+	if got := globalConfig.Overlay(projectConfig).EntryPoints; len(got) != 2 {
+		t.Fatalf("expected overlay to take project entry points, got %v", got)
+	}
+}
+
+func TestMergeBudgetWeights(t *testing.T) {
+	dir := t.TempDir()
+	content := "budget-weights:\n  internal/: 0.6\n  \"*.md\": 0.1\n"
+	if err := os.WriteFile(filepath.Join(dir, ".promptext.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+
+	projectConfig, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	globalConfig := &FileConfig{BudgetWeights: map[string]float64{"pkg/": 0.5}}
+
+	if got := MergeBudgetWeights(globalConfig, projectConfig); len(got) != 2 || got["internal/"] != 0.6 || got["*.md"] != 0.1 {
+		t.Fatalf("expected project budget weights to win, got %v", got)
+	}
+	if got := MergeBudgetWeights(globalConfig, &FileConfig{}); len(got) != 1 || got["pkg/"] != 0.5 {
+		t.Fatalf("expected global budget weights as fallback, got %v", got)
+	}
+}
+
+func TestMergeAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	content := "annotations:\n  internal/legacy/**: Being deprecated\n  \"*.proto\": Generated clients depend on this\n"
+	if err := os.WriteFile(filepath.Join(dir, ".promptext.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+
+	projectConfig, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	globalConfig := &FileConfig{Annotations: map[string]string{"internal/legacy/**": "Old", "vendor/**": "Third-party"}}
+
+	want := map[string]string{
+		"internal/legacy/**": "Being deprecated",
+		"*.proto":            "Generated clients depend on this",
+		"vendor/**":          "Third-party",
+	}
+	if got := MergeAnnotations(globalConfig, projectConfig); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected combined annotations with project notes winning, got %v", got)
+	}
+	if got := globalConfig.Overlay(projectConfig).Annotations; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected Overlay to combine annotations, got %v", got)
+	}
+}
+
+func TestMergeSynonyms(t *testing.T) {
+	dir := t.TempDir()
+	content := "relevance:\n  synonyms:\n    auth: [sso, saml]\n"
+	if err := os.WriteFile(filepath.Join(dir, ".promptext.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+
+	projectConfig, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	globalConfig := &FileConfig{Relevance: RelevanceConfig{Synonyms: map[string][]string{"auth": {"ldap"}, "db": {"postgres"}}}}
+
+	want := map[string][]string{"auth": {"sso", "saml"}, "db": {"postgres"}}
+	if got := MergeSynonyms(globalConfig, projectConfig); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected combined synonyms with project lists winning, got %v", got)
+	}
+	if got := globalConfig.Overlay(projectConfig).Relevance.Synonyms; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected Overlay to combine synonyms, got %v", got)
+	}
+	if got := MergeSynonyms(&FileConfig{}, projectConfig); !reflect.DeepEqual(got, projectConfig.Relevance.Synonyms) {
+		t.Fatalf("expected the project synonyms alone, got %v", got)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	content := `extensions: [.go]
+excludes: [vendor/]
+profiles:
+  docs:
+    extensions: [.md]
+    excludes: [drafts/]
+    format: markdown
+  review:
+    gitignore: false
+`
+	if err := os.WriteFile(filepath.Join(dir, ".promptext.yml"), []byte(content), 0644); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+	projectConfig, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	globalConfig := &FileConfig{Profiles: map[string]*FileConfig{
+		"docs":   {Format: "xml"},
+		"global": {Extensions: []string{".py"}},
+	}}
+
+	docs, err := FindProfile("docs", projectConfig, globalConfig)
+	if err != nil {
+		t.Fatalf("FindProfile error: %v", err)
+	}
+	merged := projectConfig.Overlay(docs)
+	if !reflect.DeepEqual(merged.Extensions, []string{".md"}) {
+		t.Fatalf("expected profile extensions to replace the base, got %v", merged.Extensions)
+	}
+	if !reflect.DeepEqual(merged.Excludes, []string{"vendor/", "drafts/"}) {
+		t.Fatalf("expected excludes to be merged, got %v", merged.Excludes)
+	}
+	if merged.Format != "markdown" {
+		t.Fatalf("expected the project profile to win over the global one, got format %q", merged.Format)
+	}
+
+	if _, err := FindProfile("global", projectConfig, globalConfig); err != nil {
+		t.Fatalf("expected fallback to global profiles, got %v", err)
+	}
+
+	_, err = FindProfile("missing", projectConfig, globalConfig)
+	if !errors.Is(err, ErrUnknownProfile) {
+		t.Fatalf("expected ErrUnknownProfile, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "docs, global, review") {
+		t.Fatalf("expected defined profiles in error, got %v", err)
+	}
+}
+
 func TestLoadConfigMissingReturnsEmpty(t *testing.T) {
 	cfg, err := LoadConfig(t.TempDir())
 	if err != nil {
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrConfigExists is returned when creating a config file that already exists
+var ErrConfigExists = errors.New("config file already exists")
+
+// ErrUnknownKey is returned for a setting that .promptext.yml doesn't have
+var ErrUnknownKey = errors.New("unknown config key")
+
+// globalTemplate is the starter global config written by InitGlobalConfig
+const globalTemplate = `# Promptext global configuration
+# Applies to every project. A project's .promptext.yml overrides these
+# settings (excludes and annotations are combined), and flags override both.
+
+# File extensions to include when a project doesn't set its own
+# extensions:
+#   - .go
+#   - .md
+
+# Patterns to exclude in every project
+excludes: []
+
+# Output format: ptx, markdown, xml, jsonl, or toon
+format: ptx
+
+# Use .gitignore patterns for additional filtering
+gitignore: true
+
+# Use built-in filtering rules for common files (node_modules, etc.)
+use-default-rules: true
+`
+
+// GlobalConfigPath returns the global config file LoadGlobalConfig reads: the
+// first of the standard locations that exists, or the preferred location
+// ($XDG_CONFIG_HOME/promptext/config.yml) when none does. It returns "" when
+// no home directory is known.
+func GlobalConfigPath() string {
+	paths := getGlobalConfigPaths()
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	if len(paths) == 0 {
+		return ""
+	}
+	return paths[0]
+}
+
+// InitGlobalConfig writes a starter global config and returns its path. An
+// existing file is left alone, returning ErrConfigExists, unless force is set.
+func InitGlobalConfig(force bool) (string, error) {
+	path := GlobalConfigPath()
+	if path == "" {
+		return "", fmt.Errorf("no home directory for the global config")
+	}
+	if _, err := os.Stat(path); err == nil && !force {
+		return path, fmt.Errorf("%w: %s (use --force to overwrite)", ErrConfigExists, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return path, fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(globalTemplate), 0644); err != nil {
+		return path, fmt.Errorf("failed to write config file: %w", err)
+	}
+	return path, nil
+}
+
+// Keys returns the settings of .promptext.yml, sorted
+func Keys() []string {
+	keys := make([]string, 0, len(configFields))
+	for key := range configFields {
+		if key != "profiles" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetValue returns the value of key in cfg as text: lists comma separated,
+// maps as "key=value" pairs and groups of settings as JSON. It reports false for a key cfg leaves unset.
+func GetValue(cfg *FileConfig, key string) (string, bool, error) {
+	field, err := configField(key)
+	if err != nil {
+		return "", false, err
+	}
+	value := reflect.ValueOf(cfg).Elem().FieldByIndex(field.Index)
+	if value.IsZero() {
+		return "", false, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		return strconv.FormatBool(value.Elem().Bool()), true, nil
+	case reflect.Struct:
+		data, err := json.Marshal(value.Interface())
+		return string(data), true, err
+	case reflect.Slice:
+		if value.Len() == 0 {
+			return "", false, nil
+		}
+		return strings.Join(value.Interface().([]string), ", "), true, nil
+	case reflect.Map:
+		if value.Len() == 0 {
+			return "", false, nil
+		}
+		keys := make([]string, 0, value.Len())
+		for _, k := range value.MapKeys() {
+			keys = append(keys, k.String())
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", k, value.MapIndex(reflect.ValueOf(k)).Interface()))
+		}
+		return strings.Join(pairs, ", "), true, nil
+	}
+	return value.String(), true, nil
+}
+
+// defaultValues are the built-in values of the settings that have one,
+// in GetValue's text form; they apply when no config file sets the key
+var defaultValues = map[string]string{
+	"format":            "ptx",
+	"gitignore":         "true",
+	"use-default-rules": "true",
+	"verbose":           "false",
+	"debug":             "false",
+}
+
+// DefaultValue returns the built-in value of key, and false for a key
+// without one
+func DefaultValue(key string) (string, bool) {
+	value, ok := defaultValues[key]
+	return value, ok
+}
+
+// SetValue sets key to value in the config file at path, creating the file
+// if needed. Lists take comma-separated values and booleans true or false;
+// map settings and profiles are edited in the file. Only the lines of key
+// change, so the rest of the file keeps its comments and layout. The result
+// must pass Validate before it is written.
+func SetValue(path, key, value string) error {
+	field, err := configField(key)
+	if err != nil {
+		return err
+	}
+
+	var setting interface{}
+	switch field.Type.Kind() {
+	case reflect.Ptr:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s must be true or false, got %q", key, value)
+		}
+		setting = b
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range parseCommaSeparated(value) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		setting = items
+	case reflect.String:
+		setting = value
+	default:
+		return fmt.Errorf("%s can't be set from the command line; edit %s", key, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var rendered bytes.Buffer
+	encoder := yaml.NewEncoder(&rendered)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string]interface{}{key: setting}); err != nil {
+		return err
+	}
+	updated := replaceKey(string(data), key, rendered.String())
+
+	if problems := Validate([]byte(updated)); len(problems) > 0 {
+		return fmt.Errorf("%s: %s", key, problems[0].Message)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// configField returns the FileConfig field of a settable key
+func configField(key string) (reflect.StructField, error) {
+	t := reflect.TypeOf(FileConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); name == key && key != "profiles" {
+			return t.Field(i), nil
+		}
+	}
+	if suggestion := closestKey(key); suggestion != "" {
+		return reflect.StructField{}, fmt.Errorf("%w %q (did you mean %q?)", ErrUnknownKey, key, suggestion)
+	}
+	return reflect.StructField{}, fmt.Errorf("%w %q", ErrUnknownKey, key)
+}
+
+// replaceKey replaces the lines of the top-level key in content, its key
+// line and the indented lines under it, with rendered. A missing key is
+// appended at the end.
+func replaceKey(content, key, rendered string) string {
+	lines := strings.SplitAfter(content, "\n")
+	start := -1
+	for n, line := range lines {
+		if strings.HasPrefix(line, key+":") {
+			start = n
+			break
+		}
+	}
+	if start < 0 {
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + rendered
+	}
+
+	// The value ends at its last indented line; comments and blank lines
+	// after it belong to what follows
+	end := start + 1
+	for n := start + 1; n < len(lines); n++ {
+		trimmed := strings.TrimSpace(lines[n])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if !strings.HasPrefix(lines[n], " ") && !strings.HasPrefix(lines[n], "\t") && !strings.HasPrefix(lines[n], "-") {
+			break
+		}
+		end = n + 1
+	}
+	return strings.Join(lines[:start], "") + rendered + strings.Join(lines[end:], "")
+}
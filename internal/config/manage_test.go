@@ -0,0 +1,154 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitGlobalConfig(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "xdg"))
+	t.Setenv("HOME", tmp)
+
+	want := filepath.Join(tmp, "xdg", "promptext", "config.yml")
+	if got := GlobalConfigPath(); got != want {
+		t.Fatalf("GlobalConfigPath() = %q, want %q", got, want)
+	}
+
+	path, err := InitGlobalConfig(false)
+	if err != nil || path != want {
+		t.Fatalf("InitGlobalConfig() = %q, %v", path, err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if problems := Validate(data); problems != nil {
+		t.Fatalf("expected a valid starter config, got %v", problems)
+	}
+	cfg, err := LoadGlobalConfig()
+	if err != nil || cfg.Format != "ptx" {
+		t.Fatalf("expected the starter config loaded, got %+v, %v", cfg, err)
+	}
+
+	if _, err := InitGlobalConfig(false); !errors.Is(err, ErrConfigExists) {
+		t.Fatalf("expected ErrConfigExists, got %v", err)
+	}
+	if _, err := InitGlobalConfig(true); err != nil {
+		t.Fatalf("InitGlobalConfig(force) error: %v", err)
+	}
+}
+
+func TestGlobalConfigPathPrefersExistingDotfile(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "xdg"))
+	t.Setenv("HOME", tmp)
+
+	dotfile := filepath.Join(tmp, ".promptext.yml")
+	if err := os.WriteFile(dotfile, []byte("format: xml\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if got := GlobalConfigPath(); got != dotfile {
+		t.Fatalf("GlobalConfigPath() = %q, want the existing %q", got, dotfile)
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".promptext.yml")
+	original := "# team config\nextensions:\n  - .go\n  - .md\n\n# generated code\nexcludes:\n  - gen/\nformat: ptx # default\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	for _, set := range [][2]string{
+		{"extensions", ".go, .proto"},
+		{"format", "markdown"},
+		{"verbose", "true"},
+	} {
+		if err := SetValue(path, set[0], set[1]); err != nil {
+			t.Fatalf("SetValue(%s) error: %v", set[0], err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	want := "# team config\nextensions:\n  - .go\n  - .proto\n\n# generated code\nexcludes:\n  - gen/\nformat: markdown\nverbose: true\n"
+	if string(data) != want {
+		t.Fatalf("SetValue() wrote\n%s\nwant\n%s", data, want)
+	}
+
+	tests := []struct {
+		key, value, wantErr string
+	}{
+		{"extentions", ".go", `did you mean "extensions"`},
+		{"debug", "maybe", "true or false"},
+		{"format", "yaml", "unknown format"},
+		{"extensions", "go", `should start with "."`},
+		{"budget-weights", "docs/=0.1", "can't be set"},
+		{"profiles", "x", "unknown config key"},
+	}
+	for _, tt := range tests {
+		err := SetValue(path, tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("SetValue(%s, %s) error = %v, want %q", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+	if after, _ := os.ReadFile(path); string(after) != want {
+		t.Errorf("expected a rejected value to leave the file alone, got\n%s", after)
+	}
+}
+
+func TestSetValueCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "promptext", "config.yml")
+	if err := SetValue(path, "gitignore", "false"); err != nil {
+		t.Fatalf("SetValue() error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "gitignore: false\n" {
+		t.Fatalf("expected a new config with the setting, got %q, %v", data, err)
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	cfg := &FileConfig{
+		Extensions:    []string{".go", ".md"},
+		Format:        "xml",
+		GitIgnore:     boolPtr(false),
+		BudgetWeights: map[string]float64{"internal/": 0.6, "docs/": 0.1},
+	}
+	tests := []struct {
+		key, want string
+		set       bool
+	}{
+		{"extensions", ".go, .md", true},
+		{"format", "xml", true},
+		{"gitignore", "false", true},
+		{"budget-weights", "docs/=0.1, internal/=0.6", true},
+		{"verbose", "", false},
+		{"excludes", "", false},
+	}
+	for _, tt := range tests {
+		got, set, err := GetValue(cfg, tt.key)
+		if err != nil || got != tt.want || set != tt.set {
+			t.Errorf("GetValue(%s) = %q, %v, %v; want %q, %v", tt.key, got, set, err, tt.want, tt.set)
+		}
+	}
+	if _, _, err := GetValue(cfg, "colour"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey, got %v", err)
+	}
+	for _, key := range Keys() {
+		if key == "profiles" {
+			t.Errorf("expected profiles left out of Keys()")
+		}
+	}
+	if value, ok := DefaultValue("format"); !ok || value != "ptx" {
+		t.Errorf("DefaultValue(format) = %q, %v; want ptx", value, ok)
+	}
+	if _, ok := DefaultValue("extensions"); ok {
+		t.Errorf("expected no default for extensions")
+	}
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an issue Validate found in a config file
+type Problem struct {
+	Line    int // 1-based line of the offending key or value, 0 if unknown
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Line == 0 {
+		return p.Message
+	}
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// configFields maps each .promptext.yml key to its FileConfig field type
+var configFields = func() map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	t := reflect.TypeOf(FileConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		if key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ","); key != "" {
+			fields[key] = t.Field(i).Type
+		}
+	}
+	return fields
+}()
+
+// yamlLine extracts the line number yaml.v3 puts in its error messages
+var yamlLine = regexp.MustCompile(`line (\d+): (.*)`)
+
+// Validate checks .promptext.yml data for problems that loading would pass
+// over silently: unknown keys (typos such as "extentions"), values of the
+// wrong type, extensions without a leading ".", and unknown formats. It
+// returns nil for a valid config.
+func Validate(data []byte) []Problem {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		problem := Problem{Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+		if m := yamlLine.FindStringSubmatch(problem.Message); m != nil {
+			problem.Line, _ = strconv.Atoi(m[1])
+			problem.Message = m[2]
+		}
+		return []Problem{problem}
+	}
+	if len(doc.Content) == 0 {
+		return nil // Empty file
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return []Problem{{Line: root.Line, Message: "config must be a mapping of settings"}}
+	}
+	return validateMapping(root, "")
+}
+
+// validateMapping checks the settings of the top-level config or, with a
+// non-empty profile, of one profile
+func validateMapping(node *yaml.Node, profile string) []Problem {
+	var problems []Problem
+	add := func(line int, format string, args ...interface{}) {
+		message := fmt.Sprintf(format, args...)
+		if profile != "" {
+			message = fmt.Sprintf("profile %q: %s", profile, message)
+		}
+		problems = append(problems, Problem{Line: line, Message: message})
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		name := key.Value
+
+		fieldType, known := configFields[name]
+		if !known {
+			if suggestion := closestKey(name); suggestion != "" {
+				add(key.Line, "unknown key %q (did you mean %q?)", name, suggestion)
+			} else {
+				add(key.Line, "unknown key %q", name)
+			}
+			continue
+		}
+		if seen[name] {
+			add(key.Line, "duplicate key %q", name)
+		}
+		seen[name] = true
+
+		if name == "profiles" {
+			if profile != "" {
+				add(key.Line, "profiles cannot be nested")
+				continue
+			}
+			problems = append(problems, validateProfiles(value)...)
+			continue
+		}
+
+		if err := value.Decode(reflect.New(fieldType).Interface()); err != nil {
+			add(value.Line, "%s must be %s", name, describeType(fieldType))
+			continue
+		}
+
+		switch name {
+		case "extensions":
+			for _, ext := range value.Content {
+				if !strings.HasPrefix(ext.Value, ".") {
+					add(ext.Line, "extension %q should start with \".\" (use %q)", ext.Value, "."+ext.Value)
+				}
+			}
+		case "relevance":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				if setting := value.Content[j]; setting.Value != "synonyms" {
+					add(setting.Line, "unknown relevance setting %q (supported: synonyms)", setting.Value)
+				}
+			}
+		case "hooks":
+			if profile != "" {
+				add(key.Line, "hooks cannot be set in a profile")
+				continue
+			}
+			problems = append(problems, validateHooks(value)...)
+		case "format":
+			if _, err := format.GetFormatter(value.Value); err != nil {
+				add(value.Line, "unknown format %q (supported: markdown, xml, ptx, toon, toon-strict, jsonl, json, html, pdf)", value.Value)
+			}
+		}
+	}
+	return problems
+}
+
+// validateProfiles checks each named profile under the profiles key
+func validateProfiles(node *yaml.Node) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{Line: node.Line, Message: "profiles must be a mapping of profile names to settings"}}
+	}
+
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, settings := node.Content[i].Value, node.Content[i+1]
+		if settings.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{Line: settings.Line, Message: fmt.Sprintf("profile %q must be a mapping of settings", name)})
+			continue
+		}
+		problems = append(problems, validateMapping(settings, name)...)
+	}
+	return problems
+}
+
+// hookSettings are the settings of each hook under the hooks key
+var hookSettings = []string{"secrets", "max-tokens", "max-growth", "profile"}
+
+// validateHooks checks the hook names and settings under the hooks key,
+// which decodes into HooksConfig already
+func validateHooks(node *yaml.Node) []Problem {
+	var problems []Problem
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, settings := node.Content[i], node.Content[i+1]
+		if !slices.Contains(HookNames, name.Value) {
+			problems = append(problems, Problem{Line: name.Line, Message: fmt.Sprintf("unknown hook %q (supported: %s)", name.Value, strings.Join(HookNames, ", "))})
+			continue
+		}
+		for j := 0; j+1 < len(settings.Content); j += 2 {
+			setting := settings.Content[j]
+			if !slices.Contains(hookSettings, setting.Value) {
+				problems = append(problems, Problem{Line: setting.Line, Message: fmt.Sprintf("unknown %s setting %q (supported: %s)", name.Value, setting.Value, strings.Join(hookSettings, ", "))})
+			} else if strings.HasPrefix(setting.Value, "max-") && strings.HasPrefix(settings.Content[j+1].Value, "-") {
+				problems = append(problems, Problem{Line: setting.Line, Message: fmt.Sprintf("%s %s must be a positive number of tokens", name.Value, setting.Value)})
+			}
+		}
+	}
+	return problems
+}
+
+// describeType names the YAML shape a config field expects
+func describeType(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Slice:
+		return "a list of strings"
+	case reflect.Map, reflect.Struct:
+		return "a mapping"
+	default:
+		return "a string"
+	}
+}
+
+// closestKey returns the known key within two edits of name, if any
+func closestKey(name string) string {
+	keys := make([]string, 0, len(configFields))
+	for key := range configFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	best, bestDistance := "", 3
+	for _, key := range keys {
+		if d := editDistance(strings.ToLower(name), key); d < bestDistance {
+			best, bestDistance = key, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr := make([]int, len(b)+1)
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want []string // Expected problems, as "line N: message" substrings
+	}{
+		{
+			name: "valid config",
+			yaml: "extensions:\n  - .go\nexcludes:\n  - vendor/\nformat: xml\ngitignore: true\n",
+		},
+		{
+			name: "empty file",
+			yaml: "",
+		},
+		{
+			name: "typo with suggestion",
+			yaml: "extentions:\n  - .go\n",
+			want: []string{`line 1: unknown key "extentions" (did you mean "extensions"?)`},
+		},
+		{
+			name: "unknown key without suggestion",
+			yaml: "format: xml\ncolour: blue\n",
+			want: []string{`line 2: unknown key "colour"`},
+		},
+		{
+			name: "extension without dot",
+			yaml: "extensions:\n  - .go\n  - ts\n",
+			want: []string{`line 3: extension "ts" should start with "." (use ".ts")`},
+		},
+		{
+			name: "unknown format",
+			yaml: "format: yaml\n",
+			want: []string{`line 1: unknown format "yaml"`},
+		},
+		{
+			name: "wrong types",
+			yaml: "verbose: sometimes\nexcludes: vendor/\n",
+			want: []string{"line 1: verbose must be true or false", "line 2: excludes must be a list of strings"},
+		},
+		{
+			name: "profile problems",
+			yaml: "profiles:\n  docs:\n    extensions: [md]\n    exclude: [a]\n",
+			want: []string{`line 3: profile "docs": extension "md"`, `line 4: profile "docs": unknown key "exclude" (did you mean "excludes"?)`},
+		},
+		{
+			name: "relevance settings",
+			yaml: "relevance:\n  synonyms:\n    auth: [sso]\n  synonym: {}\n",
+			want: []string{`line 4: unknown relevance setting "synonym" (supported: synonyms)`},
+		},
+		{
+			name: "hooks",
+			yaml: "hooks:\n  pre-commit:\n    max-growth: 5000\n    max-token: 1\n  post-merge: {}\n  pre-push:\n    max-tokens: -1\n",
+			want: []string{
+				`line 4: unknown pre-commit setting "max-token"`,
+				`line 5: unknown hook "post-merge" (supported: pre-commit, pre-push)`,
+				`line 7: pre-push max-tokens must be a positive number of tokens`,
+			},
+		},
+		{
+			name: "hooks of a profile",
+			yaml: "profiles:\n  docs:\n    hooks:\n      pre-commit: {}\n",
+			want: []string{`line 3: profile "docs": hooks cannot be set in a profile`},
+		},
+		{
+			name: "syntax error",
+			yaml: "extensions: [.go\nformat: xml\n",
+			want: []string{"line "},
+		},
+		{
+			name: "not a mapping",
+			yaml: "- .go\n",
+			want: []string{"line 1: config must be a mapping of settings"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := Validate([]byte(tt.yaml))
+			if len(problems) != len(tt.want) {
+				t.Fatalf("Validate() = %v, want %d problems", problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := problems[i].String(); !strings.Contains(got, want) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, got, want)
+				}
+			}
+		})
+	}
+}
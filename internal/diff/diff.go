@@ -0,0 +1,235 @@
+// Package diff computes line-based unified diffs between two versions of a file.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxEdits bounds the edit distance the diff searches for. Myers' algorithm
+// keeps a trace that grows with its square; past the bound, the differing
+// middle of the files is reported as removed and re-added as a whole.
+const maxEdits = 1000
+
+// op is one line of an edit script: ' ' kept, '-' removed from a, '+' added from b
+type op struct {
+	kind byte
+	line string // Including its newline, if any
+}
+
+// Unified returns the differences between a and b as a unified diff, the
+// format of diff -u and git diff, with context unchanged lines around each
+// change. fromName and toName label the --- and +++ headers; use /dev/null
+// for a side that doesn't exist. It returns "" when a and b are equal.
+func Unified(fromName, toName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	ops := edits(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks(ops, context) {
+		writeHunk(&sb, ops, h)
+	}
+	return sb.String()
+}
+
+// splitLines splits s after each newline; a last line without one is kept
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edits returns an edit script turning a into b. The common prefix and
+// suffix are matched directly, so the search only covers the lines between.
+func edits(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{' ', line})
+	}
+	ops = append(ops, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{' ', line})
+	}
+	return ops
+}
+
+// myers finds a shortest edit script with Myers' O(ND) algorithm, or replaces
+// all of a with all of b when the edit distance exceeds maxEdits
+func myers(a, b []string) []op {
+	n, m := len(a), len(b)
+	replace := func() []op {
+		ops := make([]op, 0, n+m)
+		for _, line := range a {
+			ops = append(ops, op{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, op{'+', line})
+		}
+		return ops
+	}
+	if n == 0 || m == 0 {
+		return replace()
+	}
+
+	// v[k+offset] is the furthest x reached on diagonal k = x - y. trace[d]
+	// holds v as it was before step d, for diagonals -d-1 through d+1.
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= n+m; d++ {
+		if d > maxEdits {
+			return replace()
+		}
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Down: insert from b
+			} else {
+				x = v[offset+k-1] + 1 // Right: delete from a
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return replace()
+}
+
+// backtrack walks the trace of myers back from the end of both inputs to
+// recover the edit script
+func backtrack(a, b []string, trace [][]int) []op {
+	var reversed []op
+	x, y := len(a), len(b)
+	for d := len(trace) - 1; d >= 0; d-- {
+		at := func(k int) int { return trace[d][k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, op{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, op{'+', b[y-1]})
+			} else {
+				reversed = append(reversed, op{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	ops := make([]op, len(reversed))
+	for i, o := range reversed {
+		ops[len(ops)-1-i] = o
+	}
+	return ops
+}
+
+// hunk is a range of ops to print together, with its starting line numbers
+type hunk struct {
+	start, end       int // Indexes into ops, end exclusive
+	fromLine, toLine int // 1-based line of ops[start] in a and b
+}
+
+// hunks groups the changes of ops into hunks with context lines around
+// them, merging changes whose context would overlap
+func hunks(ops []op, context int) []hunk {
+	var result []hunk
+	fromLine, toLine := 1, 1
+	lines := make([][2]int, len(ops)) // Line numbers in a and b at each op
+	for i, o := range ops {
+		lines[i] = [2]int{fromLine, toLine}
+		if o.kind != '+' {
+			fromLine++
+		}
+		if o.kind != '-' {
+			toLine++
+		}
+	}
+
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == ' ' {
+			continue
+		}
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		end = min(end+context, len(ops))
+		if n := len(result); n > 0 && result[n-1].end >= start {
+			result[n-1].end = end
+		} else {
+			result = append(result, hunk{start: start, end: end, fromLine: lines[start][0], toLine: lines[start][1]})
+		}
+		i = end - 1
+	}
+	return result
+}
+
+// writeHunk writes the @@ header and lines of h
+func writeHunk(sb *strings.Builder, ops []op, h hunk) {
+	fromCount, toCount := 0, 0
+	for _, o := range ops[h.start:h.end] {
+		if o.kind != '+' {
+			fromCount++
+		}
+		if o.kind != '-' {
+			toCount++
+		}
+	}
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(h.fromLine, fromCount), hunkRange(h.toLine, toCount))
+	for _, o := range ops[h.start:h.end] {
+		sb.WriteByte(o.kind)
+		sb.WriteString(o.line)
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk's line range: "3" for one line, "3,4" for four,
+// and, for an empty range, the line before it as "2,0"
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
@@ -0,0 +1,99 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "same\n",
+			b:    "same\n",
+			want: "",
+		},
+		{
+			name: "changed line with context",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n",
+			b:    "1\n2\n3\n4\nfive\n6\n7\n8\n",
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "added file",
+			a:    "",
+			b:    "x\ny\n",
+			want: "--- a\n+++ b\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "removed file",
+			a:    "x\n",
+			b:    "",
+			want: "--- a\n+++ b\n@@ -1 +0,0 @@\n-x\n",
+		},
+		{
+			name: "missing newline at end",
+			a:    "x\ny",
+			b:    "x\ny\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,2 @@\n x\n-y\n\\ No newline at end of file\n+y\n",
+		},
+		{
+			name: "insertion and deletion in the middle",
+			a:    "a\nb\nc\nd\n",
+			b:    "a\nc\nx\nd\n",
+			want: "--- a\n+++ b\n@@ -1,4 +1,4 @@\n a\n-b\n c\n+x\n d\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Unified("a", "b", tt.a, tt.b, 3))
+		})
+	}
+}
+
+func TestUnifiedSeparateHunks(t *testing.T) {
+	var a, b strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&a, "%d\n", i)
+		if i == 2 || i == 19 {
+			fmt.Fprintf(&b, "changed %d\n", i)
+		} else {
+			fmt.Fprintf(&b, "%d\n", i)
+		}
+	}
+
+	got := Unified("a", "b", a.String(), b.String(), 2)
+	assert.Equal(t, 2, strings.Count(got, "@@ -"))
+	assert.Contains(t, got, "@@ -1,4 +1,4 @@\n 1\n-2\n+changed 2\n 3\n 4\n")
+	assert.Contains(t, got, "@@ -17,4 +17,4 @@\n 17\n 18\n-19\n+changed 19\n 20\n")
+}
+
+func TestEditsRoundTrip(t *testing.T) {
+	a := splitLines("the\nquick\nbrown\nfox\njumps\nover\nthe\nlazy\ndog\n")
+	b := splitLines("a\nquick\nfox\njumps\nhigh\nover\nthe\ndog\nagain\n")
+
+	var from, to []string
+	changes := 0
+	for _, o := range edits(a, b) {
+		if o.kind != '+' {
+			from = append(from, o.line)
+		}
+		if o.kind != '-' {
+			to = append(to, o.line)
+		}
+		if o.kind != ' ' {
+			changes++
+		}
+	}
+	assert.Equal(t, a, from)
+	assert.Equal(t, b, to)
+	assert.Equal(t, 6, changes, "a shortest edit script")
+}
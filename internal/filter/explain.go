@@ -0,0 +1,131 @@
+package filter
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"github.com/1broseidon/promptext/internal/filter/types"
+)
+
+// Rules reported by Explain
+const (
+	RuleExclude     = "exclude"         // A user exclude pattern or extension
+	RuleGitIgnore   = "gitignore"       // A pattern in .gitignore, info/exclude, or core.excludesFile
+	RuleDefault     = "default"         // A built-in exclude pattern
+	RuleLockfile    = "lockfile"        // Lockfile detection
+	RuleGenerated   = "generated"       // Generated-file detection by name, size, or content
+	RuleBinary      = "binary"          // Binary detection
+	RuleAllowlist   = "allowlist"       // Not on the .promptextinclude allowlist
+	RuleIncludePath = "include-path"    // Outside the include path globs
+	RuleExtension   = "extension"       // Not one of the included extensions
+	RuleContent     = "content-pattern" // Content matched a content exclude
+)
+
+// Decision is how the filter treats one path, as reported by Explain
+type Decision struct {
+	Excluded bool
+	Rule     string // The Rule* constant that excluded the path
+	Detail   string // What matched: a pattern ("<ignore file>: <pattern>" for gitignore) or extension
+	Dir      string // The directory the rule excluded, when it matched a parent rather than the path
+}
+
+// Explain reports which rule, if any, keeps path out. Parent directories are
+// checked first, as the walk never enters an excluded directory. User choices
+// (excludes and ignore files) are reported before the built-in default rules
+// when both match. Content is not considered; see ExplainContent.
+func (f *Filter) Explain(path string) Decision {
+	path = filepath.Clean(path)
+
+	// A rule matching both a parent directory and the file is reported for the file
+	own := f.explainExcluded(path)
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for n := 1; n < len(parts); n++ {
+		dir := filepath.FromSlash(strings.Join(parts[:n], "/"))
+		if decision := f.explainExcluded(dir); decision.Excluded {
+			if decision == own {
+				return own
+			}
+			decision.Dir = dir
+			return decision
+		}
+	}
+	if own.Excluded {
+		return own
+	}
+
+	if f.allow != nil && !f.allow.Match(path) {
+		return Decision{Excluded: true, Rule: RuleAllowlist, Detail: IncludeFileName}
+	}
+	if f.paths != nil && !f.paths.Match(path) {
+		return Decision{Excluded: true, Rule: RuleIncludePath, Detail: strings.Join(f.opts.IncludePaths, ",")}
+	}
+
+	hasIncludes := false
+	for _, rule := range f.rules {
+		if rule.Action() == types.Include {
+			if rule.Match(path) {
+				return Decision{}
+			}
+			hasIncludes = true
+		}
+	}
+	if hasIncludes {
+		return Decision{Excluded: true, Rule: RuleExtension, Detail: filepath.Ext(path)}
+	}
+	return Decision{}
+}
+
+// ExplainContent reports whether content excludes a file that passed the
+// path rules, naming the rule as Explain does
+func (f *Filter) ExplainContent(path, content string) Decision {
+	switch f.ContentReason(path, content) {
+	case "generated":
+		return Decision{Excluded: true, Rule: RuleGenerated, Detail: "content"}
+	case "content-pattern":
+		return Decision{Excluded: true, Rule: RuleContent}
+	}
+	return Decision{}
+}
+
+// explainExcluded finds the exclude rule matching path, checking the user's
+// own rules before the defaults
+func (f *Filter) explainExcluded(path string) Decision {
+	for _, rule := range f.chosen {
+		if rule.Action() != types.Exclude || !rule.Match(path) {
+			continue
+		}
+		switch r := rule.(type) {
+		case *rules.GitIgnoreRule:
+			source, pattern, _ := r.Explain(path)
+			return Decision{Excluded: true, Rule: RuleGitIgnore, Detail: source + ": " + pattern}
+		case *rules.PatternRule:
+			pattern, _ := r.MatchingPattern(path)
+			return Decision{Excluded: true, Rule: RuleExclude, Detail: pattern}
+		default:
+			return Decision{Excluded: true, Rule: RuleExclude, Detail: filepath.Ext(path)}
+		}
+	}
+
+	for _, rule := range f.rules {
+		if rule.Action() != types.Exclude || !rule.Match(path) {
+			continue
+		}
+		switch r := rule.(type) {
+		case *rules.PatternRule:
+			pattern, _ := r.MatchingPattern(path)
+			return Decision{Excluded: true, Rule: RuleDefault, Detail: pattern}
+		case *rules.ExtensionRule:
+			return Decision{Excluded: true, Rule: RuleDefault, Detail: filepath.Ext(path)}
+		case *rules.LockFileRule, *rules.EcosystemRule:
+			return Decision{Excluded: true, Rule: RuleLockfile}
+		case *rules.GeneratedFileRule:
+			return Decision{Excluded: true, Rule: RuleGenerated}
+		case *rules.BinaryRule:
+			return Decision{Excluded: true, Rule: RuleBinary}
+		default:
+			return Decision{Excluded: true, Rule: RuleDefault}
+		}
+	}
+	return Decision{}
+}
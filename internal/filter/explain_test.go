@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilter_Explain(t *testing.T) {
+	isolateGitConfig(t, os.DevNull)
+	root := t.TempDir()
+	createTestFile(t, filepath.Join(root, "web", ".gitignore"), []byte("dist/\n"))
+
+	f := New(Options{
+		Root:            root,
+		UseGitIgnore:    true,
+		UseDefaultRules: true,
+		Includes:        []string{".go", ".js", ".png"},
+		Excludes:        []string{"vendor/", "*.gen.go"},
+	})
+
+	tests := []struct {
+		path string
+		want Decision
+	}{
+		{"main.go", Decision{}},
+		{"vendor/lib/lib.go", Decision{Excluded: true, Rule: RuleExclude, Detail: "vendor/"}},
+		{"api/types.gen.go", Decision{Excluded: true, Rule: RuleExclude, Detail: "*.gen.go"}},
+		{"web/dist/app.js", Decision{Excluded: true, Rule: RuleGitIgnore, Detail: "web/.gitignore: dist/"}},
+		{"node_modules/x/a.js", Decision{Excluded: true, Rule: RuleDefault, Detail: "node_modules/"}},
+		{"assets/logo.png", Decision{Excluded: true, Rule: RuleBinary}},
+		{"docs/readme.md", Decision{Excluded: true, Rule: RuleExtension, Detail: ".md"}},
+	}
+	for _, tt := range tests {
+		if got := f.Explain(tt.path); got != tt.want {
+			t.Errorf("Explain(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFilter_ExplainAllowlistAndIncludePaths(t *testing.T) {
+	f := New(Options{Allowlist: []string{"cmd/**"}})
+	if got := f.Explain("internal/a.go"); got.Rule != RuleAllowlist {
+		t.Errorf("expected the allowlist to exclude internal/a.go, got %+v", got)
+	}
+
+	f = New(Options{IncludePaths: []string{"internal/**"}})
+	if got := f.Explain("cmd/main.go"); got.Rule != RuleIncludePath || got.Detail != "internal/**" {
+		t.Errorf("expected the include paths to exclude cmd/main.go, got %+v", got)
+	}
+	if got := f.Explain("internal/a.go"); got.Excluded {
+		t.Errorf("expected internal/a.go to pass, got %+v", got)
+	}
+}
+
+func TestFilter_ExplainContent(t *testing.T) {
+	f := New(Options{SkipGenerated: true, ContentExcludes: []string{"VENDORED"}})
+	if got := f.ExplainContent("a.go", "// Code generated by x. DO NOT EDIT.\npackage a\n"); got.Rule != RuleGenerated {
+		t.Errorf("expected generated, got %+v", got)
+	}
+	if got := f.ExplainContent("b.go", "// VENDORED\npackage b\n"); got.Rule != RuleContent {
+		t.Errorf("expected content-pattern, got %+v", got)
+	}
+	if got := f.ExplainContent("c.go", "package c\n"); got.Excluded {
+		t.Errorf("expected c.go to pass, got %+v", got)
+	}
+}
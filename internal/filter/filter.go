@@ -2,8 +2,10 @@ package filter
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/1broseidon/promptext/internal/filter/rules"
@@ -16,12 +18,91 @@ type Options struct {
 	Excludes        []string
 	UseDefaultRules bool // Controls whether to apply default filtering rules
 	UseGitIgnore    bool
+
+	// Root is the project directory the filtered paths are relative to, where
+	// UseGitIgnore looks for ignore files. Empty means the current directory.
+	Root string
+
+	// IncludePaths restricts processing to paths matching these gitignore-style
+	// globs (e.g. "internal/processor/**", "cmd/*/main.go"). Like the
+	// allowlist, excludes and .gitignore still win and Includes narrow it further.
+	IncludePaths []string
+
+	// Allowlist switches the filter into allowlist mode: only paths matching
+	// these gitignore-style patterns are processed. Excludes still apply on top
+	// of the allowlist and Includes (extensions) narrow it further.
+	Allowlist []string
+
+	// ContentExcludes are regular expressions; files whose content matches any
+	// of them are skipped. Invalid expressions are ignored with a warning.
+	ContentExcludes []string
+
+	// SkipGenerated skips files whose content marks them as generated
+	// ("Code generated ... DO NOT EDIT", protoc headers, @generated) and
+	// minified JS/CSS
+	SkipGenerated bool
+
+	// Frameworks are the project types detected in Root (initializer names
+	// such as "django" or "laravel"); with UseDefaultRules, their build
+	// output and runtime directories are excluded too
+	Frameworks []string
+
+	// EntryPoints are gitignore-style globs naming the files that start the
+	// project (e.g. "cmd/*/main.go", "functions/*/handler.ts"), ranked first
+	// under a token budget. They replace DefaultEntryPoints; nil keeps them.
+	EntryPoints []string
 }
 
+// DefaultEntryPoints are the file names treated as entry points unless
+// Options.EntryPoints names others
+var DefaultEntryPoints = []string{
+	// Go
+	"main.go",
+
+	// JavaScript/TypeScript
+	"index.js", "index.ts", "index.jsx", "index.tsx",
+	"app.js", "app.ts", "app.jsx", "app.tsx",
+	"server.js", "server.ts", "index.html",
+
+	// Python
+	"main.py", "app.py", "__init__.py", "__main__.py", "manage.py", "wsgi.py", "asgi.py",
+
+	// Ruby
+	"application.rb", "config.ru",
+
+	// PHP
+	"index.php", "app.php",
+
+	// Java
+	"Main.java", "Application.java",
+
+	// Rust
+	"main.rs", "lib.rs",
+
+	// C/C++
+	"main.c", "main.cpp", "main.cc",
+}
+
+// defaultEntryPoints matches DefaultEntryPoints, for classifying files without a filter
+var defaultEntryPoints = rules.NewGitPatternRule(DefaultEntryPoints, types.Include)
+
+// IncludeFileName is the project-root file whose patterns form the allowlist
+const IncludeFileName = ".promptextinclude"
+
 // ParseGitIgnore reads .gitignore file and returns patterns
 func ParseGitIgnore(rootDir string) ([]string, error) {
-	gitignorePath := filepath.Join(rootDir, ".gitignore")
-	file, err := os.Open(gitignorePath)
+	return parsePatternFile(filepath.Join(rootDir, ".gitignore"))
+}
+
+// ParseIncludeFile reads the .promptextinclude allowlist from rootDir and
+// returns its patterns, or nil if the file does not exist
+func ParseIncludeFile(rootDir string) ([]string, error) {
+	return parsePatternFile(filepath.Join(rootDir, IncludeFileName))
+}
+
+// parsePatternFile reads a gitignore-style file, skipping blank lines and comments
+func parsePatternFile(patternPath string) ([]string, error) {
+	file, err := os.Open(patternPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -62,14 +143,41 @@ func MergeAndDedupePatterns(patterns ...[]string) []string {
 }
 
 type Filter struct {
-	rules []types.Rule
+	rules   []types.Rule
+	allow   types.Rule           // nil unless an allowlist is configured
+	paths   types.Rule           // nil unless include paths are configured
+	content []*rules.ContentRule // Applied to file content once read
+	chosen  []types.Rule         // Excludes and includes the user chose, without default rules
+	entry   types.Rule           // Entry point globs
+	opts    Options
+
+	ignoreFiles []string // Ignore files the gitignore rule was read from; see IgnoreFiles
+}
+
+// IgnoreFiles returns the git ignore files the filter's patterns depend on:
+// core.excludesFile, info/exclude and the .gitignore files above Root
+// whether or not they exist, and the .gitignore files under Root that do. A
+// change to any of them can change what the filter excludes.
+func (f *Filter) IgnoreFiles() []string {
+	return f.ignoreFiles
+}
+
+// Signature returns a string identifying the options the filter was built
+// from. Filters with equal signatures select the same files.
+func (f *Filter) Signature() string {
+	return fmt.Sprintf("inc=%q exc=%q paths=%q allow=%q content=%q generated=%t defaults=%t gitignore=%t frameworks=%q entry=%q",
+		f.opts.Includes, f.opts.Excludes, f.opts.IncludePaths, f.opts.Allowlist, f.opts.ContentExcludes, f.opts.SkipGenerated, f.opts.UseDefaultRules, f.opts.UseGitIgnore, f.opts.Frameworks, f.opts.EntryPoints)
 }
 
 func New(opts Options) *Filter {
+	opts.Excludes = slashPatterns(opts.Excludes)
+	opts.IncludePaths = slashPatterns(opts.IncludePaths)
+	opts.EntryPoints = slashPatterns(opts.EntryPoints)
+
 	var filterRules []types.Rule
 	var excludePatterns []string
 
-	var defaultPatterns, gitPatterns, configPatterns []string
+	var defaultPatterns, configPatterns []string
 
 	log.Phase("Filter Configuration")
 
@@ -81,14 +189,11 @@ func New(opts Options) *Filter {
 				defaultPatterns = append(defaultPatterns, patternRule.Patterns()...)
 			}
 		}
-		log.Debug("Default exclude patterns: %d", len(defaultPatterns))
-	}
-
-	if opts.UseGitIgnore {
-		if patterns, err := ParseGitIgnore("."); err == nil && len(patterns) > 0 {
-			gitPatterns = patterns
-			log.Debug("Gitignore patterns: %d", len(gitPatterns))
+		if patterns := rules.FrameworkExcludes(opts.Frameworks); len(patterns) > 0 {
+			defaultPatterns = append(defaultPatterns, patterns...)
+			log.Debug("Framework exclude patterns (%s): [%s]", strings.Join(opts.Frameworks, ", "), strings.Join(patterns, ", "))
 		}
+		log.Debug("Default exclude patterns: %d", len(defaultPatterns))
 	}
 
 	if len(opts.Excludes) > 0 {
@@ -97,7 +202,7 @@ func New(opts Options) *Filter {
 	}
 
 	// Merge all patterns
-	excludePatterns = MergeAndDedupePatterns([][]string{defaultPatterns, gitPatterns, configPatterns}...)
+	excludePatterns = MergeAndDedupePatterns([][]string{defaultPatterns, configPatterns}...)
 
 	// Log final consolidated patterns in array style
 	if len(excludePatterns) > 0 {
@@ -116,17 +221,175 @@ func New(opts Options) *Filter {
 			rules.NewExtensionRule(excludePatterns, types.Exclude))
 	}
 
+	// Ignore files apply with git's own semantics; directories the rules
+	// above exclude are not searched for nested .gitignore files
+	var gitIgnore types.Rule
+	var ignoreFiles []string
+	if opts.UseGitIgnore {
+		root := opts.Root
+		if root == "" {
+			root = "."
+		}
+		excluded := func(rel string) bool {
+			for _, rule := range filterRules {
+				if rule.Action() == types.Exclude && rule.Match(rel) {
+					return true
+				}
+			}
+			return false
+		}
+		var rule *rules.GitIgnoreRule
+		if rule, ignoreFiles = loadGitIgnore(root, excluded); rule != nil {
+			gitIgnore = rule
+			filterRules = append(filterRules, gitIgnore)
+		}
+	}
+
 	// Add include rules
 	if len(opts.Includes) > 0 {
 		filterRules = append(filterRules, rules.NewExtensionRule(opts.Includes, types.Include))
 	}
 
-	return &Filter{rules: filterRules}
+	// The user's own selection, for telling built-in skips from chosen ones
+	var chosen []types.Rule
+	if gitIgnore != nil {
+		chosen = append(chosen, gitIgnore)
+	}
+	if len(configPatterns) > 0 {
+		chosen = append(chosen,
+			rules.NewPatternRule(configPatterns, types.Exclude),
+			rules.NewExtensionRule(configPatterns, types.Exclude))
+	}
+	if len(opts.Includes) > 0 {
+		chosen = append(chosen, rules.NewExtensionRule(opts.Includes, types.Include))
+	}
+
+	var allow types.Rule
+	if len(opts.Allowlist) > 0 {
+		allow = rules.NewGitPatternRule(opts.Allowlist, types.Include)
+		log.Debug("Allowlist patterns (%d): [%s]", len(opts.Allowlist), strings.Join(opts.Allowlist, ", "))
+	}
+
+	var paths types.Rule
+	if len(opts.IncludePaths) > 0 {
+		paths = rules.NewGitPatternRule(opts.IncludePaths, types.Include)
+		log.Debug("Include path patterns (%d): [%s]", len(opts.IncludePaths), strings.Join(opts.IncludePaths, ", "))
+	}
+
+	var content []*rules.ContentRule
+	if opts.SkipGenerated {
+		content = append(content, rules.NewGeneratedContentRule())
+	}
+	if len(opts.ContentExcludes) > 0 {
+		var patterns []*regexp.Regexp
+		for _, expr := range opts.ContentExcludes {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				log.Warn("Ignoring invalid content exclude %q: %v", expr, err)
+				continue
+			}
+			patterns = append(patterns, re)
+		}
+		content = append(content, rules.NewContentRule(patterns))
+		log.Debug("Content exclude patterns (%d): [%s]", len(patterns), strings.Join(opts.ContentExcludes, ", "))
+	}
+
+	entry := defaultEntryPoints
+	if len(opts.EntryPoints) > 0 {
+		entry = rules.NewGitPatternRule(opts.EntryPoints, types.Include)
+		log.Debug("Entry point patterns (%d): [%s]", len(opts.EntryPoints), strings.Join(opts.EntryPoints, ", "))
+	}
+
+	return &Filter{rules: filterRules, allow: allow, paths: paths, content: content, chosen: chosen, entry: entry,
+		ignoreFiles: ignoreFiles, opts: opts}
+}
+
+// slashPath returns path cleaned and with forward slashes, the form every
+// rule matches, whichever separators the operating system or caller used
+func slashPath(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// slashPatterns returns patterns with backslash separators, as written on
+// Windows, replaced by forward slashes, so a config matches the same files on
+// every system. Patterns name paths, so a backslash is never an escape here.
+func slashPatterns(patterns []string) []string {
+	if len(patterns) == 0 {
+		return patterns
+	}
+	slashed := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		slashed[i] = strings.ReplaceAll(pattern, `\`, "/")
+	}
+	return slashed
+}
+
+// IsEntryPoint reports whether path is one of the project's entry points,
+// matched against Options.EntryPoints or, without any, DefaultEntryPoints.
+// A nil filter uses the defaults.
+func (f *Filter) IsEntryPoint(path string) bool {
+	if f == nil || f.entry == nil {
+		return isEntryPoint(path)
+	}
+	return f.entry.Match(slashPath(path))
+}
+
+// Selects reports whether path passes the user's own selection (excludes,
+// .gitignore, extensions, include paths, allowlist) regardless of the
+// built-in default rules, i.e. whether a skipped file was skipped only by
+// binary, lockfile, or generated-file detection
+func (f *Filter) Selects(path string) bool {
+	path = slashPath(path)
+	if f.allow != nil && !f.allow.Match(path) {
+		return false
+	}
+	if f.paths != nil && !f.paths.Match(path) {
+		return false
+	}
+
+	hasIncludes, included := false, false
+	for _, rule := range f.chosen {
+		switch rule.Action() {
+		case types.Exclude:
+			if rule.Match(path) {
+				return false
+			}
+		case types.Include:
+			hasIncludes = true
+			included = included || rule.Match(path)
+		}
+	}
+	return !hasIncludes || included
+}
+
+// ExcludesContent reports whether a file that passed the path rules is
+// excluded by its content (SkipGenerated or ContentExcludes)
+func (f *Filter) ExcludesContent(path, content string) bool {
+	if f.ContentReason(path, content) != "" {
+		log.Debug("Skipping file by content: %s", path)
+		return true
+	}
+	return false
+}
+
+// ContentReason returns why content excludes a file: "generated" for
+// SkipGenerated detection, "content-pattern" for a ContentExcludes match,
+// or "" if the file is kept
+func (f *Filter) ContentReason(path, content string) string {
+	for _, rule := range f.content {
+		if rule.MatchContent(path, content) {
+			if rule.Generated() {
+				return "generated"
+			}
+			return "content-pattern"
+		}
+	}
+	return ""
 }
 
 // ShouldProcess determines if a path should be processed
 func (f *Filter) ShouldProcess(path string) bool {
-	path = filepath.Clean(path)
+	path = slashPath(path)
 
 	// First check excludes silently
 	if f.IsExcluded(path) {
@@ -143,6 +406,15 @@ func (f *Filter) ShouldProcess(path string) bool {
 		}
 	}
 
+	// In allowlist mode, anything not on the allowlist is skipped
+	if f.allow != nil && !f.allow.Match(path) {
+		return false
+	}
+	// Likewise for --include path globs
+	if f.paths != nil && !f.paths.Match(path) {
+		return false
+	}
+
 	// Then check includes
 	for _, rule := range f.rules {
 		if rule.Match(path) && rule.Action() == types.Include {
@@ -163,7 +435,7 @@ func (f *Filter) ShouldProcess(path string) bool {
 
 // IsExcluded checks if a path is explicitly excluded
 func (f *Filter) IsExcluded(path string) bool {
-	path = filepath.Clean(path)
+	path = slashPath(path)
 
 	for _, rule := range f.rules {
 		if rule.Match(path) && rule.Action() == types.Exclude {
@@ -194,16 +466,8 @@ func isTestFile(path, base string) bool {
 		strings.HasSuffix(base, "_test.py")
 }
 
-func isEntryPoint(base string) bool {
-	entryPoints := map[string]bool{
-		"main.go":   true,
-		"index.js":  true,
-		"app.py":    true,
-		"index.ts":  true,
-		"app.js":    true,
-		"server.js": true,
-	}
-	return entryPoints[base]
+func isEntryPoint(path string) bool {
+	return defaultEntryPoints.Match(slashPath(path))
 }
 
 func getConfigType(ext string) (string, string) {
@@ -303,7 +567,7 @@ func GetFileType(path string, f *Filter) FileTypeInfo {
 	}
 
 	// Check for entry points
-	if isEntryPoint(base) {
+	if f.IsEntryPoint(path) {
 		info.IsEntryPoint = true
 		info.Type = "source"
 		info.Category = "entry:" + strings.TrimPrefix(ext, ".")
@@ -176,6 +176,126 @@ func TestParseGitIgnore_NoFile(t *testing.T) {
 	}
 }
 
+func TestFilter_Allowlist(t *testing.T) {
+	tmpDir := t.TempDir()
+	createTestFile(t, filepath.Join(tmpDir, IncludeFileName), []byte("# only the API surface\ncmd/\ninternal/api/*.go\n"))
+
+	allowlist, err := ParseIncludeFile(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseIncludeFile() error = %v", err)
+	}
+
+	f := New(Options{
+		Excludes:  []string{"*_test.go"},
+		Allowlist: allowlist,
+		Includes:  []string{".go"},
+	})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"cmd/app/main.go", true},
+		{"internal/api/server.go", true},
+		{"internal/api/server_test.go", false}, // excludes still apply
+		{"internal/api/README.md", false},      // extensions still narrow
+		{"internal/db/db.go", false},           // not on the allowlist
+		{"main.go", false},
+	}
+	for _, tt := range tests {
+		if got := f.ShouldProcess(tt.path); got != tt.want {
+			t.Errorf("ShouldProcess(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFilter_IncludePaths(t *testing.T) {
+	f := New(Options{
+		Excludes:     []string{"*_test.go"},
+		IncludePaths: []string{"internal/processor/**", "cmd/*/main.go"},
+	})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"internal/processor/processor.go", true},
+		{"internal/processor/sub/helper.go", true},
+		{"internal/processor/processor_test.go", false}, // excludes still apply
+		{"internal/format/format.go", false},
+		{"cmd/promptext/main.go", true},
+		{"cmd/promptext/usage.go", false},
+		{"cmd/main.go", false}, // "*" matches exactly one directory
+	}
+	for _, tt := range tests {
+		if got := f.ShouldProcess(tt.path); got != tt.want {
+			t.Errorf("ShouldProcess(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFilter_BackslashPatterns(t *testing.T) {
+	// Patterns written on Windows match the same files on every system
+	f := New(Options{
+		Excludes:     []string{`src\generated\`, `docs\*.md`},
+		IncludePaths: []string{`src\**`, `docs\**`},
+		EntryPoints:  []string{`cmd\*\main.go`},
+	})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"src/app.go", true},
+		{"src/generated/api.go", false},
+		{"docs/intro.md", false},
+		{"docs/intro.txt", true},
+		{"test/app_test.go", false}, // outside the include paths
+	}
+	for _, tt := range tests {
+		if got := f.ShouldProcess(tt.path); got != tt.want {
+			t.Errorf("ShouldProcess(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if !f.IsEntryPoint("cmd/promptext/main.go") {
+		t.Error("expected cmd/promptext/main.go to be an entry point")
+	}
+}
+
+func TestFilter_FrameworkExcludes(t *testing.T) {
+	django := New(Options{UseDefaultRules: true, Frameworks: []string{"django", "python"}})
+	plain := New(Options{UseDefaultRules: true})
+	noDefaults := New(Options{Frameworks: []string{"django"}})
+
+	for _, path := range []string{"staticfiles/admin/base.css", "media/avatar.txt"} {
+		if django.ShouldProcess(path) {
+			t.Errorf("expected %s excluded in a Django project", path)
+		}
+		if decision := django.Explain(path); decision.Rule != RuleDefault {
+			t.Errorf("Explain(%q) = %+v, want a default rule", path, decision)
+		}
+		if !plain.ShouldProcess(path) {
+			t.Errorf("expected %s kept without a detected framework", path)
+		}
+		if !noDefaults.ShouldProcess(path) {
+			t.Errorf("expected %s kept without default rules", path)
+		}
+	}
+	if !django.ShouldProcess("app/views.py") {
+		t.Error("expected app/views.py kept in a Django project")
+	}
+	if django.Signature() == plain.Signature() {
+		t.Error("expected detected frameworks in the signature")
+	}
+}
+
+func TestParseIncludeFile_NoFile(t *testing.T) {
+	patterns, err := ParseIncludeFile(t.TempDir())
+	if err != nil || patterns != nil {
+		t.Errorf("ParseIncludeFile() with missing file = %v, %v; want nil, nil", patterns, err)
+	}
+}
+
 func TestMergeAndDedupePatterns(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -360,7 +480,7 @@ func TestGetFileType(t *testing.T) {
 		},
 		{
 			name:         "rust source",
-			path:         "src/main.rs",
+			path:         "src/parser.rs",
 			wantType:     "source",
 			wantCategory: "source:rust",
 			wantTest:     false,
@@ -500,6 +620,8 @@ func TestIsEntryPoint(t *testing.T) {
 		{"app.py", true},
 		{"index.ts", true},
 		{"server.js", true},
+		{"src/main.rs", true},
+		{"cmd/tool/main.go", true},
 		{"helper.go", false},
 		{"utils.js", false},
 		{"config.py", false},
@@ -515,6 +637,39 @@ func TestIsEntryPoint(t *testing.T) {
 	}
 }
 
+func TestFilterEntryPoints(t *testing.T) {
+	f := New(Options{EntryPoints: []string{"cmd/*/main.go", "functions/*/handler.ts"}})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"cmd/api/main.go", true},
+		{"functions/checkout/handler.ts", true},
+		{"main.go", false},
+		{"internal/tools/main.go", false},
+		{"functions/checkout/util/handler.ts", false},
+		{"src/index.js", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := f.IsEntryPoint(tt.path); got != tt.want {
+				t.Errorf("IsEntryPoint(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	if got := GetFileType("functions/checkout/handler.ts", f); !got.IsEntryPoint || got.Category != "entry:ts" {
+		t.Errorf("GetFileType(handler.ts) = %+v, want an entry:ts entry point", got)
+	}
+	if !New(Options{}).IsEntryPoint("src/index.js") {
+		t.Error("expected the default entry points without configured ones")
+	}
+	if New(Options{EntryPoints: []string{"main.go"}}).Signature() == New(Options{}).Signature() {
+		t.Error("expected entry points to change the filter signature")
+	}
+}
+
 func TestGetConfigType(t *testing.T) {
 	tests := []struct {
 		ext          string
@@ -702,3 +857,45 @@ func TestNew_GitIgnoreIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestFilter_Selects(t *testing.T) {
+	f := New(Options{
+		Includes:        []string{".go", ".png"},
+		Excludes:        []string{"vendor/", "*.gen.go"},
+		UseDefaultRules: true,
+	})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"assets/logo.png", true}, // Skipped by binary detection, but chosen
+		{"main.go", true},
+		{"docs/readme.md", false},     // Not an included extension
+		{"vendor/lib/lib.go", false},  // User exclude
+		{"api/types.gen.go", false},   // User exclude
+		{"node_modules/x/a.go", true}, // Only a default rule skips it
+	}
+	for _, tt := range tests {
+		if got := f.Selects(tt.path); got != tt.want {
+			t.Errorf("Selects(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestFilter_ContentReason(t *testing.T) {
+	f := New(Options{SkipGenerated: true, ContentExcludes: []string{"VENDORED", "(["}})
+
+	if got := f.ContentReason("a.go", "// Code generated by x. DO NOT EDIT.\npackage a\n"); got != "generated" {
+		t.Errorf("expected generated, got %q", got)
+	}
+	if got := f.ContentReason("b.go", "// VENDORED\npackage b\n"); got != "content-pattern" {
+		t.Errorf("expected content-pattern, got %q", got)
+	}
+	if got := f.ContentReason("c.go", "package c\n"); got != "" {
+		t.Errorf("expected no reason, got %q", got)
+	}
+	if !f.ExcludesContent("b.go", "VENDORED") || f.ExcludesContent("c.go", "package c") {
+		t.Error("ExcludesContent should follow ContentReason")
+	}
+}
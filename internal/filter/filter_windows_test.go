@@ -0,0 +1,38 @@
+//go:build windows
+
+package filter
+
+import "testing"
+
+func TestFilter_WindowsPaths(t *testing.T) {
+	// The walker hands the filter paths with backslashes; patterns in either
+	// form match them
+	f := New(Options{
+		Excludes:     []string{"node_modules/", `build\`, "docs/*.md", "*.log"},
+		IncludePaths: []string{"src/**", `docs\**`},
+	})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{`src\app.go`, true},
+		{`src\node_modules\lib\index.js`, false},
+		{`src\build\out.go`, false},
+		{`src\debug.log`, false},
+		{`docs\intro.md`, false},
+		{`docs\guide\intro.md`, true}, // "*" stays within one directory
+		{`test\app.go`, false},
+	}
+	for _, tt := range tests {
+		if got := f.ShouldProcess(tt.path); got != tt.want {
+			t.Errorf("ShouldProcess(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if !f.IsExcluded(`src\node_modules`) {
+		t.Errorf(`expected src\node_modules to be excluded`)
+	}
+	if !f.Selects(`src\app.go`) || f.Selects(`src\build\out.go`) {
+		t.Errorf("Selects should match backslash paths like slash paths")
+	}
+}
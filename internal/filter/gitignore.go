@@ -0,0 +1,171 @@
+package filter
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"github.com/1broseidon/promptext/internal/filter/types"
+	"github.com/1broseidon/promptext/internal/log"
+)
+
+// loadGitIgnore collects the ignore files git would apply to root, in git's
+// order of increasing precedence: core.excludesFile, .git/info/exclude, and
+// every .gitignore from the repository top down to the deepest directory.
+// Outside a repository only the .gitignore files under root apply. Directories
+// for which skip returns true are not searched for .gitignore files. The
+// rule is nil if no ignore file has any patterns.
+//
+// files lists the ignore files consulted: those outside root whether or not
+// they exist, since creating one changes the patterns, and those under root
+// that exist, since creating one there changes its directory's mtime.
+func loadGitIgnore(root string, skip func(rel string) bool) (rule *rules.GitIgnoreRule, files []string) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		log.Debug("Cannot resolve %s for gitignore: %v", root, err)
+		return nil, nil
+	}
+
+	top, gitDir := findRepository(absRoot)
+	prefix, err := filepath.Rel(top, absRoot)
+	if err != nil {
+		return nil, nil
+	}
+	rule = rules.NewGitIgnoreRule(nil, filepath.ToSlash(prefix), types.Exclude)
+	count := 0
+	add := func(dir, file string) {
+		files = append(files, file)
+		patterns, err := parsePatternFile(file)
+		if err != nil {
+			log.Debug("Cannot read %s: %v", file, err)
+			return
+		}
+		if len(patterns) > 0 {
+			// Name files in the repository relative to root
+			source := file
+			if rel, err := filepath.Rel(absRoot, file); err == nil && strings.HasPrefix(file, top+string(filepath.Separator)) {
+				source = filepath.ToSlash(rel)
+			}
+			rule.Add(rules.IgnoreFile{Dir: filepath.ToSlash(dir), Patterns: patterns, Source: source})
+			count += len(patterns)
+			log.Debug("Gitignore patterns from %s: %d", file, len(patterns))
+		}
+	}
+
+	if gitDir != "" {
+		if excludes := globalExcludesFile(top); excludes != "" {
+			add("", excludes)
+		}
+		add("", filepath.Join(commonGitDir(gitDir), "info", "exclude"))
+
+		// .gitignore files between the repository top and root
+		if prefix != "." {
+			dir := ""
+			add(dir, filepath.Join(top, ".gitignore"))
+			for _, part := range strings.Split(filepath.Dir(prefix), string(filepath.Separator)) {
+				if part == "." {
+					break
+				}
+				dir = filepath.Join(dir, part)
+				add(dir, filepath.Join(top, dir, ".gitignore"))
+			}
+		}
+	}
+
+	_ = filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(absRoot, path)
+		if err != nil {
+			return nil
+		}
+		if rel != "." {
+			if d.Name() == ".git" || skip(rel) || rule.MatchDir(rel) {
+				return filepath.SkipDir
+			}
+		}
+		if gitignore := filepath.Join(path, ".gitignore"); fileExists(gitignore) {
+			add(filepath.Join(prefix, rel), gitignore)
+		}
+		return nil
+	})
+
+	if count == 0 {
+		return nil, files
+	}
+	log.Debug("Gitignore patterns: %d", count)
+	return rule, files
+}
+
+// fileExists reports whether path names an existing file
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// findRepository returns the repository top containing dir and its git
+// directory, or dir itself and "" when dir is not inside a repository
+func findRepository(dir string) (top, gitDir string) {
+	for current := dir; ; {
+		dotGit := filepath.Join(current, ".git")
+		if stat, err := os.Stat(dotGit); err == nil {
+			if stat.IsDir() {
+				return current, dotGit
+			}
+			// Worktrees and submodules point at their git directory
+			if data, err := os.ReadFile(dotGit); err == nil {
+				if target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:"); ok {
+					target = strings.TrimSpace(target)
+					if !filepath.IsAbs(target) {
+						target = filepath.Join(current, target)
+					}
+					return current, target
+				}
+			}
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir, ""
+		}
+		current = parent
+	}
+}
+
+// commonGitDir returns the directory holding info/exclude, which linked
+// worktrees share with the main repository
+func commonGitDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+	common := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(gitDir, common)
+	}
+	return common
+}
+
+// globalExcludesFile returns the path of git's core.excludesFile, defaulting
+// to $XDG_CONFIG_HOME/git/ignore as git does when it is unset
+func globalExcludesFile(top string) string {
+	cmd := exec.Command("git", "config", "--path", "--get", "core.excludesFile")
+	cmd.Dir = top
+	if out, err := cmd.Output(); err == nil {
+		if path := strings.TrimSpace(string(out)); path != "" {
+			return path
+		}
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "git", "ignore")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config", "git", "ignore")
+	}
+	return ""
+}
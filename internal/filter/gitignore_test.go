@@ -0,0 +1,125 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// isolateGitConfig keeps the user's git configuration out of a test
+func isolateGitConfig(t *testing.T, globalConfig string) {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+}
+
+func TestNew_NestedGitIgnore(t *testing.T) {
+	isolateGitConfig(t, os.DevNull)
+	root := t.TempDir()
+	createTestFile(t, filepath.Join(root, ".git", "info", "exclude"), []byte("*.local\n"))
+	createTestFile(t, filepath.Join(root, ".gitignore"), []byte("*.log\n"))
+	createTestFile(t, filepath.Join(root, "packages", "web", ".gitignore"), []byte("dist/\n!keep.log\n!dev.local\n"))
+	createTestFile(t, filepath.Join(root, "packages", "api", ".gitignore"), []byte("/tmp/\n"))
+
+	f := New(Options{UseGitIgnore: true, Root: root})
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"debug.log", false},
+		{"packages/web/keep.log", true},        // Negated in the nested file
+		{"packages/api/keep.log", false},       // Sibling keeps the root rule
+		{"packages/web/dist/app.js", false},    // Nested directory pattern
+		{"dist/app.js", true},                  // Nested pattern stays in its package
+		{"packages/api/tmp/cache.json", false}, // Anchored to packages/api
+		{"packages/api/src/tmp/cache.json", true},
+		{"settings.local", false},           // .git/info/exclude
+		{"packages/web/dev.local", true},    // .gitignore outranks info/exclude
+		{"packages/web/src/index.ts", true}, // Not ignored anywhere
+	}
+	for _, tt := range tests {
+		if got := f.ShouldProcess(tt.path); got != tt.want {
+			t.Errorf("ShouldProcess(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if f.Selects("packages/web/dist/app.js") {
+		t.Error("expected ignored files to be outside the user's selection")
+	}
+
+	// The files read, and info/exclude and core.excludesFile even when absent
+	files := map[string]bool{}
+	for _, path := range f.IgnoreFiles() {
+		files[path] = true
+	}
+	for _, want := range []string{
+		filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "git", "ignore"),
+		filepath.Join(root, ".git", "info", "exclude"),
+		filepath.Join(root, ".gitignore"),
+		filepath.Join(root, "packages", "web", ".gitignore"),
+	} {
+		if !files[want] {
+			t.Errorf("expected %s in IgnoreFiles(), got %v", want, f.IgnoreFiles())
+		}
+	}
+	if files[filepath.Join(root, "packages", ".gitignore")] {
+		t.Errorf("expected missing .gitignore files under the root left out, got %v", f.IgnoreFiles())
+	}
+}
+
+func TestNew_GlobalExcludesFile(t *testing.T) {
+	home := t.TempDir()
+	excludes := filepath.Join(home, "ignore")
+	createTestFile(t, excludes, []byte("*.swp\n.idea/\n"))
+	gitconfig := filepath.Join(home, "gitconfig")
+	createTestFile(t, gitconfig, []byte("[core]\n\texcludesFile = "+excludes+"\n"))
+	isolateGitConfig(t, gitconfig)
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	createTestFile(t, filepath.Join(root, ".gitignore"), []byte("!keep.swp\n"))
+
+	// core.excludesFile comes from git's configuration
+	if globalExcludesFile(root) == "" {
+		t.Fatal("expected a global excludes file")
+	}
+	f := New(Options{UseGitIgnore: true, Root: root})
+	for path, want := range map[string]bool{
+		"main.go.swp":     false,
+		".idea/workspace": false,
+		"keep.swp":        true, // The project outranks the global file
+		"main.go":         true,
+	} {
+		if got := f.ShouldProcess(path); got != want {
+			t.Errorf("ShouldProcess(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestNew_GitIgnoreFromRepositoryTop(t *testing.T) {
+	isolateGitConfig(t, os.DevNull)
+	top := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(top, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	createTestFile(t, filepath.Join(top, ".gitignore"), []byte("*.log\n/packages/web/generated/\n"))
+	createTestFile(t, filepath.Join(top, "packages", ".gitignore"), []byte("web/secret.txt\n"))
+	root := filepath.Join(top, "packages", "web")
+	createTestFile(t, filepath.Join(root, "main.go"), []byte("package main\n"))
+
+	// Extracting a subdirectory still honors the ignore files above it
+	f := New(Options{UseGitIgnore: true, Root: root})
+	for path, want := range map[string]bool{
+		"app.log":            false,
+		"generated/types.go": false,
+		"secret.txt":         false,
+		"main.go":            true,
+	} {
+		if got := f.ShouldProcess(path); got != want {
+			t.Errorf("ShouldProcess(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"bytes"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -68,6 +69,15 @@ func NewBinaryRule() types.Rule {
 // 2. File size check (fast - single stat call, no content read)
 // 3. Content analysis (slowest - reads file content as last resort)
 func (r *BinaryRule) Match(path string) bool {
+	return r.match(path, func() (fs.FileInfo, error) { return os.Stat(path) }, func() (fs.File, error) { return os.Open(path) })
+}
+
+// MatchFS is Match for the file at name in fsys
+func (r *BinaryRule) MatchFS(fsys fs.FS, name string) bool {
+	return r.match(name, func() (fs.FileInfo, error) { return fs.Stat(fsys, name) }, func() (fs.File, error) { return fsys.Open(name) })
+}
+
+func (r *BinaryRule) match(path string, stat func() (fs.FileInfo, error), open func() (fs.File, error)) bool {
 	// Stage 1: Check file extension first - fastest method with no I/O
 	ext := strings.ToLower(filepath.Ext(path))
 	if binaryExtensions[ext] {
@@ -76,7 +86,7 @@ func (r *BinaryRule) Match(path string) bool {
 
 	// Stage 2: Check file size - very large files are likely binary
 	// This avoids reading content for obviously binary files like large media/archives
-	fileInfo, err := os.Stat(path)
+	fileInfo, err := stat()
 	if err != nil {
 		return false
 	}
@@ -94,12 +104,17 @@ func (r *BinaryRule) Match(path string) bool {
 
 	// Stage 3: Content analysis - only for files that passed previous checks
 	// This is the expensive operation we want to minimize
-	return r.isBinaryContent(path)
+	return r.isBinaryFile(open)
 }
 
 // isBinaryContent performs content-based binary detection
 func (r *BinaryRule) isBinaryContent(path string) bool {
-	file, err := os.Open(path)
+	return r.isBinaryFile(func() (fs.File, error) { return os.Open(path) })
+}
+
+// isBinaryFile inspects the start of the file open returns
+func (r *BinaryRule) isBinaryFile(open func() (fs.File, error)) bool {
+	file, err := open()
 	if err != nil {
 		return false
 	}
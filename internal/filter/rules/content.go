@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// headerSize is how much of a file is searched for generated-code markers;
+// tools put them in the leading comment
+const headerSize = 4096
+
+// generatedContentPatterns are header markers written by code generators
+var generatedContentPatterns = []*regexp.Regexp{
+	// Go convention: https://go.dev/s/generatedcode
+	regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`),
+	// protoc output for C++, Java, Python, and others
+	regexp.MustCompile(`(?m)^\s*(?://|#) Generated by the protocol buffer compiler\.\s+DO NOT EDIT!`),
+	// Facebook-style marker (Relay, Hack, Buck, ...)
+	regexp.MustCompile(`(?m)^\s*(?://|#|/\*|\*)\s*@generated\b`),
+	// .NET tooling
+	regexp.MustCompile(`(?m)^\s*// <auto-generated`),
+}
+
+// minifiableExtensions are the file types checked for minified content
+var minifiableExtensions = map[string]bool{
+	".js": true, ".mjs": true, ".cjs": true, ".css": true,
+}
+
+// ContentRule excludes files by what they contain rather than their path,
+// for generated code that sits next to hand-written sources under ordinary
+// names. Unlike path rules it can only be applied once a file has been read.
+type ContentRule struct {
+	patterns  []*regexp.Regexp // Matched against the whole content
+	generated bool             // Also apply the built-in generated-code checks
+}
+
+// NewContentRule creates a rule excluding files whose content matches any of patterns
+func NewContentRule(patterns []*regexp.Regexp) *ContentRule {
+	return &ContentRule{patterns: patterns}
+}
+
+// NewGeneratedContentRule creates a rule excluding generated files: Go, protoc,
+// @generated and .NET markers in the file header, and minified JS/CSS
+func NewGeneratedContentRule() *ContentRule {
+	return &ContentRule{generated: true}
+}
+
+// Generated reports whether the rule applies the built-in generated-code checks
+func (r *ContentRule) Generated() bool {
+	return r.generated
+}
+
+// MatchContent reports whether the file at path with the given content is excluded
+func (r *ContentRule) MatchContent(path, content string) bool {
+	for _, pattern := range r.patterns {
+		if pattern.MatchString(content) {
+			return true
+		}
+	}
+	if !r.generated {
+		return false
+	}
+
+	header := content
+	if len(header) > headerSize {
+		header = header[:headerSize]
+	}
+	for _, pattern := range generatedContentPatterns {
+		if pattern.MatchString(header) {
+			return true
+		}
+	}
+	return IsMinifiedFile(path, content)
+}
+
+// IsMinifiedFile reports whether the file at path is minified JS or CSS
+func IsMinifiedFile(path, content string) bool {
+	return minifiableExtensions[strings.ToLower(filepath.Ext(path))] && IsMinified(content)
+}
+
+// IsMinified reports whether content looks minified: long enough to matter,
+// with very long lines on average and at least one line far beyond what a
+// formatter would produce
+func IsMinified(content string) bool {
+	if len(content) < 1024 {
+		return false
+	}
+	lines := strings.Split(content, "\n")
+	longest := 0
+	for _, line := range lines {
+		longest = max(longest, len(line))
+	}
+	return longest > 1000 && len(content)/len(lines) > 200
+}
@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedContentRule(t *testing.T) {
+	rule := NewGeneratedContentRule()
+	minified := strings.Repeat("var a=function(b){return b+1};", 100)
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    bool
+	}{
+		{"go generated", "api.pb.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n", true},
+		{"go stringer", "kind_string.go", "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage x\n", true},
+		{"protoc python", "api_pb2.py", "# -*- coding: utf-8 -*-\n# Generated by the protocol buffer compiler.  DO NOT EDIT!\n", true},
+		{"at-generated", "schema.js", "/**\n * @generated SignedSource<<abc>>\n */\n", true},
+		{"dotnet", "Model.g.cs", "// <auto-generated>\n//   This code was generated by a tool.\n", true},
+		{"minified js", "vendor.js", minified, true},
+		{"long line in go", "table.go", minified, false},
+		{"handwritten", "main.go", "package main\n\n// Generated reports are saved here; do not edit them by hand.\nfunc main() {}\n", false},
+		{"marker after header", "doc.go", strings.Repeat("// filler\n", 500) + "// Code generated by x. DO NOT EDIT.\n", false},
+	}
+	for _, tt := range tests {
+		if got := rule.MatchContent(tt.path, tt.content); got != tt.want {
+			t.Errorf("%s: MatchContent(%q) = %v, want %v", tt.name, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestContentRulePatterns(t *testing.T) {
+	rule := NewContentRule([]*regexp.Regexp{regexp.MustCompile(`(?m)^# vendored from`)})
+
+	if !rule.MatchContent("lib.py", "import os\n# vendored from upstream v1.2\n") {
+		t.Error("expected the custom pattern to match anywhere in the content")
+	}
+	if rule.MatchContent("gen.go", "// Code generated by x. DO NOT EDIT.\n") {
+		t.Error("custom rules should not apply the generated-code checks")
+	}
+}
+
+func TestIsMinified(t *testing.T) {
+	if IsMinified("function a() {\n  return 1;\n}\n") {
+		t.Error("short formatted code is not minified")
+	}
+	if !IsMinified(strings.Repeat("x", 5000)) {
+		t.Error("expected a single 5000-character line to count as minified")
+	}
+	if IsMinified(strings.Repeat("x", 1200) + strings.Repeat("\nshort", 400)) {
+		t.Error("one long line among many short ones is not minified")
+	}
+}
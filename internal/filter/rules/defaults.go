@@ -16,6 +16,9 @@ func DefaultExcludes() []types.Rule {
 			".hg/",
 			".bzr/",
 
+			// promptext's own file cache
+			".promptext-cache/",
+
 			// ========== JavaScript/TypeScript/Node.js ==========
 			// Dependencies
 			"node_modules/",
@@ -0,0 +1,27 @@
+package rules
+
+// frameworkExcludes are build output and runtime directories of frameworks
+// that the default excludes leave alone, since in other projects the same
+// names can hold source. Keys are the initializer's project type names.
+var frameworkExcludes = map[string][]string{
+	"angular":   {".angular/"},
+	"django":    {"staticfiles/", "media/", "db.sqlite3"},
+	"flask":     {"instance/"},
+	"kotlin":    {".kotlin/"},
+	"laravel":   {"storage/", "bootstrap/cache/", "public/build/", "public/hot"},
+	"nextjs":    {"next-env.d.ts"},
+	"phoenix":   {"priv/static/"},
+	"ruby":      {"log/", "public/assets/", "public/packs/"},
+	"terraform": {"*.tfstate", "*.tfstate.*", "*.tfplan"},
+	"vite":      {".vite/"},
+}
+
+// FrameworkExcludes returns the exclude patterns for the detected
+// frameworks, such as staticfiles/ for Django
+func FrameworkExcludes(frameworks []string) []string {
+	var patterns []string
+	for _, name := range frameworks {
+		patterns = append(patterns, frameworkExcludes[name]...)
+	}
+	return patterns
+}
@@ -0,0 +1,123 @@
+package rules
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter/types"
+)
+
+// IgnoreFile is one source of ignore patterns and the directory they are
+// relative to, slash-separated from the repository top ("" for the top itself)
+type IgnoreFile struct {
+	Dir      string
+	Patterns []string
+	Source   string // Where the patterns come from, for Explain
+}
+
+// ignorePattern is a parsed pattern and the ignore file it came from
+type ignorePattern struct {
+	gitPattern
+	source string
+}
+
+// GitIgnoreRule matches paths the way git applies its ignore files. Each file's
+// patterns are relative to its own directory and only apply below it. Files
+// are given in increasing precedence (core.excludesFile, .git/info/exclude,
+// then .gitignore files from the top down) and, as in git, the last matching
+// pattern decides. Once a directory is ignored nothing below it can be
+// re-included.
+type GitIgnoreRule struct {
+	types.BaseRule
+	prefix []string                   // Project root relative to the repository top
+	dirs   map[string][]ignorePattern // Patterns by the directory they apply below
+}
+
+// NewGitIgnoreRule creates a rule from ignore files in increasing precedence.
+// prefix is the project root relative to the repository top; matched paths are
+// relative to the project root.
+func NewGitIgnoreRule(files []IgnoreFile, prefix string, action types.RuleAction) *GitIgnoreRule {
+	var prefixParts []string
+	if p := cleanSlash(prefix); p != "" {
+		prefixParts = strings.Split(p, "/")
+	}
+
+	r := &GitIgnoreRule{
+		BaseRule: types.NewBaseRule("", action),
+		prefix:   prefixParts,
+		dirs:     make(map[string][]ignorePattern),
+	}
+	for _, file := range files {
+		r.Add(file)
+	}
+	return r
+}
+
+// Add appends an ignore file that takes precedence over those added before
+func (r *GitIgnoreRule) Add(file IgnoreFile) {
+	dir := cleanSlash(file.Dir)
+	for _, gp := range parseGitPatterns(file.Patterns) {
+		r.dirs[dir] = append(r.dirs[dir], ignorePattern{gitPattern: gp, source: file.Source})
+	}
+}
+
+// Match reports whether the file at p, relative to the project root, is ignored
+func (r *GitIgnoreRule) Match(p string) bool {
+	_, _, ignored := r.match(p, false)
+	return ignored
+}
+
+// MatchDir reports whether the directory at p, relative to the project root,
+// is ignored
+func (r *GitIgnoreRule) MatchDir(p string) bool {
+	_, _, ignored := r.match(p, true)
+	return ignored
+}
+
+// Explain returns the ignore file and pattern that ignore the file at p
+func (r *GitIgnoreRule) Explain(p string) (source, pattern string, ignored bool) {
+	return r.match(p, false)
+}
+
+func (r *GitIgnoreRule) match(p string, isDir bool) (source, pattern string, ignored bool) {
+	rel := cleanSlash(p)
+	if rel == "" {
+		return "", "", false
+	}
+	parts := append(append([]string(nil), r.prefix...), strings.Split(rel, "/")...)
+
+	// Parent directories inside the project come first: an ignored
+	// directory takes everything below it along
+	for n := len(r.prefix) + 1; n <= len(parts); n++ {
+		if decided := r.decide(parts[:n], isDir || n < len(parts)); decided != nil && !decided.negate {
+			return decided.source, decided.text, true
+		}
+	}
+	return "", "", false
+}
+
+// decide evaluates the patterns of every directory above target, from the top
+// down, and returns the last one matching, which decides, or nil
+func (r *GitIgnoreRule) decide(target []string, isDir bool) *ignorePattern {
+	var decided *ignorePattern
+	for depth := 0; depth < len(target); depth++ {
+		patterns := r.dirs[strings.Join(target[:depth], "/")]
+		for i := range patterns {
+			gp := &patterns[i]
+			if gp.negate == (decided != nil && !decided.negate) && gp.matchesTarget(target[depth:], isDir) {
+				decided = gp
+			}
+		}
+	}
+	return decided
+}
+
+// cleanSlash normalizes a relative path to slash form, with "" for the root
+func cleanSlash(p string) string {
+	p = path.Clean(filepath.ToSlash(p))
+	if p == "." || p == "/" {
+		return ""
+	}
+	return p
+}
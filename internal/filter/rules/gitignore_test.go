@@ -0,0 +1,88 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitIgnoreRule_Match(t *testing.T) {
+	rule := NewGitIgnoreRule([]IgnoreFile{
+		{Dir: "", Patterns: []string{"*.log", "build/", "/root-only.txt"}},
+		{Dir: "packages/web", Patterns: []string{"dist/", "!keep.log", "/local.txt"}},
+		{Dir: "packages/web/src", Patterns: []string{"*.tmp", "!important.tmp"}},
+	}, "", types.Exclude)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"debug.log", true},
+		{"packages/api/debug.log", true},
+		{"packages/web/keep.log", false},      // Deeper negation wins
+		{"packages/web/src/keep.log", false},  // Unanchored negation applies below its directory
+		{"packages/api/keep.log", true},       // Out of scope of packages/web
+		{"packages/web/dist/app.js", true},    // Nested directory pattern
+		{"dist/app.js", false},                // Nested pattern does not reach the root
+		{"packages/web/local.txt", true},      // Anchored to its own directory
+		{"packages/web/src/local.txt", false}, // Anchored patterns do not match deeper
+		{"root-only.txt", true},
+		{"packages/web/root-only.txt", false},
+		{"packages/web/src/a.tmp", true},
+		{"packages/web/src/important.tmp", false},
+		{"packages/web/a.tmp", false},
+		{"build/out.bin", true},
+		{"build", false}, // A file named build is not a directory
+		{"src/main.go", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rule.Match(tt.path), tt.path)
+	}
+	assert.True(t, rule.MatchDir("build"))
+}
+
+func TestGitIgnoreRule_IgnoredDirectoryCannotBeReincluded(t *testing.T) {
+	rule := NewGitIgnoreRule([]IgnoreFile{
+		{Patterns: []string{"vendor/", "!vendor/keep.go"}},
+		{Patterns: []string{"logs/*", "!logs/keep.txt"}},
+	}, "", types.Exclude)
+
+	assert.True(t, rule.Match("vendor/keep.go"))
+	assert.False(t, rule.Match("logs/keep.txt"))
+	assert.True(t, rule.Match("logs/other.txt"))
+}
+
+func TestGitIgnoreRule_Prefix(t *testing.T) {
+	// The project root is packages/web inside the repository
+	rule := NewGitIgnoreRule([]IgnoreFile{
+		{Dir: "", Patterns: []string{"/packages/web/generated/", "*.log"}},
+		{Dir: "packages", Patterns: []string{"web/secret.txt"}},
+	}, "packages/web", types.Exclude)
+
+	assert.True(t, rule.Match("generated/types.go"))
+	assert.True(t, rule.Match("secret.txt"))
+	assert.True(t, rule.Match("src/app.log"))
+	assert.False(t, rule.Match("src/app.go"))
+	assert.False(t, rule.Match("."))
+}
+
+func TestGitIgnoreRule_Explain(t *testing.T) {
+	rule := NewGitIgnoreRule([]IgnoreFile{
+		{Patterns: []string{"*.log"}, Source: ".gitignore"},
+		{Dir: "web", Patterns: []string{"!keep.log", "dist/"}, Source: "web/.gitignore"},
+	}, "", types.Exclude)
+
+	source, pattern, ignored := rule.Explain("web/dist/app.js")
+	assert.True(t, ignored)
+	assert.Equal(t, "web/.gitignore", source)
+	assert.Equal(t, "dist/", pattern)
+
+	source, pattern, ignored = rule.Explain("api/debug.log")
+	assert.True(t, ignored)
+	assert.Equal(t, ".gitignore", source)
+	assert.Equal(t, "*.log", pattern)
+
+	_, _, ignored = rule.Explain("web/keep.log")
+	assert.False(t, ignored)
+}
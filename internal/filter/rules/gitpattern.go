@@ -0,0 +1,126 @@
+package rules
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter/types"
+)
+
+// GitPatternRule matches paths using .gitignore pattern semantics:
+//   - a pattern without a slash matches a file or directory name at any depth
+//   - a pattern containing a slash is anchored to the project root
+//   - a trailing slash matches directories (and everything below them) only
+//   - "**" matches any number of directories
+//   - a leading "!" negates a pattern; the last matching pattern wins
+//
+// A path also matches when one of its parent directories matches.
+type GitPatternRule struct {
+	types.BaseRule
+	patterns []gitPattern
+}
+
+type gitPattern struct {
+	text     string // The pattern as written
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+func NewGitPatternRule(patterns []string, action types.RuleAction) types.Rule {
+	return &GitPatternRule{
+		BaseRule: types.NewBaseRule("", action),
+		patterns: parseGitPatterns(patterns),
+	}
+}
+
+// parseGitPatterns parses gitignore-style lines, skipping blanks and comments
+func parseGitPatterns(patterns []string) []gitPattern {
+	var parsed []gitPattern
+	for _, raw := range patterns {
+		p := strings.TrimSpace(raw)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+
+		gp := gitPattern{text: p}
+		if strings.HasPrefix(p, "!") {
+			gp.negate = true
+			p = p[1:]
+		}
+		if strings.HasSuffix(p, "/") {
+			gp.dirOnly = true
+			p = strings.TrimRight(p, "/")
+		}
+		gp.anchored = strings.Contains(p, "/")
+		p = strings.TrimPrefix(p, "/")
+		if p == "" {
+			continue
+		}
+
+		gp.segments = strings.Split(p, "/")
+		parsed = append(parsed, gp)
+	}
+	return parsed
+}
+
+func (r *GitPatternRule) Match(p string) bool {
+	parts := strings.Split(filepath.ToSlash(path.Clean(filepath.ToSlash(p))), "/")
+
+	matched := false
+	for _, gp := range r.patterns {
+		if gp.negate == matched && gp.matches(parts) {
+			matched = !gp.negate
+		}
+	}
+	return matched
+}
+
+// matches reports whether the pattern matches the path or one of its parents
+func (gp gitPattern) matches(parts []string) bool {
+	for n := len(parts); n > 0; n-- {
+		// The full path is treated as a file, every prefix as a directory
+		// The full path is treated as a file, every prefix as a directory
+		if gp.matchesTarget(parts[:n], n < len(parts)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTarget reports whether the pattern matches exactly this path
+func (gp gitPattern) matchesTarget(target []string, isDir bool) bool {
+	if gp.dirOnly && !isDir {
+		return false
+	}
+	if gp.anchored {
+		return matchSegments(gp.segments, target)
+	}
+	matched, _ := path.Match(gp.segments[0], target[len(target)-1])
+	return matched
+}
+
+// matchSegments matches pattern segments against path segments, with "**"
+// standing for zero or more path segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], parts[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
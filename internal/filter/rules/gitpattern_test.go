@@ -0,0 +1,43 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitPatternRule_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{"bare name at root", []string{"main.go"}, "main.go", true},
+		{"bare name at depth", []string{"main.go"}, "cmd/app/main.go", true},
+		{"bare glob", []string{"*.md"}, "docs/guide/intro.md", true},
+		{"bare directory name covers contents", []string{"internal"}, "internal/auth/auth.go", true},
+		{"anchored path", []string{"cmd/app/main.go"}, "cmd/app/main.go", true},
+		{"anchored path does not float", []string{"app/main.go"}, "cmd/app/main.go", false},
+		{"leading slash anchors", []string{"/README.md"}, "docs/README.md", false},
+		{"directory pattern covers contents", []string{"src/"}, "src/lib/util.go", true},
+		{"directory pattern does not match file", []string{"src/"}, "src", false},
+		{"double star prefix", []string{"**/handlers/*.go"}, "internal/api/handlers/user.go", true},
+		{"double star middle", []string{"pkg/**/*.go"}, "pkg/a/b/c.go", true},
+		{"double star matches zero dirs", []string{"pkg/**/*.go"}, "pkg/c.go", true},
+		{"negation removes match", []string{"src/", "!*_test.go"}, "src/util_test.go", false},
+		{"negation keeps others", []string{"src/", "!*_test.go"}, "src/util.go", true},
+		{"later pattern re-adds", []string{"src/", "!src/gen/", "src/gen/keep.go"}, "src/gen/keep.go", true},
+		{"comments and blanks ignored", []string{"# comment", "", "go.mod"}, "go.mod", true},
+		{"no match", []string{"src/"}, "docs/intro.md", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := NewGitPatternRule(tt.patterns, types.Include)
+			assert.Equal(t, tt.want, rule.Match(tt.path))
+			assert.Equal(t, types.Include, rule.Action())
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package rules
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// fileKinds groups the known binary extensions by what they contain
+var fileKinds = map[string][]string{
+	"image":      {".jpg", ".jpeg", ".png", ".gif", ".bmp", ".tiff", ".tif", ".webp", ".ico", ".svg", ".psd", ".ai", ".eps", ".raw", ".cr2", ".nef"},
+	"audio":      {".mp3", ".wav", ".flac", ".aac", ".ogg", ".wma", ".m4a"},
+	"video":      {".mp4", ".avi", ".mkv", ".mov", ".wmv", ".flv", ".webm", ".m4v"},
+	"archive":    {".zip", ".tar", ".gz", ".7z", ".rar", ".bz2", ".xz", ".tgz", ".tbz", ".tbz2", ".lz", ".lzma", ".jar", ".war"},
+	"document":   {".pdf", ".doc", ".docx", ".odt", ".xls", ".xlsx", ".ods", ".ppt", ".pptx", ".odp", ".rtf", ".pages", ".numbers", ".key"},
+	"font":       {".ttf", ".otf", ".woff", ".woff2", ".eot"},
+	"database":   {".db", ".sqlite", ".sqlite3", ".mdb", ".accdb", ".dbf"},
+	"executable": {".exe", ".dll", ".so", ".dylib", ".bin", ".obj", ".o", ".a", ".lib", ".app", ".msi", ".deb", ".rpm", ".pkg"},
+	"bytecode":   {".class", ".pyc", ".pyo", ".pyd", ".wasm"},
+	"disk-image": {".iso", ".dmg", ".img"},
+}
+
+var kindByExtension = func() map[string]string {
+	m := make(map[string]string)
+	for kind, exts := range fileKinds {
+		for _, ext := range exts {
+			m[ext] = kind
+		}
+	}
+	return m
+}()
+
+// FileKind names the kind of content a file holds based on its extension
+// ("image", "archive", "font", ...), or "" if the extension isn't known
+func FileKind(path string) string {
+	return kindByExtension[strings.ToLower(filepath.Ext(path))]
+}
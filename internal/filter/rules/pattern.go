@@ -2,6 +2,7 @@ package rules
 
 import (
 	"github.com/1broseidon/promptext/internal/filter/types"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -22,8 +23,15 @@ func NewPatternRule(patterns []string, action types.RuleAction) types.Rule {
 	}
 }
 
-func (r *PatternRule) Match(path string) bool {
-	normalizedPath := filepath.ToSlash(path)
+func (r *PatternRule) Match(p string) bool {
+	_, matched := r.MatchingPattern(p)
+	return matched
+}
+
+// MatchingPattern returns the first pattern matching p. Paths and patterns
+// are compared with forward slashes, so neither needs the host's separator.
+func (r *PatternRule) MatchingPattern(p string) (string, bool) {
+	normalizedPath := filepath.ToSlash(p)
 	for _, pattern := range r.patterns {
 		pattern = filepath.ToSlash(pattern)
 
@@ -31,16 +39,22 @@ func (r *PatternRule) Match(path string) bool {
 		if strings.HasSuffix(pattern, "/") {
 			if strings.HasPrefix(normalizedPath, pattern) ||
 				strings.Contains(normalizedPath, "/"+pattern) {
-				return true
+				return pattern, true
 			}
 			continue
 		}
 
-		// Handle wildcard patterns (e.g., .aider*)
+		// Handle wildcard patterns (e.g., .aider*), against the file name or,
+		// for a pattern with directories (e.g., docs/*.md), the path's tail
 		if strings.Contains(pattern, "*") {
-			matched, _ := filepath.Match(pattern, filepath.Base(normalizedPath))
-			if matched {
-				return true
+			if !strings.Contains(pattern, "/") {
+				if matched, _ := path.Match(pattern, path.Base(normalizedPath)); matched {
+					return pattern, true
+				}
+				continue
+			}
+			if matchTail(pattern, normalizedPath) {
+				return pattern, true
 			}
 			continue
 		}
@@ -49,8 +63,23 @@ func (r *PatternRule) Match(path string) bool {
 		if strings.HasPrefix(normalizedPath, pattern) ||
 			strings.Contains(normalizedPath, "/"+pattern) ||
 			normalizedPath == pattern {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// matchTail reports whether pattern matches p or the part of p after one of
+// its slashes, so "docs/*.md" matches "docs/a.md" and "site/docs/a.md"
+func matchTail(pattern, p string) bool {
+	for {
+		if matched, _ := path.Match(pattern, p); matched {
 			return true
 		}
+		i := strings.Index(p, "/")
+		if i < 0 {
+			return false
+		}
+		p = p[i+1:]
 	}
-	return false
 }
@@ -144,6 +144,25 @@ func TestPatternRule_Match_WildcardPatterns(t *testing.T) {
 	}
 }
 
+func TestPatternRule_Match_WildcardDirectoryPatterns(t *testing.T) {
+	rule := NewPatternRule([]string{"docs/*.md", "src/gen/*"}, types.Exclude)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"docs/intro.md", true},
+		{"site/docs/intro.md", true},   // the pattern may match the path's tail
+		{"docs/guide/intro.md", false}, // "*" stays within one directory
+		{"src/gen/api.go", true},
+		{"src/generated/api.go", false}, // whole directory names only
+		{"mydocs/intro.md", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, rule.Match(tt.path), "Path: %s", tt.path)
+	}
+}
+
 func TestPatternRule_Match_ExactAndPathPatterns(t *testing.T) {
 	rule := NewPatternRule([]string{
 		".DS_Store",
@@ -388,3 +407,18 @@ func BenchmarkPatternRule_NoMatch(b *testing.B) {
 		rule.Match(path)
 	}
 }
+
+func TestPatternRule_MatchingPattern(t *testing.T) {
+	rule := NewPatternRule([]string{"vendor/", "*.log", "secret.txt"}, types.Exclude).(*PatternRule)
+
+	pattern, matched := rule.MatchingPattern("app/debug.log")
+	assert.True(t, matched)
+	assert.Equal(t, "*.log", pattern)
+
+	pattern, matched = rule.MatchingPattern("vendor/lib/a.go")
+	assert.True(t, matched)
+	assert.Equal(t, "vendor/", pattern)
+
+	_, matched = rule.MatchingPattern("main.go")
+	assert.False(t, matched)
+}
@@ -16,6 +16,9 @@ const (
 	FormatTOONStrict OutputFormat = "toon-strict" // TOON v1.3 strict compliance
 	FormatTOONV13    OutputFormat = "toon-v1.3"   // Alias for toon-strict
 	FormatJSONL      OutputFormat = "jsonl"       // JSONL - machine-friendly sidecar format
+	FormatJSON       OutputFormat = "json"        // Single JSON document with a published schema
+	FormatHTML       OutputFormat = "html"        // Self-contained HTML page for human review
+	FormatPDF        OutputFormat = "pdf"         // Paginated PDF of the markdown output for archival
 )
 
 // DirectoryNode represents a node in the directory tree
@@ -35,8 +38,113 @@ type ProjectOutput struct {
 	FileStats     *FileStatistics  `xml:"fileStats,omitempty"`
 	Dependencies  *DependencyInfo  `xml:"dependencies,omitempty"`
 	Analysis      *ProjectAnalysis `xml:"analysis,omitempty"`
-	Budget        *BudgetInfo      `xml:"budget,omitempty"`       // PTX v2.0: Token budget tracking
-	FilterConfig  *FilterConfig    `xml:"filterConfig,omitempty"` // PTX v2.0: Filter configuration used
+	Budget        *BudgetInfo      `xml:"budget,omitempty"`               // PTX v2.0: Token budget tracking
+	FilterConfig  *FilterConfig    `xml:"filterConfig,omitempty"`         // PTX v2.0: Filter configuration used
+	Packages      []PackageInfo    `xml:"packages>package,omitempty"`     // Go/JS packages and their inter-package imports
+	Workspace     *WorkspaceInfo   `xml:"workspace,omitempty"`            // Monorepo member packages and their dependencies on each other
+	RecentCommits []CommitInfo     `xml:"recentCommits>commit,omitempty"` // Latest commits, newest first
+	Redactions    *RedactionInfo   `xml:"redactions,omitempty"`           // Secrets replaced with placeholders (nil unless redaction is on)
+	SkippedFiles  []SkippedFile    `xml:"skippedFiles>file,omitempty"`    // Stubs for files whose content was left out
+	Issues        []Issue          `xml:"issues>issue,omitempty"`         // TODO, FIXME and HACK comments in the included files
+	Metrics       *MetricsInfo     `xml:"metrics,omitempty"`              // Size and complexity of the included source files
+	Split         *SplitInfo       `xml:"split,omitempty"`                // Position of this output when split across parts
+	Selection     *SelectionReport `xml:"selection,omitempty"`            // Why each file was ranked, kept or dropped (explain-selection)
+	Integrity     *IntegrityInfo   `xml:"integrity,omitempty"`            // Hash over the included files' hashes (nil unless hashes are on)
+}
+
+// IntegrityInfo records the content hash of an output: the SHA-256 of its
+// files' hashes (see ContentHash), so a reader can tell whether the files it
+// lists still match the workspace
+type IntegrityInfo struct {
+	Algorithm string `xml:"algorithm,attr"` // Always "sha256"
+	Content   string `xml:"content,attr"`   // Hex-encoded ContentHash of the output's files
+}
+
+// SelectionReport explains file selection: every candidate file in priority
+// order with its relevance score, the factors behind it, and whether it made
+// it into the output
+type SelectionReport struct {
+	Threshold float64          `xml:"threshold,attr"` // Score at which a file counts as highly relevant
+	Files     []SelectionEntry `xml:"file"`
+}
+
+// SelectionEntry is one candidate file in a SelectionReport
+type SelectionEntry struct {
+	Path      string   `xml:"path,attr"`
+	Tokens    int      `xml:"tokens,attr"`
+	Score     float64  `xml:"score,attr"`     // Relevance score (0 without keywords or a custom scorer)
+	Filename  float64  `xml:"filename,attr"`  // Score from keyword matches in the file name
+	Directory float64  `xml:"directory,attr"` // Score from keyword matches in the directory path
+	Imports   float64  `xml:"imports,attr"`   // Score from keyword matches in import statements
+	Content   float64  `xml:"content,attr"`   // Score from keyword occurrences in the content, less negative keyword mentions
+	Traits    []string `xml:"-"`              // Ranking traits: "entry", "config", "core", "test", "imported", "paired", "recent"
+	Included  bool     `xml:"included,attr"`
+	Reason    string   `xml:"reason,attr,omitempty"` // Why the file was excluded, when it was
+}
+
+// TreeRollup is a node of a tree-only extraction: a directory with the number
+// of files beneath it and their token total, or a single file
+type TreeRollup struct {
+	Name     string        // Base name ("" never; the root is the project directory)
+	Path     string        // Slash-separated path relative to the project root ("." for the root)
+	Type     string        // "dir" or "file"
+	Files    int           // Files at or below this node
+	Tokens   int           // Token total of those files
+	Children []*TreeRollup // Directories first, then files, each sorted by name
+}
+
+// SplitInfo places one part of an output split across several files, and
+// says where the files in the other parts went
+type SplitInfo struct {
+	Part  int        `xml:"part,attr"`  // 1-based number of this part
+	Total int        `xml:"total,attr"` // Number of parts
+	Parts [][]string `xml:"-"`          // File paths in each part; Parts[0] is part 1
+}
+
+// SkippedFile is a stub for a file whose content isn't included, so readers
+// still know it exists
+type SkippedFile struct {
+	Path   string `xml:"path,attr"`
+	Size   int64  `xml:"size,attr"`   // Bytes on disk
+	Kind   string `xml:"kind,attr"`   // What it holds: "image", "archive", "font", ..., or "binary"/"text"
+	Reason string `xml:"reason,attr"` // Why it was skipped: "binary", "too-large", or "generated"
+}
+
+// MetricsInfo holds static metrics of the included source files, most
+// complex first
+type MetricsInfo struct {
+	Files     []FileMetrics `xml:"file"`
+	Functions int           `xml:"functions,attr"` // Functions across all files
+}
+
+// FileMetrics is the size and complexity of one source file. Complexity is
+// cyclomatic: one per function plus one per branch. Go is measured from its
+// syntax tree; other languages are estimated from their branch keywords.
+type FileMetrics struct {
+	Path          string `xml:"path,attr"`
+	Lines         int    `xml:"lines,attr"`
+	Functions     int    `xml:"functions,attr"`
+	Complexity    int    `xml:"complexity,attr"`            // Sum over the file's functions
+	MaxComplexity int    `xml:"maxComplexity,attr"`         // Of the most complex function
+	MaxFunction   string `xml:"maxFunction,attr,omitempty"` // Name of the most complex function
+}
+
+// MetricsHotspots is how many of the most complex files the text formats list
+const MetricsHotspots = 10
+
+// Issue is a TODO, FIXME or HACK comment found in an included file
+type Issue struct {
+	Path string `xml:"path,attr"`
+	Line int    `xml:"line,attr"` // 1-based line in the file's content as included
+	Kind string `xml:"kind,attr"` // "TODO", "FIXME" or "HACK"
+	Text string `xml:",chardata"` // The comment after the marker
+}
+
+// RedactionInfo counts the secrets replaced with placeholders in the included files
+type RedactionInfo struct {
+	Total int            `xml:"total,attr"` // Redactions across all files
+	Files int            `xml:"files,attr"` // Files with at least one redaction
+	Rules map[string]int `xml:"-"`          // Redactions per detection rule
 }
 
 type ProjectOverview struct {
@@ -102,17 +210,159 @@ type GitInfo struct {
 	CommitMessage string `xml:"commitMessage"`
 }
 
+// CommitInfo is one entry of the recent git history
+type CommitInfo struct {
+	Hash    string `xml:"hash,attr"`
+	Date    string `xml:"date,attr"`
+	Subject string `xml:"subject"`
+	Body    string `xml:"body,omitempty"`
+}
+
 type Metadata struct {
-	Language     string   `xml:"language"`
-	Version      string   `xml:"version"`
-	Dependencies []string `xml:"dependencies>dependency,omitempty"`
+	Language     string       `xml:"language"`
+	Version      string       `xml:"version"`
+	Dependencies []Dependency `xml:"dependencies>dependency,omitempty"`
+	Frameworks   []Framework  `xml:"frameworks>framework,omitempty"`
+	Owners       *OwnerInfo   `xml:"owners,omitempty"`   // CODEOWNERS rollup of the included files (nil unless owners are attached)
+	Licenses     *LicenseInfo `xml:"licenses,omitempty"` // License inventory (nil unless licenses are scanned)
+	Audit        *AuditInfo   `xml:"audit,omitempty"`    // Known vulnerabilities of the dependencies (nil unless audited)
+
+	// Languages breaks the included files down by language, most tokens first
+	Languages []LanguageShare `xml:"languages>language,omitempty"`
+}
+
+// LanguageShare is one language's part of the included files
+type LanguageShare struct {
+	Name         string  `xml:"name,attr"` // As GitHub's linguist spells it, e.g. "TypeScript", or "Other"
+	Files        int     `xml:"files,attr"`
+	Lines        int     `xml:"lines,attr"`
+	Tokens       int     `xml:"tokens,attr"`
+	LinePercent  float64 `xml:"linePercent,attr"`  // Share of the included lines, to one decimal place
+	TokenPercent float64 `xml:"tokenPercent,attr"` // Share of the included tokens, to one decimal place
+}
+
+// AuditInfo lists the known vulnerabilities of the project's dependencies
+type AuditInfo struct {
+	Source          string          `xml:"source,attr"`  // "osv.dev", or the osv-scanner report read
+	Checked         int             `xml:"checked,attr"` // Dependencies looked up, or packages in the report
+	Vulnerabilities []Vulnerability `xml:"vulnerability"`
+}
+
+// Vulnerability is a known vulnerability affecting a dependency
+type Vulnerability struct {
+	ID       string   `xml:"id,attr"` // OSV identifier, e.g. "GHSA-35jh-r3h4-6jhm"
+	Aliases  []string `xml:"alias"`   // Other identifiers, such as CVE numbers
+	Package  string   `xml:"package,attr"`
+	Version  string   `xml:"version,attr"`
+	Severity string   `xml:"severity,attr,omitempty"` // "HIGH" and the like, or a CVSS vector
+	Summary  string   `xml:"summary"`
+	Fixed    string   `xml:"fixed,attr,omitempty"` // First fixed version, when known
+}
+
+// Dependency is a package the project manifest depends on
+type Dependency struct {
+	Name    string `xml:"name,attr"`
+	Version string `xml:"version,attr,omitempty"` // Version or constraint as written, e.g. "v1.8.0" or "^4.17.1"
+	Scope   string `xml:"scope,attr"`             // "direct", "dev" or "indirect"
+}
+
+// LicenseInfo is the license inventory of the included files: the licenses
+// declared by LICENSE files and SPDX headers, the files whose header is
+// missing or disagrees with their LICENSE file, and the copyright notices
+type LicenseInfo struct {
+	Licenses   []LicenseSummary `xml:"license"`
+	Flagged    []LicenseFlag    `xml:"flagged>file,omitempty"`
+	Copyrights []string         `xml:"copyrights>copyright,omitempty"` // Distinct notices, sorted
+}
+
+// LicenseSummary is one license found in the project
+type LicenseSummary struct {
+	ID      string   `xml:"id,attr"`                  // SPDX identifier, e.g. "MIT", or "unknown" for an unrecognized LICENSE file
+	Files   int      `xml:"files,attr"`               // Included files whose SPDX header names it
+	Sources []string `xml:"sources>source,omitempty"` // LICENSE files declaring it
+}
+
+// LicenseFlag marks an included source file whose license header needs review
+type LicenseFlag struct {
+	Path   string `xml:"path,attr"`
+	Reason string `xml:"reason,attr"` // "missing-header" or "conflicting-header"
+	Detail string `xml:",chardata"`   // The header and the license it conflicts with
+}
+
+// OwnerInfo rolls the included files up by CODEOWNERS owner
+type OwnerInfo struct {
+	Owners  []OwnerSummary `xml:"owner"`
+	Unowned int            `xml:"unowned,attr"` // Files no rule assigns an owner
+	Source  string         `xml:"source,attr"`  // CODEOWNERS file the owners come from
+}
+
+// OwnerSummary is one owner's share of the included files, listed most
+// files first
+type OwnerSummary struct {
+	Owner  string `xml:"name,attr"`
+	Files  int    `xml:"files,attr"`
+	Tokens int    `xml:"tokens,attr"`
+}
+
+// Framework is a detected framework, build tool, or language with its
+// detection priority, highest first in Metadata.Frameworks
+type Framework struct {
+	Name        string `xml:"name,attr"`
+	Description string `xml:",chardata"`
+	Priority    int    `xml:"priority,attr"`
 }
 
 type FileInfo struct {
-	Path       string          `xml:"path,attr"`
-	Content    string          `xml:"content"`
-	Tokens     int             `xml:"tokens,omitempty"`     // PTX v2.0: Token count for this file
-	Truncation *TruncationInfo `xml:"truncation,omitempty"` // PTX v2.0: Truncation metadata if file was truncated
+	Path            string          `xml:"path,attr"`
+	Content         string          `xml:"content"`
+	Tokens          int             `xml:"tokens,omitempty"`               // PTX v2.0: Token count for this file
+	Truncation      *TruncationInfo `xml:"truncation,omitempty"`           // PTX v2.0: Truncation metadata if file was truncated
+	AssociatedTests []string        `xml:"associatedTests>test,omitempty"` // Test files that cover this file (heuristic pairing)
+	Imports         []string        `xml:"-"`                              // Imports stripped from Content, summarized in Dependencies
+	Redactions      map[string]int  `xml:"-"`                              // Secrets redacted from Content, per detection rule
+	Compaction      string          `xml:"-"`                              // How Content was compacted ("whitespace" or "indent"); empty when byte-exact
+	ScoreAdjustment float64         `xml:"-"`                              // Relevance added by caller-supplied filter rules
+	Annotations     []string        `xml:"-"`                              // Notes attached by annotation globs, rendered with the file
+	Owners          []string        `xml:"-"`                              // CODEOWNERS owners of the file
+	License         string          `xml:"-"`                              // SPDX expression of the file's license header, as read before transforms
+	Copyright       string          `xml:"-"`                              // First copyright notice in the file's header
+	External        bool            `xml:"-"`                              // File lies outside the project root; Path is relative to it or absolute
+	Sample          *SampleInfo     `xml:"-"`                              // Set when Content holds only the first rows of a data file
+	Hash            string          `xml:"-"`                              // Hex-encoded SHA-256 of the file as on disk, before transforms
+}
+
+// RedactionCount returns the number of secrets redacted from the file
+func (f FileInfo) RedactionCount() int {
+	total := 0
+	for _, n := range f.Redactions {
+		total += n
+	}
+	return total
+}
+
+// PackageInfo describes one Go package or JS/TS module directory
+type PackageInfo struct {
+	Name    string   `xml:"name,attr"`                // Package name (Go package clause, package.json name, or directory name)
+	Path    string   `xml:"path,attr"`                // Directory relative to the project root ("." for the root)
+	Files   []string `xml:"files>file,omitempty"`     // Included files belonging to the package
+	Imports []string `xml:"imports>import,omitempty"` // Paths of other packages in the project this one imports
+}
+
+// WorkspaceInfo describes a monorepo: the member packages its workspace
+// manifests declare and the dependencies between them
+type WorkspaceInfo struct {
+	Manifests []string           `xml:"manifests,attr"` // Workspace manifests read, e.g. "go.work" or "pnpm-workspace.yaml"
+	Packages  []WorkspacePackage `xml:"package"`        // Sorted by path
+}
+
+// WorkspacePackage is one member of a workspace
+type WorkspacePackage struct {
+	Name      string   `xml:"name,attr"`                   // Go module path, or package.json, Cargo.toml or project.json name
+	Path      string   `xml:"path,attr"`                   // Directory relative to the project root
+	Ecosystem string   `xml:"ecosystem,attr"`              // "go", "npm", "cargo" or "nx"
+	DependsOn []string `xml:"dependsOn>package,omitempty"` // Names of the members it depends on, sorted
+	Files     int      `xml:"files,attr"`                  // Included files under Path
+	Tokens    int      `xml:"tokens,attr"`                 // Tokens of those files
 }
 
 // BudgetInfo tracks token budget and truncation statistics (PTX v2.0)
@@ -134,6 +384,14 @@ type TruncationInfo struct {
 	OriginalTokens int    `xml:"originalTokens"` // Token count before truncation
 }
 
+// SampleInfo describes a data file (CSV, TSV, JSON Lines) cut down to its
+// header and first rows
+type SampleInfo struct {
+	Rows      int // Rows kept, after the header
+	TotalRows int // Rows in the file, after the header
+	Columns   int // Header fields, or keys of the first JSON Lines object
+}
+
 // Formatter interface for different output formats
 type Formatter interface {
 	Format(project *ProjectOutput) (string, error)
@@ -154,7 +412,13 @@ func GetFormatter(format string) (Formatter, error) {
 		return &TOONStrictFormatter{}, nil
 	case "jsonl":
 		return &JSONLFormatter{}, nil
+	case "json":
+		return &JSONFormatter{}, nil
+	case "html", "htm":
+		return &HTMLFormatter{}, nil
+	case "pdf":
+		return &PDFFormatter{}, nil
 	default:
-		return nil, fmt.Errorf("unsupported format: %s (supported: markdown, xml, ptx, toon, toon-strict, jsonl)", format)
+		return nil, fmt.Errorf("unsupported format: %s (supported: markdown, xml, ptx, toon, toon-strict, jsonl, json, html, pdf)", format)
 	}
 }
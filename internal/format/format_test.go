@@ -2,6 +2,7 @@ package format
 
 import (
 	"encoding/xml"
+	"io"
 	"strings"
 	"testing"
 )
@@ -66,6 +67,441 @@ func TestGetFormatter(t *testing.T) {
 	}
 }
 
+func TestBuiltinsResolve(t *testing.T) {
+	for _, info := range Builtins {
+		for _, name := range append([]string{info.Name}, info.Aliases...) {
+			if _, err := GetFormatter(name); err != nil {
+				t.Errorf("built-in format %q: %v", name, err)
+			}
+		}
+		if info.Description == "" || info.MimeType == "" {
+			t.Errorf("built-in format %q lacks a description or MIME type", info.Name)
+		}
+	}
+}
+
+func TestFormattersRenderAnnotations(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Files: []FileInfo{{
+			Path:        "legacy/old.go",
+			Content:     "package legacy",
+			Annotations: []string{"Being deprecated"},
+		}},
+	}
+	for _, info := range Builtins {
+		if info.Name == "pdf" {
+			continue // Renders the markdown output
+		}
+		formatter, err := GetFormatter(info.Name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", info.Name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", info.Name, err)
+		}
+		if !strings.Contains(output, "Being deprecated") {
+			t.Errorf("%s output lacks the annotation:\n%s", info.Name, output)
+		}
+	}
+}
+
+func TestFormattersRenderOwners(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Metadata: &Metadata{
+			Language: "Go",
+			Owners: &OwnerInfo{
+				Source:  ".github/CODEOWNERS",
+				Owners:  []OwnerSummary{{Owner: "@org/billing", Files: 1, Tokens: 4}},
+				Unowned: 2,
+			},
+		},
+		Files: []FileInfo{{
+			Path:    "billing/invoice.go",
+			Content: "package billing",
+			Tokens:  4,
+			Owners:  []string{"@org/billing"},
+		}},
+	}
+	for _, info := range Builtins {
+		if info.Name == "pdf" {
+			continue // Renders the markdown output
+		}
+		formatter, err := GetFormatter(info.Name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", info.Name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", info.Name, err)
+		}
+		if strings.Count(output, "@org/billing") < 2 {
+			t.Errorf("%s output lacks the file's owner or the owner rollup:\n%s", info.Name, output)
+		}
+	}
+}
+
+func TestFormattersRenderIssues(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Files:         []FileInfo{{Path: "main.go", Content: "// TODO: handle retries"}},
+		Issues:        []Issue{{Path: "main.go", Line: 1, Kind: "TODO", Text: "handle retries"}},
+	}
+	for _, info := range Builtins {
+		if info.Name == "pdf" {
+			continue // Renders the markdown output
+		}
+		formatter, err := GetFormatter(info.Name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", info.Name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", info.Name, err)
+		}
+		if strings.Count(output, "handle retries") < 2 {
+			t.Errorf("%s output lacks the issue:\n%s", info.Name, output)
+		}
+	}
+
+	markdown, _ := (&MarkdownFormatter{}).Format(project)
+	if !strings.Contains(markdown, "| main.go | 1 | TODO | handle retries |") {
+		t.Errorf("expected an issues table in markdown:\n%s", markdown)
+	}
+}
+
+func TestFormattersRenderMetrics(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Files:         []FileInfo{{Path: "main.go", Content: "package main"}},
+		Metrics: &MetricsInfo{
+			Functions: 4,
+			Files:     []FileMetrics{{Path: "main.go", Lines: 40, Functions: 4, Complexity: 12, MaxComplexity: 9, MaxFunction: "Server.Handle"}},
+		},
+	}
+	for _, name := range []string{"ptx", "toon-strict", "markdown", "xml", "json"} {
+		formatter, err := GetFormatter(name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(output, "Server.Handle") {
+			t.Errorf("%s output lacks the metrics:\n%s", name, output)
+		}
+	}
+
+	markdown, _ := (&MarkdownFormatter{}).Format(project)
+	if !strings.Contains(markdown, "Complexity Hotspots (top 1 of 1 files, 4 functions)") ||
+		!strings.Contains(markdown, "| main.go | 40 | 4 | 12 | Server.Handle (9) |") {
+		t.Errorf("expected a hotspots table in markdown:\n%s", markdown)
+	}
+}
+
+func TestFormattersRenderLicenses(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Metadata: &Metadata{
+			Language: "Go",
+			Licenses: &LicenseInfo{
+				Licenses:   []LicenseSummary{{ID: "Apache-2.0", Files: 1, Sources: []string{"LICENSE"}}},
+				Flagged:    []LicenseFlag{{Path: "util.go", Reason: "missing-header", Detail: "no SPDX-License-Identifier header"}},
+				Copyrights: []string{"Copyright 2024 Acme"},
+			},
+		},
+		Files: []FileInfo{
+			{Path: "main.go", Content: "package main", License: "Apache-2.0"},
+			{Path: "util.go", Content: "package main"},
+		},
+	}
+	for _, info := range Builtins {
+		if info.Name == "pdf" {
+			continue // Renders the markdown output
+		}
+		formatter, err := GetFormatter(info.Name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", info.Name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", info.Name, err)
+		}
+		for _, want := range []string{"Apache-2.0", "missing-header", "Copyright 2024 Acme"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s output lacks %q:\n%s", info.Name, want, output)
+			}
+		}
+		if strings.Count(output, "Apache-2.0") < 2 {
+			t.Errorf("%s output lacks the file's license:\n%s", info.Name, output)
+		}
+	}
+}
+
+func TestFormattersRenderAudit(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Metadata: &Metadata{
+			Language: "JavaScript/Node.js",
+			Audit: &AuditInfo{
+				Source:  "osv.dev",
+				Checked: 3,
+				Vulnerabilities: []Vulnerability{{
+					ID:       "GHSA-35jh-r3h4-6jhm",
+					Aliases:  []string{"CVE-2021-23337"},
+					Package:  "lodash",
+					Version:  "4.17.20",
+					Severity: "HIGH",
+					Summary:  "Command Injection in lodash",
+					Fixed:    "4.17.21",
+				}},
+			},
+		},
+		Files: []FileInfo{{Path: "index.js", Content: "require('lodash')"}},
+	}
+	for _, info := range Builtins {
+		if info.Name == "pdf" {
+			continue // Renders the markdown output
+		}
+		formatter, err := GetFormatter(info.Name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", info.Name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", info.Name, err)
+		}
+		for _, want := range []string{"GHSA-35jh-r3h4-6jhm", "CVE-2021-23337", "4.17.21", "Command Injection in lodash"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s output lacks %q:\n%s", info.Name, want, output)
+			}
+		}
+	}
+
+	markdown, _ := (&MarkdownFormatter{}).Format(project)
+	if !strings.Contains(markdown, "Vulnerabilities: 1 known (osv.dev, 3 dependencies checked)") ||
+		!strings.Contains(markdown, "  - GHSA-35jh-r3h4-6jhm (CVE-2021-23337) in lodash 4.17.20 [HIGH]: Command Injection in lodash; fixed in 4.17.21") {
+		t.Errorf("expected the vulnerabilities in markdown:\n%s", markdown)
+	}
+}
+
+func TestFormattersRenderWorkspace(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Workspace: &WorkspaceInfo{
+			Manifests: []string{"pnpm-workspace.yaml", "turbo.json"},
+			Packages: []WorkspacePackage{
+				{Name: "@acme/auth-service", Path: "apps/auth", Ecosystem: "npm", DependsOn: []string{"@acme/db"}, Files: 2, Tokens: 120},
+				{Name: "@acme/db", Path: "packages/db", Ecosystem: "npm", Files: 1, Tokens: 40},
+			},
+		},
+		Files: []FileInfo{{Path: "apps/auth/index.ts", Content: "import '@acme/db'"}},
+	}
+	for _, name := range []string{"ptx", "toon-strict", "jsonl", "markdown", "xml", "json"} {
+		formatter, err := GetFormatter(name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, want := range []string{"@acme/auth-service", "packages/db", "pnpm-workspace.yaml"} {
+			if name == "jsonl" && want == "pnpm-workspace.yaml" {
+				continue // JSONL has one line per member only
+			}
+			if !strings.Contains(output, want) {
+				t.Errorf("%s output lacks %q:\n%s", name, want, output)
+			}
+		}
+	}
+
+	markdown, _ := (&MarkdownFormatter{}).Format(project)
+	if !strings.Contains(markdown, "Workspace (pnpm-workspace.yaml, turbo.json, 2 packages)") ||
+		!strings.Contains(markdown, "| @acme/auth-service | apps/auth | @acme/db | 2 | 120 |") {
+		t.Errorf("expected a workspace table in markdown:\n%s", markdown)
+	}
+}
+
+func TestFormattersRenderLanguages(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Metadata: &Metadata{
+			Languages: []LanguageShare{
+				{Name: "TypeScript", Files: 3, Lines: 300, Tokens: 2400, LinePercent: 60, TokenPercent: 72.7},
+				{Name: "Python", Files: 2, Lines: 200, Tokens: 900, LinePercent: 40, TokenPercent: 27.3},
+			},
+		},
+		Files: []FileInfo{{Path: "src/app.ts", Content: "export {}"}},
+	}
+	for _, info := range Builtins {
+		if info.Name == "pdf" {
+			continue // Renders the markdown output
+		}
+		formatter, err := GetFormatter(info.Name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", info.Name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", info.Name, err)
+		}
+		for _, want := range []string{"TypeScript", "Python", "72.7"} {
+			if !strings.Contains(output, want) {
+				t.Errorf("%s output lacks %q:\n%s", info.Name, want, output)
+			}
+		}
+	}
+
+	markdown, _ := (&MarkdownFormatter{}).Format(project)
+	if !strings.Contains(markdown, "Languages:\n  - TypeScript: 3 files, 60.0% of lines, 72.7% of tokens\n  - Python: 2 files") {
+		t.Errorf("expected the language breakdown in markdown:\n%s", markdown)
+	}
+	if strings.Contains(markdown, "Language: \n") {
+		t.Errorf("expected no empty language line:\n%s", markdown)
+	}
+}
+
+func TestFormattersMarkExternalFiles(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Files: []FileInfo{{
+			Path:     "../shared/api.proto",
+			Content:  "syntax = \"proto3\";",
+			External: true,
+		}},
+	}
+	want := map[string]string{
+		"ptx":         "external",
+		"toon-strict": "external",
+		"jsonl":       `"external":true`,
+		"json":        `"external": true`,
+		"markdown":    "(external, 1 lines)",
+		"xml":         `external="true"`,
+		"html":        "· external",
+	}
+	for name, marker := range want {
+		formatter, err := GetFormatter(name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(output, marker) {
+			t.Errorf("%s output lacks %q:\n%s", name, marker, output)
+		}
+	}
+}
+
+func TestFormattersMarkSampledFiles(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Files: []FileInfo{{
+			Path:    "data/users.csv",
+			Content: "id,name\n1,a\n2,b",
+			Sample:  &SampleInfo{Rows: 2, TotalRows: 500, Columns: 2},
+		}},
+	}
+	want := map[string]string{
+		"ptx":         "total_rows: 500",
+		"toon-strict": "sampled_rows,total_rows",
+		"jsonl":       `"sampled":{"columns":2,"rows":2,"total_rows":500}`,
+		"json":        `"total_rows": 500`,
+		"markdown":    "(3 lines, sampled 2 of 500 rows)",
+		"xml":         `sampled-rows="2" total-rows="500" columns="2"`,
+		"html":        "· sampled 2 of 500 rows",
+	}
+	for name, marker := range want {
+		formatter, err := GetFormatter(name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(output, marker) {
+			t.Errorf("%s output lacks %q:\n%s", name, marker, output)
+		}
+	}
+}
+
+func TestFormattersRenderMapsInOrder(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		FileStats: &FileStatistics{
+			TotalFiles:  6,
+			FilesByType: map[string]int{".go": 1, ".md": 1, ".py": 1, ".ts": 1, ".yml": 1, ".json": 1},
+		},
+		Analysis: &ProjectAnalysis{
+			EntryPoints: map[string]string{"cmd/a/main.go": "entry", "cmd/b/main.go": "entry", "cmd/c/main.go": "entry", "cmd/d/main.go": "entry"},
+		},
+		Files: []FileInfo{{Path: "main.go", Content: "package main"}},
+	}
+	for _, info := range Builtins {
+		if info.Name == "pdf" {
+			continue
+		}
+		formatter, err := GetFormatter(info.Name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", info.Name, err)
+		}
+		first, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", info.Name, err)
+		}
+		for i := 0; i < 20; i++ {
+			if output, _ := formatter.Format(project); output != first {
+				t.Fatalf("%s output differs between renderings of the same project", info.Name)
+			}
+		}
+	}
+
+	xml, _ := (&XMLFormatter{}).Format(project)
+	if strings.Index(xml, `ext=".go"`) > strings.Index(xml, `ext=".yml"`) {
+		t.Errorf("expected file types sorted by extension:\n%s", xml)
+	}
+}
+
+func TestFormattersListFrameworks(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{Name: "root", Type: "dir"},
+		Metadata: &Metadata{
+			Language: "JavaScript/Node.js",
+			Frameworks: []Framework{
+				{Name: "nextjs", Description: "Next.js", Priority: 100},
+				{Name: "node", Description: "Node.js", Priority: 60},
+			},
+		},
+	}
+	want := map[string]string{
+		"ptx":         "Next.js,nextjs,100",
+		"toon-strict": "Next.js,nextjs,100",
+		"jsonl":       `"frameworks":[{"description":"Next.js","name":"nextjs","priority":100}`,
+		"json":        `"description": "Next.js"`,
+		"markdown":    "Frameworks: Next.js, Node.js",
+		"html":        "Next.js, Node.js",
+	}
+	for name, marker := range want {
+		formatter, err := GetFormatter(name)
+		if err != nil {
+			t.Fatalf("GetFormatter(%q): %v", name, err)
+		}
+		output, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !strings.Contains(output, marker) {
+			t.Errorf("%s output lacks %q:\n%s", name, marker, output)
+		}
+	}
+}
+
 func TestMarkdownFormatter_Format(t *testing.T) {
 	formatter := &MarkdownFormatter{}
 
@@ -82,7 +518,7 @@ func TestMarkdownFormatter_Format(t *testing.T) {
 				Metadata: &Metadata{
 					Language:     "Go",
 					Version:      "1.17",
-					Dependencies: []string{"dep1", "dep2"},
+					Dependencies: []Dependency{{Name: "dep1", Version: "v1.0.0", Scope: "direct"}, {Name: "dep2", Version: "^2.1", Scope: "dev"}},
 				},
 				DirectoryTree: &DirectoryNode{
 					Name: "test",
@@ -96,8 +532,8 @@ func TestMarkdownFormatter_Format(t *testing.T) {
 				"Language: Go",
 				"Version: 1.17",
 				"Dependencies:",
-				"  - dep1",
-				"  - dep2",
+				"  - dep1 v1.0.0\n",
+				"  - dep2 ^2.1 (dev)\n",
 				"Project Structure:",
 				"└── main.go",
 			},
@@ -151,6 +587,67 @@ func TestMarkdownFormatter_Format(t *testing.T) {
 	}
 }
 
+func TestMarkdownFormatter_Options(t *testing.T) {
+	project := &ProjectOutput{
+		Files: []FileInfo{
+			{Path: "web/app.ts", Content: "export const fence = \"```\"\n"},
+			{Path: "deploy/config.yml", Content: "a: 1\nb: 2\nc: 3\nd: 4"},
+			{Path: "web/app-ts", Content: "binary"},
+		},
+	}
+
+	plain, err := (&MarkdownFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	for _, want := range []string{"````typescript\nexport const fence", "```yaml\na: 1", "```text\nbinary"} {
+		if !strings.Contains(plain, want) {
+			t.Errorf("Format() output should contain %q", want)
+		}
+	}
+	for _, unwant := range []string{"## Contents", "<a id=", "<details>"} {
+		if strings.Contains(plain, unwant) {
+			t.Errorf("Format() with no options should not contain %q", unwant)
+		}
+	}
+
+	got, err := (&MarkdownFormatter{TOC: true, CollapseLines: 3}).Format(project)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	for _, want := range []string{
+		"## Contents\n\n- [web/app.ts](#file-web-app-ts) (2 lines)\n- [deploy/config.yml](#file-deploy-config-yml) (4 lines)\n- [web/app-ts](#file-web-app-ts-2) (1 lines)\n",
+		"<a id=\"file-web-app-ts\"></a>\n\n### web/app.ts (2 lines)\n````typescript\n",
+		"<a id=\"file-web-app-ts-2\"></a>",
+		"### deploy/config.yml (4 lines)\n<details>\n<summary>Show 4 lines</summary>\n\n```yaml\na: 1\nb: 2\nc: 3\nd: 4\n```\n\n</details>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Format() output should contain %q", want)
+		}
+	}
+	if strings.Count(got, "<details>") != 1 {
+		t.Errorf("only the file over CollapseLines should collapse:\n%s", got)
+	}
+
+	anchored, err := (&MarkdownFormatter{Anchors: true}).Format(project)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if strings.Contains(anchored, "## Contents") || !strings.Contains(anchored, "<a id=\"file-deploy-config-yml\"></a>") {
+		t.Errorf("Anchors should add anchors without a table of contents:\n%s", anchored)
+	}
+}
+
+func TestCodeBlock(t *testing.T) {
+	if got, want := CodeBlock("main.go", "package main\n"), "```go\npackage main\n```"; got != want {
+		t.Errorf("CodeBlock = %q, want %q", got, want)
+	}
+	// Content with a fence of its own gets a longer one
+	if got := CodeBlock("app.ts", "const f = \"```\""); !strings.HasPrefix(got, "````typescript\n") || !strings.HasSuffix(got, "\n````") {
+		t.Errorf("CodeBlock should outlast the content's fence, got %q", got)
+	}
+}
+
 func TestXMLFormatter_Format(t *testing.T) {
 	formatter := &XMLFormatter{}
 
@@ -296,3 +793,156 @@ func TestEscapeForTOON(t *testing.T) {
 		})
 	}
 }
+
+func TestXMLFormatter_MatchesSchema(t *testing.T) {
+	content := "if a < b && c > d {}\n// ]]> ends CDATA\n"
+	project := &ProjectOutput{
+		Integrity: &IntegrityInfo{Algorithm: HashAlgorithm, Content: FileHash([]byte("x"))},
+		Split:     &SplitInfo{Part: 1, Total: 2, Parts: [][]string{{"main.go"}, {"util.go"}}},
+		Metadata: &Metadata{
+			Language:     "Go",
+			Version:      "1.22",
+			Dependencies: []Dependency{{Name: "github.com/spf13/pflag", Version: "v1.0.5", Scope: "direct"}},
+			Frameworks:   []Framework{{Name: "Go Modules", Description: "Go <modules>", Priority: 1}},
+			Languages:    []LanguageShare{{Name: "Go", Files: 1, Lines: 2, Tokens: 20, LinePercent: 100, TokenPercent: 100}},
+			Owners:       &OwnerInfo{Source: "CODEOWNERS", Owners: []OwnerSummary{{Owner: "@team", Files: 1, Tokens: 20}}},
+			Licenses:     &LicenseInfo{Licenses: []LicenseSummary{{ID: "MIT", Files: 1, Sources: []string{"LICENSE"}}}, Copyrights: []string{"Copyright (c) A & B"}},
+			Audit:        &AuditInfo{Source: "osv.dev", Checked: 1, Vulnerabilities: []Vulnerability{{ID: "GO-1", Aliases: []string{"CVE-1"}, Package: "x", Version: "1", Summary: "bad"}}},
+		},
+		Overview:      &ProjectOverview{Description: "Demo", Purpose: "Tests", Features: []string{"a & b"}},
+		FileStats:     &FileStatistics{TotalFiles: 1, TotalLines: 2, FilesByType: map[string]int{".go": 1}},
+		Budget:        &BudgetInfo{MaxTokens: 100, EstimatedTokens: 80, FileTruncations: 1},
+		FilterConfig:  &FilterConfig{Includes: []string{".go"}, Excludes: []string{"vendor/"}},
+		DirectoryTree: &DirectoryNode{Children: []*DirectoryNode{{Name: "main.go", Type: "file"}}},
+		GitInfo:       &GitInfo{Branch: "main", CommitHash: "abc", CommitMessage: "Fix <bug>"},
+		RecentCommits: []CommitInfo{{Hash: "abc", Date: "2024-01-01", Subject: "Fix", Body: "Details"}},
+		Analysis:      &ProjectAnalysis{EntryPoints: map[string]string{"main.go": "Main entry point"}, TestFiles: map[string]string{"main_test.go": "Test file"}},
+		Dependencies:  &DependencyInfo{Imports: map[string][]string{"main.go": {"fmt"}}, Packages: []string{"fmt"}, CoreFiles: []string{"main.go"}},
+		Packages:      []PackageInfo{{Name: "main", Path: ".", Files: []string{"main.go"}, Imports: []string{"internal/x"}}},
+		Redactions:    &RedactionInfo{Total: 1, Files: 1, Rules: map[string]int{"aws-key": 1}},
+		Workspace:     &WorkspaceInfo{Manifests: []string{"go.work"}, Packages: []WorkspacePackage{{Name: "app", Path: "app", Ecosystem: "go", DependsOn: []string{"lib"}}}},
+		SkippedFiles:  []SkippedFile{{Path: "logo.png", Size: 10, Kind: "image", Reason: "binary"}},
+		Issues:        []Issue{{Path: "main.go", Line: 1, Kind: "TODO", Text: "fix"}},
+		Metrics:       &MetricsInfo{Functions: 1, Files: []FileMetrics{{Path: "main.go", Lines: 2, Functions: 1, Complexity: 1, MaxComplexity: 1, MaxFunction: "main"}}},
+		Selection:     &SelectionReport{Threshold: 10, Files: []SelectionEntry{{Path: "main.go", Tokens: 20, Included: true, Traits: []string{"entry"}}}},
+		Files: []FileInfo{{
+			Path: "a&b/main.go", Content: content, Tokens: 20, Hash: FileHash([]byte(content)),
+			Truncation:      &TruncationInfo{Mode: "head:10", OriginalTokens: 40},
+			AssociatedTests: []string{"main_test.go"},
+			Annotations:     []string{"Entry point"},
+		}},
+	}
+	out, err := (&XMLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	// Collect the element and attribute names the schema declares, and the
+	// order of the project's sections
+	xsd, err := Schema("xml")
+	if err != nil {
+		t.Fatalf("Schema(xml) failed: %v", err)
+	}
+	elements, attributes := map[string]bool{}, map[string]bool{}
+	var sections []string
+	dec := xml.NewDecoder(strings.NewReader(xsd))
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid schema: %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			depth++
+			name := ""
+			for _, attr := range tok.Attr {
+				if attr.Name.Local == "name" {
+					name = attr.Value
+				}
+			}
+			switch tok.Name.Local {
+			case "element":
+				elements[name] = true
+				if depth == 5 { // schema > element project > complexType > sequence > element
+					sections = append(sections, name)
+				}
+			case "attribute":
+				attributes[name] = true
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	// Every element and attribute of the output is declared, and the
+	// sections are in schema order
+	dec = xml.NewDecoder(strings.NewReader(out))
+	depth, last := 0, -1
+	seen := map[string]bool{}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid XML: %v\n%s", err, out)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if !elements[tok.Name.Local] {
+				t.Errorf("element <%s> is not in the schema", tok.Name.Local)
+			}
+			for _, attr := range tok.Attr {
+				if !attributes[attr.Name.Local] {
+					t.Errorf("attribute %s of <%s> is not in the schema", attr.Name.Local, tok.Name.Local)
+				}
+			}
+			if depth == 2 {
+				index := -1
+				for i, section := range sections {
+					if section == tok.Name.Local {
+						index = i
+					}
+				}
+				if index <= last {
+					t.Errorf("section <%s> out of schema order", tok.Name.Local)
+				}
+				last = index
+				seen[tok.Name.Local] = true
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	for _, section := range sections {
+		if !seen[section] {
+			t.Errorf("section <%s> missing from the output", section)
+		}
+	}
+
+	var parsed struct {
+		Files []struct {
+			Path    string `xml:"path,attr"`
+			Tokens  int    `xml:"tokens,attr"`
+			Content string `xml:"content"`
+		} `xml:"files>file"`
+	}
+	if err := xml.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(parsed.Files) != 1 || parsed.Files[0].Path != "a&b/main.go" || parsed.Files[0].Tokens != 20 || parsed.Files[0].Content != content {
+		t.Errorf("file did not round-trip: %+v", parsed.Files)
+	}
+
+	// Characters XML doesn't allow are replaced rather than breaking the document
+	out, _ = (&XMLFormatter{}).Format(&ProjectOutput{Files: []FileInfo{{Path: "log.txt", Content: "\x1b[31mred\x1b[0m\n"}}})
+	parsed.Files = nil
+	if err := xml.Unmarshal([]byte(out), &parsed); err != nil || parsed.Files[0].Content != "\uFFFD[31mred\uFFFD[0m\n" {
+		t.Errorf("unexpected content %q: %v", parsed.Files[0].Content, err)
+	}
+}
@@ -6,47 +6,605 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode"
 )
 
-type MarkdownFormatter struct{}
+// MarkdownFormatter renders the project as Markdown. The zero value lists
+// the files one after another under plain headings.
+type MarkdownFormatter struct {
+	TOC           bool // Start with a table of contents linking to each file
+	Anchors       bool // Give each file an anchor ("file-" and its path) to link to; implied by TOC
+	CollapseLines int  // Fold files longer than this many lines into a <details> block (0 = never)
+}
+
 type XMLFormatter struct{}
 type PTXFormatter struct{}        // PTX v2.0 - TOON-based with multiline code and enhanced manifest
 type TOONStrictFormatter struct{} // TOON v1.3 strict compliance
 type JSONLFormatter struct{}      // JSONL - Machine-friendly sidecar format (one JSON object per line)
 
-func (m *MarkdownFormatter) formatSourceFiles(sb *strings.Builder, files []FileInfo) {
+// redactionRules lists per-rule redaction counts sorted by rule name
+func redactionRules(info *RedactionInfo) []map[string]interface{} {
+	names := make([]string, 0, len(info.Rules))
+	for name := range info.Rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	rules := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		rules = append(rules, map[string]interface{}{"rule": name, "count": info.Rules[name]})
+	}
+	return rules
+}
+
+// redactionSummary is the manifest entry for redactions shared by the TOON formats
+func redactionSummary(info *RedactionInfo) map[string]interface{} {
+	summary := map[string]interface{}{"total": info.Total, "files": info.Files}
+	if len(info.Rules) > 0 {
+		summary["rules"] = redactionRules(info)
+	}
+	return summary
+}
+
+// skippedList is the manifest entry for skipped-file stubs shared by the TOON formats
+func skippedList(files []SkippedFile) []map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(files))
+	for _, file := range files {
+		list = append(list, map[string]interface{}{
+			"path":   file.Path,
+			"size":   file.Size,
+			"kind":   file.Kind,
+			"reason": file.Reason,
+		})
+	}
+	return list
+}
+
+// issueList is the manifest entry for TODO comments shared by the TOON formats
+func issueList(issues []Issue) []map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(issues))
+	for _, issue := range issues {
+		list = append(list, map[string]interface{}{
+			"path": issue.Path,
+			"line": issue.Line,
+			"kind": issue.Kind,
+			"text": issue.Text,
+		})
+	}
+	return list
+}
+
+// metricsSummary is the manifest entry for static metrics shared by the TOON
+// formats: totals and the MetricsHotspots most complex files
+func metricsSummary(info *MetricsInfo) map[string]interface{} {
+	hotspots := make([]map[string]interface{}, 0, MetricsHotspots)
+	for _, file := range metricsHotspots(info) {
+		hotspots = append(hotspots, map[string]interface{}{
+			"path":           file.Path,
+			"lines":          file.Lines,
+			"functions":      file.Functions,
+			"complexity":     file.Complexity,
+			"max_complexity": file.MaxComplexity,
+			"max_function":   file.MaxFunction,
+		})
+	}
+	return map[string]interface{}{
+		"files":     len(info.Files),
+		"functions": info.Functions,
+		"hotspots":  hotspots,
+	}
+}
+
+// metricsHotspots returns the first MetricsHotspots files of info, which are
+// kept most complex first
+func metricsHotspots(info *MetricsInfo) []FileMetrics {
+	return info.Files[:min(len(info.Files), MetricsHotspots)]
+}
+
+// selectionList is the manifest entry for a selection report shared by the
+// TOON formats, one uniform row per candidate file
+func selectionList(report *SelectionReport) []map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(report.Files))
+	for _, entry := range report.Files {
+		list = append(list, map[string]interface{}{
+			"path":      entry.Path,
+			"tokens":    entry.Tokens,
+			"score":     entry.Score,
+			"filename":  entry.Filename,
+			"directory": entry.Directory,
+			"imports":   entry.Imports,
+			"content":   entry.Content,
+			"traits":    strings.Join(entry.Traits, " "),
+			"included":  entry.Included,
+			"reason":    entry.Reason,
+		})
+	}
+	return list
+}
+
+// selectionFactors describes the non-zero score factors of an entry, e.g.
+// "filename 10, content 3"
+func selectionFactors(entry SelectionEntry) string {
+	var factors []string
+	for _, factor := range []struct {
+		name  string
+		score float64
+	}{
+		{"filename", entry.Filename},
+		{"directory", entry.Directory},
+		{"imports", entry.Imports},
+		{"content", entry.Content},
+	} {
+		if factor.score != 0 {
+			factors = append(factors, fmt.Sprintf("%s %g", factor.name, factor.score))
+		}
+	}
+	return strings.Join(factors, ", ")
+}
+
+// otherParts lists the files in every part except the current one
+func otherParts(info *SplitInfo) []map[string]interface{} {
+	var parts []map[string]interface{}
+	for i, files := range info.Parts {
+		if i+1 != info.Part {
+			parts = append(parts, map[string]interface{}{"part": i + 1, "files": files})
+		}
+	}
+	return parts
+}
+
+// splitSummary is the manifest entry for a split part shared by the TOON formats
+func splitSummary(info *SplitInfo) map[string]interface{} {
+	summary := map[string]interface{}{"part": info.Part, "total": info.Total}
+	if others := otherParts(info); len(others) > 0 {
+		summary["other_parts"] = others
+	}
+	return summary
+}
+
+// ownerSummary renders an OwnerInfo for the map-based formats
+func ownerSummary(info *OwnerInfo) map[string]interface{} {
+	owners := make([]map[string]interface{}, 0, len(info.Owners))
+	for _, owner := range info.Owners {
+		owners = append(owners, map[string]interface{}{
+			"name":   owner.Owner,
+			"files":  owner.Files,
+			"tokens": owner.Tokens,
+		})
+	}
+	return map[string]interface{}{
+		"source":  info.Source,
+		"owners":  owners,
+		"unowned": info.Unowned,
+	}
+}
+
+// ownerCounts describes an OwnerInfo in one line, as "@a (3 files), @b
+// (1 file); 2 unowned"
+func ownerCounts(info *OwnerInfo) string {
+	parts := make([]string, 0, len(info.Owners))
+	for _, owner := range info.Owners {
+		noun := "files"
+		if owner.Files == 1 {
+			noun = "file"
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d %s)", owner.Owner, owner.Files, noun))
+	}
+	line := strings.Join(parts, ", ")
+	if info.Unowned > 0 {
+		if line != "" {
+			line += "; "
+		}
+		line += fmt.Sprintf("%d unowned", info.Unowned)
+	}
+	return line
+}
+
+// licenseSummary renders a LicenseInfo for the map-based formats
+func licenseSummary(info *LicenseInfo) map[string]interface{} {
+	licenses := make([]map[string]interface{}, 0, len(info.Licenses))
+	for _, license := range info.Licenses {
+		licenses = append(licenses, map[string]interface{}{
+			"id":      license.ID,
+			"files":   license.Files,
+			"sources": strings.Join(license.Sources, " "),
+		})
+	}
+	summary := map[string]interface{}{"licenses": licenses}
+	if len(info.Flagged) > 0 {
+		flagged := make([]map[string]interface{}, 0, len(info.Flagged))
+		for _, flag := range info.Flagged {
+			flagged = append(flagged, map[string]interface{}{
+				"path":   flag.Path,
+				"reason": flag.Reason,
+				"detail": flag.Detail,
+			})
+		}
+		summary["flagged"] = flagged
+	}
+	if len(info.Copyrights) > 0 {
+		summary["copyrights"] = info.Copyrights
+	}
+	return summary
+}
+
+// languageList renders a language breakdown as uniform rows for the
+// map-based formats
+func languageList(languages []LanguageShare) []map[string]interface{} {
+	rows := make([]map[string]interface{}, 0, len(languages))
+	for _, lang := range languages {
+		rows = append(rows, map[string]interface{}{
+			"name":       lang.Name,
+			"files":      lang.Files,
+			"lines":      lang.Lines,
+			"lines_pct":  lang.LinePercent,
+			"tokens":     lang.Tokens,
+			"tokens_pct": lang.TokenPercent,
+		})
+	}
+	return rows
+}
+
+// languageLine describes a language's share in one line, as
+// "Go: 12 files, 70.0% of lines, 72.4% of tokens"
+func languageLine(lang LanguageShare) string {
+	return fmt.Sprintf("%s: %d files, %.1f%% of lines, %.1f%% of tokens", lang.Name, lang.Files, lang.LinePercent, lang.TokenPercent)
+}
+
+// workspaceSummary renders a WorkspaceInfo for the map-based formats, one
+// uniform row per member with its dependencies joined by spaces
+func workspaceSummary(info *WorkspaceInfo) map[string]interface{} {
+	packages := make([]map[string]interface{}, 0, len(info.Packages))
+	for _, pkg := range info.Packages {
+		packages = append(packages, map[string]interface{}{
+			"name":       pkg.Name,
+			"path":       pkg.Path,
+			"ecosystem":  pkg.Ecosystem,
+			"depends_on": strings.Join(pkg.DependsOn, " "),
+			"files":      pkg.Files,
+			"tokens":     pkg.Tokens,
+		})
+	}
+	return map[string]interface{}{
+		"manifests": strings.Join(info.Manifests, " "),
+		"packages":  packages,
+	}
+}
+
+// auditSummary renders an AuditInfo for the map-based formats, one uniform
+// row per vulnerability
+func auditSummary(info *AuditInfo) map[string]interface{} {
+	vulns := make([]map[string]interface{}, 0, len(info.Vulnerabilities))
+	for _, vuln := range info.Vulnerabilities {
+		vulns = append(vulns, map[string]interface{}{
+			"id":       vuln.ID,
+			"aliases":  strings.Join(vuln.Aliases, " "),
+			"package":  vuln.Package,
+			"version":  vuln.Version,
+			"severity": vuln.Severity,
+			"summary":  vuln.Summary,
+			"fixed":    vuln.Fixed,
+		})
+	}
+	return map[string]interface{}{
+		"source":          info.Source,
+		"checked":         info.Checked,
+		"vulnerabilities": vulns,
+	}
+}
+
+// auditCounts describes an AuditInfo in one line, as
+// "2 known (osv.dev, 14 dependencies checked)"
+func auditCounts(info *AuditInfo) string {
+	found := "none known"
+	if n := len(info.Vulnerabilities); n > 0 {
+		found = fmt.Sprintf("%d known", n)
+	}
+	return fmt.Sprintf("%s (%s, %d dependencies checked)", found, info.Source, info.Checked)
+}
+
+// vulnerabilityLine describes a vulnerability in one line, as
+// "GHSA-35jh-r3h4-6jhm (CVE-2021-23337) in lodash 4.17.20 [HIGH]: Command
+// Injection in lodash; fixed in 4.17.21"
+func vulnerabilityLine(vuln Vulnerability) string {
+	line := vuln.ID
+	if len(vuln.Aliases) > 0 {
+		line += " (" + strings.Join(vuln.Aliases, ", ") + ")"
+	}
+	line += fmt.Sprintf(" in %s %s", vuln.Package, vuln.Version)
+	if vuln.Severity != "" {
+		line += " [" + vuln.Severity + "]"
+	}
+	if vuln.Summary != "" {
+		line += ": " + vuln.Summary
+	}
+	if vuln.Fixed != "" {
+		line += "; fixed in " + vuln.Fixed
+	}
+	return line
+}
+
+// licenseCounts describes the licenses of a LicenseInfo in one line, as
+// "MIT (LICENSE, 3 headers), Apache-2.0 (1 header)"
+func licenseCounts(info *LicenseInfo) string {
+	if len(info.Licenses) == 0 {
+		return "none found"
+	}
+	parts := make([]string, 0, len(info.Licenses))
+	for _, license := range info.Licenses {
+		var where []string
+		where = append(where, license.Sources...)
+		if license.Files == 1 {
+			where = append(where, "1 header")
+		} else if license.Files > 1 {
+			where = append(where, fmt.Sprintf("%d headers", license.Files))
+		}
+		parts = append(parts, fmt.Sprintf("%s (%s)", license.ID, strings.Join(where, ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// frameworkList is the metadata entry for detected frameworks shared by the TOON formats and JSONL
+func frameworkList(frameworks []Framework) []map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(frameworks))
+	for _, framework := range frameworks {
+		list = append(list, map[string]interface{}{
+			"name":        framework.Name,
+			"description": framework.Description,
+			"priority":    framework.Priority,
+		})
+	}
+	return list
+}
+
+// dependencyList is the manifest entry for project dependencies shared by
+// the TOON formats, one uniform row per dependency
+func dependencyList(deps []Dependency) []map[string]interface{} {
+	list := make([]map[string]interface{}, 0, len(deps))
+	for _, dep := range deps {
+		list = append(list, map[string]interface{}{
+			"name":    dep.Name,
+			"version": dep.Version,
+			"scope":   dep.Scope,
+		})
+	}
+	return list
+}
+
+// dependencyLine renders a dependency for the text formats, e.g.
+// "github.com/spf13/pflag v1.0.5" or "vite ^5.0.0 (dev)"
+func dependencyLine(dep Dependency) string {
+	line := strings.TrimSpace(dep.Name + " " + dep.Version)
+	if dep.Scope != "" && dep.Scope != "direct" {
+		line += " (" + dep.Scope + ")"
+	}
+	return line
+}
+
+// frameworkNames lists the display names of frameworks, highest priority first
+func frameworkNames(frameworks []Framework) []string {
+	names := make([]string, 0, len(frameworks))
+	for _, framework := range frameworks {
+		names = append(names, framework.Description)
+	}
+	return names
+}
+
+// sampleMap is the manifest entry for a sampled data file shared by the TOON formats and JSONL
+func sampleMap(sample *SampleInfo) map[string]interface{} {
+	return map[string]interface{}{"rows": sample.Rows, "total_rows": sample.TotalRows, "columns": sample.Columns}
+}
+
+// humanSize formats a byte count for display, e.g. "12.3 KB"
+func humanSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// markdownFences maps file extensions to the code fence languages Markdown
+// renderers highlight, where they differ from the extension
+var markdownFences = map[string]string{
+	"ts": "typescript", "mts": "typescript", "cts": "typescript", "js": "javascript", "mjs": "javascript", "cjs": "javascript",
+	"yml": "yaml", "py": "python", "pyi": "python", "rb": "ruby", "rs": "rust", "kt": "kotlin", "kts": "kotlin",
+	"cs": "csharp", "fs": "fsharp", "h": "c", "cc": "cpp", "cxx": "cpp", "hpp": "cpp", "hh": "cpp", "m": "objectivec",
+	"sh": "bash", "zsh": "bash", "ps1": "powershell", "md": "markdown", "mdx": "mdx", "ex": "elixir", "exs": "elixir",
+	"erl": "erlang", "hs": "haskell", "ml": "ocaml", "clj": "clojure", "jl": "julia", "pl": "perl", "tf": "hcl",
+	"proto": "protobuf", "gql": "graphql", "txt": "text", "jsonc": "json",
+}
+
+// markdownFenceNames maps the names of files without a telling extension
+var markdownFenceNames = map[string]string{
+	"Dockerfile": "dockerfile", "Containerfile": "dockerfile", "Makefile": "makefile", "GNUmakefile": "makefile",
+	"CMakeLists.txt": "cmake", "Gemfile": "ruby", "Rakefile": "ruby", "Jenkinsfile": "groovy", "go.mod": "go-mod", "go.sum": "text",
+}
+
+// fenceLanguage returns the code fence language of a file
+func fenceLanguage(path string) string {
+	base := filepath.Base(path)
+	if lang, ok := markdownFenceNames[base]; ok {
+		return lang
+	}
+	if strings.HasPrefix(base, "Dockerfile.") {
+		return "dockerfile"
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(base), "."))
+	if lang, ok := markdownFences[ext]; ok {
+		return lang
+	}
+	if ext == "" {
+		return "text"
+	}
+	return ext
+}
+
+// codeFence returns a backtick fence longer than any backtick run in
+// content, so the content can't close it early
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// CodeBlock wraps content in a Markdown code block tagged with the language
+// of the file at path, fenced so the content can't close it early
+func CodeBlock(path, content string) string {
+	fence := codeFence(content)
+	return fence + fenceLanguage(path) + "\n" + strings.TrimSuffix(content, "\n") + "\n" + fence
+}
+
+// markdownAnchors gives each file an anchor id of "file-" and its path in
+// lowercase with other characters than letters and digits as dashes, with a
+// number added when two paths come out the same
+func markdownAnchors(files []FileInfo) map[string]string {
+	anchors := make(map[string]string, len(files))
+	used := make(map[string]bool, len(files))
+	for _, file := range files {
+		slug := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return '-'
+		}, file.Path)
+		anchor := "file-" + slug
+		for n := 2; used[anchor]; n++ {
+			anchor = fmt.Sprintf("file-%s-%d", slug, n)
+		}
+		used[anchor] = true
+		anchors[file.Path] = anchor
+	}
+	return anchors
+}
+
+// formatContents lists the files with links to their anchors
+func (m *MarkdownFormatter) formatContents(sb *strings.Builder, files []FileInfo, anchors map[string]string) {
 	if len(files) == 0 {
 		return
 	}
-	sb.WriteString("\n## Source Files\n")
+	sb.WriteString("## Contents\n\n")
 	for _, file := range files {
-		ext := strings.TrimPrefix(filepath.Ext(file.Path), ".")
-		if ext == "" {
-			ext = "text"
-		}
+		label := strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(file.Path)
+		sb.WriteString(fmt.Sprintf("- [%s](#%s) (%d lines)\n", label, anchors[file.Path], strings.Count(file.Content, "\n")+1))
+	}
+	sb.WriteString("\n")
+}
 
+func (m *MarkdownFormatter) formatSourceFiles(sb *strings.Builder, files []FileInfo, anchors map[string]string) {
+	if len(files) == 0 {
+		return
+	}
+	sb.WriteString("\n## Source Files\n")
+	for _, file := range files {
 		lineCount := strings.Count(file.Content, "\n") + 1
-		sb.WriteString(fmt.Sprintf("\n### %s (%d lines)\n", file.Path, lineCount))
-		sb.WriteString(fmt.Sprintf("```%s\n", ext))
+		details := fmt.Sprintf("%d lines", lineCount)
+		if file.External {
+			details = "external, " + details
+		}
+		if file.Sample != nil {
+			details += fmt.Sprintf(", sampled %d of %d rows", file.Sample.Rows, file.Sample.TotalRows)
+		}
+		if len(file.Owners) > 0 {
+			details += ", owners: " + strings.Join(file.Owners, " ")
+		}
+		if file.License != "" {
+			details += ", license: " + file.License
+		}
+		sb.WriteString("\n")
+		if anchor, ok := anchors[file.Path]; ok {
+			sb.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchor))
+		}
+		sb.WriteString(fmt.Sprintf("### %s (%s)\n", file.Path, details))
+		for _, note := range file.Annotations {
+			sb.WriteString(fmt.Sprintf("> **Note:** %s\n", note))
+		}
+		collapsed := m.CollapseLines > 0 && lineCount > m.CollapseLines
+		if collapsed {
+			sb.WriteString(fmt.Sprintf("<details>\n<summary>Show %d lines</summary>\n\n", lineCount))
+		}
+		fence := codeFence(file.Content)
+		sb.WriteString(fmt.Sprintf("%s%s\n", fence, fenceLanguage(file.Path)))
 		sb.WriteString(file.Content)
-		sb.WriteString("\n```\n")
-
+		sb.WriteString("\n" + fence + "\n")
+		if collapsed {
+			sb.WriteString("\n</details>\n")
+		}
 	}
 }
 
 func (m *MarkdownFormatter) Format(project *ProjectOutput) (string, error) {
 	var sb strings.Builder
+	var anchors map[string]string
+	if m.TOC || m.Anchors {
+		anchors = markdownAnchors(project.Files)
+	}
+
+	// Cross-reference the other parts of a split output first
+	if project.Split != nil {
+		sb.WriteString(fmt.Sprintf("Part %d of %d\n", project.Split.Part, project.Split.Total))
+		for _, part := range otherParts(project.Split) {
+			sb.WriteString(fmt.Sprintf("  - part %d: %s\n", part["part"], strings.Join(part["files"].([]string), ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	if m.TOC {
+		m.formatContents(&sb, project.Files, anchors)
+	}
 
 	// Start with language and metadata
 	if project.Metadata != nil {
-		sb.WriteString(fmt.Sprintf("Language: %s\n", project.Metadata.Language))
+		if project.Metadata.Language != "" {
+			sb.WriteString(fmt.Sprintf("Language: %s\n", project.Metadata.Language))
+		}
 		if project.Metadata.Version != "" {
 			sb.WriteString(fmt.Sprintf("Version: %s\n", project.Metadata.Version))
 		}
+		if len(project.Metadata.Frameworks) > 0 {
+			sb.WriteString(fmt.Sprintf("Frameworks: %s\n", strings.Join(frameworkNames(project.Metadata.Frameworks), ", ")))
+		}
+		if len(project.Metadata.Languages) > 0 {
+			sb.WriteString("Languages:\n")
+			for _, lang := range project.Metadata.Languages {
+				sb.WriteString(fmt.Sprintf("  - %s\n", languageLine(lang)))
+			}
+		}
+		if project.Metadata.Owners != nil {
+			sb.WriteString(fmt.Sprintf("Owners: %s\n", ownerCounts(project.Metadata.Owners)))
+		}
+		if licenses := project.Metadata.Licenses; licenses != nil {
+			sb.WriteString(fmt.Sprintf("Licenses: %s\n", licenseCounts(licenses)))
+			for _, notice := range licenses.Copyrights {
+				sb.WriteString(fmt.Sprintf("  - %s\n", notice))
+			}
+			if len(licenses.Flagged) > 0 {
+				sb.WriteString("License headers to review:\n")
+				for _, flag := range licenses.Flagged {
+					sb.WriteString(fmt.Sprintf("  - %s: %s (%s)\n", flag.Path, flag.Reason, flag.Detail))
+				}
+			}
+		}
+		if audit := project.Metadata.Audit; audit != nil {
+			sb.WriteString(fmt.Sprintf("Vulnerabilities: %s\n", auditCounts(audit)))
+			for _, vuln := range audit.Vulnerabilities {
+				sb.WriteString(fmt.Sprintf("  - %s\n", vulnerabilityLine(vuln)))
+			}
+		}
 		if len(project.Metadata.Dependencies) > 0 {
 			sb.WriteString("Dependencies:\n")
 			for _, dep := range project.Metadata.Dependencies {
-				sb.WriteString(fmt.Sprintf("  - %s\n", dep))
+				sb.WriteString(fmt.Sprintf("  - %s\n", dependencyLine(dep)))
 			}
 			sb.WriteString("\n")
 		}
@@ -62,12 +620,128 @@ func (m *MarkdownFormatter) Format(project *ProjectOutput) (string, error) {
 		sb.WriteString("\n")
 	}
 
+	// Imports stripped from the source files are summarized once here
+	if project.Dependencies != nil && len(project.Dependencies.Packages) > 0 {
+		sb.WriteString("Imports:\n")
+		for _, pkg := range project.Dependencies.Packages {
+			sb.WriteString(fmt.Sprintf("  - %s\n", pkg))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Note redacted secrets so readers know placeholders are intentional
+	if project.Redactions != nil {
+		sb.WriteString(fmt.Sprintf("Redactions: %d secret(s) replaced in %d file(s)\n", project.Redactions.Total, project.Redactions.Files))
+		for _, rule := range redactionRules(project.Redactions) {
+			sb.WriteString(fmt.Sprintf("  - %s: %d\n", rule["rule"], rule["count"]))
+		}
+		sb.WriteString("\n")
+	}
+
+	// List files that exist but whose content was left out
+	if len(project.SkippedFiles) > 0 {
+		sb.WriteString("Skipped Files (content not included):\n")
+		for _, file := range project.SkippedFiles {
+			sb.WriteString(fmt.Sprintf("  - %s (%s, %s, %s)\n", file.Path, humanSize(file.Size), file.Kind, file.Reason))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Map the workspace members and what each depends on
+	if project.Workspace != nil {
+		sb.WriteString(fmt.Sprintf("Workspace (%s, %d packages):\n\n", strings.Join(project.Workspace.Manifests, ", "), len(project.Workspace.Packages)))
+		sb.WriteString("| Package | Path | Depends on | Files | Tokens |\n|---------|------|------------|-------|--------|\n")
+		for _, pkg := range project.Workspace.Packages {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %d | %d |\n", pkg.Name, pkg.Path, strings.Join(pkg.DependsOn, ", "), pkg.Files, pkg.Tokens))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Inventory the TODO, FIXME and HACK comments for tech-debt review
+	if len(project.Issues) > 0 {
+		sb.WriteString("Issues (TODO/FIXME/HACK comments):\n\n")
+		sb.WriteString("| File | Line | Kind | Text |\n|------|------|------|------|\n")
+		for _, issue := range project.Issues {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s |\n", issue.Path, issue.Line, issue.Kind, strings.ReplaceAll(issue.Text, "|", "\\|")))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Point at the largest and most branching files
+	if project.Metrics != nil && len(project.Metrics.Files) > 0 {
+		hotspots := metricsHotspots(project.Metrics)
+		sb.WriteString(fmt.Sprintf("Complexity Hotspots (top %d of %d files, %d functions):\n\n", len(hotspots), len(project.Metrics.Files), project.Metrics.Functions))
+		sb.WriteString("| File | Lines | Functions | Complexity | Most complex function |\n|------|-------|-----------|------------|-----------------------|\n")
+		for _, file := range hotspots {
+			most := ""
+			if file.MaxFunction != "" {
+				most = fmt.Sprintf("%s (%d)", file.MaxFunction, file.MaxComplexity)
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %s |\n", file.Path, file.Lines, file.Functions, file.Complexity, most))
+		}
+		sb.WriteString("\n")
+	}
+
+	// Explain how files were ranked and which were kept
+	if project.Selection != nil {
+		sb.WriteString(fmt.Sprintf("Selection Report (relevance threshold %g):\n", project.Selection.Threshold))
+		for _, entry := range project.Selection.Files {
+			decision := "included"
+			if !entry.Included {
+				decision = "excluded: " + entry.Reason
+			}
+			line := fmt.Sprintf("  - [%s] %s score %g, %d tokens", decision, entry.Path, entry.Score, entry.Tokens)
+			if factors := selectionFactors(entry); factors != "" {
+				line += " (" + factors + ")"
+			}
+			if len(entry.Traits) > 0 {
+				line += " " + strings.Join(entry.Traits, ", ")
+			}
+			sb.WriteString(line + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	// Add recent history before the code it explains
+	if len(project.RecentCommits) > 0 {
+		sb.WriteString("Recent Commits:\n")
+		for _, commit := range project.RecentCommits {
+			sb.WriteString(fmt.Sprintf("  - %s %s %s\n", commit.Hash, commit.Date, commit.Subject))
+			for _, line := range strings.Split(commit.Body, "\n") {
+				if line = strings.TrimRight(line, " "); line != "" {
+					sb.WriteString(fmt.Sprintf("    %s\n", line))
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+
 	// Add source files
-	m.formatSourceFiles(&sb, project.Files)
+	m.formatSourceFiles(&sb, project.Files, anchors)
 
 	return sb.String(), nil
 }
 
+// xmlEscape escapes s for use as XML element text or an attribute value
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// cdata wraps s in a CDATA section, splitting any "]]>" in it across two
+// sections so the content can't end the section early. Characters XML
+// doesn't allow, such as terminal escapes, become U+FFFD as in xmlEscape.
+func cdata(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' || r == 0xFFFE || r == 0xFFFF {
+			return '\uFFFD'
+		}
+		return r
+	}, s)
+	return "<![CDATA[" + strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>") + "]]>"
+}
+
 // Helper function to write directory nodes as XML
 func writeDirectoryNode(node *DirectoryNode, b *strings.Builder, indent int) {
 	if node == nil {
@@ -77,7 +751,7 @@ func writeDirectoryNode(node *DirectoryNode, b *strings.Builder, indent int) {
 	indentStr := strings.Repeat(" ", indent)
 
 	if node.Type != "" { // Skip root node
-		b.WriteString(fmt.Sprintf("%s<node name=\"%s\" type=\"%s\"", indentStr, node.Name, node.Type))
+		b.WriteString(fmt.Sprintf("%s<node name=\"%s\" type=\"%s\"", indentStr, xmlEscape(node.Name), node.Type))
 		if len(node.Children) == 0 {
 			b.WriteString("/>\n")
 			return
@@ -94,17 +768,57 @@ func writeDirectoryNode(node *DirectoryNode, b *strings.Builder, indent int) {
 	}
 }
 
+func (x *XMLFormatter) formatIntegrity(b *strings.Builder, info *IntegrityInfo) {
+	if info == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("  <integrity algorithm=\"%s\" content=\"%s\"/>\n", info.Algorithm, info.Content))
+}
+
+func (x *XMLFormatter) formatMetadata(b *strings.Builder, metadata *Metadata) {
+	if metadata == nil || (metadata.Language == "" && metadata.Version == "" && len(metadata.Dependencies) == 0 && len(metadata.Frameworks) == 0) {
+		return
+	}
+	b.WriteString("  <metadata>\n")
+	if metadata.Language != "" {
+		b.WriteString(fmt.Sprintf("    <language>%s</language>\n", xmlEscape(metadata.Language)))
+	}
+	if metadata.Version != "" {
+		b.WriteString(fmt.Sprintf("    <version>%s</version>\n", xmlEscape(metadata.Version)))
+	}
+	if len(metadata.Dependencies) > 0 {
+		b.WriteString("    <dependencies>\n")
+		for _, dep := range metadata.Dependencies {
+			b.WriteString(fmt.Sprintf("      <dependency name=\"%s\"", xmlEscape(dep.Name)))
+			if dep.Version != "" {
+				b.WriteString(fmt.Sprintf(" version=\"%s\"", xmlEscape(dep.Version)))
+			}
+			b.WriteString(fmt.Sprintf(" scope=\"%s\"/>\n", dep.Scope))
+		}
+		b.WriteString("    </dependencies>\n")
+	}
+	if len(metadata.Frameworks) > 0 {
+		b.WriteString("    <frameworks>\n")
+		for _, framework := range metadata.Frameworks {
+			b.WriteString(fmt.Sprintf("      <framework name=\"%s\" priority=\"%d\">%s</framework>\n",
+				xmlEscape(framework.Name), framework.Priority, xmlEscape(framework.Description)))
+		}
+		b.WriteString("    </frameworks>\n")
+	}
+	b.WriteString("  </metadata>\n")
+}
+
 func (x *XMLFormatter) formatOverview(b *strings.Builder, overview *ProjectOverview) {
 	if overview == nil {
 		return
 	}
 	b.WriteString("  <overview>\n")
-	b.WriteString(fmt.Sprintf("    <description><![CDATA[%s]]></description>\n", overview.Description))
-	b.WriteString(fmt.Sprintf("    <purpose><![CDATA[%s]]></purpose>\n", overview.Purpose))
+	b.WriteString(fmt.Sprintf("    <description>%s</description>\n", cdata(overview.Description)))
+	b.WriteString(fmt.Sprintf("    <purpose>%s</purpose>\n", cdata(overview.Purpose)))
 	if len(overview.Features) > 0 {
 		b.WriteString("    <features>\n")
 		for _, feature := range overview.Features {
-			b.WriteString(fmt.Sprintf("      <feature>%s</feature>\n", feature))
+			b.WriteString(fmt.Sprintf("      <feature>%s</feature>\n", xmlEscape(feature)))
 		}
 		b.WriteString("    </features>\n")
 	}
@@ -121,27 +835,109 @@ func (x *XMLFormatter) formatFileStats(b *strings.Builder, stats *FileStatistics
 	b.WriteString(fmt.Sprintf("    <packageCount>%d</packageCount>\n", stats.PackageCount))
 	if len(stats.FilesByType) > 0 {
 		b.WriteString("    <fileTypes>\n")
-		for ext, count := range stats.FilesByType {
-			b.WriteString(fmt.Sprintf("      <type ext=\"%s\">%d</type>\n", ext, count))
+		for _, ext := range sortedKeys(stats.FilesByType) {
+			b.WriteString(fmt.Sprintf("      <type ext=\"%s\">%d</type>\n", xmlEscape(ext), stats.FilesByType[ext]))
 		}
 		b.WriteString("    </fileTypes>\n")
 	}
 	b.WriteString("  </fileStats>\n")
 }
 
+func (x *XMLFormatter) formatBudget(b *strings.Builder, budget *BudgetInfo) {
+	if budget == nil {
+		return
+	}
+	b.WriteString("  <budget>\n")
+	b.WriteString(fmt.Sprintf("    <maxTokens>%d</maxTokens>\n", budget.MaxTokens))
+	b.WriteString(fmt.Sprintf("    <estimatedTokens>%d</estimatedTokens>\n", budget.EstimatedTokens))
+	b.WriteString(fmt.Sprintf("    <fileTruncations>%d</fileTruncations>\n", budget.FileTruncations))
+	b.WriteString("  </budget>\n")
+}
+
+func (x *XMLFormatter) formatFilterConfig(b *strings.Builder, filters *FilterConfig) {
+	if filters == nil || (len(filters.Includes) == 0 && len(filters.Excludes) == 0) {
+		return
+	}
+	b.WriteString("  <filterConfig>\n")
+	if len(filters.Includes) > 0 {
+		b.WriteString("    <includes>\n")
+		for _, pattern := range filters.Includes {
+			b.WriteString(fmt.Sprintf("      <include>%s</include>\n", xmlEscape(pattern)))
+		}
+		b.WriteString("    </includes>\n")
+	}
+	if len(filters.Excludes) > 0 {
+		b.WriteString("    <excludes>\n")
+		for _, pattern := range filters.Excludes {
+			b.WriteString(fmt.Sprintf("      <exclude>%s</exclude>\n", xmlEscape(pattern)))
+		}
+		b.WriteString("    </excludes>\n")
+	}
+	b.WriteString("  </filterConfig>\n")
+}
+
 func (x *XMLFormatter) formatGitInfo(b *strings.Builder, gitInfo *GitInfo) {
 	if gitInfo == nil {
 		return
 	}
 	b.WriteString("  <gitInfo>\n")
-	b.WriteString(fmt.Sprintf("    <branch>%s</branch>\n", gitInfo.Branch))
+	b.WriteString(fmt.Sprintf("    <branch>%s</branch>\n", xmlEscape(gitInfo.Branch)))
 	b.WriteString(fmt.Sprintf("    <commitHash>%s</commitHash>\n", gitInfo.CommitHash))
-	b.WriteString("    <commitMessage><![CDATA[")
-	b.WriteString(gitInfo.CommitMessage)
-	b.WriteString("]]></commitMessage>\n")
+	b.WriteString(fmt.Sprintf("    <commitMessage>%s</commitMessage>\n", cdata(gitInfo.CommitMessage)))
 	b.WriteString("  </gitInfo>\n")
 }
 
+func (x *XMLFormatter) formatRecentCommits(b *strings.Builder, commits []CommitInfo) {
+	if len(commits) == 0 {
+		return
+	}
+	b.WriteString("  <recentCommits>\n")
+	for _, commit := range commits {
+		b.WriteString(fmt.Sprintf("    <commit hash=\"%s\" date=\"%s\">\n", commit.Hash, xmlEscape(commit.Date)))
+		b.WriteString(fmt.Sprintf("      <subject>%s</subject>\n", cdata(commit.Subject)))
+		if commit.Body != "" {
+			b.WriteString(fmt.Sprintf("      <body>%s</body>\n", cdata(commit.Body)))
+		}
+		b.WriteString("    </commit>\n")
+	}
+	b.WriteString("  </recentCommits>\n")
+}
+
+// formatAnalysis lists the files the analysis classified, each with its
+// description, by category
+func (x *XMLFormatter) formatAnalysis(b *strings.Builder, analysis *ProjectAnalysis) {
+	if analysis == nil {
+		return
+	}
+	categories := []struct {
+		name  string
+		files map[string]string
+	}{
+		{"entryPoints", analysis.EntryPoints},
+		{"configFiles", analysis.ConfigFiles},
+		{"coreFiles", analysis.CoreFiles},
+		{"testFiles", analysis.TestFiles},
+		{"documentation", analysis.Documentation},
+	}
+	var sb strings.Builder
+	for _, category := range categories {
+		if len(category.files) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("    <%s>\n", category.name))
+		for _, path := range sortedKeys(category.files) {
+			sb.WriteString(fmt.Sprintf("      <file path=\"%s\">%s</file>\n", xmlEscape(path), xmlEscape(category.files[path])))
+		}
+		sb.WriteString(fmt.Sprintf("    </%s>\n", category.name))
+	}
+	if sb.Len() == 0 {
+		return
+	}
+	b.WriteString("  <analysis>\n")
+	b.WriteString(sb.String())
+	b.WriteString("  </analysis>\n")
+}
+
 func (x *XMLFormatter) formatDependencies(b *strings.Builder, deps *DependencyInfo) {
 	if deps == nil {
 		return
@@ -149,25 +945,232 @@ func (x *XMLFormatter) formatDependencies(b *strings.Builder, deps *DependencyIn
 	b.WriteString("  <dependencies>\n")
 	if len(deps.Imports) > 0 {
 		b.WriteString("    <imports>\n")
-		for file, imports := range deps.Imports {
-			b.WriteString(fmt.Sprintf("      <file path=\"%s\">\n", file))
-			for _, imp := range imports {
-				b.WriteString(fmt.Sprintf("        <import>%s</import>\n", imp))
+		for _, file := range sortedKeys(deps.Imports) {
+			b.WriteString(fmt.Sprintf("      <file path=\"%s\">\n", xmlEscape(file)))
+			for _, imp := range deps.Imports[file] {
+				b.WriteString(fmt.Sprintf("        <import>%s</import>\n", xmlEscape(imp)))
 			}
 			b.WriteString("      </file>\n")
 		}
 		b.WriteString("    </imports>\n")
 	}
+	if len(deps.Packages) > 0 {
+		b.WriteString("    <packages>\n")
+		for _, pkg := range deps.Packages {
+			b.WriteString(fmt.Sprintf("      <package>%s</package>\n", xmlEscape(pkg)))
+		}
+		b.WriteString("    </packages>\n")
+	}
 	if len(deps.CoreFiles) > 0 {
 		b.WriteString("    <coreFiles>\n")
 		for _, file := range deps.CoreFiles {
-			b.WriteString(fmt.Sprintf("      <file>%s</file>\n", file))
+			b.WriteString(fmt.Sprintf("      <file>%s</file>\n", xmlEscape(file)))
 		}
 		b.WriteString("    </coreFiles>\n")
 	}
 	b.WriteString("  </dependencies>\n")
 }
 
+func (x *XMLFormatter) formatPackages(b *strings.Builder, packages []PackageInfo) {
+	if len(packages) == 0 {
+		return
+	}
+	b.WriteString("  <packages>\n")
+	for _, pkg := range packages {
+		b.WriteString(fmt.Sprintf("    <package name=\"%s\" path=\"%s\">\n", xmlEscape(pkg.Name), xmlEscape(pkg.Path)))
+		b.WriteString("      <files>\n")
+		for _, file := range pkg.Files {
+			b.WriteString(fmt.Sprintf("        <file>%s</file>\n", xmlEscape(file)))
+		}
+		b.WriteString("      </files>\n")
+		if len(pkg.Imports) > 0 {
+			b.WriteString("      <imports>\n")
+			for _, imp := range pkg.Imports {
+				b.WriteString(fmt.Sprintf("        <import>%s</import>\n", xmlEscape(imp)))
+			}
+			b.WriteString("      </imports>\n")
+		}
+		b.WriteString("    </package>\n")
+	}
+	b.WriteString("  </packages>\n")
+}
+
+func (x *XMLFormatter) formatRedactions(b *strings.Builder, info *RedactionInfo) {
+	if info == nil {
+		return
+	}
+	rules := redactionRules(info)
+	if len(rules) == 0 {
+		b.WriteString(fmt.Sprintf("  <redactions total=\"%d\" files=\"%d\"/>\n", info.Total, info.Files))
+		return
+	}
+	b.WriteString(fmt.Sprintf("  <redactions total=\"%d\" files=\"%d\">\n", info.Total, info.Files))
+	for _, rule := range rules {
+		b.WriteString(fmt.Sprintf("    <rule name=\"%s\" count=\"%d\"/>\n", xmlEscape(rule["rule"].(string)), rule["count"]))
+	}
+	b.WriteString("  </redactions>\n")
+}
+
+func (x *XMLFormatter) formatOwners(b *strings.Builder, info *OwnerInfo) {
+	if info == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("  <owners source=\"%s\" unowned=\"%d\">\n", xmlEscape(info.Source), info.Unowned))
+	for _, owner := range info.Owners {
+		b.WriteString(fmt.Sprintf("    <owner name=\"%s\" files=\"%d\" tokens=\"%d\"/>\n", xmlEscape(owner.Owner), owner.Files, owner.Tokens))
+	}
+	b.WriteString("  </owners>\n")
+}
+
+func (x *XMLFormatter) formatLicenses(b *strings.Builder, info *LicenseInfo) {
+	if info == nil {
+		return
+	}
+	b.WriteString("  <licenses>\n")
+	for _, license := range info.Licenses {
+		b.WriteString(fmt.Sprintf("    <license id=\"%s\" files=\"%d\"", xmlEscape(license.ID), license.Files))
+		if len(license.Sources) > 0 {
+			b.WriteString(fmt.Sprintf(" sources=\"%s\"", xmlEscape(strings.Join(license.Sources, " "))))
+		}
+		b.WriteString("/>\n")
+	}
+	for _, flag := range info.Flagged {
+		b.WriteString(fmt.Sprintf("    <flagged path=\"%s\" reason=\"%s\">%s</flagged>\n", xmlEscape(flag.Path), flag.Reason, cdata(flag.Detail)))
+	}
+	for _, notice := range info.Copyrights {
+		b.WriteString(fmt.Sprintf("    <copyright>%s</copyright>\n", cdata(notice)))
+	}
+	b.WriteString("  </licenses>\n")
+}
+
+func (x *XMLFormatter) formatLanguages(b *strings.Builder, languages []LanguageShare) {
+	if len(languages) == 0 {
+		return
+	}
+	b.WriteString("  <languages>\n")
+	for _, lang := range languages {
+		b.WriteString(fmt.Sprintf("    <language name=\"%s\" files=\"%d\" lines=\"%d\" linePercent=\"%.1f\" tokens=\"%d\" tokenPercent=\"%.1f\"/>\n",
+			xmlEscape(lang.Name), lang.Files, lang.Lines, lang.LinePercent, lang.Tokens, lang.TokenPercent))
+	}
+	b.WriteString("  </languages>\n")
+}
+
+func (x *XMLFormatter) formatAudit(b *strings.Builder, info *AuditInfo) {
+	if info == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("  <audit source=\"%s\" checked=\"%d\">\n", xmlEscape(info.Source), info.Checked))
+	for _, vuln := range info.Vulnerabilities {
+		b.WriteString(fmt.Sprintf("    <vulnerability id=\"%s\" package=\"%s\" version=\"%s\"", xmlEscape(vuln.ID), xmlEscape(vuln.Package), xmlEscape(vuln.Version)))
+		if vuln.Severity != "" {
+			b.WriteString(fmt.Sprintf(" severity=\"%s\"", xmlEscape(vuln.Severity)))
+		}
+		if vuln.Fixed != "" {
+			b.WriteString(fmt.Sprintf(" fixed=\"%s\"", xmlEscape(vuln.Fixed)))
+		}
+		b.WriteString(">\n")
+		for _, alias := range vuln.Aliases {
+			b.WriteString(fmt.Sprintf("      <alias>%s</alias>\n", xmlEscape(alias)))
+		}
+		b.WriteString(fmt.Sprintf("      <summary>%s</summary>\n", cdata(vuln.Summary)))
+		b.WriteString("    </vulnerability>\n")
+	}
+	b.WriteString("  </audit>\n")
+}
+
+func (x *XMLFormatter) formatWorkspace(b *strings.Builder, info *WorkspaceInfo) {
+	if info == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("  <workspace manifests=\"%s\">\n", xmlEscape(strings.Join(info.Manifests, " "))))
+	for _, pkg := range info.Packages {
+		b.WriteString(fmt.Sprintf("    <package name=\"%s\" path=\"%s\" ecosystem=\"%s\" files=\"%d\" tokens=\"%d\"",
+			xmlEscape(pkg.Name), xmlEscape(pkg.Path), pkg.Ecosystem, pkg.Files, pkg.Tokens))
+		if len(pkg.DependsOn) == 0 {
+			b.WriteString("/>\n")
+			continue
+		}
+		b.WriteString(">\n")
+		for _, dep := range pkg.DependsOn {
+			b.WriteString(fmt.Sprintf("      <dependsOn>%s</dependsOn>\n", xmlEscape(dep)))
+		}
+		b.WriteString("    </package>\n")
+	}
+	b.WriteString("  </workspace>\n")
+}
+
+func (x *XMLFormatter) formatSkippedFiles(b *strings.Builder, files []SkippedFile) {
+	if len(files) == 0 {
+		return
+	}
+	b.WriteString("  <skippedFiles>\n")
+	for _, file := range files {
+		b.WriteString(fmt.Sprintf("    <file path=\"%s\" size=\"%d\" kind=\"%s\" reason=\"%s\"/>\n", xmlEscape(file.Path), file.Size, file.Kind, file.Reason))
+	}
+	b.WriteString("  </skippedFiles>\n")
+}
+
+func (x *XMLFormatter) formatIssues(b *strings.Builder, issues []Issue) {
+	if len(issues) == 0 {
+		return
+	}
+	b.WriteString("  <issues>\n")
+	for _, issue := range issues {
+		b.WriteString(fmt.Sprintf("    <issue path=\"%s\" line=\"%d\" kind=\"%s\">%s</issue>\n", xmlEscape(issue.Path), issue.Line, issue.Kind, cdata(issue.Text)))
+	}
+	b.WriteString("  </issues>\n")
+}
+
+func (x *XMLFormatter) formatMetrics(b *strings.Builder, info *MetricsInfo) {
+	if info == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("  <metrics functions=\"%d\">\n", info.Functions))
+	for _, file := range info.Files {
+		b.WriteString(fmt.Sprintf("    <file path=\"%s\" lines=\"%d\" functions=\"%d\" complexity=\"%d\" maxComplexity=\"%d\"",
+			xmlEscape(file.Path), file.Lines, file.Functions, file.Complexity, file.MaxComplexity))
+		if file.MaxFunction != "" {
+			b.WriteString(fmt.Sprintf(" maxFunction=\"%s\"", xmlEscape(file.MaxFunction)))
+		}
+		b.WriteString("/>\n")
+	}
+	b.WriteString("  </metrics>\n")
+}
+
+func (x *XMLFormatter) formatSelection(b *strings.Builder, report *SelectionReport) {
+	if report == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("  <selection threshold=\"%g\">\n", report.Threshold))
+	for _, entry := range report.Files {
+		b.WriteString(fmt.Sprintf("    <file path=\"%s\" tokens=\"%d\" score=\"%g\" filename=\"%g\" directory=\"%g\" imports=\"%g\" content=\"%g\" included=\"%t\"",
+			xmlEscape(entry.Path), entry.Tokens, entry.Score, entry.Filename, entry.Directory, entry.Imports, entry.Content, entry.Included))
+		if len(entry.Traits) > 0 {
+			b.WriteString(fmt.Sprintf(" traits=\"%s\"", strings.Join(entry.Traits, " ")))
+		}
+		if entry.Reason != "" {
+			b.WriteString(fmt.Sprintf(" reason=\"%s\"", xmlEscape(entry.Reason)))
+		}
+		b.WriteString("/>\n")
+	}
+	b.WriteString("  </selection>\n")
+}
+
+func (x *XMLFormatter) formatSplit(b *strings.Builder, info *SplitInfo) {
+	if info == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("  <split part=\"%d\" total=\"%d\">\n", info.Part, info.Total))
+	for _, part := range otherParts(info) {
+		b.WriteString(fmt.Sprintf("    <part number=\"%d\">\n", part["part"]))
+		for _, path := range part["files"].([]string) {
+			b.WriteString(fmt.Sprintf("      <file path=\"%s\"/>\n", xmlEscape(path)))
+		}
+		b.WriteString("    </part>\n")
+	}
+	b.WriteString("  </split>\n")
+}
+
 func (x *XMLFormatter) formatFiles(b *strings.Builder, files []FileInfo) {
 	if len(files) == 0 {
 		return
@@ -175,25 +1178,67 @@ func (x *XMLFormatter) formatFiles(b *strings.Builder, files []FileInfo) {
 	b.WriteString("  <files>\n")
 	for _, file := range files {
 		lineCount := strings.Count(file.Content, "\n") + 1
-		b.WriteString(fmt.Sprintf("    <file path=\"%s\" lines=\"%d\">\n", file.Path, lineCount))
-		b.WriteString("      <content><![CDATA[")
-		b.WriteString(file.Content)
-		b.WriteString("]]></content>\n")
+		attrs := fmt.Sprintf("path=\"%s\" lines=\"%d\"", xmlEscape(file.Path), lineCount)
+		if file.Tokens > 0 {
+			attrs += fmt.Sprintf(" tokens=\"%d\"", file.Tokens)
+		}
+		if file.Hash != "" {
+			attrs += fmt.Sprintf(" sha256=\"%s\"", file.Hash)
+		}
+		if n := file.RedactionCount(); n > 0 {
+			attrs += fmt.Sprintf(" redactions=\"%d\"", n)
+		}
+		if file.Compaction != "" {
+			attrs += fmt.Sprintf(" compaction=\"%s\"", file.Compaction)
+		}
+		if file.External {
+			attrs += " external=\"true\""
+		}
+		if len(file.Owners) > 0 {
+			attrs += fmt.Sprintf(" owners=\"%s\"", xmlEscape(strings.Join(file.Owners, " ")))
+		}
+		if file.License != "" {
+			attrs += fmt.Sprintf(" license=\"%s\"", xmlEscape(file.License))
+		}
+		if file.Sample != nil {
+			attrs += fmt.Sprintf(" sampled-rows=\"%d\" total-rows=\"%d\" columns=\"%d\"", file.Sample.Rows, file.Sample.TotalRows, file.Sample.Columns)
+		}
+		b.WriteString(fmt.Sprintf("    <file %s>\n", attrs))
+		if file.Truncation != nil {
+			b.WriteString(fmt.Sprintf("      <truncation mode=\"%s\" originalTokens=\"%d\"/>\n", xmlEscape(file.Truncation.Mode), file.Truncation.OriginalTokens))
+		}
+		if len(file.AssociatedTests) > 0 {
+			b.WriteString("      <associatedTests>\n")
+			for _, test := range file.AssociatedTests {
+				b.WriteString(fmt.Sprintf("        <test>%s</test>\n", xmlEscape(test)))
+			}
+			b.WriteString("      </associatedTests>\n")
+		}
+		for _, note := range file.Annotations {
+			b.WriteString(fmt.Sprintf("      <note>%s</note>\n", cdata(note)))
+		}
+		b.WriteString(fmt.Sprintf("      <content>%s</content>\n", cdata(file.Content)))
 		b.WriteString("    </file>\n")
 	}
 	b.WriteString("  </files>\n")
 }
 
+// Format renders the project as XML with the sections of the PTX manifest,
+// valid against the schema Schema("xml") returns. Sections appear in a fixed
+// order and are left out when empty.
 func (x *XMLFormatter) Format(project *ProjectOutput) (string, error) {
 	var b strings.Builder
-	enc := xml.NewEncoder(&b)
-	enc.Indent("", "  ")
 
 	b.WriteString(xml.Header)
 	b.WriteString("<project>\n")
 
+	x.formatIntegrity(&b, project.Integrity)
+	x.formatSplit(&b, project.Split)
+	x.formatMetadata(&b, project.Metadata)
 	x.formatOverview(&b, project.Overview)
 	x.formatFileStats(&b, project.FileStats)
+	x.formatBudget(&b, project.Budget)
+	x.formatFilterConfig(&b, project.FilterConfig)
 
 	// Directory Tree
 	b.WriteString("  <directoryTree>\n")
@@ -201,7 +1246,22 @@ func (x *XMLFormatter) Format(project *ProjectOutput) (string, error) {
 	b.WriteString("  </directoryTree>\n")
 
 	x.formatGitInfo(&b, project.GitInfo)
+	x.formatRecentCommits(&b, project.RecentCommits)
+	x.formatAnalysis(&b, project.Analysis)
 	x.formatDependencies(&b, project.Dependencies)
+	x.formatPackages(&b, project.Packages)
+	x.formatRedactions(&b, project.Redactions)
+	x.formatWorkspace(&b, project.Workspace)
+	if project.Metadata != nil {
+		x.formatLanguages(&b, project.Metadata.Languages)
+		x.formatOwners(&b, project.Metadata.Owners)
+		x.formatLicenses(&b, project.Metadata.Licenses)
+		x.formatAudit(&b, project.Metadata.Audit)
+	}
+	x.formatSkippedFiles(&b, project.SkippedFiles)
+	x.formatIssues(&b, project.Issues)
+	x.formatMetrics(&b, project.Metrics)
+	x.formatSelection(&b, project.Selection)
 	x.formatFiles(&b, project.Files)
 
 	b.WriteString("</project>")
@@ -216,17 +1276,42 @@ func (t *PTXFormatter) Format(project *ProjectOutput) (string, error) {
 	// PTX schema version and manifest
 	promptext := make(map[string]interface{})
 	promptext["schema"] = "ptx/v2.0"
+	if project.Integrity != nil {
+		promptext["integrity"] = integritySummary(project, true)
+	}
 	data["promptext"] = promptext
 
+	// Position within a split output and where the other files went
+	if project.Split != nil {
+		data["split"] = splitSummary(project.Split)
+	}
+
 	// Project metadata with enhanced fields
 	if project.Metadata != nil {
 		metadata := make(map[string]interface{})
-		metadata["language"] = project.Metadata.Language
+		if project.Metadata.Language != "" {
+			metadata["language"] = project.Metadata.Language
+		}
+		if len(project.Metadata.Languages) > 0 {
+			metadata["languages"] = languageList(project.Metadata.Languages)
+		}
 		if project.Metadata.Version != "" {
 			metadata["version"] = project.Metadata.Version
 		}
 		if len(project.Metadata.Dependencies) > 0 {
-			metadata["dependencies"] = project.Metadata.Dependencies
+			metadata["dependencies"] = dependencyList(project.Metadata.Dependencies)
+		}
+		if len(project.Metadata.Frameworks) > 0 {
+			metadata["frameworks"] = frameworkList(project.Metadata.Frameworks)
+		}
+		if project.Metadata.Owners != nil {
+			metadata["owners"] = ownerSummary(project.Metadata.Owners)
+		}
+		if project.Metadata.Licenses != nil {
+			metadata["licenses"] = licenseSummary(project.Metadata.Licenses)
+		}
+		if project.Metadata.Audit != nil {
+			metadata["audit"] = auditSummary(project.Metadata.Audit)
 		}
 
 		// Add project size stats for instant intuition
@@ -249,6 +1334,11 @@ func (t *PTXFormatter) Format(project *ProjectOutput) (string, error) {
 		data["git"] = gitInfo
 	}
 
+	// Recent commit history, newest first
+	if len(project.RecentCommits) > 0 {
+		data["commits"] = commitList(project.RecentCommits, false)
+	}
+
 	// Budget information (token estimation and truncation tracking)
 	if project.Budget != nil {
 		budget := make(map[string]interface{})
@@ -260,6 +1350,30 @@ func (t *PTXFormatter) Format(project *ProjectOutput) (string, error) {
 		data["budget"] = budget
 	}
 
+	// Secrets replaced with placeholders
+	if project.Redactions != nil {
+		data["redactions"] = redactionSummary(project.Redactions)
+	}
+
+	// Files that exist but whose content was left out
+	if len(project.SkippedFiles) > 0 {
+		data["skipped"] = skippedList(project.SkippedFiles)
+	}
+	if len(project.Issues) > 0 {
+		data["issues"] = issueList(project.Issues)
+	}
+	if project.Metrics != nil {
+		data["metrics"] = metricsSummary(project.Metrics)
+	}
+
+	// Why each candidate file was ranked, kept or dropped
+	if project.Selection != nil {
+		data["selection"] = map[string]interface{}{
+			"threshold": project.Selection.Threshold,
+			"files":     selectionList(project.Selection),
+		}
+	}
+
 	// Filter configuration used to generate this output
 	if project.FilterConfig != nil {
 		filters := make(map[string]interface{})
@@ -282,10 +1396,10 @@ func (t *PTXFormatter) Format(project *ProjectOutput) (string, error) {
 		if len(project.FileStats.FilesByType) > 0 {
 			// Convert map to tabular array for token efficiency
 			var fileTypes []map[string]interface{}
-			for ext, count := range project.FileStats.FilesByType {
+			for _, ext := range sortedKeys(project.FileStats.FilesByType) {
 				fileTypes = append(fileTypes, map[string]interface{}{
 					"type":  ext,
-					"count": count,
+					"count": project.FileStats.FilesByType[ext],
 				})
 			}
 			stats["fileTypes"] = fileTypes
@@ -339,6 +1453,26 @@ func (t *PTXFormatter) Format(project *ProjectOutput) (string, error) {
 		}
 	}
 
+	// Packages - architecture map of Go/JS packages and their internal imports
+	if len(project.Packages) > 0 {
+		var packages []map[string]interface{}
+		for _, pkg := range project.Packages {
+			entry := map[string]interface{}{
+				"name":  pkg.Name,
+				"path":  pkg.Path,
+				"files": pkg.Files,
+			}
+			if len(pkg.Imports) > 0 {
+				entry["imports"] = pkg.Imports
+			}
+			packages = append(packages, entry)
+		}
+		data["packages"] = packages
+	}
+	if project.Workspace != nil {
+		data["workspace"] = workspaceSummary(project.Workspace)
+	}
+
 	// Files - enhanced manifest with per-file metadata including token counts and truncation info
 	if len(project.Files) > 0 {
 		// Sort files by path for deterministic output (PTX v2.0 requirement)
@@ -375,6 +1509,33 @@ func (t *PTXFormatter) Format(project *ProjectOutput) (string, error) {
 				fileEntry["truncation"] = truncInfo
 			}
 
+			// Add associated test files if pairing was requested
+			if len(file.AssociatedTests) > 0 {
+				fileEntry["tests"] = file.AssociatedTests
+			}
+			if len(file.Annotations) > 0 {
+				fileEntry["notes"] = file.Annotations
+			}
+			if len(file.Owners) > 0 {
+				fileEntry["owners"] = file.Owners
+			}
+			if file.License != "" {
+				fileEntry["license"] = file.License
+			}
+			if file.External {
+				fileEntry["external"] = true
+			}
+			if file.Sample != nil {
+				fileEntry["sampled"] = sampleMap(file.Sample)
+			}
+
+			if n := file.RedactionCount(); n > 0 {
+				fileEntry["redactions"] = n
+			}
+			if file.Compaction != "" {
+				fileEntry["compaction"] = file.Compaction
+			}
+
 			fileMetadata = append(fileMetadata, fileEntry)
 		}
 		data["files"] = fileMetadata
@@ -451,15 +1612,65 @@ func (t *PTXFormatter) buildDirectoryMap(node *DirectoryNode, currentPath string
 // Helper to convert map[string]string to list of maps for tabular format
 func (t *PTXFormatter) mapToList(m map[string]string) []map[string]interface{} {
 	var result []map[string]interface{}
-	for k, v := range m {
+	for _, k := range sortedKeys(m) {
 		result = append(result, map[string]interface{}{
 			"path": k,
-			"desc": v,
+			"desc": m[k],
 		})
 	}
 	return result
 }
 
+// integritySummary builds the integrity manifest of the PTX and JSONL
+// formats. PTX lists each file's hash in it, as its files table can't hold
+// a column only some rows have; JSONL puts the hash on each file line.
+func integritySummary(project *ProjectOutput, withFiles bool) map[string]interface{} {
+	summary := map[string]interface{}{
+		"algorithm": project.Integrity.Algorithm,
+		"content":   project.Integrity.Content,
+	}
+	if withFiles {
+		files := make([]interface{}, 0, len(project.Files))
+		for _, file := range project.Files {
+			files = append(files, map[string]interface{}{"path": file.Path, "sha256": file.Hash})
+		}
+		summary["files"] = files
+	}
+	return summary
+}
+
+// sortedKeys returns the keys of m in order, for rendering maps the same way every time
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// commitList converts commits to TOON list entries. Bodies are escaped for
+// TOON v1.3 strict output, where multiline strings are not allowed.
+func commitList(commits []CommitInfo, escape bool) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, commit := range commits {
+		entry := map[string]interface{}{
+			"hash":    commit.Hash,
+			"date":    commit.Date,
+			"subject": commit.Subject,
+		}
+		if commit.Body != "" {
+			if escape {
+				entry["body"] = escapeForTOON(commit.Body)
+			} else {
+				entry["body"] = commit.Body
+			}
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
 // TOONStrictFormatter implements TOON v1.3 strict compliance
 // This formatter follows the official TOON specification exactly,
 // using escaped strings for code content instead of multiline blocks.
@@ -470,15 +1681,37 @@ func (t *TOONStrictFormatter) Format(project *ProjectOutput) (string, error) {
 	// Build structured data similar to PTX but with escaped strings
 	data := make(map[string]interface{})
 
+	// Split position (same as PTX)
+	if project.Split != nil {
+		data["split"] = splitSummary(project.Split)
+	}
+
 	// Project metadata (same as PTX)
 	if project.Metadata != nil {
 		metadata := make(map[string]interface{})
-		metadata["language"] = project.Metadata.Language
+		if project.Metadata.Language != "" {
+			metadata["language"] = project.Metadata.Language
+		}
+		if len(project.Metadata.Languages) > 0 {
+			metadata["languages"] = languageList(project.Metadata.Languages)
+		}
 		if project.Metadata.Version != "" {
 			metadata["version"] = project.Metadata.Version
 		}
 		if len(project.Metadata.Dependencies) > 0 {
-			metadata["dependencies"] = project.Metadata.Dependencies
+			metadata["dependencies"] = dependencyList(project.Metadata.Dependencies)
+		}
+		if len(project.Metadata.Frameworks) > 0 {
+			metadata["frameworks"] = frameworkList(project.Metadata.Frameworks)
+		}
+		if project.Metadata.Owners != nil {
+			metadata["owners"] = ownerSummary(project.Metadata.Owners)
+		}
+		if project.Metadata.Licenses != nil {
+			metadata["licenses"] = licenseSummary(project.Metadata.Licenses)
+		}
+		if project.Metadata.Audit != nil {
+			metadata["audit"] = auditSummary(project.Metadata.Audit)
 		}
 
 		// Add project size stats
@@ -502,6 +1735,35 @@ func (t *TOONStrictFormatter) Format(project *ProjectOutput) (string, error) {
 		data["git"] = gitInfo
 	}
 
+	// Recent commit history (same as PTX, with escaped bodies)
+	if project.Dependencies != nil && len(project.Dependencies.Packages) > 0 {
+		data["dependencies"] = map[string]interface{}{"packages": project.Dependencies.Packages}
+	}
+	if len(project.RecentCommits) > 0 {
+		data["commits"] = commitList(project.RecentCommits, true)
+	}
+	if project.Redactions != nil {
+		data["redactions"] = redactionSummary(project.Redactions)
+	}
+	if len(project.SkippedFiles) > 0 {
+		data["skipped"] = skippedList(project.SkippedFiles)
+	}
+	if len(project.Issues) > 0 {
+		data["issues"] = issueList(project.Issues)
+	}
+	if project.Metrics != nil {
+		data["metrics"] = metricsSummary(project.Metrics)
+	}
+	if project.Workspace != nil {
+		data["workspace"] = workspaceSummary(project.Workspace)
+	}
+	if project.Selection != nil {
+		data["selection"] = map[string]interface{}{
+			"threshold": project.Selection.Threshold,
+			"files":     selectionList(project.Selection),
+		}
+	}
+
 	// File statistics (same as PTX)
 	if project.FileStats != nil {
 		stats := make(map[string]interface{})
@@ -511,10 +1773,10 @@ func (t *TOONStrictFormatter) Format(project *ProjectOutput) (string, error) {
 
 		if len(project.FileStats.FilesByType) > 0 {
 			var fileTypes []map[string]interface{}
-			for ext, count := range project.FileStats.FilesByType {
+			for _, ext := range sortedKeys(project.FileStats.FilesByType) {
 				fileTypes = append(fileTypes, map[string]interface{}{
 					"type":  ext,
-					"count": count,
+					"count": project.FileStats.FilesByType[ext],
 				})
 			}
 			stats["fileTypes"] = fileTypes
@@ -547,11 +1809,38 @@ func (t *TOONStrictFormatter) Format(project *ProjectOutput) (string, error) {
 			}
 
 			// Add to file metadata (tabular)
-			fileMetadata = append(fileMetadata, map[string]interface{}{
+			meta := map[string]interface{}{
 				"path":  file.Path,
 				"ext":   ext,
 				"lines": lineCount,
-			})
+			}
+			if n := file.RedactionCount(); n > 0 {
+				meta["redactions"] = n
+			}
+			if file.Compaction != "" {
+				meta["compaction"] = file.Compaction
+			}
+			if len(file.Annotations) > 0 {
+				notes := make([]string, len(file.Annotations))
+				for i, note := range file.Annotations {
+					notes[i] = escapeForTOON(note)
+				}
+				meta["notes"] = notes
+			}
+			if len(file.Owners) > 0 {
+				meta["owners"] = file.Owners
+			}
+			if file.License != "" {
+				meta["license"] = file.License
+			}
+			if file.External {
+				meta["external"] = true
+			}
+			if file.Sample != nil {
+				meta["sampled_rows"] = file.Sample.Rows
+				meta["total_rows"] = file.Sample.TotalRows
+			}
+			fileMetadata = append(fileMetadata, meta)
 
 			// Add to code content (tabular with escaped content)
 			codeContent = append(codeContent, map[string]interface{}{
@@ -584,116 +1873,3 @@ func escapeForTOON(s string) string {
 	s = strings.ReplaceAll(s, "\t", "\\t")
 	return s
 }
-
-// JSONLFormatter implements machine-friendly JSONL output (one JSON object per line)
-// This format is ideal for programmatic processing, streaming, and pipeline integration
-func (j *JSONLFormatter) Format(project *ProjectOutput) (string, error) {
-	var sb strings.Builder
-	encoder := NewTOONEncoder() // We'll use JSON encoding from TOON encoder
-
-	// Line 1: Metadata header
-	metadataLine := make(map[string]interface{})
-	metadataLine["type"] = "metadata"
-	if project.Metadata != nil {
-		metadataLine["language"] = project.Metadata.Language
-		if project.Metadata.Version != "" {
-			metadataLine["version"] = project.Metadata.Version
-		}
-		if len(project.Metadata.Dependencies) > 0 {
-			metadataLine["dependencies"] = project.Metadata.Dependencies
-		}
-		if project.FileStats != nil {
-			metadataLine["total_files"] = project.FileStats.TotalFiles
-			metadataLine["total_lines"] = project.FileStats.TotalLines
-		}
-	}
-	if metadataJSON, err := encoder.encodeToJSON(metadataLine); err == nil {
-		sb.WriteString(metadataJSON)
-		sb.WriteString("\n")
-	}
-
-	// Line 2: Git info
-	if project.GitInfo != nil {
-		gitLine := map[string]interface{}{
-			"type":   "git",
-			"branch": project.GitInfo.Branch,
-			"commit": project.GitInfo.CommitHash,
-		}
-		if project.GitInfo.CommitMessage != "" {
-			gitLine["message"] = project.GitInfo.CommitMessage
-		}
-		if gitJSON, err := encoder.encodeToJSON(gitLine); err == nil {
-			sb.WriteString(gitJSON)
-			sb.WriteString("\n")
-		}
-	}
-
-	// Line 3: Budget info (if present)
-	if project.Budget != nil {
-		budgetLine := map[string]interface{}{
-			"type":       "budget",
-			"max_tokens": project.Budget.MaxTokens,
-			"est_tokens": project.Budget.EstimatedTokens,
-		}
-		if project.Budget.FileTruncations > 0 {
-			budgetLine["file_truncations"] = project.Budget.FileTruncations
-		}
-		if budgetJSON, err := encoder.encodeToJSON(budgetLine); err == nil {
-			sb.WriteString(budgetJSON)
-			sb.WriteString("\n")
-		}
-	}
-
-	// Line 4: Filter config (if present)
-	if project.FilterConfig != nil {
-		filterLine := map[string]interface{}{
-			"type": "filters",
-		}
-		if len(project.FilterConfig.Includes) > 0 {
-			filterLine["includes"] = project.FilterConfig.Includes
-		}
-		if len(project.FilterConfig.Excludes) > 0 {
-			filterLine["excludes"] = project.FilterConfig.Excludes
-		}
-		if filterJSON, err := encoder.encodeToJSON(filterLine); err == nil {
-			sb.WriteString(filterJSON)
-			sb.WriteString("\n")
-		}
-	}
-
-	// Sort files by path for deterministic output
-	sortedFiles := make([]FileInfo, len(project.Files))
-	copy(sortedFiles, project.Files)
-	sort.Slice(sortedFiles, func(i, j int) bool {
-		return sortedFiles[i].Path < sortedFiles[j].Path
-	})
-
-	// Lines N: One line per file with metadata and content
-	for _, file := range sortedFiles {
-		lineCount := strings.Count(file.Content, "\n") + 1
-		fileLine := map[string]interface{}{
-			"type":    "file",
-			"path":    file.Path,
-			"lines":   lineCount,
-			"content": file.Content,
-		}
-
-		if file.Tokens > 0 {
-			fileLine["tokens"] = file.Tokens
-		}
-
-		if file.Truncation != nil {
-			fileLine["truncation"] = map[string]interface{}{
-				"mode":            file.Truncation.Mode,
-				"original_tokens": file.Truncation.OriginalTokens,
-			}
-		}
-
-		if fileJSON, err := encoder.encodeToJSON(fileLine); err == nil {
-			sb.WriteString(fileJSON)
-			sb.WriteString("\n")
-		}
-	}
-
-	return sb.String(), nil
-}
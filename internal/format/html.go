@@ -0,0 +1,458 @@
+package format
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// HTMLFormatter renders a self-contained HTML page for sharing context with
+// people who don't use the CLI: a collapsible file tree, highlighted code
+// blocks, and token stats. The page has no external assets or scripts.
+type HTMLFormatter struct{}
+
+// htmlStyle is inlined so the page renders the same when opened offline
+const htmlStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;margin:0 auto;max-width:1100px;padding:1.5rem;color:#1f2328;background:#fff}
+h1{font-size:1.5rem;margin:0 0 1rem}h2{font-size:1.15rem;margin:1.75rem 0 .5rem;border-bottom:1px solid #d0d7de;padding-bottom:.25rem}
+table{border-collapse:collapse}td,th{border:1px solid #d0d7de;padding:.25rem .6rem;text-align:left}th{background:#f6f8fa}
+.tree ul{list-style:none;margin:0;padding-left:1.1rem}.tree summary{cursor:pointer}.tree a{color:#0969da;text-decoration:none}
+.muted{color:#656d76}details.file{border:1px solid #d0d7de;border-radius:6px;margin:.75rem 0}
+details.file>summary{cursor:pointer;padding:.4rem .75rem;background:#f6f8fa;font-family:ui-monospace,SFMono-Regular,Menlo,monospace}
+.note{margin:0;padding:.4rem .75rem;background:#fff8c5;border-bottom:1px solid #d0d7de}
+pre{margin:0;padding:.75rem;overflow-x:auto;font:12px/1.45 ui-monospace,SFMono-Regular,Menlo,monospace}
+.kw{color:#cf222e}.str{color:#0a3069}.com{color:#6e7781;font-style:italic}.num{color:#0550ae}`
+
+// Format renders the project as a single HTML document
+func (h *HTMLFormatter) Format(project *ProjectOutput) (string, error) {
+	var b strings.Builder
+
+	title := "promptext"
+	if project.DirectoryTree != nil && project.DirectoryTree.Name != "" {
+		title = project.DirectoryTree.Name
+	}
+
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	b.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(title)))
+	b.WriteString("<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n")
+	b.WriteString(fmt.Sprintf("<h1>%s</h1>\n", html.EscapeString(title)))
+
+	h.formatSummary(&b, project)
+
+	// Anchors let tree entries jump to the file they name
+	anchors := make(map[string]string, len(project.Files))
+	for i, file := range project.Files {
+		anchors[file.Path] = fmt.Sprintf("file-%d", i+1)
+	}
+
+	if project.DirectoryTree != nil && len(project.DirectoryTree.Children) > 0 {
+		b.WriteString("<h2>Project Structure</h2>\n<nav class=\"tree\">\n<ul>\n")
+		for _, child := range project.DirectoryTree.Children {
+			writeHTMLTreeNode(&b, child, "", anchors)
+		}
+		b.WriteString("</ul>\n</nav>\n")
+	}
+
+	h.formatSplit(&b, project.Split)
+	h.formatSkippedFiles(&b, project.SkippedFiles)
+	h.formatIssues(&b, project.Issues, anchors)
+	h.formatSelection(&b, project.Selection, anchors)
+	h.formatRecentCommits(&b, project.RecentCommits)
+	h.formatFiles(&b, project.Files, anchors)
+
+	b.WriteString("</body>\n</html>\n")
+	return b.String(), nil
+}
+
+// formatSummary writes project metadata and token stats as a table
+func (h *HTMLFormatter) formatSummary(b *strings.Builder, project *ProjectOutput) {
+	var rows [][2]string
+	if project.Split != nil {
+		rows = append(rows, [2]string{"Part", fmt.Sprintf("%d of %d", project.Split.Part, project.Split.Total)})
+	}
+	if project.Metadata != nil {
+		if project.Metadata.Language != "" {
+			rows = append(rows, [2]string{"Language", project.Metadata.Language})
+		}
+		if project.Metadata.Version != "" {
+			rows = append(rows, [2]string{"Version", project.Metadata.Version})
+		}
+		if len(project.Metadata.Frameworks) > 0 {
+			rows = append(rows, [2]string{"Frameworks", strings.Join(frameworkNames(project.Metadata.Frameworks), ", ")})
+		}
+		for _, lang := range project.Metadata.Languages {
+			rows = append(rows, [2]string{"Language share", languageLine(lang)})
+		}
+		if project.Metadata.Owners != nil {
+			rows = append(rows, [2]string{"Owners", ownerCounts(project.Metadata.Owners)})
+		}
+		if licenses := project.Metadata.Licenses; licenses != nil {
+			rows = append(rows, [2]string{"Licenses", licenseCounts(licenses)})
+			for _, notice := range licenses.Copyrights {
+				rows = append(rows, [2]string{"Copyright", notice})
+			}
+			for _, flag := range licenses.Flagged {
+				rows = append(rows, [2]string{"License review", fmt.Sprintf("%s: %s (%s)", flag.Path, flag.Reason, flag.Detail)})
+			}
+		}
+		if audit := project.Metadata.Audit; audit != nil {
+			rows = append(rows, [2]string{"Vulnerabilities", auditCounts(audit)})
+			for _, vuln := range audit.Vulnerabilities {
+				rows = append(rows, [2]string{"Vulnerability", vulnerabilityLine(vuln)})
+			}
+		}
+	}
+	if project.GitInfo != nil && project.GitInfo.Branch != "" {
+		rows = append(rows, [2]string{"Branch", fmt.Sprintf("%s @ %s", project.GitInfo.Branch, project.GitInfo.CommitHash)})
+	}
+
+	tokens := 0
+	for _, file := range project.Files {
+		tokens += file.Tokens
+	}
+	rows = append(rows, [2]string{"Files", fmt.Sprintf("%d", len(project.Files))})
+	if project.FileStats != nil && project.FileStats.TotalLines > 0 {
+		rows = append(rows, [2]string{"Lines", fmt.Sprintf("%d", project.FileStats.TotalLines)})
+	}
+	if project.Budget != nil {
+		estimated := project.Budget.EstimatedTokens
+		if estimated == 0 {
+			estimated = tokens
+		}
+		rows = append(rows, [2]string{"Tokens", fmt.Sprintf("%d", estimated)})
+		if project.Budget.MaxTokens > 0 {
+			rows = append(rows, [2]string{"Token budget", fmt.Sprintf("%d", project.Budget.MaxTokens)})
+		}
+		if project.Budget.FileTruncations > 0 {
+			rows = append(rows, [2]string{"Truncated files", fmt.Sprintf("%d", project.Budget.FileTruncations)})
+		}
+	} else if tokens > 0 {
+		rows = append(rows, [2]string{"Tokens", fmt.Sprintf("%d", tokens)})
+	}
+	if project.Redactions != nil {
+		rows = append(rows, [2]string{"Redactions", fmt.Sprintf("%d secret(s) in %d file(s)", project.Redactions.Total, project.Redactions.Files)})
+	}
+
+	b.WriteString("<table class=\"summary\">\n")
+	for _, row := range rows {
+		b.WriteString(fmt.Sprintf("<tr><th>%s</th><td>%s</td></tr>\n", row[0], html.EscapeString(row[1])))
+	}
+	b.WriteString("</table>\n")
+}
+
+// writeHTMLTreeNode renders directories as <details> so they collapse without scripts
+func writeHTMLTreeNode(b *strings.Builder, node *DirectoryNode, parent string, anchors map[string]string) {
+	nodePath := path.Join(parent, node.Name)
+	name := html.EscapeString(node.Name)
+
+	if node.Type == "dir" {
+		b.WriteString(fmt.Sprintf("<li><details open><summary>%s/</summary>\n<ul>\n", name))
+		for _, child := range node.Children {
+			writeHTMLTreeNode(b, child, nodePath, anchors)
+		}
+		b.WriteString("</ul>\n</details></li>\n")
+		return
+	}
+
+	if anchor, ok := anchors[nodePath]; ok {
+		b.WriteString(fmt.Sprintf("<li><a href=\"#%s\">%s</a></li>\n", anchor, name))
+		return
+	}
+	b.WriteString(fmt.Sprintf("<li class=\"muted\">%s</li>\n", name))
+}
+
+func (h *HTMLFormatter) formatSplit(b *strings.Builder, info *SplitInfo) {
+	if info == nil || info.Total < 2 {
+		return
+	}
+	others := otherParts(info)
+	b.WriteString("<h2>Other Parts</h2>\n<ul>\n")
+	for _, part := range others {
+		files := part["files"].([]string)
+		escaped := make([]string, len(files))
+		for i, file := range files {
+			escaped[i] = html.EscapeString(file)
+		}
+		b.WriteString(fmt.Sprintf("<li>Part %d: %s</li>\n", part["part"], strings.Join(escaped, ", ")))
+	}
+	b.WriteString("</ul>\n")
+}
+
+func (h *HTMLFormatter) formatSkippedFiles(b *strings.Builder, files []SkippedFile) {
+	if len(files) == 0 {
+		return
+	}
+	b.WriteString("<h2>Skipped Files</h2>\n<ul class=\"muted\">\n")
+	for _, file := range files {
+		b.WriteString(fmt.Sprintf("<li>%s (%s, %s, %s)</li>\n",
+			html.EscapeString(file.Path), humanSize(file.Size), html.EscapeString(file.Kind), html.EscapeString(file.Reason)))
+	}
+	b.WriteString("</ul>\n")
+}
+
+func (h *HTMLFormatter) formatIssues(b *strings.Builder, issues []Issue, anchors map[string]string) {
+	if len(issues) == 0 {
+		return
+	}
+	b.WriteString("<h2>Issues</h2>\n<table class=\"selection\">\n<tr><th>File</th><th>Line</th><th>Kind</th><th>Text</th></tr>\n")
+	for _, issue := range issues {
+		name := html.EscapeString(issue.Path)
+		if anchor, ok := anchors[issue.Path]; ok {
+			name = fmt.Sprintf("<a href=\"#%s\">%s</a>", anchor, name)
+		}
+		b.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%s</td><td>%s</td></tr>\n",
+			name, issue.Line, html.EscapeString(issue.Kind), html.EscapeString(issue.Text)))
+	}
+	b.WriteString("</table>\n")
+}
+
+func (h *HTMLFormatter) formatSelection(b *strings.Builder, report *SelectionReport, anchors map[string]string) {
+	if report == nil {
+		return
+	}
+	b.WriteString(fmt.Sprintf("<h2>Selection Report</h2>\n<p class=\"muted\">Relevance threshold %g</p>\n", report.Threshold))
+	b.WriteString("<table class=\"selection\">\n<tr><th>File</th><th>Score</th><th>Factors</th><th>Tokens</th><th>Decision</th></tr>\n")
+	for _, entry := range report.Files {
+		name := html.EscapeString(entry.Path)
+		if anchor, ok := anchors[entry.Path]; ok {
+			name = fmt.Sprintf("<a href=\"#%s\">%s</a>", anchor, name)
+		}
+		if len(entry.Traits) > 0 {
+			name += fmt.Sprintf(" <span class=\"muted\">%s</span>", strings.Join(entry.Traits, ", "))
+		}
+		decision := "included"
+		if !entry.Included {
+			decision = "excluded: " + html.EscapeString(entry.Reason)
+		}
+		b.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%g</td><td>%s</td><td>%d</td><td>%s</td></tr>\n",
+			name, entry.Score, selectionFactors(entry), entry.Tokens, decision))
+	}
+	b.WriteString("</table>\n")
+}
+
+func (h *HTMLFormatter) formatRecentCommits(b *strings.Builder, commits []CommitInfo) {
+	if len(commits) == 0 {
+		return
+	}
+	b.WriteString("<h2>Recent Commits</h2>\n<ul>\n")
+	for _, commit := range commits {
+		b.WriteString(fmt.Sprintf("<li><code>%s</code> <span class=\"muted\">%s</span> %s</li>\n",
+			html.EscapeString(commit.Hash), html.EscapeString(commit.Date), html.EscapeString(commit.Subject)))
+	}
+	b.WriteString("</ul>\n")
+}
+
+func (h *HTMLFormatter) formatFiles(b *strings.Builder, files []FileInfo, anchors map[string]string) {
+	if len(files) == 0 {
+		return
+	}
+	b.WriteString("<h2>Source Files</h2>\n")
+	for _, file := range files {
+		lang := languageForPath(file.Path)
+		meta := fmt.Sprintf("%d lines", strings.Count(file.Content, "\n")+1)
+		if file.Tokens > 0 {
+			meta += fmt.Sprintf(" · %d tokens", file.Tokens)
+		}
+		if file.Truncation != nil {
+			meta += fmt.Sprintf(" · truncated from %d tokens", file.Truncation.OriginalTokens)
+		}
+		if file.Compaction != "" {
+			meta += fmt.Sprintf(" · compacted (%s)", file.Compaction)
+		}
+		if file.External {
+			meta += " · external"
+		}
+		if file.Sample != nil {
+			meta += fmt.Sprintf(" · sampled %d of %d rows", file.Sample.Rows, file.Sample.TotalRows)
+		}
+		if len(file.Owners) > 0 {
+			meta += " · " + html.EscapeString(strings.Join(file.Owners, " "))
+		}
+		if file.License != "" {
+			meta += " · " + html.EscapeString(file.License)
+		}
+
+		b.WriteString(fmt.Sprintf("<details class=\"file\" id=\"%s\" open>\n<summary>%s <span class=\"muted\">%s</span></summary>\n",
+			anchors[file.Path], html.EscapeString(file.Path), meta))
+		for _, note := range file.Annotations {
+			b.WriteString(fmt.Sprintf("<p class=\"note\">%s</p>\n", html.EscapeString(note)))
+		}
+		b.WriteString(fmt.Sprintf("<pre><code class=\"language-%s\">", lang))
+		b.WriteString(highlight(file.Content, lang))
+		b.WriteString("</code></pre>\n</details>\n")
+	}
+}
+
+// syntax describes just enough of a language to color comments, strings,
+// and keywords; anything subtler is left as plain text
+type syntax struct {
+	lineComment  []string
+	blockComment [2]string
+	quotes       string
+	keywords     map[string]bool
+}
+
+func keywordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var syntaxes = map[string]syntax{
+	"go": {[]string{"//"}, [2]string{"/*", "*/"}, "\"'`", keywordSet(
+		"break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false")},
+	"javascript": {[]string{"//"}, [2]string{"/*", "*/"}, "\"'`", keywordSet(
+		"async await break case catch class const continue default delete do else export extends false finally for from function if import in instanceof interface let new null return static super switch this throw true try type typeof undefined var void while yield")},
+	"python": {[]string{"#"}, [2]string{}, "\"'", keywordSet(
+		"and as assert async await break class continue def del elif else except False finally for from global if import in is lambda None nonlocal not or pass raise return True try while with yield")},
+	"rust": {[]string{"//"}, [2]string{"/*", "*/"}, "\"", keywordSet(
+		"as async await break const continue crate else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while")},
+	"java": {[]string{"//"}, [2]string{"/*", "*/"}, "\"'", keywordSet(
+		"abstract break case catch class const continue default do else enum extends final finally for if implements import instanceof interface namespace new null override package private protected public return static struct super switch this throw true false try using var void while")},
+	"ruby": {[]string{"#"}, [2]string{}, "\"'", keywordSet(
+		"begin break case class def do else elsif end ensure false for if in module next nil not return self super then true unless until when while yield")},
+	"shell": {[]string{"#"}, [2]string{}, "\"'", keywordSet(
+		"case do done elif else esac export fi for function if in local return then until while")},
+	"sql": {[]string{"--"}, [2]string{"/*", "*/"}, "'", keywordSet(
+		"select from where insert into update delete create table alter drop join left right inner outer on group by order having limit values and or not null as primary key index SELECT FROM WHERE INSERT INTO UPDATE DELETE CREATE TABLE ALTER DROP JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT VALUES AND OR NOT NULL AS PRIMARY KEY INDEX")},
+	"yaml": {[]string{"#"}, [2]string{}, "\"'", keywordSet("true false null yes no")},
+	"css":  {nil, [2]string{"/*", "*/"}, "\"'", nil},
+}
+
+// languageExtensions maps file extensions to the language used for highlighting
+var languageExtensions = map[string]string{
+	".go": "go", ".js": "javascript", ".jsx": "javascript", ".mjs": "javascript", ".cjs": "javascript",
+	".ts": "javascript", ".tsx": "javascript", ".py": "python", ".rs": "rust",
+	".java": "java", ".kt": "java", ".scala": "java", ".c": "java", ".h": "java", ".cpp": "java",
+	".hpp": "java", ".cs": "java", ".swift": "java", ".php": "java",
+	".rb": "ruby", ".sh": "shell", ".bash": "shell", ".zsh": "shell",
+	".sql": "sql", ".yml": "yaml", ".yaml": "yaml", ".toml": "yaml",
+	".css": "css", ".scss": "css",
+}
+
+// languageForPath returns the highlighting language for a file, or "text"
+func languageForPath(p string) string {
+	if lang, ok := languageExtensions[strings.ToLower(filepath.Ext(p))]; ok {
+		return lang
+	}
+	switch filepath.Base(p) {
+	case "Makefile", "Dockerfile", ".gitignore":
+		return "shell"
+	}
+	return "text"
+}
+
+// highlight escapes content and wraps comments, strings, numbers, and
+// keywords in spans for the embedded stylesheet
+func highlight(content, lang string) string {
+	syn, ok := syntaxes[lang]
+	if !ok {
+		return html.EscapeString(content)
+	}
+
+	var b strings.Builder
+	span := func(class, text string) {
+		b.WriteString("<span class=\"" + class + "\">" + html.EscapeString(text) + "</span>")
+	}
+
+	for i := 0; i < len(content); {
+		rest := content[i:]
+
+		if open := syn.blockComment[0]; open != "" && strings.HasPrefix(rest, open) {
+			end := strings.Index(rest[len(open):], syn.blockComment[1])
+			n := len(rest)
+			if end >= 0 {
+				n = len(open) + end + len(syn.blockComment[1])
+			}
+			span("com", rest[:n])
+			i += n
+			continue
+		}
+
+		if prefix := lineCommentPrefix(rest, syn.lineComment); prefix != "" {
+			n := strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			span("com", rest[:n])
+			i += n
+			continue
+		}
+
+		c := rest[0]
+		if strings.IndexByte(syn.quotes, c) >= 0 {
+			n := stringLiteralLength(rest)
+			span("str", rest[:n])
+			i += n
+			continue
+		}
+
+		if isWordStart(c) {
+			n := 1
+			for n < len(rest) && isWordPart(rest[n]) {
+				n++
+			}
+			word := rest[:n]
+			if syn.keywords[word] {
+				span("kw", word)
+			} else {
+				b.WriteString(html.EscapeString(word))
+			}
+			i += n
+			continue
+		}
+
+		if c >= '0' && c <= '9' {
+			n := 1
+			for n < len(rest) && (isWordPart(rest[n]) || rest[n] == '.') {
+				n++
+			}
+			span("num", rest[:n])
+			i += n
+			continue
+		}
+
+		b.WriteString(html.EscapeString(rest[:1]))
+		i++
+	}
+	return b.String()
+}
+
+func lineCommentPrefix(s string, prefixes []string) string {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return p
+		}
+	}
+	return ""
+}
+
+// stringLiteralLength returns the byte length of the quoted literal at the
+// start of s. Backtick strings may span lines; other quotes stop at a newline
+// so an unbalanced quote can't swallow the rest of the file.
+func stringLiteralLength(s string) int {
+	quote := s[0]
+	for n := 1; n < len(s); n++ {
+		switch {
+		case s[n] == '\\' && quote != '`':
+			n++
+		case s[n] == quote:
+			return n + 1
+		case s[n] == '\n' && quote != '`':
+			return n
+		}
+	}
+	return len(s)
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || c >= 0x80 || unicode.IsLetter(rune(c))
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || (c >= '0' && c <= '9')
+}
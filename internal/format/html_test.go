@@ -0,0 +1,92 @@
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTMLFormatter_Format(t *testing.T) {
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{
+			Name: "demo",
+			Type: "dir",
+			Children: []*DirectoryNode{
+				{Name: "cmd", Type: "dir", Children: []*DirectoryNode{{Name: "main.go", Type: "file"}}},
+				{Name: "README.md", Type: "file"},
+			},
+		},
+		Metadata: &Metadata{Language: "Go"},
+		Files: []FileInfo{
+			{Path: "cmd/main.go", Content: "package main\n\n// greet <user>\nfunc main() { println(\"a<b\", 42) }", Tokens: 18},
+		},
+		Budget:       &BudgetInfo{MaxTokens: 1000, EstimatedTokens: 18},
+		SkippedFiles: []SkippedFile{{Path: "logo.png", Size: 2048, Kind: "image", Reason: "binary"}},
+	}
+
+	out, err := (&HTMLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>demo</title>",
+		"<tr><th>Tokens</th><td>18</td></tr>",
+		"<tr><th>Token budget</th><td>1000</td></tr>",
+		"<summary>cmd/</summary>",
+		`<a href="#file-1">main.go</a>`,
+		`<li class="muted">README.md</li>`,
+		`id="file-1"`,
+		`<code class="language-go">`,
+		`<span class="kw">package</span>`,
+		`<span class="com">// greet &lt;user&gt;</span>`,
+		`<span class="str">&#34;a&lt;b&#34;</span>`,
+		`<span class="num">42</span>`,
+		"logo.png (2.0 KB, image, binary)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if strings.Contains(out, "<user>") || strings.Contains(out, "<script") {
+		t.Error("output contains unescaped content or scripts")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		lang    string
+		want    string
+	}{
+		{"plain text is escaped", "a < b & c", "text", "a &lt; b &amp; c"},
+		{"python comment", "x = 1  # note", "python", `x = <span class="num">1</span>  <span class="com"># note</span>`},
+		{"block comment", "/* a */b", "go", `<span class="com">/* a */</span>b`},
+		{"unterminated string stops at newline", "s := \"abc\nreturn", "go", "s := <span class=\"str\">&#34;abc</span>\n<span class=\"kw\">return</span>"},
+		{"identifier containing keyword", "format", "go", "format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlight(tt.content, tt.lang); got != tt.want {
+				t.Errorf("highlight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLanguageForPath(t *testing.T) {
+	tests := map[string]string{
+		"main.go":     "go",
+		"app.TSX":     "javascript",
+		"Makefile":    "shell",
+		"notes.txt":   "text",
+		"config.yaml": "yaml",
+	}
+	for p, want := range tests {
+		if got := languageForPath(p); got != want {
+			t.Errorf("languageForPath(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package format
+
+// Info describes a built-in output format
+type Info struct {
+	Name        string
+	Description string
+	Aliases     []string // Other names GetFormatter accepts
+	Extensions  []string // Output file extensions that select the format, preferred first
+	MimeType    string
+}
+
+// Builtins describes the built-in formats, recommended first
+var Builtins = []Info{
+	{Name: "ptx", Description: "PTX v2.0, TOON-based with multiline code and a manifest (default)",
+		Aliases: []string{"toon"}, Extensions: []string{".ptx", ".toon"}, MimeType: "text/plain"},
+	{Name: "toon-strict", Description: "TOON v1.3 strict compliance with escaped strings",
+		Aliases: []string{"toon-v1.3"}, MimeType: "text/plain"},
+	{Name: "jsonl", Description: "One JSON object per line, for machine pipelines",
+		Extensions: []string{".jsonl"}, MimeType: "application/x-ndjson"},
+	{Name: "json", Description: "Single JSON document with a published schema",
+		Extensions: []string{".json"}, MimeType: "application/json"},
+	{Name: "markdown", Description: "Human-readable Markdown",
+		Aliases: []string{"md"}, Extensions: []string{".md", ".markdown"}, MimeType: "text/markdown"},
+	{Name: "xml", Description: "Machine-parseable XML",
+		Extensions: []string{".xml"}, MimeType: "application/xml"},
+	{Name: "html", Description: "Self-contained HTML page with a file tree, for human review",
+		Aliases: []string{"htm"}, Extensions: []string{".html", ".htm"}, MimeType: "text/html"},
+	{Name: "pdf", Description: "Paginated PDF of the Markdown output, for archival",
+		Extensions: []string{".pdf"}, MimeType: "application/pdf"},
+}
+
+// Names returns the names of the built-in formats, without aliases
+func Names() []string {
+	names := make([]string, len(Builtins))
+	for i, info := range Builtins {
+		names[i] = info.Name
+	}
+	return names
+}
@@ -0,0 +1,191 @@
+package format
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HashAlgorithm names the hash FileInfo.Hash and IntegrityInfo use
+const HashAlgorithm = "sha256"
+
+// FileHash returns the hex-encoded SHA-256 of content
+func FileHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// ContentHash returns the hash over an output's files, given their hashes by
+// path: the SHA-256 of one "HASH  PATH" line per file in path order, as
+// sha256sum prints them. It changes when any file is changed, added or
+// dropped, and doesn't depend on the order the output lists files in.
+func ContentHash(hashes map[string]string) string {
+	var sb strings.Builder
+	for _, path := range sortedKeys(hashes) {
+		fmt.Fprintf(&sb, "%s  %s\n", hashes[path], path)
+	}
+	return FileHash([]byte(sb.String()))
+}
+
+// Integrity returns the IntegrityInfo of files
+func Integrity(files []FileInfo) *IntegrityInfo {
+	hashes := make(map[string]string, len(files))
+	for _, file := range files {
+		hashes[file.Path] = file.Hash
+	}
+	return &IntegrityInfo{Algorithm: HashAlgorithm, Content: ContentHash(hashes)}
+}
+
+// ErrNoIntegrity is returned by ReadIntegrity for output without hashes
+var ErrNoIntegrity = errors.New("no integrity manifest")
+
+// IntegrityManifest is the integrity data read back from a rendered output
+type IntegrityManifest struct {
+	Format  string            // "ptx", "jsonl", "json" or "xml"
+	Content string            // Recorded content hash
+	Files   map[string]string // Recorded file hashes by path
+}
+
+// ReadIntegrity reads the integrity manifest of PTX, JSONL, JSON or XML output
+// rendered with hashes on. It returns ErrNoIntegrity for output in another
+// format or rendered without hashes.
+func ReadIntegrity(data []byte) (*IntegrityManifest, error) {
+	text := strings.TrimSpace(string(data))
+	var manifest *IntegrityManifest
+	var err error
+	switch {
+	case strings.HasPrefix(text, "{") && json.Valid([]byte(text)):
+		manifest, err = readJSONIntegrity([]byte(text))
+	case strings.HasPrefix(text, "{"):
+		manifest, err = readJSONLIntegrity(text)
+	case strings.HasPrefix(text, "<?xml") || strings.HasPrefix(text, "<project"):
+		manifest, err = readXMLIntegrity([]byte(text))
+	default:
+		manifest, err = readPTXIntegrity(text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil || manifest.Content == "" {
+		return nil, ErrNoIntegrity
+	}
+	return manifest, nil
+}
+
+func readJSONIntegrity(data []byte) (*IntegrityManifest, error) {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Integrity == nil {
+		return nil, nil
+	}
+	manifest := &IntegrityManifest{Format: "json", Content: doc.Integrity.Content, Files: make(map[string]string)}
+	for _, file := range doc.Files {
+		manifest.Files[file.Path] = file.SHA256
+	}
+	return manifest, nil
+}
+
+func readJSONLIntegrity(text string) (*IntegrityManifest, error) {
+	manifest := &IntegrityManifest{Format: "jsonl", Files: make(map[string]string)}
+	for i, line := range strings.Split(text, "\n") {
+		var record struct {
+			Type    string `json:"type"`
+			Content string `json:"content"`
+			Path    string `json:"path"`
+			SHA256  string `json:"sha256"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch record.Type {
+		case "integrity":
+			manifest.Content = record.Content
+		case "file":
+			manifest.Files[record.Path] = record.SHA256
+		}
+	}
+	return manifest, nil
+}
+
+func readXMLIntegrity(data []byte) (*IntegrityManifest, error) {
+	var doc struct {
+		Integrity *IntegrityInfo `xml:"integrity"`
+		Files     []struct {
+			Path   string `xml:"path,attr"`
+			SHA256 string `xml:"sha256,attr"`
+		} `xml:"files>file"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Integrity == nil {
+		return nil, nil
+	}
+	manifest := &IntegrityManifest{Format: "xml", Content: doc.Integrity.Content, Files: make(map[string]string)}
+	for _, file := range doc.Files {
+		manifest.Files[file.Path] = file.SHA256
+	}
+	return manifest, nil
+}
+
+// readPTXIntegrity reads the integrity section of the promptext manifest.
+// Only unindented lines start a section, as every line of file content in
+// the code section is indented.
+func readPTXIntegrity(text string) (*IntegrityManifest, error) {
+	lines := strings.Split(text, "\n")
+	start := -1
+	for i := 0; i+1 < len(lines) && start < 0; i++ {
+		if lines[i] == "promptext:" {
+			for j := i + 1; j < len(lines) && strings.HasPrefix(lines[j], " "); j++ {
+				if lines[j] == "  integrity:" {
+					start = j + 1
+					break
+				}
+			}
+		}
+	}
+	if start < 0 {
+		return nil, nil
+	}
+
+	manifest := &IntegrityManifest{Format: "ptx", Files: make(map[string]string)}
+	for i := start; i < len(lines) && strings.HasPrefix(lines[i], "    "); i++ {
+		line := strings.TrimSpace(lines[i])
+		if content, ok := strings.CutPrefix(line, "content: "); ok {
+			manifest.Content = content
+			continue
+		}
+		if !strings.HasPrefix(line, "files[") {
+			continue
+		}
+		var n int
+		if _, err := fmt.Sscanf(line, "files[%d]", &n); err != nil {
+			return nil, fmt.Errorf("integrity files: %w", err)
+		}
+		for ; n > 0 && i+1 < len(lines); n-- {
+			i++
+			row := strings.TrimSpace(lines[i])
+			sep := strings.LastIndex(row, ",")
+			if sep < 0 {
+				return nil, fmt.Errorf("integrity files: malformed row %q", row)
+			}
+			path := row[:sep]
+			if strings.HasPrefix(path, `"`) {
+				unquoted, err := strconv.Unquote(path)
+				if err != nil {
+					return nil, fmt.Errorf("integrity files: malformed path %s", path)
+				}
+				path = unquoted
+			}
+			manifest.Files[path] = row[sep+1:]
+		}
+	}
+	return manifest, nil
+}
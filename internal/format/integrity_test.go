@@ -0,0 +1,64 @@
+package format
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestContentHashIgnoresOrder(t *testing.T) {
+	a := ContentHash(map[string]string{"a.go": FileHash([]byte("a")), "b.go": FileHash([]byte("b"))})
+	b := ContentHash(map[string]string{"b.go": FileHash([]byte("b")), "a.go": FileHash([]byte("a"))})
+	if a != b {
+		t.Fatalf("content hash depends on order: %s != %s", a, b)
+	}
+	if c := ContentHash(map[string]string{"a.go": FileHash([]byte("a"))}); c == a {
+		t.Fatalf("content hash unchanged after dropping a file")
+	}
+}
+
+func TestReadIntegrityRoundTrip(t *testing.T) {
+	project := &ProjectOutput{
+		Files: []FileInfo{
+			{Path: "main.go", Content: "package main\n", Hash: FileHash([]byte("package main\n"))},
+			{Path: "docs/a, b.md", Content: "integrity:\n", Hash: FileHash([]byte("integrity:\n"))},
+		},
+	}
+	project.Integrity = Integrity(project.Files)
+
+	for name, formatter := range map[string]Formatter{"ptx": &PTXFormatter{}, "jsonl": &JSONLFormatter{}, "json": &JSONFormatter{}, "xml": &XMLFormatter{}} {
+		t.Run(name, func(t *testing.T) {
+			out, err := formatter.Format(project)
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+			manifest, err := ReadIntegrity([]byte(out))
+			if err != nil {
+				t.Fatalf("ReadIntegrity failed: %v\n%s", err, out)
+			}
+			if manifest.Format != name || manifest.Content != project.Integrity.Content {
+				t.Fatalf("unexpected manifest %s %q, want content %q", manifest.Format, manifest.Content, project.Integrity.Content)
+			}
+			for _, file := range project.Files {
+				if manifest.Files[file.Path] != file.Hash {
+					t.Errorf("hash of %s = %q, want %q", file.Path, manifest.Files[file.Path], file.Hash)
+				}
+			}
+			if len(manifest.Files) != len(project.Files) {
+				t.Errorf("read %d files, want %d", len(manifest.Files), len(project.Files))
+			}
+		})
+	}
+}
+
+func TestReadIntegrityWithoutHashes(t *testing.T) {
+	project := &ProjectOutput{Files: []FileInfo{{Path: "main.go", Content: "package main\n", Hash: FileHash([]byte("package main\n"))}}}
+	for name, formatter := range map[string]Formatter{"ptx": &PTXFormatter{}, "jsonl": &JSONLFormatter{}, "json": &JSONFormatter{}, "xml": &XMLFormatter{}, "markdown": &MarkdownFormatter{}} {
+		out, err := formatter.Format(project)
+		if err != nil {
+			t.Fatalf("%s: Format failed: %v", name, err)
+		}
+		if _, err := ReadIntegrity([]byte(out)); !errors.Is(err, ErrNoIntegrity) {
+			t.Errorf("%s: expected ErrNoIntegrity, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package format
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JSONSchemaVersion identifies the shape of JSONFormatter documents; it is
+// bumped whenever a field is removed or changes meaning
+const JSONSchemaVersion = "2"
+
+//go:embed json.schema.json
+var jsonSchema string
+
+//go:embed xml.xsd
+var xmlSchema string
+
+// JSONFormatter renders the project as a single JSON document described by
+// the schema returned from Schema("json")
+type JSONFormatter struct{}
+
+type jsonDocument struct {
+	SchemaVersion string          `json:"schema_version"`
+	Metadata      jsonMetadata    `json:"metadata"`
+	Git           *jsonGit        `json:"git,omitempty"`
+	Stats         jsonStats       `json:"stats"`
+	Files         []jsonFile      `json:"files"`
+	Skipped       []jsonSkipped   `json:"skipped,omitempty"`
+	Issues        []jsonIssue     `json:"issues,omitempty"`
+	Metrics       *jsonMetrics    `json:"metrics,omitempty"`
+	Workspace     *jsonWorkspace  `json:"workspace,omitempty"`
+	Redactions    *jsonRedactions `json:"redactions,omitempty"`
+	Split         *jsonSplit      `json:"split,omitempty"`
+	Selection     *jsonSelection  `json:"selection,omitempty"`
+	Integrity     *jsonIntegrity  `json:"integrity,omitempty"`
+}
+
+type jsonIntegrity struct {
+	Algorithm string `json:"algorithm"`
+	Content   string `json:"content"`
+}
+
+type jsonSelection struct {
+	Threshold float64              `json:"threshold"`
+	Files     []jsonSelectionEntry `json:"files"`
+}
+
+type jsonSelectionEntry struct {
+	Path     string          `json:"path"`
+	Tokens   int             `json:"tokens"`
+	Score    float64         `json:"score"`
+	Factors  jsonScoreFactor `json:"factors"`
+	Traits   []string        `json:"traits,omitempty"`
+	Included bool            `json:"included"`
+	Reason   string          `json:"reason,omitempty"`
+}
+
+type jsonScoreFactor struct {
+	Filename  float64 `json:"filename"`
+	Directory float64 `json:"directory"`
+	Imports   float64 `json:"imports"`
+	Content   float64 `json:"content"`
+}
+
+type jsonSplit struct {
+	Part       int             `json:"part"`
+	Total      int             `json:"total"`
+	OtherParts []jsonSplitPart `json:"other_parts"`
+}
+
+type jsonSplitPart struct {
+	Part  int      `json:"part"`
+	Files []string `json:"files"`
+}
+
+type jsonMetadata struct {
+	Language     string           `json:"language"`
+	Version      string           `json:"version,omitempty"`
+	Dependencies []jsonDependency `json:"dependencies,omitempty"`
+	Frameworks   []jsonFramework  `json:"frameworks,omitempty"`
+	Languages    []jsonLanguage   `json:"languages,omitempty"`
+	Owners       *jsonOwners      `json:"owners,omitempty"`
+	Licenses     *jsonLicenses    `json:"licenses,omitempty"`
+	Audit        *jsonAudit       `json:"audit,omitempty"`
+}
+
+type jsonLanguage struct {
+	Name         string  `json:"name"`
+	Files        int     `json:"files"`
+	Lines        int     `json:"lines"`
+	Tokens       int     `json:"tokens"`
+	LinePercent  float64 `json:"lines_pct"`
+	TokenPercent float64 `json:"tokens_pct"`
+}
+
+type jsonAudit struct {
+	Source          string              `json:"source"`
+	Checked         int                 `json:"checked"`
+	Vulnerabilities []jsonVulnerability `json:"vulnerabilities"`
+}
+
+type jsonVulnerability struct {
+	ID       string   `json:"id"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Package  string   `json:"package"`
+	Version  string   `json:"version"`
+	Severity string   `json:"severity,omitempty"`
+	Summary  string   `json:"summary"`
+	Fixed    string   `json:"fixed,omitempty"`
+}
+
+type jsonLicenses struct {
+	Licenses   []jsonLicense     `json:"licenses"`
+	Flagged    []jsonLicenseFlag `json:"flagged,omitempty"`
+	Copyrights []string          `json:"copyrights,omitempty"`
+}
+
+type jsonLicense struct {
+	ID      string   `json:"id"`
+	Files   int      `json:"files"`
+	Sources []string `json:"sources,omitempty"`
+}
+
+type jsonLicenseFlag struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+}
+
+type jsonOwners struct {
+	Source  string      `json:"source"`
+	Owners  []jsonOwner `json:"owners"`
+	Unowned int         `json:"unowned"`
+}
+
+type jsonOwner struct {
+	Name   string `json:"name"`
+	Files  int    `json:"files"`
+	Tokens int    `json:"tokens"`
+}
+
+type jsonDependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Scope   string `json:"scope"`
+}
+
+type jsonFramework struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Priority    int    `json:"priority"`
+}
+
+type jsonGit struct {
+	Branch  string `json:"branch"`
+	Commit  string `json:"commit"`
+	Message string `json:"message,omitempty"`
+}
+
+type jsonStats struct {
+	Files           int `json:"files"`
+	Lines           int `json:"lines"`
+	Tokens          int `json:"tokens"`
+	MaxTokens       int `json:"max_tokens"`
+	FileTruncations int `json:"file_truncations"`
+}
+
+type jsonFile struct {
+	Path       string          `json:"path"`
+	Lines      int             `json:"lines"`
+	Tokens     int             `json:"tokens"`
+	Content    string          `json:"content"`
+	Truncation *jsonTruncation `json:"truncation,omitempty"`
+	Tests      []string        `json:"tests,omitempty"`
+	Redactions int             `json:"redactions,omitempty"`
+	Compaction string          `json:"compaction,omitempty"`
+	Notes      []string        `json:"notes,omitempty"`
+	Owners     []string        `json:"owners,omitempty"`
+	License    string          `json:"license,omitempty"`
+	External   bool            `json:"external,omitempty"`
+	Sampled    *jsonSample     `json:"sampled,omitempty"`
+	SHA256     string          `json:"sha256,omitempty"`
+}
+
+type jsonTruncation struct {
+	Mode           string `json:"mode"`
+	OriginalTokens int    `json:"original_tokens"`
+}
+
+type jsonSample struct {
+	Rows      int `json:"rows"`
+	TotalRows int `json:"total_rows"`
+	Columns   int `json:"columns"`
+}
+
+type jsonSkipped struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+}
+
+type jsonIssue struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+type jsonMetrics struct {
+	Functions int               `json:"functions"`
+	Files     []jsonFileMetrics `json:"files"`
+}
+
+type jsonFileMetrics struct {
+	Path          string `json:"path"`
+	Lines         int    `json:"lines"`
+	Functions     int    `json:"functions"`
+	Complexity    int    `json:"complexity"`
+	MaxComplexity int    `json:"max_complexity"`
+	MaxFunction   string `json:"max_function,omitempty"`
+}
+
+type jsonWorkspace struct {
+	Manifests []string               `json:"manifests"`
+	Packages  []jsonWorkspacePackage `json:"packages"`
+}
+
+type jsonWorkspacePackage struct {
+	Name      string   `json:"name"`
+	Path      string   `json:"path"`
+	Ecosystem string   `json:"ecosystem"`
+	DependsOn []string `json:"depends_on"`
+	Files     int      `json:"files"`
+	Tokens    int      `json:"tokens"`
+}
+
+type jsonRedactions struct {
+	Total int            `json:"total"`
+	Files int            `json:"files"`
+	Rules map[string]int `json:"rules"`
+}
+
+// Format renders the project as an indented JSON document with files sorted by path
+func (j *JSONFormatter) Format(project *ProjectOutput) (string, error) {
+	doc := jsonDocument{
+		SchemaVersion: JSONSchemaVersion,
+		Files:         make([]jsonFile, 0, len(project.Files)),
+	}
+
+	if project.Metadata != nil {
+		doc.Metadata = jsonMetadata{
+			Language: project.Metadata.Language,
+			Version:  project.Metadata.Version,
+		}
+		for _, dep := range project.Metadata.Dependencies {
+			doc.Metadata.Dependencies = append(doc.Metadata.Dependencies, jsonDependency(dep))
+		}
+		for _, framework := range project.Metadata.Frameworks {
+			doc.Metadata.Frameworks = append(doc.Metadata.Frameworks, jsonFramework(framework))
+		}
+		for _, lang := range project.Metadata.Languages {
+			doc.Metadata.Languages = append(doc.Metadata.Languages, jsonLanguage(lang))
+		}
+		if info := project.Metadata.Owners; info != nil {
+			doc.Metadata.Owners = &jsonOwners{Source: info.Source, Owners: []jsonOwner{}, Unowned: info.Unowned}
+			for _, owner := range info.Owners {
+				doc.Metadata.Owners.Owners = append(doc.Metadata.Owners.Owners, jsonOwner{Name: owner.Owner, Files: owner.Files, Tokens: owner.Tokens})
+			}
+		}
+		if info := project.Metadata.Licenses; info != nil {
+			doc.Metadata.Licenses = &jsonLicenses{Licenses: []jsonLicense{}, Copyrights: info.Copyrights}
+			for _, license := range info.Licenses {
+				doc.Metadata.Licenses.Licenses = append(doc.Metadata.Licenses.Licenses, jsonLicense(license))
+			}
+			for _, flag := range info.Flagged {
+				doc.Metadata.Licenses.Flagged = append(doc.Metadata.Licenses.Flagged, jsonLicenseFlag(flag))
+			}
+		}
+		if info := project.Metadata.Audit; info != nil {
+			doc.Metadata.Audit = &jsonAudit{Source: info.Source, Checked: info.Checked, Vulnerabilities: []jsonVulnerability{}}
+			for _, vuln := range info.Vulnerabilities {
+				doc.Metadata.Audit.Vulnerabilities = append(doc.Metadata.Audit.Vulnerabilities, jsonVulnerability(vuln))
+			}
+		}
+	}
+	if project.GitInfo != nil {
+		doc.Git = &jsonGit{
+			Branch:  project.GitInfo.Branch,
+			Commit:  project.GitInfo.CommitHash,
+			Message: project.GitInfo.CommitMessage,
+		}
+	}
+
+	sortedFiles := make([]FileInfo, len(project.Files))
+	copy(sortedFiles, project.Files)
+	sort.Slice(sortedFiles, func(a, b int) bool {
+		return sortedFiles[a].Path < sortedFiles[b].Path
+	})
+
+	for _, file := range sortedFiles {
+		entry := jsonFile{
+			Path:       file.Path,
+			Lines:      strings.Count(file.Content, "\n") + 1,
+			Tokens:     file.Tokens,
+			Content:    file.Content,
+			Tests:      file.AssociatedTests,
+			Redactions: file.RedactionCount(),
+			Compaction: file.Compaction,
+			Notes:      file.Annotations,
+			Owners:     file.Owners,
+			License:    file.License,
+			External:   file.External,
+		}
+		if file.Truncation != nil {
+			entry.Truncation = &jsonTruncation{
+				Mode:           file.Truncation.Mode,
+				OriginalTokens: file.Truncation.OriginalTokens,
+			}
+		}
+		if file.Sample != nil {
+			entry.Sampled = &jsonSample{
+				Rows:      file.Sample.Rows,
+				TotalRows: file.Sample.TotalRows,
+				Columns:   file.Sample.Columns,
+			}
+		}
+		if project.Integrity != nil {
+			entry.SHA256 = file.Hash
+		}
+		doc.Files = append(doc.Files, entry)
+		doc.Stats.Lines += entry.Lines
+		doc.Stats.Tokens += entry.Tokens
+	}
+	doc.Stats.Files = len(doc.Files)
+
+	if project.FileStats != nil && project.FileStats.TotalLines > 0 {
+		doc.Stats.Lines = project.FileStats.TotalLines
+	}
+	if project.Budget != nil {
+		doc.Stats.MaxTokens = project.Budget.MaxTokens
+		doc.Stats.FileTruncations = project.Budget.FileTruncations
+		if project.Budget.EstimatedTokens > 0 {
+			doc.Stats.Tokens = project.Budget.EstimatedTokens
+		}
+	}
+
+	for _, file := range project.SkippedFiles {
+		doc.Skipped = append(doc.Skipped, jsonSkipped(file))
+	}
+	for _, issue := range project.Issues {
+		doc.Issues = append(doc.Issues, jsonIssue(issue))
+	}
+	if project.Metrics != nil {
+		doc.Metrics = &jsonMetrics{Functions: project.Metrics.Functions, Files: []jsonFileMetrics{}}
+		for _, file := range project.Metrics.Files {
+			doc.Metrics.Files = append(doc.Metrics.Files, jsonFileMetrics(file))
+		}
+	}
+	if project.Workspace != nil {
+		doc.Workspace = &jsonWorkspace{Manifests: project.Workspace.Manifests, Packages: []jsonWorkspacePackage{}}
+		for _, pkg := range project.Workspace.Packages {
+			if pkg.DependsOn == nil {
+				pkg.DependsOn = []string{}
+			}
+			doc.Workspace.Packages = append(doc.Workspace.Packages, jsonWorkspacePackage(pkg))
+		}
+	}
+	if project.Redactions != nil {
+		doc.Redactions = &jsonRedactions{
+			Total: project.Redactions.Total,
+			Files: project.Redactions.Files,
+			Rules: project.Redactions.Rules,
+		}
+	}
+
+	if project.Split != nil {
+		doc.Split = &jsonSplit{Part: project.Split.Part, Total: project.Split.Total, OtherParts: []jsonSplitPart{}}
+		for _, part := range otherParts(project.Split) {
+			doc.Split.OtherParts = append(doc.Split.OtherParts, jsonSplitPart{Part: part["part"].(int), Files: part["files"].([]string)})
+		}
+	}
+
+	if project.Integrity != nil {
+		doc.Integrity = &jsonIntegrity{Algorithm: project.Integrity.Algorithm, Content: project.Integrity.Content}
+	}
+
+	if project.Selection != nil {
+		doc.Selection = &jsonSelection{Threshold: project.Selection.Threshold, Files: []jsonSelectionEntry{}}
+		for _, entry := range project.Selection.Files {
+			doc.Selection.Files = append(doc.Selection.Files, jsonSelectionEntry{
+				Path:   entry.Path,
+				Tokens: entry.Tokens,
+				Score:  entry.Score,
+				Factors: jsonScoreFactor{
+					Filename:  entry.Filename,
+					Directory: entry.Directory,
+					Imports:   entry.Imports,
+					Content:   entry.Content,
+				},
+				Traits:   entry.Traits,
+				Included: entry.Included,
+				Reason:   entry.Reason,
+			})
+		}
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding JSON: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// Schema returns the schema for a format's output: a JSON Schema for "json",
+// a JSON Schema for each line of "jsonl" and an XML Schema (XSD) for "xml".
+// The other formats aren't meant to be validated.
+func Schema(format string) (string, error) {
+	switch OutputFormat(format) {
+	case FormatJSON:
+		return jsonSchema, nil
+	case FormatJSONL:
+		return jsonlSchema, nil
+	case FormatXML:
+		return xmlSchema, nil
+	}
+	return "", fmt.Errorf("no schema for format: %s (available: json, jsonl, xml)", format)
+}
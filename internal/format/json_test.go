@@ -0,0 +1,99 @@
+package format
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONFormatter_Format(t *testing.T) {
+	project := &ProjectOutput{
+		Metadata: &Metadata{Language: "Go", Version: "1.22"},
+		GitInfo:  &GitInfo{Branch: "main", CommitHash: "abc123"},
+		Files: []FileInfo{
+			{Path: "z.go", Content: "package z\n", Tokens: 4, Redactions: map[string]int{"aws-access-key": 1}, Compaction: "whitespace"},
+			{Path: "a.go", Content: "package a", Tokens: 3, Truncation: &TruncationInfo{Mode: "head:10", OriginalTokens: 50}},
+		},
+		Budget:       &BudgetInfo{MaxTokens: 100},
+		SkippedFiles: []SkippedFile{{Path: "logo.png", Size: 10, Kind: "image", Reason: "binary"}},
+		Selection: &SelectionReport{Threshold: 5, Files: []SelectionEntry{
+			{Path: "a.go", Tokens: 3, Score: 10, Filename: 10, Traits: []string{"entry"}, Included: true},
+			{Path: "b.go", Tokens: 9, Reason: "token-budget"},
+		}},
+	}
+
+	out, err := (&JSONFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonSchema), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	checkAgainstSchema(t, "$", doc, schema)
+
+	files := doc["files"].([]interface{})
+	if first := files[0].(map[string]interface{}); first["path"] != "a.go" {
+		t.Errorf("files not sorted by path, first = %v", first["path"])
+	}
+	stats := doc["stats"].(map[string]interface{})
+	if stats["files"] != float64(2) || stats["tokens"] != float64(7) || stats["lines"] != float64(3) {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+}
+
+// checkAgainstSchema verifies required keys and rejects keys the schema
+// doesn't declare, recursing into object properties and array items
+func checkAgainstSchema(t *testing.T, path string, value interface{}, schema map[string]interface{}) {
+	t.Helper()
+	switch v := value.(type) {
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		if props == nil {
+			return
+		}
+		for _, key := range schema["required"].([]interface{}) {
+			if _, ok := v[key.(string)]; !ok {
+				t.Errorf("%s: missing required key %q", path, key)
+			}
+		}
+		for key, child := range v {
+			sub, ok := props[key].(map[string]interface{})
+			if !ok {
+				t.Errorf("%s: key %q not in schema", path, key)
+				continue
+			}
+			checkAgainstSchema(t, path+"."+key, child, sub)
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for _, item := range v {
+			checkAgainstSchema(t, path+"[]", item, items)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema("json")
+	if err != nil {
+		t.Fatalf("Schema(json) error = %v", err)
+	}
+	if !strings.Contains(schema, `"const": "`+JSONSchemaVersion+`"`) {
+		t.Error("schema does not pin the current schema_version")
+	}
+	if jsonl, err := Schema("jsonl"); err != nil || !strings.Contains(jsonl, `"summary": {`) {
+		t.Errorf("Schema(jsonl) = %.40q, %v", jsonl, err)
+	}
+	if xsd, err := Schema("xml"); err != nil || !strings.Contains(xsd, `<xs:element name="project">`) {
+		t.Errorf("Schema(xml) = %.40q, %v", xsd, err)
+	}
+	if _, err := Schema("markdown"); err == nil {
+		t.Error("expected an error for a format without a schema")
+	}
+}
@@ -0,0 +1,284 @@
+package format
+
+import (
+	_ "embed"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+//go:embed jsonl.schema.json
+var jsonlSchema string
+
+// jsonlWriter encodes JSONL records one per line, keeping the first error
+// so a long run of records needs one check at the end
+type jsonlWriter struct {
+	encoder *json.Encoder
+	err     error
+}
+
+// record writes fields as one line of the given record type
+func (w *jsonlWriter) record(kind string, fields map[string]interface{}) {
+	if w.err != nil {
+		return
+	}
+	fields["type"] = kind
+	w.err = w.encoder.Encode(fields)
+}
+
+// Format implements machine-friendly JSONL output (one JSON object per line).
+// This format is ideal for programmatic processing, streaming, and pipeline
+// integration; Write produces the same records without holding them in memory.
+func (j *JSONLFormatter) Format(project *ProjectOutput) (string, error) {
+	var sb strings.Builder
+	if err := j.Write(&sb, project); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Write streams the project to w as JSONL, one record per line as it is
+// encoded, so huge projects never exist as a single string. Every record has
+// a "type" field; Schema("jsonl") describes each type. The stream opens with
+// a metadata record, has a file record per included file in path order and
+// closes with a summary record, so a reader can tell a complete stream from
+// one cut off partway.
+func (j *JSONLFormatter) Write(w io.Writer, project *ProjectOutput) error {
+	out := &jsonlWriter{encoder: json.NewEncoder(w)}
+
+	// Metadata record first
+	metadataLine := make(map[string]interface{})
+	if project.Metadata != nil {
+		if project.Metadata.Language != "" {
+			metadataLine["language"] = project.Metadata.Language
+		}
+		if len(project.Metadata.Languages) > 0 {
+			metadataLine["languages"] = languageList(project.Metadata.Languages)
+		}
+		if project.Metadata.Version != "" {
+			metadataLine["version"] = project.Metadata.Version
+		}
+		if len(project.Metadata.Dependencies) > 0 {
+			metadataLine["dependencies"] = dependencyList(project.Metadata.Dependencies)
+		}
+		if len(project.Metadata.Frameworks) > 0 {
+			metadataLine["frameworks"] = frameworkList(project.Metadata.Frameworks)
+		}
+		if project.Metadata.Owners != nil {
+			metadataLine["owners"] = ownerSummary(project.Metadata.Owners)
+		}
+		if project.Metadata.Licenses != nil {
+			metadataLine["licenses"] = licenseSummary(project.Metadata.Licenses)
+		}
+		if project.Metadata.Audit != nil {
+			metadataLine["audit"] = auditSummary(project.Metadata.Audit)
+		}
+		if project.FileStats != nil {
+			metadataLine["total_files"] = project.FileStats.TotalFiles
+			metadataLine["total_lines"] = project.FileStats.TotalLines
+		}
+	}
+	out.record("metadata", metadataLine)
+
+	// Split record: this part's position and the files in the other parts
+	if project.Split != nil {
+		out.record("split", splitSummary(project.Split))
+	}
+
+	// Integrity record: the content hash the file records' hashes add up to
+	if project.Integrity != nil {
+		out.record("integrity", integritySummary(project, false))
+	}
+
+	// Git info
+	if project.GitInfo != nil {
+		gitLine := map[string]interface{}{
+			"branch": project.GitInfo.Branch,
+			"commit": project.GitInfo.CommitHash,
+		}
+		if project.GitInfo.CommitMessage != "" {
+			gitLine["message"] = project.GitInfo.CommitMessage
+		}
+		out.record("git", gitLine)
+	}
+
+	// Commit records: recent history, newest first
+	for _, commit := range project.RecentCommits {
+		commitLine := map[string]interface{}{
+			"hash":    commit.Hash,
+			"date":    commit.Date,
+			"subject": commit.Subject,
+		}
+		if commit.Body != "" {
+			commitLine["body"] = commit.Body
+		}
+		out.record("commit", commitLine)
+	}
+
+	// Budget info (if present)
+	if project.Budget != nil {
+		budgetLine := map[string]interface{}{
+			"max_tokens": project.Budget.MaxTokens,
+			"est_tokens": project.Budget.EstimatedTokens,
+		}
+		if project.Budget.FileTruncations > 0 {
+			budgetLine["file_truncations"] = project.Budget.FileTruncations
+		}
+		out.record("budget", budgetLine)
+	}
+
+	// Filter config (if present)
+	if project.FilterConfig != nil {
+		filterLine := map[string]interface{}{}
+		if len(project.FilterConfig.Includes) > 0 {
+			filterLine["includes"] = project.FilterConfig.Includes
+		}
+		if len(project.FilterConfig.Excludes) > 0 {
+			filterLine["excludes"] = project.FilterConfig.Excludes
+		}
+		out.record("filters", filterLine)
+	}
+
+	// Dependencies record: imports stripped from the file contents
+	if project.Dependencies != nil && len(project.Dependencies.Packages) > 0 {
+		out.record("dependencies", map[string]interface{}{"packages": project.Dependencies.Packages})
+	}
+
+	// Redactions record: secrets replaced with placeholders
+	if project.Redactions != nil {
+		out.record("redactions", redactionSummary(project.Redactions))
+	}
+
+	// Skipped records: stubs for files whose content was left out
+	for _, file := range project.SkippedFiles {
+		out.record("skipped", map[string]interface{}{
+			"path":   file.Path,
+			"size":   file.Size,
+			"kind":   file.Kind,
+			"reason": file.Reason,
+		})
+	}
+
+	// Issue records: one per TODO, FIXME or HACK comment
+	for _, row := range issueList(project.Issues) {
+		out.record("issue", row)
+	}
+
+	// Selection records: one per candidate file with its score and decision
+	if project.Selection != nil {
+		for _, row := range selectionList(project.Selection) {
+			out.record("selection", row)
+		}
+	}
+
+	// Package records: one per Go/JS package with its files and internal imports
+	for _, pkg := range project.Packages {
+		packageLine := map[string]interface{}{
+			"name":  pkg.Name,
+			"path":  pkg.Path,
+			"files": pkg.Files,
+		}
+		if len(pkg.Imports) > 0 {
+			packageLine["imports"] = pkg.Imports
+		}
+		out.record("package", packageLine)
+	}
+
+	// Workspace records: one per member package with its dependencies and totals
+	if project.Workspace != nil {
+		for _, pkg := range project.Workspace.Packages {
+			row := map[string]interface{}{
+				"name":      pkg.Name,
+				"path":      pkg.Path,
+				"ecosystem": pkg.Ecosystem,
+				"files":     pkg.Files,
+				"tokens":    pkg.Tokens,
+			}
+			if len(pkg.DependsOn) > 0 {
+				row["depends_on"] = pkg.DependsOn
+			}
+			out.record("workspace", row)
+		}
+	}
+
+	// Sort files by path for deterministic output
+	sortedFiles := make([]FileInfo, len(project.Files))
+	copy(sortedFiles, project.Files)
+	sort.Slice(sortedFiles, func(i, j int) bool {
+		return sortedFiles[i].Path < sortedFiles[j].Path
+	})
+
+	// File records: one per file with metadata and content
+	var totalLines, totalTokens int
+	for _, file := range sortedFiles {
+		lineCount := strings.Count(file.Content, "\n") + 1
+		totalLines += lineCount
+		totalTokens += file.Tokens
+		fileLine := map[string]interface{}{
+			"path":    file.Path,
+			"lines":   lineCount,
+			"content": file.Content,
+		}
+
+		if file.Tokens > 0 {
+			fileLine["tokens"] = file.Tokens
+		}
+		if project.Integrity != nil {
+			fileLine["sha256"] = file.Hash
+		}
+
+		if file.Truncation != nil {
+			fileLine["truncation"] = map[string]interface{}{
+				"mode":            file.Truncation.Mode,
+				"original_tokens": file.Truncation.OriginalTokens,
+			}
+		}
+
+		if len(file.AssociatedTests) > 0 {
+			fileLine["tests"] = file.AssociatedTests
+		}
+
+		if len(file.Annotations) > 0 {
+			fileLine["notes"] = file.Annotations
+		}
+
+		if file.External {
+			fileLine["external"] = true
+		}
+
+		if len(file.Owners) > 0 {
+			fileLine["owners"] = file.Owners
+		}
+
+		if file.License != "" {
+			fileLine["license"] = file.License
+		}
+
+		if file.Sample != nil {
+			fileLine["sampled"] = sampleMap(file.Sample)
+		}
+
+		if n := file.RedactionCount(); n > 0 {
+			fileLine["redactions"] = n
+		}
+		if file.Compaction != "" {
+			fileLine["compaction"] = file.Compaction
+		}
+
+		out.record("file", fileLine)
+	}
+
+	// Summary record last: the totals of the file records before it
+	summaryLine := map[string]interface{}{
+		"files":  len(sortedFiles),
+		"lines":  totalLines,
+		"tokens": totalTokens,
+	}
+	if len(project.SkippedFiles) > 0 {
+		summaryLine["skipped"] = len(project.SkippedFiles)
+	}
+	out.record("summary", summaryLine)
+
+	return out.err
+}
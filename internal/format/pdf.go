@@ -0,0 +1,185 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PDFFormatter renders the markdown output as a paginated PDF for archival.
+// Pages use the standard Courier fonts, so nothing is embedded and the
+// document stays plain ASCII; each page is headed with the file it shows.
+type PDFFormatter struct{}
+
+// Page geometry in points (A4 portrait, 9pt Courier is 5.4pt per glyph)
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 48
+	pdfFontSize     = 9
+	pdfLeading      = 11
+	pdfLineWidth    = 92 // Characters per line before wrapping
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin - 2*pdfLeading) / pdfLeading
+)
+
+// pdfPage is one page of wrapped lines and the section it belongs to
+type pdfPage struct {
+	header string
+	lines  []string
+}
+
+// Format renders the project as a PDF document
+func (p *PDFFormatter) Format(project *ProjectOutput) (string, error) {
+	text, err := (&MarkdownFormatter{}).Format(project)
+	if err != nil {
+		return "", err
+	}
+
+	title := "promptext"
+	if project.DirectoryTree != nil && project.DirectoryTree.Name != "" {
+		title = pdfText(project.DirectoryTree.Name)
+	}
+	return writePDF(title, paginate(text, title)), nil
+}
+
+// paginate wraps the markdown text into pages, starting a new page for each
+// source file so its header names the file
+func paginate(text, title string) []pdfPage {
+	var pages []pdfPage
+	current := pdfPage{header: title}
+	flush := func() {
+		if len(current.lines) > 0 {
+			pages = append(pages, current)
+		}
+		current = pdfPage{header: current.header}
+	}
+
+	for _, line := range strings.Split(pdfText(text), "\n") {
+		if heading, ok := strings.CutPrefix(line, "### "); ok {
+			flush()
+			current.header = heading
+		}
+		for _, wrapped := range wrapLine(line, pdfLineWidth) {
+			if len(current.lines) == pdfLinesPerPage {
+				flush()
+			}
+			current.lines = append(current.lines, wrapped)
+		}
+	}
+	flush()
+
+	if len(pages) == 0 {
+		pages = append(pages, pdfPage{header: title})
+	}
+	return pages
+}
+
+// wrapLine splits a line into chunks of at most width characters
+func wrapLine(line string, width int) []string {
+	if len(line) <= width {
+		return []string{line}
+	}
+	var chunks []string
+	for len(line) > width {
+		chunks = append(chunks, line[:width])
+		line = line[width:]
+	}
+	return append(chunks, line)
+}
+
+// writePDF lays out the pages as PDF objects and appends the xref table
+func writePDF(title string, pages []pdfPage) string {
+	var b strings.Builder
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		b.WriteString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", len(offsets), body))
+	}
+
+	// Objects 1-4 are fixed; each page then takes a page and a content object
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	b.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		stream := pageStream(page, i+1, len(pages))
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+
+	offsets = append(offsets, b.Len())
+	info := len(offsets)
+	b.WriteString(fmt.Sprintf("%d 0 obj\n<< /Title (%s) /Producer (promptext) >>\nendobj\n", info, pdfEscape(title)))
+
+	xref := b.Len()
+	b.WriteString(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1))
+	for _, offset := range offsets {
+		b.WriteString(fmt.Sprintf("%010d 00000 n \n", offset))
+	}
+	b.WriteString(fmt.Sprintf("trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, info, xref))
+	return b.String()
+}
+
+// pageStream draws the bold header line, the body text, and the page number
+func pageStream(page pdfPage, number, total int) string {
+	var b strings.Builder
+	top := pdfPageHeight - pdfMargin
+
+	header := page.header
+	if limit := pdfLineWidth - 14; len(header) > limit {
+		header = "..." + header[len(header)-limit+3:]
+	}
+	b.WriteString(fmt.Sprintf("BT /F2 %d Tf %d %d Td (%s) Tj ET\n", pdfFontSize, pdfMargin, top, pdfEscape(header)))
+	pageLabel := fmt.Sprintf("%d / %d", number, total)
+	labelX := pdfPageWidth - pdfMargin - len(pageLabel)*pdfFontSize*6/10
+	b.WriteString(fmt.Sprintf("BT /F1 %d Tf %d %d Td (%s) Tj ET\n", pdfFontSize, labelX, top, pageLabel))
+
+	b.WriteString(fmt.Sprintf("BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, top-2*pdfLeading))
+	for _, line := range page.lines {
+		b.WriteString(fmt.Sprintf("(%s) Tj T*\n", pdfEscape(line)))
+	}
+	b.WriteString("ET")
+	return b.String()
+}
+
+// pdfEscape escapes the characters that delimit PDF string literals
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
+
+// pdfTransliterations covers the non-ASCII characters the formatters emit
+var pdfTransliterations = map[rune]string{
+	'└': "`", '├': "|", '│': "|", '─': "-", '•': "*", '…': "...", '·': "-",
+	'‘': "'", '’': "'", '“': `"`, '”': `"`, '–': "-", '—': "--", '→': "->",
+	'⚠': "!", '✓': "+", '\u00a0': " ",
+}
+
+// pdfText reduces text to printable ASCII, which the standard fonts can
+// render without embedding; tabs expand to four spaces
+func pdfText(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			b.WriteString("    ")
+		case r == '\n' || (r >= ' ' && r < 0x7f):
+			b.WriteRune(r)
+		case r == '\r' || r == '\ufe0f':
+			// Carriage returns and emoji variation selectors carry no glyph
+		default:
+			if repl, ok := pdfTransliterations[r]; ok {
+				b.WriteString(repl)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
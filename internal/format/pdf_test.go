@@ -0,0 +1,68 @@
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFFormatter_Format(t *testing.T) {
+	long := strings.Repeat("line (with parens)\n", 100)
+	project := &ProjectOutput{
+		DirectoryTree: &DirectoryNode{
+			Name:     "demo",
+			Type:     "dir",
+			Children: []*DirectoryNode{{Name: "main.go", Type: "file"}},
+		},
+		Metadata: &Metadata{Language: "Go"},
+		Files: []FileInfo{
+			{Path: "main.go", Content: "package main"},
+			{Path: "notes.txt", Content: long},
+		},
+	}
+
+	out, err := (&PDFFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatal("output is not a complete PDF document")
+	}
+	for i := 0; i < len(out); i++ {
+		if out[i] > 0x7e && out[i] != '\n' {
+			t.Fatalf("output contains non-ASCII byte %#x", out[i])
+		}
+	}
+
+	// Metadata page, main.go, and notes.txt spilling onto a second page
+	if got := strings.Count(out, "/Type /Page "); got != 4 {
+		t.Errorf("page count = %d, want 4", got)
+	}
+	for _, want := range []string{`(main.go \(1 lines\)) Tj`, `(notes.txt \(101 lines\)) Tj`, "(4 / 4) Tj", `(line \(with parens\)) Tj T*`, "(`-- main.go) Tj T*"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+
+	// Every xref entry must point at the start of its object
+	start, err := strconv.Atoi(regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(out)[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(out[start:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if want := fmt.Sprintf("%d 0 obj", i+1); !strings.HasPrefix(out[offset:], want) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, out[offset:offset+10], want)
+		}
+	}
+}
+
+func TestPDFText(t *testing.T) {
+	if got := pdfText("└── a\tb • ✓ 日本"); got != "`-- a    b * + ??" {
+		t.Errorf("pdfText() = %q", got)
+	}
+}
@@ -1,7 +1,9 @@
 package format
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -12,7 +14,7 @@ func TestPTXFormatterFormatIncludesManifestAndStructure(t *testing.T) {
 		Metadata: &Metadata{
 			Language:     "Go",
 			Version:      "1.21",
-			Dependencies: []string{"dep1", "dep2"},
+			Dependencies: []Dependency{{Name: "dep1", Version: "v1.0.0", Scope: "direct"}, {Name: "dep2", Scope: "indirect"}},
 		},
 		GitInfo: &GitInfo{
 			Branch:        "main",
@@ -64,7 +66,8 @@ func TestPTXFormatterFormatIncludesManifestAndStructure(t *testing.T) {
 	checks := []string{
 		"schema: ptx/v2.0",
 		"language: Go",
-		"dependencies[2]: dep1,dep2",
+		"dependencies[2]{name,scope,version}:",
+		"dep1,direct,v1.0.0",
 		"branch: main",
 		"max_tokens: 8000",
 		"includes[1]: *.go",
@@ -177,8 +180,8 @@ func TestJSONLFormatterProducesDeterministicLines(t *testing.T) {
 	}
 
 	lines := strings.Split(strings.TrimSpace(out), "\n")
-	if len(lines) != 6 {
-		t.Fatalf("expected 6 lines, got %d: %q", len(lines), lines)
+	if len(lines) != 7 {
+		t.Fatalf("expected 7 lines, got %d: %q", len(lines), lines)
 	}
 
 	typeLine := func(idx int) string {
@@ -214,15 +217,86 @@ func TestJSONLFormatterProducesDeterministicLines(t *testing.T) {
 	if !strings.Contains(lines[5], "\"tokens\":10") {
 		t.Fatalf("expected token count in second file line")
 	}
+	if lines[6] != `{"files":2,"lines":2,"tokens":10,"type":"summary"}` {
+		t.Fatalf("line 6 should be the summary, got %s", lines[6])
+	}
 }
 
+func TestJSONLFormatterWriteMatchesSchema(t *testing.T) {
+	project := &ProjectOutput{
+		Metadata: &Metadata{
+			Language:     "Go",
+			Dependencies: []Dependency{{Name: "github.com/spf13/pflag", Version: "v1.0.5", Scope: "direct"}},
+			Owners:       &OwnerInfo{Source: "CODEOWNERS", Owners: []OwnerSummary{{Owner: "@core", Files: 1, Tokens: 4}}, Unowned: 1},
+			Licenses:     &LicenseInfo{Licenses: []LicenseSummary{{ID: "MIT", Sources: []string{"LICENSE"}}}},
+		},
+		FileStats:     &FileStatistics{TotalFiles: 2, TotalLines: 3},
+		GitInfo:       &GitInfo{Branch: "main", CommitHash: "abc", CommitMessage: "Fix"},
+		RecentCommits: []CommitInfo{{Hash: "abc", Date: "2025-01-02", Subject: "Fix"}},
+		Split:         &SplitInfo{Part: 1, Total: 2, Parts: [][]string{{"a.go"}, {"c.go"}}},
+		Integrity:     &IntegrityInfo{Algorithm: "sha256", Content: "00ff"},
+		Redactions:    &RedactionInfo{Total: 1, Files: 1, Rules: map[string]int{"aws-access-key": 1}},
+		SkippedFiles:  []SkippedFile{{Path: "logo.png", Size: 10, Kind: "image", Reason: "binary"}},
+		Issues:        []Issue{{Path: "a.go", Line: 1, Kind: "TODO", Text: "split"}},
+		Packages:      []PackageInfo{{Name: "main", Path: ".", Files: []string{"a.go"}}},
+		Files: []FileInfo{
+			{Path: "a.go", Content: "package a\n", Tokens: 4, Hash: "aa", Owners: []string{"@core"}},
+			{Path: "data.csv", Content: "a,b", Hash: "bb", Sample: &SampleInfo{Rows: 1, TotalRows: 9, Columns: 2}},
+		},
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonlSchema), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	defs := schema["$defs"].(map[string]interface{})
+
+	var buf bytes.Buffer
+	if err := (&JSONLFormatter{}).Write(&buf, project); err != nil {
+		t.Fatalf("Write error: %v", err)
+	}
+	formatted, _ := (&JSONLFormatter{}).Format(project)
+	if buf.String() != formatted {
+		t.Fatal("Write and Format should produce the same records")
+	}
+
+	var types []string
+	for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("line %d not valid json: %v", i+1, err)
+		}
+		kind, _ := record["type"].(string)
+		def, ok := defs[kind].(map[string]interface{})
+		if !ok {
+			t.Errorf("line %d: record type %q not in schema", i+1, kind)
+			continue
+		}
+		checkAgainstSchema(t, kind, record, def)
+		types = append(types, kind)
+	}
+	want := []string{"metadata", "split", "integrity", "git", "commit", "redactions", "skipped", "issue", "package", "file", "file", "summary"}
+	if strings.Join(types, " ") != strings.Join(want, " ") {
+		t.Errorf("record types = %v, want %v", types, want)
+	}
+
+	// A failing writer stops the stream with its error
+	if err := (&JSONLFormatter{}).Write(failingWriter{}, project); err == nil {
+		t.Error("expected the writer's error")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
 func TestTOONStrictFormatterIncludesSections(t *testing.T) {
 	formatter := &TOONStrictFormatter{}
 	project := &ProjectOutput{
 		Metadata: &Metadata{
 			Language:     "Go",
 			Version:      "1.0",
-			Dependencies: []string{"fmt"},
+			Dependencies: []Dependency{{Name: "fmt", Scope: "direct"}},
 		},
 		GitInfo: &GitInfo{
 			Branch:        "main",
@@ -260,3 +334,189 @@ func TestTOONStrictFormatterIncludesSections(t *testing.T) {
 		t.Fatalf("expected file path in output")
 	}
 }
+
+func TestPTXAndJSONLIncludeAssociatedTests(t *testing.T) {
+	project := &ProjectOutput{
+		Files: []FileInfo{
+			{Path: "auth.go", Content: "package auth\n", AssociatedTests: []string{"auth_test.go"}},
+			{Path: "auth_test.go", Content: "package auth\n"},
+		},
+	}
+
+	ptx, err := (&PTXFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("PTX format failed: %v", err)
+	}
+	if !strings.Contains(ptx, "tests[1]: auth_test.go") {
+		t.Errorf("expected PTX manifest to list associated tests, got:\n%s", ptx)
+	}
+
+	jsonl, err := (&JSONLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("JSONL format failed: %v", err)
+	}
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(jsonl), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSONL line %q: %v", line, err)
+		}
+		if record["path"] == "auth.go" {
+			tests, _ := record["tests"].([]interface{})
+			if len(tests) == 1 && tests[0] == "auth_test.go" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected JSONL file record to include tests, got:\n%s", jsonl)
+	}
+}
+
+func TestFormattersMarkCompactedFiles(t *testing.T) {
+	project := &ProjectOutput{
+		Files: []FileInfo{
+			{Path: "app.py", Content: "def f():\n x = 1\n", Compaction: "indent"},
+			{Path: "main.go", Content: "package main\n"},
+		},
+	}
+
+	ptx, err := (&PTXFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("PTX format failed: %v", err)
+	}
+	if !strings.Contains(ptx, "compaction: indent") {
+		t.Errorf("expected PTX manifest to mark the compacted file, got:\n%s", ptx)
+	}
+
+	xml, err := (&XMLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("XML format failed: %v", err)
+	}
+	if !strings.Contains(xml, `<file path="app.py" lines="3" compaction="indent">`) || strings.Contains(xml, `path="main.go" lines="2" compaction`) {
+		t.Errorf("expected only app.py to carry a compaction attribute, got:\n%s", xml)
+	}
+
+	jsonl, err := (&JSONLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("JSONL format failed: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(jsonl), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSONL line %q: %v", line, err)
+		}
+		want := map[string]interface{}{"app.py": "indent", "main.go": nil}
+		if path, ok := record["path"].(string); ok && record["type"] == "file" && record["compaction"] != want[path] {
+			t.Errorf("%s: compaction = %v, want %v", path, record["compaction"], want[path])
+		}
+	}
+}
+
+func TestPTXAndJSONLIncludePackages(t *testing.T) {
+	project := &ProjectOutput{
+		Files: []FileInfo{
+			{Path: "main.go", Content: "package main\n"},
+			{Path: "util/util.go", Content: "package util\n"},
+		},
+		Packages: []PackageInfo{
+			{Name: "main", Path: ".", Files: []string{"main.go"}, Imports: []string{"util"}},
+			{Name: "util", Path: "util", Files: []string{"util/util.go"}},
+		},
+	}
+
+	ptx, err := (&PTXFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("PTX format failed: %v", err)
+	}
+	if !strings.Contains(ptx, "packages[2]") || !strings.Contains(ptx, "imports[1]: util") {
+		t.Errorf("expected PTX output to list packages with imports, got:\n%s", ptx)
+	}
+
+	jsonl, err := (&JSONLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("JSONL format failed: %v", err)
+	}
+	var packages []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(jsonl), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSONL line %q: %v", line, err)
+		}
+		if record["type"] == "package" {
+			packages = append(packages, record)
+		}
+	}
+	if len(packages) != 2 || packages[0]["name"] != "main" || packages[1]["path"] != "util" {
+		t.Errorf("expected two package lines, got %v", packages)
+	}
+}
+
+func TestFormattersIncludeSelectionReport(t *testing.T) {
+	project := &ProjectOutput{
+		Files: []FileInfo{{Path: "auth/login.go", Content: "package auth\n"}},
+		Selection: &SelectionReport{
+			Threshold: 5,
+			Files: []SelectionEntry{
+				{Path: "auth/login.go", Tokens: 12, Score: 15, Filename: 10, Directory: 5, Traits: []string{"entry"}, Included: true},
+				{Path: "util/common.go", Tokens: 30, Reason: "relevance"},
+			},
+		},
+	}
+
+	markdown, err := (&MarkdownFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("Markdown format failed: %v", err)
+	}
+	for _, want := range []string{
+		"Selection Report (relevance threshold 5):",
+		"[included] auth/login.go score 15, 12 tokens (filename 10, directory 5) entry",
+		"[excluded: relevance] util/common.go score 0, 30 tokens",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+
+	ptx, err := (&PTXFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("PTX format failed: %v", err)
+	}
+	if !strings.Contains(ptx, "selection:") || !strings.Contains(ptx, "util/common.go") {
+		t.Errorf("expected PTX output to include the selection report, got:\n%s", ptx)
+	}
+
+	jsonl, err := (&JSONLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("JSONL format failed: %v", err)
+	}
+	var rows []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(jsonl), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid JSONL line %q: %v", line, err)
+		}
+		if record["type"] == "selection" {
+			rows = append(rows, record)
+		}
+	}
+	if len(rows) != 2 || rows[0]["filename"] != float64(10) || rows[1]["included"] != false || rows[1]["reason"] != "relevance" {
+		t.Errorf("expected two selection lines, got %v", rows)
+	}
+
+	xml, err := (&XMLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("XML format failed: %v", err)
+	}
+	page, err := (&HTMLFormatter{}).Format(project)
+	if err != nil {
+		t.Fatalf("HTML format failed: %v", err)
+	}
+	if !strings.Contains(page, `<td><a href="#file-1">auth/login.go</a> <span class="muted">entry</span></td><td>15</td>`) {
+		t.Errorf("expected HTML selection table linking included files, got:\n%s", page)
+	}
+
+	if !strings.Contains(xml, `<file path="util/common.go" tokens="30" score="0" filename="0" directory="0" imports="0" content="0" included="false" reason="relevance"/>`) {
+		t.Errorf("expected XML selection entry, got:\n%s", xml)
+	}
+}
@@ -0,0 +1,242 @@
+// Package gha runs promptext as a GitHub Actions step. It reads the pull
+// request from the workflow event, lists the files the pull request
+// changes, and reports back through step outputs, the job summary and a
+// comment on the pull request.
+package gha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// DefaultAPIURL is the GitHub API root when GITHUB_API_URL is unset
+const DefaultAPIURL = "https://api.github.com"
+
+// CommentMarker starts the pull request comment Comment writes, so a later
+// run edits that comment instead of adding another
+const CommentMarker = "<!-- promptext -->"
+
+// ErrNotActions is returned when the environment is not a GitHub Actions run
+var ErrNotActions = errors.New("not running in GitHub Actions (GITHUB_EVENT_PATH is unset)")
+
+// Env is the part of the GitHub Actions environment a step reports through
+type Env struct {
+	EventPath   string // GITHUB_EVENT_PATH: the JSON payload of the triggering event
+	Repository  string // GITHUB_REPOSITORY: owner/name
+	APIURL      string // GITHUB_API_URL; empty means DefaultAPIURL
+	Token       string // GITHUB_TOKEN, needed only by Comment
+	OutputPath  string // GITHUB_OUTPUT: the step outputs file
+	SummaryPath string // GITHUB_STEP_SUMMARY: the job summary file
+	HTTP        *http.Client
+}
+
+// EnvFrom reads the Actions environment through getenv
+func EnvFrom(getenv func(string) string) (Env, error) {
+	env := Env{
+		EventPath:   getenv("GITHUB_EVENT_PATH"),
+		Repository:  getenv("GITHUB_REPOSITORY"),
+		APIURL:      getenv("GITHUB_API_URL"),
+		Token:       getenv("GITHUB_TOKEN"),
+		OutputPath:  getenv("GITHUB_OUTPUT"),
+		SummaryPath: getenv("GITHUB_STEP_SUMMARY"),
+	}
+	if env.EventPath == "" {
+		return Env{}, ErrNotActions
+	}
+	return env, nil
+}
+
+// PullRequest is the pull request a workflow runs for
+type PullRequest struct {
+	Number  int
+	Title   string
+	BaseRef string // Branch the pull request merges into
+	BaseSHA string
+	HeadSHA string
+}
+
+// PullRequest reads the pull request from the event payload, or returns nil
+// for events without one, such as pushes
+func (e Env) PullRequest() (*PullRequest, error) {
+	data, err := os.ReadFile(e.EventPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading the workflow event: %w", err)
+	}
+	var event struct {
+		PullRequest *struct {
+			Number int    `json:"number"`
+			Title  string `json:"title"`
+			Base   struct {
+				Ref string `json:"ref"`
+				SHA string `json:"sha"`
+			} `json:"base"`
+			Head struct {
+				SHA string `json:"sha"`
+			} `json:"head"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("reading the workflow event: %w", err)
+	}
+	if event.PullRequest == nil {
+		return nil, nil
+	}
+	pr := event.PullRequest
+	return &PullRequest{Number: pr.Number, Title: pr.Title, BaseRef: pr.Base.Ref, BaseSHA: pr.Base.SHA, HeadSHA: pr.Head.SHA}, nil
+}
+
+// ChangedFiles lists the files a pull request adds or modifies between base
+// and head, relative to dir and limited to the files below it. Deleted
+// files are left out. Both commits and their merge base must be in the
+// checkout, as with actions/checkout's fetch-depth: 0.
+func ChangedFiles(dir, base, head string) ([]string, error) {
+	cmd := exec.Command("git", "diff", "--name-only", "--relative", "--diff-filter=d", "-z", base+"..."+head)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing the changed files (check out with fetch-depth: 0): %s", strings.TrimSpace(stderr.String()))
+	}
+	files := []string{}
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
+
+// SetOutputs adds outputs to the step's outputs, in key order. Values
+// spanning lines are written between delimiters, as Actions expects.
+func (e Env) SetOutputs(outputs map[string]string) error {
+	if e.OutputPath == "" {
+		return nil
+	}
+	keys := make([]string, 0, len(outputs))
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		value := outputs[key]
+		if strings.ContainsAny(value, "\r\n") {
+			delimiter := "promptext_" + key
+			for strings.Contains(value, delimiter) {
+				delimiter += "_"
+			}
+			fmt.Fprintf(&sb, "%s<<%s\n%s\n%s\n", key, delimiter, value, delimiter)
+		} else {
+			fmt.Fprintf(&sb, "%s=%s\n", key, value)
+		}
+	}
+	return appendFile(e.OutputPath, sb.String())
+}
+
+// AppendSummary adds markdown to the job summary
+func (e Env) AppendSummary(markdown string) error {
+	if e.SummaryPath == "" {
+		return nil
+	}
+	return appendFile(e.SummaryPath, markdown)
+}
+
+func appendFile(path, text string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Comment posts body, after CommentMarker, on pull request number, editing
+// the comment an earlier run left instead when there is one
+func (e Env) Comment(ctx context.Context, number int, body string) error {
+	if e.Token == "" {
+		return errors.New("commenting needs GITHUB_TOKEN")
+	}
+	if e.Repository == "" {
+		return errors.New("commenting needs GITHUB_REPOSITORY")
+	}
+	body = CommentMarker + "\n" + body
+
+	var comments []struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	listURL := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100", e.Repository, number)
+	if err := e.request(ctx, http.MethodGet, listURL, nil, &comments); err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		if strings.HasPrefix(comment.Body, CommentMarker) {
+			editURL := fmt.Sprintf("/repos/%s/issues/comments/%d", e.Repository, comment.ID)
+			return e.request(ctx, http.MethodPatch, editURL, map[string]string{"body": body}, nil)
+		}
+	}
+	postURL := fmt.Sprintf("/repos/%s/issues/%d/comments", e.Repository, number)
+	return e.request(ctx, http.MethodPost, postURL, map[string]string{"body": body}, nil)
+}
+
+// request calls the GitHub API at path, sending body as JSON when set and
+// decoding the reply into out when set
+func (e Env) request(ctx context.Context, method, path string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	root := e.APIURL
+	if root == "" {
+		root = DefaultAPIURL
+	}
+	url := strings.TrimRight(root, "/") + path
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+e.Token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := e.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s returned %s: %s", method, url, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("%s %s: decoding response: %w", method, url, err)
+		}
+	}
+	return nil
+}
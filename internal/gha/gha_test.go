@@ -0,0 +1,177 @@
+package gha
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvFrom(t *testing.T) {
+	vars := map[string]string{
+		"GITHUB_EVENT_PATH":   "/tmp/event.json",
+		"GITHUB_REPOSITORY":   "octo/repo",
+		"GITHUB_TOKEN":        "secret",
+		"GITHUB_OUTPUT":       "/tmp/out",
+		"GITHUB_STEP_SUMMARY": "/tmp/summary",
+	}
+	env, err := EnvFrom(func(key string) string { return vars[key] })
+	require.NoError(t, err)
+	assert.Equal(t, "octo/repo", env.Repository)
+	assert.Equal(t, "/tmp/out", env.OutputPath)
+
+	_, err = EnvFrom(func(string) string { return "" })
+	assert.ErrorIs(t, err, ErrNotActions)
+}
+
+func TestPullRequest(t *testing.T) {
+	dir := t.TempDir()
+	event := filepath.Join(dir, "event.json")
+	require.NoError(t, os.WriteFile(event, []byte(`{
+		"action": "synchronize",
+		"pull_request": {
+			"number": 42,
+			"title": "Add caching",
+			"base": {"ref": "main", "sha": "aaa"},
+			"head": {"ref": "cache", "sha": "bbb"}
+		}
+	}`), 0644))
+
+	pr, err := Env{EventPath: event}.PullRequest()
+	require.NoError(t, err)
+	assert.Equal(t, &PullRequest{Number: 42, Title: "Add caching", BaseRef: "main", BaseSHA: "aaa", HeadSHA: "bbb"}, pr)
+
+	// Pushes carry no pull request
+	require.NoError(t, os.WriteFile(event, []byte(`{"ref": "refs/heads/main"}`), 0644))
+	pr, err = Env{EventPath: event}.PullRequest()
+	require.NoError(t, err)
+	assert.Nil(t, pr)
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return string(out)
+	}
+	write := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+	}
+
+	git("init", "-q")
+	write("main.go", "package main\n")
+	write("old.go", "package main\n")
+	write("pkg/util.go", "package pkg\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	base := git("rev-parse", "HEAD")
+
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("pkg/new.go", "package pkg\n")
+	require.NoError(t, os.Remove(filepath.Join(dir, "old.go")))
+	git("add", "-A")
+	git("commit", "-q", "-m", "change")
+	head := git("rev-parse", "HEAD")
+
+	files, err := ChangedFiles(dir, base[:len(base)-1], head[:len(head)-1])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"main.go", "pkg/new.go"}, files, "deleted files are left out")
+
+	files, err = ChangedFiles(filepath.Join(dir, "pkg"), base[:len(base)-1], head[:len(head)-1])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new.go"}, files, "paths are relative to the directory")
+
+	_, err = ChangedFiles(dir, "0000000000000000000000000000000000000000", "HEAD")
+	assert.ErrorContains(t, err, "fetch-depth: 0")
+}
+
+func TestSetOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output")
+	env := Env{OutputPath: path}
+	require.NoError(t, env.SetOutputs(map[string]string{"tokens": "1200", "files": "a.go\nb.go"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "files<<promptext_files\na.go\nb.go\npromptext_files\ntokens=1200\n", string(data))
+
+	// Without GITHUB_OUTPUT there is nowhere to write
+	assert.NoError(t, Env{}.SetOutputs(map[string]string{"tokens": "1"}))
+}
+
+func TestAppendSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary")
+	env := Env{SummaryPath: path}
+	require.NoError(t, env.AppendSummary("## one\n"))
+	require.NoError(t, env.AppendSummary("## two\n"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "## one\n## two\n", string(data))
+}
+
+// fakeGitHub serves the issue comment endpoints, starting with comments
+func fakeGitHub(t *testing.T, comments []map[string]any) (*httptest.Server, *[]string) {
+	t.Helper()
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/octo/repo/issues/7/comments":
+			json.NewEncoder(w).Encode(comments)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/octo/repo/issues/7/comments":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			assert.Equal(t, CommentMarker+"\nhello", body["body"])
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/octo/repo/issues/comments/99":
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestComment(t *testing.T) {
+	server, calls := fakeGitHub(t, []map[string]any{{"id": 1, "body": "LGTM"}})
+	env := Env{Repository: "octo/repo", APIURL: server.URL, Token: "secret"}
+	require.NoError(t, env.Comment(context.Background(), 7, "hello"))
+	assert.Equal(t, []string{"GET /repos/octo/repo/issues/7/comments", "POST /repos/octo/repo/issues/7/comments"}, *calls)
+
+	// A later run edits its earlier comment
+	server, calls = fakeGitHub(t, []map[string]any{{"id": 1, "body": "LGTM"}, {"id": 99, "body": CommentMarker + "\nold"}})
+	env.APIURL = server.URL
+	require.NoError(t, env.Comment(context.Background(), 7, "hello"))
+	assert.Equal(t, []string{"GET /repos/octo/repo/issues/7/comments", "PATCH /repos/octo/repo/issues/comments/99"}, *calls)
+
+	assert.ErrorContains(t, Env{Repository: "octo/repo"}.Comment(context.Background(), 7, "hello"), "GITHUB_TOKEN")
+}
+
+func TestCommentAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Resource not accessible by integration"}`, http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	env := Env{Repository: "octo/repo", APIURL: server.URL, Token: "secret"}
+	err := env.Comment(context.Background(), 7, "hello")
+	assert.ErrorContains(t, err, "403 Forbidden")
+	assert.ErrorContains(t, err, "not accessible")
+}
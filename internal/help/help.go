@@ -0,0 +1,231 @@
+// Package help lays out the command-line help of promptext. The help is
+// kept as sections of options and subcommands, grouped into topics, and
+// rendered as the plain text prx --help and prx help TOPIC print or as a
+// man page.
+package help
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Width is the column text is wrapped at
+const Width = 104
+
+// descColumn is the column entry descriptions start at
+const descColumn = 29
+
+// Entry is an option or subcommand of a section
+type Entry struct {
+	Name string // As typed, such as "-d, --directory DIR" or "cache clear"
+	Desc string // Wrapped to fit; a newline starts a new line
+}
+
+// Section is a titled part of the help. Text comes before the entries and
+// is laid out like a Go doc comment: blank lines separate paragraphs, which
+// are wrapped to fit, and lines indented by a tab are kept as they are.
+type Section struct {
+	Title   string
+	Text    string
+	Entries []Entry
+}
+
+// Topic is a part of the help prx help NAME prints on its own
+type Topic struct {
+	Name     string
+	Aliases  []string // Other names Find accepts, such as a subcommand
+	Summary  string
+	Sections []Section
+}
+
+// Find returns the topic of topics called name, or with name as an alias
+func Find(topics []Topic, name string) (Topic, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, topic := range topics {
+		if topic.Name == name {
+			return topic, true
+		}
+		for _, alias := range topic.Aliases {
+			if alias == name {
+				return topic, true
+			}
+		}
+	}
+	return Topic{}, false
+}
+
+// Names returns the names of topics, in order
+func Names(topics []Topic) []string {
+	names := make([]string, len(topics))
+	for i, topic := range topics {
+		names[i] = topic.Name
+	}
+	return names
+}
+
+// Write prints sections as plain text, each ending with a blank line
+func Write(w io.Writer, sections ...Section) error {
+	var b strings.Builder
+	for _, section := range sections {
+		b.WriteString(section.Title + ":\n")
+		for i, block := range blocks(section.Text) {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			lines := block.lines
+			if !block.literal {
+				lines = wrap(strings.Join(lines, " "), Width-4)
+			}
+			for _, line := range lines {
+				b.WriteString(strings.TrimRight("    "+line, " ") + "\n")
+			}
+		}
+		if section.Text != "" && len(section.Entries) > 0 {
+			b.WriteString("\n")
+		}
+		for _, entry := range section.Entries {
+			writeEntry(&b, entry)
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeEntry lays entry out with its description from descColumn on,
+// starting below the name when the name reaches that far
+func writeEntry(b *strings.Builder, entry Entry) {
+	indent := "        "
+	if strings.HasPrefix(entry.Name, "-") && !strings.HasPrefix(entry.Name, "--") {
+		indent = "    " // Keep long options lined up after the short ones
+	}
+	var lines []string
+	for _, line := range strings.Split(entry.Desc, "\n") {
+		lines = append(lines, wrap(line, Width-descColumn)...)
+	}
+
+	head := indent + entry.Name
+	if len(head) < descColumn && len(lines) > 0 {
+		b.WriteString(head + strings.Repeat(" ", descColumn-len(head)) + lines[0] + "\n")
+		lines = lines[1:]
+	} else {
+		b.WriteString(head + "\n")
+	}
+	for _, line := range lines {
+		b.WriteString(strings.Repeat(" ", descColumn) + line + "\n")
+	}
+}
+
+// block is a paragraph or a run of literal lines of a section's text
+type block struct {
+	literal bool
+	lines   []string
+}
+
+func blocks(text string) []block {
+	var result []block
+	var current *block
+	for _, line := range strings.Split(strings.Trim(text, "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+		literal := strings.HasPrefix(line, "\t")
+		if literal {
+			line = line[1:]
+		}
+		if current == nil || current.literal != literal {
+			result = append(result, block{literal: literal})
+			current = &result[len(result)-1]
+		}
+		current.lines = append(current.lines, line)
+	}
+	return result
+}
+
+// wrap breaks text into lines of at most width characters, at spaces. A
+// word longer than width gets a line of its own.
+func wrap(text string, width int) []string {
+	var lines []string
+	var line strings.Builder
+	for _, word := range strings.Fields(text) {
+		if line.Len() > 0 && utf8.RuneCountInString(line.String())+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+		}
+		if line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		line.WriteString(word)
+	}
+	if line.Len() > 0 || len(lines) == 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// Page is a man page
+type Page struct {
+	Name     string // Command the page documents, such as "promptext"
+	Section  int    // Manual section, 1 for user commands
+	Date     string // Left empty when unknown
+	Source   string // Package and version, such as "promptext v0.7.3"
+	Summary  string // What the command does, for the NAME section
+	Aliases  []string
+	Sections []Section
+}
+
+// WriteMan prints page as roff for man(1), starting with the NAME section
+func WriteMan(w io.Writer, page Page) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH %s %d %q %q %q\n", strings.ToUpper(page.Name), page.Section, page.Date, page.Source, "User Commands")
+	b.WriteString(".SH NAME\n")
+	b.WriteString(escape(strings.Join(append([]string{page.Name}, page.Aliases...), ", ")) + ` \- ` + escape(page.Summary) + "\n")
+	for _, section := range page.Sections {
+		fmt.Fprintf(&b, ".SH %q\n", section.Title)
+		for i, block := range blocks(section.Text) {
+			if i > 0 {
+				b.WriteString(".PP\n")
+			}
+			if block.literal {
+				b.WriteString(".nf\n")
+			}
+			for _, line := range block.lines {
+				b.WriteString(roffLine(line) + "\n")
+			}
+			if block.literal {
+				b.WriteString(".fi\n")
+			}
+		}
+		for _, entry := range section.Entries {
+			b.WriteString(".TP\n")
+			b.WriteString(`\fB` + escape(entry.Name) + `\fR` + "\n")
+			for i, line := range strings.Split(entry.Desc, "\n") {
+				if i > 0 {
+					b.WriteString(".br\n")
+				}
+				b.WriteString(roffLine(line) + "\n")
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var roffEscaper = strings.NewReplacer(`\`, `\e`, "-", `\-`, "•", `\(bu`)
+
+func escape(text string) string {
+	return roffEscaper.Replace(text)
+}
+
+// roffLine escapes a line of text, guarding a leading dot or quote from
+// being read as a request
+func roffLine(line string) string {
+	line = escape(line)
+	if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+		line = `\&` + line
+	}
+	return line
+}
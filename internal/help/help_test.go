@@ -0,0 +1,97 @@
+package help
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	section := Section{
+		Title: "CACHE",
+		Text: `Files are cached so
+unchanged files are not read again.
+
+	prx cache clear`,
+		Entries: []Entry{
+			{Name: "-d, --directory DIR", Desc: "Directory to process\nComma-separate several"},
+			{Name: "--clipboard-backend NAME", Desc: "How to copy"},
+			{Name: "--tree", Desc: strings.Repeat("word ", 30)},
+		},
+	}
+	var b strings.Builder
+	require.NoError(t, Write(&b, section))
+
+	assert.Equal(t, `CACHE:
+    Files are cached so unchanged files are not read again.
+
+    prx cache clear
+
+    -d, --directory DIR      Directory to process
+                             Comma-separate several
+        --clipboard-backend NAME
+                             How to copy
+        --tree               word word word word word word word word word word word word word word word
+                             word word word word word word word word word word word word word word word
+
+`, b.String())
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, []string{"a bb", "ccc"}, wrap("a bb ccc", 5))
+	assert.Equal(t, []string{"longword", "a"}, wrap("longword a", 4), "a long word gets a line of its own")
+	assert.Equal(t, []string{""}, wrap("", 10))
+}
+
+func TestFind(t *testing.T) {
+	topics := []Topic{
+		{Name: "filtering", Summary: "Which files are read"},
+		{Name: "hooks", Aliases: []string{"hook"}},
+	}
+	topic, ok := Find(topics, "Filtering")
+	require.True(t, ok)
+	assert.Equal(t, "Which files are read", topic.Summary)
+
+	topic, ok = Find(topics, "hook")
+	require.True(t, ok)
+	assert.Equal(t, "hooks", topic.Name)
+
+	_, ok = Find(topics, "colors")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"filtering", "hooks"}, Names(topics))
+}
+
+func TestWriteMan(t *testing.T) {
+	page := Page{
+		Name:    "promptext",
+		Section: 1,
+		Source:  "promptext v1.0.0",
+		Summary: "code context extractor",
+		Aliases: []string{"prx"},
+		Sections: []Section{{
+			Title:   "INPUT OPTIONS",
+			Text:    ".promptext.yml sets the defaults\n\n\t- .go",
+			Entries: []Entry{{Name: "-e, --extension LIST", Desc: "Extensions to include\n• C:\\src"}},
+		}},
+	}
+	var b strings.Builder
+	require.NoError(t, WriteMan(&b, page))
+
+	assert.Equal(t, `.TH PROMPTEXT 1 "" "promptext v1.0.0" "User Commands"
+.SH NAME
+promptext, prx \- code context extractor
+.SH "INPUT OPTIONS"
+\&.promptext.yml sets the defaults
+.PP
+.nf
+\- .go
+.fi
+.TP
+\fB\-e, \-\-extension LIST\fR
+Extensions to include
+.br
+\(bu C:\esrc
+`, b.String())
+}
@@ -0,0 +1,273 @@
+// Package hook installs the git hooks that run promptext checks before a
+// commit or push, and works out the change each hook is about to let
+// through: the staged files for pre-commit, the pushed commits for pre-push.
+package hook
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/redact"
+)
+
+// Hooks promptext installs
+const (
+	PreCommit = "pre-commit"
+	PrePush   = "pre-push"
+)
+
+// Names lists the hooks Install accepts
+var Names = []string{PreCommit, PrePush}
+
+// marker identifies the hook scripts promptext wrote, which Install may
+// replace and Uninstall removes
+const marker = "# Installed by promptext"
+
+// zeroSHA is the object name git passes for a ref that doesn't exist
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+// binaryProbe is how much of a file is checked for NUL bytes before its
+// content is scanned
+const binaryProbe = 8000
+
+// ErrHookExists is returned by Install when the hook file is a hook
+// promptext didn't write
+var ErrHookExists = errors.New("hook already exists")
+
+// Script returns the hook script for name. It runs prx, or promptext when
+// only that name is on the PATH, and lets the commit through with a warning
+// when neither is installed.
+func Script(name string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s: runs the checks under hooks.%s in .promptext.yml.
+# Remove with: prx hook uninstall %s
+for prx in prx promptext; do
+	if command -v "$prx" >/dev/null 2>&1; then
+		exec "$prx" hook run %s "$@"
+	fi
+done
+echo "promptext is not installed; skipping the %s checks" >&2
+`, marker, name, name, name, name)
+}
+
+// Dir returns the directory git runs the hooks of repo from, honoring
+// core.hooksPath
+func Dir(repo string) (string, error) {
+	out, err := git(repo, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repo, dir)
+	}
+	return dir, nil
+}
+
+// Install writes the script of hook name into repo and returns its path. A
+// hook promptext didn't write is left alone, returning ErrHookExists, unless
+// force is set.
+func Install(repo, name string, force bool) (string, error) {
+	if !slices.Contains(Names, name) {
+		return "", fmt.Errorf("unknown hook %q (supported: %s)", name, strings.Join(Names, ", "))
+	}
+	dir, err := Dir(repo)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if existing, err := os.ReadFile(path); err == nil && !bytes.Contains(existing, []byte(marker)) && !force {
+		return path, fmt.Errorf("%w: %s (use --force to replace it)", ErrHookExists, path)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return path, fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(Script(name)), 0755); err != nil {
+		return path, fmt.Errorf("failed to write hook: %w", err)
+	}
+	return path, nil
+}
+
+// Uninstall removes hook name from repo if promptext installed it, and
+// reports whether it did
+func Uninstall(repo, name string) (string, bool, error) {
+	dir, err := Dir(repo)
+	if err != nil {
+		return "", false, err
+	}
+	path := filepath.Join(dir, name)
+	existing, err := os.ReadFile(path)
+	if err != nil || !bytes.Contains(existing, []byte(marker)) {
+		return path, false, nil
+	}
+	if err := os.Remove(path); err != nil {
+		return path, false, fmt.Errorf("failed to remove hook: %w", err)
+	}
+	return path, true, nil
+}
+
+// Change is what a hook checks: a commit and the one it follows
+type Change struct {
+	Rev   string   // Commit holding the change; for pre-commit a snapshot of the index
+	Base  string   // Commit the change follows; empty when Rev starts the history
+	Files []string // Files the change adds or modifies, relative to the directory
+	Desc  string   // What the change is, for messages
+}
+
+// Staged returns the change a commit in repo is about to record. The index
+// is snapshotted as a commit object no ref points to, so the staged content
+// is checked rather than the working tree; git prunes it later.
+func Staged(repo string) (Change, error) {
+	tree, err := git(repo, "write-tree")
+	if err != nil {
+		return Change{}, fmt.Errorf("error reading the staged files: %w", err)
+	}
+	args := []string{"commit-tree", "--no-gpg-sign", "-m", "promptext pre-commit snapshot", strings.TrimSpace(string(tree))}
+	base := revParse(repo, "HEAD")
+	if base != "" {
+		args = append(args, "-p", base)
+	}
+	cmd := gitCommand(repo, args...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=promptext", "GIT_AUTHOR_EMAIL=promptext@localhost",
+		"GIT_COMMITTER_NAME=promptext", "GIT_COMMITTER_EMAIL=promptext@localhost")
+	rev, err := run(cmd)
+	if err != nil {
+		return Change{}, fmt.Errorf("error reading the staged files: %w", err)
+	}
+
+	files, err := list(repo, "diff", "--cached", "--name-only", "--relative", "--diff-filter=d", "-z")
+	if err != nil {
+		return Change{}, fmt.Errorf("error listing the staged files: %w", err)
+	}
+	return Change{Rev: strings.TrimSpace(string(rev)), Base: base, Files: files, Desc: "the staged changes"}, nil
+}
+
+// Pushed returns the changes a push from repo is about to send, reading the
+// "<local ref> <local sha> <remote ref> <remote sha>" lines git gives the
+// pre-push hook on stdin. Deleted refs and refs with nothing new are left
+// out. A new branch is compared to the last commit the remotes already have.
+func Pushed(repo string, stdin io.Reader) ([]Change, error) {
+	var changes []Change
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		localRef, local, remote := fields[0], fields[1], fields[3]
+		if local == zeroSHA {
+			continue // Deleting the remote ref
+		}
+
+		base := ""
+		if remote != zeroSHA && revParse(repo, remote) != "" {
+			base = remote
+		} else {
+			out, err := git(repo, "rev-list", "--reverse", local, "--not", "--remotes")
+			if err != nil {
+				return nil, fmt.Errorf("error reading the pushed commits: %w", err)
+			}
+			first, _, _ := strings.Cut(string(out), "\n")
+			if first == "" {
+				continue // The remotes have every commit already
+			}
+			base = revParse(repo, first+"^")
+		}
+
+		var files []string
+		var err error
+		if base != "" {
+			files, err = list(repo, "diff", "--name-only", "--relative", "--diff-filter=d", "-z", base, local)
+		} else {
+			files, err = list(repo, "ls-tree", "-r", "--name-only", "-z", local)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing the pushed files: %w", err)
+		}
+		changes = append(changes, Change{Rev: local, Base: base, Files: files, Desc: "the commits pushed from " + strings.TrimPrefix(localRef, "refs/heads/")})
+	}
+	return changes, scanner.Err()
+}
+
+// Finding is a changed file the secret scan flagged
+type Finding struct {
+	Path  string
+	Count int      // Secrets found
+	Rules []string // Rules that matched, sorted
+}
+
+// ScanSecrets runs the redaction rules over the files of change as they are
+// in change.Rev, skipping binary files, and returns the files that hold
+// secrets
+func ScanSecrets(repo string, change Change) ([]Finding, error) {
+	var findings []Finding
+	for _, path := range change.Files {
+		content, err := git(repo, "show", change.Rev+":./"+filepath.ToSlash(path))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if bytes.IndexByte(content[:min(len(content), binaryProbe)], 0) >= 0 {
+			continue
+		}
+		if _, counts := redact.Redact(path, string(content)); len(counts) > 0 {
+			findings = append(findings, Finding{Path: path, Count: redact.Total(counts), Rules: redact.RuleNames(counts)})
+		}
+	}
+	return findings, nil
+}
+
+// revParse returns the commit rev names in repo, or "" when it names none
+func revParse(repo, rev string) string {
+	out, err := git(repo, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// list runs a git command printing NUL-separated paths and returns them
+func list(repo string, args ...string) ([]string, error) {
+	out, err := git(repo, args...)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func gitCommand(repo string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repo
+	return cmd
+}
+
+// git runs a git command in repo and returns its standard output
+func git(repo string, args ...string) ([]byte, error) {
+	return run(gitCommand(repo, args...))
+}
+
+func run(cmd *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,168 @@
+// Package httpapi serves promptext over a small JSON REST API so CI bots and
+// internal tools can request code context without installing the CLI.
+//
+// Endpoints:
+//
+//	POST /extract   extract context; the body is a JSON service.ExtractRequest
+//	GET  /info      project summary (?directory=...)
+//	GET  /formats   supported output formats
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/service"
+	"github.com/1broseidon/promptext/pkg/promptext"
+)
+
+// maxRequestBody bounds the size of a POST /extract body
+const maxRequestBody = 1 << 20
+
+// rawContentType returns the Content-Type for a ?raw=true response in the
+// named format, from the MIME type the format declares
+func rawContentType(name string) string {
+	for _, info := range promptext.Formatters() {
+		if (info.Name == name || slices.Contains(info.Aliases, name)) && info.MimeType != "" {
+			if strings.HasPrefix(info.MimeType, "text/") {
+				return info.MimeType + "; charset=utf-8"
+			}
+			return info.MimeType
+		}
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// ExtractResponse is the JSON body returned by POST /extract
+type ExtractResponse struct {
+	Format      string         `json:"format"`
+	Output      string         `json:"output"`
+	TokenCount  int            `json:"token_count"`
+	TotalTokens int            `json:"total_tokens"`
+	Files       []FileSummary  `json:"files"`
+	Excluded    []ExcludedFile `json:"excluded,omitempty"`
+	Warnings    []string       `json:"warnings,omitempty"`
+}
+
+// FileSummary lists an included file without its content
+type FileSummary struct {
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+}
+
+// ExcludedFile lists a file left out of the output and why
+type ExcludedFile struct {
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+	Reason string `json:"reason"`
+}
+
+// NewHandler returns the API handler for projects under root
+func NewHandler(root string) http.Handler {
+	svc := service.New(root)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/extract", func(w http.ResponseWriter, r *http.Request) { handleExtract(svc, w, r) })
+	mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) { handleInfo(svc, w, r) })
+	mux.HandleFunc("/formats", handleFormats)
+	return mux
+}
+
+func handleExtract(svc *service.Service, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	var req service.ExtractRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if req.Format == "" {
+		req.Format = string(promptext.FormatPTX)
+	}
+
+	result, err := svc.Extract(req)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	if r.URL.Query().Get("raw") == "true" {
+		w.Header().Set("Content-Type", rawContentType(req.Format))
+		w.Write([]byte(result.FormattedOutput))
+		return
+	}
+
+	resp := ExtractResponse{
+		Format:      req.Format,
+		Output:      result.FormattedOutput,
+		TokenCount:  result.TokenCount,
+		TotalTokens: result.TotalTokens,
+		Files:       []FileSummary{},
+		Warnings:    result.Warnings,
+	}
+	for _, file := range result.ProjectOutput.Files {
+		resp.Files = append(resp.Files, FileSummary{Path: file.Path, Tokens: file.Tokens})
+	}
+	for _, excluded := range result.ExcludedFileList {
+		resp.Excluded = append(resp.Excluded, ExcludedFile{Path: excluded.Path, Tokens: excluded.Tokens, Reason: excluded.Reason})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func handleInfo(svc *service.Service, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	summary, err := svc.ProjectInfo(r.URL.Query().Get("directory"))
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func handleFormats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"formats": service.Formats})
+}
+
+// statusFor maps extraction errors to HTTP status codes: caller mistakes are
+// 400s, anything else is a server-side failure
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, service.ErrOutsideRoot),
+		errors.Is(err, promptext.ErrInvalidFormat),
+		errors.Is(err, promptext.ErrInvalidDirectory),
+		errors.Is(err, promptext.ErrNoFilesMatched):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed, use %s", allowed))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,114 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/demo\n\ngo 1.22\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("# demo\n"), 0644))
+	return root
+}
+
+func TestHandlerExtract(t *testing.T) {
+	handler := NewHandler(newTestProject(t))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/extract", strings.NewReader(`{"extensions":[".go"],"format":"markdown"}`)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var resp ExtractResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "markdown", resp.Format)
+	assert.Contains(t, resp.Output, "func main()")
+	assert.Equal(t, []FileSummary{{Path: "main.go", Tokens: resp.Files[0].Tokens}}, resp.Files)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/extract?raw=true", strings.NewReader(`{"extensions":[".go"],"format":"xml"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/xml", rec.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "<"), rec.Body.String())
+}
+
+func TestHandlerErrors(t *testing.T) {
+	handler := NewHandler(newTestProject(t))
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"wrong method", http.MethodGet, "/extract", "", http.StatusMethodNotAllowed},
+		{"malformed body", http.MethodPost, "/extract", `{"extensions":`, http.StatusBadRequest},
+		{"unknown field", http.MethodPost, "/extract", `{"extension":[".go"]}`, http.StatusBadRequest},
+		{"outside root", http.MethodPost, "/extract", `{"directory":"../"}`, http.StatusBadRequest},
+		{"bad format", http.MethodPost, "/extract", `{"format":"yaml"}`, http.StatusBadRequest},
+		{"info outside root", http.MethodGet, "/info?directory=..", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+
+			var body map[string]string
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.NotEmpty(t, body["error"])
+		})
+	}
+}
+
+func TestHandlerInfoAndFormats(t *testing.T) {
+	handler := NewHandler(newTestProject(t))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var summary map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	assert.Equal(t, "Go", summary["language"])
+	assert.Contains(t, summary["tree"], "main.go")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/formats", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var formats map[string][]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &formats))
+	assert.Contains(t, formats["formats"], "ptx")
+}
+
+func TestHandlerSymlinkOutsideRoot(t *testing.T) {
+	root := newTestProject(t)
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.go"), []byte("package secret\n"), 0644))
+	if err := os.Symlink(outside, filepath.Join(root, "linked")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	handler := NewHandler(root)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/extract", strings.NewReader(`{"directory":"linked"}`)),
+		httptest.NewRequest(http.MethodGet, "/info?directory=linked", nil),
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		assert.NotContains(t, rec.Body.String(), "package secret")
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/1broseidon/promptext/internal/cache"
 	"github.com/1broseidon/promptext/internal/filter"
 	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/initializer"
 	"github.com/1broseidon/promptext/internal/log"
 )
 
@@ -24,6 +26,7 @@ type ProjectInfo struct {
 	DirectoryTree *format.DirectoryNode
 	GitInfo       *GitInfo
 	Metadata      *ProjectMetadata
+	RecentCommits []CommitInfo // Only populated on request (see GetRecentCommits)
 }
 
 // GitInfo holds git repository information
@@ -38,10 +41,19 @@ type ProjectMetadata struct {
 	Name         string
 	Language     string
 	Version      string
-	Dependencies []string
+	Dependencies []Dependency
+	Frameworks   []Framework // Highest priority first
 	Health       *ProjectHealth
 }
 
+// Framework is a framework, build tool, or language found by the
+// initializer's project detector
+type Framework struct {
+	Name        string // Detector name, e.g. "nextjs"
+	Description string // Display name, e.g. "Next.js"
+	Priority    int    // Higher is more specific (see initializer.Priority*)
+}
+
 // ProjectHealth holds information about project health indicators
 type ProjectHealth struct {
 	HasReadme  bool
@@ -53,6 +65,64 @@ type ProjectHealth struct {
 
 // GetProjectInfo gathers all available information about the project
 func GetProjectInfo(rootPath string, f *filter.Filter) (*ProjectInfo, error) {
+	return GetProjectInfoWithCache(rootPath, f, nil)
+}
+
+// GetProjectInfoWithCache is like GetProjectInfo but takes the directory tree
+// from cache when possible. A nil cache always regenerates the tree.
+func GetProjectInfoWithCache(rootPath string, f *filter.Filter, cache *TreeCache) (*ProjectInfo, error) {
+	return gatherProjectInfo(rootPath, func() (*format.DirectoryNode, error) {
+		if cache != nil {
+			return cache.Get(rootPath, f)
+		}
+		return generateDirectoryTree(rootPath, f)
+	})
+}
+
+// GetProjectInfoForRoots is like GetProjectInfo but the directory tree only
+// covers the given subdirectories of rootPath (relative paths), nested under
+// their path from rootPath. Git and metadata still come from rootPath.
+func GetProjectInfoForRoots(rootPath string, roots []string, f *filter.Filter) (*ProjectInfo, error) {
+	return gatherProjectInfo(rootPath, func() (*format.DirectoryNode, error) {
+		return generateRootsTree(rootPath, roots, f)
+	})
+}
+
+// GetProjectInfoWalk is like GetProjectInfoForRoots with the directory walk
+// supplied, such as one following symlinks. Empty roots cover all of rootPath.
+func GetProjectInfoWalk(rootPath string, roots []string, f *filter.Filter, walk func(string, fs.WalkDirFunc) error) (*ProjectInfo, error) {
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	return gatherProjectInfo(rootPath, func() (*format.DirectoryNode, error) {
+		return buildRootsTree(rootPath, roots, f, walk)
+	})
+}
+
+// GetProjectInfoFS is like GetProjectInfoForRoots for a project held in fsys,
+// such as an opened archive, instead of on disk; rootPath only names the root
+// ("." in fsys). Git info and project metadata are not gathered. Empty roots
+// cover the whole of fsys.
+func GetProjectInfoFS(fsys fs.FS, rootPath string, roots []string, f *filter.Filter) (*ProjectInfo, error) {
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	tree, err := buildRootsTree(rootPath, roots, f, func(start string, fn fs.WalkDirFunc) error {
+		rel, err := filepath.Rel(rootPath, start)
+		if err != nil {
+			return err
+		}
+		return fs.WalkDir(fsys, filepath.ToSlash(rel), func(name string, d fs.DirEntry, err error) error {
+			return fn(filepath.Join(rootPath, filepath.FromSlash(name)), d, err)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error generating directory tree: %w", err)
+	}
+	return &ProjectInfo{DirectoryTree: tree}, nil
+}
+
+func gatherProjectInfo(rootPath string, buildTree func() (*format.DirectoryNode, error)) (*ProjectInfo, error) {
 	info := &ProjectInfo{}
 
 	// Get git info if available
@@ -74,8 +144,16 @@ func GetProjectInfo(rootPath string, f *filter.Filter) (*ProjectInfo, error) {
 		}
 	}
 
+	// Frameworks come from marker files, with or without a recognized language
+	if frameworks := DetectFrameworks(rootPath); len(frameworks) > 0 {
+		if info.Metadata == nil {
+			info.Metadata = &ProjectMetadata{}
+		}
+		info.Metadata.Frameworks = frameworks
+	}
+
 	// Generate directory tree
-	tree, err := generateDirectoryTree(rootPath, f)
+	tree, err := buildTree()
 	if err != nil {
 		return nil, fmt.Errorf("error generating directory tree: %w", err)
 	}
@@ -84,7 +162,40 @@ func GetProjectInfo(rootPath string, f *filter.Filter) (*ProjectInfo, error) {
 	return info, nil
 }
 
+// DetectFrameworks runs the initializer's project detector on root
+func DetectFrameworks(root string) []Framework {
+	detected, err := initializer.NewFileDetector().Detect(root)
+	if err != nil {
+		return nil
+	}
+	var frameworks []Framework
+	for _, pt := range detected {
+		frameworks = append(frameworks, Framework(pt))
+	}
+	return frameworks
+}
+
+// FrameworkNames lists the names of frameworks, as filter.Options takes them
+func FrameworkNames(frameworks []Framework) []string {
+	var names []string
+	for _, framework := range frameworks {
+		names = append(names, framework.Name)
+	}
+	return names
+}
+
 func generateDirectoryTree(root string, f *filter.Filter) (*format.DirectoryNode, error) {
+	return generateRootsTree(root, []string{"."}, f)
+}
+
+// generateRootsTree builds the tree of root restricted to the given relative
+// subdirectories; paths between root and each subdirectory become dir nodes
+func generateRootsTree(root string, roots []string, f *filter.Filter) (*format.DirectoryNode, error) {
+	return buildRootsTree(root, roots, f, filepath.WalkDir)
+}
+
+// buildRootsTree is generateRootsTree with the directory walk supplied
+func buildRootsTree(root string, roots []string, f *filter.Filter, walk func(string, fs.WalkDirFunc) error) (*format.DirectoryNode, error) {
 	rootNode := &format.DirectoryNode{
 		Name: filepath.Base(root),
 		Type: "dir",
@@ -94,7 +205,18 @@ func generateDirectoryTree(root string, f *filter.Filter) (*format.DirectoryNode
 	dirMap := make(map[string]*format.DirectoryNode)
 	dirMap["."] = rootNode
 
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+	for _, sub := range roots {
+		if err := addToTree(root, filepath.Join(root, sub), f, rootNode, dirMap, walk); err != nil {
+			return nil, err
+		}
+	}
+	return rootNode, nil
+}
+
+// addToTree walks start (inside root) and adds the files that pass the filter
+// to the tree, keyed by their path relative to root
+func addToTree(root, start string, f *filter.Filter, rootNode *format.DirectoryNode, dirMap map[string]*format.DirectoryNode, walk func(string, fs.WalkDirFunc) error) error {
+	return walk(start, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
@@ -109,6 +231,15 @@ func generateDirectoryTree(root string, f *filter.Filter) (*format.DirectoryNode
 			return nil
 		}
 
+		// Symlinks reach the tree only through a walk that follows them
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		// promptext's own file cache is never part of the project
+		if d.IsDir() && d.Name() == cache.DirName {
+			return filepath.SkipDir
+		}
+
 		// Check if path should be excluded
 		if f.IsExcluded(rel) {
 			if d.IsDir() {
@@ -159,11 +290,6 @@ func generateDirectoryTree(root string, f *filter.Filter) (*format.DirectoryNode
 
 		return nil
 	})
-
-	if err != nil {
-		return nil, err
-	}
-	return rootNode, nil
 }
 
 func getGitInfo(root string) (*GitInfo, error) {
@@ -181,21 +307,134 @@ func getGitInfo(root string) (*GitInfo, error) {
 		info.Branch = strings.TrimSpace(string(out))
 	}
 
-	// Get latest commit hash
-	cmd = exec.Command("git", "rev-parse", "--short", "HEAD")
+	describeCommit(root, "HEAD", info)
+	return info, nil
+}
+
+// GetGitRefInfo returns git info for ref (a branch, tag, or commit) instead of
+// the checked-out HEAD. Branch holds ref as given.
+func GetGitRefInfo(root, ref string) *GitInfo {
+	info := &GitInfo{Branch: ref}
+	describeCommit(root, ref, info)
+	return info
+}
+
+// describeCommit fills in the hash and message of the commit rev names
+func describeCommit(root, rev string, info *GitInfo) {
+	// Get commit hash
+	cmd := exec.Command("git", "rev-parse", "--short", rev+"^{commit}")
 	cmd.Dir = root
 	if out, err := cmd.Output(); err == nil {
 		info.CommitHash = strings.TrimSpace(string(out))
 	}
 
-	// Get latest commit message
-	cmd = exec.Command("git", "log", "-1", "--pretty=%B")
+	// Get commit message
+	cmd = exec.Command("git", "log", "-1", "--pretty=%B", rev, "--")
 	cmd.Dir = root
 	if out, err := cmd.Output(); err == nil {
 		info.CommitMessage = strings.TrimSpace(string(out))
 	}
+}
 
-	return info, nil
+// CommitInfo holds a single entry of the git history
+type CommitInfo struct {
+	Hash    string
+	Date    string // Commit date, YYYY-MM-DD
+	Subject string
+	Body    string
+}
+
+// Field and record separators for parsing git log output; neither can appear
+// in commit messages
+const (
+	gitFieldSep  = "\x1f"
+	gitRecordSep = "\x1e"
+)
+
+// GetRecentCommits returns the last n commits reachable from ref, or from
+// HEAD when ref is empty, newest first
+func GetRecentCommits(root, ref string, n int) ([]CommitInfo, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if _, err := os.Stat(filepath.Join(root, ".git")); os.IsNotExist(err) {
+		return nil, fmt.Errorf("not a git repository")
+	}
+
+	if ref == "" {
+		ref = "HEAD"
+	}
+	cmd := exec.Command("git", "log", fmt.Sprintf("-n%d", n), "--date=short",
+		"--pretty=format:%h"+gitFieldSep+"%ad"+gitFieldSep+"%s"+gitFieldSep+"%b"+gitRecordSep, ref, "--")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading git log: %w", err)
+	}
+
+	var commits []CommitInfo
+	for _, record := range strings.Split(string(out), gitRecordSep) {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), gitFieldSep, 4)
+		if len(fields) != 4 {
+			continue
+		}
+		commits = append(commits, CommitInfo{
+			Hash:    fields[0],
+			Date:    fields[1],
+			Subject: fields[2],
+			Body:    strings.TrimSpace(fields[3]),
+		})
+	}
+	return commits, nil
+}
+
+// FileActivity is how much a file changed in the recent git history
+type FileActivity struct {
+	Commits    int   // Commits that touched the file
+	LastChange int64 // Commit time of the newest of them, Unix seconds
+}
+
+// GetFileActivity reads the last n commits reachable from ref, or from HEAD
+// when ref is empty, and returns what they did to each file still under
+// root, keyed by slash-separated path relative to root. Commit times are
+// taken from git, so the result doesn't depend on when it is read. It fails
+// when root is not in a git repository.
+func GetFileActivity(root, ref string, n int) (map[string]FileActivity, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	cmd := exec.Command("git", "log", fmt.Sprintf("-n%d", n), "--name-only", "--relative",
+		"--pretty=format:"+gitRecordSep+"%ct", ref, "--", ".")
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading git log: %w", err)
+	}
+
+	activity := make(map[string]FileActivity)
+	for _, record := range strings.Split(string(out), gitRecordSep) {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		var timestamp int64
+		if _, err := fmt.Sscan(lines[0], &timestamp); err != nil {
+			continue
+		}
+		for _, path := range lines[1:] {
+			if path = strings.TrimSpace(path); path == "" {
+				continue
+			}
+			entry := activity[path]
+			entry.Commits++
+			entry.LastChange = max(entry.LastChange, timestamp)
+			activity[path] = entry
+		}
+	}
+	return activity, nil
 }
 
 // Helper functions to reduce cyclomatic complexity
@@ -377,106 +616,6 @@ func getLanguageVersion(root, language string) string {
 	}
 }
 
-func getDependencies(root, filename string) []string {
-	switch filename {
-	case "go.mod":
-		return getGoDependencies(root)
-	case "package.json":
-		return getNodeDependencies(root)
-	case "requirements.txt":
-		return getPythonDependencies(root)
-	case "Cargo.toml":
-		return getRustDependencies(root)
-	case "pom.xml":
-		return getJavaMavenDependencies(root)
-	case "build.gradle":
-		return getJavaGradleDependencies(root)
-	default:
-		return nil
-	}
-}
-
-func getGoVersion(root string) string {
-	content, err := os.ReadFile(filepath.Join(root, "go.mod"))
-	if err != nil {
-		return ""
-	}
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "go ") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "go"))
-		}
-	}
-	return ""
-}
-
-func getNodeVersion(root string) string {
-	content, err := os.ReadFile(filepath.Join(root, "package.json"))
-	if err != nil {
-		return ""
-	}
-	lines := strings.Split(string(content), "\n")
-	var nodeVersion string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "\"node\"") {
-			parts := strings.Split(line, "\"")
-			if len(parts) >= 4 {
-				nodeVersion = strings.TrimSpace(parts[3])
-				return fmt.Sprintf("requires Node %s", nodeVersion)
-			}
-		}
-	}
-	return ""
-}
-
-func getPythonVersion(root string) string {
-	// Try pyproject.toml
-	if content, err := os.ReadFile(filepath.Join(root, "pyproject.toml")); err == nil {
-		lines := strings.Split(string(content), "\n")
-		inToolPoetry := false
-		inDependencies := false
-		for _, line := range lines {
-			line = strings.TrimSpace(line)
-			if line == "[tool.poetry]" {
-				inToolPoetry = true
-				continue
-			}
-			if line == "[tool.poetry.dependencies]" {
-				inDependencies = true
-				continue
-			}
-			if (inToolPoetry || inDependencies) && strings.HasPrefix(line, "[") {
-				inToolPoetry = false
-				inDependencies = false
-				continue
-			}
-			if inDependencies && strings.HasPrefix(line, "python = ") {
-				version := strings.Trim(strings.TrimPrefix(line, "python = "), "\"'^")
-				return version
-			}
-		}
-	}
-	return ""
-}
-
-func getRustVersion(root string) string {
-	content, err := os.ReadFile(filepath.Join(root, "Cargo.toml"))
-	if err != nil {
-		return ""
-	}
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "version = ") {
-			parts := strings.Split(line, "\"")
-			if len(parts) >= 2 {
-				return strings.Trim(parts[1], "\"'")
-			}
-		}
-	}
-	return ""
-}
-
 func getJavaVersion(root string) string {
 	cmd := exec.Command("java", "--version")
 	cmd.Dir = root
@@ -486,301 +625,6 @@ func getJavaVersion(root string) string {
 	return ""
 }
 
-func getGoDependencies(root string) []string {
-	content, err := os.ReadFile(filepath.Join(root, "go.mod"))
-	if err != nil {
-		return nil
-	}
-
-	var deps []string
-	lines := strings.Split(string(content), "\n")
-	inRequire := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Skip empty lines, comments, or lines with just parentheses
-		if line == "" || strings.HasPrefix(line, "//") || line == "(" || line == ")" {
-			continue
-		}
-
-		if strings.HasPrefix(line, "require ") {
-			if strings.Contains(line, "(") {
-				inRequire = true
-				continue
-			}
-			// Single-line require
-			parts := strings.Fields(line)
-			if len(parts) > 1 {
-				deps = append(deps, parts[1])
-			}
-			continue
-		}
-
-		if line == ")" {
-			inRequire = false
-			continue
-		}
-
-		if inRequire {
-			// Inside require block, each line should be a dependency
-			parts := strings.Fields(line)
-			if len(parts) > 0 {
-				deps = append(deps, parts[0])
-			}
-		}
-	}
-	return deps
-}
-
-func getNodeDependencies(root string) []string {
-	content, err := os.ReadFile(filepath.Join(root, "package.json"))
-	if err != nil {
-		return nil
-	}
-
-	var deps []string
-	lines := strings.Split(string(content), "\n")
-	inDeps := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "\"dependencies\"") || strings.Contains(line, "\"devDependencies\"") {
-			inDeps = true
-			continue
-		}
-		if inDeps && strings.Contains(line, "}") {
-			inDeps = false
-			continue
-		}
-		if inDeps && strings.Contains(line, "\":") {
-			dep := strings.Split(line, "\"")[1]
-			deps = append(deps, dep)
-		}
-	}
-	return deps
-}
-
-// getPythonDependencies returns all Python dependencies from various sources
-func getPythonDependencies(root string) []string {
-	depsMap := make(map[string]bool)
-
-	// Collect dependencies from each source
-	getPipDependencies(root, depsMap)
-	getPoetryDependencies(root, depsMap)
-	getPoetryLockDependencies(root, depsMap)
-	getVenvDependencies(root, depsMap)
-
-	// Convert map to slice
-	var allDeps []string
-	for dep := range depsMap {
-		allDeps = append(allDeps, dep)
-	}
-	return allDeps
-}
-
-// getPipDependencies reads dependencies from requirements.txt
-func getPipDependencies(root string, depsMap map[string]bool) {
-	content, err := os.ReadFile(filepath.Join(root, "requirements.txt"))
-	if err != nil {
-		return
-	}
-
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line != "" && !strings.HasPrefix(line, "#") {
-			dep := strings.Split(line, "==")[0]
-			depsMap[dep] = true
-		}
-	}
-}
-
-// getPoetryDependencies reads dependencies from pyproject.toml
-func getPoetryDependencies(root string, depsMap map[string]bool) {
-	content, err := os.ReadFile(filepath.Join(root, "pyproject.toml"))
-	if err != nil {
-		return
-	}
-
-	lines := strings.Split(string(content), "\n")
-	inMainDeps := false
-	inDevDeps := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		switch line {
-		case "[tool.poetry.dependencies]":
-			inMainDeps = true
-			inDevDeps = false
-			continue
-		case "[tool.poetry.group.dev.dependencies]":
-			inMainDeps = false
-			inDevDeps = true
-			continue
-		}
-
-		if (inMainDeps || inDevDeps) && strings.HasPrefix(line, "[") {
-			inMainDeps = false
-			inDevDeps = false
-			continue
-		}
-
-		if (inMainDeps || inDevDeps) && line != "" && !strings.HasPrefix(line, "#") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) > 0 {
-				dep := strings.TrimSpace(parts[0])
-				if dep != "python" { // Skip python version constraint
-					if inDevDeps {
-						depsMap["[dev] "+dep] = true
-					} else {
-						depsMap[dep] = true
-					}
-				}
-			}
-		}
-	}
-}
-
-// getPoetryLockDependencies reads dependencies from poetry.lock
-func getPoetryLockDependencies(root string, depsMap map[string]bool) {
-	content, err := os.ReadFile(filepath.Join(root, "poetry.lock"))
-	if err != nil {
-		return
-	}
-
-	lines := strings.Split(string(content), "\n")
-	inPackage := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.HasPrefix(line, "[[package]]") {
-			inPackage = true
-			continue
-		}
-
-		if inPackage && strings.HasPrefix(line, "name = ") {
-			name := strings.Trim(strings.TrimPrefix(line, "name = "), "\"")
-			depsMap[name] = true
-			inPackage = false
-		}
-	}
-}
-
-// getVenvDependencies reads dependencies from virtual environment
-func getVenvDependencies(root string, depsMap map[string]bool) {
-	venvDirs := []string{".venv", "venv"}
-
-	for _, venvDir := range venvDirs {
-		sitePackages := filepath.Join(root, venvDir, "lib", "python3.*", "site-packages")
-		matches, err := filepath.Glob(sitePackages)
-		if err != nil || len(matches) == 0 {
-			continue
-		}
-
-		entries, err := os.ReadDir(matches[0])
-		if err != nil {
-			continue
-		}
-
-		for _, entry := range entries {
-			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
-				depsMap[entry.Name()] = true
-			}
-		}
-	}
-}
-
-func getRustDependencies(root string) []string {
-	content, err := os.ReadFile(filepath.Join(root, "Cargo.toml"))
-	if err != nil {
-		return nil
-	}
-
-	var deps []string
-	lines := strings.Split(string(content), "\n")
-	inDeps := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "[dependencies]") {
-			inDeps = true
-			continue
-		}
-		if inDeps && strings.HasPrefix(line, "[") {
-			inDeps = false
-			continue
-		}
-		if inDeps && strings.Contains(line, "=") {
-			dep := strings.Split(line, "=")[0]
-			deps = append(deps, strings.TrimSpace(dep))
-		}
-	}
-	return deps
-}
-
-func getJavaMavenDependencies(root string) []string {
-	// This is a simplified version. For a full implementation,
-	// you'd want to use an XML parser
-	content, err := os.ReadFile(filepath.Join(root, "pom.xml"))
-	if err != nil {
-		return nil
-	}
-
-	var deps []string
-	lines := strings.Split(string(content), "\n")
-	inDep := false
-	var currentDep string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "<dependency>") {
-			inDep = true
-			currentDep = ""
-			continue
-		}
-		if strings.Contains(line, "</dependency>") {
-			if currentDep != "" {
-				deps = append(deps, currentDep)
-			}
-			inDep = false
-			continue
-		}
-		if inDep && strings.Contains(line, "<artifactId>") {
-			currentDep = strings.TrimSuffix(strings.TrimPrefix(line, "<artifactId>"), "</artifactId>")
-		}
-	}
-	return deps
-}
-
-func getJavaGradleDependencies(root string) []string {
-	content, err := os.ReadFile(filepath.Join(root, "build.gradle"))
-	if err != nil {
-		return nil
-	}
-
-	var deps []string
-	lines := strings.Split(string(content), "\n")
-	inDeps := false
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "dependencies {") {
-			inDeps = true
-			continue
-		}
-		if inDeps && strings.Contains(line, "}") {
-			inDeps = false
-			continue
-		}
-		if inDeps && strings.Contains(line, "implementation") {
-			parts := strings.Split(line, "'")
-			if len(parts) > 1 {
-				deps = append(deps, parts[1])
-			}
-		}
-	}
-	return deps
-}
-
 // ProjectAnalysis contains categorized project files and their descriptions
 type ProjectAnalysis struct {
 	EntryPoints   map[string]string // Entry points by language pattern
@@ -815,21 +659,32 @@ func getDocDescription(path string) string {
 	return "Documentation"
 }
 
-func isCoreFile(path string) bool {
+// DefaultCoreDirs are the directories whose files are treated as core
+// implementation when no custom core directories are configured
+var DefaultCoreDirs = []string{"internal", "pkg", "src", "lib", "core"}
+
+// IsCoreFile reports whether path lies under one of coreDirs at any depth.
+// Entries may be single names ("domain") or nested paths ("app/services").
+// A nil or empty coreDirs uses DefaultCoreDirs.
+func IsCoreFile(path string, coreDirs []string) bool {
+	// Convert path separators to forward slashes for consistent matching
+	normalizedPath := "/" + filepath.ToSlash(path)
+
 	// Skip node_modules
-	if strings.Contains(path, "node_modules/") {
+	if strings.Contains(normalizedPath, "/node_modules/") {
 		return false
 	}
 
-	// Convert path separators to forward slashes for consistent matching
-	normalizedPath := filepath.ToSlash(path)
-
-	// Only consider files in these special directories as core
-	return strings.Contains(normalizedPath, "internal/") ||
-		strings.Contains(normalizedPath, "pkg/") ||
-		strings.Contains(normalizedPath, "src/") ||
-		strings.Contains(normalizedPath, "lib/") ||
-		strings.Contains(normalizedPath, "core/")
+	if len(coreDirs) == 0 {
+		coreDirs = DefaultCoreDirs
+	}
+	for _, dir := range coreDirs {
+		dir = strings.Trim(filepath.ToSlash(dir), "/")
+		if dir != "" && strings.Contains(normalizedPath, "/"+dir+"/") {
+			return true
+		}
+	}
+	return false
 }
 
 func getCoreDescription(_ string) string {
@@ -837,6 +692,12 @@ func getCoreDescription(_ string) string {
 }
 
 func AnalyzeProject(rootPath string, f *filter.Filter) *ProjectAnalysis {
+	return AnalyzeProjectWithCoreDirs(rootPath, f, nil)
+}
+
+// AnalyzeProjectWithCoreDirs is like AnalyzeProject but classifies files under
+// coreDirs as core implementation instead of DefaultCoreDirs
+func AnalyzeProjectWithCoreDirs(rootPath string, f *filter.Filter, coreDirs []string) *ProjectAnalysis {
 	analysis := &ProjectAnalysis{
 		EntryPoints:   make(map[string]string),
 		ConfigFiles:   make(map[string]string),
@@ -868,7 +729,7 @@ func AnalyzeProject(rootPath string, f *filter.Filter) *ProjectAnalysis {
 			analysis.TestFiles[rel] = "Test suite"
 		case typeInfo.Type == "doc":
 			analysis.Documentation[rel] = getDocDescription(rel)
-		case isCoreFile(rel):
+		case IsCoreFile(rel, coreDirs):
 			analysis.CoreFiles[rel] = getCoreDescription(rel)
 		}
 
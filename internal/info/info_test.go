@@ -2,11 +2,15 @@ package info
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetProjectInfo(t *testing.T) {
@@ -141,7 +145,7 @@ require github.com/stretchr/testify v1.8.0
 		assert.NoError(t, err)
 		assert.Equal(t, "Go", metadata.Language)
 		assert.Equal(t, "1.17", metadata.Version)
-		assert.Contains(t, metadata.Dependencies, "github.com/stretchr/testify")
+		assert.Contains(t, metadata.Dependencies, Dependency{Name: "github.com/stretchr/testify", Version: "v1.8.0", Scope: ScopeDirect})
 	})
 
 	t.Run("Node.js project", func(t *testing.T) {
@@ -163,10 +167,45 @@ require github.com/stretchr/testify v1.8.0
 		metadata, err := getProjectMetadata(tmpDir)
 		assert.NoError(t, err)
 		assert.Equal(t, "JavaScript/Node.js", metadata.Language)
-		assert.Contains(t, metadata.Dependencies, "express")
+		assert.Contains(t, metadata.Dependencies, Dependency{Name: "express", Version: "^4.17.1", Scope: ScopeDirect})
 	})
 }
 
+func TestDetectFrameworks(t *testing.T) {
+	tmpDir := t.TempDir()
+	for name, content := range map[string]string{
+		"package.json":   `{"name": "web"}`,
+		"next.config.js": "module.exports = {}\n",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644))
+	}
+
+	frameworks := DetectFrameworks(tmpDir)
+	assert.Equal(t, []Framework{
+		{Name: "nextjs", Description: "Next.js", Priority: 100},
+		{Name: "node", Description: "Node.js", Priority: 60},
+	}, frameworks)
+	assert.Equal(t, []string{"nextjs", "node"}, FrameworkNames(frameworks))
+
+	info, err := GetProjectInfo(tmpDir, filter.New(filter.Options{}))
+	require.NoError(t, err)
+	require.NotNil(t, info.Metadata)
+	assert.Equal(t, "JavaScript/Node.js", info.Metadata.Language)
+	assert.Equal(t, frameworks, info.Metadata.Frameworks)
+}
+
+func TestGetProjectInfoFrameworksWithoutLanguage(t *testing.T) {
+	// manage.py marks a Django project even without a recognized manifest
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "manage.py"), []byte("import django\n"), 0644))
+
+	info, err := GetProjectInfo(tmpDir, filter.New(filter.Options{}))
+	require.NoError(t, err)
+	require.NotNil(t, info.Metadata)
+	assert.Empty(t, info.Metadata.Language)
+	assert.Equal(t, []Framework{{Name: "django", Description: "Django", Priority: 100}}, info.Metadata.Frameworks)
+}
+
 func TestAnalyzeProject(t *testing.T) {
 	// Create a temporary directory for testing
 	tmpDir, err := os.MkdirTemp("", "analysis-test")
@@ -223,6 +262,38 @@ func TestAnalyzeProject(t *testing.T) {
 	})
 }
 
+func TestAnalyzeProjectWithCoreDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"app/domain/order.go":    "package domain\n",
+		"services/billing.go":    "package services\n",
+		"internal/legacy.go":     "package internal\n",
+		"scripts/release.go":     "package scripts\n",
+		"notdomain/helper.go":    "package notdomain\n",
+		"app/domainx/similar.go": "package domainx\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	f := filter.New(filter.Options{UseDefaultRules: true})
+	analysis := AnalyzeProjectWithCoreDirs(tmpDir, f, []string{"domain", "services/"})
+
+	assert.Contains(t, analysis.CoreFiles, filepath.Join("app", "domain", "order.go"))
+	assert.Contains(t, analysis.CoreFiles, filepath.Join("services", "billing.go"))
+	assert.NotContains(t, analysis.CoreFiles, filepath.Join("internal", "legacy.go"), "custom dirs replace the defaults")
+	assert.NotContains(t, analysis.CoreFiles, filepath.Join("scripts", "release.go"))
+	assert.NotContains(t, analysis.CoreFiles, filepath.Join("notdomain", "helper.go"), "matching is per path segment")
+	assert.NotContains(t, analysis.CoreFiles, filepath.Join("app", "domainx", "similar.go"))
+
+	// Without custom dirs the defaults apply
+	defaults := AnalyzeProject(tmpDir, f)
+	assert.Contains(t, defaults.CoreFiles, filepath.Join("internal", "legacy.go"))
+	assert.NotContains(t, defaults.CoreFiles, filepath.Join("services", "billing.go"))
+}
+
 func TestGetPythonVersion(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -272,36 +343,57 @@ edition = "2021"
 	})
 }
 
+func collectDependencies(get func(string, func(Dependency)), root string) []Dependency {
+	var deps []Dependency
+	get(root, func(dep Dependency) { deps = append(deps, dep) })
+	return deps
+}
+
+func TestParseRequirement(t *testing.T) {
+	tests := []struct {
+		line string
+		want Dependency
+	}{
+		{"requests==2.28.0", Dependency{Name: "requests", Version: "2.28.0"}},
+		{"flask>=2.0.0", Dependency{Name: "flask", Version: ">=2.0.0"}},
+		{"uvicorn[standard] >=0.20,<1.0", Dependency{Name: "uvicorn", Version: ">=0.20,<1.0"}},
+		{"pywin32 (>=300) ; sys_platform == 'win32'", Dependency{Name: "pywin32", Version: ">=300"}},
+		{"rich", Dependency{Name: "rich"}},
+		{"mypkg @ https://example.com/mypkg.zip", Dependency{Name: "mypkg"}},
+	}
+	for _, tt := range tests {
+		dep, ok := parseRequirement(tt.line)
+		assert.True(t, ok, tt.line)
+		assert.Equal(t, tt.want, dep, tt.line)
+	}
+}
+
 func TestGetPipDependencies(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	t.Run("parse requirements.txt", func(t *testing.T) {
 		requirementsContent := `requests==2.28.0
-pytest==7.2.0
+pytest==7.2.0  # pinned for CI
 # This is a comment
 flask>=2.0.0
+-r dev-requirements.txt
 
 django==4.1.0
 `
 		err := os.WriteFile(filepath.Join(tmpDir, "requirements.txt"), []byte(requirementsContent), 0644)
 		assert.NoError(t, err)
 
-		depsMap := make(map[string]bool)
-		getPipDependencies(tmpDir, depsMap)
-
-		assert.True(t, depsMap["requests"])
-		assert.True(t, depsMap["pytest"])
-		// Function splits on "==", so "flask>=2.0.0" (no "==") stays as-is
-		assert.True(t, depsMap["flask>=2.0.0"])
-		assert.True(t, depsMap["django"])
-		assert.Equal(t, 4, len(depsMap))
+		assert.Equal(t, []Dependency{
+			{Name: "requests", Version: "2.28.0", Scope: ScopeDirect},
+			{Name: "pytest", Version: "7.2.0", Scope: ScopeDirect},
+			{Name: "flask", Version: ">=2.0.0", Scope: ScopeDirect},
+			{Name: "django", Version: "4.1.0", Scope: ScopeDirect},
+		}, collectDependencies(getPipDependencies, tmpDir))
 	})
 
 	t.Run("no requirements.txt", func(t *testing.T) {
 		tmpDir2 := t.TempDir()
-		depsMap := make(map[string]bool)
-		getPipDependencies(tmpDir2, depsMap)
-		assert.Equal(t, 0, len(depsMap))
+		assert.Empty(t, collectDependencies(getPipDependencies, tmpDir2))
 	})
 }
 
@@ -312,7 +404,7 @@ func TestGetPoetryDependencies(t *testing.T) {
 		pyprojectContent := `[tool.poetry.dependencies]
 python = "^3.9"
 requests = "^2.28.0"
-flask = ">=2.0.0"
+flask = { version = ">=2.0.0", extras = ["async"] }
 
 [tool.poetry.group.dev.dependencies]
 pytest = "^7.2.0"
@@ -321,21 +413,39 @@ black = "^22.0.0"
 		err := os.WriteFile(filepath.Join(tmpDir, "pyproject.toml"), []byte(pyprojectContent), 0644)
 		assert.NoError(t, err)
 
-		depsMap := make(map[string]bool)
-		getPoetryDependencies(tmpDir, depsMap)
+		// Python version should be excluded
+		assert.Equal(t, []Dependency{
+			{Name: "flask", Version: ">=2.0.0", Scope: ScopeDirect},
+			{Name: "requests", Version: "^2.28.0", Scope: ScopeDirect},
+			{Name: "black", Version: "^22.0.0", Scope: ScopeDev},
+			{Name: "pytest", Version: "^7.2.0", Scope: ScopeDev},
+		}, collectDependencies(getPoetryDependencies, tmpDir))
+	})
+
+	t.Run("parse PEP 621 project table", func(t *testing.T) {
+		tmpDir2 := t.TempDir()
+		pyprojectContent := `[project]
+name = "app"
+requires-python = ">=3.10"
+dependencies = ["httpx>=0.27", "pydantic==2.7.1"]
 
-		assert.True(t, depsMap["requests"])
-		assert.True(t, depsMap["flask"])
-		assert.True(t, depsMap["[dev] pytest"])
-		assert.True(t, depsMap["[dev] black"])
-		assert.False(t, depsMap["python"]) // Python version should be excluded
+[project.optional-dependencies]
+cli = ["typer"]
+`
+		err := os.WriteFile(filepath.Join(tmpDir2, "pyproject.toml"), []byte(pyprojectContent), 0644)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []Dependency{
+			{Name: "httpx", Version: ">=0.27", Scope: ScopeDirect},
+			{Name: "pydantic", Version: "2.7.1", Scope: ScopeDirect},
+			{Name: "typer", Scope: ScopeDirect},
+		}, collectDependencies(getPoetryDependencies, tmpDir2))
+		assert.Equal(t, ">=3.10", getPythonVersion(tmpDir2))
 	})
 
 	t.Run("no pyproject.toml", func(t *testing.T) {
 		tmpDir2 := t.TempDir()
-		depsMap := make(map[string]bool)
-		getPoetryDependencies(tmpDir2, depsMap)
-		assert.Equal(t, 0, len(depsMap))
+		assert.Empty(t, collectDependencies(getPoetryDependencies, tmpDir2))
 	})
 }
 
@@ -358,20 +468,16 @@ version = "3.4"
 		err := os.WriteFile(filepath.Join(tmpDir, "poetry.lock"), []byte(poetryLockContent), 0644)
 		assert.NoError(t, err)
 
-		depsMap := make(map[string]bool)
-		getPoetryLockDependencies(tmpDir, depsMap)
-
-		assert.True(t, depsMap["certifi"])
-		assert.True(t, depsMap["charset-normalizer"])
-		assert.True(t, depsMap["idna"])
-		assert.Equal(t, 3, len(depsMap))
+		assert.Equal(t, []Dependency{
+			{Name: "certifi", Version: "2023.5.7", Scope: ScopeIndirect},
+			{Name: "charset-normalizer", Version: "3.1.0", Scope: ScopeIndirect},
+			{Name: "idna", Version: "3.4", Scope: ScopeIndirect},
+		}, collectDependencies(getPoetryLockDependencies, tmpDir))
 	})
 
 	t.Run("no poetry.lock", func(t *testing.T) {
 		tmpDir2 := t.TempDir()
-		depsMap := make(map[string]bool)
-		getPoetryLockDependencies(tmpDir2, depsMap)
-		assert.Equal(t, 0, len(depsMap))
+		assert.Empty(t, collectDependencies(getPoetryLockDependencies, tmpDir2))
 	})
 }
 
@@ -394,13 +500,24 @@ flask = ">=2.0.0"
 		err = os.WriteFile(filepath.Join(tmpDir, "pyproject.toml"), []byte(pyprojectContent), 0644)
 		assert.NoError(t, err)
 
-		deps := getPythonDependencies(tmpDir)
+		// Locked packages already declared keep their declared scope
+		lockContent := `[[package]]
+name = "Flask"
+version = "2.3.2"
 
-		// Should contain deps from both sources
-		assert.Contains(t, deps, "requests")
-		assert.Contains(t, deps, "pytest")
-		assert.Contains(t, deps, "flask")
-		assert.GreaterOrEqual(t, len(deps), 3)
+[[package]]
+name = "werkzeug"
+version = "2.3.6"
+`
+		err = os.WriteFile(filepath.Join(tmpDir, "poetry.lock"), []byte(lockContent), 0644)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []Dependency{
+			{Name: "requests", Version: "2.28.0", Scope: ScopeDirect},
+			{Name: "pytest", Version: "7.2.0", Scope: ScopeDirect},
+			{Name: "flask", Version: ">=2.0.0", Scope: ScopeDirect},
+			{Name: "werkzeug", Version: "2.3.6", Scope: ScopeIndirect},
+		}, getPythonDependencies(tmpDir))
 	})
 
 	t.Run("no dependency files", func(t *testing.T) {
@@ -422,16 +539,27 @@ version = "0.1.0"
 serde = "1.0"
 tokio = { version = "1.0", features = ["full"] }
 reqwest = "0.11"
+local = { path = "../local" }
+
+[dev-dependencies]
+criterion = "0.5"
+
+[target.'cfg(windows)'.dependencies]
+winapi = "0.3"
 `
 		err := os.WriteFile(filepath.Join(tmpDir, "Cargo.toml"), []byte(cargoContent), 0644)
 		assert.NoError(t, err)
 
-		deps := getRustDependencies(tmpDir)
+		deps := getDependencies(tmpDir, "Cargo.toml")
 
-		assert.Contains(t, deps, "serde")
-		assert.Contains(t, deps, "tokio")
-		assert.Contains(t, deps, "reqwest")
-		assert.GreaterOrEqual(t, len(deps), 3)
+		assert.Equal(t, []Dependency{
+			{Name: "local", Scope: ScopeDirect},
+			{Name: "reqwest", Version: "0.11", Scope: ScopeDirect},
+			{Name: "serde", Version: "1.0", Scope: ScopeDirect},
+			{Name: "tokio", Version: "1.0", Scope: ScopeDirect},
+			{Name: "winapi", Version: "0.3", Scope: ScopeDirect},
+			{Name: "criterion", Version: "0.5", Scope: ScopeDev},
+		}, deps)
 	})
 
 	t.Run("no Cargo.toml", func(t *testing.T) {
@@ -447,14 +575,21 @@ func TestGetJavaMavenDependencies(t *testing.T) {
 	t.Run("parse pom.xml", func(t *testing.T) {
 		pomContent := `<?xml version="1.0" encoding="UTF-8"?>
 <project>
+    <version>1.4.0</version>
+    <properties>
+        <spring.version>3.2.0</spring.version>
+    </properties>
     <dependencies>
         <dependency>
             <groupId>org.springframework.boot</groupId>
             <artifactId>spring-boot-starter-web</artifactId>
+            <version>${spring.version}</version>
         </dependency>
         <dependency>
             <groupId>junit</groupId>
             <artifactId>junit</artifactId>
+            <version>4.13.2</version>
+            <scope>test</scope>
         </dependency>
     </dependencies>
 </project>
@@ -462,11 +597,10 @@ func TestGetJavaMavenDependencies(t *testing.T) {
 		err := os.WriteFile(filepath.Join(tmpDir, "pom.xml"), []byte(pomContent), 0644)
 		assert.NoError(t, err)
 
-		deps := getJavaMavenDependencies(tmpDir)
-
-		assert.Contains(t, deps, "spring-boot-starter-web")
-		assert.Contains(t, deps, "junit")
-		assert.GreaterOrEqual(t, len(deps), 2)
+		assert.Equal(t, []Dependency{
+			{Name: "org.springframework.boot:spring-boot-starter-web", Version: "3.2.0", Scope: ScopeDirect},
+			{Name: "junit:junit", Version: "4.13.2", Scope: ScopeDev},
+		}, getJavaMavenDependencies(tmpDir))
 	})
 
 	t.Run("no pom.xml", func(t *testing.T) {
@@ -486,22 +620,20 @@ func TestGetJavaGradleDependencies(t *testing.T) {
 
 dependencies {
     implementation 'org.springframework.boot:spring-boot-starter-web:2.7.0'
-    implementation 'com.google.guava:guava:31.0-jre'
+    implementation("com.google.guava:guava:31.0-jre")
     testImplementation 'junit:junit:4.13.2'
-    runtimeOnly 'com.h2database:h2:2.1.214'
+    runtimeOnly 'com.h2database:h2'
 }
 `
 		err := os.WriteFile(filepath.Join(tmpDir, "build.gradle"), []byte(gradleContent), 0644)
 		assert.NoError(t, err)
 
-		deps := getJavaGradleDependencies(tmpDir)
-
-		// Function only parses "implementation" lines, returns full dependency string
-		assert.Contains(t, deps, "org.springframework.boot:spring-boot-starter-web:2.7.0")
-		assert.Contains(t, deps, "com.google.guava:guava:31.0-jre")
-		// testImplementation and runtimeOnly are not parsed
-		assert.NotContains(t, deps, "junit:junit:4.13.2")
-		assert.GreaterOrEqual(t, len(deps), 2)
+		assert.Equal(t, []Dependency{
+			{Name: "org.springframework.boot:spring-boot-starter-web", Version: "2.7.0", Scope: ScopeDirect},
+			{Name: "com.google.guava:guava", Version: "31.0-jre", Scope: ScopeDirect},
+			{Name: "junit:junit", Version: "4.13.2", Scope: ScopeDev},
+			{Name: "com.h2database:h2", Scope: ScopeDirect},
+		}, getJavaGradleDependencies(tmpDir))
 	})
 
 	t.Run("no build.gradle", func(t *testing.T) {
@@ -614,14 +746,37 @@ require (
 	github.com/stretchr/testify v1.8.0
 	github.com/gorilla/mux v1.8.0
 )
+
+require github.com/davecgh/go-spew v1.1.1 // indirect
 `
 		err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte(goModContent), 0644)
 		assert.NoError(t, err)
 
 		// getDependencies takes (root, filename) not (root, language)
 		deps := getDependencies(tmpDir, "go.mod")
-		assert.Contains(t, deps, "github.com/stretchr/testify")
-		assert.Contains(t, deps, "github.com/gorilla/mux")
+		assert.Equal(t, []Dependency{
+			{Name: "github.com/gorilla/mux", Version: "v1.8.0", Scope: ScopeDirect},
+			{Name: "github.com/stretchr/testify", Version: "v1.8.0", Scope: ScopeDirect},
+			{Name: "github.com/davecgh/go-spew", Version: "v1.1.1", Scope: ScopeIndirect},
+		}, deps)
+	})
+
+	t.Run("Node dependencies", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		packageContent := `{
+  "dependencies": {"react": "^18.2.0"},
+  "devDependencies": {"vite": "^5.0.0", "react": "^18.2.0"},
+  "peerDependencies": {"react-dom": ">=18"}
+}
+`
+		err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(packageContent), 0644)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []Dependency{
+			{Name: "react", Version: "^18.2.0", Scope: ScopeDirect},
+			{Name: "react-dom", Version: ">=18", Scope: ScopeDirect},
+			{Name: "vite", Version: "^5.0.0", Scope: ScopeDev},
+		}, getDependencies(tmpDir, "package.json"))
 	})
 
 	t.Run("Python dependencies", func(t *testing.T) {
@@ -633,8 +788,26 @@ flask==2.3.0
 		assert.NoError(t, err)
 
 		deps := getDependencies(tmpDir, "requirements.txt")
-		assert.Contains(t, deps, "requests")
-		assert.Contains(t, deps, "flask")
+		assert.Equal(t, []Dependency{
+			{Name: "flask", Version: "2.3.0", Scope: ScopeDirect},
+			{Name: "requests", Version: "2.28.0", Scope: ScopeDirect},
+		}, deps)
+	})
+
+	t.Run("pyproject.toml project", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, "pyproject.toml"), []byte("[project]\ndependencies = [\"httpx\"]\n"), 0644)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []Dependency{{Name: "httpx", Scope: ScopeDirect}}, getDependencies(tmpDir, "pyproject.toml"))
+	})
+
+	t.Run("malformed manifest", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(`{"dependencies": `), 0644)
+		assert.NoError(t, err)
+
+		assert.Empty(t, getDependencies(tmpDir, "package.json"))
 	})
 
 	t.Run("unknown filename", func(t *testing.T) {
@@ -643,3 +816,146 @@ flask==2.3.0
 		assert.Nil(t, deps)
 	})
 }
+
+func TestTreeCacheReusesTreeUntilDirectoryChanges(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f := filter.New(filter.Options{UseDefaultRules: true})
+
+	builds := 0
+	cache := NewTreeCache(func(root string, f *filter.Filter) (*format.DirectoryNode, error) {
+		builds++
+		return generateDirectoryTree(root, f)
+	})
+
+	first, err := cache.Get(tmpDir, f)
+	require.NoError(t, err)
+	second, err := cache.Get(tmpDir, f)
+	require.NoError(t, err)
+	assert.Equal(t, 1, builds, "unchanged directory should not rebuild the tree")
+	assert.Same(t, first, second)
+
+	// Adding a file changes the directory mtime and invalidates the entry
+	newDir := filepath.Join(tmpDir, "pkg")
+	require.NoError(t, os.Mkdir(newDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(newDir, "lib.go"), []byte("package pkg"), 0644))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(tmpDir, future, future))
+
+	third, err := cache.Get(tmpDir, f)
+	require.NoError(t, err)
+	assert.Equal(t, 2, builds)
+	assert.NotSame(t, first, third)
+
+	// A different filter configuration gets its own entry
+	_, err = cache.Get(tmpDir, filter.New(filter.Options{Includes: []string{".go"}}))
+	require.NoError(t, err)
+	assert.Equal(t, 3, builds)
+}
+
+func TestTreeCacheTracksGitExcludes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(home, "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	tmpDir := t.TempDir()
+	out, err := exec.Command("git", "init", "-q", tmpDir).CombinedOutput()
+	require.NoError(t, err, string(out))
+	for _, name := range []string{"main.go", "gen.go", "scratch.go"} {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name), []byte("package x"), 0644))
+	}
+
+	// Each extraction builds its filter afresh, as the processor does
+	newFilter := func() *filter.Filter {
+		return filter.New(filter.Options{UseDefaultRules: true, UseGitIgnore: true, Root: tmpDir})
+	}
+	builds := 0
+	cache := NewTreeCache(func(root string, f *filter.Filter) (*format.DirectoryNode, error) {
+		builds++
+		return generateDirectoryTree(root, f)
+	})
+	treeHas := func(name string) bool {
+		tree, err := cache.Get(tmpDir, newFilter())
+		require.NoError(t, err)
+		for _, child := range tree.Children {
+			if child.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	// Ignore files are edited in place, so push their mtimes past the stamps
+	write := func(path, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		future := time.Now().Add(time.Duration(builds+1) * time.Hour)
+		require.NoError(t, os.Chtimes(path, future, future))
+	}
+
+	require.True(t, treeHas("gen.go"))
+	require.True(t, treeHas("gen.go"))
+	assert.Equal(t, 1, builds, "unchanged ignore files should not rebuild the tree")
+
+	write(filepath.Join(tmpDir, ".git", "info", "exclude"), "gen.go\n")
+	assert.False(t, treeHas("gen.go"), "an edit to .git/info/exclude should rebuild the tree")
+	assert.Equal(t, 2, builds)
+
+	write(filepath.Join(home, "xdg", "git", "ignore"), "scratch.go\n")
+	assert.False(t, treeHas("scratch.go"), "a new core.excludesFile should rebuild the tree")
+	assert.Equal(t, 3, builds)
+}
+
+func TestGetFileActivity(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	commit := func(date string, files ...string) {
+		for _, name := range files {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(date), 0644))
+		}
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", "change"}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(),
+				"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+				"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+				"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+		}
+	}
+
+	_, err := GetFileActivity(dir, "", 10)
+	assert.Error(t, err, "not a git repository yet")
+
+	gitInit := exec.Command("git", "init", "-q")
+	gitInit.Dir = dir
+	require.NoError(t, gitInit.Run())
+	commit("2024-01-01T00:00:00Z", "main.go", "pkg/lib.go")
+	commit("2024-01-08T00:00:00Z", "pkg/lib.go")
+	commit("2024-01-15T00:00:00Z", "pkg/lib.go", "README.md")
+
+	activity, err := GetFileActivity(dir, "", 10)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FileActivity{
+		"main.go":    {Commits: 1, LastChange: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()},
+		"pkg/lib.go": {Commits: 3, LastChange: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC).Unix()},
+		"README.md":  {Commits: 1, LastChange: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC).Unix()},
+	}, activity)
+
+	// Only the last n commits count, and paths are relative to root
+	activity, err = GetFileActivity(filepath.Join(dir, "pkg"), "HEAD", 2)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FileActivity{
+		"lib.go": {Commits: 2, LastChange: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC).Unix()},
+	}, activity)
+}
@@ -0,0 +1,525 @@
+package info
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/BurntSushi/toml"
+	"golang.org/x/mod/modfile"
+)
+
+// Dependency scopes
+const (
+	ScopeDirect   = "direct"   // Required by the project itself
+	ScopeDev      = "dev"      // Required only to develop, build or test it
+	ScopeIndirect = "indirect" // Required by another dependency, or installed without being declared
+)
+
+// Dependency is a package a project depends on
+type Dependency struct {
+	Name    string
+	Version string // Version or constraint as written, e.g. "v1.8.0" or "^4.17.1"; empty when unconstrained
+	Scope   string // ScopeDirect, ScopeDev or ScopeIndirect
+}
+
+func getDependencies(root, filename string) []Dependency {
+	var deps []Dependency
+	switch filename {
+	case "go.mod":
+		deps = getGoDependencies(root)
+	case "package.json":
+		deps = getNodeDependencies(root)
+	case "requirements.txt", "pyproject.toml", "poetry.lock":
+		deps = getPythonDependencies(root)
+	case "Cargo.toml":
+		deps = getRustDependencies(root)
+	case "pom.xml":
+		deps = getJavaMavenDependencies(root)
+	case "build.gradle":
+		deps = getJavaGradleDependencies(root)
+	default:
+		return nil
+	}
+	sortDependencies(deps)
+	return deps
+}
+
+// sortDependencies orders deps direct first, then dev, then indirect, and
+// by name within each scope
+func sortDependencies(deps []Dependency) {
+	rank := map[string]int{ScopeDirect: 0, ScopeDev: 1, ScopeIndirect: 2}
+	sort.SliceStable(deps, func(i, j int) bool {
+		if rank[deps[i].Scope] != rank[deps[j].Scope] {
+			return rank[deps[i].Scope] < rank[deps[j].Scope]
+		}
+		return deps[i].Name < deps[j].Name
+	})
+}
+
+// readManifest decodes the manifest root/name with decode, logging and
+// returning false when it is missing or malformed
+func readManifest(root, name string, decode func(content []byte) error) bool {
+	content, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		return false
+	}
+	if err := decode(content); err != nil {
+		log.Debug("Failed to parse %s: %v", name, err)
+		return false
+	}
+	return true
+}
+
+func readGoMod(root string) *modfile.File {
+	var file *modfile.File
+	readManifest(root, "go.mod", func(content []byte) (err error) {
+		file, err = modfile.ParseLax("go.mod", content, nil)
+		return err
+	})
+	return file
+}
+
+func getGoVersion(root string) string {
+	if file := readGoMod(root); file != nil && file.Go != nil {
+		return file.Go.Version
+	}
+	return ""
+}
+
+// getGoDependencies returns the requirements of go.mod, marked indirect
+// when they carry the "// indirect" comment
+func getGoDependencies(root string) []Dependency {
+	file := readGoMod(root)
+	if file == nil {
+		return nil
+	}
+	var deps []Dependency
+	for _, req := range file.Require {
+		scope := ScopeDirect
+		if req.Indirect {
+			scope = ScopeIndirect
+		}
+		deps = append(deps, Dependency{Name: req.Mod.Path, Version: req.Mod.Version, Scope: scope})
+	}
+	return deps
+}
+
+// packageJSON is the part of package.json describing dependencies
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	Engines              map[string]string `json:"engines"`
+}
+
+func readPackageJSON(root string) *packageJSON {
+	var manifest packageJSON
+	if !readManifest(root, "package.json", func(content []byte) error { return json.Unmarshal(content, &manifest) }) {
+		return nil
+	}
+	return &manifest
+}
+
+func getNodeVersion(root string) string {
+	if manifest := readPackageJSON(root); manifest != nil && manifest.Engines["node"] != "" {
+		return fmt.Sprintf("requires Node %s", manifest.Engines["node"])
+	}
+	return ""
+}
+
+// getNodeDependencies returns the dependencies of package.json. Optional
+// and peer dependencies are direct; a package listed in several sections
+// keeps its first.
+func getNodeDependencies(root string) []Dependency {
+	manifest := readPackageJSON(root)
+	if manifest == nil {
+		return nil
+	}
+	var deps []Dependency
+	seen := make(map[string]bool)
+	for _, section := range []struct {
+		deps  map[string]string
+		scope string
+	}{
+		{manifest.Dependencies, ScopeDirect},
+		{manifest.OptionalDependencies, ScopeDirect},
+		{manifest.PeerDependencies, ScopeDirect},
+		{manifest.DevDependencies, ScopeDev},
+	} {
+		for name, version := range section.deps {
+			if !seen[name] {
+				seen[name] = true
+				deps = append(deps, Dependency{Name: name, Version: version, Scope: section.scope})
+			}
+		}
+	}
+	return deps
+}
+
+// pyproject is the part of pyproject.toml describing dependencies, in the
+// standard [project] table or Poetry's [tool.poetry] tables
+type pyproject struct {
+	Project struct {
+		RequiresPython       string              `toml:"requires-python"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+func readPyproject(root string) *pyproject {
+	var manifest pyproject
+	if !readManifest(root, "pyproject.toml", func(content []byte) error { return toml.Unmarshal(content, &manifest) }) {
+		return nil
+	}
+	return &manifest
+}
+
+func getPythonVersion(root string) string {
+	manifest := readPyproject(root)
+	if manifest == nil {
+		return ""
+	}
+	if version := tomlVersion(manifest.Tool.Poetry.Dependencies["python"]); version != "" {
+		return strings.Trim(version, "^")
+	}
+	return manifest.Project.RequiresPython
+}
+
+// tomlVersion returns the version of a TOML dependency, written either as a
+// string or as a table with a version key
+func tomlVersion(value interface{}) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case map[string]interface{}:
+		version, _ := value["version"].(string)
+		return version
+	}
+	return ""
+}
+
+// getPythonDependencies returns the Python dependencies declared by
+// requirements.txt and pyproject.toml, then those locked in poetry.lock or
+// installed in a virtual environment, which are indirect unless declared
+func getPythonDependencies(root string) []Dependency {
+	var deps []Dependency
+	seen := make(map[string]bool)
+	add := func(dep Dependency) {
+		if key := strings.ToLower(dep.Name); !seen[key] {
+			seen[key] = true
+			deps = append(deps, dep)
+		}
+	}
+
+	// Collect dependencies from each source
+	getPipDependencies(root, add)
+	getPoetryDependencies(root, add)
+	getPoetryLockDependencies(root, add)
+	getVenvDependencies(root, add)
+	return deps
+}
+
+// requirementLine matches a PEP 508 requirement: its name, optional extras,
+// and version specifier, up to any environment marker
+var requirementLine = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*\(?\s*([<>=!~][^;)]*)?`)
+
+// parseRequirement parses a requirement such as "requests==2.28.0" or
+// "flask[async]>=2.0; python_version>'3.8'". An exact "==" pin is reported
+// as the bare version, other specifiers as written.
+func parseRequirement(line string) (Dependency, bool) {
+	m := requirementLine.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return Dependency{}, false
+	}
+	version := strings.TrimSpace(m[2])
+	if pinned := strings.TrimPrefix(version, "=="); pinned != version && !strings.Contains(pinned, ",") {
+		version = strings.TrimSpace(pinned)
+	}
+	return Dependency{Name: m[1], Version: version}, true
+}
+
+// getPipDependencies reads dependencies from requirements.txt, skipping
+// options such as -r and -e
+func getPipDependencies(root string, add func(Dependency)) {
+	content, err := os.ReadFile(filepath.Join(root, "requirements.txt"))
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if dep, ok := parseRequirement(line); ok {
+			dep.Scope = ScopeDirect
+			add(dep)
+		}
+	}
+}
+
+// getPoetryDependencies reads dependencies from pyproject.toml: the
+// [project] dependencies and optional dependencies, and Poetry's
+// dependencies, whose dev dependencies and groups are dev
+func getPoetryDependencies(root string, add func(Dependency)) {
+	manifest := readPyproject(root)
+	if manifest == nil {
+		return
+	}
+
+	requirements := append([]string(nil), manifest.Project.Dependencies...)
+	for _, extra := range sortedKeys(manifest.Project.OptionalDependencies) {
+		requirements = append(requirements, manifest.Project.OptionalDependencies[extra]...)
+	}
+	for _, requirement := range requirements {
+		if dep, ok := parseRequirement(requirement); ok {
+			dep.Scope = ScopeDirect
+			add(dep)
+		}
+	}
+
+	addTable := func(table map[string]interface{}, scope string) {
+		for _, name := range sortedKeys(table) {
+			if name != "python" { // Skip python version constraint
+				add(Dependency{Name: name, Version: tomlVersion(table[name]), Scope: scope})
+			}
+		}
+	}
+	poetry := manifest.Tool.Poetry
+	addTable(poetry.Dependencies, ScopeDirect)
+	addTable(poetry.DevDependencies, ScopeDev)
+	for _, group := range sortedKeys(poetry.Group) {
+		addTable(poetry.Group[group].Dependencies, ScopeDev)
+	}
+}
+
+// getPoetryLockDependencies reads the locked packages of poetry.lock
+func getPoetryLockDependencies(root string, add func(Dependency)) {
+	var lock struct {
+		Package []struct {
+			Name    string `toml:"name"`
+			Version string `toml:"version"`
+		} `toml:"package"`
+	}
+	if !readManifest(root, "poetry.lock", func(content []byte) error { return toml.Unmarshal(content, &lock) }) {
+		return
+	}
+	for _, pkg := range lock.Package {
+		add(Dependency{Name: pkg.Name, Version: pkg.Version, Scope: ScopeIndirect})
+	}
+}
+
+// getVenvDependencies reads the packages installed in a virtual
+// environment from their NAME-VERSION.dist-info directories
+func getVenvDependencies(root string, add func(Dependency)) {
+	venvDirs := []string{".venv", "venv"}
+
+	for _, venvDir := range venvDirs {
+		sitePackages := filepath.Join(root, venvDir, "lib", "python3.*", "site-packages")
+		matches, err := filepath.Glob(sitePackages)
+		if err != nil || len(matches) == 0 {
+			continue
+		}
+
+		entries, err := os.ReadDir(matches[0])
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			base, ok := strings.CutSuffix(entry.Name(), ".dist-info")
+			if !entry.IsDir() || !ok {
+				continue
+			}
+			name, version, _ := strings.Cut(base, "-")
+			add(Dependency{Name: name, Version: version, Scope: ScopeIndirect})
+		}
+	}
+}
+
+// cargoDependencies are the dependency tables of Cargo.toml or of one of
+// its [target.*] tables
+type cargoDependencies struct {
+	Dependencies      map[string]interface{} `toml:"dependencies"`
+	DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+	BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+}
+
+// cargoManifest is the part of Cargo.toml describing the package and its
+// dependencies
+type cargoManifest struct {
+	Package struct {
+		Version interface{} `toml:"version"` // A string, or {workspace = true}
+	} `toml:"package"`
+	cargoDependencies
+	Target map[string]cargoDependencies `toml:"target"`
+}
+
+func readCargoManifest(root string) *cargoManifest {
+	var manifest cargoManifest
+	if !readManifest(root, "Cargo.toml", func(content []byte) error { return toml.Unmarshal(content, &manifest) }) {
+		return nil
+	}
+	return &manifest
+}
+
+func getRustVersion(root string) string {
+	if manifest := readCargoManifest(root); manifest != nil {
+		version, _ := manifest.Package.Version.(string)
+		return version
+	}
+	return ""
+}
+
+// getRustDependencies returns the dependencies of Cargo.toml, including
+// platform-specific ones; dev and build dependencies are dev
+func getRustDependencies(root string) []Dependency {
+	manifest := readCargoManifest(root)
+	if manifest == nil {
+		return nil
+	}
+	var deps []Dependency
+	seen := make(map[string]bool)
+	addTables := func(tables cargoDependencies) {
+		for _, section := range []struct {
+			deps  map[string]interface{}
+			scope string
+		}{
+			{tables.Dependencies, ScopeDirect},
+			{tables.DevDependencies, ScopeDev},
+			{tables.BuildDependencies, ScopeDev},
+		} {
+			for name, value := range section.deps {
+				if !seen[name] {
+					seen[name] = true
+					deps = append(deps, Dependency{Name: name, Version: tomlVersion(value), Scope: section.scope})
+				}
+			}
+		}
+	}
+	addTables(manifest.cargoDependencies)
+	for _, target := range sortedKeys(manifest.Target) {
+		addTables(manifest.Target[target])
+	}
+	return deps
+}
+
+// pomProject is the part of pom.xml describing dependencies
+type pomProject struct {
+	Version string `xml:"version"`
+	Parent  struct {
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies []struct {
+		GroupID    string `xml:"groupId"`
+		ArtifactID string `xml:"artifactId"`
+		Version    string `xml:"version"`
+		Scope      string `xml:"scope"`
+	} `xml:"dependencies>dependency"`
+}
+
+// pomProperty matches a ${property} reference in a pom.xml value
+var pomProperty = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// getJavaMavenDependencies returns the dependencies of pom.xml as
+// groupId:artifactId, resolving versions that refer to its properties.
+// Test-scoped dependencies are dev.
+func getJavaMavenDependencies(root string) []Dependency {
+	var pom pomProject
+	if !readManifest(root, "pom.xml", func(content []byte) error { return xml.Unmarshal(content, &pom) }) {
+		return nil
+	}
+
+	properties := map[string]string{"project.version": pom.Version, "project.parent.version": pom.Parent.Version}
+	if pom.Version == "" {
+		properties["project.version"] = pom.Parent.Version
+	}
+	for _, entry := range pom.Properties.Entries {
+		properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+
+	var deps []Dependency
+	for _, dep := range pom.Dependencies {
+		version := pomProperty.ReplaceAllStringFunc(strings.TrimSpace(dep.Version), func(ref string) string {
+			if value, ok := properties[ref[2:len(ref)-1]]; ok {
+				return value
+			}
+			return ref
+		})
+		scope := ScopeDirect
+		if strings.TrimSpace(dep.Scope) == "test" {
+			scope = ScopeDev
+		}
+		deps = append(deps, Dependency{
+			Name:    strings.TrimSpace(dep.GroupID) + ":" + strings.TrimSpace(dep.ArtifactID),
+			Version: version,
+			Scope:   scope,
+		})
+	}
+	return deps
+}
+
+// gradleDependency matches a dependency declared in a Gradle build script
+// with a "group:name:version" string, capturing the configuration, the
+// group and name, and the version. Gradle scripts are programs rather than
+// data, so declarations built at run time aren't found.
+var gradleDependency = regexp.MustCompile(`^\s*(implementation|api|compileOnly|runtimeOnly|annotationProcessor|kapt|compile|runtime|testImplementation|testCompileOnly|testRuntimeOnly|testCompile|androidTestImplementation)\s*\(?\s*['"]([^'":\s]+:[^'":\s]+)(?::([^'"@\s]+))?[^'"]*['"]`)
+
+// getJavaGradleDependencies returns the dependencies declared in
+// build.gradle; those of test configurations are dev
+func getJavaGradleDependencies(root string) []Dependency {
+	content, err := os.ReadFile(filepath.Join(root, "build.gradle"))
+	if err != nil {
+		return nil
+	}
+
+	var deps []Dependency
+	for _, line := range strings.Split(string(content), "\n") {
+		m := gradleDependency.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		scope := ScopeDirect
+		if strings.HasPrefix(m[1], "test") || strings.HasPrefix(m[1], "androidTest") {
+			scope = ScopeDev
+		}
+		deps = append(deps, Dependency{Name: m[2], Version: m[3], Scope: scope})
+	}
+	return deps
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,128 @@
+package info
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+)
+
+// TreeBuilder builds the directory tree for a project root
+type TreeBuilder func(root string, f *filter.Filter) (*format.DirectoryNode, error)
+
+// TreeCache reuses directory trees across extractions of the same directory.
+// Entries are keyed by root path and filter signature and are invalidated when
+// the modification time of a directory the tree covers, or of a git ignore
+// file the filter read (.gitignore, .git/info/exclude, core.excludesFile),
+// changes. Directory mtimes change whenever entries are added, removed, or
+// renamed, which is exactly what affects the tree; editing file contents does
+// not.
+//
+// The directories are walked once, when a tree is built. A lookup only stats
+// the paths recorded then, without reading any directory.
+//
+// Cached trees are shared between callers and must be treated as read-only.
+type TreeCache struct {
+	mu      sync.Mutex
+	build   TreeBuilder
+	entries map[string]treeCacheEntry
+}
+
+type treeCacheEntry struct {
+	stamps []stamp
+	tree   *format.DirectoryNode
+}
+
+// stamp records the modification time of a path the tree depends on; 0 for
+// a path that did not exist
+type stamp struct {
+	path    string
+	modTime int64
+}
+
+// NewTreeCache creates a tree cache. A nil builder uses the default tree generator.
+func NewTreeCache(build TreeBuilder) *TreeCache {
+	if build == nil {
+		build = generateDirectoryTree
+	}
+	return &TreeCache{
+		build:   build,
+		entries: make(map[string]treeCacheEntry),
+	}
+}
+
+// Get returns the cached tree for root if the directory is unchanged since it
+// was built, and otherwise builds (and caches) a fresh one
+func (c *TreeCache) Get(root string, f *filter.Filter) (*format.DirectoryNode, error) {
+	// A filter reading other ignore files (a new core.excludesFile) is another entry
+	key := root + "\x00" + f.Signature() + "\x00" + strings.Join(f.IgnoreFiles(), "\x00")
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && unchanged(entry.stamps) {
+		log.Debug("Reusing cached directory tree for %s", root)
+		return entry.tree, nil
+	}
+
+	// Stamp before building, so changes made during the build invalidate it
+	stamps := treeStamps(root, f)
+	tree, err := c.build(root, f)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = treeCacheEntry{stamps: stamps, tree: tree}
+	c.mu.Unlock()
+	return tree, nil
+}
+
+// treeStamps records every directory that the tree builder would descend
+// into, and the ignore files f was built from
+func treeStamps(root string, f *filter.Filter) []stamp {
+	var stamps []stamp
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		if rel != "." && f.IsExcluded(rel) {
+			return filepath.SkipDir
+		}
+		stamps = append(stamps, stamp{path: path, modTime: modTime(path)})
+		return nil
+	})
+	// Editing an ignore file in place leaves its directory's mtime alone
+	for _, path := range f.IgnoreFiles() {
+		stamps = append(stamps, stamp{path: path, modTime: modTime(path)})
+	}
+	return stamps
+}
+
+// unchanged reports whether every stamped path still has its recorded mtime
+func unchanged(stamps []stamp) bool {
+	for _, s := range stamps {
+		if modTime(s.path) != s.modTime {
+			return false
+		}
+	}
+	return true
+}
+
+// modTime returns the modification time of path, or 0 if it can't be read
+func modTime(path string) int64 {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return stat.ModTime().UnixNano()
+}
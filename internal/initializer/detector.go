@@ -49,6 +49,7 @@ func (d *FileDetector) Detect(rootPath string) ([]ProjectType, error) {
 	// Define detection rules: file -> project type
 	detectionRules := []struct {
 		files       []string // Any of these files indicates this project type
+		requires    []string // When set, any of these must be present too
 		projectType ProjectType
 	}{
 		// JavaScript/TypeScript frameworks
@@ -205,6 +206,95 @@ func (d *FileDetector) Detect(rootPath string) ([]ProjectType, error) {
 			},
 		},
 
+		// Deno and Bun
+		{
+			files: []string{"deno.json", "deno.jsonc"},
+			projectType: ProjectType{
+				Name:        "deno",
+				Description: "Deno",
+				Priority:    PriorityBuildTool,
+			},
+		},
+		{
+			files: []string{"bunfig.toml", "bun.lockb", "bun.lock"},
+			projectType: ProjectType{
+				Name:        "bun",
+				Description: "Bun",
+				Priority:    PriorityBuildTool,
+			},
+		},
+
+		// Elixir
+		{
+			// Phoenix generates a lib/<app>_web directory for the web layer
+			files:    []string{"lib/*_web"},
+			requires: []string{"mix.exs"},
+			projectType: ProjectType{
+				Name:        "phoenix",
+				Description: "Phoenix",
+				Priority:    PriorityFrameworkSpecific,
+			},
+		},
+		{
+			files: []string{"mix.exs"},
+			projectType: ProjectType{
+				Name:        "elixir",
+				Description: "Elixir",
+				Priority:    PriorityLanguage,
+			},
+		},
+
+		// Kotlin (Gradle Kotlin DSL)
+		{
+			files: []string{"build.gradle.kts", "settings.gradle.kts"},
+			projectType: ProjectType{
+				Name:        "kotlin",
+				Description: "Kotlin",
+				Priority:    PriorityLanguage,
+			},
+		},
+
+		// Swift
+		{
+			files: []string{"Package.swift"},
+			projectType: ProjectType{
+				Name:        "swift",
+				Description: "Swift",
+				Priority:    PriorityLanguage,
+			},
+		},
+
+		// Terraform
+		{
+			files: []string{"*.tf"},
+			projectType: ProjectType{
+				Name:        "terraform",
+				Description: "Terraform",
+				Priority:    PriorityLanguage,
+			},
+		},
+
+		// C/C++
+		{
+			files: []string{"CMakeLists.txt", "meson.build"},
+			projectType: ProjectType{
+				Name:        "cpp",
+				Description: "C/C++",
+				Priority:    PriorityLanguage,
+			},
+		},
+		{
+			// A Makefile alone is common in any language's repo; it means
+			// C/C++ only next to C or C++ sources
+			files:    []string{"Makefile", "makefile", "GNUmakefile"},
+			requires: []string{"*.c", "*.cpp", "*.cc", "*.h", "*.hpp", "src/*.c", "src/*.cpp", "src/*.cc"},
+			projectType: ProjectType{
+				Name:        "cpp",
+				Description: "C/C++",
+				Priority:    PriorityLanguage,
+			},
+		},
+
 		// Node.js (generic - lowest priority)
 		{
 			files: []string{"package.json"},
@@ -218,29 +308,13 @@ func (d *FileDetector) Detect(rootPath string) ([]ProjectType, error) {
 
 	// Check each detection rule
 	for _, rule := range detectionRules {
-		for _, file := range rule.files {
-			// Safety check for empty strings
-			if len(file) == 0 {
-				continue
-			}
-
-			// Check if pattern contains wildcards
-			if strings.Contains(file, "*") {
-				// Use glob matching for wildcard patterns
-				matches, err := filepath.Glob(filepath.Join(rootPath, file))
-				if err == nil && len(matches) > 0 {
-					detected = append(detected, rule.projectType)
-					break
-				}
-			} else {
-				// Regular file existence check
-				filePath := filepath.Join(rootPath, file)
-				if _, err := os.Stat(filePath); err == nil {
-					detected = append(detected, rule.projectType)
-					break
-				}
-			}
+		if !anyExists(rootPath, rule.files) {
+			continue
 		}
+		if len(rule.requires) > 0 && !anyExists(rootPath, rule.requires) {
+			continue
+		}
+		detected = append(detected, rule.projectType)
 	}
 
 	// Sort by priority (highest first) using sort.Slice
@@ -260,3 +334,29 @@ func (d *FileDetector) Detect(rootPath string) ([]ProjectType, error) {
 
 	return unique, nil
 }
+
+// anyExists reports whether any of files, which may be glob patterns, exists
+// in rootPath
+func anyExists(rootPath string, files []string) bool {
+	for _, file := range files {
+		// Safety check for empty strings
+		if len(file) == 0 {
+			continue
+		}
+
+		// Check if pattern contains wildcards
+		if strings.Contains(file, "*") {
+			// Use glob matching for wildcard patterns
+			matches, err := filepath.Glob(filepath.Join(rootPath, file))
+			if err == nil && len(matches) > 0 {
+				return true
+			}
+		} else {
+			// Regular file existence check
+			if _, err := os.Stat(filepath.Join(rootPath, file)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -47,6 +47,51 @@ func TestFileDetector_Detect(t *testing.T) {
 			files:         []string{"artisan", "composer.json"},
 			expectedTypes: []string{"laravel", "php"},
 		},
+		{
+			name:          "Phoenix project",
+			files:         []string{"mix.exs", "lib/shop_web/router.ex"},
+			expectedTypes: []string{"phoenix", "elixir"},
+		},
+		{
+			name:          "Kotlin project",
+			files:         []string{"build.gradle.kts", "src/main/kotlin/App.kt"},
+			expectedTypes: []string{"kotlin", "gradle"},
+		},
+		{
+			name:          "Swift package",
+			files:         []string{"Package.swift"},
+			expectedTypes: []string{"swift"},
+		},
+		{
+			name:          "Terraform module",
+			files:         []string{"main.tf", "variables.tf"},
+			expectedTypes: []string{"terraform"},
+		},
+		{
+			name:          "CMake project",
+			files:         []string{"CMakeLists.txt", "src/main.cpp"},
+			expectedTypes: []string{"cpp"},
+		},
+		{
+			name:          "Makefile with C sources",
+			files:         []string{"Makefile", "main.c"},
+			expectedTypes: []string{"cpp"},
+		},
+		{
+			name:          "Makefile in a Go project",
+			files:         []string{"Makefile", "go.mod"},
+			expectedTypes: []string{"go"},
+		},
+		{
+			name:          "Deno project",
+			files:         []string{"deno.json", "main.ts"},
+			expectedTypes: []string{"deno"},
+		},
+		{
+			name:          "Bun project",
+			files:         []string{"bunfig.toml", "package.json"},
+			expectedTypes: []string{"bun", "node"},
+		},
 		{
 			name:          "Empty project",
 			files:         []string{},
@@ -6,7 +6,7 @@
 //
 // # Key Features
 //
-// - Smart project type detection for 15+ frameworks and languages
+// - Smart project type detection for 25+ frameworks and languages
 // - Framework-specific file extensions and exclusion patterns
 // - Multi-language project support (e.g., Go + Node.js)
 // - Interactive prompts for user preferences
@@ -52,6 +52,15 @@
 //	init := initializer.NewInitializer("/path/to/project", false, true)
 //	err := init.RunQuick() // Uses defaults, excludes tests
 //
+// Scripted initialization, with the prompts' answers given up front:
+//
+//	err := initializer.RunWithOptions(initializer.Options{
+//	    RootPath:     "/path/to/project",
+//	    IncludeTests: true,
+//	    Format:       "jsonl",
+//	    Extensions:   []string{".go", ".proto"},
+//	})
+//
 // Force overwrite:
 //
 //	init := initializer.NewInitializer("/path/to/project", true, false)
@@ -67,6 +76,8 @@
 // - Angular (angular.json)
 // - Svelte (svelte.config.js)
 // - Node.js (package.json)
+// - Deno (deno.json)
+// - Bun (bunfig.toml, bun.lockb)
 //
 // Backend:
 // - Go (go.mod)
@@ -75,12 +86,20 @@
 // - Laravel (artisan)
 // - Ruby/Rails (Gemfile)
 // - PHP (composer.json)
+// - Phoenix (mix.exs with lib/*_web)
+// - Elixir (mix.exs)
 //
 // Systems:
 // - Rust (Cargo.toml)
 // - Java/Maven (pom.xml)
 // - Java/Gradle (build.gradle)
+// - Kotlin (build.gradle.kts)
 // - .NET (*.csproj, *.fsproj, *.vbproj)
+// - Swift (Package.swift)
+// - C/C++ (CMakeLists.txt, meson.build, or a Makefile next to C/C++ sources)
+//
+// Infrastructure:
+// - Terraform (*.tf)
 //
 // # Detection Priority Levels
 //
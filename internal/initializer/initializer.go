@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
 )
 
 // Initializer handles config file initialization
@@ -28,9 +30,27 @@ func NewInitializer(rootPath string, force bool, quiet bool) *Initializer {
 	}
 }
 
-// Run executes the initialization process
-func (i *Initializer) Run() error {
-	// Validate that rootPath exists and is a directory
+// Options are the answers to the initializer's prompts, for generating a
+// config without them (see RunWithOptions)
+type Options struct {
+	RootPath     string
+	Force        bool     // Overwrite an existing config
+	Quiet        bool     // Print nothing on success
+	IncludeTests bool     // Keep test files, the answer to "include test files?"
+	Format       string   // Output format written to the config (default: ptx)
+	Extensions   []string // Replace the detected extensions
+	Excludes     []string // Added to the detected excludes
+}
+
+// RunWithOptions writes the config for opts.RootPath from the detected
+// project types and opts, without prompting. An existing config is an error
+// unless opts.Force is set.
+func RunWithOptions(opts Options) error {
+	return NewInitializer(opts.RootPath, opts.Force, opts.Quiet).run(opts)
+}
+
+// validateRoot checks that rootPath exists and is a directory
+func (i *Initializer) validateRoot() error {
 	info, err := os.Stat(i.rootPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -41,6 +61,14 @@ func (i *Initializer) Run() error {
 	if !info.IsDir() {
 		return fmt.Errorf("path is not a directory: %s", i.rootPath)
 	}
+	return nil
+}
+
+// Run executes the initialization process
+func (i *Initializer) Run() error {
+	if err := i.validateRoot(); err != nil {
+		return err
+	}
 
 	// Check if config already exists
 	configPath := filepath.Join(i.rootPath, ".promptext.yml")
@@ -157,16 +185,18 @@ func (i *Initializer) promptConfirm(question string) bool {
 
 // RunQuick runs initialization with default options (no prompts)
 func (i *Initializer) RunQuick() error {
-	// Validate that rootPath exists and is a directory
-	info, err := os.Stat(i.rootPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("directory does not exist: %s", i.rootPath)
-		}
-		return fmt.Errorf("failed to access directory: %w", err)
+	return i.run(Options{RootPath: i.rootPath, Force: i.force, Quiet: i.quiet})
+}
+
+// run generates the config without prompting, taking the answers from opts
+func (i *Initializer) run(opts Options) error {
+	if err := i.validateRoot(); err != nil {
+		return err
 	}
-	if !info.IsDir() {
-		return fmt.Errorf("path is not a directory: %s", i.rootPath)
+	if opts.Format != "" {
+		if _, err := format.GetFormatter(opts.Format); err != nil {
+			return err
+		}
 	}
 
 	// Check if config already exists
@@ -181,8 +211,17 @@ func (i *Initializer) RunQuick() error {
 		return fmt.Errorf("failed to detect project type: %w", err)
 	}
 
-	// Generate template (exclude tests by default in quick mode)
-	template := i.generator.Generate(projectTypes, false)
+	// Generate template (tests are excluded unless asked for)
+	template := i.generator.Generate(projectTypes, opts.IncludeTests)
+	template.Format = opts.Format
+	if len(opts.Extensions) > 0 {
+		template.Extensions = normalizeExtensions(opts.Extensions)
+	}
+	for _, exc := range opts.Excludes {
+		if !containsString(template.Excludes, exc) {
+			template.Excludes = append(template.Excludes, exc)
+		}
+	}
 	yamlContent := i.generator.GenerateYAML(template)
 
 	// Write to file
@@ -200,3 +239,31 @@ func (i *Initializer) RunQuick() error {
 
 	return nil
 }
+
+// normalizeExtensions trims extensions and gives each a leading dot, so
+// "go" is written as ".go"
+func normalizeExtensions(extensions []string) []string {
+	var normalized []string
+	for _, ext := range extensions {
+		ext = strings.TrimSpace(ext)
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if !containsString(normalized, ext) {
+			normalized = append(normalized, ext)
+		}
+	}
+	return normalized
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
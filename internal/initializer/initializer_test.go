@@ -108,6 +108,59 @@ func TestInitializer_ForceOverwrite(t *testing.T) {
 	}
 }
 
+func TestRunWithOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatalf("Failed to create go.mod: %v", err)
+	}
+
+	err := RunWithOptions(Options{
+		RootPath:     tmpDir,
+		Quiet:        true,
+		IncludeTests: true,
+		Format:       "jsonl",
+		Extensions:   []string{".go", "proto", " .go"},
+		Excludes:     []string{"gen/", "**/vendor/**"},
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(tmpDir, ".promptext.yml"))
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	yaml := string(content)
+
+	if !strings.Contains(yaml, "extensions:\n  - .go\n  - .proto\n\n") {
+		t.Errorf("expected only the given extensions, got:\n%s", yaml)
+	}
+	if !strings.Contains(yaml, "format: jsonl\n") {
+		t.Errorf("expected format jsonl, got:\n%s", yaml)
+	}
+	if !strings.Contains(yaml, `  - "gen/"`) || strings.Count(yaml, "**/vendor/**") != 1 {
+		t.Errorf("expected gen/ added once and vendor not duplicated, got:\n%s", yaml)
+	}
+	if strings.Contains(yaml, "_test.go") {
+		t.Errorf("expected tests kept with IncludeTests, got:\n%s", yaml)
+	}
+
+	// Runs are deterministic, and an existing config needs Force
+	if err := RunWithOptions(Options{RootPath: tmpDir, Quiet: true}); err == nil {
+		t.Error("expected an error for an existing config without Force")
+	}
+	if err := RunWithOptions(Options{RootPath: tmpDir, Quiet: true, Force: true, IncludeTests: true, Format: "jsonl", Extensions: []string{".go", ".proto"}, Excludes: []string{"gen/"}}); err != nil {
+		t.Fatalf("RunWithOptions() with Force error = %v", err)
+	}
+	again, _ := os.ReadFile(filepath.Join(tmpDir, ".promptext.yml"))
+	if string(again) != yaml {
+		t.Errorf("expected the same config from the same answers, got:\n%s", again)
+	}
+
+	if err := RunWithOptions(Options{RootPath: tmpDir, Force: true, Format: "docx"}); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("expected an unsupported format error, got %v", err)
+	}
+}
+
 func TestInitializer_ConfigGeneration(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -414,7 +467,16 @@ func TestInitializer_SecurityInputValidation(t *testing.T) {
 		t.Error("Expected error for excessively long path")
 	}
 
-	// Test that non-absolute paths are handled correctly
+	// Test that non-absolute paths are handled correctly, from the temp dir
+	// so no config is written into the package
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working dir: %v", err)
+	}
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("Failed to change dir: %v", err)
+	}
+	defer os.Chdir(wd)
 	init = NewInitializer(".", false, true)
 	_ = init.RunQuick()
 	// Should not panic, may succeed or fail depending on CWD
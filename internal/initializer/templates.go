@@ -9,6 +9,7 @@ import (
 type ConfigTemplate struct {
 	Extensions []string
 	Excludes   []string
+	Format     string            // Output format; empty means ptx
 	Comments   map[string]string // Key -> comment explaining the setting
 }
 
@@ -81,6 +82,20 @@ func (g *TemplateGenerator) Generate(projectTypes []ProjectType, includeTests bo
 			g.addPHP(template, extSet, excSet, includeTests)
 		case "dotnet":
 			g.addDotNet(template, extSet, excSet, includeTests)
+		case "deno":
+			g.addDeno(template, extSet, excSet, includeTests)
+		case "bun":
+			g.addBun(template, extSet, excSet, includeTests)
+		case "phoenix", "elixir":
+			g.addElixir(template, extSet, excSet, includeTests)
+		case "kotlin":
+			g.addKotlin(template, extSet, excSet, includeTests)
+		case "swift":
+			g.addSwift(template, extSet, excSet, includeTests)
+		case "terraform":
+			g.addTerraform(template, extSet, excSet, includeTests)
+		case "cpp":
+			g.addCpp(template, extSet, excSet, includeTests)
 		}
 	}
 
@@ -563,6 +578,210 @@ func (g *TemplateGenerator) addDotNet(t *ConfigTemplate, extSet, excSet map[stri
 	}
 }
 
+func (g *TemplateGenerator) addDeno(t *ConfigTemplate, extSet, excSet map[string]bool, includeTests bool) {
+	exts := []string{".ts", ".tsx", ".js", ".jsx", ".json", ".jsonc", ".md"}
+	for _, ext := range exts {
+		if !extSet[ext] {
+			t.Extensions = append(t.Extensions, ext)
+			extSet[ext] = true
+		}
+	}
+
+	excludes := []string{
+		"**/node_modules/**",
+		"**/.deno/**",
+		"**/dist/**",
+		"**/coverage/**",
+	}
+	// Deno's test runner picks up *_test and *.test files
+	if !includeTests {
+		excludes = append(excludes, "**/*_test.ts", "**/*_test.tsx", "**/*.test.ts", "**/*.test.js")
+	}
+
+	for _, exc := range excludes {
+		if !excSet[exc] {
+			t.Excludes = append(t.Excludes, exc)
+			excSet[exc] = true
+		}
+	}
+}
+
+func (g *TemplateGenerator) addBun(t *ConfigTemplate, extSet, excSet map[string]bool, includeTests bool) {
+	exts := []string{".ts", ".tsx", ".js", ".jsx", ".json", ".md"}
+	for _, ext := range exts {
+		if !extSet[ext] {
+			t.Extensions = append(t.Extensions, ext)
+			extSet[ext] = true
+		}
+	}
+
+	excludes := []string{
+		"**/node_modules/**",
+		"**/dist/**",
+		"**/build/**",
+		"**/coverage/**",
+	}
+	if !includeTests {
+		excludes = append(excludes, "**/*.test.ts", "**/*.test.tsx", "**/*.test.js", "**/*.spec.ts", "**/*.spec.js", "**/__tests__/**")
+	}
+
+	for _, exc := range excludes {
+		if !excSet[exc] {
+			t.Excludes = append(t.Excludes, exc)
+			excSet[exc] = true
+		}
+	}
+}
+
+func (g *TemplateGenerator) addElixir(t *ConfigTemplate, extSet, excSet map[string]bool, includeTests bool) {
+	exts := []string{".ex", ".exs", ".eex", ".heex", ".leex", ".md"}
+	for _, ext := range exts {
+		if !extSet[ext] {
+			t.Extensions = append(t.Extensions, ext)
+			extSet[ext] = true
+		}
+	}
+
+	excludes := []string{
+		"**/_build/**",
+		"**/deps/**",
+		"**/.elixir_ls/**",
+		"**/priv/static/**",
+		"**/cover/**",
+		"**/erl_crash.dump",
+	}
+	if !includeTests {
+		excludes = append(excludes, "**/test/**", "**/*_test.exs")
+	}
+
+	for _, exc := range excludes {
+		if !excSet[exc] {
+			t.Excludes = append(t.Excludes, exc)
+			excSet[exc] = true
+		}
+	}
+}
+
+func (g *TemplateGenerator) addKotlin(t *ConfigTemplate, extSet, excSet map[string]bool, includeTests bool) {
+	exts := []string{".kt", ".kts", ".java", ".xml", ".properties", ".md"}
+	for _, ext := range exts {
+		if !extSet[ext] {
+			t.Extensions = append(t.Extensions, ext)
+			extSet[ext] = true
+		}
+	}
+
+	excludes := []string{
+		"**/build/**",
+		"**/.gradle/**",
+		"**/.kotlin/**",
+		"**/out/**",
+		"**/*.class",
+		"**/*.jar",
+	}
+	if !includeTests {
+		excludes = append(excludes, "**/src/test/**", "**/src/androidTest/**", "**/*Test.kt")
+	}
+
+	for _, exc := range excludes {
+		if !excSet[exc] {
+			t.Excludes = append(t.Excludes, exc)
+			excSet[exc] = true
+		}
+	}
+}
+
+func (g *TemplateGenerator) addSwift(t *ConfigTemplate, extSet, excSet map[string]bool, includeTests bool) {
+	exts := []string{".swift", ".h", ".m", ".plist", ".md"}
+	for _, ext := range exts {
+		if !extSet[ext] {
+			t.Extensions = append(t.Extensions, ext)
+			extSet[ext] = true
+		}
+	}
+
+	excludes := []string{
+		"**/.build/**",
+		"**/.swiftpm/**",
+		"**/DerivedData/**",
+		"**/Pods/**",
+		"**/Carthage/Build/**",
+		"**/xcuserdata/**",
+	}
+	if !includeTests {
+		excludes = append(excludes, "**/Tests/**", "**/*Tests.swift")
+	}
+
+	for _, exc := range excludes {
+		if !excSet[exc] {
+			t.Excludes = append(t.Excludes, exc)
+			excSet[exc] = true
+		}
+	}
+}
+
+func (g *TemplateGenerator) addTerraform(t *ConfigTemplate, extSet, excSet map[string]bool, includeTests bool) {
+	exts := []string{".tf", ".hcl", ".tpl", ".md"}
+	for _, ext := range exts {
+		if !extSet[ext] {
+			t.Extensions = append(t.Extensions, ext)
+			extSet[ext] = true
+		}
+	}
+
+	excludes := []string{
+		"**/.terraform/**",
+		"**/*.tfstate",
+		"**/*.tfstate.*",
+		"**/*.tfplan",
+		"**/crash.log",
+		"**/*.tfvars", // Variable files often hold credentials
+	}
+	if !includeTests {
+		excludes = append(excludes, "**/*.tftest.hcl", "**/tests/**")
+	}
+
+	for _, exc := range excludes {
+		if !excSet[exc] {
+			t.Excludes = append(t.Excludes, exc)
+			excSet[exc] = true
+		}
+	}
+}
+
+func (g *TemplateGenerator) addCpp(t *ConfigTemplate, extSet, excSet map[string]bool, includeTests bool) {
+	exts := []string{".c", ".h", ".cpp", ".cc", ".cxx", ".hpp", ".hh", ".hxx", ".cmake", ".md"}
+	for _, ext := range exts {
+		if !extSet[ext] {
+			t.Extensions = append(t.Extensions, ext)
+			extSet[ext] = true
+		}
+	}
+
+	excludes := []string{
+		"**/build/**",
+		"**/cmake-build-*/**",
+		"**/CMakeFiles/**",
+		"**/*.o",
+		"**/*.obj",
+		"**/*.a",
+		"**/*.so",
+		"**/*.dylib",
+		"**/*.dll",
+		"**/*.exe",
+	}
+	if !includeTests {
+		excludes = append(excludes, "**/test/**", "**/tests/**", "**/*_test.c", "**/*_test.cpp")
+	}
+
+	for _, exc := range excludes {
+		if !excSet[exc] {
+			t.Excludes = append(t.Excludes, exc)
+			excSet[exc] = true
+		}
+	}
+}
+
 // GenerateYAML creates a YAML string from the template
 func (g *TemplateGenerator) GenerateYAML(template *ConfigTemplate) string {
 	var sb strings.Builder
@@ -600,7 +819,11 @@ func (g *TemplateGenerator) GenerateYAML(template *ConfigTemplate) string {
 	sb.WriteString("use-default-rules: true\n\n")
 
 	sb.WriteString("# Output format: ptx, markdown, xml, jsonl, or toon\n")
-	sb.WriteString("format: ptx\n\n")
+	outputFormat := template.Format
+	if outputFormat == "" {
+		outputFormat = "ptx"
+	}
+	sb.WriteString(fmt.Sprintf("format: %s\n\n", outputFormat))
 
 	sb.WriteString("# Enable verbose output\n")
 	sb.WriteString("verbose: false\n\n")
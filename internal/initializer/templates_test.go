@@ -172,6 +172,7 @@ func TestTemplateGenerator_AllFrameworks(t *testing.T) {
 		"nextjs", "nuxt", "vite", "vue", "angular", "svelte", "node",
 		"go", "django", "flask", "python",
 		"rust", "maven", "gradle", "ruby", "php", "laravel", "dotnet",
+		"deno", "bun", "phoenix", "elixir", "kotlin", "swift", "terraform", "cpp",
 	}
 
 	generator := NewTemplateGenerator()
@@ -246,3 +247,21 @@ func TestTemplateGenerator_Deduplication(t *testing.T) {
 		}
 	}
 }
+
+func TestTemplateGenerator_InfrastructureExcludes(t *testing.T) {
+	generator := NewTemplateGenerator()
+	template := generator.Generate([]ProjectType{{Name: "terraform", Description: "Terraform", Priority: PriorityLanguage}}, true)
+
+	excludes := strings.Join(template.Excludes, " ")
+	for _, want := range []string{"**/.terraform/**", "**/*.tfstate", "**/*.tfvars"} {
+		if !strings.Contains(excludes, want) {
+			t.Errorf("Terraform template should exclude %s, got %v", want, template.Excludes)
+		}
+	}
+	if strings.Contains(excludes, "tftest") {
+		t.Errorf("Terraform tests should be kept with includeTests, got %v", template.Excludes)
+	}
+	if strings.Join(template.Extensions, " ") != ".tf .hcl .tpl .md" {
+		t.Errorf("unexpected Terraform extensions %v", template.Extensions)
+	}
+}
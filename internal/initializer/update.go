@@ -0,0 +1,251 @@
+package initializer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/diff"
+	"gopkg.in/yaml.v3"
+)
+
+// RunUpdate re-runs detection and merges the extensions and excludes it
+// suggests into the existing .promptext.yml. Only missing list items are
+// added, in the style of the items around them; other settings, comments and
+// layout are kept as written. The change is shown as a diff and written after
+// confirmation, or right away when assumeYes is set. A config with no
+// extensions keeps including every extension, and one without test excludes
+// keeps including tests.
+func (i *Initializer) RunUpdate(assumeYes bool) error {
+	if err := i.validateRoot(); err != nil {
+		return err
+	}
+
+	configPath := filepath.Join(i.rootPath, ".promptext.yml")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no .promptext.yml to update (run --init to create one)")
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	content := string(data)
+
+	var existing struct {
+		Extensions []string `yaml:"extensions"`
+		Excludes   []string `yaml:"excludes"`
+	}
+	if err := yaml.Unmarshal(data, &existing); err != nil {
+		return fmt.Errorf("failed to parse .promptext.yml: %w", err)
+	}
+
+	projectTypes, err := i.detector.Detect(i.rootPath)
+	if err != nil {
+		return fmt.Errorf("failed to detect project type: %w", err)
+	}
+	template := i.generator.Generate(projectTypes, !hasTestExcludes(existing.Excludes))
+
+	var extensions, excludes []string
+	if len(existing.Extensions) > 0 {
+		extensions = missing(template.Extensions, existing.Extensions)
+	}
+	excludes = missing(template.Excludes, existing.Excludes)
+	if len(extensions) == 0 && len(excludes) == 0 {
+		if !i.quiet {
+			fmt.Println("✅ .promptext.yml is up to date with the detected project types")
+		}
+		return nil
+	}
+
+	updated, err := addListItems(content, "extensions", extensions)
+	if err != nil {
+		return err
+	}
+	if updated, err = addListItems(updated, "excludes", excludes); err != nil {
+		return err
+	}
+
+	if !assumeYes || !i.quiet {
+		fmt.Print(diff.Unified("a/.promptext.yml", "b/.promptext.yml", content, updated, 3))
+		fmt.Println()
+	}
+	if !assumeYes && !i.promptConfirm("Apply these changes?") {
+		fmt.Println("❌ Update cancelled.")
+		return nil
+	}
+
+	if err := os.WriteFile(configPath, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if !i.quiet {
+		fmt.Printf("✨ Updated .promptext.yml (%d extensions, %d excludes added)\n", len(extensions), len(excludes))
+	}
+	return nil
+}
+
+// hasTestExcludes reports whether any exclude pattern targets test files,
+// taken as the config's answer to "include test files?"
+func hasTestExcludes(excludes []string) bool {
+	for _, exc := range excludes {
+		lower := strings.ToLower(exc)
+		if strings.Contains(lower, "test") || strings.Contains(lower, "spec") {
+			return true
+		}
+	}
+	return false
+}
+
+// missing returns the items of suggested that aren't in existing
+func missing(suggested, existing []string) []string {
+	var items []string
+	for _, item := range suggested {
+		if !containsString(existing, item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// addListItems appends items to the top-level list key of a YAML document,
+// editing the text so everything else stays as written. Items follow the
+// indentation and quoting of the list's last item; a missing key is added at
+// the end. The result is parsed back to check that it holds the items.
+func addListItems(content, key string, items []string) (string, error) {
+	if len(items) == 0 {
+		return content, nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+
+	keyLine := -1
+	for n, line := range lines {
+		if strings.HasPrefix(line, key+":") {
+			keyLine = n
+			break
+		}
+	}
+
+	var updated string
+	switch {
+	case keyLine < 0:
+		var b strings.Builder
+		b.WriteString(content)
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n" + key + ":\n")
+		for _, item := range items {
+			b.WriteString("  - " + quoteItem(item, "") + "\n")
+		}
+		updated = b.String()
+
+	case strings.TrimSpace(strings.TrimPrefix(lines[keyLine], key+":")) != "":
+		// A flow list such as "extensions: [.go, .js]"
+		value := strings.TrimSpace(strings.TrimPrefix(lines[keyLine], key+":"))
+		var current []string
+		if !strings.HasPrefix(value, "[") || yaml.Unmarshal([]byte(value), &current) != nil {
+			return "", fmt.Errorf("can't update %s in .promptext.yml: expected a list", key)
+		}
+		quoted := make([]string, 0, len(current)+len(items))
+		for _, item := range append(current, items...) {
+			quoted = append(quoted, quoteItem(item, ""))
+		}
+		lines[keyLine] = key + ": [" + strings.Join(quoted, ", ") + "]\n"
+		updated = strings.Join(lines, "")
+
+	default:
+		// A block list: items are indented or comment lines up to the next key
+		insertAt, indent, style := keyLine+1, "  ", ""
+		for n := keyLine + 1; n < len(lines); n++ {
+			line := lines[n]
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+				break
+			}
+			if strings.HasPrefix(trimmed, "- ") || trimmed == "-" {
+				insertAt = n + 1
+				indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+				style = ""
+				if value := strings.TrimSpace(strings.TrimPrefix(trimmed, "-")); value != "" {
+					style = value[:1]
+				}
+			}
+		}
+		if !strings.HasSuffix(lines[insertAt-1], "\n") {
+			lines[insertAt-1] += "\n"
+		}
+		var added []string
+		for _, item := range items {
+			added = append(added, indent+"- "+quoteItem(item, style)+"\n")
+		}
+		lines = append(lines[:insertAt], append(added, lines[insertAt:]...)...)
+		updated = strings.Join(lines, "")
+	}
+
+	var check map[string]interface{}
+	if err := yaml.Unmarshal([]byte(updated), &check); err != nil {
+		return "", fmt.Errorf("could not add to %s in .promptext.yml: %w", key, err)
+	}
+	list, _ := check[key].([]interface{})
+	for _, item := range items {
+		found := false
+		for _, value := range list {
+			found = found || value == item
+		}
+		if !found {
+			return "", fmt.Errorf("could not add %q to %s in .promptext.yml", item, key)
+		}
+	}
+	return updated, nil
+}
+
+// quoteItem writes item as a YAML scalar: double quoted when style is `"`
+// or the item needs quoting, single quoted when style is `'`
+func quoteItem(item, style string) string {
+	switch {
+	case style == `"` || needsQuotes(item):
+		return strconv.Quote(item)
+	case style == "'":
+		return "'" + strings.ReplaceAll(item, "'", "''") + "'"
+	}
+	return item
+}
+
+// needsQuotes reports whether item can't be written as a plain YAML scalar
+func needsQuotes(item string) bool {
+	if item == "" || strings.ContainsAny(item[:1], "*&!|>'\"%@`#,[]{}?:- ") {
+		return true
+	}
+	return strings.Contains(item, ": ") || strings.Contains(item, " #")
+}
+
+// AddExcludes adds the patterns not already there to the excludes of
+// rootPath's .promptext.yml, creating the file if needed, and returns the
+// patterns it added. The rest of the file is kept as written.
+func AddExcludes(rootPath string, patterns []string) ([]string, error) {
+	configPath := filepath.Join(rootPath, ".promptext.yml")
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var existing struct {
+		Excludes []string `yaml:"excludes"`
+	}
+	if err := yaml.Unmarshal(data, &existing); err != nil {
+		return nil, fmt.Errorf("failed to parse .promptext.yml: %w", err)
+	}
+	added := missing(patterns, existing.Excludes)
+	if len(added) == 0 {
+		return nil, nil
+	}
+	updated, err := addListItems(string(data), "excludes", added)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(configPath, []byte(strings.TrimPrefix(updated, "\n")), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %w", err)
+	}
+	return added, nil
+}
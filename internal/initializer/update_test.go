@@ -0,0 +1,158 @@
+package initializer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddListItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		items   []string
+		want    string
+	}{
+		{
+			name:    "block list keeps comments and quoting",
+			content: "# Excludes\nexcludes:\n    - 'vendor/'   # ours\n    # generated code\n\ngitignore: true\n",
+			key:     "excludes",
+			items:   []string{"dist/", "**/build/**"},
+			want:    "# Excludes\nexcludes:\n    - 'vendor/'   # ours\n    - 'dist/'\n    - \"**/build/**\"\n    # generated code\n\ngitignore: true\n",
+		},
+		{
+			name:    "flow list",
+			content: "extensions: [.go, .md]\nverbose: false\n",
+			key:     "extensions",
+			items:   []string{".js"},
+			want:    "extensions: [.go, .md, .js]\nverbose: false\n",
+		},
+		{
+			name:    "missing key",
+			content: "verbose: false",
+			key:     "excludes",
+			items:   []string{"node_modules/"},
+			want:    "verbose: false\n\nexcludes:\n  - node_modules/\n",
+		},
+		{
+			name:    "no items",
+			content: "verbose: false\n",
+			key:     "excludes",
+			want:    "verbose: false\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := addListItems(tt.content, tt.key, tt.items)
+			if err != nil {
+				t.Fatalf("addListItems() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("addListItems() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := addListItems("excludes: none\n", "excludes", []string{"dist/"}); err == nil {
+		t.Error("expected an error for a key that isn't a list")
+	}
+}
+
+func TestRunUpdate(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".promptext.yml")
+	if err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatalf("Failed to create go.mod: %v", err)
+	}
+
+	i := NewInitializer(tmpDir, false, true)
+	if err := i.RunUpdate(true); err == nil || !strings.Contains(err.Error(), "--init") {
+		t.Fatalf("expected an error pointing to --init without a config, got %v", err)
+	}
+
+	original := "# team settings\nextensions:\n  - .go\n  - .proto # api\nexcludes:\n  - gen/\n  - \"**/*_test.go\"\nformat: markdown\n"
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte("{}\n"), 0644); err != nil {
+		t.Fatalf("Failed to create package.json: %v", err)
+	}
+
+	if err := i.RunUpdate(true); err != nil {
+		t.Fatalf("RunUpdate() error = %v", err)
+	}
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	yaml := string(content)
+
+	if !strings.HasPrefix(yaml, "# team settings\nextensions:\n  - .go\n  - .proto # api\n") {
+		t.Errorf("expected the existing lines kept, got:\n%s", yaml)
+	}
+	for _, want := range []string{"  - .js\n", `  - "**/node_modules/**"`, `  - "**/*.test.js"`, "  - gen/\n", "format: markdown\n"} {
+		if !strings.Contains(yaml, want) {
+			t.Errorf("expected %q in updated config, got:\n%s", want, yaml)
+		}
+	}
+	if strings.Count(yaml, "**/*_test.go") != 1 {
+		t.Errorf("expected existing excludes not duplicated, got:\n%s", yaml)
+	}
+
+	// A second run finds nothing new
+	if err := i.RunUpdate(true); err != nil {
+		t.Fatalf("RunUpdate() error = %v", err)
+	}
+	again, _ := os.ReadFile(configPath)
+	if string(again) != yaml {
+		t.Errorf("expected an up to date config left alone, got:\n%s", again)
+	}
+}
+
+func TestRunUpdate_KeepsAllExtensionsAndTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, ".promptext.yml")
+	if err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatalf("Failed to create go.mod: %v", err)
+	}
+	if err := os.WriteFile(configPath, []byte("excludes:\n  - vendor/\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if err := NewInitializer(tmpDir, false, true).RunUpdate(true); err != nil {
+		t.Fatalf("RunUpdate() error = %v", err)
+	}
+	content, _ := os.ReadFile(configPath)
+	yaml := string(content)
+	if strings.Contains(yaml, "extensions:") {
+		t.Errorf("expected a config without extensions to keep including all, got:\n%s", yaml)
+	}
+	if strings.Contains(yaml, "_test.go") || !strings.Contains(yaml, `  - "**/vendor/**"`) {
+		t.Errorf("expected build excludes added and tests kept, got:\n%s", yaml)
+	}
+}
+
+func TestAddExcludes(t *testing.T) {
+	dir := t.TempDir()
+	added, err := AddExcludes(dir, []string{"docs/", "*_test.go"})
+	if err != nil {
+		t.Fatalf("AddExcludes failed: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".promptext.yml"))
+	if len(added) != 2 || string(data) != "excludes:\n  - docs/\n  - \"*_test.go\"\n" {
+		t.Fatalf("unexpected new config (added %v):\n%s", added, data)
+	}
+
+	os.WriteFile(filepath.Join(dir, ".promptext.yml"), []byte("# Project config\nexcludes:\n  - vendor/ # third party\nformat: ptx\n"), 0644)
+	added, err = AddExcludes(dir, []string{"vendor/", "testdata/"})
+	if err != nil {
+		t.Fatalf("AddExcludes failed: %v", err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, ".promptext.yml"))
+	if len(added) != 1 || added[0] != "testdata/" || string(data) != "# Project config\nexcludes:\n  - vendor/ # third party\n  - testdata/\nformat: ptx\n" {
+		t.Fatalf("unexpected updated config (added %v):\n%s", added, data)
+	}
+}
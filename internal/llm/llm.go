@@ -0,0 +1,302 @@
+// Package llm streams completions from hosted and local language models
+// (OpenAI, Anthropic, Ollama) for one-shot questions about extracted code.
+// It keeps no credentials of its own: keys and endpoints come from the
+// environment on every call to FromEnv.
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Environment variables FromEnv reads
+const (
+	EnvProvider = "PROMPTEXT_LLM_PROVIDER" // openai, anthropic, or ollama
+	EnvModel    = "PROMPTEXT_LLM_MODEL"    // Model name; each provider has a default
+)
+
+// Providers
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// Default endpoints and models
+const (
+	DefaultOpenAIURL      = "https://api.openai.com/v1"
+	DefaultAnthropicURL   = "https://api.anthropic.com"
+	DefaultOllamaURL      = "http://localhost:11434"
+	DefaultOpenAIModel    = "gpt-4o"
+	DefaultAnthropicModel = "claude-sonnet-4-20250514"
+	DefaultOllamaModel    = "llama3.1"
+)
+
+// anthropicVersion is the Messages API version requests are made against
+const anthropicVersion = "2023-06-01"
+
+// maxAnswerTokens bounds the answer for APIs that require a limit
+const maxAnswerTokens = 4096
+
+// ErrNotConfigured is returned by FromEnv when the environment selects no provider
+var ErrNotConfigured = errors.New("no language model configured")
+
+// Client streams answers from one provider's chat API
+type Client struct {
+	Provider string // ProviderOpenAI, ProviderAnthropic, or ProviderOllama
+	BaseURL  string // API root; empty means the provider's default
+	APIKey   string // Not used by Ollama
+	Model    string
+	HTTP     *http.Client // nil means http.DefaultClient; cancel through the context
+}
+
+// FromEnv configures a client from the environment. PROMPTEXT_LLM_PROVIDER
+// picks the provider; without it, the first of ANTHROPIC_API_KEY,
+// OPENAI_API_KEY, and OLLAMA_HOST that is set does. PROMPTEXT_LLM_MODEL
+// overrides the provider's default model, and OPENAI_BASE_URL,
+// ANTHROPIC_BASE_URL, and OLLAMA_HOST its endpoint.
+func FromEnv(getenv func(string) string) (*Client, error) {
+	provider := strings.ToLower(strings.TrimSpace(getenv(EnvProvider)))
+	if provider == "" {
+		switch {
+		case getenv("ANTHROPIC_API_KEY") != "":
+			provider = ProviderAnthropic
+		case getenv("OPENAI_API_KEY") != "":
+			provider = ProviderOpenAI
+		case getenv("OLLAMA_HOST") != "":
+			provider = ProviderOllama
+		default:
+			return nil, fmt.Errorf("%w: set ANTHROPIC_API_KEY, OPENAI_API_KEY, or OLLAMA_HOST, or %s=ollama for a local server", ErrNotConfigured, EnvProvider)
+		}
+	}
+
+	client := &Client{Provider: provider, Model: getenv(EnvModel)}
+	switch provider {
+	case ProviderOpenAI:
+		client.APIKey, client.BaseURL = getenv("OPENAI_API_KEY"), getenv("OPENAI_BASE_URL")
+	case ProviderAnthropic:
+		client.APIKey, client.BaseURL = getenv("ANTHROPIC_API_KEY"), getenv("ANTHROPIC_BASE_URL")
+		if client.APIKey == "" {
+			return nil, fmt.Errorf("%w: ANTHROPIC_API_KEY is not set", ErrNotConfigured)
+		}
+	case ProviderOllama:
+		client.BaseURL = getenv("OLLAMA_HOST")
+		if client.BaseURL != "" && !strings.Contains(client.BaseURL, "://") {
+			client.BaseURL = "http://" + client.BaseURL // OLLAMA_HOST is often host:port
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown provider %q in %s (use openai, anthropic, or ollama)", ErrNotConfigured, provider, EnvProvider)
+	}
+	if client.Model == "" {
+		client.Model = client.defaultModel()
+	}
+	return client, nil
+}
+
+// String names the provider and model, e.g. "anthropic/claude-sonnet-4-20250514"
+func (c *Client) String() string {
+	return c.Provider + "/" + c.Model
+}
+
+// Stream sends prompt as a single user message and writes the answer to w
+// as it arrives
+func (c *Client) Stream(ctx context.Context, prompt string, w io.Writer) error {
+	messages := []map[string]string{{"role": "user", "content": prompt}}
+	switch c.Provider {
+	case ProviderOpenAI:
+		body := map[string]any{"model": c.Model, "messages": messages, "stream": true}
+		return c.post(ctx, "/chat/completions", body, openAIChunk, w)
+	case ProviderAnthropic:
+		body := map[string]any{"model": c.Model, "messages": messages, "max_tokens": maxAnswerTokens, "stream": true}
+		return c.post(ctx, "/v1/messages", body, anthropicChunk, w)
+	case ProviderOllama:
+		body := map[string]any{"model": c.Model, "messages": messages, "stream": true}
+		return c.post(ctx, "/api/chat", body, ollamaChunk, w)
+	}
+	return fmt.Errorf("unknown provider %q", c.Provider)
+}
+
+// post sends body and feeds each line of the streamed response through
+// decode, writing the text it yields to w. decode returns io.EOF with the
+// last line of the stream.
+func (c *Client) post(ctx context.Context, path string, body any, decode func([]byte) (string, error), w io.Writer) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	url := strings.TrimRight(c.baseURL(), "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch c.Provider {
+	case ProviderAnthropic:
+		req.Header.Set("x-api-key", c.APIKey)
+		req.Header.Set("anthropic-version", anthropicVersion)
+	case ProviderOpenAI:
+		if c.APIKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.APIKey)
+		}
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, strings.TrimSpace(string(detail)))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		text, err := decode(line)
+		if text != "" {
+			if _, err := io.WriteString(w, text); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", url, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	switch c.Provider {
+	case ProviderAnthropic:
+		return DefaultAnthropicURL
+	case ProviderOllama:
+		return DefaultOllamaURL
+	}
+	return DefaultOpenAIURL
+}
+
+func (c *Client) defaultModel() string {
+	switch c.Provider {
+	case ProviderAnthropic:
+		return DefaultAnthropicModel
+	case ProviderOllama:
+		return DefaultOllamaModel
+	}
+	return DefaultOpenAIModel
+}
+
+// sseData returns the payload of a server-sent "data:" line, or nil for
+// other lines (event names, comments)
+func sseData(line []byte) []byte {
+	data, ok := bytes.CutPrefix(line, []byte("data:"))
+	if !ok {
+		return nil
+	}
+	return bytes.TrimSpace(data)
+}
+
+// openAIChunk decodes one line of a chat completions stream
+func openAIChunk(line []byte) (string, error) {
+	data := sseData(line)
+	if data == nil {
+		return "", nil
+	}
+	if string(data) == "[DONE]" {
+		return "", io.EOF
+	}
+	var chunk struct {
+		Choices []struct {
+			Delta struct {
+				Content string `json:"content"`
+			} `json:"delta"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return "", fmt.Errorf("decoding stream: %w", err)
+	}
+	if chunk.Error != nil {
+		return "", errors.New(chunk.Error.Message)
+	}
+	if len(chunk.Choices) == 0 {
+		return "", nil
+	}
+	return chunk.Choices[0].Delta.Content, nil
+}
+
+// anthropicChunk decodes one line of a Messages API stream
+func anthropicChunk(line []byte) (string, error) {
+	data := sseData(line)
+	if data == nil {
+		return "", nil
+	}
+	var event struct {
+		Type  string `json:"type"`
+		Delta struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"delta"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return "", fmt.Errorf("decoding stream: %w", err)
+	}
+	switch event.Type {
+	case "content_block_delta":
+		if event.Delta.Type == "text_delta" {
+			return event.Delta.Text, nil
+		}
+	case "message_stop":
+		return "", io.EOF
+	case "error":
+		return "", errors.New(event.Error.Message)
+	}
+	return "", nil
+}
+
+// ollamaChunk decodes one line of an Ollama /api/chat stream
+func ollamaChunk(line []byte) (string, error) {
+	var chunk struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		Done  bool   `json:"done"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(line, &chunk); err != nil {
+		return "", fmt.Errorf("decoding stream: %w", err)
+	}
+	if chunk.Error != "" {
+		return "", errors.New(chunk.Error)
+	}
+	if chunk.Done {
+		return chunk.Message.Content, io.EOF
+	}
+	return chunk.Message.Content, nil
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestFromEnv(t *testing.T) {
+	client, err := FromEnv(env(map[string]string{"ANTHROPIC_API_KEY": "sk-ant", "OPENAI_API_KEY": "sk"}))
+	require.NoError(t, err)
+	assert.Equal(t, ProviderAnthropic, client.Provider)
+	assert.Equal(t, DefaultAnthropicModel, client.Model)
+	assert.Equal(t, "sk-ant", client.APIKey)
+
+	client, err = FromEnv(env(map[string]string{EnvProvider: "OpenAI", EnvModel: "gpt-4.1", "OPENAI_API_KEY": "sk", "OPENAI_BASE_URL": "http://localhost:1234/v1"}))
+	require.NoError(t, err)
+	assert.Equal(t, "openai/gpt-4.1", client.String())
+	assert.Equal(t, "http://localhost:1234/v1", client.BaseURL)
+
+	client, err = FromEnv(env(map[string]string{"OLLAMA_HOST": "127.0.0.1:11434"}))
+	require.NoError(t, err)
+	assert.Equal(t, ProviderOllama, client.Provider)
+	assert.Equal(t, "http://127.0.0.1:11434", client.BaseURL)
+
+	client, err = FromEnv(env(map[string]string{EnvProvider: "ollama"}))
+	require.NoError(t, err)
+	assert.Equal(t, DefaultOllamaURL, client.baseURL())
+
+	for _, vars := range []map[string]string{
+		{},
+		{EnvProvider: "anthropic"},
+		{EnvProvider: "gemini", "OPENAI_API_KEY": "sk"},
+	} {
+		_, err := FromEnv(env(vars))
+		assert.ErrorIs(t, err, ErrNotConfigured, "%v", vars)
+	}
+}
+
+// streamServer replies to path with the given stream lines and records the
+// request's headers and body
+func streamServer(t *testing.T, path string, lines []string, headers *http.Header, body *map[string]any) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, path, r.URL.Path)
+		*headers = r.Header.Clone()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(body))
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+	}))
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		provider string
+		path     string
+		lines    []string
+	}{
+		{ProviderOpenAI, "/chat/completions", []string{
+			`data: {"choices":[{"delta":{"role":"assistant"}}]}`,
+			`data: {"choices":[{"delta":{"content":"Auth is in "}}]}`,
+			``,
+			`data: {"choices":[{"delta":{"content":"auth/login.go."}}]}`,
+			`data: [DONE]`,
+		}},
+		{ProviderAnthropic, "/v1/messages", []string{
+			`event: message_start`,
+			`data: {"type":"message_start","message":{}}`,
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Auth is in "}}`,
+			`data: {"type":"ping"}`,
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"auth/login.go."}}`,
+			`data: {"type":"message_stop"}`,
+		}},
+		{ProviderOllama, "/api/chat", []string{
+			`{"message":{"role":"assistant","content":"Auth is in "},"done":false}`,
+			`{"message":{"role":"assistant","content":"auth/login.go."},"done":true}`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var headers http.Header
+			var body map[string]any
+			server := streamServer(t, tt.path, tt.lines, &headers, &body)
+			defer server.Close()
+
+			client := &Client{Provider: tt.provider, BaseURL: server.URL, APIKey: "key", Model: "m"}
+			var answer strings.Builder
+			require.NoError(t, client.Stream(context.Background(), "where is auth?", &answer))
+			assert.Equal(t, "Auth is in auth/login.go.", answer.String())
+
+			assert.Equal(t, "m", body["model"])
+			assert.Equal(t, true, body["stream"])
+			messages := body["messages"].([]any)
+			assert.Equal(t, "where is auth?", messages[0].(map[string]any)["content"])
+			switch tt.provider {
+			case ProviderOpenAI:
+				assert.Equal(t, "Bearer key", headers.Get("Authorization"))
+			case ProviderAnthropic:
+				assert.Equal(t, "key", headers.Get("x-api-key"))
+				assert.Equal(t, anthropicVersion, headers.Get("anthropic-version"))
+				assert.NotZero(t, body["max_tokens"])
+			case ProviderOllama:
+				assert.Empty(t, headers.Get("Authorization"))
+			}
+		})
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := &Client{Provider: ProviderOpenAI, BaseURL: server.URL, Model: "m"}
+	err := client.Stream(context.Background(), "q", &strings.Builder{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Contains(t, err.Error(), "invalid api key")
+
+	// Errors reported inside the stream
+	var headers http.Header
+	var body map[string]any
+	stream := streamServer(t, "/v1/messages", []string{
+		`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"partial"}}`,
+		`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+	}, &headers, &body)
+	defer stream.Close()
+
+	client = &Client{Provider: ProviderAnthropic, BaseURL: stream.URL, APIKey: "key", Model: "m"}
+	var answer strings.Builder
+	err = client.Stream(context.Background(), "q", &answer)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Overloaded")
+	assert.Equal(t, "partial", answer.String())
+}
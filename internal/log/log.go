@@ -1,28 +1,145 @@
 package log
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
 	"time"
 )
 
+// Log levels, lowest first. A message is logged when its level is at or
+// above the configured level (see SetLevel).
+const (
+	LevelDebug = slog.LevelDebug
+	LevelInfo  = slog.LevelInfo
+	LevelWarn  = slog.LevelWarn
+	LevelError = slog.LevelError
+)
+
+// Log output formats (see SetFormat)
+const (
+	FormatText = "text" // "15:04:05 [WARN] message" lines, the default
+	FormatJSON = "json" // One JSON object per line, from slog.JSONHandler
+)
+
 var (
-	debugMode  bool
-	quietMode  bool
-	logger     *log.Logger
-	phaseStart time.Time
-	timeMarks  map[string]time.Time
-	debugColor = "\033[0;37m" // Light gray
-	resetColor = "\033[0m"
-	useColors  = false
+	debugMode        bool
+	quietMode        bool
+	level            = LevelWarn // Lowest level logged when debug mode is off
+	handler          slog.Handler
+	handlerOwnsLevel bool      // The handler alone decides which levels it logs (see UseHandler)
+	output           io.Writer = os.Stderr
+	logger           *log.Logger
+	phaseStart       time.Time
+	timeMarks        map[string]time.Time
+	debugColor       = "\033[0;37m" // Light gray
+	resetColor       = "\033[0m"
+	useColors        = false
 )
 
 func init() {
-	logger = log.New(os.Stderr, "", log.Ltime)
+	logger = log.New(output, "", log.Ltime)
 	timeMarks = make(map[string]time.Time)
 }
 
+// ParseLevel returns the level named by s: error, warn (or warning), info,
+// or debug, in any case
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "error":
+		return LevelError, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "info":
+		return LevelInfo, nil
+	case "debug":
+		return LevelDebug, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (want error, warn, info, or debug)", s)
+}
+
+// SetLevel sets the lowest level logged. Debug mode (Enable) logs every
+// level regardless.
+func SetLevel(l slog.Level) {
+	level = l
+}
+
+// GetLevel returns the lowest level logged
+func GetLevel() slog.Level {
+	if debugMode {
+		return LevelDebug
+	}
+	return level
+}
+
+// SetFormat selects text (the default) or JSON log lines on standard error
+func SetFormat(format string) error {
+	switch format {
+	case "", FormatText:
+		handler = nil
+	case FormatJSON:
+		handler = slog.NewJSONHandler(output, &slog.HandlerOptions{Level: LevelDebug})
+	default:
+		return fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+	return nil
+}
+
+// SetHandler sends log records to h instead of standard error, such as a
+// host application's own slog pipeline. Records still pass the configured
+// level first, then h.Enabled. A nil h restores text output.
+func SetHandler(h slog.Handler) {
+	handler = h
+}
+
+// UseHandler sends log records to h, as SetHandler does, but leaves the
+// choice of levels to h.Enabled rather than the configured level. The
+// previous handler comes back when restore is called, so a caller can scope
+// the handler to one operation.
+func UseHandler(h slog.Handler) (restore func()) {
+	previous, previousOwnsLevel := handler, handlerOwnsLevel
+	handler, handlerOwnsLevel = h, true
+	return func() {
+		handler, handlerOwnsLevel = previous, previousOwnsLevel
+	}
+}
+
+// enabled reports whether messages at l are logged
+func enabled(l slog.Level) bool {
+	if l < LevelError && l > LevelDebug && quietMode {
+		return false // Quiet mode keeps only errors, and debug output asked for explicitly
+	}
+	if handler != nil && !handler.Enabled(context.Background(), l) {
+		return false // Skip formatting messages the handler would drop
+	}
+	return handlerOwnsLevel || l >= GetLevel()
+}
+
+// emit logs msg at l: as a text line with prefix, or as a record with attrs
+// when a handler is set
+func emit(l slog.Level, prefix, msg string, attrs ...slog.Attr) {
+	if handler == nil {
+		logger.Print(prefix + msg)
+		return
+	}
+	ctx := context.Background()
+	if !handler.Enabled(ctx, l) {
+		return
+	}
+	record := slog.NewRecord(time.Now(), l, strings.TrimSuffix(msg, "\n"), 0)
+	record.AddAttrs(attrs...)
+	_ = handler.Handle(ctx, record)
+}
+
+// durationMS is a duration in milliseconds, to two decimals
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
+
 // SetColorEnabled enables or disables color output
 func SetColorEnabled(enabled bool) {
 	useColors = enabled
@@ -33,9 +150,10 @@ func Phase(name string) {
 	if debugMode {
 		if !phaseStart.IsZero() {
 			duration := time.Since(phaseStart)
-			logger.Printf("[DEBUG] Phase completed in %.2fms\n", float64(duration.Microseconds())/1000.0)
+			emit(LevelDebug, "[DEBUG] ", fmt.Sprintf("Phase completed in %.2fms\n", durationMS(duration)),
+				slog.Float64("duration_ms", durationMS(duration)))
 		}
-		logger.Printf("[DEBUG] %s%s%s\n", "=== ", name, " ===")
+		emit(LevelDebug, "[DEBUG] ", "=== "+name+" ===\n", slog.String("phase", name))
 		phaseStart = time.Now()
 		timeMarks[name] = phaseStart
 	}
@@ -53,7 +171,8 @@ func EndTimer(operation string) {
 	if debugMode {
 		if start, ok := timeMarks[operation]; ok {
 			duration := time.Since(start)
-			logger.Printf("[DEBUG] %s completed in %.2fms\n", operation, float64(duration.Microseconds())/1000.0)
+			emit(LevelDebug, "[DEBUG] ", fmt.Sprintf("%s completed in %.2fms\n", operation, durationMS(duration)),
+				slog.String("operation", operation), slog.Float64("duration_ms", durationMS(duration)))
 			delete(timeMarks, operation)
 		}
 	}
@@ -81,30 +200,31 @@ func IsQuietMode() bool {
 
 // Debug logs a debug message if debug mode is enabled
 func Debug(format string, v ...interface{}) {
-	if debugMode {
-		if useColors {
-			logger.Printf("%s[DEBUG] %s%s", debugColor, fmt.Sprintf(format, v...), resetColor)
+	if enabled(LevelDebug) {
+		msg := fmt.Sprintf(format, v...)
+		if useColors && handler == nil {
+			emit(LevelDebug, debugColor+"[DEBUG] ", msg+resetColor)
 		} else {
-			logger.Printf("[DEBUG] "+format, v...)
+			emit(LevelDebug, "[DEBUG] ", msg)
 		}
 	}
 }
 
-// Info logs an info message if debug mode is enabled and not in quiet mode
+// Info logs an info message at the info level or below, unless in quiet mode
 func Info(format string, v ...interface{}) {
-	if debugMode && !quietMode {
-		logger.Printf("[INFO] "+format, v...)
+	if enabled(LevelInfo) {
+		emit(LevelInfo, "[INFO] ", fmt.Sprintf(format, v...))
 	}
 }
 
 // Error logs an error message (always shown)
 func Error(format string, v ...interface{}) {
-	logger.Printf("[ERROR] "+format, v...)
+	emit(LevelError, "[ERROR] ", fmt.Sprintf(format, v...))
 }
 
 // Fatal logs an error message and exits
 func Fatal(format string, v ...interface{}) {
-	logger.Printf("[FATAL] "+format, v...)
+	emit(LevelError, "[FATAL] ", fmt.Sprintf(format, v...), slog.Bool("fatal", true))
 	os.Exit(1)
 }
 
@@ -118,10 +238,10 @@ func GetPhaseStart() time.Time {
 	return phaseStart
 }
 
-// Warn logs a warning message (always shown unless in quiet mode)
+// Warn logs a warning message (shown by default, unless in quiet mode)
 func Warn(format string, v ...interface{}) {
-	if !quietMode {
-		logger.Printf("[WARN] "+format, v...)
+	if enabled(LevelWarn) {
+		emit(LevelWarn, "[WARN] ", fmt.Sprintf(format, v...))
 	}
 }
 
@@ -2,8 +2,10 @@ package log
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"log"
+	"log/slog"
 	"os"
 	"strings"
 	"testing"
@@ -44,6 +46,9 @@ func setupTest(t *testing.T) {
 
 	// Reset state
 	debugMode = false
+	quietMode = false
+	level = LevelWarn
+	handler = nil
 	phaseStart = time.Time{}
 	timeMarks = make(map[string]time.Time)
 	useColors = false
@@ -51,6 +56,9 @@ func setupTest(t *testing.T) {
 	// Cleanup after test
 	t.Cleanup(func() {
 		debugMode = false
+		quietMode = false
+		level = LevelWarn
+		handler = nil
 		phaseStart = time.Time{}
 		timeMarks = make(map[string]time.Time)
 		useColors = false
@@ -623,3 +631,98 @@ func TestOutputHonorsQuietMode(t *testing.T) {
 	})
 	assert.Empty(t, hidden)
 }
+
+func TestParseLevel(t *testing.T) {
+	for name, want := range map[string]slog.Level{"error": LevelError, "WARN": LevelWarn, "warning": LevelWarn, "info": LevelInfo, " debug ": LevelDebug} {
+		got, err := ParseLevel(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestSetLevel(t *testing.T) {
+	setupTest(t)
+
+	SetLevel(LevelInfo)
+	output := captureLogOutput(t, func() {
+		Debug("hidden debug")
+		Info("shown info")
+		Warn("shown warning")
+	})
+	assert.NotContains(t, output, "hidden debug")
+	assert.Contains(t, output, "[INFO] shown info")
+	assert.Contains(t, output, "[WARN] shown warning")
+
+	SetLevel(LevelError)
+	output = captureLogOutput(t, func() {
+		Warn("hidden warning")
+		Error("shown error")
+	})
+	assert.NotContains(t, output, "hidden warning")
+	assert.Contains(t, output, "[ERROR] shown error")
+
+	// Debug mode logs every level whatever the level is set to
+	Enable()
+	assert.Equal(t, LevelDebug, GetLevel())
+	output = captureLogOutput(t, func() {
+		Debug("shown debug")
+	})
+	assert.Contains(t, output, "[DEBUG] shown debug")
+}
+
+func TestSetFormatJSON(t *testing.T) {
+	setupTest(t)
+
+	var buf bytes.Buffer
+	originalOutput := output
+	output = &buf
+	t.Cleanup(func() { output = originalOutput })
+
+	require.NoError(t, SetFormat(FormatJSON))
+	Enable()
+	SetColorEnabled(true)
+	Warn("cache %s is stale", "index")
+	StartTimer("walk")
+	EndTimer("walk")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var warning, timing map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &warning))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &timing))
+	assert.Equal(t, "WARN", warning["level"])
+	assert.Equal(t, "cache index is stale", warning["msg"])
+	assert.Equal(t, "DEBUG", timing["level"])
+	assert.Equal(t, "walk", timing["operation"])
+	assert.Contains(t, timing, "duration_ms")
+	assert.NotContains(t, buf.String(), "\\u001b", "no color codes in JSON")
+
+	require.NoError(t, SetFormat(FormatText))
+	assert.Nil(t, handler)
+	assert.Error(t, SetFormat("xml"))
+}
+
+func TestSetHandler(t *testing.T) {
+	setupTest(t)
+
+	var buf bytes.Buffer
+	SetHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: LevelWarn}))
+	SetLevel(LevelDebug)
+	text := captureLogOutput(t, func() {
+		Debug("dropped by the handler")
+		Warn("routed %d", 1)
+	})
+	assert.Empty(t, text, "nothing goes to the default logger")
+	assert.NotContains(t, buf.String(), "dropped by the handler")
+	assert.Contains(t, buf.String(), "level=WARN msg=\"routed 1\"")
+
+	// Quiet mode still drops warnings before they reach the handler
+	buf.Reset()
+	SetQuiet(true)
+	Warn("quiet warning")
+	Error("loud error")
+	assert.NotContains(t, buf.String(), "quiet warning")
+	assert.Contains(t, buf.String(), "loud error")
+}
@@ -0,0 +1,8 @@
+// Package longpath lets promptext read and write paths longer than Windows'
+// 260-character MAX_PATH limit, which deep node_modules or generated trees
+// easily exceed. Elsewhere paths have no such limit and pass through as is.
+package longpath
+
+// maxPath is the longest path Windows opens without the \\?\ prefix: MAX_PATH
+// less the 12 characters reserved for an 8.3 file name, which directories need
+const maxPath = 248
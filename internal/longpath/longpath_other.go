@@ -0,0 +1,8 @@
+//go:build !windows
+
+package longpath
+
+// Fix returns path unchanged: only Windows limits path length
+func Fix(path string) string {
+	return path
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package longpath
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFix(t *testing.T) {
+	long := "/project/" + strings.Repeat("deep/", 60) + "main.go"
+	if got := Fix(long); got != long {
+		t.Errorf("Fix(%q) = %q, want it unchanged", long, got)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package longpath
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Fix returns path in the extended-length \\?\ form Windows opens at any
+// length, when it is too long to open otherwise, and unchanged when not.
+// Extended-length paths are used verbatim, so the result is made absolute and
+// cleaned with backslash separators first; UNC shares become \\?\UNC\.
+func Fix(path string) string {
+	if strings.HasPrefix(path, `\\?\`) || (filepath.IsAbs(path) && len(path) < maxPath) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil || len(abs) < maxPath {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,53 @@
+//go:build windows
+
+package longpath
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFix(t *testing.T) {
+	short := `C:\project\main.go`
+	if got := Fix(short); got != short {
+		t.Errorf("Fix(%q) = %q, want it unchanged", short, got)
+	}
+
+	long := `C:\project\` + strings.Repeat(`deep\`, 60) + "main.go"
+	if got, want := Fix(long), `\\?\`+long; got != want {
+		t.Errorf("Fix(long) = %q, want %q", got, want)
+	}
+	if got := Fix(`\\?\` + long); got != `\\?\`+long {
+		t.Errorf("Fix should leave an extended-length path alone, got %q", got)
+	}
+
+	unc := `\\server\share\` + strings.Repeat(`deep\`, 60) + "main.go"
+	if got, want := Fix(unc), `\\?\UNC\server\share\`+strings.Repeat(`deep\`, 60)+"main.go"; got != want {
+		t.Errorf("Fix(unc) = %q, want %q", got, want)
+	}
+
+	// Forward slashes aren't read in the extended form, so they are converted
+	mixed := "C:/project/" + strings.Repeat("deep/", 60) + "main.go"
+	if got := Fix(mixed); strings.Contains(got, "/") {
+		t.Errorf("Fix(%q) = %q, should use backslashes", mixed, got)
+	}
+}
+
+func TestFixReadsLongPaths(t *testing.T) {
+	dir := t.TempDir()
+	for len(dir) < 300 {
+		dir = filepath.Join(dir, strings.Repeat("d", 40))
+	}
+	if err := os.MkdirAll(Fix(dir), 0755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(Fix(path), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if data, err := os.ReadFile(Fix(path)); err != nil || string(data) != "package main\n" {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+}
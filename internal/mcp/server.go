@@ -0,0 +1,174 @@
+// Package mcp serves promptext over the Model Context Protocol, so MCP clients
+// (Claude Desktop, IDE agents) can request code context through tool calls.
+//
+// The server speaks JSON-RPC 2.0 over newline-delimited stdio, as specified by
+// the MCP stdio transport.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/1broseidon/promptext/internal/service"
+)
+
+// ProtocolVersion is the MCP revision this server implements
+const ProtocolVersion = "2024-11-05"
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server answers MCP requests for projects under a root directory
+type Server struct {
+	svc     *service.Service
+	version string
+	tools   []tool
+
+	mu  sync.Mutex // serializes writes
+	out io.Writer
+}
+
+// NewServer creates a server whose tools may read directories under root.
+// version is reported to clients as the server version.
+func NewServer(root, version string) *Server {
+	s := &Server{svc: service.New(root), version: version}
+	s.tools = s.defaultTools()
+	return s
+}
+
+// Serve reads requests from r and writes responses to w until r is exhausted
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+			continue
+		}
+		if resp := s.handle(req); resp != nil {
+			s.write(*resp)
+		}
+	}
+	return scanner.Err()
+}
+
+// handle dispatches one request; notifications (no id) get no response
+func (s *Server) handle(req request) *response {
+	result, rpcErr := s.dispatch(req)
+	if len(req.ID) == 0 {
+		return nil
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+}
+
+func (s *Server) dispatch(req request) (interface{}, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "jsonrpc must be \"2.0\""}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{}},
+			"serverInfo":      map[string]interface{}{"name": "promptext", "version": s.version},
+		}, nil
+
+	case "ping":
+		return map[string]interface{}{}, nil
+
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+
+	case "tools/list":
+		list := make([]map[string]interface{}, len(s.tools))
+		for i, t := range s.tools {
+			list[i] = map[string]interface{}{
+				"name":        t.name,
+				"description": t.description,
+				"inputSchema": t.schema,
+			}
+		}
+		return map[string]interface{}{"tools": list}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		for _, t := range s.tools {
+			if t.name == params.Name {
+				return s.callTool(t, params.Arguments), nil
+			}
+		}
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+	}
+
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+}
+
+// callTool runs a tool and wraps its output as MCP text content. Tool failures
+// are reported in the result (isError) so the model can see and react to them.
+func (s *Server) callTool(t tool, args json.RawMessage) map[string]interface{} {
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	text, err := t.run(args)
+	if err != nil {
+		return map[string]interface{}{
+			"content": []map[string]interface{}{{"type": "text", "text": err.Error()}},
+			"isError": true,
+		}
+	}
+	return map[string]interface{}{
+		"content": []map[string]interface{}{{"type": "text", "text": text}},
+	}
+}
+
+func (s *Server) write(resp response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &rpcError{Code: codeInvalidRequest, Message: err.Error()}})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
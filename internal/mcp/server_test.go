@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip sends each request line to a fresh server and decodes the responses
+func roundTrip(t *testing.T, root string, requests ...string) []map[string]interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, NewServer(root, "test").Serve(strings.NewReader(strings.Join(requests, "\n")+"\n"), &out))
+
+	var responses []map[string]interface{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &resp))
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// toolText returns the text content of a tools/call result
+func toolText(t *testing.T, resp map[string]interface{}) string {
+	t.Helper()
+	result, ok := resp["result"].(map[string]interface{})
+	require.True(t, ok, "expected a result, got %v", resp)
+	content := result["content"].([]interface{})
+	require.Len(t, content, 1)
+	return content[0].(map[string]interface{})["text"].(string)
+}
+
+func TestServerProtocol(t *testing.T) {
+	responses := roundTrip(t, t.TempDir(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":"x","method":"resources/list"}`,
+		`not json`,
+	)
+	require.Len(t, responses, 4, "notifications must not be answered")
+
+	initResult := responses[0]["result"].(map[string]interface{})
+	assert.Equal(t, ProtocolVersion, initResult["protocolVersion"])
+	assert.Equal(t, "promptext", initResult["serverInfo"].(map[string]interface{})["name"])
+
+	var names []string
+	for _, tool := range responses[1]["result"].(map[string]interface{})["tools"].([]interface{}) {
+		names = append(names, tool.(map[string]interface{})["name"].(string))
+	}
+	assert.Equal(t, []string{"extract_context", "search_relevant_files", "project_info"}, names)
+
+	assert.Equal(t, "x", responses[2]["id"])
+	assert.Equal(t, float64(codeMethodNotFound), responses[2]["error"].(map[string]interface{})["code"])
+	assert.Equal(t, float64(codeParseError), responses[3]["error"].(map[string]interface{})["code"])
+}
+
+func TestServerTools(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "auth"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/demo\n\ngo 1.22\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "auth", "login.go"), []byte("package auth\n\n// Login checks a password\nfunc Login() {}\n"), 0644))
+
+	responses := roundTrip(t, root,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"extract_context","arguments":{"extensions":[".go"],"format":"markdown"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_relevant_files","arguments":{"keywords":["login"]}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"project_info"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"extract_context","arguments":{"directory":"../"}}}`,
+	)
+	require.Len(t, responses, 4)
+
+	extracted := toolText(t, responses[0])
+	assert.Contains(t, extracted, "func Login()")
+	assert.Contains(t, extracted, "func main()")
+
+	var found []service.SearchResult
+	require.NoError(t, json.Unmarshal([]byte(toolText(t, responses[1])), &found))
+	require.NotEmpty(t, found)
+	assert.Equal(t, "auth/login.go", found[0].Path)
+
+	var projectInfo map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(toolText(t, responses[2])), &projectInfo))
+	assert.Equal(t, "Go", projectInfo["language"])
+	assert.Contains(t, projectInfo["tree"], "login.go")
+
+	escaped := responses[3]["result"].(map[string]interface{})
+	assert.Equal(t, true, escaped["isError"])
+	assert.Contains(t, toolText(t, responses[3]), "outside the server root")
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/1broseidon/promptext/internal/service"
+)
+
+// tool is a callable MCP tool with a JSON Schema for its arguments
+type tool struct {
+	name        string
+	description string
+	schema      map[string]interface{}
+	run         func(args json.RawMessage) (string, error)
+}
+
+// Argument schema fragments shared by the tools
+var (
+	directoryProp  = map[string]interface{}{"type": "string", "description": "Project directory, relative to the server root (default: the root itself)"}
+	extensionsProp = map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "File extensions to include, e.g. [\".go\", \".md\"]"}
+	excludesProp   = map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Gitignore-style patterns to exclude"}
+)
+
+func (s *Server) defaultTools() []tool {
+	return []tool{
+		{
+			name:        "extract_context",
+			description: "Extract project source code as AI-ready context, optionally filtered by extension, path globs, relevance keywords and a token budget.",
+			schema: objectSchema(map[string]interface{}{
+				"directory":  directoryProp,
+				"extensions": extensionsProp,
+				"excludes":   excludesProp,
+				"includes":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Gitignore-style path globs to restrict extraction to"},
+				"keywords":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Relevance keywords used to prioritize files"},
+				"max_tokens": map[string]interface{}{"type": "integer", "description": "Token budget for file content"},
+				"format":     map[string]interface{}{"type": "string", "enum": service.Formats, "description": "Output format (default: ptx)"},
+			}),
+			run: s.extractContext,
+		},
+		{
+			name:        "search_relevant_files",
+			description: "Rank project files by relevance to keywords and return the best matches with their scores and token counts.",
+			schema: objectSchema(map[string]interface{}{
+				"directory":  directoryProp,
+				"keywords":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "Keywords to search for"},
+				"extensions": extensionsProp,
+				"excludes":   excludesProp,
+				"limit":      map[string]interface{}{"type": "integer", "description": fmt.Sprintf("Maximum number of files to return (default: %d)", service.DefaultSearchLimit)},
+			}, "keywords"),
+			run: s.searchRelevantFiles,
+		},
+		{
+			name:        "project_info",
+			description: "Describe the project: language, version, dependencies, git state and directory tree.",
+			schema: objectSchema(map[string]interface{}{
+				"directory": directoryProp,
+			}),
+			run: s.projectInfo,
+		},
+	}
+}
+
+func objectSchema(props map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (s *Server) extractContext(raw json.RawMessage) (string, error) {
+	var req service.ExtractRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	result, err := s.svc.Extract(req)
+	if err != nil {
+		return "", err
+	}
+	return result.FormattedOutput, nil
+}
+
+func (s *Server) searchRelevantFiles(raw json.RawMessage) (string, error) {
+	var req service.SearchRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	results, err := s.svc.Search(req)
+	if err != nil {
+		return "", err
+	}
+	return marshalText(results)
+}
+
+func (s *Server) projectInfo(raw json.RawMessage) (string, error) {
+	var args struct {
+		Directory string `json:"directory"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	summary, err := s.svc.ProjectInfo(args.Directory)
+	if err != nil {
+		return "", err
+	}
+	return marshalText(summary)
+}
+
+func marshalText(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,278 @@
+// Package osv looks up the known vulnerabilities of dependencies in the OSV
+// database (https://osv.dev), either through its API or from a JSON report
+// written by osv-scanner.
+package osv
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvURL overrides the API root FromEnv configures, e.g. for a mirror
+const EnvURL = "PROMPTEXT_OSV_URL"
+
+// DefaultURL is the root of the public OSV API
+const DefaultURL = "https://api.osv.dev"
+
+// maxBatch is the most queries the API accepts in one batch request
+const maxBatch = 1000
+
+// maxSummary bounds a summary taken from a record's details
+const maxSummary = 200
+
+// Package is a dependency at an exact version
+type Package struct {
+	Name      string
+	Version   string
+	Ecosystem string // OSV ecosystem, e.g. "Go", "npm", "PyPI", "crates.io" or "Maven"
+}
+
+// Vulnerability is a known vulnerability affecting a package version
+type Vulnerability struct {
+	ID       string   // OSV identifier, e.g. "GHSA-vh95-rmgr-6w4m" or "GO-2024-2611"
+	Aliases  []string // Other identifiers, such as CVE numbers
+	Package  string
+	Version  string
+	Severity string // Label such as "HIGH" when the database gives one, else the CVSS vector
+	Summary  string
+	Fixed    string // First fixed version above Version, when known
+}
+
+// Client queries the OSV API
+type Client struct {
+	BaseURL string       // API root; empty means DefaultURL
+	HTTP    *http.Client // nil means a client with a 30 second timeout
+}
+
+// FromEnv configures a client from the environment: EnvURL overrides the
+// API root
+func FromEnv(getenv func(string) string) *Client {
+	return &Client{BaseURL: getenv(EnvURL)}
+}
+
+// record is the part of an OSV record a Vulnerability is made from
+type record struct {
+	ID       string   `json:"id"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Aliases  []string `json:"aliases"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	Affected []struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+		Ranges []struct {
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+	} `json:"affected"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+// Query returns the known vulnerabilities of packages, sorted by package
+// and ID. It looks the packages up in batches, then fetches each
+// vulnerability found for its details.
+func (c *Client) Query(ctx context.Context, packages []Package) ([]Vulnerability, error) {
+	type query struct {
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+		Version string `json:"version"`
+	}
+	var batch struct {
+		Results []struct {
+			Vulns []struct {
+				ID string `json:"id"`
+			} `json:"vulns"`
+		} `json:"results"`
+	}
+
+	affected := make(map[string][]Package) // Affected packages by vulnerability ID
+	var ids []string
+	for start := 0; start < len(packages); start += maxBatch {
+		chunk := packages[start:min(start+maxBatch, len(packages))]
+		queries := make([]query, len(chunk))
+		for i, pkg := range chunk {
+			queries[i].Package.Name, queries[i].Package.Ecosystem, queries[i].Version = pkg.Name, pkg.Ecosystem, pkg.Version
+		}
+		batch.Results = nil
+		if err := c.do(ctx, http.MethodPost, "/v1/querybatch", map[string]any{"queries": queries}, &batch); err != nil {
+			return nil, err
+		}
+		if len(batch.Results) != len(chunk) {
+			return nil, fmt.Errorf("OSV returned %d results for %d queries", len(batch.Results), len(chunk))
+		}
+		for i, result := range batch.Results {
+			for _, vuln := range result.Vulns {
+				if affected[vuln.ID] == nil {
+					ids = append(ids, vuln.ID)
+				}
+				affected[vuln.ID] = append(affected[vuln.ID], chunk[i])
+			}
+		}
+	}
+
+	var vulns []Vulnerability
+	for _, id := range ids {
+		var rec record
+		if err := c.do(ctx, http.MethodGet, "/v1/vulns/"+id, nil, &rec); err != nil {
+			return nil, err
+		}
+		for _, pkg := range affected[id] {
+			vulns = append(vulns, rec.vulnerability(pkg.Name, pkg.Version))
+		}
+	}
+	sortVulnerabilities(vulns)
+	return vulns, nil
+}
+
+// do sends a request with body encoded as JSON, when there is one, and
+// decodes the response into out
+func (c *Client) do(ctx context.Context, method, path string, body any, out any) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(data)
+	}
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultURL
+	}
+	url := strings.TrimRight(base, "/") + path
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding %s: %w", url, err)
+	}
+	return nil
+}
+
+// ReadReport returns the vulnerabilities of an osv-scanner JSON report
+// (osv-scanner --format json), sorted by package and ID, and the number of
+// packages it scanned
+func ReadReport(content []byte) ([]Vulnerability, int, error) {
+	var report struct {
+		Results []struct {
+			Packages []struct {
+				Package struct {
+					Name    string `json:"name"`
+					Version string `json:"version"`
+				} `json:"package"`
+				Vulnerabilities []record `json:"vulnerabilities"`
+			} `json:"packages"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, 0, fmt.Errorf("not an osv-scanner JSON report: %w", err)
+	}
+
+	var vulns []Vulnerability
+	packages := 0
+	for _, result := range report.Results {
+		for _, pkg := range result.Packages {
+			packages++
+			for _, rec := range pkg.Vulnerabilities {
+				vulns = append(vulns, rec.vulnerability(pkg.Package.Name, pkg.Package.Version))
+			}
+		}
+	}
+	sortVulnerabilities(vulns)
+	return vulns, packages, nil
+}
+
+// vulnerability describes rec as it affects version of the package name
+func (rec record) vulnerability(name, version string) Vulnerability {
+	vuln := Vulnerability{ID: rec.ID, Aliases: rec.Aliases, Package: name, Version: version, Summary: rec.Summary}
+	if vuln.Summary == "" {
+		vuln.Summary = strings.TrimSpace(strings.SplitN(strings.TrimSpace(rec.Details), "\n", 2)[0])
+		if len(vuln.Summary) > maxSummary {
+			vuln.Summary = vuln.Summary[:maxSummary] + "..."
+		}
+	}
+	vuln.Severity = rec.DatabaseSpecific.Severity
+	if vuln.Severity == "" && len(rec.Severity) > 0 {
+		vuln.Severity = rec.Severity[0].Score
+	}
+	for _, affected := range rec.Affected {
+		if affected.Package.Name != name {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				fixed := event["fixed"]
+				if fixed != "" && compareVersions(fixed, version) > 0 && (vuln.Fixed == "" || compareVersions(fixed, vuln.Fixed) < 0) {
+					vuln.Fixed = fixed
+				}
+			}
+		}
+	}
+	return vuln
+}
+
+// compareVersions orders two versions by their numeric components, such
+// that "1.10.0" follows "1.9.2". Components that aren't numbers compare as
+// strings, and a leading "v" is ignored.
+func compareVersions(a, b string) int {
+	split := func(v string) []string {
+		return strings.FieldsFunc(strings.TrimPrefix(v, "v"), func(r rune) bool { return r == '.' || r == '-' || r == '+' })
+	}
+	as, bs := split(a), split(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return len(as) - len(bs)
+}
+
+func sortVulnerabilities(vulns []Vulnerability) {
+	sort.SliceStable(vulns, func(i, j int) bool {
+		if vulns[i].Package != vulns[j].Package {
+			return vulns[i].Package < vulns[j].Package
+		}
+		return vulns[i].ID < vulns[j].ID
+	})
+}
@@ -0,0 +1,110 @@
+package osv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lodashRecord = `{
+  "id": "GHSA-35jh-r3h4-6jhm",
+  "summary": "Command Injection in lodash",
+  "aliases": ["CVE-2021-23337"],
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:H/A:H"}],
+  "affected": [{
+    "package": {"name": "lodash", "ecosystem": "npm"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]
+  }],
+  "database_specific": {"severity": "HIGH"}
+}`
+
+func TestQuery(t *testing.T) {
+	var queries []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			assert.Equal(t, http.MethodPost, r.Method)
+			var body struct {
+				Queries []map[string]any `json:"queries"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			queries = body.Queries
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "GHSA-35jh-r3h4-6jhm"}]}, {}]}`))
+		case "/v1/vulns/GHSA-35jh-r3h4-6jhm":
+			w.Write([]byte(lodashRecord))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := FromEnv(func(key string) string { return map[string]string{EnvURL: server.URL}[key] })
+	vulns, err := client.Query(context.Background(), []Package{
+		{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"},
+		{Name: "express", Version: "4.18.2", Ecosystem: "npm"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Vulnerability{{
+		ID:       "GHSA-35jh-r3h4-6jhm",
+		Aliases:  []string{"CVE-2021-23337"},
+		Package:  "lodash",
+		Version:  "4.17.20",
+		Severity: "HIGH",
+		Summary:  "Command Injection in lodash",
+		Fixed:    "4.17.21",
+	}}, vulns)
+	require.Len(t, queries, 2)
+	assert.Equal(t, map[string]any{"package": map[string]any{"name": "lodash", "ecosystem": "npm"}, "version": "4.17.20"}, queries[0])
+}
+
+func TestQueryReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	_, err := (&Client{BaseURL: server.URL}).Query(context.Background(), []Package{{Name: "lodash", Version: "4.17.20", Ecosystem: "npm"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "429")
+}
+
+func TestReadReport(t *testing.T) {
+	report := `{"results": [{
+  "source": {"path": "/src/package-lock.json", "type": "lockfile"},
+  "packages": [
+    {"package": {"name": "lodash", "version": "4.17.20", "ecosystem": "npm"}, "vulnerabilities": [` + lodashRecord + `]},
+    {"package": {"name": "minimist", "version": "1.2.5", "ecosystem": "npm"}, "vulnerabilities": [{
+      "id": "GHSA-xvch-5gv4-984h",
+      "details": "Prototype pollution in minimist.\nMore text.",
+      "affected": [{"package": {"name": "minimist"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "0.2.4"}, {"introduced": "1.0.0"}, {"fixed": "1.2.6"}]}]}]
+    }]}
+  ]
+}]}`
+	vulns, packages, err := ReadReport([]byte(report))
+	require.NoError(t, err)
+	assert.Equal(t, 2, packages)
+	require.Len(t, vulns, 2)
+	assert.Equal(t, "lodash", vulns[0].Package)
+	assert.Equal(t, Vulnerability{
+		ID:      "GHSA-xvch-5gv4-984h",
+		Package: "minimist",
+		Version: "1.2.5",
+		Summary: "Prototype pollution in minimist.",
+		Fixed:   "1.2.6",
+	}, vulns[1])
+
+	_, _, err = ReadReport([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 1, compareVersions("1.10.0", "1.9.2"))
+	assert.Equal(t, -1, compareVersions("v1.2.3", "1.2.4"))
+	assert.Equal(t, 0, compareVersions("2.0.0", "v2.0.0"))
+	assert.Less(t, compareVersions("1.2", "1.2.1"), 0)
+}
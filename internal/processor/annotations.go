@@ -0,0 +1,49 @@
+package processor
+
+import (
+	"sort"
+
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"github.com/1broseidon/promptext/internal/filter/types"
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// annotation is a note for the files matching a glob
+type annotation struct {
+	match types.Rule
+	note  string
+}
+
+// annotator attaches the notes of Config.Annotations to matching files
+type annotator []annotation
+
+// newAnnotator compiles annotations, ordered by glob so files matching
+// several get their notes in a stable order
+func newAnnotator(annotations map[string]string) annotator {
+	globs := make([]string, 0, len(annotations))
+	for glob := range annotations {
+		globs = append(globs, glob)
+	}
+	sort.Strings(globs)
+
+	a := make(annotator, 0, len(globs))
+	for _, glob := range globs {
+		if annotations[glob] == "" {
+			continue
+		}
+		a = append(a, annotation{
+			match: rules.NewGitPatternRule([]string{glob}, types.Include),
+			note:  annotations[glob],
+		})
+	}
+	return a
+}
+
+// annotate sets the notes of the annotations matching file
+func (a annotator) annotate(file *format.FileInfo) {
+	for _, annotation := range a {
+		if annotation.match.Match(file.Path) {
+			file.Annotations = append(file.Annotations, annotation.note)
+		}
+	}
+}
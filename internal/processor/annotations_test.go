@@ -0,0 +1,49 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryAnnotations(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"main.go":              "package main\n",
+		"internal/legacy/a.go": "package legacy\n",
+		"api/service.proto":    "syntax = \"proto3\";\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath: tmpDir,
+		Filter:  filter.New(filter.Options{Includes: []string{".go", ".proto"}}),
+		Annotations: map[string]string{
+			"internal/legacy/**": "Being deprecated",
+			"*.proto":            "Clients are generated from this",
+			"internal/":          "Not importable from outside",
+		},
+	}
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	notes := make(map[string][]string)
+	for _, file := range result.ProjectOutput.Files {
+		notes[file.Path] = file.Annotations
+	}
+	assert.Nil(t, notes["main.go"])
+	assert.Equal(t, []string{"Not importable from outside", "Being deprecated"}, notes["internal/legacy/a.go"])
+	assert.Equal(t, []string{"Clients are generated from this"}, notes["api/service.proto"])
+
+	var streamed []format.FileInfo
+	require.NoError(t, StreamFiles(config, func(file format.FileInfo) error {
+		streamed = append(streamed, file)
+		return nil
+	}))
+	for _, file := range streamed {
+		assert.Equal(t, notes[file.Path], file.Annotations, file.Path)
+	}
+}
@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/osv"
+)
+
+// osvEcosystems are the OSV ecosystems of the languages whose manifests
+// the metadata reads dependencies from
+var osvEcosystems = map[string]string{
+	"Go":                 "Go",
+	"JavaScript/Node.js": "npm",
+	"Python":             "PyPI",
+	"Rust":               "crates.io",
+	"Java (Maven)":       "Maven",
+	"Java (Gradle)":      "Maven",
+}
+
+// auditDependencies lists the known vulnerabilities of the project's
+// dependencies, read from the osv-scanner report config.AuditReport when
+// set, else looked up in the OSV API. Only dependencies pinned to an exact
+// version can be looked up.
+func auditDependencies(config Config, metadata *format.Metadata) (*format.AuditInfo, error) {
+	if config.AuditReport != "" {
+		data, err := os.ReadFile(config.AuditReport)
+		if err != nil {
+			return nil, err
+		}
+		vulns, packages, err := osv.ReadReport(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.AuditReport, err)
+		}
+		return auditInfo(config.AuditReport, packages, vulns), nil
+	}
+
+	var packages []osv.Package
+	if ecosystem := osvEcosystems[metadata.Language]; ecosystem != "" {
+		for _, dep := range metadata.Dependencies {
+			if version, ok := exactVersion(ecosystem, dep.Version); ok {
+				packages = append(packages, osv.Package{Name: dep.Name, Version: version, Ecosystem: ecosystem})
+			}
+		}
+	}
+	log.Debug("Auditing %d of %d dependencies against OSV", len(packages), len(metadata.Dependencies))
+	if len(packages) == 0 {
+		return auditInfo("osv.dev", 0, nil), nil
+	}
+	vulns, err := osv.FromEnv(os.Getenv).Query(context.Background(), packages)
+	if err != nil {
+		return nil, err
+	}
+	return auditInfo("osv.dev", len(packages), vulns), nil
+}
+
+func auditInfo(source string, checked int, vulns []osv.Vulnerability) *format.AuditInfo {
+	info := &format.AuditInfo{Source: source, Checked: checked, Vulnerabilities: []format.Vulnerability{}}
+	for _, vuln := range vulns {
+		info.Vulnerabilities = append(info.Vulnerabilities, format.Vulnerability(vuln))
+	}
+	return info
+}
+
+// exactVersion returns the version OSV is asked about for a dependency's
+// version as written, or false when it is a range, a tag or a source rather
+// than one version. Cargo reads a bare "1.0" as "^1.0", so only "=" pins count
+// there; Go versions lose their "v" as OSV records them without it.
+func exactVersion(ecosystem, version string) (string, bool) {
+	version = strings.TrimSpace(version)
+	pinned := strings.TrimLeft(version, "=")
+	if ecosystem == "crates.io" && pinned == version {
+		return "", false
+	}
+	if pinned == "" || strings.ContainsAny(pinned, "^~<>*|,$!:/ ") || pinned == "latest" {
+		return "", false
+	}
+	if ecosystem == "Go" {
+		pinned = strings.TrimPrefix(pinned, "v")
+	}
+	return pinned, true
+}
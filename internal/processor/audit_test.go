@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/osv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryAuditDeps(t *testing.T) {
+	var queried []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/querybatch":
+			var body struct {
+				Queries []struct {
+					Package struct {
+						Name      string `json:"name"`
+						Ecosystem string `json:"ecosystem"`
+					} `json:"package"`
+					Version string `json:"version"`
+				} `json:"queries"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			for _, q := range body.Queries {
+				queried = append(queried, q.Package.Ecosystem+" "+q.Package.Name+"@"+q.Version)
+			}
+			w.Write([]byte(`{"results": [{"vulns": [{"id": "GO-2023-1571"}]}, {}]}`))
+		case "/v1/vulns/GO-2023-1571":
+			w.Write([]byte(`{"id": "GO-2023-1571", "aliases": ["CVE-2022-41723"], "summary": "Denial of service via crafted HTTP/2 stream",
+				"affected": [{"package": {"name": "golang.org/x/net"}, "ranges": [{"events": [{"introduced": "0"}, {"fixed": "0.7.0"}]}]}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv(osv.EnvURL, server.URL)
+
+	tmpDir := setupTestProject(t, map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.21\n\nrequire (\n\tgolang.org/x/net v0.6.0\n\tgithub.com/pkg/errors v0.9.1 // indirect\n)\n",
+		"main.go": "package main\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	result, err := ProcessDirectory(Config{
+		DirPath:    tmpDir,
+		Extensions: []string{".go"},
+		Filter:     filter.New(filter.Options{UseDefaultRules: true}),
+		AuditDeps:  true,
+	}, false)
+	require.NoError(t, err)
+
+	audit := result.ProjectOutput.Metadata.Audit
+	require.NotNil(t, audit)
+	assert.Equal(t, "osv.dev", audit.Source)
+	assert.Equal(t, 2, audit.Checked)
+	assert.Equal(t, []string{"Go golang.org/x/net@0.6.0", "Go github.com/pkg/errors@0.9.1"}, queried)
+	require.Len(t, audit.Vulnerabilities, 1)
+	assert.Equal(t, "golang.org/x/net", audit.Vulnerabilities[0].Package)
+	assert.Equal(t, "0.7.0", audit.Vulnerabilities[0].Fixed)
+	assert.Equal(t, []string{"CVE-2022-41723"}, audit.Vulnerabilities[0].Aliases)
+}
+
+func TestProcessDirectoryAuditReport(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"package.json": `{"dependencies": {"lodash": "^4.17.0"}}`,
+		"index.js":     "require('lodash')\n",
+	})
+	defer os.RemoveAll(tmpDir)
+	report := filepath.Join(t.TempDir(), "osv.json")
+	require.NoError(t, os.WriteFile(report, []byte(`{"results": [{"packages": [{
+		"package": {"name": "lodash", "version": "4.17.20", "ecosystem": "npm"},
+		"vulnerabilities": [{"id": "GHSA-35jh-r3h4-6jhm", "summary": "Command Injection in lodash", "database_specific": {"severity": "HIGH"}}]
+	}]}]}`), 0644))
+	// The report is read instead of querying OSV
+	t.Setenv(osv.EnvURL, "http://127.0.0.1:1")
+
+	config := Config{
+		DirPath:     tmpDir,
+		Extensions:  []string{".js"},
+		Filter:      filter.New(filter.Options{UseDefaultRules: true}),
+		AuditReport: report,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	audit := result.ProjectOutput.Metadata.Audit
+	require.NotNil(t, audit)
+	assert.Equal(t, report, audit.Source)
+	assert.Equal(t, 1, audit.Checked)
+	require.Len(t, audit.Vulnerabilities, 1)
+	assert.Equal(t, "HIGH", audit.Vulnerabilities[0].Severity)
+
+	config.AuditReport = filepath.Join(tmpDir, "missing.json")
+	_, err = ProcessDirectory(config, false)
+	assert.ErrorContains(t, err, "error auditing dependencies")
+}
+
+func TestExactVersion(t *testing.T) {
+	tests := []struct {
+		ecosystem, version, want string
+		ok                       bool
+	}{
+		{"Go", "v1.2.3", "1.2.3", true},
+		{"npm", "4.17.21", "4.17.21", true},
+		{"npm", "^4.17.0", "", false},
+		{"npm", "latest", "", false},
+		{"npm", "github:user/repo", "", false},
+		{"PyPI", "==2.31.0", "2.31.0", true},
+		{"PyPI", ">=2.0", "", false},
+		{"crates.io", "1.0", "", false},
+		{"crates.io", "=1.0.5", "1.0.5", true},
+		{"Maven", "5.3.20", "5.3.20", true},
+		{"Maven", "${spring.version}", "", false},
+	}
+	for _, tt := range tests {
+		version, ok := exactVersion(tt.ecosystem, tt.version)
+		assert.Equal(t, tt.ok, ok, tt.version)
+		assert.Equal(t, tt.want, version, tt.version)
+	}
+}
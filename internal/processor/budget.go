@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// budgetAreas splits a token budget between the areas named in
+// Config.BudgetWeights: directories ("internal/") and extensions (".md").
+// Files in no weighted area share what the weights leave over.
+type budgetAreas struct {
+	dirs   []string       // Directory keys, longest first
+	quotas map[string]int // Tokens per area key; "" is the unweighted rest
+	used   map[string]int
+}
+
+// newBudgetAreas turns weights into token quotas of available. Weights are
+// fractions of the budget; if they add up to more than 1 they are scaled down
+// proportionally, leaving nothing reserved for unweighted files.
+func newBudgetAreas(weights map[string]float64, available int) *budgetAreas {
+	areas := &budgetAreas{quotas: make(map[string]int), used: make(map[string]int)}
+	normalized := make(map[string]float64, len(weights))
+	total := 0.0
+	for key, weight := range weights {
+		key = normalizeBudgetKey(key)
+		if key == "" {
+			continue
+		}
+		weight = max(weight, 0)
+		normalized[key] += weight
+		total += weight
+	}
+
+	scale := 1.0
+	if total > 1 {
+		scale = 1 / total
+	}
+	assigned := 0
+	for key, weight := range normalized {
+		areas.quotas[key] = int(weight * scale * float64(available))
+		assigned += areas.quotas[key]
+		if !strings.HasPrefix(key, ".") {
+			areas.dirs = append(areas.dirs, key)
+		}
+	}
+	if total < 1 {
+		areas.quotas[""] = available - assigned
+	}
+	sort.Slice(areas.dirs, func(i, j int) bool { return len(areas.dirs[i]) > len(areas.dirs[j]) })
+	return areas
+}
+
+// normalizeBudgetKey returns ".ext" for an extension ("*.go", ".go")
+// and "dir/" for a directory ("./internal", "internal/"), or "" for the root
+func normalizeBudgetKey(key string) string {
+	key = strings.TrimSpace(filepath.ToSlash(key))
+	if ext := strings.TrimPrefix(key, "*"); strings.HasPrefix(ext, ".") && !strings.Contains(ext, "/") && ext != "." && ext != ".." {
+		return strings.ToLower(ext)
+	}
+	key = strings.Trim(strings.TrimPrefix(key, "./"), "/")
+	if key == "" || key == "." {
+		return ""
+	}
+	return key + "/"
+}
+
+// area returns the key of the area path belongs to: the longest matching
+// directory, else its extension, else "" for the unweighted rest
+func (b *budgetAreas) area(path string) string {
+	path = filepath.ToSlash(path)
+	for _, dir := range b.dirs {
+		if strings.HasPrefix(path, dir) {
+			return dir
+		}
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext != "" {
+		if _, ok := b.quotas[ext]; ok {
+			return ext
+		}
+	}
+	return ""
+}
+
+// selectWithinBudget reports which files, taken in priority order, fit in
+// available tokens at the given per-file costs. Without weights files are
+// taken greedily. With weights, a first pass keeps each area within its
+// quota, so one large area can't crowd out the others; a second pass spends
+// whatever the quotas left unused on the remaining files, still in priority
+// order.
+func selectWithinBudget(files []format.FileInfo, costs []int, available int, weights map[string]float64) []bool {
+	included := make([]bool, len(files))
+	used := 0
+	if len(weights) > 0 {
+		areas := newBudgetAreas(weights, available)
+		for i, file := range files {
+			area := areas.area(file.Path)
+			if areas.used[area]+costs[i] <= areas.quotas[area] && used+costs[i] <= available {
+				included[i] = true
+				areas.used[area] += costs[i]
+				used += costs[i]
+			}
+		}
+	}
+	for i := range files {
+		if !included[i] && used+costs[i] <= available {
+			included[i] = true
+			used += costs[i]
+		}
+	}
+	return included
+}
@@ -0,0 +1,74 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeBudgetKey(t *testing.T) {
+	tests := map[string]string{
+		"internal":    "internal/",
+		"./internal/": "internal/",
+		"pkg/api":     "pkg/api/",
+		".md":         ".md",
+		"*.MD":        ".md",
+		".github":     ".github",
+		".github/":    ".github/",
+		".":           "",
+		"./":          "",
+	}
+	for key, want := range tests {
+		assert.Equal(t, want, normalizeBudgetKey(key), key)
+	}
+}
+
+func TestBudgetAreas(t *testing.T) {
+	areas := newBudgetAreas(map[string]float64{"internal/": 0.5, "internal/api/": 0.2, ".md": 0.1}, 1000)
+
+	assert.Equal(t, 500, areas.quotas["internal/"])
+	assert.Equal(t, 200, areas.quotas["internal/api/"])
+	assert.Equal(t, 100, areas.quotas[".md"])
+	assert.Equal(t, 200, areas.quotas[""])
+
+	assert.Equal(t, "internal/api/", areas.area("internal/api/handler.go"))
+	assert.Equal(t, "internal/", areas.area("internal/README.md"))
+	assert.Equal(t, ".md", areas.area("docs/guide.MD"))
+	assert.Equal(t, "", areas.area("main.go"))
+	assert.Equal(t, "", areas.area("internalize.go"))
+}
+
+func TestBudgetAreasScaleDown(t *testing.T) {
+	areas := newBudgetAreas(map[string]float64{"a/": 1.5, "b/": 0.5, "c/": -1}, 1000)
+
+	assert.Equal(t, 750, areas.quotas["a/"])
+	assert.Equal(t, 250, areas.quotas["b/"])
+	assert.Equal(t, 0, areas.quotas["c/"])
+	assert.Equal(t, 0, areas.quotas[""])
+}
+
+func TestSelectWithinBudget(t *testing.T) {
+	files := []format.FileInfo{
+		{Path: "internal/a.go"},
+		{Path: "internal/b.go"},
+		{Path: "internal/c.go"},
+		{Path: "docs/guide.md"},
+		{Path: "main.go"},
+	}
+	costs := []int{40, 40, 40, 30, 20}
+
+	t.Run("greedy without weights", func(t *testing.T) {
+		assert.Equal(t, []bool{true, true, false, false, true}, selectWithinBudget(files, costs, 100, nil))
+	})
+
+	t.Run("weights spread the budget", func(t *testing.T) {
+		weights := map[string]float64{"internal/": 0.5, "docs/": 0.3}
+		assert.Equal(t, []bool{true, false, false, true, true}, selectWithinBudget(files, costs, 100, weights))
+	})
+
+	t.Run("unused quota is spent in priority order", func(t *testing.T) {
+		weights := map[string]float64{"docs/": 0.9}
+		assert.Equal(t, []bool{true, true, false, true, false}, selectWithinBudget(files, costs, 110, weights))
+	})
+}
@@ -0,0 +1,435 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// commentSyntax describes how one language family writes comments and the
+// string literals that may contain comment markers
+type commentSyntax struct {
+	line       []string    // Line comment markers
+	block      [][2]string // Block comment delimiters, checked before line markers
+	quotes     string      // Single-line string delimiters with backslash escapes
+	multiline  []string    // Delimiters of strings that may span lines (JS template literals, Python """)
+	rawQuote   byte        // A multi-line string without escapes (Go's backtick)
+	charQuote  bool        // ' starts a character literal, not a string (C, Go, Rust)
+	wordStart  bool        // Markers and quotes count only at the start of a word (shell, YAML)
+	directives []string    // Line comments that carry meaning and are kept
+}
+
+var (
+	cComments = commentSyntax{
+		line:      []string{"//"},
+		block:     [][2]string{{"/*", "*/"}},
+		quotes:    `"`,
+		charQuote: true,
+	}
+	goComments = commentSyntax{
+		line:       []string{"//"},
+		block:      [][2]string{{"/*", "*/"}},
+		quotes:     `"`,
+		rawQuote:   '`',
+		charQuote:  true,
+		directives: []string{"//go:", "// +build", "//export ", "//line "},
+	}
+	jvmComments = commentSyntax{
+		line:      []string{"//"},
+		block:     [][2]string{{"/*", "*/"}},
+		quotes:    `"`,
+		multiline: []string{`"""`},
+		charQuote: true,
+	}
+	jsComments = commentSyntax{
+		line:       []string{"//"},
+		block:      [][2]string{{"/*", "*/"}},
+		quotes:     `"'`,
+		multiline:  []string{"`"},
+		directives: []string{"// @ts-", "/// <reference", "// eslint-"},
+	}
+	cssComments = commentSyntax{
+		block:  [][2]string{{"/*", "*/"}},
+		quotes: `"'`,
+	}
+	scssComments = commentSyntax{
+		line:   []string{"//"},
+		block:  [][2]string{{"/*", "*/"}},
+		quotes: `"'`,
+	}
+	phpComments = commentSyntax{
+		line:   []string{"//", "#"},
+		block:  [][2]string{{"/*", "*/"}},
+		quotes: `"'`,
+	}
+	pythonComments = commentSyntax{
+		line:       []string{"#"},
+		quotes:     `"'`,
+		multiline:  []string{`"""`, `'''`},
+		directives: []string{"# type:", "# noqa", "# -*-"},
+	}
+	hashComments = commentSyntax{
+		line:      []string{"#"},
+		quotes:    `"'`,
+		wordStart: true,
+	}
+	tomlComments = commentSyntax{
+		line:      []string{"#"},
+		quotes:    `"'`,
+		multiline: []string{`"""`, `'''`},
+		wordStart: true,
+	}
+	hclComments = commentSyntax{
+		line:   []string{"#", "//"},
+		block:  [][2]string{{"/*", "*/"}},
+		quotes: `"`,
+	}
+	sqlComments = commentSyntax{
+		line:   []string{"--"},
+		block:  [][2]string{{"/*", "*/"}},
+		quotes: `'"`,
+	}
+	luaComments = commentSyntax{
+		line:   []string{"--"},
+		block:  [][2]string{{"--[[", "]]"}},
+		quotes: `"'`,
+	}
+	haskellComments = commentSyntax{
+		line:   []string{"--"},
+		block:  [][2]string{{"{-", "-}"}},
+		quotes: `"`,
+	}
+	markupComments = commentSyntax{
+		block: [][2]string{{"<!--", "-->"}},
+	}
+)
+
+// commentLanguages maps file extensions to their comment syntax
+var commentLanguages = map[string]*commentSyntax{
+	".go":    &goComments,
+	".c":     &cComments,
+	".h":     &cComments,
+	".cc":    &cComments,
+	".cpp":   &cComments,
+	".cxx":   &cComments,
+	".hpp":   &cComments,
+	".hh":    &cComments,
+	".m":     &cComments,
+	".cs":    &cComments,
+	".rs":    &cComments,
+	".swift": &jvmComments,
+	".java":  &jvmComments,
+	".kt":    &jvmComments,
+	".kts":   &jvmComments,
+	".scala": &jvmComments,
+	".dart":  &jsComments,
+	".js":    &jsComments,
+	".jsx":   &jsComments,
+	".mjs":   &jsComments,
+	".cjs":   &jsComments,
+	".ts":    &jsComments,
+	".tsx":   &jsComments,
+	".proto": &scssComments,
+	".css":   &cssComments,
+	".scss":  &scssComments,
+	".less":  &scssComments,
+	".php":   &phpComments,
+	".py":    &pythonComments,
+	".pyi":   &pythonComments,
+	".rb":    &hashComments,
+	".sh":    &hashComments,
+	".bash":  &hashComments,
+	".zsh":   &hashComments,
+	".pl":    &hashComments,
+	".r":     &hashComments,
+	".ex":    &hashComments,
+	".exs":   &hashComments,
+	".yaml":  &hashComments,
+	".yml":   &hashComments,
+	".toml":  &tomlComments,
+	".tf":    &hclComments,
+	".hcl":   &hclComments,
+	".sql":   &sqlComments,
+	".lua":   &luaComments,
+	".hs":    &haskellComments,
+	".html":  &markupComments,
+	".htm":   &markupComments,
+	".xml":   &markupComments,
+	".svg":   &markupComments,
+	".vue":   &markupComments,
+}
+
+// commentFileNames maps extensionless files to their comment syntax
+var commentFileNames = map[string]*commentSyntax{
+	"Makefile":    &hashComments,
+	"Dockerfile":  &hashComments,
+	"Gemfile":     &hashComments,
+	"Rakefile":    &hashComments,
+	"Jenkinsfile": &jvmComments,
+}
+
+// stripComments removes comments from source in the languages listed in
+// commentLanguages, leaving string literals that contain comment markers
+// intact. Lines holding only a comment are removed; comments after code are
+// cut with the whitespace before them. A leading #! line and directive
+// comments such as //go:build are kept. Content of other languages is
+// returned unchanged.
+func stripComments(path, content string) string {
+	syntax := commentLanguages[strings.ToLower(filepath.Ext(path))]
+	if syntax == nil {
+		syntax = commentFileNames[filepath.Base(path)]
+	}
+	if syntax == nil {
+		return content
+	}
+
+	s := &commentStripper{syntax: syntax, src: content}
+	if strings.HasPrefix(content, "#!") {
+		end := strings.IndexByte(content, '\n')
+		if end < 0 {
+			return content
+		}
+		s.out.WriteString(content[:end+1])
+		s.pos = end + 1
+	}
+	s.run()
+	return s.out.String()
+}
+
+// commentStripper copies src to out without comments, a line at a time so
+// that lines left empty by a removed comment can be dropped
+type commentStripper struct {
+	syntax     *commentSyntax
+	src        string
+	pos        int
+	out        strings.Builder
+	line       strings.Builder // The current output line
+	hadComment bool            // A comment was removed from the current line
+}
+
+func (s *commentStripper) run() {
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch {
+		case c == '\n':
+			s.flushLine(true)
+			s.pos++
+		case c == '\\':
+			// Escapes outside strings (regex literals, line continuations)
+			// never start a comment
+			s.copy(2)
+		case s.atMarker() && (s.blockComment() || s.lineComment()):
+		case !s.stringLiteral():
+			s.copy(1)
+		}
+	}
+	if s.line.Len() > 0 || s.hadComment {
+		s.flushLine(false)
+	}
+}
+
+// atMarker reports whether a comment may start at the current position
+func (s *commentStripper) atMarker() bool {
+	if !s.syntax.wordStart || s.pos == 0 {
+		return true
+	}
+	prev := s.src[s.pos-1]
+	return prev == ' ' || prev == '\t' || prev == '\n' || prev == ';'
+}
+
+// blockComment skips a block comment at the current position, reporting
+// whether there was one. An unterminated comment runs to the end of src.
+func (s *commentStripper) blockComment() bool {
+	rest := s.src[s.pos:]
+	for _, delims := range s.syntax.block {
+		if !strings.HasPrefix(rest, delims[0]) {
+			continue
+		}
+		end := strings.Index(rest[len(delims[0]):], delims[1])
+		if end < 0 {
+			s.pos = len(s.src)
+		} else {
+			s.pos += len(delims[0]) + end + len(delims[1])
+		}
+		s.hadComment = true
+		return true
+	}
+	return false
+}
+
+// lineComment skips a line comment at the current position up to its
+// newline, reporting whether there was one. Directives are copied instead.
+func (s *commentStripper) lineComment() bool {
+	rest := s.src[s.pos:]
+	for _, marker := range s.syntax.line {
+		if !strings.HasPrefix(rest, marker) {
+			continue
+		}
+		end := strings.IndexByte(rest, '\n')
+		if end < 0 {
+			end = len(rest)
+		}
+		end = len(strings.TrimSuffix(rest[:end], "\r")) // The \r stays with the line
+		for _, directive := range s.syntax.directives {
+			if strings.HasPrefix(rest, directive) {
+				s.copy(end)
+				return true
+			}
+		}
+		s.pos += end
+		s.hadComment = true
+		return true
+	}
+	return false
+}
+
+// stringLiteral copies a string or character literal at the current
+// position, reporting whether there was one
+func (s *commentStripper) stringLiteral() bool {
+	rest := s.src[s.pos:]
+	c := rest[0]
+
+	if c == s.syntax.rawQuote {
+		end := strings.IndexByte(rest[1:], c)
+		if end < 0 {
+			end = len(rest) - 1
+		} else {
+			end += 2
+		}
+		s.copy(end)
+		return true
+	}
+	for _, delim := range s.syntax.multiline {
+		if strings.HasPrefix(rest, delim) {
+			s.copy(len(delim) + quotedLength(rest[len(delim):], delim, true))
+			return true
+		}
+	}
+	if c == '\'' && s.syntax.charQuote {
+		// Only a short literal such as 'a' or '\n'; Rust lifetimes and
+		// Scala symbols have no closing quote
+		if n := charLiteralLength(rest); n > 0 {
+			s.copy(n)
+			return true
+		}
+		return false
+	}
+	if strings.IndexByte(s.syntax.quotes, c) < 0 {
+		return false
+	}
+	if s.syntax.wordStart && s.pos > 0 && isWordByte(s.src[s.pos-1]) {
+		return false // An apostrophe, as in a YAML value don't
+	}
+	s.copy(1 + quotedLength(rest[1:], string(c), false))
+	return true
+}
+
+// quotedLength returns the length of a string body up to and including its
+// closing delim. Backslash escapes are honored; a single-line string ends at
+// its newline if it isn't closed sooner.
+func quotedLength(body, delim string, multiline bool) int {
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\\':
+			i++
+		case body[i] == '\n' && !multiline:
+			return i
+		case strings.HasPrefix(body[i:], delim):
+			return i + len(delim)
+		}
+	}
+	return len(body)
+}
+
+// charLiteralLength returns the length of a character literal at the start
+// of s, or 0 if the quote doesn't open one
+func charLiteralLength(s string) int {
+	if len(s) < 3 {
+		return 0
+	}
+	if s[1] == '\\' {
+		// '\n', '\'', '\x7f', '\u{1F600}'
+		if end := strings.IndexByte(s[3:], '\''); end >= 0 && end <= 10 && !strings.Contains(s[3:3+end], "\n") {
+			return end + 4
+		}
+		return 0
+	}
+	_, size := utf8.DecodeRuneInString(s[1:])
+	if 1+size < len(s) && s[1+size] == '\'' {
+		return size + 2
+	}
+	return 0
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// copy moves n bytes of source into the current line, splitting it at
+// newlines inside multi-line strings
+func (s *commentStripper) copy(n int) {
+	end := min(s.pos+n, len(s.src))
+	for s.pos < end {
+		newline := strings.IndexByte(s.src[s.pos:end], '\n')
+		if newline < 0 {
+			s.line.WriteString(s.src[s.pos:end])
+			s.pos = end
+			return
+		}
+		s.line.WriteString(s.src[s.pos : s.pos+newline])
+		s.pos += newline + 1
+		s.flushLine(true)
+	}
+}
+
+// flushLine writes the current line to out. A line a comment was removed
+// from loses its trailing whitespace, and is dropped if nothing is left.
+func (s *commentStripper) flushLine(newline bool) {
+	line := s.line.String()
+	s.line.Reset()
+	cr := strings.HasSuffix(line, "\r")
+	if s.hadComment {
+		s.hadComment = false
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			return
+		}
+		if cr {
+			line += "\r"
+		}
+	}
+	s.out.WriteString(line)
+	if newline {
+		s.out.WriteByte('\n')
+	}
+}
+
+// squashBlankLines collapses runs of blank lines into a single empty line
+// and drops blank lines at the start and end of content
+func squashBlankLines(content string) string {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	blank := true // Drops leading blank lines
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			if !blank {
+				kept = append(kept, strings.TrimLeft(line, " \t")) // Keeps a CRLF file's \r
+			}
+			blank = true
+			continue
+		}
+		kept = append(kept, line)
+		blank = false
+	}
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" {
+		kept = kept[:len(kept)-1]
+	}
+	if len(kept) == 0 {
+		return ""
+	}
+	result := strings.Join(kept, "\n")
+	if strings.HasSuffix(content, "\n") {
+		result += "\n"
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{
+			name:    "go line and block comments",
+			path:    "main.go",
+			content: "// Package main runs.\npackage main\n\n/*\nBlock doc.\n*/\nfunc main() { // trailing\n\tx := 1 /* inline */ + 2\n}\n",
+			want:    "package main\n\nfunc main() {\n\tx := 1  + 2\n}\n",
+		},
+		{
+			name:    "go strings keep comment markers",
+			path:    "url.go",
+			content: "package url\n\nconst a = \"http://example.com\" // site\nconst b = `/* not a comment */`\nconst c = '/'\nconst d = \"escaped \\\" // quote\"\n",
+			want:    "package url\n\nconst a = \"http://example.com\"\nconst b = `/* not a comment */`\nconst c = '/'\nconst d = \"escaped \\\" // quote\"\n",
+		},
+		{
+			name:    "go directives are kept",
+			path:    "build.go",
+			content: "//go:build linux\n\n// Package build is linux only.\npackage build\n\n//go:generate stringer -type=Kind\n",
+			want:    "//go:build linux\n\npackage build\n\n//go:generate stringer -type=Kind\n",
+		},
+		{
+			name:    "rust lifetimes are not character literals",
+			path:    "lib.rs",
+			content: "fn first<'a>(s: &'a str) -> char { // first char\n    if s.is_empty() { '/' } else { '\\'' }\n}\n",
+			want:    "fn first<'a>(s: &'a str) -> char {\n    if s.is_empty() { '/' } else { '\\'' }\n}\n",
+		},
+		{
+			name:    "typescript template literal and regex",
+			path:    "app.ts",
+			content: "/** Docs. */\nconst url = `${base}//path`; // join\nconst re = /\\/*/g;\nconst s = 'it\\'s // fine';\n",
+			want:    "const url = `${base}//path`;\nconst re = /\\/*/g;\nconst s = 'it\\'s // fine';\n",
+		},
+		{
+			name:    "python comments and docstrings",
+			path:    "app.py",
+			content: "#!/usr/bin/env python3\n# Module comment\ndef main():\n    \"\"\"Docstring with # hash.\"\"\"\n    x = \"# not a comment\"  # real comment\n    return x  # type: ignore\n",
+			want:    "#!/usr/bin/env python3\ndef main():\n    \"\"\"Docstring with # hash.\"\"\"\n    x = \"# not a comment\"\n    return x  # type: ignore\n",
+		},
+		{
+			name:    "shell hash inside words",
+			path:    "run.sh",
+			content: "#!/bin/sh\n# Say how many\necho \"$# args\" ${#1} # count\n",
+			want:    "#!/bin/sh\necho \"$# args\" ${#1}\n",
+		},
+		{
+			name:    "yaml apostrophes and fragments",
+			path:    "config.yml",
+			content: "# Settings\nname: don't # who\nurl: http://example.com/#top\n",
+			want:    "name: don't\nurl: http://example.com/#top\n",
+		},
+		{
+			name:    "sql dashes",
+			path:    "schema.sql",
+			content: "-- Users\nSELECT '--' AS dashes; -- trailing\n",
+			want:    "SELECT '--' AS dashes;\n",
+		},
+		{
+			name:    "html comments",
+			path:    "index.html",
+			content: "<!-- header -->\n<p>Text</p> <!-- note -->\n",
+			want:    "<p>Text</p>\n",
+		},
+		{
+			name:    "crlf line endings",
+			path:    "main.c",
+			content: "// comment\r\nint x; // note\r\n",
+			want:    "int x;\r\n",
+		},
+		{
+			name:    "unknown language is untouched",
+			path:    "notes.txt",
+			content: "// not code\n",
+			want:    "// not code\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, stripComments(tt.path, tt.content))
+		})
+	}
+}
+
+func TestSquashBlankLines(t *testing.T) {
+	assert.Equal(t, "a\n\nb\n", squashBlankLines("\n\na\n\n\n  \nb\n\n"))
+	assert.Equal(t, "a\r\n\r\nb", squashBlankLines("a\r\n\r\n\r\nb"))
+	assert.Equal(t, "", squashBlankLines("\n \n"))
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Compaction modes for Config.Compact
+const (
+	CompactWhitespace = "whitespace" // Trim trailing whitespace and collapse blank lines
+	CompactIndent     = "indent"     // Also shrink space indentation to one space per level
+)
+
+// proseExtensions are files whose indentation carries meaning beyond nesting
+// (Markdown code blocks and lists), compacted no further than CompactWhitespace
+var proseExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+	".mdx":      true,
+	".rst":      true,
+	".txt":      true,
+}
+
+// compactContent rewrites content to use fewer tokens. It returns the new
+// content and the mode that changed it, or "" when content is unchanged.
+func compactContent(path, content, mode string) (string, string) {
+	if mode != CompactWhitespace && mode != CompactIndent {
+		return content, ""
+	}
+	if mode == CompactIndent && proseExtensions[strings.ToLower(filepath.Ext(path))] {
+		mode = CompactWhitespace
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, " \t\r")
+		if strings.HasSuffix(line, "\r") {
+			trimmed += "\r"
+		}
+		lines[i] = trimmed
+	}
+	if mode == CompactIndent {
+		reindent(lines)
+	}
+	compacted := squashBlankLines(strings.Join(lines, "\n"))
+	if compacted == content {
+		return content, ""
+	}
+	return compacted, mode
+}
+
+// reindent replaces the leading spaces of each line with one space per
+// distinct indentation width below it, so 0/4/8/12 becomes 0/1/2/3. The
+// mapping keeps the order and equality of indents, which preserves the
+// structure of indentation-sensitive languages such as Python and YAML.
+// Lines indented with tabs are left alone.
+func reindent(lines []string) {
+	widths := make(map[int]bool)
+	for _, line := range lines {
+		if width, ok := spaceIndent(line); ok && width > 0 {
+			widths[width] = true
+		}
+	}
+	if len(widths) == 0 {
+		return
+	}
+	sorted := make([]int, 0, len(widths))
+	for width := range widths {
+		sorted = append(sorted, width)
+	}
+	sort.Ints(sorted)
+	rank := make(map[int]int, len(sorted))
+	for i, width := range sorted {
+		rank[width] = i + 1
+	}
+
+	for i, line := range lines {
+		if width, ok := spaceIndent(line); ok && width > 0 {
+			lines[i] = strings.Repeat(" ", rank[width]) + line[width:]
+		}
+	}
+}
+
+// spaceIndent returns the number of leading spaces of a non-blank line, and
+// false for blank lines and lines whose indentation contains a tab
+func spaceIndent(line string) (int, bool) {
+	width := len(line) - len(strings.TrimLeft(line, " "))
+	if width == len(line) || line[width] == '\t' || strings.TrimSpace(line) == "" {
+		return 0, false
+	}
+	return width, true
+}
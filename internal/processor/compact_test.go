@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		mode     string
+		want     string
+		wantMode string
+	}{
+		{
+			name:     "whitespace trims and collapses",
+			path:     "main.go",
+			content:  "package main  \n\n\n\nfunc main() {\t\n\tprintln()\n}\n\n",
+			mode:     CompactWhitespace,
+			want:     "package main\n\nfunc main() {\n\tprintln()\n}\n",
+			wantMode: CompactWhitespace,
+		},
+		{
+			name:     "indent ranks space indentation",
+			path:     "app.py",
+			content:  "class A:\n    def f(self):\n        if x:\n            return (1,\n                    2)\n        return 0\n",
+			mode:     CompactIndent,
+			want:     "class A:\n def f(self):\n  if x:\n   return (1,\n    2)\n  return 0\n",
+			wantMode: CompactIndent,
+		},
+		{
+			name:     "tab indentation is kept",
+			path:     "main.go",
+			content:  "func main() {\n\tif x {\n\t\treturn\n\t}\n}\n",
+			mode:     CompactIndent,
+			want:     "func main() {\n\tif x {\n\t\treturn\n\t}\n}\n",
+			wantMode: "",
+		},
+		{
+			name:     "markdown is not re-indented",
+			path:     "README.md",
+			content:  "Example:\n\n    code block   \n",
+			mode:     CompactIndent,
+			want:     "Example:\n\n    code block\n",
+			wantMode: CompactWhitespace,
+		},
+		{
+			name:     "crlf line endings",
+			path:     "app.js",
+			content:  "a();  \r\n\r\n\r\n  b();\r\n",
+			mode:     CompactIndent,
+			want:     "a();\r\n\r\n b();\r\n",
+			wantMode: CompactIndent,
+		},
+		{
+			name:     "unknown mode is a no-op",
+			path:     "main.go",
+			content:  "x  \n",
+			mode:     "",
+			want:     "x  \n",
+			wantMode: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, mode := compactContent(tt.path, tt.content, tt.mode)
+			assert.Equal(t, tt.want, content)
+			assert.Equal(t, tt.wantMode, mode)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package processor
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter"
+)
+
+// Rules ExplainFile reports besides the filter's own
+const (
+	ExplainNotFound   = "not-found"  // No such file in the project
+	ExplainDirectory  = "directory"  // The path is a directory
+	ExplainNotListed  = "not-listed" // Outside the roots or file list being processed
+	ExplainUnreadable = "unreadable" // No read permission
+)
+
+// ExplainFile reports why the file at relPath would be left out of the files
+// ProcessDirectory loads with config, going through the same checks in the
+// same order: the roots, the filter's path rules, permissions and size,
+// binary detection, config.FileRules, and content. A Decision that is not Excluded means the
+// file passes them all; selection by relevance or budget happens later.
+func ExplainFile(config Config, relPath string) filter.Decision {
+	relPath = filepath.Clean(relPath)
+	path := filepath.Join(config.DirPath, relPath)
+
+	stat, err := statFile(config, path)
+	if err != nil {
+		return filter.Decision{Excluded: true, Rule: ExplainNotFound}
+	}
+	if stat.IsDir() {
+		return filter.Decision{Excluded: true, Rule: ExplainDirectory}
+	}
+
+	if len(config.Roots) > 0 && !underRoots(relPath, config.Roots) {
+		return filter.Decision{Excluded: true, Rule: ExplainNotListed}
+	}
+
+	if decision := config.Filter.Explain(relPath); decision.Excluded {
+		return decision
+	}
+	if filepath.Base(relPath) == ".DS_Store" {
+		return filter.Decision{Excluded: true, Rule: filter.RuleDefault, Detail: ".DS_Store"}
+	}
+
+	if stat.Mode().Perm()&0444 == 0 {
+		return filter.Decision{Excluded: true, Rule: ExplainUnreadable}
+	}
+	if stat.Size() > maxFileSize(config) {
+		return filter.Decision{Excluded: true, Rule: SkipReasonTooLarge}
+	}
+	if isBinaryFile(config, path) {
+		return filter.Decision{Excluded: true, Rule: filter.RuleBinary, Detail: "content"}
+	}
+
+	read := lazyContent(path, config)
+	outcome := applyFileRules(config.FileRules, relPath, read)
+	if outcome.excludedBy != "" {
+		return filter.Decision{Excluded: true, Rule: ExplainFileRule, Detail: outcome.excludedBy}
+	}
+	if outcome.included {
+		return filter.Decision{}
+	}
+
+	content, err := read()
+	if err != nil {
+		return filter.Decision{Excluded: true, Rule: ExplainUnreadable, Detail: err.Error()}
+	}
+	return config.Filter.ExplainContent(relPath, content)
+}
+
+// underRoots reports whether relPath is one of roots or lies below one
+func underRoots(relPath string, roots []string) bool {
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		if root == "." || relPath == root || strings.HasPrefix(relPath, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainFile(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"main.go":          "package main\n",
+		"gen/types.go":     "// Code generated by protoc. DO NOT EDIT.\npackage gen\n",
+		"docs/guide.md":    "# Guide\n",
+		"assets/data.json": "{}\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath: tmpDir,
+		Filter:  filter.New(filter.Options{Includes: []string{".go", ".json"}, SkipGenerated: true}),
+		Roots:   []string{".", "gen"},
+	}
+
+	assert.False(t, ExplainFile(config, "main.go").Excluded)
+	assert.Equal(t, filter.Decision{Excluded: true, Rule: filter.RuleGenerated, Detail: "content"}, ExplainFile(config, "gen/types.go"))
+	assert.Equal(t, filter.RuleExtension, ExplainFile(config, "docs/guide.md").Rule)
+	assert.Equal(t, ExplainNotFound, ExplainFile(config, "missing.go").Rule)
+	assert.Equal(t, ExplainDirectory, ExplainFile(config, "docs").Rule)
+
+	config.Roots = []string{"gen"}
+	assert.Equal(t, ExplainNotListed, ExplainFile(config, filepath.Join("assets", "data.json")).Rule)
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// ExternalDir names the tree node that holds the extra files from outside
+// the project root
+const ExternalDir = "(external)"
+
+// loadExtraFiles loads config.ExtraFiles from disk, even when config.FS
+// holds the project. Each must be a regular text file; a missing, binary,
+// or oversized file is an error, since the caller asked for it by name.
+// Files already in loaded, such as an extra file inside the project, are
+// left out.
+func loadExtraFiles(config Config, tokenCounter *token.TokenCounter, loaded []format.FileInfo) ([]format.FileInfo, error) {
+	if len(config.ExtraFiles) == 0 {
+		return nil, nil
+	}
+	seen := make(map[string]bool, len(loaded))
+	for _, file := range loaded {
+		seen[file.Path] = true
+	}
+
+	disk := config
+	disk.FS = nil
+	var files []format.FileInfo
+	for _, path := range config.ExtraFiles {
+		relPath := externalPath(config.DirPath, path)
+		if seen[relPath] {
+			continue
+		}
+		seen[relPath] = true
+
+		stat, err := statFile(disk, path)
+		if err != nil {
+			return nil, fmt.Errorf("extra file %s: %w", path, err)
+		}
+		switch {
+		case !stat.Mode().IsRegular():
+			return nil, fmt.Errorf("extra file %s: not a regular file", path)
+		case stat.Size() > maxFileSize(config):
+			return nil, fmt.Errorf("extra file %s: larger than %s", path, formatSize(maxFileSize(config)))
+		case isBinaryFile(disk, path):
+			return nil, fmt.Errorf("extra file %s: binary file", path)
+		}
+		content, err := readFileContent(path, disk)
+		if err != nil {
+			return nil, fmt.Errorf("extra file %s: %w", path, err)
+		}
+
+		fileInfo := format.FileInfo{Path: relPath, Content: content, External: true, Hash: format.FileHash([]byte(content))}
+		if err := transformContent(&fileInfo, relPath, config, tokenCounter); err != nil {
+			return nil, err
+		}
+		truncateFile(&fileInfo, config.MaxFileTokens, config.TruncateStrategy, tokenCounter)
+		files = append(files, fileInfo)
+	}
+	return files, nil
+}
+
+// externalPath labels the file at path relative to dirPath, such as
+// "../shared/api.proto", or by its absolute path when it has no relative one
+func externalPath(dirPath, path string) string {
+	if rel, err := filepath.Rel(dirPath, path); err == nil {
+		return rel
+	}
+	return path
+}
+
+// withExternal returns a copy of the directory tree with the external files
+// among files listed under an ExternalDir node, by their labels
+func withExternal(tree *format.DirectoryNode, files []format.FileInfo) *format.DirectoryNode {
+	var external []string
+	for _, file := range files {
+		if file.External {
+			external = append(external, file.Path)
+		}
+	}
+	if tree == nil || len(external) == 0 {
+		return tree
+	}
+	root := cloneTree(tree)
+	dir := childNode(root, ExternalDir, "dir")
+	for _, path := range external {
+		childNode(dir, filepath.ToSlash(path), "file")
+	}
+	return root
+}
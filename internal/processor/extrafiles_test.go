@@ -0,0 +1,61 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryExtraFiles(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"service/main.go":    "package main\n",
+		"shared/api.proto":   "syntax = \"proto3\";\n",
+		"shared/blob.bin":    "\x00\x01\x02",
+		"service/handler.go": "package main\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	root := filepath.Join(tmpDir, "service")
+	config := Config{
+		DirPath:    root,
+		Filter:     filter.New(filter.Options{Includes: []string{".go"}}),
+		ExtraFiles: []string{filepath.Join(tmpDir, "shared", "api.proto"), filepath.Join(root, "main.go")},
+	}
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	label := filepath.Join("..", "shared", "api.proto")
+	assert.ElementsMatch(t, []string{"main.go", "handler.go", label}, includedPaths(result),
+		"extensions don't apply to extra files, and one inside the project isn't repeated")
+	for _, file := range result.ProjectOutput.Files {
+		assert.Equal(t, file.Path == label, file.External, file.Path)
+	}
+
+	var external *format.DirectoryNode
+	for _, child := range result.ProjectOutput.DirectoryTree.Children {
+		if child.Name == ExternalDir {
+			external = child
+		}
+	}
+	require.NotNil(t, external)
+	require.Len(t, external.Children, 1)
+	assert.Equal(t, "../shared/api.proto", external.Children[0].Name)
+
+	var streamed []string
+	require.NoError(t, StreamFiles(config, func(file format.FileInfo) error {
+		streamed = append(streamed, file.Path)
+		return nil
+	}))
+	assert.Equal(t, []string{"handler.go", "main.go", label}, streamed)
+
+	for _, bad := range []string{filepath.Join(tmpDir, "shared", "blob.bin"), filepath.Join(tmpDir, "missing.go"), filepath.Join(tmpDir, "shared")} {
+		config.ExtraFiles = []string{bad}
+		_, err := ProcessDirectory(config, false)
+		assert.ErrorContains(t, err, "extra file "+bad, bad)
+	}
+}
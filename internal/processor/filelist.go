@@ -0,0 +1,41 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveFileList turns an explicit list of paths, relative to dirPath or
+// absolute, into the clean relative paths to process in place of a walk (as
+// Config.Roots). Duplicates are dropped; paths that don't exist, such as files
+// deleted in a diff, are returned in missing instead. A path outside dirPath
+// or an empty list is an error.
+func ResolveFileList(dirPath string, paths []string) (files []string, missing []string, err error) {
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no paths given")
+	}
+	seen := make(map[string]bool)
+	for _, p := range paths {
+		abs := p
+		if !filepath.IsAbs(abs) {
+			abs = filepath.Join(dirPath, p)
+		}
+		rel, err := filepath.Rel(dirPath, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, nil, fmt.Errorf("%s is outside %s", p, dirPath)
+		}
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		if _, err := os.Stat(abs); err != nil {
+			missing = append(missing, p)
+			continue
+		}
+		files = append(files, rel)
+	}
+	return files, missing, nil
+}
@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveFileList(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"cmd/main.go":   "package main\n",
+		"internal/a.go": "package internal\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	files, missing, err := ResolveFileList(tmpDir, []string{
+		"cmd/main.go",
+		"./cmd/main.go",
+		filepath.Join(tmpDir, "internal", "a.go"),
+		"deleted.go",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("cmd", "main.go"), filepath.Join("internal", "a.go")}, files)
+	assert.Equal(t, []string{"deleted.go"}, missing)
+
+	_, _, err = ResolveFileList(tmpDir, []string{"../elsewhere.go"})
+	assert.Error(t, err)
+	_, _, err = ResolveFileList(tmpDir, []string{})
+	assert.Error(t, err)
+}
+
+func TestProcessDirectoryFileList(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"cmd/main.go":   "package main\n",
+		"internal/a.go": "package internal\n",
+		"internal/b.go": "package internal\n",
+		"logo.png":      "\x89PNG\r\n\x1a\n\x00\x00",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	files, _, err := ResolveFileList(tmpDir, []string{"internal/b.go", "cmd/main.go", "logo.png"})
+	require.NoError(t, err)
+
+	config := Config{
+		DirPath: tmpDir,
+		Roots:   files,
+		Filter:  filter.New(filter.Options{UseDefaultRules: true}),
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	// Only listed files are read, and binary files are still skipped
+	var paths []string
+	for _, file := range result.ProjectOutput.Files {
+		paths = append(paths, file.Path)
+	}
+	assert.ElementsMatch(t, []string{filepath.Join("internal", "b.go"), filepath.Join("cmd", "main.go")}, paths)
+
+	// The tree shows only the listed files
+	tree := result.ProjectOutput.DirectoryTree.ToMarkdown(1)
+	assert.Contains(t, tree, "b.go")
+	assert.NotContains(t, tree, "a.go")
+}
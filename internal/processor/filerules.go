@@ -0,0 +1,120 @@
+package processor
+
+import (
+	"sync"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/relevance"
+)
+
+// ExplainFileRule is the Explain rule of a file a FileRule excluded
+const ExplainFileRule = "custom-rule"
+
+// Actions of a RuleDecision
+const (
+	RulePass    = iota // No opinion; the built-in rules decide
+	RuleInclude        // Keep the file even if content rules would skip it
+	RuleExclude        // Leave the file out
+)
+
+// RuleDecision is what a FileRule decides about one file
+type RuleDecision struct {
+	Action          int     // RulePass, RuleInclude, or RuleExclude
+	ScoreAdjustment float64 // Added to the file's relevance score when relevance scoring is on
+	Reason          string  // Why, for Explain; the rule's name is used when empty
+}
+
+// FileRule is a caller-supplied rule that decides on each file the built-in
+// path, size and binary checks keep, before any content rule. Decide is
+// called from the load workers concurrently and must be safe for that;
+// content reads the file on first call.
+type FileRule struct {
+	Name   string
+	Decide func(path string, content func() (string, error)) RuleDecision
+}
+
+// fileRuleOutcome combines the decisions of every FileRule on one file
+type fileRuleOutcome struct {
+	excludedBy string // Name of the rule that excluded the file, with its reason
+	included   bool   // A rule kept the file whatever the content rules say
+	adjustment float64
+}
+
+// applyFileRules runs rules on the file at relPath. The first rule that
+// includes or excludes the file decides; score adjustments of all rules add up.
+func applyFileRules(rules []FileRule, relPath string, content func() (string, error)) fileRuleOutcome {
+	var outcome fileRuleOutcome
+	decided := false
+	for _, rule := range rules {
+		decision := rule.Decide(relPath, content)
+		outcome.adjustment += decision.ScoreAdjustment
+		if decided || decision.Action == RulePass {
+			continue
+		}
+		decided = true
+		switch decision.Action {
+		case RuleExclude:
+			outcome.excludedBy = rule.Name
+			if decision.Reason != "" {
+				outcome.excludedBy += ": " + decision.Reason
+			}
+		case RuleInclude:
+			outcome.included = true
+		}
+	}
+	if outcome.excludedBy != "" {
+		log.Debug("Skipping file by rule %s: %s", outcome.excludedBy, relPath)
+	}
+	return outcome
+}
+
+// lazyContent returns a function reading the file at path once, on first call
+func lazyContent(path string, config Config) func() (string, error) {
+	var once sync.Once
+	var content string
+	var err error
+	return func() (string, error) {
+		once.Do(func() { content, err = readFileContent(path, config) })
+		return content, err
+	}
+}
+
+// adjustedScorer adds the score adjustments FileRules made to files to the
+// scores of base. Adjusted scores don't go below 0.
+type adjustedScorer struct {
+	base        relevance.FileScorer
+	adjustments map[string]float64
+}
+
+// withAdjustments wraps scorer with the adjustments of files, or returns it
+// as is when no file has one
+func withAdjustments(scorer relevance.FileScorer, files []format.FileInfo) relevance.FileScorer {
+	adjustments := make(map[string]float64)
+	for _, file := range files {
+		if file.ScoreAdjustment != 0 {
+			adjustments[file.Path] = file.ScoreAdjustment
+		}
+	}
+	if len(adjustments) == 0 {
+		return scorer
+	}
+	return adjustedScorer{base: scorer, adjustments: adjustments}
+}
+
+// baseScorer returns scorer without the FileRules adjustments and recency
+// boost
+func baseScorer(scorer relevance.FileScorer) relevance.FileScorer {
+	if boosted, ok := scorer.(boostedScorer); ok {
+		scorer = boosted.base
+	}
+	if adjusted, ok := scorer.(adjustedScorer); ok {
+		return adjusted.base
+	}
+	return scorer
+}
+
+// ScoreFile implements relevance.FileScorer
+func (s adjustedScorer) ScoreFile(path, content string) float64 {
+	return max(s.base.ScoreFile(path, content)+s.adjustments[path], 0)
+}
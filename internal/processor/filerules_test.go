@@ -0,0 +1,131 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func includedPaths(result *ProcessResult) []string {
+	var paths []string
+	for _, file := range result.ProjectOutput.Files {
+		paths = append(paths, file.Path)
+	}
+	return paths
+}
+
+func TestProcessDirectoryFileRules(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"main.go":         "package main\n",
+		"vendor_ext/a.go": "package ext\n",
+		"patients/db.go":  "package patients\n// PHI-RESTRICTED\n",
+		"gen/types.go":    "// Code generated by protoc. DO NOT EDIT.\npackage gen\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	var reads atomic.Int32
+	rules := []FileRule{
+		{Name: "no-ext", Decide: func(path string, content func() (string, error)) RuleDecision {
+			if strings.HasPrefix(filepath.ToSlash(path), "vendor_ext/") {
+				return RuleDecision{Action: RuleExclude}
+			}
+			return RuleDecision{}
+		}},
+		{Name: "phi", Decide: func(path string, content func() (string, error)) RuleDecision {
+			reads.Add(1)
+			text, err := content()
+			if err == nil && strings.Contains(text, "PHI-RESTRICTED") {
+				return RuleDecision{Action: RuleExclude, Reason: "PHI marker"}
+			}
+			if strings.HasPrefix(filepath.ToSlash(path), "gen/") {
+				return RuleDecision{Action: RuleInclude}
+			}
+			return RuleDecision{}
+		}},
+	}
+	config := Config{
+		DirPath:   tmpDir,
+		Filter:    filter.New(filter.Options{UseDefaultRules: true, SkipGenerated: true}),
+		FileRules: rules,
+	}
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"main.go", "gen/types.go"}, includedPaths(result))
+	assert.Equal(t, int32(4), reads.Load(), "rules after a decision still run, for score adjustments")
+
+	assert.Equal(t, filter.Decision{Excluded: true, Rule: ExplainFileRule, Detail: "phi: PHI marker"}, ExplainFile(config, "patients/db.go"))
+	assert.Equal(t, filter.Decision{Excluded: true, Rule: ExplainFileRule, Detail: "no-ext"}, ExplainFile(config, "vendor_ext/a.go"))
+	assert.False(t, ExplainFile(config, "gen/types.go").Excluded)
+}
+
+func TestProcessDirectoryFileRuleContentIsLazy(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"a.go": "package a\n",
+		"b.go": "package b\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	var reads atomic.Int32
+	config := Config{
+		DirPath: tmpDir,
+		Filter:  filter.New(filter.Options{UseDefaultRules: true}),
+		FileRules: []FileRule{{Name: "by-path", Decide: func(path string, content func() (string, error)) RuleDecision {
+			if path == "a.go" {
+				return RuleDecision{Action: RuleExclude}
+			}
+			_, _ = content()
+			_, _ = content()
+			reads.Add(1)
+			return RuleDecision{}
+		}}},
+	}
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.go"}, includedPaths(result))
+	assert.Equal(t, int32(1), reads.Load())
+}
+
+func TestProcessDirectoryFileRuleScoreAdjustment(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"auth/login.go":   "package auth\n// login handler\n",
+		"utils/common.go": "package utils\n",
+		"auth/legacy.go":  "package auth\n// old login flow\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:           tmpDir,
+		Filter:            filter.New(filter.Options{UseDefaultRules: true}),
+		RelevanceKeywords: "login",
+		ExplainSelection:  true,
+		FileRules: []FileRule{{Name: "boost", Decide: func(path string, content func() (string, error)) RuleDecision {
+			switch filepath.ToSlash(path) {
+			case "utils/common.go":
+				return RuleDecision{ScoreAdjustment: 50}
+			case "auth/legacy.go":
+				return RuleDecision{ScoreAdjustment: -100}
+			}
+			return RuleDecision{}
+		}}},
+	}
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	report := result.ProjectOutput.Selection
+	require.NotNil(t, report)
+	require.Len(t, report.Files, 3)
+	assert.Equal(t, "utils/common.go", report.Files[0].Path)
+	assert.Equal(t, 50.0, report.Files[0].Score)
+	assert.True(t, report.Files[0].Included)
+	assert.Equal(t, "auth/legacy.go", report.Files[2].Path)
+	assert.Equal(t, 0.0, report.Files[2].Score)
+	assert.False(t, report.Files[2].Included)
+}
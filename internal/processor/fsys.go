@@ -0,0 +1,43 @@
+package processor
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"github.com/1broseidon/promptext/internal/longpath"
+)
+
+// fsName maps a path under config.DirPath to its name in config.FS
+func fsName(config Config, path string) string {
+	rel, err := filepath.Rel(config.DirPath, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// statFile stats path on disk or, when config.FS is set, in config.FS
+func statFile(config Config, path string) (fs.FileInfo, error) {
+	if config.FS != nil {
+		return fs.Stat(config.FS, fsName(config, path))
+	}
+	return os.Stat(longpath.Fix(path))
+}
+
+// readFile reads path from disk or, when config.FS is set, from config.FS
+func readFile(config Config, path string) ([]byte, error) {
+	if config.FS != nil {
+		return fs.ReadFile(config.FS, fsName(config, path))
+	}
+	return os.ReadFile(longpath.Fix(path))
+}
+
+// isBinaryFile reports whether BinaryRule considers the file at path binary
+func isBinaryFile(config Config, path string) bool {
+	if config.FS != nil {
+		return rules.NewBinaryRule().(*rules.BinaryRule).MatchFS(config.FS, fsName(config, path))
+	}
+	return rules.NewBinaryRule().Match(longpath.Fix(path))
+}
@@ -0,0 +1,49 @@
+package processor
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"go.mod":            {Data: []byte("module example.com/project\n"), Mode: 0644},
+		"cmd/main.go":       {Data: []byte("package main\n\nimport \"example.com/project/internal/db\"\n"), Mode: 0644},
+		"internal/db/db.go": {Data: []byte("package db\n"), Mode: 0644},
+		"logo.png":          {Data: []byte("\x89PNG\r\n\x1a\n\x00\x00"), Mode: 0644},
+	}
+
+	// The root only names the project; nothing is read from the disk
+	config := Config{
+		DirPath: filepath.Join(string(filepath.Separator), "project"),
+		Filter:  filter.New(filter.Options{UseDefaultRules: true}),
+		FS:      fsys,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, file := range result.ProjectOutput.Files {
+		paths = append(paths, filepath.ToSlash(file.Path))
+	}
+	assert.ElementsMatch(t, []string{"go.mod", "cmd/main.go", "internal/db/db.go"}, paths)
+
+	tree := result.ProjectOutput.DirectoryTree
+	require.NotNil(t, tree)
+	assert.Equal(t, "project", tree.Name)
+	assert.Contains(t, tree.ToMarkdown(1), "db.go")
+
+	// go.mod is read from the FS to resolve package imports
+	var imports []string
+	for _, pkg := range result.ProjectOutput.Packages {
+		if pkg.Path == "cmd" {
+			imports = pkg.Imports
+		}
+	}
+	assert.Equal(t, []string{"internal/db"}, imports)
+}
@@ -0,0 +1,42 @@
+//go:build windows
+
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/longpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryLongPaths(t *testing.T) {
+	root := t.TempDir()
+	deep := root
+	for len(deep) < 300 {
+		deep = filepath.Join(deep, strings.Repeat("d", 40))
+	}
+	require.NoError(t, os.MkdirAll(longpath.Fix(deep), 0755))
+	require.NoError(t, os.WriteFile(longpath.Fix(filepath.Join(deep, "main.go")), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app.go"), []byte("package app\n"), 0644))
+
+	config := Config{
+		DirPath: root,
+		Filter:  filter.New(filter.Options{UseDefaultRules: true}),
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	// Paths stay relative to the root, without the \\?\ prefix
+	var paths []string
+	for _, file := range result.ProjectOutput.Files {
+		paths = append(paths, filepath.ToSlash(file.Path))
+	}
+	rel, err := filepath.Rel(root, filepath.Join(deep, "main.go"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"app.go", filepath.ToSlash(rel)}, paths)
+}
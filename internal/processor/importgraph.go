@@ -0,0 +1,233 @@
+package processor
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/relevance"
+)
+
+// importGraph maps each file path to the project files it imports directly
+type importGraph map[string][]string
+
+// buildImportGraph resolves the imports of Go, JS/TS and Python files to
+// other files in the set. Go imports within the module resolve to every
+// non-test file of the imported package; relative JS/TS specifiers resolve to
+// the file or index module they name; Python modules resolve to their .py
+// file or package __init__.py, from the project root or src/. Imports of
+// anything outside files are ignored.
+func buildImportGraph(files []format.FileInfo, config Config) importGraph {
+	// Index slash-separated paths back to the file paths used as keys
+	byPath := make(map[string]string, len(files))
+	goDirs := make(map[string][]string)
+	for _, file := range files {
+		p := filepath.ToSlash(file.Path)
+		byPath[p] = file.Path
+		if path.Ext(p) == ".go" && !strings.HasSuffix(p, "_test.go") {
+			goDirs[path.Dir(p)] = append(goDirs[path.Dir(p)], file.Path)
+		}
+	}
+	modulePath := readGoModulePath(config)
+
+	graph := make(importGraph)
+	for _, file := range files {
+		p := filepath.ToSlash(file.Path)
+		dir := path.Dir(p)
+		ext := path.Ext(p)
+
+		seen := map[string]bool{file.Path: true}
+		add := func(target string) {
+			if !seen[target] {
+				seen[target] = true
+				graph[file.Path] = append(graph[file.Path], target)
+			}
+		}
+
+		for _, spec := range fileImports(file) {
+			switch {
+			case ext == ".go":
+				if modulePath == "" {
+					continue
+				}
+				pkgDir := ""
+				if spec == modulePath {
+					pkgDir = "."
+				} else if rel, ok := strings.CutPrefix(spec, modulePath+"/"); ok {
+					pkgDir = rel
+				} else {
+					continue
+				}
+				for _, target := range goDirs[pkgDir] {
+					add(target)
+				}
+
+			case jsExtensions[ext]:
+				if target, ok := resolveJSImport(byPath, dir, spec); ok {
+					add(target)
+				}
+
+			case ext == ".py":
+				if target, ok := resolvePythonImport(byPath, dir, spec); ok {
+					add(target)
+				}
+			}
+		}
+	}
+	return graph
+}
+
+// fileImports returns the module specifiers a file imports, including those
+// moved out of its content by import stripping
+func fileImports(file format.FileInfo) []string {
+	specs := append([]string(nil), file.Imports...)
+	if jsExtensions[strings.ToLower(filepath.Ext(file.Path))] {
+		for _, pattern := range jsImportPatterns {
+			for _, match := range pattern.FindAllStringSubmatch(file.Content, -1) {
+				specs = append(specs, match[1])
+			}
+		}
+		return specs
+	}
+	_, imports := stripImports(file.Path, file.Content)
+	return append(specs, imports...)
+}
+
+// resolveJSImport finds the file a relative specifier names, trying the
+// path as written, with each JS/TS extension, and as a directory index.
+// TypeScript's ".js" specifiers for ".ts" sources are resolved too.
+func resolveJSImport(byPath map[string]string, dir, spec string) (string, bool) {
+	if !strings.HasPrefix(spec, ".") {
+		return "", false
+	}
+	base := path.Join(dir, spec)
+	candidates := []string{base}
+	if stem, ok := strings.CutSuffix(base, ".js"); ok {
+		candidates = append(candidates, stem+".ts", stem+".tsx")
+	}
+	for _, ext := range []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"} {
+		candidates = append(candidates, base+ext)
+	}
+	for _, ext := range []string{".ts", ".tsx", ".js", ".jsx"} {
+		candidates = append(candidates, base+"/index"+ext)
+	}
+	return firstExisting(byPath, candidates)
+}
+
+// resolvePythonImport finds the module file for a dotted import. Leading dots
+// make it relative to the importing file's package.
+func resolvePythonImport(byPath map[string]string, dir, spec string) (string, bool) {
+	var roots []string
+	module := strings.TrimLeft(spec, ".")
+	if dots := len(spec) - len(module); dots > 0 {
+		base := dir
+		for i := 1; i < dots; i++ {
+			base = path.Dir(base)
+		}
+		roots = []string{base}
+	} else {
+		roots = []string{".", "src"}
+	}
+
+	var candidates []string
+	for _, root := range roots {
+		modPath := path.Join(root, strings.ReplaceAll(module, ".", "/"))
+		if module == "" {
+			candidates = append(candidates, path.Join(modPath, "__init__.py"))
+			continue
+		}
+		candidates = append(candidates, modPath+".py", path.Join(modPath, "__init__.py"))
+	}
+	return firstExisting(byPath, candidates)
+}
+
+// firstExisting returns the file for the first candidate path in byPath
+func firstExisting(byPath map[string]string, candidates []string) (string, bool) {
+	for _, candidate := range candidates {
+		if file, ok := byPath[candidate]; ok {
+			return file, true
+		}
+	}
+	return "", false
+}
+
+// reachable returns the files imported by seeds, directly or through up to
+// depth-1 further imports, in breadth-first order. Seeds themselves are not
+// included.
+func (g importGraph) reachable(seeds []string, depth int) []string {
+	visited := make(map[string]bool, len(seeds))
+	for _, seed := range seeds {
+		visited[seed] = true
+	}
+
+	var found []string
+	frontier := seeds
+	for level := 0; level < depth && len(frontier) > 0; level++ {
+		var next []string
+		for _, file := range frontier {
+			for _, target := range g[file] {
+				if !visited[target] {
+					visited[target] = true
+					found = append(found, target)
+					next = append(next, target)
+				}
+			}
+		}
+		frontier = next
+	}
+	return found
+}
+
+// followImports moves the files imported by highly relevant files (up to
+// depth hops away) to just after the last of them, so the token budget
+// reaches a relevant file's dependencies before weaker keyword matches.
+// files must already be in priority order. The returned set holds the
+// followed files, which are kept even if they score 0.
+func followImports(files []format.FileInfo, scorer relevance.FileScorer, depth int, config Config) ([]format.FileInfo, map[string]bool) {
+	if depth <= 0 || len(files) == 0 {
+		return files, nil
+	}
+
+	threshold := relevance.GetRelevanceThreshold()
+	var seeds []string
+	lastSeed := -1
+	for i, file := range files {
+		if scorer.ScoreFile(file.Path, file.Content) >= threshold {
+			seeds = append(seeds, file.Path)
+			lastSeed = i
+		}
+	}
+	if len(seeds) == 0 {
+		return files, nil
+	}
+
+	imported := buildImportGraph(files, config).reachable(seeds, depth)
+	if len(imported) == 0 {
+		return files, nil
+	}
+	followed := make(map[string]bool, len(imported))
+	for _, p := range imported {
+		followed[p] = true
+	}
+
+	// Imports already ranked ahead of the last seed keep their place
+	index := make(map[string]int, len(files))
+	for i, file := range files {
+		index[file.Path] = i
+	}
+	ordered := make([]format.FileInfo, 0, len(files))
+	for i, file := range files {
+		if i <= lastSeed || !followed[file.Path] {
+			ordered = append(ordered, file)
+		}
+		if i == lastSeed {
+			for _, p := range imported {
+				if index[p] > lastSeed {
+					ordered = append(ordered, files[index[p]])
+				}
+			}
+		}
+	}
+	return ordered, followed
+}
@@ -0,0 +1,106 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/relevance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildImportGraphAcrossLanguages(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(tmpDir+"/go.mod", []byte("module example.com/app\n"), 0644))
+
+	files := []format.FileInfo{
+		// Go: module imports resolve to the package's non-test files
+		{Path: "auth/handler.go", Content: "package auth\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/store\"\n)\n"},
+		{Path: "store/store.go", Content: "package store\n"},
+		{Path: "store/cache.go", Content: "package store\n"},
+		{Path: "store/store_test.go", Content: "package store\n"},
+		// TypeScript: extensionless, .js-for-.ts and index specifiers
+		{Path: "web/app.ts", Content: "import { api } from './api'\nimport { log } from './log.js'\nimport * as ui from './ui'\nimport React from 'react'\n"},
+		{Path: "web/api.ts"},
+		{Path: "web/log.ts"},
+		{Path: "web/ui/index.tsx"},
+		// Python: absolute and relative imports
+		{Path: "svc/main.py", Content: "import os\nfrom svc.models import User\nfrom .util import slug\n"},
+		{Path: "svc/models/__init__.py"},
+		{Path: "svc/util.py"},
+	}
+
+	graph := buildImportGraph(files, Config{DirPath: tmpDir})
+
+	assert.ElementsMatch(t, []string{"store/store.go", "store/cache.go"}, graph["auth/handler.go"])
+	assert.ElementsMatch(t, []string{"web/api.ts", "web/log.ts", "web/ui/index.tsx"}, graph["web/app.ts"])
+	assert.ElementsMatch(t, []string{"svc/models/__init__.py", "svc/util.py"}, graph["svc/main.py"])
+	assert.Empty(t, graph["store/store.go"])
+}
+
+func TestImportGraphReachableHonoursDepth(t *testing.T) {
+	graph := importGraph{
+		"a.go": {"b.go"},
+		"b.go": {"c.go", "a.go"},
+		"c.go": {"d.go"},
+	}
+
+	assert.Equal(t, []string{"b.go"}, graph.reachable([]string{"a.go"}, 1))
+	assert.Equal(t, []string{"b.go", "c.go"}, graph.reachable([]string{"a.go"}, 2))
+	assert.Equal(t, []string{"b.go", "c.go", "d.go"}, graph.reachable([]string{"a.go"}, 5))
+}
+
+func TestFollowImportsRanksImportsAfterSeeds(t *testing.T) {
+	files := []format.FileInfo{
+		{Path: "auth/handler.ts", Content: "import { hash } from '../crypto/hash'\n// auth"},
+		{Path: "docs/auth.ts", Content: "// auth"},
+		{Path: "crypto/hash.ts"},
+		{Path: "misc.ts"},
+	}
+	scorer := relevance.StaticScorer{"auth/handler.ts": 20, "docs/auth.ts": 1}
+
+	ordered, followed := followImports(files, scorer, 1, Config{DirPath: t.TempDir()})
+
+	var paths []string
+	for _, f := range ordered {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"auth/handler.ts", "crypto/hash.ts", "docs/auth.ts", "misc.ts"}, paths)
+	assert.Equal(t, map[string]bool{"crypto/hash.ts": true}, followed)
+}
+
+func TestProcessDirectoryFollowImports(t *testing.T) {
+	files := map[string]string{
+		"go.mod":             "module example.com/app\n",
+		"auth/handler.go":    "package auth\n\nimport \"example.com/app/session\"\n\nvar _ = session.New\n",
+		"session/token.go":   "package session\n\nfunc New() {}\n",
+		"billing/invoice.go": "package billing\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:           tmpDir,
+		Filter:            filter.New(filter.Options{UseDefaultRules: true}),
+		RelevanceKeywords: "auth",
+	}
+
+	paths := func(result *ProcessResult) []string {
+		var included []string
+		for _, f := range result.ProjectOutput.Files {
+			included = append(included, f.Path)
+		}
+		return included
+	}
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth/handler.go"}, paths(result))
+
+	config.FollowImports = 1
+	result, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"auth/handler.go", "session/token.go"}, paths(result))
+}
@@ -0,0 +1,142 @@
+package processor
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// span is a byte range [start, end) of file content
+type span struct {
+	start, end int
+}
+
+var (
+	// ES module imports, including multi-line named imports and bare side-effect imports
+	jsImportStmt = regexp.MustCompile(`(?m)^[ \t]*import\s+(?:type\s+)?(?:[\w*$\s{},]+?\s+from\s*)?['"]([^'"\n]+)['"][ \t]*;?[ \t]*(?:\r?\n|$)`)
+	// CommonJS top-level requires: const x = require('y')
+	jsRequireStmt = regexp.MustCompile(`(?m)^[ \t]*(?:const|let|var)\s+[\w$\s{},:]+=\s*require\(\s*['"]([^'"\n]+)['"]\s*\)[ \t]*;?[ \t]*(?:\r?\n|$)`)
+	// Top-level Python imports: "import a, b as c" and "from x import (...)"
+	pyImportStmt     = regexp.MustCompile(`(?m)^import[ \t]+([^\n#]+)(?:#[^\n]*)?(?:\r?\n|$)`)
+	pyFromImportStmt = regexp.MustCompile(`(?m)^from[ \t]+(\S+)[ \t]+import[ \t]+(?:\([^)]*\)|[^\n]*)[^\n]*(?:\r?\n|$)`)
+)
+
+// stripImports removes import statements from Go, JS/TS and Python source and
+// returns the remaining content along with the imported module paths. Content
+// of other languages, or Go files that fail to parse, is returned unchanged.
+func stripImports(path, content string) (string, []string) {
+	var spans []span
+	var imports []string
+
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".go":
+		fset := token.NewFileSet()
+		parsed, err := parser.ParseFile(fset, path, content, parser.ImportsOnly)
+		if err != nil {
+			return content, nil
+		}
+		for _, decl := range parsed.Decls {
+			start := fset.Position(decl.Pos()).Offset
+			end := fset.Position(decl.End()).Offset
+			if end < len(content) && content[end] == '\n' {
+				end++
+			}
+			spans = append(spans, span{start, end})
+		}
+		for _, spec := range parsed.Imports {
+			if p, err := strconv.Unquote(spec.Path.Value); err == nil {
+				imports = append(imports, p)
+			}
+		}
+
+	case jsExtensions[ext]:
+		for _, re := range []*regexp.Regexp{jsImportStmt, jsRequireStmt} {
+			for _, m := range re.FindAllStringSubmatchIndex(content, -1) {
+				spans = append(spans, span{m[0], m[1]})
+				imports = append(imports, content[m[2]:m[3]])
+			}
+		}
+
+	case ext == ".py":
+		for _, m := range pyImportStmt.FindAllStringSubmatchIndex(content, -1) {
+			spans = append(spans, span{m[0], m[1]})
+			for _, name := range strings.Split(content[m[2]:m[3]], ",") {
+				if fields := strings.Fields(name); len(fields) > 0 {
+					imports = append(imports, fields[0])
+				}
+			}
+		}
+		for _, m := range pyFromImportStmt.FindAllStringSubmatchIndex(content, -1) {
+			spans = append(spans, span{m[0], m[1]})
+			imports = append(imports, content[m[2]:m[3]])
+		}
+
+	default:
+		return content, nil
+	}
+
+	if len(spans) == 0 {
+		return content, nil
+	}
+	return removeSpans(content, spans), dedupeSorted(imports)
+}
+
+// removeSpans cuts the given ranges out of content. Where a cut leaves two
+// blank lines in a row (the usual blank line before and after an import
+// block), one of them is dropped.
+func removeSpans(content string, spans []span) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var sb strings.Builder
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			continue // overlapping match
+		}
+		sb.WriteString(content[last:s.start])
+		last = s.end
+		if strings.HasSuffix(sb.String(), "\n\n") && strings.HasPrefix(content[last:], "\n") {
+			last++
+		}
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// dedupeSorted returns the unique values in sorted order
+func dedupeSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// buildDependencyInfo summarizes the imports stripped from files into the
+// dependencies section: per-file imports plus the unique set across files
+func buildDependencyInfo(files []format.FileInfo) *format.DependencyInfo {
+	deps := &format.DependencyInfo{Imports: make(map[string][]string)}
+	var all []string
+	for _, file := range files {
+		if len(file.Imports) > 0 {
+			deps.Imports[file.Path] = file.Imports
+			all = append(all, file.Imports...)
+		}
+	}
+	if len(deps.Imports) == 0 {
+		return nil
+	}
+	deps.Packages = dedupeSorted(all)
+	return deps
+}
@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripImports(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		content     string
+		wantContent string
+		wantImports []string
+	}{
+		{
+			name:        "go import block",
+			path:        "main.go",
+			content:     "package main\n\nimport (\n\t\"fmt\"\n\tstr \"strings\"\n)\n\nfunc main() { fmt.Println(str.ToUpper(\"x\")) }\n",
+			wantContent: "package main\n\nfunc main() { fmt.Println(str.ToUpper(\"x\")) }\n",
+			wantImports: []string{"fmt", "strings"},
+		},
+		{
+			name:        "go single imports",
+			path:        "util.go",
+			content:     "package util\n\nimport \"os\"\nimport \"io\"\n\nvar _ = os.Stdout\n",
+			wantContent: "package util\n\nvar _ = os.Stdout\n",
+			wantImports: []string{"io", "os"},
+		},
+		{
+			name:        "typescript imports and requires",
+			path:        "src/app.ts",
+			content:     "import React from 'react';\nimport {\n  a,\n  b,\n} from \"./util\";\nimport './styles.css';\nconst fs = require('fs');\n\nexport const x = a + b;\n",
+			wantContent: "\nexport const x = a + b;\n",
+			wantImports: []string{"./styles.css", "./util", "fs", "react"},
+		},
+		{
+			name:        "python imports",
+			path:        "app.py",
+			content:     "import os, sys as system\nfrom typing import (\n    List,\n    Dict,\n)\n\n\ndef main():\n    import json\n    return os.getcwd()\n",
+			wantContent: "\n\ndef main():\n    import json\n    return os.getcwd()\n",
+			wantImports: []string{"os", "sys", "typing"},
+		},
+		{
+			name:        "unsupported language is untouched",
+			path:        "main.rs",
+			content:     "use std::io;\n",
+			wantContent: "use std::io;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, imports := stripImports(tt.path, tt.content)
+			assert.Equal(t, tt.wantContent, content)
+			assert.Equal(t, tt.wantImports, imports)
+		})
+	}
+}
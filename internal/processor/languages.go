@@ -0,0 +1,113 @@
+package processor
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// otherLanguage groups the files no entry of fileLanguages names
+const otherLanguage = "Other"
+
+// fileLanguages maps file extensions to language names, as GitHub's
+// linguist spells them
+var fileLanguages = map[string]string{
+	".go": "Go", ".py": "Python", ".pyi": "Python", ".ipynb": "Jupyter Notebook",
+	".js": "JavaScript", ".jsx": "JavaScript", ".mjs": "JavaScript", ".cjs": "JavaScript",
+	".ts": "TypeScript", ".tsx": "TypeScript", ".mts": "TypeScript", ".cts": "TypeScript",
+	".vue": "Vue", ".svelte": "Svelte", ".astro": "Astro",
+	".java": "Java", ".kt": "Kotlin", ".kts": "Kotlin", ".scala": "Scala", ".groovy": "Groovy", ".gradle": "Groovy",
+	".c": "C", ".h": "C", ".cc": "C++", ".cpp": "C++", ".cxx": "C++", ".hpp": "C++", ".hh": "C++",
+	".cs": "C#", ".fs": "F#", ".vb": "Visual Basic .NET", ".m": "Objective-C", ".mm": "Objective-C++",
+	".swift": "Swift", ".rs": "Rust", ".zig": "Zig", ".nim": "Nim", ".d": "D",
+	".rb": "Ruby", ".php": "PHP", ".pl": "Perl", ".pm": "Perl", ".lua": "Lua", ".r": "R",
+	".dart": "Dart", ".ex": "Elixir", ".exs": "Elixir", ".erl": "Erlang", ".hs": "Haskell",
+	".ml": "OCaml", ".mli": "OCaml", ".clj": "Clojure", ".cljs": "Clojure", ".jl": "Julia",
+	".sh": "Shell", ".bash": "Shell", ".zsh": "Shell", ".fish": "Shell", ".ps1": "PowerShell", ".bat": "Batchfile",
+	".sql": "SQL", ".proto": "Protocol Buffer", ".graphql": "GraphQL", ".gql": "GraphQL",
+	".tf": "HCL", ".hcl": "HCL", ".cmake": "CMake",
+	".html": "HTML", ".htm": "HTML", ".css": "CSS", ".scss": "SCSS", ".sass": "Sass", ".less": "Less",
+	".md": "Markdown", ".mdx": "MDX", ".rst": "reStructuredText", ".adoc": "AsciiDoc", ".tex": "TeX", ".txt": "Text",
+	".json": "JSON", ".jsonc": "JSON", ".yml": "YAML", ".yaml": "YAML", ".toml": "TOML", ".xml": "XML",
+	".ini": "INI", ".csv": "CSV", ".tsv": "TSV",
+}
+
+// fileNameLanguages maps the names of files without a telling extension
+var fileNameLanguages = map[string]string{
+	"Dockerfile": "Dockerfile", "Containerfile": "Dockerfile", "Makefile": "Makefile", "GNUmakefile": "Makefile",
+	"CMakeLists.txt": "CMake", "Rakefile": "Ruby", "Gemfile": "Ruby", "Jenkinsfile": "Groovy",
+	"go.mod": "Go Module", "go.sum": "Go Checksums",
+}
+
+// languageOf returns the language of a file from its name, or otherLanguage
+func languageOf(path string) string {
+	base := filepath.Base(path)
+	if lang, ok := fileNameLanguages[base]; ok {
+		return lang
+	}
+	if strings.HasPrefix(base, "Dockerfile.") {
+		return "Dockerfile"
+	}
+	if lang, ok := fileLanguages[strings.ToLower(filepath.Ext(base))]; ok {
+		return lang
+	}
+	return otherLanguage
+}
+
+// languageBreakdown totals the files, lines and tokens of each language among
+// files and their share of the whole, most tokens first. Files in no known
+// language are grouped under otherLanguage. It returns nil for files all in
+// one language, which the breakdown would add nothing to.
+func languageBreakdown(files []format.FileInfo) []format.LanguageShare {
+	byName := make(map[string]*format.LanguageShare)
+	var lines, tokens int
+	for _, file := range files {
+		lang := languageOf(file.Path)
+		share, ok := byName[lang]
+		if !ok {
+			share = &format.LanguageShare{Name: lang}
+			byName[lang] = share
+		}
+		n := 0
+		if file.Content != "" {
+			n = strings.Count(file.Content, "\n") + 1
+		}
+		share.Files++
+		share.Lines += n
+		share.Tokens += file.Tokens
+		lines += n
+		tokens += file.Tokens
+	}
+	if len(byName) < 2 {
+		return nil
+	}
+
+	breakdown := make([]format.LanguageShare, 0, len(byName))
+	for _, share := range byName {
+		share.LinePercent = percentOf(share.Lines, lines)
+		share.TokenPercent = percentOf(share.Tokens, tokens)
+		breakdown = append(breakdown, *share)
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		a, b := breakdown[i], breakdown[j]
+		if a.Tokens != b.Tokens {
+			return a.Tokens > b.Tokens
+		}
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		return a.Name < b.Name
+	})
+	return breakdown
+}
+
+// percentOf returns part as a percentage of total, to one decimal place
+func percentOf(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)*1000/float64(total)) / 10
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguageOf(t *testing.T) {
+	tests := map[string]string{
+		"cmd/main.go":       "Go",
+		"web/App.tsx":       "TypeScript",
+		"lib/util.MJS":      "JavaScript",
+		"deploy/Dockerfile": "Dockerfile",
+		"Dockerfile.dev":    "Dockerfile",
+		"Makefile":          "Makefile",
+		"go.mod":            "Go Module",
+		"docs/README.md":    "Markdown",
+		"assets/logo.svgz":  "Other",
+		"LICENSE":           "Other",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, languageOf(path), path)
+	}
+}
+
+func TestLanguageBreakdown(t *testing.T) {
+	breakdown := languageBreakdown([]format.FileInfo{
+		{Path: "main.go", Content: "package main\n\nfunc main() {}", Tokens: 60},
+		{Path: "util.go", Content: "package main", Tokens: 15},
+		{Path: "README.md", Content: "# Title\nText", Tokens: 20},
+		{Path: "LICENSE", Content: "MIT", Tokens: 5},
+	})
+	assert.Equal(t, []format.LanguageShare{
+		{Name: "Go", Files: 2, Lines: 4, Tokens: 75, LinePercent: 57.1, TokenPercent: 75},
+		{Name: "Markdown", Files: 1, Lines: 2, Tokens: 20, LinePercent: 28.6, TokenPercent: 20},
+		{Name: "Other", Files: 1, Lines: 1, Tokens: 5, LinePercent: 14.3, TokenPercent: 5},
+	}, breakdown)
+
+	assert.Nil(t, languageBreakdown([]format.FileInfo{{Path: "a.go", Content: "package a"}, {Path: "b.go", Content: "package b"}}))
+	assert.Nil(t, languageBreakdown(nil))
+}
+
+func TestProcessDirectoryLanguages(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"web/app.ts":  "export const app = 1\n",
+		"web/view.ts": "export const view = 2\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	result, err := ProcessDirectory(Config{
+		DirPath:    tmpDir,
+		Extensions: []string{".go", ".ts"},
+		Filter:     filter.New(filter.Options{UseDefaultRules: true}),
+	}, false)
+	require.NoError(t, err)
+	require.NotNil(t, result.ProjectOutput.Metadata)
+
+	languages := result.ProjectOutput.Metadata.Languages
+	require.Len(t, languages, 2)
+	names := map[string]int{languages[0].Name: languages[0].Files, languages[1].Name: languages[1].Files}
+	assert.Equal(t, map[string]int{"Go": 1, "TypeScript": 2}, names)
+	assert.InDelta(t, 100, languages[0].TokenPercent+languages[1].TokenPercent, 0.11)
+	assert.InDelta(t, 100, languages[0].LinePercent+languages[1].LinePercent, 0.11)
+}
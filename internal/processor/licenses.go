@@ -0,0 +1,247 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// licenseFileNames are the files read as the license of their directory
+var licenseFileNames = []string{
+	"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "LICENCE.md",
+	"COPYING", "COPYING.md", "LICENSE-MIT", "LICENSE-APACHE",
+}
+
+// licenseHeaderLines is how far into a file license headers are looked for.
+// A header is a comment line holding only the SPDX tag, so the tag in a
+// string or in prose isn't taken for one.
+const licenseHeaderLines = 30
+
+var (
+	spdxHeader      = regexp.MustCompile(`^\s*(?://+|#+|/\*+|\*|--|<!--|;+)?\s*SPDX-License-Identifier:\s*([\w.+:() -]+?)\s*(?:\*/|-->)?\s*$`)
+	copyrightNotice = regexp.MustCompile(`(?i)\b(copyright\s+(?:\(c\)\s*|©\s*)?\d{4}.*?)\s*(?:\*/|-->)?\s*$`)
+)
+
+// licenseTexts recognize license files by phrases of their text, tried in
+// order so that, say, the LGPL isn't taken for the GPL it mentions
+var licenseTexts = []struct {
+	id      string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"GNU AFFERO GENERAL PUBLIC LICENSE"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-2.1", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 2.1"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 2"}},
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"MPL-2.0", []string{"Mozilla Public License", "2.0"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "Neither the name"}},
+	{"BSD-2-Clause", []string{"Redistribution and use in source and binary forms"}},
+	{"ISC", []string{"Permission to use, copy, modify, and/or distribute this software"}},
+	{"MIT", []string{"Permission is hereby granted, free of charge"}},
+	{"Unlicense", []string{"This is free and unencumbered software released into the public domain"}},
+}
+
+// notLicensed are extensions commentLanguages knows that hold data or markup
+// rather than code, so a missing license header isn't flagged
+var notLicensed = map[string]bool{
+	".yaml": true, ".yml": true, ".toml": true, ".xml": true, ".svg": true, ".html": true, ".htm": true,
+}
+
+// readLicenseHeader sets the SPDX expression and copyright notice of file
+// from the first licenseHeaderLines lines of its content
+func readLicenseHeader(file *format.FileInfo) {
+	lines := strings.SplitN(file.Content, "\n", licenseHeaderLines+1)
+	for _, line := range lines[:min(len(lines), licenseHeaderLines)] {
+		if m := spdxHeader.FindStringSubmatch(line); m != nil && file.License == "" {
+			file.License = m[1]
+		}
+		if m := copyrightNotice.FindStringSubmatch(line); m != nil && file.Copyright == "" {
+			file.Copyright = m[1]
+		}
+	}
+}
+
+// identifyLicense returns the SPDX identifier of a LICENSE file's text: the
+// SPDX-License-Identifier line when it has one, else the license whose
+// phrases it contains, else "unknown"
+func identifyLicense(text string) string {
+	if m := spdxHeader.FindStringSubmatch(text[:strings.IndexByte(text+"\n", '\n')]); m != nil {
+		return m[1]
+	}
+	for _, license := range licenseTexts {
+		found := true
+		for _, phrase := range license.phrases {
+			found = found && strings.Contains(text, phrase)
+		}
+		if found {
+			return license.id
+		}
+	}
+	return "unknown"
+}
+
+// spdxIDs returns the license identifiers of an SPDX expression such as
+// "(MIT OR Apache-2.0)" or "GPL-2.0-only WITH Classpath-exception-2.0",
+// leaving out the operators and exceptions
+func spdxIDs(expression string) []string {
+	var ids []string
+	fields := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(expression))
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "AND", "OR", "and", "or":
+		case "WITH", "with":
+			i++
+		default:
+			ids = append(ids, fields[i])
+		}
+	}
+	return ids
+}
+
+// licenseFile is a LICENSE file, the license it declares and its
+// copyright notice
+type licenseFile struct {
+	path      string
+	id        string
+	copyright string
+}
+
+// scanLicenses builds the license inventory of files. Each file is governed
+// by the LICENSE files of the nearest directory above it that has any. A
+// source file is flagged when its SPDX header names none of the licenses
+// governing it, or when it has no header while other source files do.
+func scanLicenses(config Config, files []format.FileInfo) *format.LicenseInfo {
+	dirs := make(map[string][]licenseFile)
+	read := func(dir string) []licenseFile {
+		if found, ok := dirs[dir]; ok {
+			return found
+		}
+		var found []licenseFile
+		for _, name := range licenseFileNames {
+			data, err := readFile(config, filepath.Join(config.DirPath, dir, name))
+			if err == nil {
+				header := format.FileInfo{Content: string(data)}
+				readLicenseHeader(&header)
+				found = append(found, licenseFile{path: filepath.Join(dir, name), id: identifyLicense(header.Content), copyright: header.Copyright})
+			}
+		}
+		dirs[dir] = found
+		return found
+	}
+	governing := func(path string) []licenseFile {
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			if found := read(dir); len(found) > 0 || dir == "." {
+				return found
+			}
+		}
+	}
+
+	read(".")
+	usesHeaders := false
+	for _, file := range files {
+		if !file.External && file.License != "" && isLicensedSource(file.Path) {
+			usesHeaders = true
+		}
+	}
+
+	info := &format.LicenseInfo{}
+	byID := make(map[string]*format.LicenseSummary)
+	summary := func(id string) *format.LicenseSummary {
+		if byID[id] == nil {
+			byID[id] = &format.LicenseSummary{ID: id}
+		}
+		return byID[id]
+	}
+	copyrights := make(map[string]bool)
+	for _, file := range files {
+		if file.External {
+			continue
+		}
+		if file.Copyright != "" {
+			copyrights[file.Copyright] = true
+		}
+		for _, id := range spdxIDs(file.License) {
+			summary(id).Files++
+		}
+		if !isLicensedSource(file.Path) {
+			continue
+		}
+		licenses := governing(file.Path)
+		switch {
+		case file.License == "" && usesHeaders:
+			detail := "no SPDX-License-Identifier header"
+			if len(licenses) > 0 {
+				detail += fmt.Sprintf("; %s declares %s", licenses[0].path, licenses[0].id)
+			}
+			info.Flagged = append(info.Flagged, format.LicenseFlag{Path: file.Path, Reason: "missing-header", Detail: detail})
+		case file.License != "" && conflicts(spdxIDs(file.License), licenses):
+			info.Flagged = append(info.Flagged, format.LicenseFlag{
+				Path:   file.Path,
+				Reason: "conflicting-header",
+				Detail: fmt.Sprintf("header says %s; %s declares %s", file.License, licenses[0].path, licenses[0].id),
+			})
+		}
+	}
+
+	for _, found := range dirs {
+		for _, lf := range found {
+			summary(lf.id).Sources = append(summary(lf.id).Sources, lf.path)
+			if lf.copyright != "" {
+				copyrights[lf.copyright] = true
+			}
+		}
+	}
+
+	for _, s := range byID {
+		sort.Strings(s.Sources)
+		info.Licenses = append(info.Licenses, *s)
+	}
+	sort.Slice(info.Licenses, func(i, j int) bool { return info.Licenses[i].ID < info.Licenses[j].ID })
+	for notice := range copyrights {
+		info.Copyrights = append(info.Copyrights, notice)
+	}
+	sort.Strings(info.Copyrights)
+	return info
+}
+
+// conflicts reports whether a header's license identifiers name none of the
+// recognized licenses of the LICENSE files governing the file. Files with no
+// LICENSE file, or only unrecognized ones, don't conflict.
+func conflicts(header []string, licenses []licenseFile) bool {
+	known := make(map[string]bool)
+	for _, lf := range licenses {
+		for _, id := range spdxIDs(lf.id) {
+			if id != "unknown" {
+				known[normalizeSPDX(id)] = true
+			}
+		}
+	}
+	if len(known) == 0 {
+		return false
+	}
+	for _, id := range header {
+		if known[normalizeSPDX(id)] {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeSPDX folds the "-only" and "-or-later" variants of GNU license
+// identifiers into the bare version, as LICENSE texts don't tell them apart
+func normalizeSPDX(id string) string {
+	id = strings.TrimSuffix(strings.TrimSuffix(id, "-only"), "-or-later")
+	return strings.TrimSuffix(id, "+")
+}
+
+// isLicensedSource reports whether path is code expected to carry a
+// license header when the project uses them
+func isLicensedSource(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return commentLanguages[ext] != nil && !notLicensed[ext]
+}
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const mitLicense = "MIT License\n\nCopyright (c) 2024 Acme Inc.\n\nPermission is hereby granted, free of charge, to any person obtaining a copy\n"
+
+func TestIdentifyLicense(t *testing.T) {
+	tests := map[string]string{
+		mitLicense: "MIT",
+		"                                 Apache License\n                           Version 2.0, January 2004\n": "Apache-2.0",
+		"GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n...GNU GENERAL PUBLIC LICENSE\n":             "LGPL-3.0",
+		"GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991\n":                                                      "GPL-2.0",
+		"SPDX-License-Identifier: MPL-2.0\n\nThis Source Code Form...":                                            "MPL-2.0",
+		"All rights reserved.\n": "unknown",
+	}
+	for text, want := range tests {
+		assert.Equal(t, want, identifyLicense(text), text)
+	}
+}
+
+func TestReadLicenseHeader(t *testing.T) {
+	file := format.FileInfo{Content: "// Copyright 2023 The Authors\n// SPDX-License-Identifier: Apache-2.0 OR MIT\n\npackage x\n"}
+	readLicenseHeader(&file)
+	assert.Equal(t, "Apache-2.0 OR MIT", file.License)
+	assert.Equal(t, "Copyright 2023 The Authors", file.Copyright)
+
+	file = format.FileInfo{Content: "/* SPDX-License-Identifier: GPL-2.0-only WITH Linux-syscall-note */\n"}
+	readLicenseHeader(&file)
+	assert.Equal(t, "GPL-2.0-only WITH Linux-syscall-note", file.License)
+	assert.Equal(t, []string{"GPL-2.0-only"}, spdxIDs(file.License))
+	assert.Equal(t, []string{"MIT", "Apache-2.0"}, spdxIDs("(MIT OR Apache-2.0)"))
+}
+
+func TestProcessDirectoryLicenseScan(t *testing.T) {
+	files := map[string]string{
+		"LICENSE":             mitLicense,
+		"main.go":             "// SPDX-License-Identifier: MIT\n\npackage main\n",
+		"util.go":             "package main\n",
+		"config.yml":          "key: value\n",
+		"vendor2/LICENSE":     "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n",
+		"vendor2/lib.go":      "// SPDX-License-Identifier: GPL-3.0-or-later\npackage lib\n",
+		"vendor2/wrong.go":    "// SPDX-License-Identifier: MIT\npackage lib\n",
+		"docs/guide/mixed.go": "// SPDX-License-Identifier: GPL-3.0-only\npackage guide\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:       tmpDir,
+		Extensions:    []string{".go", ".yml"},
+		Filter:        filter.New(filter.Options{UseDefaultRules: true}),
+		LicenseScan:   true,
+		StripComments: true,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	require.NotNil(t, result.ProjectOutput.Metadata)
+	info := result.ProjectOutput.Metadata.Licenses
+	require.NotNil(t, info)
+
+	assert.Equal(t, []format.LicenseSummary{
+		{ID: "GPL-3.0", Sources: []string{filepath.Join("vendor2", "LICENSE")}},
+		{ID: "GPL-3.0-only", Files: 1},
+		{ID: "GPL-3.0-or-later", Files: 1},
+		{ID: "MIT", Files: 2, Sources: []string{"LICENSE"}},
+	}, info.Licenses)
+	assert.Equal(t, []format.LicenseFlag{
+		{Path: filepath.Join("docs", "guide", "mixed.go"), Reason: "conflicting-header", Detail: "header says GPL-3.0-only; LICENSE declares MIT"},
+		{Path: "util.go", Reason: "missing-header", Detail: "no SPDX-License-Identifier header; LICENSE declares MIT"},
+		{Path: filepath.Join("vendor2", "wrong.go"), Reason: "conflicting-header", Detail: "header says MIT; " + filepath.Join("vendor2", "LICENSE") + " declares GPL-3.0"},
+	}, info.Flagged)
+	assert.Equal(t, []string{"Copyright (c) 2024 Acme Inc."}, info.Copyrights)
+
+	for _, file := range result.ProjectOutput.Files {
+		if file.Path == "main.go" {
+			assert.Equal(t, "MIT", file.License, "headers are read before comments are stripped")
+			assert.NotContains(t, file.Content, "SPDX")
+		}
+	}
+
+	config.LicenseScan = false
+	result, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+	if result.ProjectOutput.Metadata != nil {
+		assert.Nil(t, result.ProjectOutput.Metadata.Licenses)
+	}
+}
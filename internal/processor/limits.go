@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrTotalSizeExceeded is returned when the files an extraction would read
+// add up to more than Config.MaxTotalSize
+var ErrTotalSizeExceeded = errors.New("files exceed the total size limit")
+
+// largestShown is how many of the largest files a total size error names
+const largestShown = 5
+
+// maxFileSize returns the size above which files are skipped as too large:
+// config.MaxFileSize when it is set and lower than maxTextFileSize
+func maxFileSize(config Config) int64 {
+	if config.MaxFileSize > 0 && config.MaxFileSize < maxTextFileSize {
+		return config.MaxFileSize
+	}
+	return maxTextFileSize
+}
+
+// sizedFile is a file counted towards the total size limit
+type sizedFile struct {
+	path string
+	size int64
+}
+
+// checkTotalSize stats the walked paths the filters select and the file size
+// cap lets through, failing before any is read when together they are larger
+// than config.MaxTotalSize
+func checkTotalSize(paths []string, config Config) error {
+	if config.MaxTotalSize <= 0 {
+		return nil
+	}
+	var total int64
+	var files []sizedFile
+	for _, path := range paths {
+		rel, err := validateFilePath(path, config)
+		if err != nil || rel == "" {
+			continue
+		}
+		stat, err := statFile(config, path)
+		if err != nil || !stat.Mode().IsRegular() || stat.Size() > maxFileSize(config) {
+			continue
+		}
+		total += stat.Size()
+		files = append(files, sizedFile{path: rel, size: stat.Size()})
+	}
+	if total <= config.MaxTotalSize {
+		return nil
+	}
+	return totalSizeError(files, total, config.MaxTotalSize)
+}
+
+// totalSizeError describes the files that went over the total size limit,
+// naming the largest so they can be excluded
+func totalSizeError(files []sizedFile, total, limit int64) error {
+	sort.SliceStable(files, func(i, j int) bool { return files[i].size > files[j].size })
+	var largest []string
+	for _, file := range files[:min(len(files), largestShown)] {
+		largest = append(largest, fmt.Sprintf("%s (%s)", file.path, formatSize(file.size)))
+	}
+	return fmt.Errorf("%w: %d files total %s, over the %s limit; largest: %s",
+		ErrTotalSizeExceeded, len(files), formatSize(total), formatSize(limit), strings.Join(largest, ", "))
+}
@@ -0,0 +1,84 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sizedProject creates a project with a small source file and two large
+// text dumps, and returns its root
+func sizedProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "data"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "data", "dump.txt"), []byte(strings.Repeat("a\n", 3000)), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "data", "small.txt"), []byte(strings.Repeat("b\n", 1000)), 0644))
+	return root
+}
+
+func TestMaxFileSize(t *testing.T) {
+	assert.Equal(t, int64(maxTextFileSize), maxFileSize(Config{}))
+	assert.Equal(t, int64(1024), maxFileSize(Config{MaxFileSize: 1024}))
+	assert.Equal(t, int64(maxTextFileSize), maxFileSize(Config{MaxFileSize: 2 * maxTextFileSize}), "the cap can't go above the binary threshold")
+}
+
+func TestProcessDirectoryMaxFileSize(t *testing.T) {
+	root := sizedProject(t)
+
+	config := Config{DirPath: root, Filter: filter.New(filter.Options{}), MaxFileSize: 4000, SkippedFileStubs: true}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	var files []string
+	for _, file := range result.ProjectOutput.Files {
+		files = append(files, filepath.ToSlash(file.Path))
+	}
+	assert.ElementsMatch(t, []string{"main.go", "data/small.txt"}, files)
+	require.Len(t, result.ProjectOutput.SkippedFiles, 1)
+	assert.Equal(t, "data/dump.txt", filepath.ToSlash(result.ProjectOutput.SkippedFiles[0].Path))
+	assert.Equal(t, SkipReasonTooLarge, result.ProjectOutput.SkippedFiles[0].Reason)
+
+	decision := ExplainFile(config, "data/dump.txt")
+	assert.True(t, decision.Excluded)
+	assert.Equal(t, SkipReasonTooLarge, decision.Rule)
+}
+
+func TestProcessDirectoryMaxTotalSize(t *testing.T) {
+	root := sizedProject(t)
+
+	config := Config{DirPath: root, Filter: filter.New(filter.Options{}), MaxTotalSize: 5000}
+	_, err := ProcessDirectory(config, false)
+	require.ErrorIs(t, err, ErrTotalSizeExceeded)
+	assert.Contains(t, err.Error(), "3 files total 7.8 KB, over the 4.9 KB limit")
+	assert.Contains(t, err.Error(), "largest: data/dump.txt (5.9 KB), data/small.txt (2.0 KB)")
+
+	// Excluding the dump brings the rest under the limit
+	config.Filter = filter.New(filter.Options{Excludes: []string{"data/dump.txt"}})
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.Len(t, result.ProjectOutput.Files, 2)
+
+	// Files the size cap skips don't count towards the total
+	config = Config{DirPath: root, Filter: filter.New(filter.Options{}), MaxTotalSize: 5000, MaxFileSize: 4000}
+	_, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+}
+
+func TestStreamFilesMaxTotalSize(t *testing.T) {
+	root := sizedProject(t)
+
+	config := Config{DirPath: root, Filter: filter.New(filter.Options{}), MaxTotalSize: 5000}
+	err := StreamFiles(config, func(format.FileInfo) error { return nil })
+	require.ErrorIs(t, err, ErrTotalSizeExceeded)
+
+	config.MaxTotalSize = 10000
+	require.NoError(t, StreamFiles(config, func(format.FileInfo) error { return nil }))
+}
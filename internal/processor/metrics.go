@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"go/ast"
+	"go/parser"
+	gotoken "go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// Branch keywords counted toward the estimated complexity of languages
+// other than Go, after comments are stripped
+var (
+	braceBranches  = regexp.MustCompile(`\b(?:if|for|foreach|while|case|catch)\b|&&|\|\|`)
+	pythonBranches = regexp.MustCompile(`\b(?:if|elif|for|while|except|case|and|or)\b`)
+)
+
+// typeDefinition matches the definition line of a type rather than a
+// function, whose braces findSymbols also reports
+var typeDefinition = regexp.MustCompile(`\b(?:class|interface|struct|enum|trait|impl|object|record|type|namespace)\s+\w`)
+
+// goFuncLine matches the declaration of a Go function or method
+var goFuncLine = regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?(\w+)`)
+
+// function is one function of a file and its cyclomatic complexity
+type function struct {
+	name       string
+	complexity int
+}
+
+// computeMetrics measures the source files among files, most complex first.
+// Files in languages findSymbols doesn't know are left out.
+func computeMetrics(files []format.FileInfo) *format.MetricsInfo {
+	info := &format.MetricsInfo{Files: []format.FileMetrics{}}
+	for _, file := range files {
+		functions, complexity, ok := measureFile(file.Path, file.Content)
+		if !ok {
+			continue
+		}
+		metrics := format.FileMetrics{
+			Path:       file.Path,
+			Lines:      strings.Count(file.Content, "\n") + 1,
+			Functions:  len(functions),
+			Complexity: complexity,
+		}
+		for _, fn := range functions {
+			if fn.complexity > metrics.MaxComplexity {
+				metrics.MaxComplexity, metrics.MaxFunction = fn.complexity, fn.name
+			}
+		}
+		info.Functions += metrics.Functions
+		info.Files = append(info.Files, metrics)
+	}
+	sort.SliceStable(info.Files, func(i, j int) bool {
+		a, b := info.Files[i], info.Files[j]
+		if a.Complexity != b.Complexity {
+			return a.Complexity > b.Complexity
+		}
+		return a.Lines > b.Lines
+	})
+	return info
+}
+
+// measureFile returns the functions of a source file and its complexity.
+// Go files that parse are measured exactly; other files, and Go cut short by
+// truncation, are estimated from branch keywords. It reports false for
+// languages it doesn't know.
+func measureFile(path, content string) ([]function, int, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".go" {
+		if functions, ok := goComplexity(path, content); ok {
+			total := 0
+			for _, fn := range functions {
+				total += fn.complexity
+			}
+			return functions, total, true
+		}
+	}
+
+	var branches *regexp.Regexp
+	switch {
+	case ext == ".py" || ext == ".pyi":
+		branches = pythonBranches
+	case ext == ".go" || braceExtensions[ext]:
+		branches = braceBranches
+	default:
+		return nil, 0, false
+	}
+
+	code := stripComments(path, content)
+	lines := strings.Split(code, "\n")
+	var functions []function
+	if ext == ".go" {
+		// Go that doesn't parse has no symbols; each func line counts as a
+		// function of unknown complexity
+		for _, line := range lines {
+			if m := goFuncLine.FindStringSubmatch(line); m != nil {
+				functions = append(functions, function{name: m[1], complexity: 1})
+			}
+		}
+	}
+	for _, sym := range findSymbols(path, code) {
+		if isTypeSymbol(lines, sym) {
+			continue
+		}
+		body := strings.Join(lines[sym.start:min(sym.end+1, len(lines))], "\n")
+		functions = append(functions, function{name: sym.name, complexity: 1 + len(branches.FindAllStringIndex(body, -1))})
+	}
+	return functions, len(functions) + len(branches.FindAllStringIndex(code, -1)), true
+}
+
+// isTypeSymbol reports whether sym is a class, struct or similar type
+// definition, judged from the first of its lines that names it
+func isTypeSymbol(lines []string, sym symbol) bool {
+	for n := sym.start; n <= sym.end && n < len(lines); n++ {
+		if strings.Contains(lines[n], sym.name) {
+			return typeDefinition.MatchString(lines[n])
+		}
+	}
+	return false
+}
+
+// goComplexity returns the cyclomatic complexity of each function and method
+// of a Go file: one, plus one for each if, for, range, non-default case, and
+// && or ||. Function literals count toward the function holding them.
+func goComplexity(path, content string) ([]function, bool) {
+	fset := gotoken.NewFileSet()
+	parsed, err := parser.ParseFile(fset, path, content, 0)
+	if err != nil {
+		return nil, false
+	}
+	var functions []function
+	for _, decl := range parsed.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		name := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) > 0 {
+			name = receiverName(fn.Recv.List[0].Type) + "." + name
+		}
+		complexity := 1
+		ast.Inspect(fn, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+				complexity++
+			case *ast.CaseClause:
+				if node.List != nil {
+					complexity++
+				}
+			case *ast.CommClause:
+				if node.Comm != nil {
+					complexity++
+				}
+			case *ast.BinaryExpr:
+				if node.Op == gotoken.LAND || node.Op == gotoken.LOR {
+					complexity++
+				}
+			}
+			return true
+		})
+		functions = append(functions, function{name: name, complexity: complexity})
+	}
+	return functions, true
+}
+
+// receiverName returns the type name of a method receiver, without pointer
+// or type parameters
+func receiverName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(expr.X)
+	case *ast.IndexExpr:
+		return receiverName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverName(expr.X)
+	case *ast.Ident:
+		return expr.Name
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	goSource := `package main
+
+type server struct{}
+
+func (s *server) handle(a, b bool) {
+	if a && b {
+		return
+	}
+	for i := range 3 {
+		switch i {
+		case 1:
+		case 2:
+		default:
+		}
+	}
+}
+
+func main() {}
+`
+	jsSource := `// if this were counted it would be wrong
+class Cart {
+}
+
+function total(items) {
+  let sum = 0;
+  for (const item of items) {
+    if (item.price > 0 || item.free) {
+      sum += item.price;
+    }
+  }
+  return sum;
+}
+`
+	metrics := computeMetrics([]format.FileInfo{
+		{Path: "main.go", Content: goSource},
+		{Path: "cart.js", Content: jsSource},
+		{Path: "README.md", Content: "# Readme\n"},
+	})
+	assert.Equal(t, &format.MetricsInfo{
+		Functions: 3,
+		Files: []format.FileMetrics{
+			// 1 + if + && + range + two cases, and main
+			{Path: "main.go", Lines: 19, Functions: 2, Complexity: 7, MaxComplexity: 6, MaxFunction: "server.handle"},
+			// 1 + for + if + ||
+			{Path: "cart.js", Lines: 14, Functions: 1, Complexity: 4, MaxComplexity: 4, MaxFunction: "total"},
+		},
+	}, metrics)
+}
+
+func TestComputeMetricsUnparsableGo(t *testing.T) {
+	metrics := computeMetrics([]format.FileInfo{
+		{Path: "cut.go", Content: "package main\n\nfunc run() {\n\tif ok {\n"},
+	})
+	require.Len(t, metrics.Files, 1)
+	assert.Equal(t, format.FileMetrics{Path: "cut.go", Lines: 5, Functions: 1, Complexity: 2, MaxComplexity: 1, MaxFunction: "run"}, metrics.Files[0])
+}
+
+func TestProcessDirectoryMetrics(t *testing.T) {
+	files := map[string]string{
+		"main.go":  "package main\n\nfunc main() {\n\tif true {\n\t}\n}\n",
+		"notes.md": "# Notes\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath: tmpDir,
+		Filter:  filter.New(filter.Options{UseDefaultRules: true}),
+		Metrics: true,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	require.NotNil(t, result.ProjectOutput.Metrics)
+	assert.Equal(t, []format.FileMetrics{
+		{Path: "main.go", Lines: 7, Functions: 1, Complexity: 2, MaxComplexity: 2, MaxFunction: "main"},
+	}, result.ProjectOutput.Metrics.Files)
+
+	config.Metrics = false
+	result, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.Nil(t, result.ProjectOutput.Metrics)
+}
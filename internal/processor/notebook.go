@@ -0,0 +1,154 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// notebook is the part of a Jupyter notebook (nbformat 4) that flattening reads
+type notebook struct {
+	Cells    []notebookCell `json:"cells"`
+	Metadata struct {
+		Kernelspec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+type notebookCell struct {
+	CellType string           `json:"cell_type"`
+	Source   notebookText     `json:"source"`
+	Outputs  []notebookOutput `json:"outputs"`
+}
+
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	Ename      string                  `json:"ename"`
+	Evalue     string                  `json:"evalue"`
+}
+
+// notebookText is multiline notebook text, stored as a string or a list of lines
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil // Binary payloads such as JSON widget state aren't text
+	}
+	*t = notebookText(s)
+	return nil
+}
+
+// isNotebook reports whether relPath names a Jupyter notebook
+func isNotebook(relPath string) bool {
+	return strings.EqualFold(filepath.Ext(relPath), ".ipynb")
+}
+
+// flattenNotebook turns a notebook's JSON into its cells in the percent
+// format of jupytext: code as is and markdown as comments, each after a
+// "# %%" marker. Outputs are dropped, or kept as comments when keepOutputs
+// is set, with images and other binary data left out. Content that isn't
+// an nbformat 4 notebook is returned unchanged.
+func flattenNotebook(content string, keepOutputs bool) string {
+	var nb notebook
+	if err := json.Unmarshal([]byte(content), &nb); err != nil || nb.Cells == nil {
+		return content
+	}
+	language := nb.Metadata.Kernelspec.Language
+	if language == "" {
+		language = nb.Metadata.LanguageInfo.Name
+	}
+	comment := notebookComment(language)
+
+	var b strings.Builder
+	for i, cell := range nb.Cells {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		source := strings.TrimRight(string(cell.Source), "\n")
+		switch cell.CellType {
+		case "code":
+			b.WriteString(comment + " %%\n")
+			if source != "" {
+				b.WriteString(source + "\n")
+			}
+			if keepOutputs {
+				writeNotebookOutputs(&b, cell.Outputs, comment)
+			}
+		default: // markdown and raw
+			b.WriteString(fmt.Sprintf("%s %%%% [%s]\n", comment, cell.CellType))
+			if source != "" {
+				b.WriteString(commentLines(source, comment))
+			}
+		}
+	}
+	return b.String()
+}
+
+// writeNotebookOutputs writes the text of a code cell's outputs as comments
+func writeNotebookOutputs(b *strings.Builder, outputs []notebookOutput, comment string) {
+	var text []string
+	for _, output := range outputs {
+		switch output.OutputType {
+		case "stream":
+			text = append(text, strings.TrimRight(string(output.Text), "\n"))
+		case "error":
+			text = append(text, output.Ename+": "+output.Evalue)
+		default: // execute_result and display_data
+			if plain, ok := output.Data["text/plain"]; ok {
+				text = append(text, strings.TrimRight(string(plain), "\n"))
+				continue
+			}
+			var kinds []string
+			for kind := range output.Data {
+				kinds = append(kinds, kind)
+			}
+			if len(kinds) > 0 {
+				sort.Strings(kinds)
+				text = append(text, fmt.Sprintf("[%s output omitted]", kinds[0]))
+			}
+		}
+	}
+	joined := strings.TrimRight(strings.Join(text, "\n"), "\n")
+	if joined == "" {
+		return
+	}
+	b.WriteString(comment + " %% [output]\n")
+	b.WriteString(commentLines(joined, comment))
+}
+
+// commentLines prefixes each line of text with comment
+func commentLines(text, comment string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			b.WriteString(comment + "\n")
+		} else {
+			b.WriteString(comment + " " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// notebookComment is the line comment of a kernel language, "#" for Python,
+// R, Julia and shells, and for languages it doesn't know
+func notebookComment(language string) string {
+	switch strings.ToLower(language) {
+	case "javascript", "typescript", "java", "scala", "kotlin", "c", "c++", "c#", "csharp", "go", "rust", "swift":
+		return "//"
+	}
+	return "#"
+}
@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testNotebook = `{
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Analysis\n", "\n", "Load data."]},
+  {"cell_type": "code", "execution_count": 1, "metadata": {}, "source": ["import pandas as pd\n", "print('hello')"],
+   "outputs": [
+    {"output_type": "stream", "name": "stdout", "text": ["hello\n"]},
+    {"output_type": "display_data", "data": {"image/png": "iVBORw0KGgo="}},
+    {"output_type": "error", "ename": "ValueError", "evalue": "bad", "traceback": ["\u001b[0;31m..."]}
+   ]},
+  {"cell_type": "code", "metadata": {}, "source": "df.head()", "outputs": [
+    {"output_type": "execute_result", "data": {"text/plain": ["   a\n", "0  1"], "text/html": ["<table>"]}}
+   ]}
+ ],
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}},
+ "nbformat": 4, "nbformat_minor": 5
+}`
+
+func TestFlattenNotebook(t *testing.T) {
+	assert.Equal(t, "# %% [markdown]\n# # Analysis\n#\n# Load data.\n"+
+		"\n# %%\nimport pandas as pd\nprint('hello')\n"+
+		"\n# %%\ndf.head()\n", flattenNotebook(testNotebook, false))
+
+	assert.Equal(t, "# %% [markdown]\n# # Analysis\n#\n# Load data.\n"+
+		"\n# %%\nimport pandas as pd\nprint('hello')\n# %% [output]\n# hello\n# [image/png output omitted]\n# ValueError: bad\n"+
+		"\n# %%\ndf.head()\n# %% [output]\n#    a\n# 0  1\n", flattenNotebook(testNotebook, true))
+
+	scala := `{"cells": [{"cell_type": "markdown", "source": "Notes"}], "metadata": {"language_info": {"name": "scala"}}}`
+	assert.Equal(t, "// %% [markdown]\n// Notes\n", flattenNotebook(scala, false))
+
+	for _, content := range []string{"not json", `{"worksheets": []}`} {
+		assert.Equal(t, content, flattenNotebook(content, false))
+	}
+}
+
+func TestProcessDirectoryFlattensNotebooks(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"analysis.ipynb": testNotebook,
+	})
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath: tmpDir,
+		Filter:  filter.New(filter.Options{Includes: []string{".ipynb"}}),
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	require.Len(t, result.ProjectOutput.Files, 1)
+	file := result.ProjectOutput.Files[0]
+	assert.Equal(t, flattenNotebook(testNotebook, false), file.Content)
+	assert.NotContains(t, file.Content, "iVBORw0KGgo")
+}
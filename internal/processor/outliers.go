@@ -0,0 +1,56 @@
+package processor
+
+import (
+	"sort"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+)
+
+// sizePercentile returns the p-th percentile (0-100) of sizes using linear
+// interpolation between the closest ranks. sizes must be sorted ascending.
+func sizePercentile(sizes []int, p float64) float64 {
+	if len(sizes) == 0 {
+		return 0
+	}
+	pos := p / 100 * float64(len(sizes)-1)
+	lower := int(pos)
+	if lower >= len(sizes)-1 {
+		return float64(sizes[len(sizes)-1])
+	}
+	frac := pos - float64(lower)
+	return float64(sizes[lower]) + frac*float64(sizes[lower+1]-sizes[lower])
+}
+
+// dropSizeOutliers removes files whose content size is above the given
+// percentile of the candidate set, so a handful of huge files can't dominate
+// the token count. A percentile outside (0, 100) disables the check.
+func dropSizeOutliers(files []format.FileInfo, percentile float64) ([]format.FileInfo, []ExcludedFileInfo) {
+	if percentile <= 0 || percentile >= 100 || len(files) < 2 {
+		return files, nil
+	}
+
+	sizes := make([]int, len(files))
+	for i, file := range files {
+		sizes[i] = len(file.Content)
+	}
+	sort.Ints(sizes)
+	threshold := sizePercentile(sizes, percentile)
+	log.Debug("Size outlier threshold (p%g): %.0f bytes", percentile, threshold)
+
+	var kept []format.FileInfo
+	var dropped []ExcludedFileInfo
+	for _, file := range files {
+		if float64(len(file.Content)) > threshold {
+			dropped = append(dropped, ExcludedFileInfo{
+				Path:   file.Path,
+				Tokens: file.Tokens,
+				Reason: ExcludeReasonSizeOutlier,
+			})
+			log.Debug("Excluding (size outlier): %s (%d bytes)", file.Path, len(file.Content))
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return kept, dropped
+}
@@ -0,0 +1,47 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDropSizeOutliers(t *testing.T) {
+	var files []format.FileInfo
+	for i := 0; i < 10; i++ {
+		files = append(files, format.FileInfo{
+			Path:    string(rune('a'+i)) + ".go",
+			Content: strings.Repeat("x", 100+i*10),
+		})
+	}
+	files = append(files, format.FileInfo{Path: "huge.go", Content: strings.Repeat("x", 50000), Tokens: 12000})
+
+	kept, dropped := dropSizeOutliers(files, 95)
+
+	assert.Len(t, kept, 10)
+	assert.Equal(t, []ExcludedFileInfo{{Path: "huge.go", Tokens: 12000, Reason: ExcludeReasonSizeOutlier}}, dropped)
+}
+
+func TestDropSizeOutliersDisabled(t *testing.T) {
+	files := []format.FileInfo{
+		{Path: "small.go", Content: "x"},
+		{Path: "huge.go", Content: strings.Repeat("x", 50000)},
+	}
+
+	for _, p := range []float64{0, -5, 100, 150} {
+		kept, dropped := dropSizeOutliers(files, p)
+		assert.Len(t, kept, 2, "percentile %g should disable dropping", p)
+		assert.Empty(t, dropped)
+	}
+}
+
+func TestSizePercentile(t *testing.T) {
+	sizes := []int{10, 20, 30, 40, 50}
+
+	assert.Equal(t, 10.0, sizePercentile(sizes, 0))
+	assert.Equal(t, 30.0, sizePercentile(sizes, 50))
+	assert.Equal(t, 48.0, sizePercentile(sizes, 95))
+	assert.Equal(t, 50.0, sizePercentile(sizes, 100))
+}
@@ -0,0 +1,209 @@
+package processor
+
+import (
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/info"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// RenderFunc renders project output in the caller's target format
+type RenderFunc func(output *format.ProjectOutput) (string, error)
+
+// EnforceOutputCap renders output and, while the rendered token count is above
+// maxTokens, drops the lowest-priority files and renders again. The cap is
+// checked against the final text produced by render, so it also holds for
+// formatters the processor's own budget doesn't know about. File priority uses
+// the relevance keywords and core directories from config.
+//
+// The output is updated in place (files, directory tree, stats, budget). The
+// returned string is the final rendering and the int its token count. If the
+// cap cannot be met even with every file dropped, the output is left with no
+// files and the caller decides how to report it.
+func EnforceOutputCap(output *format.ProjectOutput, maxTokens int, config Config, render RenderFunc) (string, int, []ExcludedFileInfo, error) {
+	return trimToRenderedBudget(output, maxTokens, config, render, ExcludeReasonOutputCap)
+}
+
+// trimToRenderedBudget implements EnforceOutputCap, recording dropped files
+// with the given exclusion reason
+func trimToRenderedBudget(output *format.ProjectOutput, maxTokens int, config Config, render RenderFunc, reason string) (string, int, []ExcludedFileInfo, error) {
+	tokenCounter, err := token.NewTokenCounterFor(config.Tokenizer)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	rendered, err := render(output)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	renderedTokens := tokenCounter.EstimateTokens(rendered)
+	if maxTokens <= 0 || renderedTokens <= maxTokens {
+		return rendered, renderedTokens, nil, nil
+	}
+
+	log.Debug("=== Trimming Rendered Output (%s) ===", reason)
+	log.Debug("Rendered output: %d tokens (cap: %d)", renderedTokens, maxTokens)
+
+	// Lowest-priority files end up at the tail of the ordered list
+	scorer, scoring, err := relevanceScorer(config, output.Files)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	kept := prioritizeFiles(output.Files, scorer, detectEntryPoints(output.Files, config.Filter), config.CoreDirs)
+	if scoring {
+		kept, _ = followImports(kept, scorer, config.FollowImports, config)
+	}
+
+	var dropped []ExcludedFileInfo
+	for renderedTokens > maxTokens && len(kept) > 0 {
+		// Drop enough files from the tail to cover the overage in one pass,
+		// then re-render to measure the real effect of framing and metadata.
+		// Shared sections can make the overage more than the files' own
+		// tokens, so the last file waits for a re-render to show it must go.
+		overage := renderedTokens - maxTokens
+		freed := 0
+		for len(kept) > 0 && (freed == 0 || freed < overage && len(kept) > 1) {
+			file := kept[len(kept)-1]
+			kept = kept[:len(kept)-1]
+
+			fileTokens := file.Tokens
+			if fileTokens == 0 {
+				fileTokens = tokenCounter.EstimateTokens(file.Content)
+			}
+			freed += fileTokens
+			dropped = append(dropped, ExcludedFileInfo{
+				Path:   file.Path,
+				Tokens: fileTokens,
+				Reason: reason,
+			})
+			log.Debug("Dropping: %s (%d tokens, overage: %d)", file.Path, fileTokens, overage)
+		}
+
+		applyFileSelection(output, kept)
+		markSelectionExcluded(output.Selection, dropped)
+
+		rendered, err = render(output)
+		if err != nil {
+			return "", 0, nil, err
+		}
+		renderedTokens = tokenCounter.EstimateTokens(rendered)
+		log.Debug("Re-rendered output: %d tokens with %d files", renderedTokens, len(output.Files))
+	}
+
+	return rendered, renderedTokens, dropped, nil
+}
+
+// applyFileSelection restricts output to the kept files, preserving the
+// original file order, and refreshes the derived tree, stats and budget sections
+func applyFileSelection(output *format.ProjectOutput, kept []format.FileInfo) {
+	keep := make(map[string]bool, len(kept))
+	for _, file := range kept {
+		keep[file.Path] = true
+	}
+
+	var files []format.FileInfo
+	estimated := 0
+	for _, file := range output.Files {
+		if keep[file.Path] {
+			files = append(files, file)
+			estimated += file.Tokens
+		}
+	}
+	output.Files = files
+
+	if output.DirectoryTree != nil {
+		// Skipped-file stubs stay in the tree alongside the kept files
+		inTree := make(map[string]bool, len(keep)+len(output.SkippedFiles))
+		for path := range keep {
+			inTree[path] = true
+		}
+		for _, stub := range output.SkippedFiles {
+			inTree[stub.Path] = true
+		}
+		for _, file := range files {
+			if file.External {
+				inTree[filepath.Join(ExternalDir, file.Path)] = true // As filterDirectoryTree joins it
+			}
+		}
+		output.DirectoryTree = filterDirectoryTree(output.DirectoryTree, inTree, "")
+	}
+	output.FileStats = calculateFileStats(files)
+	output.Packages = filterPackages(output.Packages, keep)
+	if output.Workspace != nil {
+		countWorkspaceFiles(output.Workspace, files)
+	}
+	if output.Metadata != nil {
+		output.Metadata.Languages = languageBreakdown(files)
+	}
+	if output.Integrity != nil {
+		output.Integrity = format.Integrity(files)
+	}
+	if output.Dependencies != nil && output.Dependencies.Imports != nil {
+		output.Dependencies = buildDependencyInfo(files)
+	}
+	if output.Redactions != nil {
+		output.Redactions = buildRedactionInfo(files)
+	}
+	if output.Budget != nil {
+		output.Budget.EstimatedTokens = estimated
+		output.Budget.FileTruncations = countTruncations(files)
+	}
+}
+
+// selectWithinRenderedBudget picks files, in priority order, whose rendered
+// size fits within config.MaxTokens. Each file's cost is the number of tokens
+// it adds to the rendering in the target format, so headers, fences and other
+// per-file framing count against the budget along with the content. The
+// project overhead (tree, git, metadata) is measured the same way.
+//
+// Files are costed one at a time, so sections the selected files share (the
+// directory tree, dependency graph, manifest alignment) are not counted and
+// the selection can still render over the budget. It is an estimate:
+// ProcessDirectory guarantees the budget by rendering the selection and
+// trimming it with trimToRenderedBudget.
+func selectWithinRenderedBudget(files []format.FileInfo, projectInfo *info.ProjectInfo, config Config, tokenCounter *token.TokenCounter) ([]format.FileInfo, []ExcludedFileInfo) {
+	renderedTokens := func(output *format.ProjectOutput) int {
+		rendered, err := config.Render(output)
+		if err != nil {
+			return 0
+		}
+		return tokenCounter.EstimateTokens(rendered)
+	}
+
+	overheadOutput := &format.ProjectOutput{}
+	populateProjectInfo(overheadOutput, projectInfo)
+	overheadTokens := renderedTokens(overheadOutput)
+	emptyTokens := renderedTokens(&format.ProjectOutput{})
+
+	availableTokens := config.MaxTokens - overheadTokens
+	log.Debug("Token budget: %d (rendered overhead: %d, available for files: %d)", config.MaxTokens, overheadTokens, availableTokens)
+
+	costs := make([]int, len(files))
+	for i, file := range files {
+		costs[i] = max(renderedTokens(&format.ProjectOutput{Files: []format.FileInfo{file}})-emptyTokens, file.Tokens)
+	}
+
+	var selected []format.FileInfo
+	var excluded []ExcludedFileInfo
+	cumulativeTokens := 0
+	for i, included := range selectWithinBudget(files, costs, availableTokens, config.BudgetWeights) {
+		file, fileTokens := files[i], costs[i]
+		if included {
+			selected = append(selected, file)
+			cumulativeTokens += fileTokens
+			log.Debug("Including: %s (%d rendered tokens, cumulative: %d)", file.Path, fileTokens, cumulativeTokens)
+		} else {
+			excluded = append(excluded, ExcludedFileInfo{
+				Path:   file.Path,
+				Tokens: file.Tokens,
+				Reason: ExcludeReasonTokenBudget,
+			})
+			log.Debug("Excluding: %s (%d rendered tokens would exceed budget)", file.Path, fileTokens)
+		}
+	}
+
+	log.Debug("Included %d files, excluded %d files due to token budget", len(selected), len(excluded))
+	return selected, excluded
+}
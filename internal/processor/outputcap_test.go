@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crossRefRender renders each file, plus a section shared by the files that
+// only appears once there are two, like a dependency graph
+func crossRefRender(output *format.ProjectOutput) (string, error) {
+	var b strings.Builder
+	for _, file := range output.Files {
+		fmt.Fprintf(&b, "## %s\n%s\n", file.Path, file.Content)
+	}
+	if len(output.Files) > 1 {
+		b.WriteString("## cross-references\n")
+		for _, file := range output.Files {
+			fmt.Fprintf(&b, "%s %s\n", file.Path, strings.Repeat("refers to ", 20))
+		}
+	}
+	return b.String(), nil
+}
+
+func TestRenderedBudgetCountsSharedSections(t *testing.T) {
+	files := map[string]string{
+		"a.go": "package a\n\nfunc A() int { return 1 }\n",
+		"b.go": "package b\n\nfunc B() int { return 2 }\n",
+		"c.go": "package c\n\nfunc C() int { return 3 }\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	counter := token.NewTokenCounter()
+	perFile := 0
+	for path, content := range files {
+		rendered, _ := crossRefRender(&format.ProjectOutput{Files: []format.FileInfo{{Path: path, Content: content}}})
+		perFile += counter.EstimateTokens(rendered)
+	}
+	all, _ := crossRefRender(&format.ProjectOutput{Files: []format.FileInfo{
+		{Path: "a.go", Content: files["a.go"]}, {Path: "b.go", Content: files["b.go"]}, {Path: "c.go", Content: files["c.go"]},
+	}})
+	// The files' costs add up to under the budget, so the selection keeps all three
+	budget := perFile + 5
+	require.Greater(t, counter.EstimateTokens(all), budget, "the shared section must push the full rendering over the budget")
+
+	config := Config{
+		DirPath:    tmpDir,
+		Extensions: []string{".go"},
+		Filter:     filter.New(filter.Options{UseDefaultRules: true}),
+		MaxTokens:  budget,
+		Render:     crossRefRender,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	rendered, err := crossRefRender(result.ProjectOutput)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, counter.EstimateTokens(rendered), budget, "the trim pass holds the budget the selection can't")
+	assert.Len(t, result.ProjectOutput.Files, 1, "one file fits once the shared section is gone")
+	assert.Equal(t, len(files)-len(result.ProjectOutput.Files), result.ExcludedFiles)
+	for _, excluded := range result.ExcludedFileList {
+		assert.Equal(t, ExcludeReasonTokenBudget, excluded.Reason)
+	}
+}
@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/1broseidon/promptext/internal/codeowners"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+)
+
+// loadCodeowners reads the first CODEOWNERS file of codeowners.Paths found
+// in config.DirPath, returning its path, or nil when there is none
+func loadCodeowners(config Config) (*codeowners.File, string) {
+	for _, name := range codeowners.Paths {
+		data, err := readFile(config, filepath.Join(config.DirPath, filepath.FromSlash(name)))
+		if err != nil {
+			continue
+		}
+		log.Debug("Reading owners from %s", name)
+		return codeowners.Parse(string(data)), name
+	}
+	log.Debug("No CODEOWNERS file found")
+	return nil, ""
+}
+
+// attachOwners sets the owners of each file from owners. Files from outside
+// the project have none.
+func attachOwners(files []format.FileInfo, owners *codeowners.File) {
+	for i := range files {
+		if !files[i].External {
+			files[i].Owners = owners.Owners(filepath.ToSlash(files[i].Path))
+		}
+	}
+}
+
+// ownerRollup counts the files and tokens of each owner, most files first.
+// A file with several owners counts toward each of them.
+func ownerRollup(files []format.FileInfo, source string) *format.OwnerInfo {
+	rollup := &format.OwnerInfo{Source: source}
+	index := make(map[string]int)
+	for _, file := range files {
+		if len(file.Owners) == 0 {
+			rollup.Unowned++
+			continue
+		}
+		for _, owner := range file.Owners {
+			n, ok := index[owner]
+			if !ok {
+				n = len(rollup.Owners)
+				index[owner] = n
+				rollup.Owners = append(rollup.Owners, format.OwnerSummary{Owner: owner})
+			}
+			rollup.Owners[n].Files++
+			rollup.Owners[n].Tokens += file.Tokens
+		}
+	}
+	sort.SliceStable(rollup.Owners, func(i, j int) bool {
+		if rollup.Owners[i].Files != rollup.Owners[j].Files {
+			return rollup.Owners[i].Files > rollup.Owners[j].Files
+		}
+		return rollup.Owners[i].Owner < rollup.Owners[j].Owner
+	})
+	return rollup
+}
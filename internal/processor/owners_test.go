@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnerRollup(t *testing.T) {
+	rollup := ownerRollup([]format.FileInfo{
+		{Path: "a.go", Tokens: 10, Owners: []string{"@core"}},
+		{Path: "b.go", Tokens: 5, Owners: []string{"@web", "@core"}},
+		{Path: "c.go", Tokens: 3, Owners: []string{"@web"}},
+		{Path: "d.go", Tokens: 1},
+	}, "CODEOWNERS")
+	assert.Equal(t, &format.OwnerInfo{
+		Source: "CODEOWNERS",
+		Owners: []format.OwnerSummary{
+			{Owner: "@core", Files: 2, Tokens: 15},
+			{Owner: "@web", Files: 2, Tokens: 8},
+		},
+		Unowned: 1,
+	}, rollup)
+}
+
+func TestProcessDirectoryOwners(t *testing.T) {
+	files := map[string]string{
+		".github/CODEOWNERS": "* @org/core\n/billing/ @org/billing\n/docs/\n",
+		"main.go":            "package main\n",
+		"billing/invoice.go": "package billing\n",
+		"docs/guide.md":      "# Guide\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:    tmpDir,
+		Extensions: []string{".go", ".md"},
+		Filter:     filter.New(filter.Options{UseDefaultRules: true}),
+		Owners:     true,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	owners := make(map[string][]string)
+	for _, file := range result.ProjectOutput.Files {
+		owners[file.Path] = file.Owners
+	}
+	assert.Equal(t, map[string][]string{
+		"main.go":                              {"@org/core"},
+		filepath.Join("billing", "invoice.go"): {"@org/billing"},
+		filepath.Join("docs", "guide.md"):      nil,
+	}, owners)
+
+	require.NotNil(t, result.ProjectOutput.Metadata)
+	rollup := result.ProjectOutput.Metadata.Owners
+	require.NotNil(t, rollup)
+	assert.Equal(t, ".github/CODEOWNERS", rollup.Source)
+	assert.Equal(t, 1, rollup.Unowned)
+	assert.Len(t, rollup.Owners, 2)
+
+	// Off by default, and nothing is attached without a CODEOWNERS file
+	config.Owners = false
+	result, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+	for _, file := range result.ProjectOutput.Files {
+		assert.Nil(t, file.Owners)
+	}
+	if result.ProjectOutput.Metadata != nil {
+		assert.Nil(t, result.ProjectOutput.Metadata.Owners)
+	}
+
+	require.NoError(t, os.Remove(filepath.Join(tmpDir, ".github", "CODEOWNERS")))
+	config.Owners = true
+	result, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+	if result.ProjectOutput.Metadata != nil {
+		assert.Nil(t, result.ProjectOutput.Metadata.Owners)
+	}
+}
@@ -0,0 +1,224 @@
+package processor
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// jsExtensions are the file extensions treated as JavaScript/TypeScript modules
+var jsExtensions = map[string]bool{
+	".js":  true,
+	".jsx": true,
+	".ts":  true,
+	".tsx": true,
+	".mjs": true,
+	".cjs": true,
+}
+
+// jsImportPatterns match module specifiers in ES imports/exports and require calls
+var jsImportPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^\s*(?:import|export)\s[^'"]*?\sfrom\s*['"]([^'"]+)['"]`),
+	regexp.MustCompile(`(?m)^\s*import\s*['"]([^'"]+)['"]`),
+	regexp.MustCompile(`\b(?:require|import)\s*\(\s*['"]([^'"]+)['"]\s*\)`),
+}
+
+// packageBuilder accumulates one package while files are scanned
+type packageBuilder struct {
+	info    format.PackageInfo
+	imports map[string]bool
+}
+
+// buildPackages groups Go and JS/TS files into packages (one per directory and
+// language) and records which other packages in the project each one imports.
+// Only imports that resolve to packages within files are reported, so the
+// result is an architecture map of the included code rather than a dependency list.
+func buildPackages(files []format.FileInfo, config Config) []format.PackageInfo {
+	goPackages := make(map[string]*packageBuilder)
+	jsPackages := make(map[string]*packageBuilder)
+	modulePath := readGoModulePath(config)
+
+	// package.json names, keyed by directory, for naming JS packages
+	jsNames := make(map[string]string)
+	for _, file := range files {
+		p := filepath.ToSlash(file.Path)
+		if path.Base(p) == "package.json" {
+			var manifest struct {
+				Name string `json:"name"`
+			}
+			if json.Unmarshal([]byte(file.Content), &manifest) == nil && manifest.Name != "" {
+				jsNames[path.Dir(p)] = manifest.Name
+			}
+		}
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		p := filepath.ToSlash(file.Path)
+		dir := path.Dir(p)
+		ext := path.Ext(p)
+
+		switch {
+		case ext == ".go":
+			pkg := getPackage(goPackages, dir)
+			pkg.info.Files = append(pkg.info.Files, file.Path)
+
+			parsed, err := parser.ParseFile(fset, p, file.Content, parser.ImportsOnly)
+			if err != nil {
+				continue
+			}
+			// External test packages (foo_test) don't name the package
+			if name := parsed.Name.Name; pkg.info.Name == "" || !strings.HasSuffix(name, "_test") {
+				pkg.info.Name = strings.TrimSuffix(name, "_test")
+			}
+			// Stripped files carry their imports alongside the content
+			importPaths := file.Imports
+			for _, spec := range parsed.Imports {
+				if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+					importPaths = append(importPaths, importPath)
+				}
+			}
+			for _, importPath := range importPaths {
+				if modulePath == "" {
+					continue
+				}
+				if importPath == modulePath {
+					pkg.imports["."] = true
+				} else if rel := strings.TrimPrefix(importPath, modulePath+"/"); rel != importPath {
+					pkg.imports[rel] = true
+				}
+			}
+
+		case jsExtensions[ext]:
+			pkg := getPackage(jsPackages, dir)
+			pkg.info.Files = append(pkg.info.Files, file.Path)
+			specs := file.Imports
+			for _, pattern := range jsImportPatterns {
+				for _, match := range pattern.FindAllStringSubmatch(file.Content, -1) {
+					specs = append(specs, match[1])
+				}
+			}
+			for _, spec := range specs {
+				if strings.HasPrefix(spec, ".") {
+					pkg.imports[path.Join(dir, spec)] = true
+				}
+			}
+		}
+	}
+
+	// JS specifiers point at files or directories; map them to package dirs
+	for _, pkg := range jsPackages {
+		resolved := make(map[string]bool)
+		for target := range pkg.imports {
+			if _, ok := jsPackages[target]; ok {
+				resolved[target] = true
+			} else if _, ok := jsPackages[path.Dir(target)]; ok {
+				resolved[path.Dir(target)] = true
+			}
+		}
+		pkg.imports = resolved
+	}
+
+	projectName := filepath.Base(config.DirPath)
+	packages := finishPackages(goPackages, nil, projectName)
+	packages = append(packages, finishPackages(jsPackages, jsNames, projectName)...)
+
+	sort.SliceStable(packages, func(i, j int) bool {
+		return packages[i].Path < packages[j].Path
+	})
+	return packages
+}
+
+// getPackage returns the builder for dir, creating it on first use
+func getPackage(set map[string]*packageBuilder, dir string) *packageBuilder {
+	pkg, ok := set[dir]
+	if !ok {
+		pkg = &packageBuilder{
+			info:    format.PackageInfo{Path: dir},
+			imports: make(map[string]bool),
+		}
+		set[dir] = pkg
+	}
+	return pkg
+}
+
+// finishPackages fills in missing names and keeps only imports of packages in
+// the same set. names overrides the directory-derived name (package.json names).
+func finishPackages(set map[string]*packageBuilder, names map[string]string, projectName string) []format.PackageInfo {
+	var packages []format.PackageInfo
+	for dir, pkg := range set {
+		if name := names[dir]; name != "" {
+			pkg.info.Name = name
+		}
+		if pkg.info.Name == "" {
+			pkg.info.Name = path.Base(dir)
+			if dir == "." {
+				pkg.info.Name = projectName
+			}
+		}
+		for target := range pkg.imports {
+			if _, ok := set[target]; ok && target != dir {
+				pkg.info.Imports = append(pkg.info.Imports, target)
+			}
+		}
+		sort.Strings(pkg.info.Files)
+		sort.Strings(pkg.info.Imports)
+		packages = append(packages, pkg.info)
+	}
+	return packages
+}
+
+// readGoModulePath returns the module path declared in the project's go.mod, if any
+func readGoModulePath(config Config) string {
+	data, err := readFile(config, filepath.Join(config.DirPath, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if rest := strings.TrimPrefix(line, "module"); rest != line {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// filterPackages restricts packages to the kept files, dropping packages left
+// without files and imports of dropped packages
+func filterPackages(packages []format.PackageInfo, keep map[string]bool) []format.PackageInfo {
+	remaining := make(map[string]bool)
+	var filtered []format.PackageInfo
+	for _, pkg := range packages {
+		var files []string
+		for _, file := range pkg.Files {
+			if keep[file] {
+				files = append(files, file)
+			}
+		}
+		if len(files) == 0 {
+			continue
+		}
+		pkg.Files = files
+		filtered = append(filtered, pkg)
+		remaining[pkg.Path] = true
+	}
+
+	for i := range filtered {
+		var imports []string
+		for _, target := range filtered[i].Imports {
+			if remaining[target] {
+				imports = append(imports, target)
+			}
+		}
+		filtered[i].Imports = imports
+	}
+	return filtered
+}
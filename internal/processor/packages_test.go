@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildPackagesGo(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module example.com/shop\n\ngo 1.22\n"), 0644))
+
+	files := []format.FileInfo{
+		{Path: "main.go", Content: "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/shop/internal/cart\"\n)\n\nfunc main() { fmt.Println(cart.Total()) }\n"},
+		{Path: filepath.Join("internal", "cart", "cart.go"), Content: "package cart\n\nimport \"strings\"\n\nfunc Total() string { return strings.TrimSpace(\" 0 \") }\n"},
+		{Path: filepath.Join("internal", "cart", "cart_test.go"), Content: "package cart_test\n\nimport (\n\t\"testing\"\n\n\t\"example.com/shop/internal/cart\"\n)\n\nfunc TestTotal(t *testing.T) { cart.Total() }\n"},
+		{Path: "README.md", Content: "# Shop"},
+	}
+
+	packages := buildPackages(files, Config{DirPath: tmpDir})
+
+	assert.Equal(t, []format.PackageInfo{
+		{
+			Name:    "main",
+			Path:    ".",
+			Files:   []string{"main.go"},
+			Imports: []string{"internal/cart"},
+		},
+		{
+			Name:  "cart",
+			Path:  "internal/cart",
+			Files: []string{filepath.Join("internal", "cart", "cart.go"), filepath.Join("internal", "cart", "cart_test.go")},
+		},
+	}, packages)
+}
+
+func TestBuildPackagesJS(t *testing.T) {
+	files := []format.FileInfo{
+		{Path: "package.json", Content: `{"name": "@acme/web"}`},
+		{Path: "index.ts", Content: "import { api } from './lib/api'\nimport React from 'react'\n"},
+		{Path: "lib/api.ts", Content: "const util = require('../utils')\nexport const api = {}\n"},
+		{Path: "utils/index.js", Content: "module.exports = {}\n"},
+	}
+
+	packages := buildPackages(files, Config{DirPath: t.TempDir()})
+
+	assert.Equal(t, []format.PackageInfo{
+		{Name: "@acme/web", Path: ".", Files: []string{"index.ts"}, Imports: []string{"lib"}},
+		{Name: "lib", Path: "lib", Files: []string{"lib/api.ts"}, Imports: []string{"utils"}},
+		{Name: "utils", Path: "utils", Files: []string{"utils/index.js"}},
+	}, packages)
+}
+
+func TestFilterPackages(t *testing.T) {
+	packages := []format.PackageInfo{
+		{Name: "main", Path: ".", Files: []string{"main.go"}, Imports: []string{"util"}},
+		{Name: "util", Path: "util", Files: []string{"util/util.go"}},
+	}
+
+	filtered := filterPackages(packages, map[string]bool{"main.go": true})
+
+	assert.Equal(t, []format.PackageInfo{{Name: "main", Path: ".", Files: []string{"main.go"}}}, filtered)
+}
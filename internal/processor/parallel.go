@@ -0,0 +1,105 @@
+package processor
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// loadedFile is the outcome of loading one walked file
+type loadedFile struct {
+	file *format.FileInfo    // Set when the file is included
+	stub *format.SkippedFile // Set when it was skipped and stubs are collected
+	err  error
+}
+
+// loadFiles filters, reads and tokenizes the files at paths with
+// config.Concurrency workers. Results keep the order of paths, so the output
+// doesn't depend on the number of workers or how they are scheduled.
+// Each loaded file is reported to report.
+func loadFiles(paths []string, config Config, tokenCounter *token.TokenCounter, report *progress) ([]format.FileInfo, []format.SkippedFile, error) {
+	results := make([]loadedFile, len(paths))
+	workers := concurrency(config.Concurrency, len(paths))
+	log.Debug("Loading %d files with %d workers", len(paths), workers)
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = loadWalkedFile(paths[i], config, tokenCounter)
+				report.processed(&results[i])
+			}
+		}()
+	}
+	for i := range paths {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var files []format.FileInfo
+	var stubs []format.SkippedFile
+	for _, result := range results {
+		switch {
+		case result.err != nil:
+			return nil, nil, result.err
+		case result.file != nil:
+			log.Debug("Processing: %s (%d tokens)", result.file.Path, result.file.Tokens)
+			files = append(files, *result.file)
+		case result.stub != nil:
+			stubs = append(stubs, *result.stub)
+		}
+	}
+	return files, stubs, nil
+}
+
+// concurrency is the number of workers for n files: requested, or one per
+// CPU when requested is 0 or less, and never more than n
+func concurrency(requested, n int) int {
+	workers := requested
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > n {
+		workers = n
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// loadWalkedFile filters and loads one file found by the walk
+func loadWalkedFile(path string, config Config, tokenCounter *token.TokenCounter) loadedFile {
+	relPath, err := filepath.Rel(config.DirPath, path)
+	if err != nil {
+		return loadedFile{err: err}
+	}
+
+	// Skip excluded files silently
+	if config.Filter.IsExcluded(relPath) {
+		return loadedFile{stub: skippedStubFor(path, relPath, config)}
+	}
+
+	fileInfo, err := loadFile(path, relPath, config, tokenCounter)
+	var transformErr *TransformError
+	if errors.As(err, &transformErr) {
+		return loadedFile{err: err}
+	}
+	if err != nil {
+		log.Debug("Error processing file %s: %v", path, err)
+		return loadedFile{} // Continue processing other files
+	}
+	if fileInfo == nil {
+		return loadedFile{stub: skippedStubFor(path, relPath, config)}
+	}
+	return loadedFile{file: fileInfo}
+}
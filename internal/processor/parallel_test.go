@@ -0,0 +1,49 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryConcurrencyKeepsOrder(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 60; i++ {
+		files[fmt.Sprintf("pkg%d/file%02d.go", i%7, i)] = fmt.Sprintf("package pkg%d\n\n// file %d\n", i%7, i)
+	}
+	files["assets/logo.png"] = "\x89PNG\r\n\x1a\n\x00\x00"
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	run := func(workers int) *ProcessResult {
+		config := Config{
+			DirPath:          tmpDir,
+			Filter:           filter.New(filter.Options{UseDefaultRules: true}),
+			SkippedFileStubs: true,
+			Concurrency:      workers,
+		}
+		result, err := ProcessDirectory(config, false)
+		require.NoError(t, err)
+		return result
+	}
+
+	sequential := run(1)
+	require.Len(t, sequential.ProjectOutput.Files, 60)
+	for _, workers := range []int{0, 4, 16} {
+		parallel := run(workers)
+		assert.Equal(t, sequential.ProjectOutput.Files, parallel.ProjectOutput.Files, "workers=%d", workers)
+		assert.Equal(t, sequential.ProjectOutput.SkippedFiles, parallel.ProjectOutput.SkippedFiles, "workers=%d", workers)
+		assert.Equal(t, sequential.ClipboardContent, parallel.ClipboardContent, "workers=%d", workers)
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	assert.Equal(t, 4, concurrency(4, 100))
+	assert.Equal(t, 3, concurrency(8, 3), "never more workers than files")
+	assert.Equal(t, 1, concurrency(4, 0))
+	assert.GreaterOrEqual(t, concurrency(0, 100), 1)
+}
@@ -0,0 +1,33 @@
+package processor
+
+import "fmt"
+
+// Exit statuses for failed CI policies, distinct from errors (1) and usage
+// mistakes (2)
+const (
+	ExitOverTokens = 3 // --fail-over-tokens: the context is larger than allowed
+	ExitEmpty      = 4 // --fail-if-empty: no files matched
+)
+
+// PolicyError reports output that violates a --fail-over-tokens or
+// --fail-if-empty policy; the CLI exits with ExitCode
+type PolicyError struct {
+	ExitCode int
+	Message  string
+}
+
+func (e *PolicyError) Error() string {
+	return e.Message
+}
+
+// CheckPolicy returns a PolicyError when files and tokens break the policies
+// in opts, or nil
+func CheckPolicy(opts RunOptions, files, tokens int) error {
+	if opts.FailIfEmpty && files == 0 {
+		return &PolicyError{ExitCode: ExitEmpty, Message: "no files matched (--fail-if-empty)"}
+	}
+	if opts.FailOverTokens > 0 && tokens > opts.FailOverTokens {
+		return &PolicyError{ExitCode: ExitOverTokens, Message: fmt.Sprintf("context is %d tokens, over the %d-token limit (--fail-over-tokens)", tokens, opts.FailOverTokens)}
+	}
+	return nil
+}
@@ -9,27 +9,190 @@ import (
 	"strings"
 	"time"
 
-	"github.com/1broseidon/promptext/internal/config"
+	"github.com/1broseidon/promptext/internal/cache"
+	"github.com/1broseidon/promptext/internal/codeowners"
 	"github.com/1broseidon/promptext/internal/filter"
-	"github.com/1broseidon/promptext/internal/filter/rules"
 	"github.com/1broseidon/promptext/internal/format"
 	"github.com/1broseidon/promptext/internal/info"
 	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/redact"
 	"github.com/1broseidon/promptext/internal/relevance"
 	"github.com/1broseidon/promptext/internal/token"
-	"github.com/atotto/clipboard"
 	"github.com/jedib0t/go-pretty/v6/text"
 )
 
 type Config struct {
 	DirPath           string
+	Roots             []string // Subdirectories or files of DirPath to process instead of all of it (nil = DirPath)
 	Extensions        []string
 	Excludes          []string
 	GitIgnore         bool
 	Filter            *filter.Filter
-	RelevanceKeywords string // Keywords for relevance filtering
-	MaxTokens         int    // Maximum token budget (0 = unlimited)
-	ExplainSelection  bool   // Show priority scoring breakdown
+	RelevanceKeywords string   // Keywords for relevance filtering
+	ExactKeywords     bool     // Match keywords as given, without stemming or synonyms
+	BoostRecent       bool     // Rank files changed recently and often in git higher
+	MaxTokens         int      // Maximum token budget (0 = unlimited)
+	ExplainSelection  bool     // Attach a SelectionReport explaining each file's ranking and inclusion
+	AssociateTests    bool     // Pair source files with the test files that cover them
+	IncludeTests      string   // TestsPaired to keep the tests of relevant files, or "" to select tests like any file
+	Owners            bool     // Attach CODEOWNERS owners to files and roll them up in Metadata.Owners
+	TodoScan          bool     // List the TODO, FIXME and HACK comments of the included files in Issues
+	LicenseScan       bool     // Inventory LICENSE files and SPDX headers in Metadata.Licenses
+	Metrics           bool     // Measure the size and complexity of the included source files in Metrics
+	AuditDeps         bool     // Look up known vulnerabilities of the dependencies in Metadata.Audit
+	AuditReport       string   // osv-scanner JSON report to read vulnerabilities from instead of the OSV API
+	WorkspacePackage  string   // Workspace member to process with the members it depends on, in place of Roots
+	CoreDirs          []string // Directories holding core code (nil = info.DefaultCoreDirs)
+	DropSizeOutliers  float64  // Drop files above this size percentile, 0-100 (0 = disabled)
+	GitLog            int      // Number of recent commits to include (0 = none)
+	Deterministic     bool     // Leave out the git branch, commit and message, which change without the files changing
+	Hashes            bool     // Record each included file's SHA-256 and the output's content hash (ProjectOutput.Integrity)
+	StripImports      bool     // Remove import blocks from file content, summarized in Dependencies
+	Redact            bool     // Replace detected secrets with placeholders, summarized in Redactions
+	StripComments     bool     // Remove comments from source in known languages
+	SquashBlankLines  bool     // Collapse runs of blank lines into one
+	Compact           string   // Compact whitespace: CompactWhitespace, CompactIndent, or "" for none
+	NotebookOutputs   bool     // Keep the text outputs of Jupyter notebook cells when flattening notebooks
+	SummarizeAssets   bool     // Replace lockfiles and minified JS/CSS with summaries of what they hold
+	SampleRows        int      // Keep only the header and first rows of CSV, TSV and JSON Lines files (0 = all)
+	MaxFileTokens     int      // Truncate files above this many tokens (0 = no limit)
+	TruncateStrategy  string   // How to truncate: TruncateHead (default), TruncateHeadTail, TruncateSignatures
+	Tokenizer         string   // Token counting backend, one of token.Tokenizers() or a local model such as "ollama:llama3" ("" = cl100k)
+	SkippedFileStubs  bool     // List binary, oversized and generated files as stubs instead of dropping them silently
+	FollowImports     int      // Import hops to follow from highly relevant files (0 = off)
+	Snippets          bool     // Cut relevant files down to the definitions whose name matches a keyword
+	SnippetContext    int      // Lines kept around each snippet
+	Concurrency       int      // Files read and tokenized in parallel (0 = one per CPU)
+
+	// BudgetWeights, when set, splits MaxTokens between directories
+	// ("internal/") and extensions (".md") by fraction instead of filling it
+	// greedily in priority order (see selectWithinBudget)
+	BudgetWeights map[string]float64
+
+	// Annotations attach notes to the files matching gitignore-style globs
+	Annotations map[string]string
+
+	// ExtraFiles are absolute paths of files outside DirPath to include as
+	// well, labeled relative to DirPath and marked External. They are read
+	// from disk even when FS is set, and ranked and budgeted like any file.
+	ExtraFiles []string
+
+	// FileRules, when set, decide on files after the built-in path, size and
+	// binary checks: they can exclude files, keep files content rules would
+	// skip, and adjust relevance scores
+	FileRules []FileRule
+
+	// Transform, when set, rewrites each file's content before the built-in
+	// transforms and token counting. An error fails the extraction. Cache
+	// is not used, since the transform's output can't be keyed.
+	Transform TransformFunc
+
+	// Synonyms are extra words each relevance keyword matches, on top of
+	// the built-in ones (relevance.synonyms in .promptext.yml)
+	Synonyms map[string][]string
+
+	// Scorer, when set, replaces keyword relevance scoring. Files it scores 0
+	// are excluded, as with keywords that match nothing.
+	Scorer relevance.BatchScorer
+
+	// Render, when set, renders output in the target format. The token budget
+	// then counts rendered tokens, including per-file framing, instead of
+	// summing raw file content estimates.
+	Render RenderFunc
+
+	// TreeCache, when set, reuses the directory tree across runs on an unchanged directory
+	TreeCache *info.TreeCache
+
+	// Cache, when set, reuses processed content and token counts of files
+	// unchanged since a previous run
+	Cache *cache.Cache
+
+	// Progress, when set, is called as files are found and loaded. Calls come
+	// from the load workers but never overlap.
+	Progress func(ProgressEvent)
+
+	// FS, when set, holds the project's files in place of the disk, such as an
+	// opened archive. DirPath then only names the project root, which
+	// config.FS's "." stands for. Git info and project metadata are not
+	// gathered, and Cache and TreeCache must be nil.
+	FS fs.FS
+
+	// GitRef names the git commit FS was read from, if any. Git info and
+	// GitLog then describe that commit instead of HEAD.
+	GitRef string
+
+	// Symlinks is the policy for symlinks met walking the disk: SymlinksSkip
+	// (the default when empty), SymlinksFollow or SymlinksFollowInRoot
+	Symlinks string
+
+	// MaxFileSize, when set, skips files larger than this many bytes as too
+	// large. It can only lower the 10 MB above which every file counts as
+	// binary.
+	MaxFileSize int64
+
+	// MaxTotalSize, when set, fails the extraction with ErrTotalSizeExceeded
+	// before reading any file when the files to read add up to more bytes
+	MaxTotalSize int64
+
+	// symlinkSkipped, when set, is told of each symlink the policy leaves out
+	symlinkSkipped func(SkippedSymlink)
+}
+
+// walkRoots walks DirPath, or each of config.Roots when set. Paths passed to
+// fn are still absolute, so relative paths stay namespaced under DirPath.
+func walkRoots(config Config, fn fs.WalkDirFunc) error {
+	roots := config.Roots
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+	for _, root := range roots {
+		if err := walkRoot(config, root, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkRoot walks one root, on disk under the symlink policy (see walkDisk)
+// or in config.FS
+func walkRoot(config Config, root string, fn fs.WalkDirFunc) error {
+	if config.FS == nil {
+		return walkDisk(config, filepath.Join(config.DirPath, root), fn)
+	}
+	return fs.WalkDir(config.FS, filepath.ToSlash(root), func(name string, d fs.DirEntry, err error) error {
+		return fn(filepath.Join(config.DirPath, filepath.FromSlash(name)), d, err)
+	})
+}
+
+// projectInfoFor gathers project info, with the tree limited to config.Roots
+// when set. Deterministic configs get no git info.
+func projectInfoFor(config Config) (*info.ProjectInfo, error) {
+	projectInfo, err := gatherInfo(config)
+	if err == nil && config.Deterministic {
+		projectInfo.GitInfo = nil
+	}
+	return projectInfo, err
+}
+
+// gatherInfo reads the project info from config.FS, config.Roots or the whole directory
+func gatherInfo(config Config) (*info.ProjectInfo, error) {
+	if config.FS != nil {
+		projectInfo, err := info.GetProjectInfoFS(config.FS, config.DirPath, config.Roots, config.Filter)
+		if err == nil && config.GitRef != "" {
+			projectInfo.GitInfo = info.GetGitRefInfo(config.DirPath, config.GitRef)
+		}
+		return projectInfo, err
+	}
+	if config.Symlinks == SymlinksFollow || config.Symlinks == SymlinksFollowInRoot {
+		// The tree shows what the symlinks lead to, which the tree cache can't watch
+		return info.GetProjectInfoWalk(config.DirPath, config.Roots, config.Filter, func(start string, fn fs.WalkDirFunc) error {
+			return walkDisk(config, start, fn)
+		})
+	}
+	if len(config.Roots) > 0 {
+		return info.GetProjectInfoForRoots(config.DirPath, config.Roots, config.Filter)
+	}
+	return info.GetProjectInfoWithCache(config.DirPath, config.Filter, config.TreeCache)
 }
 
 func ParseCommaSeparated(input string) []string {
@@ -39,22 +202,20 @@ func ParseCommaSeparated(input string) []string {
 	return strings.Split(input, ",")
 }
 
+// Reasons recorded on ExcludedFileInfo
+const (
+	ExcludeReasonRelevance   = "relevance"        // No keyword matches
+	ExcludeReasonNegative    = "negative-keyword" // Path matches a negative relevance keyword
+	ExcludeReasonTokenBudget = "token-budget"     // Would exceed --max-tokens
+	ExcludeReasonOutputCap   = "output-cap"       // Dropped to keep rendered output under the hard cap
+	ExcludeReasonSizeOutlier = "size-outlier"     // Larger than the configured size percentile
+)
+
 // ExcludedFileInfo contains information about an excluded file
 type ExcludedFileInfo struct {
 	Path   string
 	Tokens int
-}
-
-// FilePriorityInfo contains information about a file's priority for explain-selection
-type FilePriorityInfo struct {
-	Path     string
-	Tokens   int
-	Score    float64
-	IsEntry  bool
-	IsTest   bool
-	IsConfig bool
-	Depth    int
-	Included bool
+	Reason string // Why the file was excluded (see ExcludeReason* constants)
 }
 
 // ProcessResult contains both display and clipboard content
@@ -67,7 +228,7 @@ type ProcessResult struct {
 	ProjectInfo      *info.ProjectInfo
 	ExcludedFiles    int                // Number of files excluded due to token budget
 	ExcludedFileList []ExcludedFileInfo // Details of excluded files
-	PriorityList     []FilePriorityInfo // Priority breakdown for explain-selection
+	Warnings         []string           // Exclude patterns the walk found no match for
 }
 
 // DryRunResult contains dry-run preview information
@@ -76,6 +237,8 @@ type DryRunResult struct {
 	EstimatedTokens int
 	ConfigSummary   *ConfigSummary
 	ProjectInfo     *info.ProjectInfo
+	SkippedSymlinks []SkippedSymlink // Symlinks the policy leaves out, in walk order
+	Warnings        []string         // Exclude patterns the walk found no match for
 }
 
 // ConfigSummary contains effective configuration information
@@ -108,9 +271,9 @@ func validateFilePath(path string, config Config) (string, error) {
 }
 
 // checkFilePermissions validates file type and permissions
-func checkFilePermissions(path string) error {
+func checkFilePermissions(path string, config Config) error {
 	// Get file info first to check if it's a directory or has read permissions
-	fileInfo, err := os.Stat(path)
+	fileInfo, err := statFile(config, path)
 	if err != nil {
 		return err
 	}
@@ -125,9 +288,13 @@ func checkFilePermissions(path string) error {
 		return fmt.Errorf("no read permissions")
 	}
 
+	// Skip files over the size cap before reading them
+	if fileInfo.Size() > maxFileSize(config) {
+		return fmt.Errorf("too large")
+	}
+
 	// Check if file is binary using BinaryRule
-	binaryRule := rules.NewBinaryRule()
-	if binaryRule.Match(path) {
+	if isBinaryFile(config, path) {
 		return fmt.Errorf("binary file")
 	}
 
@@ -135,8 +302,8 @@ func checkFilePermissions(path string) error {
 }
 
 // readFileContent reads and returns file content as string
-func readFileContent(path string) (string, error) {
-	content, err := os.ReadFile(path)
+func readFileContent(path string, config Config) (string, error) {
+	content, err := readFile(config, path)
 	if err != nil {
 		return "", err
 	}
@@ -145,27 +312,42 @@ func readFileContent(path string) (string, error) {
 
 // processFile handles the processing of a single file
 func processFile(path string, config Config) (*format.FileInfo, error) {
+	fileInfo, _, err := checkAndReadFile(path, config)
+	return fileInfo, err
+}
+
+// checkAndReadFile runs the built-in checks on the file at path, then
+// config.FileRules, and reads the file if both keep it
+func checkAndReadFile(path string, config Config) (*format.FileInfo, fileRuleOutcome, error) {
 	rel, err := validateFilePath(path, config)
 	if err != nil {
-		return nil, err
+		return nil, fileRuleOutcome{}, err
 	}
 	if rel == "" {
-		return nil, nil // File should be skipped
+		return nil, fileRuleOutcome{}, nil // File should be skipped
 	}
 
-	if err := checkFilePermissions(path); err != nil {
-		return nil, nil // File should be skipped
+	if err := checkFilePermissions(path, config); err != nil {
+		return nil, fileRuleOutcome{}, nil // File should be skipped
 	}
 
-	content, err := readFileContent(path)
+	read := lazyContent(path, config)
+	outcome := applyFileRules(config.FileRules, rel, read)
+	if outcome.excludedBy != "" {
+		return nil, outcome, nil
+	}
+
+	content, err := read()
 	if err != nil {
-		return nil, nil // File should be skipped
+		return nil, outcome, nil // File should be skipped
 	}
 
 	return &format.FileInfo{
-		Path:    rel,
-		Content: content,
-	}, nil
+		Path:            rel,
+		Content:         content,
+		ScoreAdjustment: outcome.adjustment,
+		Hash:            format.FileHash([]byte(content)),
+	}, outcome, nil
 }
 
 // populateProjectInfo adds project information to the output
@@ -182,11 +364,25 @@ func populateProjectInfo(projectOutput *format.ProjectOutput, projectInfo *info.
 
 	if projectInfo.Metadata != nil {
 		projectOutput.Metadata = &format.Metadata{
-			Language:     projectInfo.Metadata.Language,
-			Version:      projectInfo.Metadata.Version,
-			Dependencies: projectInfo.Metadata.Dependencies,
+			Language: projectInfo.Metadata.Language,
+			Version:  projectInfo.Metadata.Version,
+		}
+		for _, dep := range projectInfo.Metadata.Dependencies {
+			projectOutput.Metadata.Dependencies = append(projectOutput.Metadata.Dependencies, format.Dependency(dep))
+		}
+		for _, framework := range projectInfo.Metadata.Frameworks {
+			projectOutput.Metadata.Frameworks = append(projectOutput.Metadata.Frameworks, format.Framework(framework))
 		}
 	}
+
+	for _, commit := range projectInfo.RecentCommits {
+		projectOutput.RecentCommits = append(projectOutput.RecentCommits, format.CommitInfo{
+			Hash:    commit.Hash,
+			Date:    commit.Date,
+			Subject: commit.Subject,
+			Body:    commit.Body,
+		})
+	}
 }
 
 // PreviewDirectory performs a dry-run preview of what files would be processed
@@ -206,15 +402,30 @@ func PreviewDirectory(config Config) (*DryRunResult, error) {
 	}
 
 	// Collect files that would be processed
-	tokenCounter := token.NewTokenCounter()
+	tokenCounter, err := token.NewTokenCounterFor(config.Tokenizer)
+	if err != nil {
+		return nil, err
+	}
 	var estimatedTokens int
 
 	log.Debug("=== Dry Run: Analyzing Files ===")
 
-	err := filepath.WalkDir(config.DirPath, func(path string, d fs.DirEntry, err error) error {
+	if config.WorkspacePackage != "" {
+		if config, err = withWorkspacePackage(config, detectWorkspace(config)); err != nil {
+			return nil, err
+		}
+	}
+	// The file walk records the symlinks it leaves out; the tree's walk repeats them
+	walkConfig := config
+	walkConfig.symlinkSkipped = func(link SkippedSymlink) {
+		result.SkippedSymlinks = append(result.SkippedSymlinks, link)
+	}
+	excludes := newExcludeCheck(config)
+	err = walkRoots(walkConfig, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		excludes.observe(path)
 
 		// Get relative path for filtering
 		relPath, err := filepath.Rel(config.DirPath, path)
@@ -224,10 +435,7 @@ func PreviewDirectory(config Config) (*DryRunResult, error) {
 
 		// For directories
 		if d.IsDir() {
-			if config.Filter.IsExcluded(relPath) {
-				return filepath.SkipDir
-			}
-			return nil
+			return walkDir(path, config)
 		}
 
 		// Skip excluded files silently
@@ -245,7 +453,7 @@ func PreviewDirectory(config Config) (*DryRunResult, error) {
 		}
 
 		// Check permissions and file type without reading content
-		if err := checkFilePermissions(path); err != nil {
+		if err := checkFilePermissions(path, config); err != nil {
 			return nil // Skip files that would fail permission check
 		}
 
@@ -253,7 +461,7 @@ func PreviewDirectory(config Config) (*DryRunResult, error) {
 		result.FilePaths = append(result.FilePaths, rel)
 
 		// Estimate tokens based on file size (rough approximation: 4 chars per token)
-		if fileInfo, err := os.Stat(path); err == nil {
+		if fileInfo, err := statFile(config, path); err == nil {
 			estimatedFileTokens := int(fileInfo.Size() / 4)
 			estimatedTokens += estimatedFileTokens
 			log.Debug("Would process: %s (estimated %d tokens)", rel, estimatedFileTokens)
@@ -267,9 +475,10 @@ func PreviewDirectory(config Config) (*DryRunResult, error) {
 	}
 
 	result.EstimatedTokens = estimatedTokens
+	result.Warnings = excludes.warnings()
 
 	// Get project info for dry-run
-	if projectInfo, err := info.GetProjectInfo(config.DirPath, config.Filter); err == nil {
+	if projectInfo, err := projectInfoFor(config); err == nil {
 		result.ProjectInfo = projectInfo
 
 		// Add estimated tokens for metadata (rough approximation)
@@ -283,18 +492,12 @@ func PreviewDirectory(config Config) (*DryRunResult, error) {
 	return result, nil
 }
 
-// processFileInWalk handles individual file processing during directory walk
-func processFileInWalk(path string, d fs.DirEntry, config Config, tokenCounter *token.TokenCounter, processedFiles *[]format.FileInfo, totalTokens *int, verbose bool) error {
-	if d.IsDir() {
-		// Get relative path for filtering
-		relPath, err := filepath.Rel(config.DirPath, path)
-		if err != nil {
-			return err
-		}
-		if config.Filter.IsExcluded(relPath) {
-			return filepath.SkipDir
-		}
-		return nil
+// walkDir decides whether the walk descends into a directory, returning
+// filepath.SkipDir for excluded directories
+func walkDir(path string, config Config) error {
+	// Never read the cache back as project content
+	if config.Cache != nil && path == config.Cache.Dir() || filepath.Base(path) == cache.DirName {
+		return filepath.SkipDir
 	}
 
 	// Get relative path for filtering
@@ -302,33 +505,128 @@ func processFileInWalk(path string, d fs.DirEntry, config Config, tokenCounter *
 	if err != nil {
 		return err
 	}
-
-	// Skip excluded files silently
 	if config.Filter.IsExcluded(relPath) {
-		return nil
+		return filepath.SkipDir
 	}
+	return nil
+}
 
-	// Process file
-	fileInfo, err := processFile(path, config)
-	if err != nil {
-		log.Debug("Error processing file %s: %v", path, err)
-		return nil // Continue processing other files
+// loadFile processes a file and counts its tokens. With a cache configured,
+// files unchanged since they were cached skip reading and tokenization.
+func loadFile(path, relPath string, config Config, tokenCounter *token.TokenCounter) (*format.FileInfo, error) {
+	var stat os.FileInfo
+	// Cached content depends on the tokenizer and on content transforms
+	variant := fmt.Sprintf("%s strip-imports=%t redact=%t strip-comments=%t squash=%t compact=%s notebook-outputs=%t summarize=%t sample=%d licenses=%t", tokenCounter.GetEncodingName(), config.StripImports, config.Redact, config.StripComments, config.SquashBlankLines, config.Compact, config.NotebookOutputs, config.SummarizeAssets, config.SampleRows, config.LicenseScan)
+	if config.Cache != nil && config.Transform == nil {
+		if !config.Filter.ShouldProcess(relPath) {
+			return nil, nil
+		}
+		if s, err := statFile(config, path); err == nil {
+			stat = s
+			if stat.Size() > maxFileSize(config) {
+				return nil, nil // Cached under a higher size cap
+			}
+			if entry, ok := config.Cache.Get(relPath, stat, variant); ok {
+				// Rules see the file as on disk, not the cached transformed content
+				outcome := applyFileRules(config.FileRules, relPath, lazyContent(path, config))
+				if outcome.excludedBy != "" || (!outcome.included && config.Filter.ExcludesContent(relPath, entry.Content)) {
+					return nil, nil
+				}
+				fileInfo := &format.FileInfo{
+					Path:            relPath,
+					Content:         entry.Content,
+					Tokens:          entry.Tokens,
+					Imports:         entry.Imports,
+					Redactions:      entry.Redactions,
+					Compaction:      entry.Compaction,
+					Sample:          sampleFromCache(entry.Sample),
+					License:         entry.License,
+					Copyright:       entry.Copyright,
+					ScoreAdjustment: outcome.adjustment,
+					Hash:            entry.Hash,
+				}
+				truncateFile(fileInfo, config.MaxFileTokens, config.TruncateStrategy, tokenCounter)
+				return fileInfo, nil
+			}
+		}
 	}
 
-	if fileInfo != nil {
-		// Count tokens and log immediately
-		fileTokens := tokenCounter.EstimateTokens(fileInfo.Content)
-		fileInfo.Tokens = fileTokens // Store token count in FileInfo (PTX v2.0)
-		*totalTokens += fileTokens
-		log.Debug("Processing: %s (%d tokens)", relPath, fileTokens)
+	fileInfo, outcome, err := checkAndReadFile(path, config)
+	if err != nil || fileInfo == nil {
+		return fileInfo, err
+	}
+	if !outcome.included && config.Filter.ExcludesContent(relPath, fileInfo.Content) {
+		return nil, nil
+	}
 
-		*processedFiles = append(*processedFiles, *fileInfo)
+	if err := transformContent(fileInfo, relPath, config, tokenCounter); err != nil {
+		return nil, err
+	}
 
-		if verbose && !log.IsDebugEnabled() {
-			fmt.Printf("\n### File: %s\n```\n%s\n```\n", path, fileInfo.Content)
+	if config.Cache != nil && stat != nil {
+		config.Cache.Put(relPath, stat, cache.Entry{
+			Variant:    variant,
+			Content:    fileInfo.Content,
+			Tokens:     fileInfo.Tokens,
+			Imports:    fileInfo.Imports,
+			Redactions: fileInfo.Redactions,
+			Compaction: fileInfo.Compaction,
+			Sample:     sampleToCache(fileInfo.Sample),
+			License:    fileInfo.License,
+			Copyright:  fileInfo.Copyright,
+			Hash:       fileInfo.Hash,
+		})
+	}
+
+	// Truncate after caching so the cache holds full content regardless of limits
+	truncateFile(fileInfo, config.MaxFileTokens, config.TruncateStrategy, tokenCounter)
+	return fileInfo, nil
+}
+
+// transformContent applies config.Transform and the content transforms
+// config enables to fileInfo, and counts its tokens
+func transformContent(fileInfo *format.FileInfo, relPath string, config Config, tokenCounter *token.TokenCounter) error {
+	if config.LicenseScan {
+		// Read before comments can be stripped
+		readLicenseHeader(fileInfo)
+	}
+	if config.Transform != nil {
+		content, err := config.Transform(relPath, []byte(fileInfo.Content))
+		if err != nil {
+			return &TransformError{Path: relPath, Err: err}
 		}
+		fileInfo.Content = string(content)
 	}
-
+	if isNotebook(relPath) {
+		fileInfo.Content = flattenNotebook(fileInfo.Content, config.NotebookOutputs)
+	}
+	if config.SampleRows > 0 {
+		fileInfo.Content, fileInfo.Sample = sampleDataFile(relPath, fileInfo.Content, config.SampleRows)
+	}
+	if config.SummarizeAssets {
+		if summary, ok := summarizeAsset(relPath, fileInfo.Content); ok {
+			// The summary is generated text, so the other transforms don't apply
+			fileInfo.Content = summary
+			fileInfo.Tokens = tokenCounter.EstimateTokens(fileInfo.Content)
+			return nil
+		}
+	}
+	if config.StripComments {
+		fileInfo.Content = stripComments(relPath, fileInfo.Content)
+	}
+	if config.SquashBlankLines {
+		fileInfo.Content = squashBlankLines(fileInfo.Content)
+	}
+	if config.Compact != "" {
+		fileInfo.Content, fileInfo.Compaction = compactContent(relPath, fileInfo.Content, config.Compact)
+	}
+	if config.StripImports {
+		fileInfo.Content, fileInfo.Imports = stripImports(relPath, fileInfo.Content)
+	}
+	if config.Redact {
+		fileInfo.Content, fileInfo.Redactions = redact.Redact(relPath, fileInfo.Content)
+	}
+	fileInfo.Tokens = tokenCounter.EstimateTokens(fileInfo.Content) // PTX v2.0: per-file token count
 	return nil
 }
 
@@ -372,6 +670,28 @@ func filterDirectoryTree(node *format.DirectoryNode, includedFiles map[string]bo
 	return filtered
 }
 
+// calculateFileStats computes line and package counts for the given files
+func calculateFileStats(files []format.FileInfo) *format.FileStatistics {
+	totalLines := 0
+	packages := make(map[string]bool)
+
+	for _, file := range files {
+		totalLines += strings.Count(file.Content, "\n") + 1
+
+		// Extract package directory for Go projects
+		dir := filepath.Dir(file.Path)
+		if dir != "." && dir != "" {
+			packages[dir] = true
+		}
+	}
+
+	return &format.FileStatistics{
+		TotalFiles:   len(files),
+		TotalLines:   totalLines,
+		PackageCount: len(packages),
+	}
+}
+
 // filePriority calculates priority score for sorting files
 // Higher scores should be processed first
 type filePriority struct {
@@ -380,11 +700,50 @@ type filePriority struct {
 	isEntry  bool
 	isTest   bool
 	isConfig bool
+	isCore   bool
 	depth    int
 }
 
+// relevanceScorer returns the scorer for config and whether it ranks files at
+// all: a custom Scorer is run once over files, otherwise keywords are used
+func relevanceScorer(config Config, files []format.FileInfo) (relevance.FileScorer, bool, error) {
+	if config.Scorer == nil {
+		keywords := relevance.NewScorerWith(config.RelevanceKeywords, relevance.ExpandOptions{
+			Exact:    config.ExactKeywords,
+			Synonyms: config.Synonyms,
+		})
+		for keyword, words := range keywords.Expansions() {
+			log.Debug("Relevance keyword %q also matches: %s", keyword, strings.Join(words, ", "))
+		}
+		return keywords, keywords.HasKeywords(), nil
+	}
+
+	contents := make([]relevance.FileContent, len(files))
+	for i, file := range files {
+		contents[i] = relevance.FileContent{Path: file.Path, Content: file.Content}
+	}
+	scores, err := relevance.Precompute(config.Scorer, contents)
+	if err != nil {
+		return nil, false, err
+	}
+	return scores, true, nil
+}
+
+// isTestPath reports whether prioritization treats path as a test file
+func isTestPath(path string) bool {
+	return strings.Contains(path, "test") || strings.HasSuffix(path, "_test.go")
+}
+
+// isConfigPath reports whether prioritization treats path as a config file
+func isConfigPath(path string) bool {
+	return strings.Contains(strings.ToLower(filepath.Base(path)), "config") ||
+		strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml") ||
+		strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".toml")
+}
+
 // prioritizeFiles sorts files by priority based on relevance and file characteristics
-func prioritizeFiles(files []format.FileInfo, scorer *relevance.Scorer, entryPoints map[string]bool) []format.FileInfo {
+// Files under coreDirs (see info.IsCoreFile) rank ahead of other non-relevant files.
+func prioritizeFiles(files []format.FileInfo, scorer relevance.FileScorer, entryPoints map[string]bool, coreDirs []string) []format.FileInfo {
 	if len(files) == 0 {
 		return files
 	}
@@ -397,10 +756,8 @@ func prioritizeFiles(files []format.FileInfo, scorer *relevance.Scorer, entryPoi
 
 		// Check file characteristics
 		isEntry := entryPoints[file.Path]
-		isTest := strings.Contains(file.Path, "test") || strings.HasSuffix(file.Path, "_test.go")
-		isConfig := strings.Contains(strings.ToLower(filepath.Base(file.Path)), "config") ||
-			strings.HasSuffix(file.Path, ".yml") || strings.HasSuffix(file.Path, ".yaml") ||
-			strings.HasSuffix(file.Path, ".json") || strings.HasSuffix(file.Path, ".toml")
+		isTest := isTestPath(file.Path)
+		isConfig := isConfigPath(file.Path)
 
 		// Calculate relevance score
 		relevanceScore := scorer.ScoreFile(file.Path, file.Content)
@@ -411,6 +768,7 @@ func prioritizeFiles(files []format.FileInfo, scorer *relevance.Scorer, entryPoi
 			isEntry:  isEntry,
 			isTest:   isTest,
 			isConfig: isConfig,
+			isCore:   info.IsCoreFile(file.Path, coreDirs),
 			depth:    depth,
 		}
 	}
@@ -443,11 +801,18 @@ func prioritizeFiles(files []format.FileInfo, scorer *relevance.Scorer, entryPoi
 			}
 		}
 
-		// 4. Config files before other non-relevant files
+		// 4. Config files, then core files, then higher scores (weak keyword
+		// matches, recency boost) among files below the threshold
 		if !piHighRelevance && !pjHighRelevance {
 			if pi.isConfig != pj.isConfig {
 				return pi.isConfig
 			}
+			if pi.isCore != pj.isCore {
+				return pi.isCore
+			}
+			if pi.score != pj.score {
+				return pi.score > pj.score
+			}
 		}
 
 		// 5. Tests come last
@@ -482,62 +847,188 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 
 	// Combined file processing and token analysis
 	log.StartTimer("Processing Files")
-	tokenCounter := token.NewTokenCounter()
+	tokenCounter, err := token.NewTokenCounterFor(config.Tokenizer)
+	if err != nil {
+		return nil, err
+	}
 	log.Debug("=== Processing Files & Counting Tokens ===")
 	var totalTokens int
 
-	// Process all files first
-	var processedFiles []format.FileInfo
-	err := filepath.WalkDir(config.DirPath, func(path string, d fs.DirEntry, err error) error {
+	// Narrow a workspace to one package and the packages it depends on
+	workspace := detectWorkspace(config)
+	if config.WorkspacePackage != "" {
+		if config, err = withWorkspacePackage(config, workspace); err != nil {
+			return nil, err
+		}
+	}
+
+	// Walk first, then read and tokenize the files in parallel
+	report := newProgress(config.Progress)
+	var paths []string
+	excludes := newExcludeCheck(config)
+	err = walkRoots(config, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
-		return processFileInWalk(path, d, config, tokenCounter, &processedFiles, &totalTokens, verbose)
+		excludes.observe(path)
+		if d.IsDir() {
+			return walkDir(path, config)
+		}
+		paths = append(paths, path)
+		report.scanned()
+		return nil
 	})
+	if err != nil {
+		return nil, fmt.Errorf("error processing files: %w", err)
+	}
+	if err := checkTotalSize(paths, config); err != nil {
+		return nil, err
+	}
 
+	processedFiles, skippedFiles, err := loadFiles(paths, config, tokenCounter, report)
 	if err != nil {
 		return nil, fmt.Errorf("error processing files: %w", err)
 	}
+	extraFiles, err := loadExtraFiles(config, tokenCounter, processedFiles)
+	if err != nil {
+		return nil, err
+	}
+	processedFiles = append(processedFiles, extraFiles...)
+	report.done()
+	annotations := newAnnotator(config.Annotations)
+	var ownersSource string
+	if config.Owners {
+		var owners *codeowners.File
+		if owners, ownersSource = loadCodeowners(config); owners != nil {
+			attachOwners(processedFiles, owners)
+		}
+	}
+	for i, file := range processedFiles {
+		annotations.annotate(&processedFiles[i])
+		totalTokens += file.Tokens
+		if verbose && !log.IsDebugEnabled() {
+			fmt.Printf("\n### File: %s\n```\n%s\n```\n", filepath.Join(config.DirPath, file.Path), file.Content)
+		}
+	}
+	if config.SkippedFileStubs {
+		projectOutput.SkippedFiles = skippedFiles
+	}
 	log.EndTimer("Processing Files")
 
+	if config.Cache != nil {
+		if err := config.Cache.Save(); err != nil {
+			log.Warn("%v", err)
+		}
+	}
+
+	// Pair source files with their tests before any budget or relevance filtering
+	// so associations reflect the whole candidate set
+	if config.AssociateTests {
+		associateTests(processedFiles)
+	}
+
+	// Drop size outliers from the candidate set before prioritization
+	var excludedFileCount int
+	var excludedFileList []ExcludedFileInfo
+	if config.DropSizeOutliers > 0 {
+		var outliers []ExcludedFileInfo
+		processedFiles, outliers = dropSizeOutliers(processedFiles, config.DropSizeOutliers)
+		if len(outliers) > 0 {
+			excludedFileCount += len(outliers)
+			excludedFileList = append(excludedFileList, outliers...)
+			totalTokens = 0
+			for _, file := range processedFiles {
+				totalTokens += file.Tokens
+			}
+		}
+	}
+
 	// Get project info early for entry point detection
 	log.StartTimer("Project Analysis")
-	projectInfo, err := info.GetProjectInfo(config.DirPath, config.Filter)
+	projectInfo, err := projectInfoFor(config)
 	if err != nil {
 		return &ProcessResult{}, fmt.Errorf("error getting project info: %w", err)
 	}
+	if config.GitLog > 0 {
+		if commits, err := info.GetRecentCommits(config.DirPath, config.GitRef, config.GitLog); err == nil {
+			projectInfo.RecentCommits = commits
+		} else {
+			log.Debug("Skipping recent commits: %v", err)
+		}
+	}
 	log.EndTimer("Project Analysis")
 
 	// Apply relevance scoring and prioritization if keywords provided
-	var excludedFileCount int
-	var excludedFileList []ExcludedFileInfo
-	scorer := relevance.NewScorer(config.RelevanceKeywords)
-	if scorer.HasKeywords() || config.MaxTokens > 0 {
+	var ranked []format.SelectionEntry
+	scorer, scoring, err := relevanceScorer(config, processedFiles)
+	if err != nil {
+		return nil, fmt.Errorf("error scoring relevance: %w", err)
+	}
+	if scoring {
+		scorer = withAdjustments(scorer, processedFiles)
+	}
+	if config.BoostRecent && (scoring || config.MaxTokens > 0) {
+		scorer = withRecencyBoost(scorer, config, scoring)
+	}
+	if scoring || config.MaxTokens > 0 {
 		log.Debug("=== Applying Relevance & Token Budget ===")
 
 		// Build entry points map using common patterns
-		entryPoints := detectEntryPoints(processedFiles)
+		entryPoints := detectEntryPoints(processedFiles, config.Filter)
 
 		// Prioritize files
-		processedFiles = prioritizeFiles(processedFiles, scorer, entryPoints)
+		processedFiles = prioritizeFiles(processedFiles, scorer, entryPoints, config.CoreDirs)
 		log.Debug("Files sorted by priority")
 
-		// Filter files by relevance if keywords provided
-		if scorer.HasKeywords() {
+		// Rank the imports of highly relevant files right behind them
+		var followed map[string]bool
+		if scoring && config.FollowImports > 0 {
+			processedFiles, followed = followImports(processedFiles, scorer, config.FollowImports, config)
+			log.Debug("Following imports: %d files pulled in by relevant files", len(followed))
+		}
+
+		// Rank the tests of relevant files right behind them
+		var paired map[string]bool
+		if scoring && config.IncludeTests == TestsPaired {
+			processedFiles, paired = pairTests(processedFiles, scorer)
+			log.Debug("Pairing tests: %d tests pulled in by relevant files", len(paired))
+		}
+		if config.ExplainSelection {
+			ranked = rankSelection(processedFiles, scorer, entryPoints, config.CoreDirs, followed, paired)
+		}
+
+		// Filter files by relevance if keywords or a custom scorer are provided
+		if scoring {
 			originalCount := len(processedFiles)
 			var relevantFiles []format.FileInfo
 
+			keywords, _ := baseScorer(scorer).(*relevance.Scorer)
 			for _, file := range processedFiles {
 				score := scorer.ScoreFile(file.Path, file.Content)
-				if score > 0 {
+				if keywords != nil && keywords.Negated(file.Path) {
+					excludedFileCount++
+					excludedFileList = append(excludedFileList, ExcludedFileInfo{
+						Path:   file.Path,
+						Tokens: tokenCounter.EstimateTokens(file.Content),
+						Reason: ExcludeReasonNegative,
+					})
+					log.Debug("Excluding (negative keyword): %s", file.Path)
+				} else if score > 0 {
 					relevantFiles = append(relevantFiles, file)
 					log.Debug("Including (relevant): %s (score: %.1f)", file.Path, score)
+				} else if followed[file.Path] {
+					relevantFiles = append(relevantFiles, file)
+					log.Debug("Including (imported by a relevant file): %s", file.Path)
+				} else if paired[file.Path] {
+					relevantFiles = append(relevantFiles, file)
+					log.Debug("Including (test of a relevant file): %s", file.Path)
 				} else {
 					excludedFileCount++
 					fileTokens := tokenCounter.EstimateTokens(file.Content)
 					excludedFileList = append(excludedFileList, ExcludedFileInfo{
 						Path:   file.Path,
 						Tokens: fileTokens,
+						Reason: ExcludeReasonRelevance,
 					})
 					log.Debug("Excluding (not relevant): %s (score: 0)", file.Path)
 				}
@@ -546,6 +1037,15 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 			processedFiles = relevantFiles
 			log.Debug("Relevance filtering: included %d/%d files with keyword matches", len(processedFiles), originalCount)
 
+			// Keep only the matching definitions of relevant files
+			if config.Snippets && keywords != nil {
+				for i := range processedFiles {
+					if extractSnippets(&processedFiles[i], keywords.Keywords(), config.SnippetContext, tokenCounter) {
+						log.Debug("Snippets: %s cut to %s", processedFiles[i].Path, processedFiles[i].Truncation.Mode)
+					}
+				}
+			}
+
 			// Recalculate totalTokens after relevance filtering
 			totalTokens = 0
 			for _, file := range processedFiles {
@@ -554,7 +1054,17 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 		}
 
 		// Apply token budget if specified
-		if config.MaxTokens > 0 {
+		if config.MaxTokens > 0 && config.Render != nil {
+			var budgetExcluded []ExcludedFileInfo
+			processedFiles, budgetExcluded = selectWithinRenderedBudget(processedFiles, projectInfo, config, tokenCounter)
+			excludedFileCount += len(budgetExcluded)
+			excludedFileList = append(excludedFileList, budgetExcluded...)
+
+			totalTokens = 0
+			for _, file := range processedFiles {
+				totalTokens += tokenCounter.EstimateTokens(file.Content)
+			}
+		} else if config.MaxTokens > 0 {
 			// Calculate overhead tokens (tree, git, metadata)
 			overheadTokens := 0
 			formatter, _ := format.GetFormatter("markdown")
@@ -578,12 +1088,16 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 			log.Debug("Token budget: %d (available for files: %d)", config.MaxTokens, availableTokens)
 
 			// Include files until budget is reached
+			costs := make([]int, len(processedFiles))
+			for i, file := range processedFiles {
+				costs[i] = tokenCounter.EstimateTokens(file.Content)
+			}
 			var filteredFiles []format.FileInfo
 			cumulativeTokens := 0
 
-			for _, file := range processedFiles {
-				fileTokens := tokenCounter.EstimateTokens(file.Content)
-				if cumulativeTokens+fileTokens <= availableTokens {
+			for i, included := range selectWithinBudget(processedFiles, costs, availableTokens, config.BudgetWeights) {
+				file, fileTokens := processedFiles[i], costs[i]
+				if included {
 					filteredFiles = append(filteredFiles, file)
 					cumulativeTokens += fileTokens
 					log.Debug("Including: %s (%d tokens, cumulative: %d)", file.Path, fileTokens, cumulativeTokens)
@@ -592,6 +1106,7 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 					excludedFileList = append(excludedFileList, ExcludedFileInfo{
 						Path:   file.Path,
 						Tokens: fileTokens,
+						Reason: ExcludeReasonTokenBudget,
 					})
 					log.Debug("Excluding: %s (%d tokens would exceed budget)", file.Path, fileTokens)
 				}
@@ -607,16 +1122,24 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 
 			log.Debug("Included %d files, excluded %d files due to token budget", len(processedFiles), excludedFileCount)
 		}
+	} else if config.ExplainSelection {
+		ranked = rankSelection(processedFiles, scorer, detectEntryPoints(processedFiles, config.Filter), config.CoreDirs, nil, nil)
 	}
 
 	// Store processed files
 	projectOutput.Files = processedFiles
+	if config.TodoScan {
+		projectOutput.Issues = scanTodos(processedFiles)
+	}
+	if config.Metrics {
+		projectOutput.Metrics = computeMetrics(processedFiles)
+	}
 
 	// Populate Budget information (PTX v2.0)
 	projectOutput.Budget = &format.BudgetInfo{
 		MaxTokens:       config.MaxTokens,
 		EstimatedTokens: totalTokens,
-		FileTruncations: 0, // Will be updated when truncation is implemented
+		FileTruncations: countTruncations(processedFiles),
 	}
 
 	// Populate FilterConfig (PTX v2.0)
@@ -626,30 +1149,35 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 	}
 
 	// Calculate file statistics
-	totalLines := 0
-	packages := make(map[string]bool)
+	projectOutput.FileStats = calculateFileStats(processedFiles)
 
-	for _, file := range processedFiles {
-		totalLines += strings.Count(file.Content, "\n") + 1
-
-		// Extract package directory for Go projects
-		dir := filepath.Dir(file.Path)
-		if dir != "." && dir != "" {
-			packages[dir] = true
+	// Populate project information (projectInfo already retrieved earlier)
+	populateProjectInfo(projectOutput, projectInfo)
+	if ownersSource != "" {
+		if projectOutput.Metadata == nil {
+			projectOutput.Metadata = &format.Metadata{}
 		}
+		projectOutput.Metadata.Owners = ownerRollup(processedFiles, ownersSource)
 	}
-
-	projectOutput.FileStats = &format.FileStatistics{
-		TotalFiles:   len(processedFiles),
-		TotalLines:   totalLines,
-		PackageCount: len(packages),
+	if config.LicenseScan {
+		if projectOutput.Metadata == nil {
+			projectOutput.Metadata = &format.Metadata{}
+		}
+		projectOutput.Metadata.Licenses = scanLicenses(config, processedFiles)
+	}
+	if config.AuditDeps || config.AuditReport != "" {
+		if projectOutput.Metadata == nil {
+			projectOutput.Metadata = &format.Metadata{}
+		}
+		audit, err := auditDependencies(config, projectOutput.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("error auditing dependencies: %w", err)
+		}
+		projectOutput.Metadata.Audit = audit
 	}
-
-	// Populate project information (projectInfo already retrieved earlier)
-	populateProjectInfo(projectOutput, projectInfo)
 
 	// Filter directory tree if files were excluded due to token budget or relevance
-	if excludedFileCount > 0 || scorer.HasKeywords() {
+	if excludedFileCount > 0 || scoring {
 		// Build set of included file paths
 		includedFiles := make(map[string]bool)
 		for _, file := range processedFiles {
@@ -662,12 +1190,61 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 		}
 		log.Debug("Filtered directory tree to show only %d included files", len(processedFiles))
 	}
+	projectOutput.DirectoryTree = withStubs(projectOutput.DirectoryTree, projectOutput.SkippedFiles)
+	projectOutput.DirectoryTree = withExternal(projectOutput.DirectoryTree, processedFiles)
+
+	// Map the package layout of the included files
+	projectOutput.Packages = buildPackages(processedFiles, config)
+	if workspace != nil {
+		countWorkspaceFiles(workspace, processedFiles)
+		projectOutput.Workspace = workspace
+	}
+	if languages := languageBreakdown(processedFiles); languages != nil {
+		if projectOutput.Metadata == nil {
+			projectOutput.Metadata = &format.Metadata{}
+		}
+		projectOutput.Metadata.Languages = languages
+	}
+	if config.StripImports {
+		projectOutput.Dependencies = buildDependencyInfo(processedFiles)
+	}
+	if config.Redact {
+		projectOutput.Redactions = buildRedactionInfo(processedFiles)
+	}
+
+	// The report is part of the output, so the rendered budget check counts it
+	if config.ExplainSelection {
+		projectOutput.Selection = finishSelection(ranked, processedFiles, excludedFileList)
+	}
+	if config.Hashes {
+		projectOutput.Integrity = format.Integrity(processedFiles)
+	}
+
+	// The selection costs each file alone, missing the sections files share
+	// (tree, dependencies, alignment); this pass over the full rendering is
+	// what keeps the output within the budget, trimming the tail if needed
+	if config.MaxTokens > 0 && config.Render != nil {
+		_, _, dropped, err := trimToRenderedBudget(projectOutput, config.MaxTokens, config, config.Render, ExcludeReasonTokenBudget)
+		if err != nil {
+			return nil, fmt.Errorf("error formatting output: %w", err)
+		}
+		if len(dropped) > 0 {
+			processedFiles = projectOutput.Files
+			excludedFileCount += len(dropped)
+			excludedFileList = append(excludedFileList, dropped...)
+			totalTokens = projectOutput.Budget.EstimatedTokens
+		}
+	}
 
 	// Get formatter for output
 	formatter, err := format.GetFormatter("markdown") // Default to markdown for token counting
 	if err != nil {
 		return nil, fmt.Errorf("error creating formatter: %w", err)
 	}
+	render := formatter.Format
+	if config.Render != nil {
+		render = config.Render
+	}
 
 	// Count tokens for directory tree
 	treeOutput, _ := formatter.Format(&format.ProjectOutput{DirectoryTree: projectOutput.DirectoryTree})
@@ -703,7 +1280,7 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 	log.Debug("Total processing time: %.2fms", float64(time.Since(log.GetPhaseStart()).Microseconds())/1000.0)
 
 	// Format the full output
-	formattedOutput, err := formatter.Format(projectOutput)
+	formattedOutput, err := render(projectOutput)
 	if err != nil {
 		return nil, fmt.Errorf("error formatting output: %w", err)
 	}
@@ -734,6 +1311,7 @@ func ProcessDirectory(config Config, verbose bool) (*ProcessResult, error) {
 		ProjectInfo:      projectInfo,
 		ExcludedFiles:    excludedFileCount,
 		ExcludedFileList: excludedFileList,
+		Warnings:         excludes.warnings(),
 	}, nil
 }
 
@@ -823,14 +1401,17 @@ func buildFileAnalysis(fileTypes map[string]int, totalSize int64, entryPoints []
 	var content strings.Builder
 
 	// Display File Distribution
+	types := make([]string, 0, len(fileTypes))
+	for typ := range fileTypes {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
 	content.WriteString("\n   Types: ")
-	first := true
-	for typ, count := range fileTypes {
-		if !first {
+	for i, typ := range types {
+		if i > 0 {
 			content.WriteString(" • ")
 		}
-		content.WriteString(fmt.Sprintf("%s: %d", typ, count))
-		first = false
+		content.WriteString(fmt.Sprintf("%s: %d", typ, fileTypes[typ]))
 	}
 	content.WriteString("\n")
 
@@ -859,7 +1440,11 @@ func buildDependenciesSection(result *ProcessResult) string {
 	var content strings.Builder
 	content.WriteString("\n📚 Dependencies\n")
 	for _, dep := range result.ProjectOutput.Metadata.Dependencies {
-		content.WriteString(fmt.Sprintf("   • %s\n", dep))
+		line := strings.TrimSpace(dep.Name + " " + dep.Version)
+		if dep.Scope != info.ScopeDirect {
+			line += " (" + dep.Scope + ")"
+		}
+		content.WriteString(fmt.Sprintf("   • %s\n", line))
 	}
 	return content.String()
 }
@@ -1052,282 +1637,129 @@ func FormatDryRunOutput(result *DryRunResult, config Config) string {
 		content.WriteString("   ⚠️  No files would be processed\n")
 	}
 
-	return formatBoxedOutput(content.String())
-}
-
-// Helper functions to reduce cyclomatic complexity
-
-func loadConfigurations(absPath string) (*config.FileConfig, *config.FileConfig) {
-	// Load global configuration
-	globalConfig, err := config.LoadGlobalConfig()
-	if err != nil {
-		log.Info("Warning: Failed to load global config: %v", err)
-		globalConfig = &config.FileConfig{}
-	}
-
-	// Load project config file from the specified directory
-	projectConfig, err := config.LoadConfig(absPath)
-	if err != nil {
-		log.Info("Warning: Failed to load .promptext.yml from %s: %v", absPath, err)
-		projectConfig = &config.FileConfig{}
-	}
-
-	return globalConfig, projectConfig
-}
-
-func handleDryRun(procConfig Config, outputFormat, outFile string, quiet bool) error {
-	dryRunResult, err := PreviewDirectory(procConfig)
-	if err != nil {
-		return fmt.Errorf("error during dry-run preview: %v", err)
-	}
-
-	// Update config summary with additional info
-	dryRunResult.ConfigSummary.Format = outputFormat
-	dryRunResult.ConfigSummary.OutputFile = outFile
-
-	// Display dry-run results
-	preview := FormatDryRunOutput(dryRunResult, procConfig)
-	if quiet {
-		fmt.Printf("files=%d tokens=%d\n", len(dryRunResult.FilePaths), dryRunResult.EstimatedTokens)
-	} else {
-		fmt.Printf("\033[32m%s\033[0m\n", preview)
-	}
-	return nil
-}
-
-func handleInfoOnly(procConfig Config, result *ProcessResult, infoOnly, quiet bool) (string, error) {
-	info, err := GetMetadataSummary(procConfig, result, infoOnly)
-	if err != nil {
-		return "", fmt.Errorf("error getting project info: %v", err)
-	}
-
-	if infoOnly {
-		if quiet {
-			fileCount := len(result.ProjectOutput.Files)
-			fmt.Printf("files=%d tokens=%d\n", fileCount, result.TokenCount)
-		} else {
-			fmt.Printf("\033[32m%s\033[0m\n", info)
-		}
-	}
-	return info, nil
-}
-
-func handleOutput(formattedOutput, outputFormat, outFile, info string, result *ProcessResult, noCopy, quiet bool) error {
-	// Build exclusion message if files were excluded
-	exclusionMsg := ""
-	if result.ExcludedFiles > 0 {
-		if quiet {
-			exclusionMsg = fmt.Sprintf(" excluded=%d", result.ExcludedFiles)
-		} else {
-			// Build detailed exclusion summary
-			var summary strings.Builder
-			summary.WriteString(fmt.Sprintf("\n⚠️  Excluded %d files due to token budget:\n", result.ExcludedFiles))
-
-			// Show first 5 excluded files with token counts
-			displayCount := 5
-			if len(result.ExcludedFileList) < displayCount {
-				displayCount = len(result.ExcludedFileList)
-			}
-
-			totalExcludedTokens := 0
-			for i := 0; i < displayCount; i++ {
-				excluded := result.ExcludedFileList[i]
-				summary.WriteString(fmt.Sprintf("    • %s (~%d tokens)\n", excluded.Path, excluded.Tokens))
-				totalExcludedTokens += excluded.Tokens
+	// Symlinks left out, so a missing directory isn't a mystery
+	if len(result.SkippedSymlinks) > 0 {
+		content.WriteString("\n🔗 Skipped Symlinks\n")
+		for i, link := range result.SkippedSymlinks {
+			if i >= maxDisplay {
+				content.WriteString(fmt.Sprintf("   ... and %d more symlinks\n", len(result.SkippedSymlinks)-maxDisplay))
+				break
 			}
-
-			// Add summary for remaining files
-			if len(result.ExcludedFileList) > displayCount {
-				remaining := len(result.ExcludedFileList) - displayCount
-				remainingTokens := 0
-				for i := displayCount; i < len(result.ExcludedFileList); i++ {
-					remainingTokens += result.ExcludedFileList[i].Tokens
-				}
-				totalExcludedTokens += remainingTokens
-				summary.WriteString(fmt.Sprintf("    ... and %d more files (~%d tokens)\n", remaining, remainingTokens))
-			}
-
-			summary.WriteString(fmt.Sprintf("    Total excluded: ~%d tokens", totalExcludedTokens))
-			exclusionMsg = summary.String()
+			content.WriteString(fmt.Sprintf("   • %s -> %s (%s)\n", link.Path, link.Target, link.Reason))
 		}
 	}
 
-	if outFile != "" {
-		if err := os.WriteFile(outFile, []byte(formattedOutput), 0644); err != nil {
-			return fmt.Errorf("error writing to output file: %w", err)
-		}
-		if quiet {
-			fmt.Printf("written=%s format=%s files=%d tokens=%d%s\n", outFile, outputFormat, len(result.ProjectOutput.Files), result.TokenCount, exclusionMsg)
-		} else {
-			fmt.Printf("\033[32m%s\n✓ code context written to %s (%s format)%s\033[0m\n", info, outFile, outputFormat, exclusionMsg)
-		}
-	} else if !noCopy {
-		if err := clipboard.WriteAll(formattedOutput); err != nil {
-			if !quiet {
-				log.Info("Warning: Failed to copy to clipboard: %v", err)
-			}
-			if quiet {
-				return fmt.Errorf("clipboard copy failed")
-			}
-		} else {
-			if quiet {
-				fmt.Printf("clipboard=ok format=%s files=%d tokens=%d%s\n", outputFormat, len(result.ProjectOutput.Files), result.TokenCount, exclusionMsg)
-			} else {
-				fmt.Printf("\033[32m%s\n✓ code context copied to clipboard (%s format)%s\033[0m\n", info, outputFormat, exclusionMsg)
-			}
-		}
-	}
-	return nil
+	return formatBoxedOutput(content.String())
 }
 
-// Run executes the promptext tool with the given configuration
-func Run(dirPath string, extension string, exclude string, noCopy bool, infoOnly bool, verbose bool, outputFormat string, outFile string, debug bool, gitignore bool, useDefaultRules bool, dryRun bool, quiet bool, relevanceKeywords string, maxTokens int, explainSelection bool) error {
-	// Enable debug logging if flag is set
-	if debug {
-		log.Enable()
-		log.SetColorEnabled(true)
-	}
-
-	// Set quiet mode
-	if quiet {
-		log.SetQuiet(true)
-	}
-
-	log.Debug("=== Promptext Initialization ===")
-	log.Debug("Directory: %s", dirPath)
-	// Handle "md" as alias for "markdown"
-	if outputFormat == "md" {
-		outputFormat = "markdown"
-	}
-
-	// Validate format
-	formatter, err := format.GetFormatter(outputFormat)
-	if err != nil {
-		return fmt.Errorf("invalid format (must be markdown or xml): %w", err)
-	}
-	// Convert dirPath to absolute path
-	absPath, err := filepath.Abs(dirPath)
-	if err != nil {
-		return fmt.Errorf("failed to get absolute path: %w", err)
-	}
-
-	// Load configurations
-	globalConfig, projectConfig := loadConfigurations(absPath)
-
-	// Merge global, project, and flag configurations with proper precedence
-	extensions, excludes, verboseFlag, _, useGitIgnore, useDefaultRules := config.MergeConfigs(globalConfig, projectConfig, extension, exclude, verbose, debug, &gitignore, &useDefaultRules)
-	log.Debug("Configuration:")
-	log.Debug("  • Extensions: %v", extensions)
-	log.Debug("  • Excludes: %#v", excludes)
-	log.Debug("  • Git Ignore: %v", useGitIgnore)
-
-	// Create filter options
-	filterOpts := filter.Options{
-		Includes:        extensions,
-		Excludes:        excludes,
-		UseDefaultRules: useDefaultRules,
-		UseGitIgnore:    useGitIgnore,
-	}
-
-	// Create the filter once and reuse it
-	f := filter.New(filterOpts)
-
-	// Create processor configuration with filter
-	procConfig := Config{
-		DirPath:           absPath,
-		Extensions:        extensions,
-		Excludes:          excludes,
-		GitIgnore:         useGitIgnore,
-		Filter:            f,
-		RelevanceKeywords: relevanceKeywords,
-		MaxTokens:         maxTokens,
-	}
-
-	// Handle dry-run mode
-	if dryRun {
-		return handleDryRun(procConfig, outputFormat, outFile, quiet)
-	}
-
-	// Process directory once and reuse results
-	result, err := ProcessDirectory(procConfig, verboseFlag)
-	if err != nil {
-		return fmt.Errorf("error processing directory: %v", err)
-	}
-
-	// Handle info-only mode
-	info, err := handleInfoOnly(procConfig, result, infoOnly, quiet)
-	if err != nil {
-		return err
-	}
-	if infoOnly {
-		return nil
-	}
-
-	// Format output for the selected format
-	formattedOutput, err := formatter.Format(result.ProjectOutput)
-	if err != nil {
-		return fmt.Errorf("error formatting output: %w", err)
-	}
-
-	// Handle output
-	return handleOutput(formattedOutput, outputFormat, outFile, info, result, noCopy, quiet)
-}
+// Helper functions to reduce cyclomatic complexity
 
-// Common entry point file patterns across languages
-var entryPointPatterns = map[string]bool{
-	// Go
-	"main.go": true,
-
-	// JavaScript/TypeScript
-	"index.js":   true,
-	"index.ts":   true,
-	"index.jsx":  true,
-	"index.tsx":  true,
-	"app.js":     true,
-	"app.ts":     true,
-	"app.jsx":    true,
-	"app.tsx":    true,
-	"server.js":  true,
-	"server.ts":  true,
-	"index.html": true,
-
-	// Python
-	"main.py":     true,
-	"app.py":      true,
-	"__init__.py": true,
-	"__main__.py": true,
-	"manage.py":   true,
-	"wsgi.py":     true,
-	"asgi.py":     true,
-
-	// Ruby
-	"application.rb": true,
-	"config.ru":      true,
-
-	// PHP
-	"index.php": true,
-	"app.php":   true,
-
-	// Java
-	"Main.java":        true,
-	"Application.java": true,
-
-	// Rust
-	"main.rs": true,
-	"lib.rs":  true,
-
-	// C/C++
-	"main.c":   true,
-	"main.cpp": true,
-	"main.cc":  true,
+// RunOptions holds the command-line settings the CLI extracts with
+type RunOptions struct {
+	DirPath           string
+	Extension         string // Comma-separated extensions to include
+	Exclude           string // Comma-separated exclude patterns
+	Include           string // Comma-separated path globs to include (e.g. internal/**,cmd/*/main.go)
+	NoCopy            bool
+	ClipboardBackend  string // auto (default), system, wl-copy, xclip, xsel, or osc52
+	ClipboardMaxBytes int    // Write output larger than this to a temp file instead of copying; 0 = no limit (CLI only)
+	InfoOnly          bool
+	TreeOnly          bool     // Output only the directory tree with per-directory token totals (CLI only)
+	Files             []string // Explicit paths to process instead of walking DirPath (nil = walk)
+	GitRef            string   // Read files as committed at this branch, tag, or commit instead of the working tree
+	Verbose           bool
+	OutputFormat      string
+	OutFile           string
+	Debug             bool
+	GitIgnore         bool
+	UseDefaultRules   bool
+	DryRun            bool
+	Quiet             bool
+	SummaryJSON       string // Write a JSON run summary to this file, or stdout for "-" (CLI only)
+	Stats             string // Print token usage by directory and extension in place of the output: "table" or "json" (CLI only)
+	FailOverTokens    int    // Fail with ExitOverTokens when the context exceeds this many tokens (0 = off)
+	FailIfEmpty       bool   // Fail with ExitEmpty when no files match
+	RelevanceKeywords string
+	ExactKeywords     bool // Match keywords as given, without stemming or synonyms
+	BoostRecent       bool // Rank files changed recently and often in git higher
+	MaxTokens         int
+	ExplainSelection  bool
+	NoCache           bool     // Disable the on-disk file cache
+	Redact            bool     // Replace detected secrets with placeholders
+	StripComments     bool     // Remove comments from source in known languages
+	SquashBlankLines  bool     // Collapse runs of blank lines into one
+	Compact           string   // Compaction mode: whitespace, indent, or "" for none
+	NotebookOutputs   bool     // Keep the text outputs of Jupyter notebook cells
+	SummarizeAssets   bool     // Replace lockfiles and minified bundles with summaries
+	SampleRows        int      // Rows of CSV, TSV and JSON Lines files to keep (0 = all)
+	Profile           string   // Named profile from .promptext.yml to apply on top of the config
+	Tokenizer         string   // Token counting backend (cl100k, o200k, claude, chars)
+	Model             string   // Target model; sets MaxTokens and Tokenizer when those are unset
+	ResponseReserve   int      // Tokens of the model's window kept for the response (0 = default)
+	ContentExcludes   []string // Regular expressions; files whose content matches are skipped
+	SkipGenerated     bool     // Skip generated and minified files detected by their content
+	SkippedFileStubs  bool     // List skipped binary, oversized and generated files as stubs
+	SplitTokens       int      // Token budget per part when splitting output across files (CLI only)
+	FollowImports     int      // Import hops to follow from highly relevant files (0 = off)
+	Snippets          bool     // Cut relevant files to the definitions matching a keyword
+	IncludeTests      string   // "paired" to keep the tests of relevant files
+	Owners            bool     // Attach CODEOWNERS owners to files
+	TodoScan          bool     // List TODO, FIXME and HACK comments in an issues section
+	LicenseScan       bool     // Report a license inventory and files with missing or conflicting headers
+	Metrics           bool     // Add a metrics section with line counts, function counts and complexity
+	AuditDeps         bool     // Query OSV for known vulnerabilities of the dependencies
+	AuditReport       string   // Read vulnerabilities from an osv-scanner JSON report instead (implies AuditDeps)
+	WorkspacePackage  string   // Extract only this workspace member and the members it depends on
+	Deterministic     bool     // Render identical files to identical output, leaving out git HEAD info
+	Hashes            bool     // Add file hashes and a content hash to the manifest, for verify
+	MarkdownTOC       bool     // Start markdown output with a linked table of contents (CLI only)
+	MarkdownCollapse  int      // Fold markdown files longer than this many lines into <details> (CLI only)
+	Template          string   // Go template file the template format renders (CLI only)
+	Gzip              bool     // Gzip the output written (CLI only)
+	Base64            bool     // Armor the output written in base64, after any gzip (CLI only)
+	SnippetContext    int      // Lines kept around each snippet
+	Concurrency       int      // Files processed in parallel (0 = one per CPU)
+	Progress          bool     // Draw a progress bar on stderr while files load (CLI only)
+	Explain           []string // Files to explain the selection of instead of extracting (CLI only)
+	Ask               string   // Question to answer with a language model instead of extracting (CLI only)
+	Plan              bool     // Suggest excludes that fit the token budget instead of extracting (CLI only)
+	Push              bool     // Upload the output to a files API instead of copying it (CLI only)
+	PushProvider      string   // Provider to push to; empty picks the first with an API key set (CLI only)
+	GHA               bool     // Run as a GitHub Actions step on the pull request's changed files (CLI only)
+	GHAComment        bool     // With GHA, post the summary as a pull request comment (CLI only)
+	PlanApply         bool     // Add the suggested excludes to .promptext.yml (CLI only)
+	CompareFrom       string   // Git ref or directory to compare from instead of extracting (CLI only)
+	CompareTo         string   // Git ref or directory to compare to; empty for the working tree (CLI only)
+	CompareDiffs      bool     // Include unified diffs in the comparison report (CLI only)
+
+	// Prompt template to wrap the output in, and its variables (CLI only)
+	Prompt     string
+	PromptVars map[string]string
+
+	// Shares of MaxTokens per directory or extension; see Config.BudgetWeights
+	BudgetWeights map[string]float64
+
+	// Files outside DirPath to include as well, relative to the working
+	// directory or absolute; see Config.ExtraFiles
+	ExtraFiles []string
+
+	// Symlinks is the symlink policy; see Config.Symlinks
+	Symlinks string
+
+	// Size caps in bytes; see Config.MaxFileSize and Config.MaxTotalSize
+	MaxFileSize  int64
+	MaxTotalSize int64
+
+	// Shell command each file's content is piped through; see CommandTransform
+	TransformCmd string
 }
 
-// detectEntryPoints identifies entry point files from the file list
-func detectEntryPoints(files []format.FileInfo) map[string]bool {
+// detectEntryPoints identifies entry point files from the file list, as the
+// filter's entry point globs name them
+func detectEntryPoints(files []format.FileInfo, f *filter.Filter) map[string]bool {
 	entryPoints := make(map[string]bool)
 	for _, file := range files {
-		basename := filepath.Base(file.Path)
-		if entryPointPatterns[basename] {
+		if f.IsEntryPoint(file.Path) {
 			entryPoints[file.Path] = true
 		}
 	}
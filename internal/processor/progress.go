@@ -0,0 +1,74 @@
+package processor
+
+import "sync"
+
+// Progress stages, in the order ProcessDirectory goes through them
+const (
+	StageScanning   = "scanning"   // Walking the directory for candidate files
+	StageProcessing = "processing" // Reading, filtering and tokenizing them
+	StageDone       = "done"       // All files are loaded; selection and formatting follow
+)
+
+// ProgressEvent reports how far ProcessDirectory has got (see Config.Progress)
+type ProgressEvent struct {
+	Stage          string
+	FilesScanned   int // Candidate files found by the walk so far
+	FilesProcessed int // Candidates read or skipped so far, out of FilesScanned
+	FilesIncluded  int // Processed files that passed the filters
+	Tokens         int // Tokens counted in the included files so far
+}
+
+// progress serializes the events of one run to Config.Progress, which the
+// load workers report to concurrently. A nil progress reports nothing.
+type progress struct {
+	mu    sync.Mutex
+	fn    func(ProgressEvent)
+	event ProgressEvent
+}
+
+// newProgress returns a reporter for fn, or nil when fn is nil
+func newProgress(fn func(ProgressEvent)) *progress {
+	if fn == nil {
+		return nil
+	}
+	return &progress{fn: fn, event: ProgressEvent{Stage: StageScanning}}
+}
+
+// scanned reports one more candidate file found by the walk
+func (p *progress) scanned() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.event.FilesScanned++
+	p.fn(p.event)
+}
+
+// processed reports one more loaded candidate, with the tokens of the file
+// when it was included
+func (p *progress) processed(file *loadedFile) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.event.Stage = StageProcessing
+	p.event.FilesProcessed++
+	if file.file != nil {
+		p.event.FilesIncluded++
+		p.event.Tokens += file.file.Tokens
+	}
+	p.fn(p.event)
+}
+
+// done reports that every candidate has been loaded
+func (p *progress) done() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.event.Stage = StageDone
+	p.fn(p.event)
+}
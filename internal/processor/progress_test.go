@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryProgress(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("pkg/file%02d.go", i)] = fmt.Sprintf("package pkg\n\n// file %d\n", i)
+	}
+	files["assets/logo.png"] = "\x89PNG\r\n\x1a\n\x00\x00"
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	var events []ProgressEvent
+	config := Config{
+		DirPath:     tmpDir,
+		Filter:      filter.New(filter.Options{UseDefaultRules: true}),
+		Concurrency: 4,
+		Progress:    func(event ProgressEvent) { events = append(events, event) },
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	// 21 files scanned, 21 processed, then done
+	require.Len(t, events, 43)
+	assert.Equal(t, ProgressEvent{Stage: StageScanning, FilesScanned: 1}, events[0])
+	assert.Equal(t, StageProcessing, events[21].Stage)
+	processed := 0
+	for _, event := range events[21:42] {
+		assert.Equal(t, 21, event.FilesScanned)
+		assert.Greater(t, event.FilesProcessed, processed, "processed count only grows")
+		processed = event.FilesProcessed
+	}
+
+	total := 0
+	for _, file := range result.ProjectOutput.Files {
+		total += file.Tokens
+	}
+	assert.Equal(t, ProgressEvent{Stage: StageDone, FilesScanned: 21, FilesProcessed: 21, FilesIncluded: 20, Tokens: total}, events[42])
+}
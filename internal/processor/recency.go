@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"math"
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/internal/info"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/relevance"
+)
+
+// Recency boost tuning
+const (
+	recencyCommits  = 300 // Commits of history read for the boost
+	recencyWeight   = 3.0 // Boost of a file changed in the newest commit
+	recencyHalfLife = 7.0 // Days after which the recency part of the boost halves
+	churnWeight     = 2.0 // Boost of the file changed most often
+)
+
+// boostedScorer adds a boost for recently and frequently changed files to
+// the scores of base. With onlyRelevant it boosts only files base already
+// scores above 0, so keyword matches are reordered but no file is added.
+type boostedScorer struct {
+	base         relevance.FileScorer
+	boosts       map[string]float64
+	onlyRelevant bool
+}
+
+// withRecencyBoost wraps scorer with boosts computed from the git history of
+// config.DirPath. Without git, or when the files come from an fs.FS that
+// isn't a git ref, scorer is returned unchanged.
+func withRecencyBoost(scorer relevance.FileScorer, config Config, onlyRelevant bool) relevance.FileScorer {
+	if config.FS != nil && config.GitRef == "" {
+		log.Debug("Skipping recency boost: files don't come from git")
+		return scorer
+	}
+	activity, err := info.GetFileActivity(config.DirPath, config.GitRef, recencyCommits)
+	if err != nil {
+		log.Debug("Skipping recency boost: %v", err)
+		return scorer
+	}
+	boosts := recencyBoosts(activity)
+	if len(boosts) == 0 {
+		return scorer
+	}
+	log.Debug("Recency boost: %d files changed in the last %d commits", len(boosts), recencyCommits)
+	return boostedScorer{base: scorer, boosts: boosts, onlyRelevant: onlyRelevant}
+}
+
+// recencyBoosts scores each file of activity by how recently it changed,
+// halving every recencyHalfLife days before the newest commit, plus how often
+// it changed compared to the file changed most. Ages are measured from the
+// newest commit rather than now, so the same history always boosts the same.
+// Keys are OS-specific paths, as used by FileInfo.
+func recencyBoosts(activity map[string]info.FileActivity) map[string]float64 {
+	var newest int64
+	maxCommits := 0
+	for _, a := range activity {
+		newest = max(newest, a.LastChange)
+		maxCommits = max(maxCommits, a.Commits)
+	}
+
+	boosts := make(map[string]float64, len(activity))
+	for path, a := range activity {
+		ageDays := float64(newest-a.LastChange) / (24 * 60 * 60)
+		boost := recencyWeight*math.Pow(0.5, ageDays/recencyHalfLife) +
+			churnWeight*float64(a.Commits)/float64(maxCommits)
+		boosts[filepath.FromSlash(path)] = math.Round(boost*100) / 100
+	}
+	return boosts
+}
+
+// ScoreFile implements relevance.FileScorer
+func (s boostedScorer) ScoreFile(path, content string) float64 {
+	score := s.base.ScoreFile(path, content)
+	return score + s.boost(path, score)
+}
+
+// boost returns the boost ScoreFile adds to a file scoring score
+func (s boostedScorer) boost(path string, score float64) float64 {
+	if s.onlyRelevant && score <= 0 {
+		return 0
+	}
+	return s.boosts[path]
+}
@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/info"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecencyBoosts(t *testing.T) {
+	const day = 24 * 60 * 60
+	boosts := recencyBoosts(map[string]info.FileActivity{
+		"new.go":     {Commits: 4, LastChange: 100 * day},
+		"week.go":    {Commits: 2, LastChange: 93 * day},
+		"old/old.go": {Commits: 1, LastChange: 0},
+	})
+	assert.Equal(t, recencyWeight+churnWeight, boosts["new.go"])
+	assert.Equal(t, recencyWeight/2+churnWeight/2, boosts["week.go"])
+	assert.InDelta(t, churnWeight/4, boosts["old/old.go"], 0.01)
+}
+
+func TestProcessDirectoryBoostRecent(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	files := map[string]string{
+		"auth/a.go":       "package auth\n",
+		"auth/b.go":       "package auth\n",
+		"utils/common.go": "package utils\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:           tmpDir,
+		Filter:            filter.New(filter.Options{UseDefaultRules: true}),
+		RelevanceKeywords: "auth",
+		BoostRecent:       true,
+		ExplainSelection:  true,
+	}
+	paths := func() []string {
+		result, err := ProcessDirectory(config, false)
+		require.NoError(t, err)
+		var paths []string
+		for _, file := range result.ProjectOutput.Files {
+			paths = append(paths, file.Path)
+		}
+		return paths
+	}
+
+	// Not a git repository: scored as without the boost
+	assert.Equal(t, []string{"auth/a.go", "auth/b.go"}, paths())
+
+	git := func(date string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("2024-01-01T00:00:00Z", "init", "-q")
+	git("2024-01-01T00:00:00Z", "add", "-A")
+	git("2024-01-01T00:00:00Z", "commit", "-q", "-m", "initial")
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "auth", "b.go"), []byte("package auth\n\n// changed\n"), 0644))
+	git("2024-02-01T00:00:00Z", "commit", "-q", "-am", "change b")
+
+	// The recently changed file ranks first; the unrelated one isn't pulled in
+	assert.Equal(t, []string{"auth/b.go", "auth/a.go"}, paths())
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	require.NotNil(t, result.ProjectOutput.Selection)
+	scores := make(map[string]float64)
+	for _, entry := range result.ProjectOutput.Selection.Files {
+		scores[entry.Path] = entry.Score
+		if entry.Path == "utils/common.go" {
+			assert.NotContains(t, entry.Traits, "recent")
+		} else {
+			assert.Contains(t, entry.Traits, "recent", entry.Path)
+		}
+	}
+	assert.Greater(t, scores["auth/b.go"], scores["auth/a.go"])
+
+	config.BoostRecent = false
+	assert.Equal(t, []string{"auth/a.go", "auth/b.go"}, paths())
+}
@@ -0,0 +1,21 @@
+package processor
+
+import "github.com/1broseidon/promptext/internal/format"
+
+// buildRedactionInfo totals the secrets replaced in the included files. It
+// always returns a summary when redaction is on, so a clean run reports zero
+// rather than omitting the section.
+func buildRedactionInfo(files []format.FileInfo) *format.RedactionInfo {
+	info := &format.RedactionInfo{Rules: make(map[string]int)}
+	for _, file := range files {
+		if len(file.Redactions) == 0 {
+			continue
+		}
+		info.Files++
+		for rule, n := range file.Redactions {
+			info.Rules[rule] += n
+			info.Total += n
+		}
+	}
+	return info
+}
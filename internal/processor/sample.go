@@ -0,0 +1,110 @@
+package processor
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// sampleDataFile cuts a CSV, TSV or JSON Lines file down to its header and
+// first rows records, keeping the original text of what it keeps. Files of
+// other types, files with no more than rows records, and CSV that doesn't
+// parse are returned unchanged with a nil SampleInfo.
+func sampleDataFile(relPath, content string, rows int) (string, *format.SampleInfo) {
+	switch strings.ToLower(filepath.Ext(relPath)) {
+	case ".csv":
+		return sampleDelimited(content, ',', rows)
+	case ".tsv":
+		return sampleDelimited(content, '\t', rows)
+	case ".jsonl", ".ndjson":
+		return sampleJSONLines(content, rows)
+	}
+	return content, nil
+}
+
+// sampleDelimited samples delimited records, the first being the header.
+// Quoted fields may span lines.
+func sampleDelimited(content string, comma rune, rows int) (string, *format.SampleInfo) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.Comma = comma
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return content, nil
+	}
+	sample := &format.SampleInfo{Rows: rows, Columns: len(header)}
+	cut := 0
+	for {
+		if _, err := reader.Read(); err == io.EOF {
+			break
+		} else if err != nil {
+			return content, nil
+		}
+		sample.TotalRows++
+		if sample.TotalRows == rows {
+			cut = int(reader.InputOffset())
+		}
+	}
+	if sample.TotalRows <= rows {
+		return content, nil
+	}
+	return content[:cut], sample
+}
+
+// sampleJSONLines samples one JSON value per line. Columns counts the keys
+// of the first value when it is an object.
+func sampleJSONLines(content string, rows int) (string, *format.SampleInfo) {
+	sample := &format.SampleInfo{Rows: rows}
+	cut := 0
+	offset := 0
+	for offset < len(content) {
+		end := strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content) - offset
+		} else {
+			end++ // Keep the newline with its line
+		}
+		line := strings.TrimSpace(content[offset : offset+end])
+		offset += end
+		if line == "" {
+			continue
+		}
+		if sample.TotalRows == 0 {
+			var first map[string]json.RawMessage
+			if json.Unmarshal([]byte(line), &first) == nil {
+				sample.Columns = len(first)
+			}
+		}
+		sample.TotalRows++
+		if sample.TotalRows == rows {
+			cut = offset
+		}
+	}
+	if sample.TotalRows <= rows {
+		return content, nil
+	}
+	return content[:cut], sample
+}
+
+// sampleToCache packs sample for a cache entry
+func sampleToCache(sample *format.SampleInfo) []int {
+	if sample == nil {
+		return nil
+	}
+	return []int{sample.Rows, sample.TotalRows, sample.Columns}
+}
+
+// sampleFromCache unpacks the sample of a cache entry
+func sampleFromCache(packed []int) *format.SampleInfo {
+	if len(packed) != 3 {
+		return nil
+	}
+	return &format.SampleInfo{Rows: packed[0], TotalRows: packed[1], Columns: packed[2]}
+}
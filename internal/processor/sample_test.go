@@ -0,0 +1,59 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleDataFile(t *testing.T) {
+	csv := "id,name,note\n1,a,\"two\nlines\"\n2,b,x\n3,c,y\n"
+	content, sample := sampleDataFile("data/users.csv", csv, 1)
+	assert.Equal(t, "id,name,note\n1,a,\"two\nlines\"\n", content)
+	assert.Equal(t, &format.SampleInfo{Rows: 1, TotalRows: 3, Columns: 3}, sample)
+
+	tsv := "a\tb\n1\t2\n3\t4\n5\t6"
+	content, sample = sampleDataFile("DATA.TSV", tsv, 2)
+	assert.Equal(t, "a\tb\n1\t2\n3\t4\n", content)
+	assert.Equal(t, &format.SampleInfo{Rows: 2, TotalRows: 3, Columns: 2}, sample)
+
+	jsonl := "{\"id\":1,\"ok\":true}\n\n{\"id\":2}\n{\"id\":3}\n"
+	content, sample = sampleDataFile("events.jsonl", jsonl, 1)
+	assert.Equal(t, "{\"id\":1,\"ok\":true}\n", content)
+	assert.Equal(t, &format.SampleInfo{Rows: 1, TotalRows: 3, Columns: 2}, sample)
+
+	// Kept whole: under the limit, not a data file, and an unterminated
+	// quote that swallows the rest of the file into one record
+	for path, content := range map[string]string{
+		"small.csv":  "a,b\n1,2\n",
+		"events.log": "1\n2\n3\n",
+		"bad.csv":    "a,b\n1,2,\"x\ny\n3,4\n",
+	} {
+		got, sample := sampleDataFile(path, content, 1)
+		assert.Nil(t, sample, path)
+		assert.Equal(t, content, got, path)
+	}
+}
+
+func TestProcessDirectorySamplesDataFiles(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"rows.csv": "x,y\n1,2\n3,4\n5,6\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:    tmpDir,
+		Filter:     filter.New(filter.Options{Includes: []string{".csv"}}),
+		SampleRows: 2,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	require.Len(t, result.ProjectOutput.Files, 1)
+	file := result.ProjectOutput.Files[0]
+	assert.Equal(t, "x,y\n1,2\n3,4\n", file.Content)
+	assert.Equal(t, &format.SampleInfo{Rows: 2, TotalRows: 3, Columns: 2}, file.Sample)
+}
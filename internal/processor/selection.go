@@ -0,0 +1,102 @@
+package processor
+
+import (
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/info"
+	"github.com/1broseidon/promptext/internal/relevance"
+)
+
+// rankSelection records each candidate file's score, score factors and
+// ranking traits, in the order given (the priority order when files were
+// prioritized). Factors are only known for keyword scoring; a custom scorer's
+// files carry just the score. Adjustments by FileRules are part of the score
+// but of no factor; negative keyword mentions are taken off the content factor.
+func rankSelection(files []format.FileInfo, scorer relevance.FileScorer, entryPoints map[string]bool, coreDirs []string, followed, paired map[string]bool) []format.SelectionEntry {
+	boosted, _ := scorer.(boostedScorer)
+	scorer = baseScorer(scorer)
+	keywords, explained := scorer.(*relevance.Scorer)
+
+	entries := make([]format.SelectionEntry, len(files))
+	for i, file := range files {
+		entry := format.SelectionEntry{Path: file.Path, Tokens: file.Tokens}
+		if explained {
+			b := keywords.Explain(file.Path, file.Content)
+			entry.Score = max(b.Total()+file.ScoreAdjustment, 0)
+			entry.Filename, entry.Directory, entry.Imports, entry.Content = b.Filename, b.Directory, b.Imports, b.Content+b.Penalty
+		} else {
+			entry.Score = max(scorer.ScoreFile(file.Path, file.Content)+file.ScoreAdjustment, 0)
+		}
+		recent := boosted.boost(file.Path, entry.Score)
+		entry.Score += recent
+
+		for _, trait := range []struct {
+			name string
+			set  bool
+		}{
+			{"entry", entryPoints[file.Path]},
+			{"config", isConfigPath(file.Path)},
+			{"core", info.IsCoreFile(file.Path, coreDirs)},
+			{"test", isTestPath(file.Path)},
+			{"imported", followed[file.Path]},
+			{"paired", paired[file.Path]},
+			{"recent", recent > 0},
+		} {
+			if trait.set {
+				entry.Traits = append(entry.Traits, trait.name)
+			}
+		}
+		entries[i] = entry
+	}
+	return entries
+}
+
+// finishSelection builds the selection report from the ranked candidates,
+// marking those that made it into the output. Files excluded before ranking
+// (size outliers) are appended with their reason.
+func finishSelection(ranked []format.SelectionEntry, included []format.FileInfo, excluded []ExcludedFileInfo) *format.SelectionReport {
+	kept := make(map[string]bool, len(included))
+	for _, file := range included {
+		kept[file.Path] = true
+	}
+	reasons := make(map[string]string, len(excluded))
+	for _, file := range excluded {
+		reasons[file.Path] = file.Reason
+	}
+
+	report := &format.SelectionReport{
+		Threshold: relevance.GetRelevanceThreshold(),
+		Files:     make([]format.SelectionEntry, 0, len(ranked)),
+	}
+	seen := make(map[string]bool, len(ranked))
+	for _, entry := range ranked {
+		seen[entry.Path] = true
+		entry.Included = kept[entry.Path]
+		if !entry.Included {
+			entry.Reason = reasons[entry.Path]
+		}
+		report.Files = append(report.Files, entry)
+	}
+	for _, file := range excluded {
+		if !seen[file.Path] {
+			report.Files = append(report.Files, format.SelectionEntry{Path: file.Path, Tokens: file.Tokens, Reason: file.Reason})
+		}
+	}
+	return report
+}
+
+// markSelectionExcluded records files dropped after the report was built
+func markSelectionExcluded(report *format.SelectionReport, dropped []ExcludedFileInfo) {
+	if report == nil || len(dropped) == 0 {
+		return
+	}
+	reasons := make(map[string]string, len(dropped))
+	for _, file := range dropped {
+		reasons[file.Path] = file.Reason
+	}
+	for i := range report.Files {
+		if reason, ok := reasons[report.Files[i].Path]; ok {
+			report.Files[i].Included = false
+			report.Files[i].Reason = reason
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryExplainSelection(t *testing.T) {
+	files := map[string]string{
+		"auth/login.go":   "package auth\n// login\n",
+		"auth/session.go": "package auth\n// session store\n",
+		"utils/common.go": "package utils\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:           tmpDir,
+		Filter:            filter.New(filter.Options{UseDefaultRules: true}),
+		RelevanceKeywords: "login",
+		ExplainSelection:  true,
+	}
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	report := result.ProjectOutput.Selection
+	require.NotNil(t, report)
+	assert.Equal(t, 5.0, report.Threshold)
+	require.Len(t, report.Files, 3)
+
+	first := report.Files[0]
+	assert.Equal(t, "auth/login.go", first.Path)
+	assert.True(t, first.Included)
+	assert.Equal(t, 10.0, first.Filename)
+	assert.Equal(t, 1.0, first.Content)
+	assert.Equal(t, first.Filename+first.Content, first.Score)
+
+	for _, entry := range report.Files[1:] {
+		assert.False(t, entry.Included, entry.Path)
+		assert.Equal(t, ExcludeReasonRelevance, entry.Reason, entry.Path)
+	}
+}
+
+func TestProcessDirectoryWithoutExplainSelection(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{"main.go": "package main\n"})
+	defer os.RemoveAll(tmpDir)
+
+	result, err := ProcessDirectory(Config{DirPath: tmpDir, Filter: filter.New(filter.Options{UseDefaultRules: true})}, false)
+	require.NoError(t, err)
+	assert.Nil(t, result.ProjectOutput.Selection)
+}
+
+func TestProcessDirectoryNegativeKeywords(t *testing.T) {
+	files := map[string]string{
+		"auth/login.go":      "package auth\n// auth\n",
+		"auth/login_test.go": "package auth\n// auth\n",
+		"auth/mock_store.go": "package auth\n// auth\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:           tmpDir,
+		Filter:            filter.New(filter.Options{UseDefaultRules: true}),
+		RelevanceKeywords: "auth -test -mock",
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	require.Len(t, result.ProjectOutput.Files, 1)
+	assert.Equal(t, "auth/login.go", result.ProjectOutput.Files[0].Path)
+	reasons := make(map[string]string)
+	for _, excluded := range result.ExcludedFileList {
+		reasons[excluded.Path] = excluded.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"auth/login_test.go": ExcludeReasonNegative,
+		"auth/mock_store.go": ExcludeReasonNegative,
+	}, reasons)
+}
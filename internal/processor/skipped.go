@@ -0,0 +1,113 @@
+package processor
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// Reasons reported on skipped-file stubs
+const (
+	SkipReasonBinary    = "binary"
+	SkipReasonTooLarge  = "too-large"
+	SkipReasonGenerated = "generated"
+)
+
+// maxTextFileSize matches the size above which BinaryRule treats any file as binary
+const maxTextFileSize = 10 * 1024 * 1024
+
+// skippedStubFor returns the stub of a file the walk skipped when stubs are
+// being collected and the file qualifies, or nil
+func skippedStubFor(path, relPath string, config Config) *format.SkippedFile {
+	if !config.SkippedFileStubs {
+		return nil
+	}
+	return skippedStub(path, relPath, config)
+}
+
+// skippedStub describes a skipped file worth mentioning: one the user's own
+// selection includes but that was dropped as binary, oversized, or
+// generated. Files skipped for other reasons return nil.
+func skippedStub(path, relPath string, config Config) *format.SkippedFile {
+	if !config.Filter.Selects(relPath) || filepath.Base(relPath) == ".DS_Store" {
+		return nil
+	}
+	stat, err := statFile(config, path)
+	if err != nil || !stat.Mode().IsRegular() {
+		return nil
+	}
+
+	stub := &format.SkippedFile{Path: relPath, Size: stat.Size(), Kind: rules.FileKind(relPath)}
+	switch {
+	case stat.Size() > maxFileSize(config):
+		stub.Reason = SkipReasonTooLarge
+	case isBinaryFile(config, path):
+		stub.Reason = SkipReasonBinary
+	default:
+		content, err := readFile(config, path)
+		if err != nil || config.Filter.ContentReason(relPath, string(content)) != SkipReasonGenerated {
+			return nil
+		}
+		stub.Reason = SkipReasonGenerated
+	}
+
+	if stub.Kind == "" {
+		stub.Kind = "text"
+		if stub.Reason == SkipReasonBinary {
+			stub.Kind = "binary"
+		}
+	}
+	return stub
+}
+
+// withStubs returns a copy of the directory tree with the skipped files
+// added, creating directories as needed and keeping siblings in name order.
+// The tree itself may be shared through a TreeCache, so it isn't modified.
+func withStubs(tree *format.DirectoryNode, stubs []format.SkippedFile) *format.DirectoryNode {
+	if tree == nil || len(stubs) == 0 {
+		return tree
+	}
+	root := cloneTree(tree)
+	for _, stub := range stubs {
+		node := root
+		parts := strings.Split(filepath.ToSlash(stub.Path), "/")
+		for i, part := range parts {
+			nodeType := "dir"
+			if i == len(parts)-1 {
+				nodeType = "file"
+			}
+			node = childNode(node, part, nodeType)
+		}
+	}
+	return root
+}
+
+// cloneTree deep-copies a directory tree
+func cloneTree(node *format.DirectoryNode) *format.DirectoryNode {
+	clone := &format.DirectoryNode{Name: node.Name, Type: node.Type}
+	for _, child := range node.Children {
+		clone.Children = append(clone.Children, cloneTree(child))
+	}
+	return clone
+}
+
+// childNode returns the child of parent with the given name, inserting it in
+// sorted position if it doesn't exist
+func childNode(parent *format.DirectoryNode, name, nodeType string) *format.DirectoryNode {
+	i := sort.Search(len(parent.Children), func(i int) bool {
+		return parent.Children[i].Name >= name
+	})
+	for j := i; j < len(parent.Children) && parent.Children[j].Name == name; j++ {
+		if parent.Children[j].Type == nodeType {
+			return parent.Children[j]
+		}
+	}
+	child := &format.DirectoryNode{Name: name, Type: nodeType}
+	parent.Children = append(parent.Children, nil)
+	copy(parent.Children[i+1:], parent.Children[i:])
+	parent.Children[i] = child
+	return child
+}
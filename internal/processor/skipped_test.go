@@ -0,0 +1,77 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectorySkippedFileStubs(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"main.go":            []byte("package main\n\nfunc main() {}\n"),
+		"assets/logo.png":    {0x89, 'P', 'N', 'G', 0, 0, 0, 0},
+		"api/api.pb.go":      []byte("// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n"),
+		"debug.log":          []byte("lines\n"),
+		"ignored/photo.jpeg": {0xff, 0xd8, 0xff, 0},
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, content, 0644))
+	}
+
+	config := Config{
+		DirPath: dir,
+		Filter: filter.New(filter.Options{
+			Excludes:        []string{"ignored/"},
+			UseDefaultRules: true,
+			SkipGenerated:   true,
+		}),
+		SkippedFileStubs: true,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	output := result.ProjectOutput
+	require.Len(t, output.Files, 1)
+	assert.Equal(t, []format.SkippedFile{
+		{Path: filepath.Join("api", "api.pb.go"), Size: int64(len(files["api/api.pb.go"])), Kind: "text", Reason: SkipReasonGenerated},
+		{Path: filepath.Join("assets", "logo.png"), Size: 8, Kind: "image", Reason: SkipReasonBinary},
+	}, output.SkippedFiles, "log files and user-excluded paths get no stubs")
+
+	var assets *format.DirectoryNode
+	for _, child := range output.DirectoryTree.Children {
+		if child.Name == "assets" {
+			assets = child
+		}
+	}
+	require.NotNil(t, assets, "stubbed files appear in the directory tree")
+	require.Len(t, assets.Children, 1)
+	assert.Equal(t, "logo.png", assets.Children[0].Name)
+
+	// Without the option nothing is collected
+	config.SkippedFileStubs = false
+	result, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.ProjectOutput.SkippedFiles)
+}
+
+func TestWithStubsLeavesTreeUnchanged(t *testing.T) {
+	tree := &format.DirectoryNode{Name: "root", Type: "dir", Children: []*format.DirectoryNode{
+		{Name: "b.go", Type: "file"},
+	}}
+	got := withStubs(tree, []format.SkippedFile{{Path: "a.png"}, {Path: filepath.Join("img", "c.gif")}})
+
+	require.Len(t, got.Children, 3)
+	assert.Equal(t, "a.png", got.Children[0].Name)
+	assert.Equal(t, "b.go", got.Children[1].Name)
+	assert.Equal(t, "img", got.Children[2].Name)
+	assert.Equal(t, "dir", got.Children[2].Type)
+	assert.Len(t, tree.Children, 1, "the original tree may be shared and must not change")
+}
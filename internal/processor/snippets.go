@@ -0,0 +1,308 @@
+package processor
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	gotoken "go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// symbol is a top-level or nested definition found in a source file, as a
+// range of 0-based lines including its doc comment
+type symbol struct {
+	name       string
+	start, end int
+}
+
+// braceDefinition matches the first line of a definition in brace languages
+// (JS/TS, Rust, Java, Kotlin, C#, PHP, Swift, C/C++), capturing its name
+var braceDefinition = []*regexp.Regexp{
+	regexp.MustCompile(`^\s*(?:[\w@]+\s+)*?(?:function\s*\*?|fn|fun|class|interface|struct|enum|trait|impl|object|record|type|namespace)\s+(\w+)`),
+	regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*=\s*(?:async\s+)?(?:function\b|\([^)]*\)[^=]*=>|\w+\s*=>)`),
+	regexp.MustCompile(`^\s*(?:[\w<>\[\],.*&?:]+\s+)*?(\w+)\s*\([^;]*$`),
+}
+
+// pythonDefinition matches a Python def or class, capturing its indentation
+// and name
+var pythonDefinition = regexp.MustCompile(`^(\s*)(?:async\s+)?(?:def|class)\s+(\w+)`)
+
+// notSymbols are words the C-style function pattern would take for a name
+var notSymbols = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"foreach": true, "using": true, "lock": true, "match": true, "when": true, "else": true,
+	"new": true, "throw": true, "sizeof": true, "typeof": true, "await": true, "yield": true,
+}
+
+// braceExtensions are the languages whose definitions findSymbols finds by
+// matching braces
+var braceExtensions = map[string]bool{
+	".js": true, ".jsx": true, ".mjs": true, ".cjs": true, ".ts": true, ".tsx": true,
+	".rs": true, ".java": true, ".kt": true, ".kts": true, ".scala": true, ".cs": true,
+	".php": true, ".swift": true, ".dart": true, ".c": true, ".h": true, ".cc": true,
+	".cpp": true, ".hpp": true,
+}
+
+// findSymbols returns the definitions in content, or nil for languages it
+// doesn't know. Go is parsed; other languages are read line by line, ending
+// definitions where their braces balance or, in Python, where the
+// indentation returns to that of the definition.
+func findSymbols(path, content string) []symbol {
+	ext := strings.ToLower(filepath.Ext(path))
+	switch {
+	case ext == ".go":
+		return goSymbols(path, content)
+	case ext == ".py" || ext == ".pyi":
+		return pythonSymbols(strings.Split(content, "\n"))
+	case braceExtensions[ext]:
+		return braceSymbols(strings.Split(content, "\n"))
+	}
+	return nil
+}
+
+// goSymbols returns the functions, methods, types, constants and variables
+// of a Go file that parses
+func goSymbols(path, content string) []symbol {
+	fset := gotoken.NewFileSet()
+	parsed, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return nil
+	}
+	var symbols []symbol
+	add := func(name string, doc *ast.CommentGroup, node ast.Node) {
+		start := node.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		symbols = append(symbols, symbol{
+			name:  name,
+			start: fset.Position(start).Line - 1,
+			end:   fset.Position(node.End()).Line - 1,
+		})
+	}
+	for _, decl := range parsed.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			add(decl.Name.Name, decl.Doc, decl)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				// A lone spec takes the declaration's doc comment and keyword
+				var node ast.Node = spec
+				doc := decl.Doc
+				if !decl.Lparen.IsValid() {
+					node = decl
+				}
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Doc != nil {
+						doc = spec.Doc
+					}
+					add(spec.Name.Name, doc, node)
+				case *ast.ValueSpec:
+					if spec.Doc != nil {
+						doc = spec.Doc
+					}
+					for _, name := range spec.Names {
+						add(name.Name, doc, node)
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// braceSymbols finds definitions whose body is a brace block. A definition
+// without a body on its line or the next, such as a declaration ending in
+// ";", is left out.
+func braceSymbols(lines []string) []symbol {
+	var symbols []symbol
+	for n, line := range lines {
+		name := ""
+		for _, pattern := range braceDefinition {
+			if m := pattern.FindStringSubmatch(line); m != nil && !notSymbols[m[1]] {
+				name = m[1]
+				break
+			}
+		}
+		if name == "" {
+			continue
+		}
+		if end := braceEnd(lines, n); end >= 0 {
+			symbols = append(symbols, symbol{name: name, start: docStart(lines, n, "//", "/*", "*", "@", "#["), end: end})
+		}
+	}
+	return symbols
+}
+
+// braceEnd returns the line where the brace block opened on line start, or
+// the line after it, closes, or -1 when no block opens there. Braces in
+// strings and line comments aren't counted.
+func braceEnd(lines []string, start int) int {
+	depth, opened := 0, false
+	for n := start; n < len(lines); n++ {
+		if !opened && n > start+1 {
+			return -1
+		}
+		var quote byte
+		line := lines[n]
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case quote != 0:
+				if c == '\\' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'' || c == '`':
+				quote = c
+			case c == '/' && strings.HasPrefix(line[i:], "//"):
+				i = len(line)
+			case c == ';' && !opened:
+				return -1
+			case c == '{':
+				depth++
+				opened = true
+			case c == '}':
+				depth--
+				if opened && depth == 0 {
+					return n
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// pythonSymbols finds def and class blocks, which end before the next
+// non-blank line indented no deeper than the definition
+func pythonSymbols(lines []string) []symbol {
+	var symbols []symbol
+	for n, line := range lines {
+		m := pythonDefinition.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		end := n
+		for next := n + 1; next < len(lines); next++ {
+			trimmed := strings.TrimSpace(lines[next])
+			if trimmed == "" {
+				continue
+			}
+			if indent := len(lines[next]) - len(strings.TrimLeft(lines[next], " \t")); indent <= len(m[1]) {
+				break
+			}
+			end = next
+		}
+		symbols = append(symbols, symbol{name: m[2], start: docStart(lines, n, "#", "@"), end: end})
+	}
+	return symbols
+}
+
+// docStart extends a definition on line n up over the comment, attribute and
+// decorator lines directly above it
+func docStart(lines []string, n int, prefixes ...string) int {
+	for n > 0 {
+		trimmed := strings.TrimSpace(lines[n-1])
+		found := false
+		for _, prefix := range prefixes {
+			found = found || (trimmed != "" && strings.HasPrefix(trimmed, prefix))
+		}
+		if !found {
+			break
+		}
+		n--
+	}
+	return n
+}
+
+// extractSnippets cuts file down to the definitions whose name contains one
+// of keywords, each with contextLines lines around it, marking the cuts like
+// truncation does and recording the names in file.Truncation. Files with no
+// matching definition, in unsupported languages, or already truncated or
+// sampled are left whole. It reports whether the file was cut.
+func extractSnippets(file *format.FileInfo, keywords []string, contextLines int, tokenCounter *token.TokenCounter) bool {
+	if len(keywords) == 0 || file.Truncation != nil || file.Sample != nil {
+		return false
+	}
+
+	contextLines = max(contextLines, 0)
+	lines := strings.Split(strings.TrimSuffix(file.Content, "\n"), "\n")
+	var names []string
+	var ranges [][2]int
+	for _, sym := range findSymbols(file.Path, file.Content) {
+		lower := strings.ToLower(sym.name)
+		for _, keyword := range keywords {
+			if strings.Contains(lower, keyword) {
+				names = append(names, sym.name)
+				ranges = append(ranges, [2]int{max(sym.start-contextLines, 0), min(sym.end+contextLines, len(lines)-1)})
+				break
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return false
+	}
+
+	// Merge overlapping ranges, so nested matches such as a class and its
+	// method are kept once. Gaps of up to minOmitted-1 lines are kept too,
+	// as the marker would take about as much room.
+	const minOmitted = 3
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		if last := &merged[len(merged)-1]; r[0] < last[1]+minOmitted {
+			last[1] = max(last[1], r[1])
+		} else {
+			merged = append(merged, r)
+		}
+	}
+	if merged[0][0] < minOmitted {
+		merged[0][0] = 0
+	}
+	if last := &merged[len(merged)-1]; last[1] >= len(lines)-minOmitted {
+		last[1] = len(lines) - 1
+	}
+	kept := 0
+	for _, r := range merged {
+		kept += r[1] - r[0] + 1
+	}
+	if kept >= len(lines) {
+		return false
+	}
+
+	var b strings.Builder
+	next := 0
+	for _, r := range merged {
+		if r[0] > next {
+			b.WriteString(strings.TrimPrefix(omittedMarker(r[0]-next), "\n"))
+		}
+		b.WriteString(strings.Join(lines[r[0]:r[1]+1], "\n") + "\n")
+		next = r[1] + 1
+	}
+	if next < len(lines) {
+		b.WriteString(strings.TrimPrefix(omittedMarker(len(lines)-next), "\n"))
+	}
+
+	file.Truncation = &format.TruncationInfo{Mode: snippetMode(names), OriginalTokens: file.Tokens}
+	file.Content = b.String()
+	file.Tokens = tokenCounter.EstimateTokens(file.Content)
+	return true
+}
+
+// snippetMode describes a file cut to snippets for TruncationInfo.Mode,
+// naming up to five of the kept definitions
+func snippetMode(names []string) string {
+	names = dedupeSorted(append([]string(nil), names...))
+	if len(names) > 5 {
+		return fmt.Sprintf("symbols:%s,+%d", strings.Join(names[:5], ","), len(names)-5)
+	}
+	return "symbols:" + strings.Join(names, ",")
+}
@@ -0,0 +1,170 @@
+package processor
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const snippetGoSource = `package config
+
+import "os"
+
+// Config holds the settings
+type Config struct {
+	Path string
+}
+
+// ParseConfig reads the config at path
+func ParseConfig(path string) (*Config, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return &Config{Path: path}, nil
+}
+
+func helper() int {
+	return 1
+}
+
+func other() int {
+	return 2
+}
+`
+
+func TestFindSymbols(t *testing.T) {
+	names := func(path, content string) map[string][2]int {
+		found := make(map[string][2]int)
+		for _, sym := range findSymbols(path, content) {
+			found[sym.name] = [2]int{sym.start, sym.end}
+		}
+		return found
+	}
+
+	t.Run("go", func(t *testing.T) {
+		assert.Equal(t, map[string][2]int{
+			"Config":      {4, 7},
+			"ParseConfig": {9, 15},
+			"helper":      {17, 19},
+			"other":       {21, 23},
+		}, names("config.go", snippetGoSource))
+	})
+
+	t.Run("typescript", func(t *testing.T) {
+		source := "import { x } from './x';\n\n" +
+			"/** Loads the config */\n" +
+			"export async function loadConfig(path: string): Promise<Config> {\n" +
+			"  if (!path) { throw new Error('}'); }\n" +
+			"  return read(path);\n" +
+			"}\n\n" +
+			"export const parse = (text: string) => {\n" +
+			"  return JSON.parse(text);\n" +
+			"};\n\n" +
+			"class Store {\n" +
+			"  get(key: string) {\n" +
+			"    return this.items[key];\n" +
+			"  }\n" +
+			"}\n"
+		assert.Equal(t, map[string][2]int{
+			"loadConfig": {2, 6},
+			"parse":      {8, 10},
+			"Store":      {12, 16},
+			"get":        {13, 15},
+		}, names("config.ts", source))
+	})
+
+	t.Run("python", func(t *testing.T) {
+		source := "import os\n\n" +
+			"@cached\n" +
+			"def load_config(path):\n" +
+			"    with open(path) as f:\n" +
+			"\n" +
+			"        return f.read()\n\n" +
+			"class Store:\n" +
+			"    def get(self, key):\n" +
+			"        return self.items[key]\n"
+		assert.Equal(t, map[string][2]int{
+			"load_config": {2, 6},
+			"Store":       {8, 10},
+			"get":         {9, 10},
+		}, names("config.py", source))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		assert.Nil(t, findSymbols("README.md", "# ParseConfig\n"))
+	})
+}
+
+func TestExtractSnippets(t *testing.T) {
+	tc := token.NewTokenCounter()
+	file := format.FileInfo{Path: "config.go", Content: snippetGoSource, Tokens: tc.EstimateTokens(snippetGoSource)}
+	original := file.Tokens
+
+	require.True(t, extractSnippets(&file, []string{"parseconfig"}, 1, tc))
+	assert.Equal(t, "... [8 lines truncated] ...\n"+
+		"\n"+
+		"// ParseConfig reads the config at path\n"+
+		"func ParseConfig(path string) (*Config, error) {\n"+
+		"\tif _, err := os.Stat(path); err != nil {\n"+
+		"\t\treturn nil, err\n"+
+		"\t}\n"+
+		"\treturn &Config{Path: path}, nil\n"+
+		"}\n"+
+		"\n"+
+		"... [7 lines truncated] ...\n", file.Content)
+	require.NotNil(t, file.Truncation)
+	assert.Equal(t, "symbols:ParseConfig", file.Truncation.Mode)
+	assert.Equal(t, original, file.Truncation.OriginalTokens)
+	assert.Less(t, file.Tokens, original)
+
+	// A keyword matching several definitions keeps each of them
+	file = format.FileInfo{Path: "config.go", Content: snippetGoSource}
+	require.True(t, extractSnippets(&file, []string{"config"}, 0, tc))
+	assert.Equal(t, "symbols:Config,ParseConfig", file.Truncation.Mode)
+	assert.NotContains(t, file.Content, "func helper")
+
+	// No matching definition, or every line kept: the file stays whole
+	file = format.FileInfo{Path: "config.go", Content: snippetGoSource}
+	assert.False(t, extractSnippets(&file, []string{"missing"}, 3, tc))
+	assert.False(t, extractSnippets(&file, []string{"config", "helper", "other"}, 3, tc))
+	assert.Equal(t, snippetGoSource, file.Content)
+	assert.Nil(t, file.Truncation)
+}
+
+func TestProcessDirectorySnippets(t *testing.T) {
+	files := map[string]string{
+		"config/config.go": snippetGoSource,
+		"README.md":        "# ParseConfig usage\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:           tmpDir,
+		Filter:            filter.New(filter.Options{UseDefaultRules: true}),
+		RelevanceKeywords: "ParseConfig",
+		Snippets:          true,
+		SnippetContext:    2,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	byPath := make(map[string]format.FileInfo)
+	for _, file := range result.ProjectOutput.Files {
+		byPath[file.Path] = file
+	}
+	require.Contains(t, byPath, "config/config.go")
+	goFile := byPath["config/config.go"]
+	require.NotNil(t, goFile.Truncation)
+	assert.Equal(t, "symbols:ParseConfig", goFile.Truncation.Mode)
+	assert.True(t, strings.Contains(goFile.Content, "func ParseConfig"))
+	assert.False(t, strings.Contains(goFile.Content, "func other"))
+	assert.Equal(t, "# ParseConfig usage\n", byPath["README.md"].Content, "unsupported files stay whole")
+	assert.Equal(t, 1, result.ProjectOutput.Budget.FileTruncations)
+}
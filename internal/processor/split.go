@@ -0,0 +1,145 @@
+package processor
+
+import (
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// maxSplitPasses bounds how often SplitOutput repacks when cross-references
+// push a part over the budget
+const maxSplitPasses = 4
+
+// SplitPart is one rendered part of an output split by token budget
+type SplitPart struct {
+	Output   *format.ProjectOutput
+	Rendered string
+	Tokens   int // Token count of Rendered
+}
+
+// SplitOutput partitions output's files, in their existing order, into parts
+// whose rendering through render stays within maxTokens. Every part repeats the
+// project manifest (full directory tree, metadata, git info) and carries a
+// SplitInfo naming the files in the other parts. Stats, budget, packages and
+// dependencies are recomputed per part. A file too large for any part is given
+// a part of its own, so that part may exceed maxTokens.
+//
+// output is not modified.
+func SplitOutput(output *format.ProjectOutput, maxTokens int, config Config, render RenderFunc) ([]SplitPart, error) {
+	tokenCounter, err := token.NewTokenCounterFor(config.Tokenizer)
+	if err != nil {
+		return nil, err
+	}
+	rendered := func(part *format.ProjectOutput) (string, int, error) {
+		text, err := render(part)
+		if err != nil {
+			return "", 0, err
+		}
+		return text, tokenCounter.EstimateTokens(text), nil
+	}
+
+	// The manifest and cross-references cost the same in every part; measure
+	// them with every path listed once, then each file by what it adds
+	allPaths := make([]string, len(output.Files))
+	for i, file := range output.Files {
+		allPaths[i] = file.Path
+	}
+	manifest := splitPartOutput(output, nil)
+	manifest.Split = &format.SplitInfo{Part: 1, Total: 2, Parts: [][]string{nil, allPaths}}
+	_, overhead, err := rendered(manifest)
+	if err != nil {
+		return nil, err
+	}
+	_, empty, err := rendered(&format.ProjectOutput{})
+	if err != nil {
+		return nil, err
+	}
+	costs := make([]int, len(output.Files))
+	for i, file := range output.Files {
+		_, withFile, err := rendered(&format.ProjectOutput{Files: []format.FileInfo{file}})
+		if err != nil {
+			return nil, err
+		}
+		costs[i] = withFile - empty
+	}
+
+	budget := maxTokens - overhead
+	var parts []SplitPart
+	for pass := 0; pass < maxSplitPasses; pass++ {
+		groups := packFiles(output.Files, costs, budget)
+		parts, err = renderSplitParts(output, groups, rendered)
+		if err != nil {
+			return nil, err
+		}
+
+		// Repack tighter if a multi-file part overshot after rendering
+		over := 0
+		for i, part := range parts {
+			if part.Tokens > maxTokens && len(groups[i]) > 1 && part.Tokens-maxTokens > over {
+				over = part.Tokens - maxTokens
+			}
+		}
+		if over == 0 {
+			break
+		}
+		log.Debug("Split part over budget by %d tokens, repacking", over)
+		budget -= over
+	}
+
+	log.Debug("Split %d files into %d parts (budget %d tokens each)", len(output.Files), len(parts), maxTokens)
+	return parts, nil
+}
+
+// packFiles groups files greedily in order so each group's cost fits budget
+func packFiles(files []format.FileInfo, costs []int, budget int) [][]format.FileInfo {
+	var groups [][]format.FileInfo
+	var current []format.FileInfo
+	used := 0
+	for i, file := range files {
+		if len(current) > 0 && used+costs[i] > budget {
+			groups = append(groups, current)
+			current, used = nil, 0
+		}
+		current = append(current, file)
+		used += costs[i]
+	}
+	if len(current) > 0 || len(groups) == 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// renderSplitParts builds and renders one output per group with cross-references
+func renderSplitParts(output *format.ProjectOutput, groups [][]format.FileInfo, rendered func(*format.ProjectOutput) (string, int, error)) ([]SplitPart, error) {
+	paths := make([][]string, len(groups))
+	for i, group := range groups {
+		for _, file := range group {
+			paths[i] = append(paths[i], file.Path)
+		}
+	}
+
+	parts := make([]SplitPart, len(groups))
+	for i, group := range groups {
+		part := splitPartOutput(output, group)
+		part.Split = &format.SplitInfo{Part: i + 1, Total: len(groups), Parts: paths}
+		text, tokens, err := rendered(part)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = SplitPart{Output: part, Rendered: text, Tokens: tokens}
+	}
+	return parts, nil
+}
+
+// splitPartOutput copies output restricted to files, keeping the full tree as
+// the shared manifest
+func splitPartOutput(output *format.ProjectOutput, files []format.FileInfo) *format.ProjectOutput {
+	part := *output
+	if output.Budget != nil {
+		budget := *output.Budget
+		part.Budget = &budget
+	}
+	applyFileSelection(&part, files)
+	part.DirectoryTree = output.DirectoryTree
+	return &part
+}
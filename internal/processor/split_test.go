@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitOutput(t *testing.T) {
+	output := &format.ProjectOutput{
+		DirectoryTree: &format.DirectoryNode{Name: "demo", Type: "dir"},
+		Metadata:      &format.Metadata{Language: "Go"},
+		Budget:        &format.BudgetInfo{},
+	}
+	for i := 0; i < 6; i++ {
+		path := fmt.Sprintf("pkg/file%d.go", i)
+		output.Files = append(output.Files, format.FileInfo{
+			Path:    path,
+			Content: strings.Repeat(fmt.Sprintf("var v%d = %d // filler text\n", i, i), 30),
+			Tokens:  300,
+		})
+		output.DirectoryTree.Children = append(output.DirectoryTree.Children, &format.DirectoryNode{Name: path, Type: "file"})
+	}
+
+	render := (&format.MarkdownFormatter{}).Format
+	parts, err := SplitOutput(output, 1000, Config{Tokenizer: "chars"}, render)
+	require.NoError(t, err)
+	require.Greater(t, len(parts), 1)
+
+	seen := map[string]int{}
+	for i, part := range parts {
+		assert.LessOrEqual(t, part.Tokens, 1000, "part %d over budget", i+1)
+		require.NotNil(t, part.Output.Split)
+		assert.Equal(t, i+1, part.Output.Split.Part)
+		assert.Equal(t, len(parts), part.Output.Split.Total)
+		assert.Len(t, part.Output.DirectoryTree.Children, 6, "every part keeps the full tree")
+		assert.Contains(t, part.Rendered, fmt.Sprintf("Part %d of %d", i+1, len(parts)))
+		assert.Equal(t, len(part.Output.Files), part.Output.FileStats.TotalFiles)
+		for _, file := range part.Output.Files {
+			seen[file.Path]++
+		}
+	}
+	assert.Len(t, seen, 6)
+	for path, n := range seen {
+		assert.Equal(t, 1, n, "%s appears in several parts", path)
+	}
+
+	assert.Nil(t, output.Split, "input output must not be modified")
+	assert.Len(t, output.Files, 6)
+}
+
+func TestSplitOutputOversizedFile(t *testing.T) {
+	output := &format.ProjectOutput{Files: []format.FileInfo{
+		{Path: "small.go", Content: "package a", Tokens: 3},
+		{Path: "huge.go", Content: strings.Repeat("x", 4000), Tokens: 1000},
+	}}
+
+	parts, err := SplitOutput(output, 200, Config{Tokenizer: "chars"}, (&format.MarkdownFormatter{}).Format)
+	require.NoError(t, err)
+	require.Len(t, parts, 2)
+	assert.Equal(t, "huge.go", parts[1].Output.Files[0].Path)
+	assert.Greater(t, parts[1].Tokens, 200)
+}
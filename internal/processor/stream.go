@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// StreamFiles walks config.DirPath and calls emit for each file that passes the
+// filter, in walk (lexical) order, then for config.ExtraFiles. Unlike ProcessDirectory it holds no file
+// contents beyond the one being emitted, so memory stays flat on very large
+// repositories. Steps that need the whole candidate set (relevance ranking,
+// token budgets, size outliers, test association) are not applied.
+//
+// If emit returns an error the walk stops and that error is returned.
+func StreamFiles(config Config, emit func(format.FileInfo) error) error {
+	tokenCounter, err := token.NewTokenCounterFor(config.Tokenizer)
+	if err != nil {
+		return err
+	}
+
+	annotations := newAnnotator(config.Annotations)
+	extraPaths := make(map[string]bool, len(config.ExtraFiles))
+	for _, path := range config.ExtraFiles {
+		extraPaths[externalPath(config.DirPath, path)] = true
+	}
+	var streamedExtras []format.FileInfo // Extra files the walk already emitted
+	var streamed []sizedFile             // Files emitted, for MaxTotalSize
+	var streamedSize int64
+	err = walkRoots(config, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return walkDir(path, config)
+		}
+
+		relPath, err := filepath.Rel(config.DirPath, path)
+		if err != nil {
+			return err
+		}
+		if config.Filter.IsExcluded(relPath) {
+			return nil
+		}
+
+		fileInfo, err := loadFile(path, relPath, config, tokenCounter)
+		var transformErr *TransformError
+		if errors.As(err, &transformErr) {
+			return err
+		}
+		if err != nil {
+			log.Debug("Error processing file %s: %v", path, err)
+			return nil // Continue processing other files
+		}
+		if fileInfo == nil {
+			return nil
+		}
+		if config.MaxTotalSize > 0 {
+			if stat, err := statFile(config, path); err == nil {
+				streamed = append(streamed, sizedFile{path: relPath, size: stat.Size()})
+				if streamedSize += stat.Size(); streamedSize > config.MaxTotalSize {
+					return totalSizeError(streamed, streamedSize, config.MaxTotalSize)
+				}
+			}
+		}
+		annotations.annotate(fileInfo)
+		if extraPaths[relPath] {
+			streamedExtras = append(streamedExtras, format.FileInfo{Path: relPath})
+		}
+		return emit(*fileInfo)
+	})
+	if err == nil {
+		var extraFiles []format.FileInfo
+		if extraFiles, err = loadExtraFiles(config, tokenCounter, streamedExtras); err == nil {
+			for _, file := range extraFiles {
+				annotations.annotate(&file)
+				if err = emit(file); err != nil {
+					break
+				}
+			}
+		}
+	}
+
+	if config.Cache != nil {
+		if saveErr := config.Cache.Save(); saveErr != nil {
+			log.Warn("%v", saveErr)
+		}
+	}
+	return err
+}
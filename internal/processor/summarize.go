@@ -0,0 +1,449 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"gopkg.in/yaml.v3"
+)
+
+// maxSummaryPackages caps the packages a lockfile summary lists when the
+// lockfile doesn't say which dependencies are direct
+const maxSummaryPackages = 50
+
+// lockPackage is one locked package
+type lockPackage struct {
+	Name    string
+	Version string
+}
+
+// lockSummary is what a lockfile records, as far as a summary needs it
+type lockSummary struct {
+	Ecosystem string
+	Packages  []lockPackage
+	Direct    []string // Names of the direct dependencies, when the lockfile records them
+}
+
+// lockfileParsers parse lockfiles by file name
+var lockfileParsers = map[string]func(content string) (*lockSummary, error){
+	"package-lock.json": parseNPMLock,
+	"yarn.lock":         parseYarnLock,
+	"pnpm-lock.yaml":    parsePNPMLock,
+	"composer.lock":     parseComposerLock,
+	"Pipfile.lock":      parsePipfileLock,
+	"poetry.lock":       func(content string) (*lockSummary, error) { return parseTOMLPackages("pypi", content), nil },
+	"Cargo.lock":        parseCargoLock,
+	"Gemfile.lock":      parseGemfileLock,
+	"go.sum":            parseGoSum,
+}
+
+// summarizeAsset returns a summary to stand in for the content of a
+// lockfile or a minified JS/CSS bundle, whose raw content spends tokens
+// without telling a model much. Other files, and lockfiles that don't
+// parse, return false.
+func summarizeAsset(relPath, content string) (string, bool) {
+	name := filepath.Base(relPath)
+	if parse, ok := lockfileParsers[name]; ok {
+		summary, err := parse(content)
+		if err != nil || summary == nil || len(summary.Packages) == 0 {
+			return "", false
+		}
+		return summary.render(name), true
+	}
+	if rules.IsMinifiedFile(relPath, content) {
+		return summarizeMinified(name, content), true
+	}
+	return "", false
+}
+
+// render writes the summary of the lockfile named name
+func (s *lockSummary) render(name string) string {
+	versions := make(map[string]string, len(s.Packages))
+	for _, pkg := range s.Packages {
+		versions[pkg.Name] = pkg.Version
+	}
+	sort.Slice(s.Packages, func(i, j int) bool { return s.Packages[i].Name < s.Packages[j].Name })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary of lockfile %s (raw content omitted)\n", name)
+	fmt.Fprintf(&b, "ecosystem: %s\n", s.Ecosystem)
+	fmt.Fprintf(&b, "locked packages: %d\n", len(s.Packages))
+	if s.Direct != nil {
+		direct := append([]string(nil), s.Direct...)
+		sort.Strings(direct)
+		fmt.Fprintf(&b, "direct dependencies: %d\n", len(direct))
+		for _, dep := range direct {
+			writeLockPackage(&b, dep, versions[dep])
+		}
+		return b.String()
+	}
+	fmt.Fprintf(&b, "packages:\n")
+	for i, pkg := range s.Packages {
+		if i == maxSummaryPackages {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(s.Packages)-maxSummaryPackages)
+			break
+		}
+		writeLockPackage(&b, pkg.Name, pkg.Version)
+	}
+	return b.String()
+}
+
+func writeLockPackage(b *strings.Builder, name, version string) {
+	if version == "" {
+		fmt.Fprintf(b, "  %s\n", name)
+		return
+	}
+	fmt.Fprintf(b, "  %s %s\n", name, version)
+}
+
+var sourceMapPattern = regexp.MustCompile(`[#@] sourceMappingURL=(\S+)`)
+
+// summarizeMinified describes a minified bundle: its size, its preserved
+// license banner (a leading /*! ... */ comment), and its source map
+func summarizeMinified(name, content string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary of minified file %s (raw content omitted)\n", name)
+	fmt.Fprintf(&b, "size: %d bytes, %d lines\n", len(content), strings.Count(content, "\n")+1)
+	if trimmed := strings.TrimSpace(content); strings.HasPrefix(trimmed, "/*!") {
+		if end := strings.Index(trimmed, "*/"); end > 0 {
+			banner := strings.Join(strings.Fields(trimmed[3:end]), " ")
+			if len(banner) > 200 {
+				banner = banner[:200] + "..."
+			}
+			fmt.Fprintf(&b, "banner: %s\n", banner)
+		}
+	}
+	if match := sourceMapPattern.FindStringSubmatch(content); match != nil {
+		fmt.Fprintf(&b, "source map: %s\n", strings.TrimSuffix(match[1], "*/"))
+	}
+	return b.String()
+}
+
+// parseNPMLock reads package-lock.json: the packages map of lockfile
+// versions 2 and 3, whose root entry names the direct dependencies, or the
+// nested dependencies of version 1
+func parseNPMLock(content string) (*lockSummary, error) {
+	type npmPackage struct {
+		Version              string                 `json:"version"`
+		Dependencies         map[string]interface{} `json:"dependencies"`
+		DevDependencies      map[string]interface{} `json:"devDependencies"`
+		OptionalDependencies map[string]interface{} `json:"optionalDependencies"`
+	}
+	type npmV1Dependency struct {
+		Version      string                     `json:"version"`
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	var lock struct {
+		Packages     map[string]npmPackage      `json:"packages"`
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+
+	summary := &lockSummary{Ecosystem: "npm"}
+	if lock.Packages != nil {
+		root := lock.Packages[""]
+		summary.Direct = []string{}
+		for _, deps := range []map[string]interface{}{root.Dependencies, root.DevDependencies, root.OptionalDependencies} {
+			for name := range deps {
+				summary.Direct = append(summary.Direct, name)
+			}
+		}
+		for path, pkg := range lock.Packages {
+			i := strings.LastIndex(path, "node_modules/")
+			if i < 0 {
+				continue // The root, or a workspace member
+			}
+			name := path[i+len("node_modules/"):]
+			if path == "node_modules/"+name {
+				summary.Packages = append(summary.Packages, lockPackage{Name: name, Version: pkg.Version})
+			} else {
+				summary.Packages = append(summary.Packages, lockPackage{Name: name + " (nested)", Version: pkg.Version})
+			}
+		}
+		return summary, nil
+	}
+
+	var walk func(deps map[string]json.RawMessage, nested bool)
+	walk = func(deps map[string]json.RawMessage, nested bool) {
+		for name, raw := range deps {
+			var dep npmV1Dependency
+			if json.Unmarshal(raw, &dep) != nil {
+				continue
+			}
+			label := name
+			if nested {
+				label += " (nested)"
+			}
+			summary.Packages = append(summary.Packages, lockPackage{Name: label, Version: dep.Version})
+			walk(dep.Dependencies, true)
+		}
+	}
+	walk(lock.Dependencies, false)
+	return summary, nil
+}
+
+// parseYarnLock reads yarn.lock, classic and Berry: each unindented entry
+// header lists the ranges it resolves, and its version line follows
+func parseYarnLock(content string) (*lockSummary, error) {
+	summary := &lockSummary{Ecosystem: "npm (yarn)"}
+	var current string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case !strings.HasPrefix(line, " "):
+			current = yarnPackageName(strings.TrimSuffix(line, ":"))
+		case current != "" && strings.HasPrefix(strings.TrimSpace(line), "version"):
+			version := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "version"))
+			version = strings.Trim(strings.TrimPrefix(version, ":"), ` "`)
+			summary.Packages = append(summary.Packages, lockPackage{Name: current, Version: version})
+			current = ""
+		}
+	}
+	return summary, nil
+}
+
+// yarnPackageName returns the package name of a yarn.lock entry header such
+// as `"@babel/core@^7.0.0", "@babel/core@^7.1.0"`
+func yarnPackageName(header string) string {
+	first := strings.Trim(strings.TrimSpace(strings.Split(header, ",")[0]), `"`)
+	if first == "__metadata" {
+		return ""
+	}
+	if at := strings.LastIndex(first, "@"); at > 0 {
+		return first[:at]
+	}
+	return first
+}
+
+// parsePNPMLock reads pnpm-lock.yaml: the root importer (or, before
+// lockfile version 6, the top-level dependencies) names the direct
+// dependencies, and the packages map holds every locked package
+func parsePNPMLock(content string) (*lockSummary, error) {
+	var lock struct {
+		Importers       map[string]map[string]interface{} `yaml:"importers"`
+		Dependencies    map[string]interface{}            `yaml:"dependencies"`
+		DevDependencies map[string]interface{}            `yaml:"devDependencies"`
+		Packages        map[string]interface{}            `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+
+	summary := &lockSummary{Ecosystem: "npm (pnpm)", Direct: []string{}}
+	direct := []interface{}{lock.Dependencies, lock.DevDependencies}
+	if root, ok := lock.Importers["."]; ok {
+		direct = []interface{}{root["dependencies"], root["devDependencies"], root["optionalDependencies"]}
+	}
+	for _, deps := range direct {
+		switch deps := deps.(type) {
+		case map[string]interface{}:
+			for name := range deps {
+				summary.Direct = append(summary.Direct, name)
+			}
+		}
+	}
+	for key := range lock.Packages {
+		// Keys are "/name@1.0.0" (v6+: "name@1.0.0") or "/name/1.0.0" (v5)
+		key = strings.TrimPrefix(key, "/")
+		if paren := strings.Index(key, "("); paren > 0 {
+			key = key[:paren] // Peer dependency suffix
+		}
+		sep := strings.LastIndex(key, "@")
+		if sep <= 0 {
+			sep = strings.LastIndex(key, "/")
+		}
+		if sep <= 0 {
+			summary.Packages = append(summary.Packages, lockPackage{Name: key})
+			continue
+		}
+		summary.Packages = append(summary.Packages, lockPackage{Name: key[:sep], Version: key[sep+1:]})
+	}
+	return summary, nil
+}
+
+// parseComposerLock reads composer.lock's packages and packages-dev
+func parseComposerLock(content string) (*lockSummary, error) {
+	type composerPackage struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	var lock struct {
+		Packages    []composerPackage `json:"packages"`
+		PackagesDev []composerPackage `json:"packages-dev"`
+	}
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+	summary := &lockSummary{Ecosystem: "packagist"}
+	for _, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		summary.Packages = append(summary.Packages, lockPackage{Name: pkg.Name, Version: pkg.Version})
+	}
+	return summary, nil
+}
+
+// parsePipfileLock reads Pipfile.lock's default and develop sections
+func parsePipfileLock(content string) (*lockSummary, error) {
+	type pipPackage struct {
+		Version string `json:"version"`
+	}
+	var lock struct {
+		Default map[string]pipPackage `json:"default"`
+		Develop map[string]pipPackage `json:"develop"`
+	}
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+	summary := &lockSummary{Ecosystem: "pypi"}
+	for _, section := range []map[string]pipPackage{lock.Default, lock.Develop} {
+		for name, pkg := range section {
+			summary.Packages = append(summary.Packages, lockPackage{Name: name, Version: strings.TrimPrefix(pkg.Version, "==")})
+		}
+	}
+	return summary, nil
+}
+
+// tomlPackage is a [[package]] table of Cargo.lock or poetry.lock
+type tomlPackage struct {
+	lockPackage
+	source       bool     // Has a source, so it isn't a workspace member
+	dependencies []string // Names from the dependencies array
+}
+
+// parseTOMLPackageTables reads the name, version, source and dependencies
+// keys of the [[package]] tables of a lockfile, line by line
+func parseTOMLPackageTables(content string) []tomlPackage {
+	var packages []tomlPackage
+	var current *tomlPackage
+	inDependencies := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if inDependencies {
+			if strings.HasPrefix(line, "]") {
+				inDependencies = false
+				continue
+			}
+			if dep := strings.Fields(strings.Trim(line, `",`)); len(dep) > 0 && current != nil {
+				current.dependencies = append(current.dependencies, dep[0])
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			current = nil
+			if line == "[[package]]" {
+				packages = append(packages, tomlPackage{})
+				current = &packages[len(packages)-1]
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || current == nil {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "name":
+			current.Name = strings.Trim(value, `"`)
+		case "version":
+			current.Version = strings.Trim(value, `"`)
+		case "source":
+			current.source = true
+		case "dependencies":
+			inDependencies = value == "["
+		}
+	}
+	return packages
+}
+
+// parseTOMLPackages summarizes the [[package]] tables of a lockfile of ecosystem
+func parseTOMLPackages(ecosystem, content string) *lockSummary {
+	summary := &lockSummary{Ecosystem: ecosystem}
+	for _, pkg := range parseTOMLPackageTables(content) {
+		summary.Packages = append(summary.Packages, pkg.lockPackage)
+	}
+	return summary
+}
+
+// parseCargoLock reads Cargo.lock, where the direct dependencies are those
+// of the workspace members, the packages without a source
+func parseCargoLock(content string) (*lockSummary, error) {
+	summary := &lockSummary{Ecosystem: "crates.io"}
+	members := make(map[string]bool)
+	direct := make(map[string]bool)
+	packages := parseTOMLPackageTables(content)
+	for _, pkg := range packages {
+		if !pkg.source {
+			members[pkg.Name] = true
+			for _, dep := range pkg.dependencies {
+				direct[dep] = true
+			}
+		}
+	}
+	for _, pkg := range packages {
+		if !members[pkg.Name] {
+			summary.Packages = append(summary.Packages, pkg.lockPackage)
+		}
+	}
+	if len(members) > 0 {
+		summary.Direct = []string{}
+		for dep := range direct {
+			if !members[dep] {
+				summary.Direct = append(summary.Direct, dep)
+			}
+		}
+	}
+	return summary, nil
+}
+
+// parseGemfileLock reads the specs of Gemfile.lock's GEM section and its
+// DEPENDENCIES section, which lists the direct dependencies
+func parseGemfileLock(content string) (*lockSummary, error) {
+	summary := &lockSummary{Ecosystem: "rubygems", Direct: []string{}}
+	section := ""
+	for _, line := range strings.Split(content, "\n") {
+		if line != "" && !strings.HasPrefix(line, " ") {
+			section = strings.TrimSpace(line)
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case section == "GEM" && strings.HasPrefix(line, "    ") && !strings.HasPrefix(line, "     "):
+			summary.Packages = append(summary.Packages, lockPackage{Name: fields[0], Version: strings.Trim(strings.Join(fields[1:], " "), "()")})
+		case section == "DEPENDENCIES":
+			summary.Direct = append(summary.Direct, strings.TrimSuffix(fields[0], "!"))
+		}
+	}
+	return summary, nil
+}
+
+// parseGoSum reads go.sum, keeping the last (highest) version of each module
+// with a content hash; go.mod decides which modules are direct
+func parseGoSum(content string) (*lockSummary, error) {
+	summary := &lockSummary{Ecosystem: "go"}
+	versions := make(map[string]string)
+	var order []string
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		if _, seen := versions[fields[0]]; !seen {
+			order = append(order, fields[0])
+		}
+		versions[fields[0]] = fields[1]
+	}
+	for _, module := range order {
+		summary.Packages = append(summary.Packages, lockPackage{Name: module, Version: versions[module]})
+	}
+	return summary, nil
+}
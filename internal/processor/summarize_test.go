@@ -0,0 +1,113 @@
+package processor
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeLockfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"package-lock.json", `{"lockfileVersion": 3, "packages": {
+			"": {"dependencies": {"react": "^18.2.0"}, "devDependencies": {"vite": "^5.0.0"}},
+			"node_modules/react": {"version": "18.2.0"},
+			"node_modules/loose-envify": {"version": "1.4.0"},
+			"node_modules/vite": {"version": "5.0.8"},
+			"node_modules/vite/node_modules/esbuild": {"version": "0.19.0"}}}`,
+			[]string{"ecosystem: npm", "locked packages: 4", "direct dependencies: 2", "  react 18.2.0\n  vite 5.0.8\n"}},
+		{"package-lock.json", `{"lockfileVersion": 1, "dependencies": {"lodash": {"version": "4.17.21"}}}`,
+			[]string{"locked packages: 1", "packages:\n  lodash 4.17.21\n"}},
+		{"yarn.lock", "# yarn lockfile v1\n\n\"@babel/core@^7.0.0\", \"@babel/core@^7.1.0\":\n  version \"7.22.0\"\n\nlodash@^4.17.21:\n  version \"4.17.21\"\n",
+			[]string{"ecosystem: npm (yarn)", "  @babel/core 7.22.0\n  lodash 4.17.21\n"}},
+		{"yarn.lock", "__metadata:\n  version: 6\n\n\"react@npm:^18.2.0\":\n  version: 18.2.0\n",
+			[]string{"locked packages: 1", "  react 18.2.0\n"}},
+		{"pnpm-lock.yaml", "lockfileVersion: '6.0'\nimporters:\n  .:\n    dependencies:\n      react:\n        specifier: ^18.2.0\n        version: 18.2.0\npackages:\n  /react@18.2.0:\n    resolution: {integrity: sha512-x}\n  /loose-envify@1.4.0:\n    resolution: {integrity: sha512-y}\n",
+			[]string{"locked packages: 2", "direct dependencies: 1\n  react 18.2.0\n"}},
+		{"Cargo.lock", "version = 3\n\n[[package]]\nname = \"app\"\nversion = \"0.1.0\"\ndependencies = [\n \"serde\",\n \"tokio 1.35.0\",\n]\n\n[[package]]\nname = \"serde\"\nversion = \"1.0.193\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n\n[[package]]\nname = \"tokio\"\nversion = \"1.35.0\"\nsource = \"registry+https://github.com/rust-lang/crates.io-index\"\n",
+			[]string{"ecosystem: crates.io", "locked packages: 2", "direct dependencies: 2\n  serde 1.0.193\n  tokio 1.35.0\n"}},
+		{"poetry.lock", "[[package]]\nname = \"requests\"\nversion = \"2.31.0\"\n\n[package.dependencies]\nidna = \">=2.5\"\n",
+			[]string{"ecosystem: pypi", "  requests 2.31.0\n"}},
+		{"Gemfile.lock", "GEM\n  remote: https://rubygems.org/\n  specs:\n    rack (3.0.8)\n    rails (7.1.2)\n      rack (>= 2.2.4)\n\nDEPENDENCIES\n  rails (~> 7.1)\n",
+			[]string{"locked packages: 2", "direct dependencies: 1\n  rails 7.1.2\n"}},
+		{"composer.lock", `{"packages": [{"name": "monolog/monolog", "version": "3.5.0"}], "packages-dev": [{"name": "phpunit/phpunit", "version": "10.5.3"}]}`,
+			[]string{"ecosystem: packagist", "  monolog/monolog 3.5.0\n  phpunit/phpunit 10.5.3\n"}},
+		{"Pipfile.lock", `{"default": {"flask": {"version": "==3.0.0"}}, "develop": {}}`,
+			[]string{"  flask 3.0.0\n"}},
+		{"go.sum", "github.com/pkg/errors v0.9.0 h1:a=\ngithub.com/pkg/errors v0.9.0/go.mod h1:b=\ngithub.com/pkg/errors v0.9.1 h1:c=\ngolang.org/x/mod v0.14.0/go.mod h1:d=\n",
+			[]string{"ecosystem: go", "locked packages: 1", "  github.com/pkg/errors v0.9.1\n"}},
+	}
+	for _, tt := range tests {
+		summary, ok := summarizeAsset("sub/"+tt.name, tt.content)
+		require.True(t, ok, tt.name)
+		assert.True(t, strings.HasPrefix(summary, "Summary of lockfile "+tt.name+" (raw content omitted)\n"), summary)
+		for _, want := range tt.want {
+			assert.Contains(t, summary, want, tt.name)
+		}
+	}
+
+	_, ok := summarizeAsset("package-lock.json", "not json")
+	assert.False(t, ok, "unparsable lockfiles are kept as they are")
+	_, ok = summarizeAsset("main.go", "package main\n")
+	assert.False(t, ok)
+}
+
+func TestSummarizeLockfileCapsPackageList(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < maxSummaryPackages+5; i++ {
+		b.WriteString("[[package]]\nname = \"crate" + strings.Repeat("x", i) + "\"\nversion = \"1.0.0\"\nsource = \"registry\"\n")
+	}
+	summary, ok := summarizeAsset("poetry.lock", b.String())
+	require.True(t, ok)
+	assert.Contains(t, summary, "  ... and 5 more\n")
+}
+
+func TestSummarizeMinified(t *testing.T) {
+	content := "/*! jQuery v3.7.1 | (c) OpenJS Foundation */\n" + strings.Repeat("var a=1;", 400) + "\n//# sourceMappingURL=jquery.min.map"
+	summary, ok := summarizeAsset("static/jquery.min.js", content)
+	require.True(t, ok)
+	assert.Equal(t, "Summary of minified file jquery.min.js (raw content omitted)\n"+
+		"size: 3281 bytes, 3 lines\n"+
+		"banner: jQuery v3.7.1 | (c) OpenJS Foundation\n"+
+		"source map: jquery.min.map\n", summary)
+}
+
+func TestProcessDirectorySummarizesLockfiles(t *testing.T) {
+	lock := `{"lockfileVersion": 3, "packages": {"": {"dependencies": {"react": "^18"}}, "node_modules/react": {"version": "18.2.0"}}}`
+	tmpDir := setupTestProject(t, map[string]string{
+		"main.go":           "package main\n",
+		"package-lock.json": lock,
+	})
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:         tmpDir,
+		Filter:          filter.New(filter.Options{}),
+		SummarizeAssets: true,
+		Redact:          true,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, file := range result.ProjectOutput.Files {
+		contents[file.Path] = file.Content
+	}
+	assert.Contains(t, contents["package-lock.json"], "direct dependencies: 1\n  react 18.2.0\n")
+	assert.Equal(t, "package main\n", contents["main.go"])
+
+	config.SummarizeAssets = false
+	result, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+	for _, file := range result.ProjectOutput.Files {
+		if file.Path == "package-lock.json" {
+			assert.Equal(t, lock, file.Content)
+		}
+	}
+}
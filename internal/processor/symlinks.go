@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/1broseidon/promptext/internal/longpath"
+)
+
+// Symlink policies for Config.Symlinks
+const (
+	SymlinksSkip         = "skip"           // Leave symlinks out (the default)
+	SymlinksFollow       = "follow"         // Read the files and directories symlinks point to
+	SymlinksFollowInRoot = "follow-in-root" // Follow only symlinks pointing inside DirPath
+)
+
+// Reasons recorded on SkippedSymlink
+const (
+	SymlinkReasonPolicy      = "policy"       // The policy is SymlinksSkip
+	SymlinkReasonOutsideRoot = "outside-root" // SymlinksFollowInRoot and the target is outside DirPath
+	SymlinkReasonCycle       = "cycle"        // The target contains the symlink, so following it never ends
+	SymlinkReasonBroken      = "broken"       // The target doesn't exist
+)
+
+// SkippedSymlink is a symlink the walk left out under the symlink policy
+type SkippedSymlink struct {
+	Path   string // Relative to DirPath
+	Target string // What the symlink points to, as written in it
+	Reason string // See the SymlinkReason* constants
+}
+
+// ValidSymlinkPolicy reports an error for a policy other than "" and the
+// Symlinks* constants
+func ValidSymlinkPolicy(policy string) error {
+	switch policy {
+	case "", SymlinksSkip, SymlinksFollow, SymlinksFollowInRoot:
+		return nil
+	}
+	return fmt.Errorf("unknown symlink policy %q (use %s, %s or %s)", policy, SymlinksSkip, SymlinksFollow, SymlinksFollowInRoot)
+}
+
+// walkDisk walks dir on disk, applying config.Symlinks to the symlinks met
+// below it. A symlink named as dir itself is always followed, as it was asked
+// for by name.
+func walkDisk(config Config, dir string, fn fs.WalkDirFunc) error {
+	w := &symlinkWalk{config: config, fn: fn}
+	if config.Symlinks == SymlinksFollowInRoot {
+		if root, err := filepath.EvalSymlinks(config.DirPath); err == nil {
+			w.root = root
+		}
+	}
+	if stat, err := os.Lstat(longpath.Fix(dir)); err == nil && stat.Mode()&fs.ModeSymlink != 0 {
+		if target, err := filepath.EvalSymlinks(dir); err == nil {
+			return w.walk(dir, target)
+		}
+	}
+	return w.walk(dir, dir)
+}
+
+// symlinkWalk is one walk of the disk under a symlink policy
+type symlinkWalk struct {
+	config  Config
+	fn      fs.WalkDirFunc
+	root    string // DirPath with symlinks resolved, for SymlinksFollowInRoot
+	skipAll bool   // fn returned SkipAll, which ends only the innermost WalkDir
+}
+
+// walk walks actual, naming its paths as if it were at name, the symlink
+// that led there. Too-long paths are walked in their extended form.
+func (w *symlinkWalk) walk(name, actual string) error {
+	long := longpath.Fix(actual)
+	return filepath.WalkDir(long, func(path string, d fs.DirEntry, err error) error {
+		path = name + strings.TrimPrefix(path, long)
+		if err == nil && d.Type()&fs.ModeSymlink != 0 {
+			err = w.symlink(path)
+		} else {
+			err = w.fn(path, d, err)
+		}
+		if errors.Is(err, filepath.SkipAll) {
+			w.skipAll = true
+		}
+		return err
+	})
+}
+
+// symlink applies the policy to the symlink at path: walking or passing on
+// what it points to, or recording why it is left out
+func (w *symlinkWalk) symlink(path string) error {
+	link, _ := os.Readlink(longpath.Fix(path))
+	skip := func(reason string) error {
+		log.Debug("Skipping symlink %s -> %s (%s)", path, link, reason)
+		if w.config.symlinkSkipped != nil {
+			rel, err := filepath.Rel(w.config.DirPath, path)
+			if err != nil {
+				rel = path
+			}
+			w.config.symlinkSkipped(SkippedSymlink{Path: rel, Target: link, Reason: reason})
+		}
+		return nil
+	}
+
+	if w.config.Symlinks != SymlinksFollow && w.config.Symlinks != SymlinksFollowInRoot {
+		return skip(SymlinkReasonPolicy)
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return skip(SymlinkReasonBroken)
+	}
+	if w.config.Symlinks == SymlinksFollowInRoot && (w.root == "" || !within(w.root, target)) {
+		return skip(SymlinkReasonOutsideRoot)
+	}
+	stat, err := os.Stat(longpath.Fix(target))
+	if err != nil {
+		return skip(SymlinkReasonBroken)
+	}
+	if !stat.IsDir() {
+		return w.fn(path, fs.FileInfoToDirEntry(stat), nil)
+	}
+
+	// A directory holding the symlink would be walked again inside itself
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil || within(target, parent) {
+		return skip(SymlinkReasonCycle)
+	}
+	if err := w.walk(path, target); err != nil {
+		return err
+	}
+	if w.skipAll {
+		// The followed directory's walk ended early; so does this one
+		return filepath.SkipAll
+	}
+	return nil
+}
+
+// within reports whether path is dir or lies below it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
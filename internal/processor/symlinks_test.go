@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// symlinkProject creates a project with a symlinked file, a symlinked
+// directory inside it, one outside it, a loop and a broken link
+func symlinkProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "pkg", "shared"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "pkg", "shared", "conf.yml"), []byte("a: 1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "ext.go"), []byte("package ext\n"), 0644))
+
+	links := map[string]string{
+		"alias.go":   "main.go",
+		"pkg/linked": "shared",
+		"outside":    outside,
+		"pkg/loop":   "..",
+		"broken":     "nowhere",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, filepath.FromSlash(link))); err != nil {
+			t.Skipf("cannot create symlinks: %v", err)
+		}
+	}
+	return root
+}
+
+func TestPreviewDirectorySymlinks(t *testing.T) {
+	root := symlinkProject(t)
+
+	tests := []struct {
+		policy   string
+		files    []string
+		skipped  map[string]string
+		treeFile string
+	}{
+		{
+			policy:  "",
+			files:   []string{"main.go", "pkg/shared/conf.yml"},
+			skipped: map[string]string{"alias.go": SymlinkReasonPolicy, "pkg/linked": SymlinkReasonPolicy, "outside": SymlinkReasonPolicy, "pkg/loop": SymlinkReasonPolicy, "broken": SymlinkReasonPolicy},
+		},
+		{
+			policy:  SymlinksFollow,
+			files:   []string{"alias.go", "main.go", "outside/ext.go", "pkg/linked/conf.yml", "pkg/shared/conf.yml"},
+			skipped: map[string]string{"pkg/loop": SymlinkReasonCycle, "broken": SymlinkReasonBroken},
+		},
+		{
+			policy:  SymlinksFollowInRoot,
+			files:   []string{"alias.go", "main.go", "pkg/linked/conf.yml", "pkg/shared/conf.yml"},
+			skipped: map[string]string{"outside": SymlinkReasonOutsideRoot, "pkg/loop": SymlinkReasonCycle, "broken": SymlinkReasonBroken},
+		},
+	}
+	for _, tt := range tests {
+		t.Run("policy="+tt.policy, func(t *testing.T) {
+			config := Config{DirPath: root, Filter: filter.New(filter.Options{}), Symlinks: tt.policy}
+			result, err := PreviewDirectory(config)
+			require.NoError(t, err)
+
+			var files []string
+			for _, path := range result.FilePaths {
+				files = append(files, filepath.ToSlash(path))
+			}
+			assert.ElementsMatch(t, tt.files, files)
+
+			skipped := map[string]string{}
+			for _, link := range result.SkippedSymlinks {
+				skipped[filepath.ToSlash(link.Path)] = link.Reason
+			}
+			assert.Equal(t, tt.skipped, skipped)
+			assert.Contains(t, FormatDryRunOutput(result, config), "Skipped Symlinks")
+		})
+	}
+}
+
+func TestProcessDirectoryFollowsSymlinks(t *testing.T) {
+	root := symlinkProject(t)
+
+	config := Config{DirPath: root, Filter: filter.New(filter.Options{}), Symlinks: SymlinksFollow}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	contents := map[string]string{}
+	for _, file := range result.ProjectOutput.Files {
+		contents[filepath.ToSlash(file.Path)] = file.Content
+	}
+	assert.Equal(t, "package ext\n", contents["outside/ext.go"])
+	assert.Equal(t, "package main\n", contents["alias.go"])
+
+	// The tree shows followed directories under the link's name
+	assert.Contains(t, result.ProjectOutput.DirectoryTree.ToMarkdown(1), "ext.go")
+}
+
+func TestValidSymlinkPolicy(t *testing.T) {
+	for _, policy := range []string{"", SymlinksSkip, SymlinksFollow, SymlinksFollowInRoot} {
+		assert.NoError(t, ValidSymlinkPolicy(policy))
+	}
+	assert.Error(t, ValidSymlinkPolicy("always"))
+}
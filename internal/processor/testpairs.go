@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/relevance"
+)
+
+// TestsPaired is the Config.IncludeTests mode that keeps the tests of files
+// selected by relevance, even when the tests don't match themselves
+const TestsPaired = "paired"
+
+// testDirNames are directory names that conventionally hold tests next to
+// (or one level below) the code they cover
+var testDirNames = map[string]bool{
+	"test":      true,
+	"tests":     true,
+	"__tests__": true,
+	"spec":      true,
+}
+
+// testSubject returns the file name of the source file a test file covers,
+// e.g. "foo_test.go" -> "foo.go" or "service.spec.ts" -> "service.ts".
+// The second return value is false when the name is not a recognized test file.
+func testSubject(base string) (string, bool) {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+
+	switch ext {
+	case ".go":
+		if s := strings.TrimSuffix(stem, "_test"); s != stem && s != "" {
+			return s + ext, true
+		}
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs":
+		for _, marker := range []string{".test", ".spec"} {
+			if s := strings.TrimSuffix(stem, marker); s != stem && s != "" {
+				return s + ext, true
+			}
+		}
+	case ".py":
+		if s := strings.TrimPrefix(stem, "test_"); s != stem && s != "" {
+			return s + ext, true
+		}
+		if s := strings.TrimSuffix(stem, "_test"); s != stem && s != "" {
+			return s + ext, true
+		}
+	case ".rb":
+		for _, marker := range []string{"_spec", "_test"} {
+			if s := strings.TrimSuffix(stem, marker); s != stem && s != "" {
+				return s + ext, true
+			}
+		}
+	case ".java", ".kt":
+		for _, marker := range []string{"Tests", "Test", "IT"} {
+			if s := strings.TrimSuffix(stem, marker); s != stem && s != "" {
+				return s + ext, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// testSubjectCandidates returns the paths, in order of preference, where the
+// source file covered by testPath is expected to live
+func testSubjectCandidates(testPath, subject string) []string {
+	dir := path.Dir(testPath)
+	candidates := []string{path.Join(dir, subject)}
+
+	// tests/foo_test.py or __tests__/foo.test.js next to the code
+	if testDirNames[path.Base(dir)] {
+		candidates = append(candidates, path.Join(path.Dir(dir), subject))
+	}
+
+	// Maven/Gradle layout: src/test/java/... mirrors src/main/java/...
+	if strings.Contains("/"+dir+"/", "/src/test/") {
+		mirrored := strings.Replace("/"+dir+"/", "/src/test/", "/src/main/", 1)
+		candidates = append(candidates, path.Join(strings.Trim(mirrored, "/"), subject))
+	}
+
+	return candidates
+}
+
+// associateTests pairs test files with the source files they cover using
+// naming heuristics and records the result in FileInfo.AssociatedTests.
+// Pairing is done within the given file set, so tests that were filtered out
+// before this point are not reported.
+func associateTests(files []format.FileInfo) {
+	for idx, tests := range testPairs(files) {
+		files[idx].AssociatedTests = tests
+	}
+}
+
+// testPairs maps the index of each file in files that tests cover to the
+// sorted paths of those tests
+func testPairs(files []format.FileInfo) map[int][]string {
+	byPath := make(map[string]int, len(files))
+	byName := make(map[string][]int)
+	for i, file := range files {
+		p := filepath.ToSlash(file.Path)
+		byPath[p] = i
+		byName[path.Base(p)] = append(byName[path.Base(p)], i)
+	}
+
+	pairs := make(map[int][]string)
+	for _, file := range files {
+		testPath := filepath.ToSlash(file.Path)
+		subject, ok := testSubject(path.Base(testPath))
+		if !ok {
+			continue
+		}
+
+		target := -1
+		for _, candidate := range testSubjectCandidates(testPath, subject) {
+			if idx, found := byPath[candidate]; found {
+				target = idx
+				break
+			}
+		}
+
+		// Fall back to a unique file name match anywhere in the project
+		// (e.g. tests/test_parser.py covering src/pkg/parser.py)
+		if target == -1 {
+			if matches := byName[subject]; len(matches) == 1 {
+				target = matches[0]
+			}
+		}
+
+		if target != -1 && filepath.ToSlash(files[target].Path) != testPath {
+			pairs[target] = append(pairs[target], file.Path)
+		}
+	}
+
+	for _, tests := range pairs {
+		sort.Strings(tests)
+	}
+	return pairs
+}
+
+// pairTests moves the tests of files that score above 0 to just after the
+// file they cover, so the token budget reaches a relevant file's tests before
+// weaker matches. files must already be in priority order. The returned set
+// holds the tests moved this way, which are kept even though they score 0.
+func pairTests(files []format.FileInfo, scorer relevance.FileScorer) ([]format.FileInfo, map[string]bool) {
+	scores := make([]float64, len(files))
+	index := make(map[string]int, len(files))
+	for i, file := range files {
+		scores[i] = scorer.ScoreFile(file.Path, file.Content)
+		index[file.Path] = i
+	}
+
+	paired := make(map[string]bool)
+	pairs := testPairs(files)
+	for idx, tests := range pairs {
+		if scores[idx] <= 0 {
+			continue
+		}
+		for _, test := range tests {
+			if scores[index[test]] <= 0 {
+				paired[test] = true
+			}
+		}
+	}
+	if len(paired) == 0 {
+		return files, nil
+	}
+
+	ordered := make([]format.FileInfo, 0, len(files))
+	for i, file := range files {
+		if paired[file.Path] {
+			continue
+		}
+		ordered = append(ordered, file)
+		for _, test := range pairs[i] {
+			if paired[test] {
+				ordered = append(ordered, files[index[test]])
+			}
+		}
+	}
+	return ordered, paired
+}
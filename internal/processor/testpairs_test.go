@@ -0,0 +1,143 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssociateTestsAcrossLanguages(t *testing.T) {
+	files := []format.FileInfo{
+		// Go: same directory
+		{Path: "internal/auth/auth.go"},
+		{Path: "internal/auth/auth_test.go"},
+		// TypeScript: .spec and .test next to source
+		{Path: "src/service.ts"},
+		{Path: "src/service.spec.ts"},
+		{Path: "src/service.test.ts"},
+		// JavaScript: __tests__ directory
+		{Path: "lib/util.js"},
+		{Path: "lib/__tests__/util.test.js"},
+		// Python: separate tests/ tree, matched by unique name
+		{Path: "pkg/parser.py"},
+		{Path: "tests/test_parser.py"},
+		// Java: Maven layout
+		{Path: "src/main/java/com/acme/Widget.java"},
+		{Path: "src/test/java/com/acme/WidgetTest.java"},
+		// Ruby: spec suffix
+		{Path: "app/models/user.rb"},
+		{Path: "app/models/user_spec.rb"},
+		// Untested file
+		{Path: "cmd/main.go"},
+	}
+
+	associateTests(files)
+
+	got := make(map[string][]string)
+	for _, f := range files {
+		if len(f.AssociatedTests) > 0 {
+			got[f.Path] = f.AssociatedTests
+		}
+	}
+
+	assert.Equal(t, map[string][]string{
+		"internal/auth/auth.go":              {"internal/auth/auth_test.go"},
+		"src/service.ts":                     {"src/service.spec.ts", "src/service.test.ts"},
+		"lib/util.js":                        {"lib/__tests__/util.test.js"},
+		"pkg/parser.py":                      {"tests/test_parser.py"},
+		"src/main/java/com/acme/Widget.java": {"src/test/java/com/acme/WidgetTest.java"},
+		"app/models/user.rb":                 {"app/models/user_spec.rb"},
+	}, got)
+}
+
+func TestAssociateTestsAmbiguousNameIsNotPaired(t *testing.T) {
+	files := []format.FileInfo{
+		{Path: "a/config.py"},
+		{Path: "b/config.py"},
+		{Path: "tests/test_config.py"},
+	}
+
+	associateTests(files)
+
+	for _, f := range files {
+		assert.Empty(t, f.AssociatedTests, "unexpected pairing for %s", f.Path)
+	}
+}
+
+func TestTestSubject(t *testing.T) {
+	tests := []struct {
+		base    string
+		subject string
+		ok      bool
+	}{
+		{"foo_test.go", "foo.go", true},
+		{"service.spec.ts", "service.ts", true},
+		{"Button.test.tsx", "Button.tsx", true},
+		{"test_views.py", "views.py", true},
+		{"views_test.py", "views.py", true},
+		{"FooTests.java", "Foo.java", true},
+		{"user_spec.rb", "user.rb", true},
+		{"main.go", "", false},
+		{"_test.go", "", false},
+		{"conftest.py", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.base, func(t *testing.T) {
+			subject, ok := testSubject(tt.base)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.subject, subject)
+		})
+	}
+}
+
+func TestProcessDirectoryPairedTests(t *testing.T) {
+	files := map[string]string{
+		"config/loader.go":      "package config\n\n// Loads YAML files\n",
+		"config/loader_test.go": "package config\n",
+		"web/app.ts":            "// renders yaml\n",
+		"web/app.spec.ts":       "test('renders', () => {})\n",
+		"other/other.go":        "package other\n",
+		"other/other_test.go":   "package other\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:           tmpDir,
+		Filter:            filter.New(filter.Options{UseDefaultRules: true}),
+		RelevanceKeywords: "yaml",
+		ExplainSelection:  true,
+	}
+	paths := func() []string {
+		result, err := ProcessDirectory(config, false)
+		require.NoError(t, err)
+		var included []string
+		for _, f := range result.ProjectOutput.Files {
+			included = append(included, f.Path)
+		}
+		return included
+	}
+
+	assert.Equal(t, []string{"web/app.ts", "config/loader.go"}, paths())
+
+	// Each test follows the file it covers; unrelated tests stay out
+	config.IncludeTests = TestsPaired
+	assert.Equal(t, []string{"web/app.ts", "web/app.spec.ts", "config/loader.go", "config/loader_test.go"}, paths())
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	for _, entry := range result.ProjectOutput.Selection.Files {
+		if entry.Path == "web/app.spec.ts" {
+			assert.Contains(t, entry.Traits, "paired")
+		}
+	}
+
+	// A negative keyword still excludes a paired test
+	config.RelevanceKeywords = "yaml -spec"
+	assert.Equal(t, []string{"web/app.ts", "config/loader.go", "config/loader_test.go"}, paths())
+}
@@ -0,0 +1,33 @@
+package processor
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// todoComment matches a TODO, FIXME or HACK marker in a line or block
+// comment ("//", "#", "/*", " *", "--", "<!--"), capturing the marker and the
+// text after it. Markers are matched in upper case only, so prose such as
+// "a hack" isn't taken for one.
+var todoComment = regexp.MustCompile(`(?:^\s*\*|//|#|/\*|--|<!--)\s*@?(TODO|FIXME|HACK)\b:?\s*(.*)`)
+
+// scanTodos lists the TODO, FIXME and HACK comments of files in file order.
+// Line numbers refer to each file's content as included, after any
+// transforms or truncation.
+func scanTodos(files []format.FileInfo) []format.Issue {
+	var issues []format.Issue
+	for _, file := range files {
+		for n, line := range strings.Split(file.Content, "\n") {
+			m := todoComment.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			text := strings.TrimSpace(m[2])
+			text = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(text, "*/"), "-->"))
+			issues = append(issues, format.Issue{Path: file.Path, Line: n + 1, Kind: m[1], Text: text})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,51 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanTodos(t *testing.T) {
+	issues := scanTodos([]format.FileInfo{
+		{Path: "main.go", Content: "package main\n\n// TODO: handle retries\nfunc main() {} // FIXME(bob) leaks\n"},
+		{Path: "app.py", Content: "# HACK work around the API\nprint('TODO in a string')\ntodo = 1\n"},
+		{Path: "style.css", Content: "/* TODO drop vendor prefixes */\n/*\n * FIXME: unused\n */\n"},
+		{Path: "README.md", Content: "<!-- TODO: screenshots -->\nA hack, not a marker.\n"},
+	})
+	assert.Equal(t, []format.Issue{
+		{Path: "main.go", Line: 3, Kind: "TODO", Text: "handle retries"},
+		{Path: "main.go", Line: 4, Kind: "FIXME", Text: "(bob) leaks"},
+		{Path: "app.py", Line: 1, Kind: "HACK", Text: "work around the API"},
+		{Path: "style.css", Line: 1, Kind: "TODO", Text: "drop vendor prefixes"},
+		{Path: "style.css", Line: 3, Kind: "FIXME", Text: "unused"},
+		{Path: "README.md", Line: 1, Kind: "TODO", Text: "screenshots"},
+	}, issues)
+}
+
+func TestProcessDirectoryTodoScan(t *testing.T) {
+	files := map[string]string{
+		"main.go":  "package main\n\n// TODO: parse flags\nfunc main() {}\n",
+		"notes.md": "# Notes\n",
+	}
+	tmpDir := setupTestProject(t, files)
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:  tmpDir,
+		Filter:   filter.New(filter.Options{UseDefaultRules: true}),
+		TodoScan: true,
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.Equal(t, []format.Issue{{Path: "main.go", Line: 3, Kind: "TODO", Text: "parse flags"}}, result.ProjectOutput.Issues)
+
+	config.TodoScan = false
+	result, err = ProcessDirectory(config, false)
+	require.NoError(t, err)
+	assert.Nil(t, result.ProjectOutput.Issues)
+}
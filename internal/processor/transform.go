@@ -0,0 +1,55 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// TransformFunc rewrites the content of the file at path, relative to the
+// processed directory. It is called from the load workers concurrently.
+type TransformFunc func(path string, content []byte) ([]byte, error)
+
+// TransformError reports a file whose Transform failed
+type TransformError struct {
+	Path string
+	Err  error
+}
+
+func (e *TransformError) Error() string {
+	return fmt.Sprintf("transform failed for %s: %v", e.Path, e.Err)
+}
+
+func (e *TransformError) Unwrap() error {
+	return e.Err
+}
+
+// CommandTransform returns a TransformFunc that pipes each file's content
+// through command, run by the shell (sh -c, or cmd /C on Windows), and uses
+// its standard output. The file's path is in the PROMPTEXT_FILE environment
+// variable. A non-zero exit fails the transform, with the command's
+// standard error as the reason.
+func CommandTransform(command string) TransformFunc {
+	return func(path string, content []byte) ([]byte, error) {
+		cmd := exec.Command("sh", "-c", command)
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		}
+		cmd.Env = append(os.Environ(), "PROMPTEXT_FILE="+filepath.ToSlash(path))
+		cmd.Stdin = bytes.NewReader(content)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				return nil, fmt.Errorf("%s: %v: %s", command, err, msg)
+			}
+			return nil, fmt.Errorf("%s: %v", command, err)
+		}
+		return stdout.Bytes(), nil
+	}
+}
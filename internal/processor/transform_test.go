@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDirectoryTransform(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"main.go":   "package main\n// secret: hunter2\n",
+		"notes.txt": "plain\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:       tmpDir,
+		Filter:        filter.New(filter.Options{UseDefaultRules: true}),
+		StripComments: true,
+		Transform: func(path string, content []byte) ([]byte, error) {
+			return []byte(strings.ReplaceAll(string(content), "hunter2", "[masked]") + "// from " + path + "\n"), nil
+		},
+	}
+
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, file := range result.ProjectOutput.Files {
+		contents[file.Path] = file.Content
+	}
+	assert.NotContains(t, contents["main.go"], "from main.go", "built-in transforms run after the custom one")
+	assert.Equal(t, "plain\n// from notes.txt\n", contents["notes.txt"])
+
+	boom := errors.New("boom")
+	config.Transform = func(path string, content []byte) ([]byte, error) {
+		if path == "notes.txt" {
+			return nil, boom
+		}
+		return content, nil
+	}
+	_, err = ProcessDirectory(config, false)
+	assert.ErrorIs(t, err, boom)
+	var transformErr *TransformError
+	require.ErrorAs(t, err, &transformErr)
+	assert.Equal(t, "notes.txt", transformErr.Path)
+}
+
+func TestCommandTransform(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+
+	out, err := CommandTransform(`tr a-z A-Z; printf '%s' "$PROMPTEXT_FILE"`)("pkg/a.go", []byte("package a\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "PACKAGE A\npkg/a.go", string(out))
+
+	_, err = CommandTransform("echo nope >&2; exit 2")("a.go", nil)
+	assert.ErrorContains(t, err, "nope")
+}
@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// BuildTreeRollup arranges files into a directory tree named rootName and
+// totals the file count and tokens beneath every directory
+func BuildTreeRollup(rootName string, files []format.FileInfo) *format.TreeRollup {
+	root := &format.TreeRollup{Name: rootName, Path: ".", Type: "dir"}
+	dirs := map[string]*format.TreeRollup{".": root}
+
+	var getDir func(p string) *format.TreeRollup
+	getDir = func(p string) *format.TreeRollup {
+		if dir, ok := dirs[p]; ok {
+			return dir
+		}
+		parent := getDir(path.Dir(p))
+		dir := &format.TreeRollup{Name: path.Base(p), Path: p, Type: "dir"}
+		parent.Children = append(parent.Children, dir)
+		dirs[p] = dir
+		return dir
+	}
+
+	for _, file := range files {
+		p := filepath.ToSlash(file.Path)
+		parent := getDir(path.Dir(p))
+		parent.Children = append(parent.Children, &format.TreeRollup{
+			Name:   path.Base(p),
+			Path:   p,
+			Type:   "file",
+			Files:  1,
+			Tokens: file.Tokens,
+		})
+	}
+
+	sumTree(root)
+	return root
+}
+
+// sumTree fills in directory totals bottom-up and sorts each level
+func sumTree(node *format.TreeRollup) {
+	if node.Type != "dir" {
+		return
+	}
+	node.Files, node.Tokens = 0, 0
+	for _, child := range node.Children {
+		sumTree(child)
+		node.Files += child.Files
+		node.Tokens += child.Tokens
+	}
+	sort.Slice(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+		if a.Type != b.Type {
+			return a.Type == "dir"
+		}
+		return a.Name < b.Name
+	})
+}
+
+// RenderTreeRollup draws the tree with box-drawing connectors, annotating
+// directories with their file count and token total and files with their
+// tokens, e.g.
+//
+//	myproject/ (3 files, ~1,250 tokens)
+//	├── cmd/ (1 file, ~900 tokens)
+//	│   └── main.go (~900 tokens)
+//	└── go.mod (~350 tokens)
+func RenderTreeRollup(root *format.TreeRollup) string {
+	var b strings.Builder
+	b.WriteString(treeLabel(root) + "\n")
+	writeTreeChildren(&b, root, "")
+	return b.String()
+}
+
+// writeTreeChildren renders node's children below prefix
+func writeTreeChildren(b *strings.Builder, node *format.TreeRollup, prefix string) {
+	for i, child := range node.Children {
+		connector, nextPrefix := "├── ", prefix+"│   "
+		if i == len(node.Children)-1 {
+			connector, nextPrefix = "└── ", prefix+"    "
+		}
+		b.WriteString(prefix + connector + treeLabel(child) + "\n")
+		writeTreeChildren(b, child, nextPrefix)
+	}
+}
+
+// treeLabel is a node's name with its annotation
+func treeLabel(node *format.TreeRollup) string {
+	if node.Type == "file" {
+		return fmt.Sprintf("%s (~%s tokens)", node.Name, formatTokenCount(node.Tokens))
+	}
+	files := "files"
+	if node.Files == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%s/ (%d %s, ~%s tokens)", node.Name, node.Files, files, formatTokenCount(node.Tokens))
+}
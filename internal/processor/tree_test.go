@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTreeRollup(t *testing.T) {
+	files := []format.FileInfo{
+		{Path: "main.go", Tokens: 100},
+		{Path: "internal/db/db.go", Tokens: 1200},
+		{Path: "internal/api/handler.go", Tokens: 300},
+		{Path: "internal/api/routes.go", Tokens: 50},
+	}
+
+	root := BuildTreeRollup("project", files)
+	assert.Equal(t, 4, root.Files)
+	assert.Equal(t, 1650, root.Tokens)
+
+	// Directories sort before files, then by name
+	require.Len(t, root.Children, 2)
+	internal := root.Children[0]
+	assert.Equal(t, "internal", internal.Path)
+	assert.Equal(t, 3, internal.Files)
+	assert.Equal(t, 1550, internal.Tokens)
+	assert.Equal(t, "main.go", root.Children[1].Name)
+
+	require.Len(t, internal.Children, 2)
+	assert.Equal(t, "internal/api", internal.Children[0].Path)
+	assert.Equal(t, 350, internal.Children[0].Tokens)
+	assert.Equal(t, "internal/db", internal.Children[1].Path)
+}
+
+func TestRenderTreeRollup(t *testing.T) {
+	root := BuildTreeRollup("project", []format.FileInfo{
+		{Path: "cmd/main.go", Tokens: 900},
+		{Path: "go.mod", Tokens: 1350},
+	})
+
+	expected := "project/ (2 files, ~2,250 tokens)\n" +
+		"├── cmd/ (1 file, ~900 tokens)\n" +
+		"│   └── main.go (~900 tokens)\n" +
+		"└── go.mod (~1,350 tokens)\n"
+	assert.Equal(t, expected, RenderTreeRollup(root))
+}
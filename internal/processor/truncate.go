@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// Truncation strategies for files above Config.MaxFileTokens
+const (
+	TruncateHead       = "head"       // Keep the beginning of the file
+	TruncateHeadTail   = "head-tail"  // Keep the beginning and the end, omitting the middle
+	TruncateSignatures = "signatures" // Keep only declaration lines (package, imports, types, funcs)
+)
+
+// signatureLine matches lines that declare something in common languages:
+// Go, JS/TS, Python, Rust, Java/C#, Ruby, PHP
+var signatureLine = regexp.MustCompile(`^\s*(?:@\w+|(?:export\s+)?(?:default\s+)?(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:package|import|from|func|type|const|var|class|interface|enum|struct|trait|impl|fn|mod|def|function|module|namespace|public|private|protected|internal|static|abstract|final)\b)`)
+
+// truncateFile shortens a file whose token count exceeds maxTokens using the
+// given strategy, recording the original size in file.Truncation. It reports
+// whether the file was truncated.
+func truncateFile(file *format.FileInfo, maxTokens int, strategy string, tokenCounter *token.TokenCounter) bool {
+	if maxTokens <= 0 || file.Tokens <= maxTokens {
+		return false
+	}
+
+	lines := strings.SplitAfter(file.Content, "\n")
+
+	// Lines are measured one at a time, which can undercount the joined text,
+	// so shrink the budget by the overshoot until the result fits
+	budget := maxTokens
+	content, mode := truncateLines(lines, budget, strategy, tokenCounter)
+	for attempt := 0; attempt < 5; attempt++ {
+		overshoot := tokenCounter.EstimateTokens(content) - maxTokens
+		if overshoot <= 0 || budget <= 0 {
+			break
+		}
+		budget -= overshoot
+		content, mode = truncateLines(lines, budget, strategy, tokenCounter)
+	}
+
+	file.Truncation = &format.TruncationInfo{Mode: mode, OriginalTokens: file.Tokens}
+	file.Content = content
+	file.Tokens = tokenCounter.EstimateTokens(content)
+	return true
+}
+
+// truncateLines applies strategy to lines within budget tokens and returns the
+// truncated content and its mode description
+func truncateLines(lines []string, budget int, strategy string, tokenCounter *token.TokenCounter) (string, string) {
+	switch strategy {
+	case TruncateHeadTail:
+		// Two thirds of the budget for the head, where the context usually lives
+		headBudget := budget * 2 / 3
+		head := fitLines(lines, headBudget, tokenCounter)
+		tail := fitLinesFromEnd(lines[head:], budget-headBudget, tokenCounter)
+		content := strings.Join(lines[:head], "") + omittedMarker(len(lines)-head-tail) + strings.Join(lines[len(lines)-tail:], "")
+		return content, fmt.Sprintf("head:%d,tail:%d", head, tail)
+
+	case TruncateSignatures:
+		var signatures []string
+		for _, line := range lines {
+			if signatureLine.MatchString(line) {
+				signatures = append(signatures, line)
+			}
+		}
+		kept := fitLines(signatures, budget, tokenCounter)
+		return strings.Join(signatures[:kept], "") + omittedMarker(len(signatures)-kept), fmt.Sprintf("signatures:%d", kept)
+
+	default: // TruncateHead
+		head := fitLines(lines, budget, tokenCounter)
+		return strings.Join(lines[:head], "") + omittedMarker(len(lines)-head), fmt.Sprintf("head:%d", head)
+	}
+}
+
+// fitLines returns how many leading lines fit in maxTokens
+func fitLines(lines []string, maxTokens int, tokenCounter *token.TokenCounter) int {
+	used := 0
+	for i, line := range lines {
+		used += tokenCounter.EstimateTokens(line)
+		if used > maxTokens {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// fitLinesFromEnd returns how many trailing lines fit in maxTokens
+func fitLinesFromEnd(lines []string, maxTokens int, tokenCounter *token.TokenCounter) int {
+	used := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		used += tokenCounter.EstimateTokens(lines[i])
+		if used > maxTokens {
+			return len(lines) - 1 - i
+		}
+	}
+	return len(lines)
+}
+
+// omittedMarker notes where lines were cut from a truncated file
+func omittedMarker(lines int) string {
+	if lines <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n... [%d lines truncated] ...\n", lines)
+}
+
+// countTruncations returns the number of truncated files
+func countTruncations(files []format.FileInfo) int {
+	count := 0
+	for _, file := range files {
+		if file.Truncation != nil {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,63 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncateFile(t *testing.T) {
+	tc := token.NewTokenCounter()
+	var sb strings.Builder
+	sb.WriteString("package big\n\nimport \"fmt\"\n\n")
+	for i := 0; i < 200; i++ {
+		sb.WriteString(fmt.Sprintf("func F%03d() {\n\tfmt.Println(\"function number %d\")\n}\n\n", i, i))
+	}
+	content := sb.String()
+	original := tc.EstimateTokens(content)
+
+	newFile := func() *format.FileInfo {
+		return &format.FileInfo{Path: "big.go", Content: content, Tokens: original}
+	}
+
+	t.Run("under the limit is untouched", func(t *testing.T) {
+		file := newFile()
+		assert.False(t, truncateFile(file, original, TruncateHead, tc))
+		assert.Nil(t, file.Truncation)
+		assert.Equal(t, content, file.Content)
+	})
+
+	t.Run("head", func(t *testing.T) {
+		file := newFile()
+		require.True(t, truncateFile(file, 200, TruncateHead, tc))
+		assert.True(t, strings.HasPrefix(file.Content, "package big\n"))
+		assert.Contains(t, file.Content, "lines truncated")
+		assert.NotContains(t, file.Content, "F199")
+		assert.Regexp(t, `^head:\d+$`, file.Truncation.Mode)
+		assert.Equal(t, original, file.Truncation.OriginalTokens)
+		assert.LessOrEqual(t, file.Tokens, 200)
+	})
+
+	t.Run("head-tail", func(t *testing.T) {
+		file := newFile()
+		require.True(t, truncateFile(file, 200, TruncateHeadTail, tc))
+		assert.True(t, strings.HasPrefix(file.Content, "package big\n"))
+		assert.Contains(t, file.Content, "F199")
+		assert.NotContains(t, file.Content, "F100")
+		assert.Regexp(t, `^head:\d+,tail:\d+$`, file.Truncation.Mode)
+	})
+
+	t.Run("signatures", func(t *testing.T) {
+		file := newFile()
+		require.True(t, truncateFile(file, 2000, TruncateSignatures, tc))
+		assert.Contains(t, file.Content, "func F000() {\n")
+		assert.Contains(t, file.Content, "import \"fmt\"\n")
+		assert.NotContains(t, file.Content, "Println")
+		assert.Regexp(t, `^signatures:\d+$`, file.Truncation.Mode)
+	})
+}
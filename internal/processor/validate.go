@@ -0,0 +1,153 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"github.com/1broseidon/promptext/internal/filter/types"
+	"github.com/1broseidon/promptext/internal/relevance"
+)
+
+// NormalizeExtensions adds a missing leading dot to extensions ("go" -> ".go")
+// and returns a warning for each extension it had to fix
+func NormalizeExtensions(extensions []string) ([]string, []string) {
+	if len(extensions) == 0 {
+		return extensions, nil
+	}
+
+	var warnings []string
+	normalized := make([]string, 0, len(extensions))
+	for _, ext := range extensions {
+		ext = strings.TrimSpace(ext)
+		if ext == "" {
+			continue
+		}
+		if !strings.HasPrefix(ext, ".") {
+			warnings = append(warnings, fmt.Sprintf("extension %q has no leading dot; using %q", ext, "."+ext))
+			ext = "." + ext
+		}
+		normalized = append(normalized, ext)
+	}
+	return normalized, warnings
+}
+
+// ValidateConfig checks a processing configuration for likely mistakes and
+// returns human-readable warnings. Validation never fails an extraction.
+// Exclude patterns that match nothing are found by the walk and reported in
+// ProcessResult.Warnings and DryRunResult.Warnings instead.
+func ValidateConfig(config Config) []string {
+	var warnings []string
+	warnings = append(warnings, validateRelevanceKeywords(config.RelevanceKeywords)...)
+	if config.DropSizeOutliers < 0 || config.DropSizeOutliers >= 100 {
+		warnings = append(warnings, fmt.Sprintf("size outlier percentile %g is outside 0-100; outlier dropping is disabled", config.DropSizeOutliers))
+	}
+	if config.FollowImports > 0 && config.Scorer == nil && !relevance.NewScorer(config.RelevanceKeywords).HasKeywords() {
+		warnings = append(warnings, "following imports has no effect without relevance keywords or a custom scorer")
+	}
+	if config.Snippets && !relevance.NewScorer(config.RelevanceKeywords).HasKeywords() {
+		warnings = append(warnings, "snippets have no effect without relevance keywords")
+	}
+	switch config.IncludeTests {
+	case "", TestsPaired:
+	default:
+		warnings = append(warnings, fmt.Sprintf("unknown test inclusion mode %q; tests are selected like other files (valid: %s)", config.IncludeTests, TestsPaired))
+	}
+	if config.IncludeTests == TestsPaired && config.Scorer == nil && !relevance.NewScorer(config.RelevanceKeywords).HasKeywords() {
+		warnings = append(warnings, "pairing tests has no effect without relevance keywords or a custom scorer")
+	}
+	if config.BoostRecent && config.MaxTokens <= 0 && config.Scorer == nil && !relevance.NewScorer(config.RelevanceKeywords).HasKeywords() {
+		warnings = append(warnings, "boosting recent files has no effect without relevance keywords, a custom scorer or a token budget")
+	}
+	switch config.TruncateStrategy {
+	case "", TruncateHead, TruncateHeadTail, TruncateSignatures:
+	default:
+		warnings = append(warnings, fmt.Sprintf("unknown truncation strategy %q; using %q (valid: %s, %s, %s)",
+			config.TruncateStrategy, TruncateHead, TruncateHead, TruncateHeadTail, TruncateSignatures))
+	}
+	return warnings
+}
+
+// excludeCheck records which exclude patterns match a path of the walk, so
+// patterns that match nothing in the project, which usually indicates a typo
+// or a pattern in the wrong syntax, are reported without walking again
+type excludeCheck struct {
+	dirPath   string
+	patterns  []excludePattern
+	remaining int
+}
+
+type excludePattern struct {
+	pattern string
+	rules   []types.Rule
+	matched bool
+}
+
+func newExcludeCheck(config Config) *excludeCheck {
+	check := &excludeCheck{dirPath: config.DirPath}
+	for _, pattern := range config.Excludes {
+		if strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		check.patterns = append(check.patterns, excludePattern{
+			pattern: pattern,
+			rules: []types.Rule{
+				rules.NewPatternRule([]string{pattern}, types.Exclude),
+				rules.NewExtensionRule([]string{pattern}, types.Exclude),
+			},
+		})
+	}
+	check.remaining = len(check.patterns)
+	return check
+}
+
+// observe marks the patterns matching path, a path of the walk
+func (c *excludeCheck) observe(path string) {
+	if c.remaining == 0 {
+		return
+	}
+	rel, err := filepath.Rel(c.dirPath, path)
+	if err != nil || rel == "." {
+		return
+	}
+	for i := range c.patterns {
+		pattern := &c.patterns[i]
+		if pattern.matched {
+			continue
+		}
+		for _, rule := range pattern.rules {
+			if rule.Match(rel) {
+				pattern.matched = true
+				c.remaining--
+				break
+			}
+		}
+	}
+}
+
+// warnings returns a warning for each pattern no path matched
+func (c *excludeCheck) warnings() []string {
+	var warnings []string
+	for _, pattern := range c.patterns {
+		if !pattern.matched {
+			warnings = append(warnings, fmt.Sprintf("exclude pattern %q does not match any files", pattern.pattern))
+		}
+	}
+	return warnings
+}
+
+// validateRelevanceKeywords warns when every relevance keyword was dropped as
+// a stopword, which silently disables relevance filtering
+func validateRelevanceKeywords(keywords string) []string {
+	if strings.TrimSpace(keywords) == "" {
+		return nil
+	}
+
+	scorer := relevance.NewScorer(keywords)
+	if scorer.HasKeywords() || len(scorer.DroppedKeywords()) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("all relevance keywords were ignored as stopwords (%s); relevance filtering is disabled",
+		strings.Join(scorer.DroppedKeywords(), ", "))}
+}
@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"os"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeExtensionsAddsLeadingDot(t *testing.T) {
+	normalized, warnings := NormalizeExtensions([]string{"go", ".js", " py "})
+
+	assert.Equal(t, []string{".go", ".js", ".py"}, normalized)
+	assert.Len(t, warnings, 2)
+	assert.Contains(t, warnings[0], `"go"`)
+	assert.Contains(t, warnings[1], `"py"`)
+}
+
+func TestNormalizeExtensionsNoWarningsWhenValid(t *testing.T) {
+	normalized, warnings := NormalizeExtensions([]string{".go"})
+
+	assert.Equal(t, []string{".go"}, normalized)
+	assert.Empty(t, warnings)
+}
+
+func TestProcessDirectoryWarnsOnUnmatchedExclude(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"main.go":          "package main",
+		"vendor/lib/a.go":  "package lib",
+		"docs/guide.md":    "# Guide",
+		"internal/x/x.go":  "package x",
+		"internal/x/x.log": "log",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	excludes := []string{"vendor/", "vnedor/", "*.log", "*.tmp"}
+	config := Config{
+		DirPath:    tmpDir,
+		Extensions: []string{".go"},
+		Excludes:   excludes,
+		Filter:     filter.New(filter.Options{Excludes: excludes, UseDefaultRules: true}),
+	}
+	assert.Empty(t, ValidateConfig(config), "the walk finds unmatched excludes")
+
+	// The processing walk and the dry run report the same patterns
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+	require.Len(t, result.Warnings, 2)
+	assert.Contains(t, result.Warnings[0], `"vnedor/"`)
+	assert.Contains(t, result.Warnings[1], `"*.tmp"`)
+
+	preview, err := PreviewDirectory(config)
+	require.NoError(t, err)
+	assert.Equal(t, result.Warnings, preview.Warnings)
+}
+
+func TestValidateConfigWarnsWhenAllKeywordsAreStopwords(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{"main.go": "package main"})
+	defer os.RemoveAll(tmpDir)
+
+	warnings := ValidateConfig(Config{DirPath: tmpDir, RelevanceKeywords: "the and of"})
+	assert.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "stopwords")
+
+	warnings = ValidateConfig(Config{DirPath: tmpDir, RelevanceKeywords: "the auth"})
+	assert.Empty(t, warnings, "a remaining keyword should not trigger a warning")
+}
@@ -0,0 +1,490 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/log"
+	"github.com/BurntSushi/toml"
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// maxNxDepth bounds how deep below the root project.json files are looked for
+const maxNxDepth = 4
+
+// workspaceScan accumulates the members of a workspace while its manifests
+// are read
+type workspaceScan struct {
+	fsys      fs.FS
+	manifests []string
+	members   []*workspaceMember
+	byPath    map[string]*workspaceMember
+}
+
+// workspaceMember is a member package and the names of everything it
+// depends on, inside the workspace or not
+type workspaceMember struct {
+	info format.WorkspacePackage
+	deps map[string]bool
+}
+
+// detectWorkspace reads the workspace manifests at the project root (go.work,
+// pnpm-workspace.yaml, package.json workspaces, a Cargo.toml [workspace] and
+// nx.json) and returns their member packages with the dependencies between
+// them, or nil when the project isn't a workspace. turbo.json is noted but
+// declares no members of its own; Turborepo uses the package manager's.
+func detectWorkspace(config Config) *format.WorkspaceInfo {
+	fsys := config.FS
+	if fsys == nil {
+		fsys = os.DirFS(config.DirPath)
+	}
+	ws := &workspaceScan{fsys: fsys, byPath: make(map[string]*workspaceMember)}
+	ws.readGoWork()
+	ws.readPnpmWorkspace()
+	ws.readNpmWorkspaces()
+	ws.readCargoWorkspace()
+	ws.readNxProjects()
+	if len(ws.members) == 0 {
+		return nil
+	}
+	if _, err := fs.Stat(fsys, "turbo.json"); err == nil {
+		ws.manifests = append(ws.manifests, "turbo.json")
+	}
+
+	names := make(map[string]bool, len(ws.members))
+	for _, member := range ws.members {
+		names[member.info.Name] = true
+	}
+	info := &format.WorkspaceInfo{Manifests: ws.manifests}
+	for _, member := range ws.members {
+		for dep := range member.deps {
+			if names[dep] && dep != member.info.Name {
+				member.info.DependsOn = append(member.info.DependsOn, dep)
+			}
+		}
+		sort.Strings(member.info.DependsOn)
+		info.Packages = append(info.Packages, member.info)
+	}
+	sort.SliceStable(info.Packages, func(i, j int) bool {
+		return info.Packages[i].Path < info.Packages[j].Path
+	})
+	return info
+}
+
+// addMember records a member at dir, merging its dependencies into an
+// earlier member at the same directory
+func (ws *workspaceScan) addMember(dir, name, ecosystem string, deps []string) {
+	member, ok := ws.byPath[dir]
+	if !ok {
+		if name == "" {
+			name = path.Base(dir)
+		}
+		member = &workspaceMember{
+			info: format.WorkspacePackage{Name: name, Path: dir, Ecosystem: ecosystem},
+			deps: make(map[string]bool),
+		}
+		ws.byPath[dir] = member
+		ws.members = append(ws.members, member)
+	}
+	for _, dep := range deps {
+		member.deps[dep] = true
+	}
+}
+
+// readGoWork adds the modules a go.work file uses
+func (ws *workspaceScan) readGoWork() {
+	data, err := fs.ReadFile(ws.fsys, "go.work")
+	if err != nil {
+		return
+	}
+	work, err := modfile.ParseWork("go.work", data, nil)
+	if err != nil {
+		return
+	}
+	ws.manifests = append(ws.manifests, "go.work")
+	for _, use := range work.Use {
+		dir, ok := memberDir(use.Path)
+		if !ok {
+			continue
+		}
+		data, err := fs.ReadFile(ws.fsys, path.Join(dir, "go.mod"))
+		if err != nil {
+			continue
+		}
+		mod, err := modfile.ParseLax(path.Join(dir, "go.mod"), data, nil)
+		if err != nil || mod.Module == nil {
+			continue
+		}
+		var deps []string
+		for _, req := range mod.Require {
+			deps = append(deps, req.Mod.Path)
+		}
+		ws.addMember(dir, mod.Module.Mod.Path, "go", deps)
+	}
+}
+
+// readPnpmWorkspace adds the packages pnpm-workspace.yaml matches
+func (ws *workspaceScan) readPnpmWorkspace() {
+	data, err := fs.ReadFile(ws.fsys, "pnpm-workspace.yaml")
+	if err != nil {
+		return
+	}
+	var manifest struct {
+		Packages []string `yaml:"packages"`
+	}
+	if yaml.Unmarshal(data, &manifest) != nil {
+		return
+	}
+	ws.manifests = append(ws.manifests, "pnpm-workspace.yaml")
+	ws.addNpmPackages(manifest.Packages)
+}
+
+// readNpmWorkspaces adds the packages of the root package.json's workspaces
+// field, as npm, Yarn and Bun read it
+func (ws *workspaceScan) readNpmWorkspaces() {
+	data, err := fs.ReadFile(ws.fsys, "package.json")
+	if err != nil {
+		return
+	}
+	var manifest struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(data, &manifest) != nil || len(manifest.Workspaces) == 0 {
+		return
+	}
+	var patterns []string
+	if json.Unmarshal(manifest.Workspaces, &patterns) != nil {
+		// Yarn's object form: {"packages": [...], "nohoist": [...]}
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if json.Unmarshal(manifest.Workspaces, &object) != nil {
+			return
+		}
+		patterns = object.Packages
+	}
+	ws.manifests = append(ws.manifests, "package.json")
+	ws.addNpmPackages(patterns)
+}
+
+// addNpmPackages adds the directories with a package.json that patterns match
+func (ws *workspaceScan) addNpmPackages(patterns []string) {
+	var include, exclude []string
+	for _, pattern := range patterns {
+		if rest, ok := strings.CutPrefix(pattern, "!"); ok {
+			exclude = append(exclude, rest)
+		} else {
+			include = append(include, pattern)
+		}
+	}
+	for _, dir := range ws.expandMembers(include, exclude, "package.json") {
+		data, err := fs.ReadFile(ws.fsys, path.Join(dir, "package.json"))
+		if err != nil {
+			continue
+		}
+		var manifest struct {
+			Name                 string            `json:"name"`
+			Dependencies         map[string]string `json:"dependencies"`
+			DevDependencies      map[string]string `json:"devDependencies"`
+			PeerDependencies     map[string]string `json:"peerDependencies"`
+			OptionalDependencies map[string]string `json:"optionalDependencies"`
+		}
+		if json.Unmarshal(data, &manifest) != nil {
+			continue
+		}
+		var deps []string
+		for _, set := range []map[string]string{manifest.Dependencies, manifest.DevDependencies, manifest.PeerDependencies, manifest.OptionalDependencies} {
+			for name := range set {
+				deps = append(deps, name)
+			}
+		}
+		ws.addMember(dir, manifest.Name, "npm", deps)
+	}
+}
+
+// readCargoWorkspace adds the crates a Cargo.toml [workspace] table lists
+func (ws *workspaceScan) readCargoWorkspace() {
+	data, err := fs.ReadFile(ws.fsys, "Cargo.toml")
+	if err != nil {
+		return
+	}
+	var manifest struct {
+		Workspace *struct {
+			Members []string `toml:"members"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"workspace"`
+	}
+	if _, err := toml.Decode(string(data), &manifest); err != nil || manifest.Workspace == nil {
+		return
+	}
+	ws.manifests = append(ws.manifests, "Cargo.toml")
+	for _, dir := range ws.expandMembers(manifest.Workspace.Members, manifest.Workspace.Exclude, "Cargo.toml") {
+		data, err := fs.ReadFile(ws.fsys, path.Join(dir, "Cargo.toml"))
+		if err != nil {
+			continue
+		}
+		var crate struct {
+			Package struct {
+				Name string `toml:"name"`
+			} `toml:"package"`
+			Dependencies      map[string]interface{} `toml:"dependencies"`
+			DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+			BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+		}
+		if _, err := toml.Decode(string(data), &crate); err != nil || crate.Package.Name == "" {
+			continue
+		}
+		var deps []string
+		for _, set := range []map[string]interface{}{crate.Dependencies, crate.DevDependencies, crate.BuildDependencies} {
+			for key, spec := range set {
+				// A renamed dependency names the crate in its package key
+				if table, ok := spec.(map[string]interface{}); ok {
+					if name, ok := table["package"].(string); ok {
+						key = name
+					}
+				}
+				deps = append(deps, key)
+			}
+		}
+		ws.addMember(dir, crate.Package.Name, "cargo", deps)
+	}
+}
+
+// readNxProjects adds the projects of an Nx workspace, found by their
+// project.json files. Their implicitDependencies are merged into members the
+// package manager already declared at the same directory.
+func (ws *workspaceScan) readNxProjects() {
+	if _, err := fs.Stat(ws.fsys, "nx.json"); err != nil {
+		return
+	}
+	found := false
+	fs.WalkDir(ws.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if name != "." && (skipWorkspaceDir(d.Name()) || strings.Count(name, "/") >= maxNxDepth) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		dir := path.Dir(name)
+		if d.Name() != "project.json" || dir == "." {
+			return nil
+		}
+		data, err := fs.ReadFile(ws.fsys, name)
+		if err != nil {
+			return nil
+		}
+		var project struct {
+			Name                 string   `json:"name"`
+			ImplicitDependencies []string `json:"implicitDependencies"`
+		}
+		if json.Unmarshal(data, &project) != nil {
+			return nil
+		}
+		var deps []string
+		for _, dep := range project.ImplicitDependencies {
+			if !strings.HasPrefix(dep, "!") {
+				deps = append(deps, dep)
+			}
+		}
+		ws.addMember(dir, project.Name, "nx", deps)
+		found = true
+		return nil
+	})
+	if found {
+		ws.manifests = append(ws.manifests, "nx.json")
+	}
+}
+
+// expandMembers returns the directories matching the member patterns that
+// hold manifest, in pattern order, leaving out those an exclude pattern
+// matches. A "**" segment matches any depth below the part before it.
+func (ws *workspaceScan) expandMembers(patterns, excludes []string, manifest string) []string {
+	var dirs []string
+	seen := make(map[string]bool)
+	add := func(dir string) {
+		if seen[dir] || matchesMember(dir, excludes) {
+			return
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	for _, pattern := range patterns {
+		pattern, ok := memberDir(pattern)
+		if !ok {
+			continue
+		}
+		if prefix, _, deep := strings.Cut(pattern, "**"); deep {
+			prefix = strings.TrimSuffix(prefix, "/")
+			if prefix == "" {
+				prefix = "."
+			}
+			fs.WalkDir(ws.fsys, prefix, func(name string, d fs.DirEntry, err error) error {
+				if err != nil || !d.IsDir() {
+					return nil
+				}
+				if name != prefix && skipWorkspaceDir(d.Name()) {
+					return fs.SkipDir
+				}
+				if _, err := fs.Stat(ws.fsys, path.Join(name, manifest)); err == nil && name != "." {
+					add(name)
+				}
+				return nil
+			})
+			continue
+		}
+		matches, _ := fs.Glob(ws.fsys, path.Join(pattern, manifest))
+		for _, match := range matches {
+			add(path.Dir(match))
+		}
+	}
+	return dirs
+}
+
+// matchesMember reports whether one of the patterns matches dir, or a
+// directory above it for patterns ending in "**"
+func matchesMember(dir string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern, ok := memberDir(pattern)
+		if !ok {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok && (dir == prefix || strings.HasPrefix(dir, prefix+"/")) {
+			return true
+		}
+		if matched, _ := path.Match(pattern, dir); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// memberDir cleans a member path or pattern from a manifest into a slash
+// path relative to the root, or reports false for one outside it
+func memberDir(p string) (string, bool) {
+	p = path.Clean(filepath.ToSlash(strings.TrimSpace(p)))
+	if p == ".." || strings.HasPrefix(p, "../") || path.IsAbs(p) {
+		return "", false
+	}
+	return p, true
+}
+
+// skipWorkspaceDir reports whether member lookups skip a directory:
+// installed dependencies, build output and hidden directories
+func skipWorkspaceDir(name string) bool {
+	return name == "node_modules" || name == "target" || name == "dist" || strings.HasPrefix(name, ".")
+}
+
+// countWorkspaceFiles totals the included files and tokens of each member,
+// counting a file toward the deepest member directory holding it
+func countWorkspaceFiles(info *format.WorkspaceInfo, files []format.FileInfo) {
+	for i := range info.Packages {
+		info.Packages[i].Files, info.Packages[i].Tokens = 0, 0
+	}
+	for _, file := range files {
+		if file.External {
+			continue
+		}
+		p := filepath.ToSlash(file.Path)
+		best := -1
+		for i, pkg := range info.Packages {
+			if (pkg.Path == "." || strings.HasPrefix(p, pkg.Path+"/")) && (best < 0 || len(pkg.Path) > len(info.Packages[best].Path)) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			info.Packages[best].Files++
+			info.Packages[best].Tokens += file.Tokens
+		}
+	}
+}
+
+// workspaceRoots returns the roots that extract the workspace package name,
+// matched by name, path or last element of its name, along with every
+// member it depends on, transitively, and the workspace manifests
+func workspaceRoots(info *format.WorkspaceInfo, name string) ([]string, error) {
+	if info == nil {
+		return nil, fmt.Errorf("package %q: no workspace found (go.work, pnpm-workspace.yaml, package.json workspaces, Cargo.toml [workspace] or nx.json)", name)
+	}
+	byName := make(map[string]format.WorkspacePackage, len(info.Packages))
+	for _, pkg := range info.Packages {
+		byName[pkg.Name] = pkg
+	}
+	var candidates []format.WorkspacePackage
+	for _, pkg := range info.Packages {
+		if pkg.Name == name || pkg.Path == strings.TrimSuffix(filepath.ToSlash(name), "/") {
+			candidates = []format.WorkspacePackage{pkg}
+			break
+		}
+		if path.Base(pkg.Name) == name {
+			candidates = append(candidates, pkg)
+		}
+	}
+	if len(candidates) != 1 {
+		var names []string
+		for _, pkg := range info.Packages {
+			names = append(names, pkg.Name)
+		}
+		if len(candidates) > 1 {
+			return nil, fmt.Errorf("package %q is ambiguous in the workspace (packages: %s)", name, strings.Join(names, ", "))
+		}
+		return nil, fmt.Errorf("unknown workspace package %q (packages: %s)", name, strings.Join(names, ", "))
+	}
+
+	var dirs []string
+	visited := make(map[string]bool)
+	var visit func(pkg format.WorkspacePackage)
+	visit = func(pkg format.WorkspacePackage) {
+		if visited[pkg.Name] {
+			return
+		}
+		visited[pkg.Name] = true
+		dirs = append(dirs, pkg.Path)
+		for _, dep := range pkg.DependsOn {
+			visit(byName[dep])
+		}
+	}
+	visit(candidates[0])
+
+	// Keep only the outermost directories so no file is walked twice
+	sort.Strings(dirs)
+	var roots []string
+	for _, dir := range dirs {
+		if dir == "." {
+			return []string{"."}, nil
+		}
+		nested := false
+		for _, root := range roots {
+			nested = nested || strings.HasPrefix(dir, root+"/")
+		}
+		if !nested {
+			roots = append(roots, dir)
+		}
+	}
+	return append(roots, info.Manifests...), nil
+}
+
+// withWorkspacePackage returns config with Roots narrowed to
+// config.WorkspacePackage and the members it depends on
+func withWorkspacePackage(config Config, info *format.WorkspaceInfo) (Config, error) {
+	if len(config.Roots) > 0 {
+		return config, fmt.Errorf("package %q: a workspace package can't be combined with other roots", config.WorkspacePackage)
+	}
+	roots, err := workspaceRoots(info, config.WorkspacePackage)
+	if err != nil {
+		return config, err
+	}
+	log.Debug("Workspace package %s: processing %s", config.WorkspacePackage, strings.Join(roots, ", "))
+	config.Roots = roots
+	return config, nil
+}
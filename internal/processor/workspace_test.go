@@ -0,0 +1,169 @@
+package processor
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectWorkspace(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+		want  *format.WorkspaceInfo
+	}{
+		{
+			name: "go.work",
+			files: fstest.MapFS{
+				"go.work":          {Data: []byte("go 1.22\n\nuse (\n\t./auth\n\t./shared\n\t../outside\n)\n")},
+				"auth/go.mod":      {Data: []byte("module example.com/mono/auth\n\ngo 1.22\n\nrequire (\n\texample.com/mono/shared v0.0.0\n\tgithub.com/google/uuid v1.6.0\n)\n")},
+				"shared/go.mod":    {Data: []byte("module example.com/mono/shared\n\ngo 1.22\n")},
+				"unused/go.mod":    {Data: []byte("module example.com/mono/unused\n")},
+				"auth/main.go":     {Data: []byte("package main\n")},
+				"shared/shared.go": {Data: []byte("package shared\n")},
+			},
+			want: &format.WorkspaceInfo{
+				Manifests: []string{"go.work"},
+				Packages: []format.WorkspacePackage{
+					{Name: "example.com/mono/auth", Path: "auth", Ecosystem: "go", DependsOn: []string{"example.com/mono/shared"}},
+					{Name: "example.com/mono/shared", Path: "shared", Ecosystem: "go"},
+				},
+			},
+		},
+		{
+			name: "pnpm with turbo",
+			files: fstest.MapFS{
+				"pnpm-workspace.yaml":                     {Data: []byte("packages:\n  - 'apps/*'\n  - 'packages/**'\n  - '!packages/legacy'\n")},
+				"turbo.json":                              {Data: []byte("{}")},
+				"apps/web/package.json":                   {Data: []byte(`{"name": "web", "dependencies": {"@acme/ui": "workspace:*", "react": "^18.0.0"}}`)},
+				"packages/ui/package.json":                {Data: []byte(`{"name": "@acme/ui", "devDependencies": {"@acme/config": "workspace:*"}}`)},
+				"packages/tools/config/package.json":      {Data: []byte(`{"name": "@acme/config"}`)},
+				"packages/legacy/package.json":            {Data: []byte(`{"name": "legacy"}`)},
+				"packages/ui/node_modules/x/package.json": {Data: []byte(`{"name": "x"}`)},
+			},
+			want: &format.WorkspaceInfo{
+				Manifests: []string{"pnpm-workspace.yaml", "turbo.json"},
+				Packages: []format.WorkspacePackage{
+					{Name: "web", Path: "apps/web", Ecosystem: "npm", DependsOn: []string{"@acme/ui"}},
+					{Name: "@acme/config", Path: "packages/tools/config", Ecosystem: "npm"},
+					{Name: "@acme/ui", Path: "packages/ui", Ecosystem: "npm", DependsOn: []string{"@acme/config"}},
+				},
+			},
+		},
+		{
+			name: "yarn workspaces object with nx",
+			files: fstest.MapFS{
+				"package.json":                 {Data: []byte(`{"private": true, "workspaces": {"packages": ["libs/*"]}}`)},
+				"nx.json":                      {Data: []byte("{}")},
+				"libs/core/package.json":       {Data: []byte(`{"name": "core"}`)},
+				"libs/core/project.json":       {Data: []byte(`{"name": "core", "implicitDependencies": ["e2e-utils", "!web"]}`)},
+				"tools/e2e-utils/project.json": {Data: []byte(`{"implicitDependencies": []}`)},
+			},
+			want: &format.WorkspaceInfo{
+				Manifests: []string{"package.json", "nx.json"},
+				Packages: []format.WorkspacePackage{
+					{Name: "core", Path: "libs/core", Ecosystem: "npm", DependsOn: []string{"e2e-utils"}},
+					{Name: "e2e-utils", Path: "tools/e2e-utils", Ecosystem: "nx"},
+				},
+			},
+		},
+		{
+			name: "cargo",
+			files: fstest.MapFS{
+				"Cargo.toml":                 {Data: []byte("[workspace]\nmembers = [\"crates/*\"]\nexclude = [\"crates/scratch\"]\n")},
+				"crates/api/Cargo.toml":      {Data: []byte("[package]\nname = \"api\"\n\n[dependencies]\nmodel = { path = \"../model\" }\nserde = \"1\"\n\n[dev-dependencies]\nfixtures = { package = \"test-fixtures\", path = \"../fixtures\" }\n")},
+				"crates/model/Cargo.toml":    {Data: []byte("[package]\nname = \"model\"\n")},
+				"crates/fixtures/Cargo.toml": {Data: []byte("[package]\nname = \"test-fixtures\"\n")},
+				"crates/scratch/Cargo.toml":  {Data: []byte("[package]\nname = \"scratch\"\n")},
+			},
+			want: &format.WorkspaceInfo{
+				Manifests: []string{"Cargo.toml"},
+				Packages: []format.WorkspacePackage{
+					{Name: "api", Path: "crates/api", Ecosystem: "cargo", DependsOn: []string{"model", "test-fixtures"}},
+					{Name: "test-fixtures", Path: "crates/fixtures", Ecosystem: "cargo"},
+					{Name: "model", Path: "crates/model", Ecosystem: "cargo"},
+				},
+			},
+		},
+		{
+			name:  "not a workspace",
+			files: fstest.MapFS{"go.mod": {Data: []byte("module example.com/app\n")}, "package.json": {Data: []byte(`{"name": "app"}`)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectWorkspace(Config{DirPath: "/project", FS: tt.files}))
+		})
+	}
+}
+
+func TestWorkspaceRoots(t *testing.T) {
+	info := &format.WorkspaceInfo{
+		Manifests: []string{"go.work"},
+		Packages: []format.WorkspacePackage{
+			{Name: "example.com/mono/auth-service", Path: "services/auth", DependsOn: []string{"example.com/mono/db"}},
+			{Name: "example.com/mono/billing", Path: "services/billing", DependsOn: []string{"example.com/mono/db"}},
+			{Name: "example.com/mono/db", Path: "libs/db", DependsOn: []string{"example.com/mono/db/migrations"}},
+			{Name: "example.com/mono/db/migrations", Path: "libs/db/migrations"},
+		},
+	}
+	roots, err := workspaceRoots(info, "auth-service")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libs/db", "services/auth", "go.work"}, roots)
+
+	roots, err = workspaceRoots(info, "services/billing/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"libs/db", "services/billing", "go.work"}, roots)
+
+	_, err = workspaceRoots(info, "payments")
+	assert.ErrorContains(t, err, `unknown workspace package "payments"`)
+	_, err = workspaceRoots(nil, "auth")
+	assert.ErrorContains(t, err, "no workspace found")
+}
+
+func TestProcessDirectoryWorkspacePackage(t *testing.T) {
+	tmpDir := setupTestProject(t, map[string]string{
+		"package.json":                   `{"private": true, "workspaces": ["apps/*", "libs/*"]}`,
+		"apps/auth-service/package.json": `{"name": "auth-service", "dependencies": {"@acme/db": "*"}}`,
+		"apps/auth-service/index.js":     "require('@acme/db')\n",
+		"apps/billing/package.json":      `{"name": "billing"}`,
+		"apps/billing/index.js":          "module.exports = {}\n",
+		"libs/db/package.json":           `{"name": "@acme/db"}`,
+		"libs/db/index.js":               "module.exports = { query() {} }\n",
+		"README.md":                      "# Monorepo\n",
+	})
+	defer os.RemoveAll(tmpDir)
+
+	config := Config{
+		DirPath:          tmpDir,
+		Extensions:       []string{".js", ".json", ".md"},
+		Filter:           filter.New(filter.Options{UseDefaultRules: true}),
+		WorkspacePackage: "auth-service",
+	}
+	result, err := ProcessDirectory(config, false)
+	require.NoError(t, err)
+
+	var paths []string
+	for _, file := range result.ProjectOutput.Files {
+		paths = append(paths, file.Path)
+	}
+	assert.ElementsMatch(t, []string{"apps/auth-service/index.js", "apps/auth-service/package.json", "libs/db/index.js", "libs/db/package.json", "package.json"}, paths)
+
+	workspace := result.ProjectOutput.Workspace
+	require.NotNil(t, workspace)
+	require.Len(t, workspace.Packages, 3)
+	assert.Equal(t, []string{"@acme/db"}, workspace.Packages[0].DependsOn)
+	assert.Equal(t, 2, workspace.Packages[0].Files)
+	assert.Positive(t, workspace.Packages[0].Tokens)
+	assert.Equal(t, "billing", workspace.Packages[1].Name)
+	assert.Zero(t, workspace.Packages[1].Files)
+
+	config.WorkspacePackage = "payments"
+	_, err = ProcessDirectory(config, false)
+	assert.ErrorContains(t, err, "unknown workspace package")
+}
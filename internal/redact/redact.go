@@ -0,0 +1,153 @@
+// Package redact finds credentials in file content and replaces them with
+// placeholders before the content is handed to an AI model.
+package redact
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rule detects one kind of secret
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+
+	// Group selects the submatch to replace; 0 replaces the whole match, so
+	// a rule can keep surrounding context such as the variable name
+	Group int
+
+	// Applies, when set, limits the rule to matching file paths
+	Applies func(path string) bool
+}
+
+// DefaultRules are the built-in detectors, applied in order
+var DefaultRules = []Rule{
+	{
+		Name:    "private-key",
+		Pattern: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		Name:    "aws-access-key",
+		Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`),
+	},
+	{
+		Name:    "aws-secret-key",
+		Pattern: regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+		Group:   1,
+	},
+	{
+		Name:    "github-token",
+		Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	},
+	{
+		Name:    "slack-token",
+		Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`),
+	},
+	{
+		Name:    "stripe-key",
+		Pattern: regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`),
+	},
+	{
+		Name:    "google-api-key",
+		Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`),
+	},
+	{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`),
+	},
+	{
+		Name:    "env-value",
+		Pattern: regexp.MustCompile(`(?m)^[ \t]*(?:export[ \t]+)?[A-Za-z_][A-Za-z0-9_]*[ \t]*=[ \t]*(\S[^\r\n]*)$`),
+		Group:   1,
+		Applies: isEnvFile,
+	},
+	{
+		Name:    "secret-assignment",
+		Pattern: regexp.MustCompile(`(?i)\b(?:password|passwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token|client[_-]?secret)\b["']?\s*[:=]\s*["']([^"'\s]{8,})["']`),
+		Group:   1,
+	},
+}
+
+// placeholderPrefix starts every placeholder, so redacted text is never matched again
+const placeholderPrefix = "[REDACTED:"
+
+// Placeholder returns the text that replaces a secret found by the named rule
+func Placeholder(rule string) string {
+	return placeholderPrefix + rule + "]"
+}
+
+// Redact replaces secrets in content using DefaultRules. It returns the
+// redacted content and the number of redactions per rule name (nil if none).
+func Redact(path, content string) (string, map[string]int) {
+	return RedactWith(DefaultRules, path, content)
+}
+
+// RedactWith is like Redact with a custom rule set
+func RedactWith(rules []Rule, path, content string) (string, map[string]int) {
+	var counts map[string]int
+	for _, rule := range rules {
+		if rule.Applies != nil && !rule.Applies(path) {
+			continue
+		}
+		var n int
+		content, n = apply(rule, content)
+		if n > 0 {
+			if counts == nil {
+				counts = make(map[string]int)
+			}
+			counts[rule.Name] += n
+		}
+	}
+	return content, counts
+}
+
+// apply replaces every match of rule in content and returns the match count
+func apply(rule Rule, content string) (string, int) {
+	matches := rule.Pattern.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content, 0
+	}
+
+	placeholder := Placeholder(rule.Name)
+	var b strings.Builder
+	last, n := 0, 0
+	for _, m := range matches {
+		start, end := m[2*rule.Group], m[2*rule.Group+1]
+		if start < 0 || strings.Contains(content[start:end], placeholderPrefix) {
+			continue
+		}
+		b.WriteString(content[last:start])
+		b.WriteString(placeholder)
+		last = end
+		n++
+	}
+	b.WriteString(content[last:])
+	return b.String(), n
+}
+
+// Total sums per-rule redaction counts
+func Total(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// RuleNames returns the names in counts, sorted
+func RuleNames(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isEnvFile reports whether path is a dotenv file (.env, .env.local, prod.env)
+func isEnvFile(path string) bool {
+	base := filepath.Base(path)
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env")
+}
@@ -0,0 +1,116 @@
+package relevance
+
+import "strings"
+
+// SynonymWeight scales the score of a match on a keyword's synonym, so files
+// naming the keyword itself still rank first
+const SynonymWeight = 0.5
+
+// builtinSynonyms maps general keywords to the more specific words code
+// uses for the same concern. Expansion goes one way: "auth" also matches
+// "login", but "login" doesn't match everything under auth/.
+var builtinSynonyms = map[string][]string{
+	"auth":       {"login", "logout", "signin", "signup", "oauth", "credential", "password", "jwt"},
+	"login":      {"signin", "logon"},
+	"permission": {"role", "acl", "rbac", "policy", "access"},
+	"user":       {"account", "profile", "member"},
+	"database":   {"sql", "query", "migration", "schema", "repository", "orm"},
+	"db":         {"database", "sql", "query", "migration", "schema", "repository", "orm"},
+	"config":     {"settings", "options", "preferences", "env"},
+	"error":      {"exception", "failure", "panic"},
+	"api":        {"endpoint", "handler", "route", "controller", "rest", "graphql"},
+	"cache":      {"memoize", "lru", "redis"},
+	"delete":     {"remove", "destroy"},
+	"email":      {"mail", "smtp"},
+	"payment":    {"billing", "invoice", "checkout", "stripe", "subscription"},
+	"queue":      {"message", "broker", "pubsub", "worker", "job"},
+	"search":     {"lookup", "index", "query"},
+	"test":       {"spec", "fixture", "mock"},
+	"ui":         {"view", "component", "frontend", "template"},
+	"deploy":     {"release", "rollout"},
+}
+
+// ExpandOptions controls how keywords are widened before matching
+type ExpandOptions struct {
+	// Exact matches keywords as given, without stemming or synonyms
+	Exact bool
+
+	// Synonyms adds words that a keyword also matches, on top of the
+	// built-in ones, e.g. {"auth": {"sso", "saml"}}
+	Synonyms map[string][]string
+}
+
+// term is a word a keyword matches and the share of the keyword's weight
+// a match on it earns
+type term struct {
+	text   string
+	weight float64
+}
+
+// expandKeyword returns the terms keyword matches: its stem at full weight
+// and the stems of its synonyms at SynonymWeight. Synonyms shorter than
+// three letters are left out, as they would match inside too many words.
+func expandKeyword(keyword string, opts ExpandOptions) []term {
+	if opts.Exact {
+		return []term{{text: keyword, weight: 1}}
+	}
+	terms := []term{{text: stem(keyword), weight: 1}}
+	seen := map[string]bool{terms[0].text: true}
+
+	synonyms := append([]string{}, builtinSynonyms[terms[0].text]...)
+	for word, extra := range opts.Synonyms {
+		if strings.EqualFold(word, keyword) {
+			synonyms = append(synonyms, extra...)
+		}
+	}
+	for _, synonym := range synonyms {
+		synonym = stem(strings.ToLower(strings.TrimSpace(synonym)))
+		if len(synonym) < 3 || seen[synonym] || strings.Contains(synonym, terms[0].text) {
+			continue // The keyword already matches everything such a synonym would
+		}
+		seen[synonym] = true
+		terms = append(terms, term{text: synonym, weight: SynonymWeight})
+	}
+	return terms
+}
+
+// suffixes are the endings stem removes, longest first
+var suffixes = []string{
+	"ational", "ization", "ations", "ation", "ments", "ment", "ness",
+	"ings", "ing", "ied", "ies", "ers", "er", "ed", "es", "ly", "s",
+}
+
+// stem cuts a common English suffix off word, so "authentication" also
+// matches "authenticate" and "users" matches "user". The stem is always a
+// prefix of word and keeps at least four letters; shorter words are
+// returned as is.
+func stem(word string) string {
+	for _, suffix := range suffixes {
+		if !strings.HasSuffix(word, suffix) || len(word)-len(suffix) < 4 {
+			continue
+		}
+		if suffix == "s" && strings.HasSuffix(word, "ss") {
+			continue // "class", "address"
+		}
+		return strings.TrimSuffix(word, suffix)
+	}
+	return word
+}
+
+// Expansions returns, for each keyword that expansion widened, the words it
+// matches besides itself, for logging and explanations
+func (s *Scorer) Expansions() map[string][]string {
+	expansions := make(map[string][]string)
+	for i, keyword := range s.keywords {
+		var words []string
+		for _, t := range s.terms[i] {
+			if t.text != keyword {
+				words = append(words, t.text)
+			}
+		}
+		if len(words) > 0 {
+			expansions[keyword] = words
+		}
+	}
+	return expansions
+}
@@ -0,0 +1,41 @@
+package relevance
+
+import (
+	"strings"
+	"unicode"
+)
+
+// questionWords are words common in questions about code that say nothing
+// about where the answer is
+var questionWords = map[string]bool{
+	"about": true, "all": true, "any": true, "can": true, "could": true,
+	"did": true, "do": true, "does": true, "done": true, "explain": true,
+	"find": true, "get": true, "has": true, "have": true, "i": true,
+	"if": true, "me": true, "my": true, "our": true, "should": true,
+	"show": true, "tell": true, "there": true, "we": true, "when": true,
+	"which": true, "who": true, "why": true, "would": true, "you": true,
+	"code": true, "codebase": true, "file": true, "files": true,
+	"function": true, "project": true, "happen": true, "happens": true,
+}
+
+// QuestionKeywords derives relevance keywords from a natural-language
+// question: its words, without punctuation, stopwords, question words, or
+// repeats, in the order they appear. Identifiers such as user_id or
+// http.Client are kept whole.
+func QuestionKeywords(question string) []string {
+	words := strings.FieldsFunc(question, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' && r != '.'
+	})
+
+	seen := make(map[string]bool)
+	var keywords []string
+	for _, word := range words {
+		word = strings.ToLower(strings.Trim(word, "-."))
+		if len(word) < 2 || stopwords[word] || questionWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		keywords = append(keywords, word)
+	}
+	return keywords
+}
@@ -1,6 +1,7 @@
 package relevance
 
 import (
+	"fmt"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -20,13 +21,72 @@ type ScoredFile struct {
 	Score float64
 }
 
+// stopwords are common words that carry no relevance signal and would match
+// almost every file's content; they are dropped from keyword lists
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "how": true, "in": true,
+	"is": true, "it": true, "of": true, "on": true, "or": true, "that": true,
+	"the": true, "this": true, "to": true, "was": true, "what": true,
+	"where": true, "with": true,
+}
+
+// FileScorer scores a single file; 0 means unrelated, and scores at or above
+// GetRelevanceThreshold mark a file as highly relevant
+type FileScorer interface {
+	ScoreFile(path, content string) float64
+}
+
+// BatchScorer scores a whole file set at once, for scorers backed by an
+// external service such as an embedding model. It returns one score per file,
+// on the same scale as FileScorer.
+type BatchScorer interface {
+	Score(files []FileContent) ([]float64, error)
+}
+
+// StaticScorer serves scores computed ahead of time, keyed by file path
+type StaticScorer map[string]float64
+
+// ScoreFile returns the precomputed score for path, or 0 if there is none
+func (s StaticScorer) ScoreFile(path, content string) float64 {
+	return s[path]
+}
+
+// Precompute runs a batch scorer over files and returns the scores as a FileScorer
+func Precompute(scorer BatchScorer, files []FileContent) (StaticScorer, error) {
+	scores, err := scorer.Score(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(scores) != len(files) {
+		return nil, fmt.Errorf("scorer returned %d scores for %d files", len(scores), len(files))
+	}
+	static := make(StaticScorer, len(files))
+	for i, file := range files {
+		static[file.Path] = scores[i]
+	}
+	return static, nil
+}
+
 // Scorer handles relevance scoring for files based on keywords
 type Scorer struct {
-	keywords []string
+	keywords  []string
+	terms     [][]term // What each keyword matches, see expandKeyword
+	negatives []string // Stems of the negated keywords ("-test")
+	dropped   []string // Keywords removed as stopwords
 }
 
-// NewScorer creates a new scorer with parsed keywords
+// NewScorer creates a new scorer with parsed keywords, each widened to its
+// stem and built-in synonyms
 func NewScorer(keywordString string) *Scorer {
+	return NewScorerWith(keywordString, ExpandOptions{})
+}
+
+// NewScorerWith creates a new scorer with parsed keywords, expanded as opts
+// says. Keywords starting with "-" are negative: files whose path contains
+// one score 0, and mentions in the content lower a file's score (see
+// Breakdown.Penalty). Negative keywords are stemmed but get no synonyms.
+func NewScorerWith(keywordString string, opts ExpandOptions) *Scorer {
 	if keywordString == "" {
 		return &Scorer{keywords: []string{}}
 	}
@@ -37,28 +97,101 @@ func NewScorer(keywordString string) *Scorer {
 
 	// Normalize keywords to lowercase for case-insensitive matching
 	keywords := make([]string, 0, len(parts))
+	var negatives, dropped []string
 	for _, kw := range parts {
-		if normalized := strings.ToLower(strings.TrimSpace(kw)); normalized != "" {
-			keywords = append(keywords, normalized)
+		normalized := strings.ToLower(strings.TrimSpace(kw))
+		negative := strings.HasPrefix(normalized, "-")
+		normalized = strings.TrimLeft(normalized, "-")
+		if normalized == "" {
+			continue
 		}
+		if stopwords[normalized] {
+			dropped = append(dropped, normalized)
+			continue
+		}
+		if negative {
+			if !opts.Exact {
+				normalized = stem(normalized)
+			}
+			negatives = append(negatives, normalized)
+			continue
+		}
+		keywords = append(keywords, normalized)
 	}
 
-	return &Scorer{keywords: keywords}
+	terms := make([][]term, len(keywords))
+	for i, keyword := range keywords {
+		terms[i] = expandKeyword(keyword, opts)
+	}
+	return &Scorer{keywords: keywords, terms: terms, negatives: negatives, dropped: dropped}
 }
 
 // HasKeywords returns true if scorer has any keywords configured
 func (s *Scorer) HasKeywords() bool {
-	return len(s.keywords) > 0
+	return len(s.keywords) > 0 || len(s.negatives) > 0
+}
+
+// Keywords returns the keywords, lowercased, as given before expansion
+func (s *Scorer) Keywords() []string {
+	return s.keywords
+}
+
+// NegativeKeywords returns the stems of the negative keywords
+func (s *Scorer) NegativeKeywords() []string {
+	return s.negatives
+}
+
+// Negated reports whether path contains a negative keyword, which excludes
+// the file however well it matches the other keywords
+func (s *Scorer) Negated(path string) bool {
+	pathLower := strings.ToLower(filepath.ToSlash(path))
+	for _, negative := range s.negatives {
+		if strings.Contains(pathLower, negative) {
+			return true
+		}
+	}
+	return false
+}
+
+// DroppedKeywords returns the keywords that were ignored as stopwords
+func (s *Scorer) DroppedKeywords() []string {
+	return s.dropped
+}
+
+// Breakdown splits a keyword score into the factors that produced it
+type Breakdown struct {
+	Filename  float64 // Keyword matches in the file name
+	Directory float64 // Keyword matches in the directory path
+	Imports   float64 // Keyword matches in import statements
+	Content   float64 // Keyword occurrences in the content (capped per keyword)
+
+	// Base is the score of every file not excluded when all keywords are
+	// negative, so such files are kept
+	Base float64
+
+	// Penalty is what negative keyword occurrences in the content take off,
+	// zero or less: ContentWeight per occurrence, capped at 10 per keyword and
+	// at half the rest of the score, so mentions alone never exclude a file
+	Penalty float64
+}
+
+// Total is the relevance score the breakdown adds up to
+func (b Breakdown) Total() float64 {
+	return b.Filename + b.Directory + b.Imports + b.Content + b.Base + b.Penalty
 }
 
 // ScoreFile calculates relevance score for a single file
 // Returns 0 if no keywords are configured
 func (s *Scorer) ScoreFile(path, content string) float64 {
-	if !s.HasKeywords() {
-		return 0
-	}
+	return s.Explain(path, content).Total()
+}
 
-	score := 0.0
+// Explain scores a file like ScoreFile, keeping each factor's contribution
+func (s *Scorer) Explain(path, content string) Breakdown {
+	var b Breakdown
+	if !s.HasKeywords() || s.Negated(path) {
+		return b
+	}
 
 	// Extract and normalize components for scoring (normalize once)
 	filename := filepath.Base(path)
@@ -67,32 +200,52 @@ func (s *Scorer) ScoreFile(path, content string) float64 {
 	dirLower := strings.ToLower(dir)
 	contentLower := strings.ToLower(content)
 
-	// Score each keyword
-	for _, keyword := range s.keywords {
-		// 1. Filename matches (highest weight)
-		if strings.Contains(filenameLower, keyword) {
-			score += FilenameWeight
+	// Score each keyword by its best matching term in each factor, so an
+	// exact match scores as it would without expansion
+	for i, keyword := range s.keywords {
+		terms := s.terms[i]
+		if terms == nil {
+			terms = []term{{text: keyword, weight: 1}}
 		}
 
-		// 2. Directory/package name matches
-		if strings.Contains(dirLower, keyword) {
-			score += DirectoryWeight
-		}
+		var filenameMatch, dirMatch, imports, contentMatches float64
+		for _, t := range terms {
+			// 1. Filename matches (highest weight)
+			if strings.Contains(filenameLower, t.text) {
+				filenameMatch = max(filenameMatch, t.weight)
+			}
 
-		// 3. Import statement matches
-		importScore := s.scoreImports(content, keyword)
-		score += float64(importScore) * ImportWeight
+			// 2. Directory/package name matches
+			if strings.Contains(dirLower, t.text) {
+				dirMatch = max(dirMatch, t.weight)
+			}
 
-		// 4. Content matches (lowest weight)
-		// Count occurrences but cap at 10 to prevent single keyword spam from dominating
-		contentMatches := strings.Count(contentLower, keyword)
-		if contentMatches > 10 {
-			contentMatches = 10
+			// 3. Import statement matches
+			imports = max(imports, float64(s.scoreImports(content, t.text))*t.weight)
+
+			// 4. Content matches (lowest weight)
+			contentMatches = max(contentMatches, float64(strings.Count(contentLower, t.text))*t.weight)
 		}
-		score += float64(contentMatches) * ContentWeight
+		b.Filename += filenameMatch * FilenameWeight
+		b.Directory += dirMatch * DirectoryWeight
+		b.Imports += imports * ImportWeight
+
+		// Cap content matches at 10 to prevent single keyword spam from dominating
+		b.Content += min(contentMatches, 10) * ContentWeight
+	}
+
+	if len(s.keywords) == 0 {
+		b.Base = ContentWeight
+	}
+	var penalty float64
+	for _, negative := range s.negatives {
+		penalty += float64(min(strings.Count(contentLower, negative), 10)) * ContentWeight
+	}
+	if penalty > 0 {
+		b.Penalty = -min(penalty, b.Total()/2)
 	}
 
-	return score
+	return b
 }
 
 // scoreImports counts keyword matches in import statements
@@ -1,6 +1,7 @@
 package relevance
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -45,6 +46,11 @@ func TestNewScorer_KeywordParsing(t *testing.T) {
 			input:    "authentication",
 			expected: []string{"authentication"},
 		},
+		{
+			name:     "Stopwords dropped",
+			input:    "how the login works",
+			expected: []string{"login", "works"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -62,6 +68,17 @@ func TestNewScorer_KeywordParsing(t *testing.T) {
 	}
 }
 
+func TestNewScorer_DroppedKeywords(t *testing.T) {
+	scorer := NewScorer("the and of")
+	if scorer.HasKeywords() {
+		t.Errorf("Expected no keywords after stopword removal, got %v", scorer.keywords)
+	}
+	dropped := scorer.DroppedKeywords()
+	if len(dropped) != 3 || dropped[0] != "the" || dropped[1] != "and" || dropped[2] != "of" {
+		t.Errorf("Expected dropped keywords [the and of], got %v", dropped)
+	}
+}
+
 func TestScorer_HasKeywords(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -380,3 +397,137 @@ func TestGetRelevanceThreshold(t *testing.T) {
 		t.Error("Threshold should be greater than a directory match")
 	}
 }
+
+func TestScorer_ExplainAddsUpToScore(t *testing.T) {
+	scorer := NewScorer("auth")
+	content := "package auth\n\nimport \"example.com/auth/token\"\n\n// auth helpers\n"
+
+	b := scorer.Explain("internal/auth/auth.go", content)
+	if b.Filename != FilenameWeight || b.Directory != DirectoryWeight {
+		t.Errorf("expected filename and directory matches, got %+v", b)
+	}
+	if b.Imports != ImportWeight {
+		t.Errorf("expected one import match, got %+v", b)
+	}
+	if b.Content != 3*ContentWeight {
+		t.Errorf("expected three content matches, got %+v", b)
+	}
+	if got := scorer.ScoreFile("internal/auth/auth.go", content); got != b.Total() {
+		t.Errorf("ScoreFile() = %v, breakdown total = %v", got, b.Total())
+	}
+}
+
+func TestQuestionKeywords(t *testing.T) {
+	tests := map[string][]string{
+		"where is auth handled?":                      {"auth", "handled"},
+		"How does the http.Client retry on failure?":  {"http.client", "retry", "failure"},
+		"Which file validates user_id, and user_id?":  {"validates", "user_id"},
+		"what does this do?":                          nil,
+		"Explain the rate-limiter. Is it per-tenant?": {"rate-limiter", "per-tenant"},
+	}
+	for question, want := range tests {
+		got := QuestionKeywords(question)
+		if len(got) != len(want) {
+			t.Errorf("QuestionKeywords(%q) = %q, want %q", question, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("QuestionKeywords(%q) = %q, want %q", question, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"authentication": "authentic",
+		"users":          "user",
+		"parser":         "pars",
+		"cached":         "cach",
+		"classes":        "class",
+		"class":          "class",
+		"address":        "address",
+		"auth":           "auth",
+		"logs":           "logs",
+	}
+	for word, want := range tests {
+		if got := stem(word); got != want {
+			t.Errorf("stem(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestScorer_Expansion(t *testing.T) {
+	// "auth" finds login code through its synonyms, at half weight
+	scorer := NewScorer("auth")
+	if score := scorer.ScoreFile("handlers/login.go", "package handlers\n"); score != FilenameWeight*SynonymWeight {
+		t.Errorf("Expected synonym filename match %.1f, got %.1f", FilenameWeight*SynonymWeight, score)
+	}
+	if score := scorer.ScoreFile("auth.go", "package x\n"); score != FilenameWeight {
+		t.Errorf("Expected an exact match at full weight, got %.1f", score)
+	}
+
+	// A keyword matches the other forms of its stem at full weight
+	scorer = NewScorer("authentication")
+	if score := scorer.ScoreFile("authenticate.go", "package x\n"); score != FilenameWeight {
+		t.Errorf("Expected stemmed filename match %.1f, got %.1f", FilenameWeight, score)
+	}
+
+	exact := NewScorerWith("auth", ExpandOptions{Exact: true})
+	if score := exact.ScoreFile("handlers/login.go", "package handlers\n"); score != 0 {
+		t.Errorf("Expected no match without expansion, got %.1f", score)
+	}
+	if len(exact.Expansions()) != 0 {
+		t.Errorf("Expected no expansions, got %v", exact.Expansions())
+	}
+
+	custom := NewScorerWith("Auth", ExpandOptions{Synonyms: map[string][]string{"auth": {"SSO", "x"}}})
+	if score := custom.ScoreFile("sso/provider.go", "package sso\n"); score != (DirectoryWeight+ContentWeight)*SynonymWeight {
+		t.Errorf("Expected configured synonym match, got %.1f", score)
+	}
+	words := custom.Expansions()["auth"]
+	if len(words) == 0 || words[len(words)-1] != "sso" {
+		t.Errorf("Expected sso last among the expansions, got %v", words)
+	}
+	for _, word := range words {
+		if word == "x" || word == "oauth" {
+			t.Errorf("Expected short synonyms and ones containing the keyword left out, got %v", words)
+		}
+	}
+}
+
+func TestScorer_NegativeKeywords(t *testing.T) {
+	scorer := NewScorer("auth -tests -Mock")
+	if got := scorer.NegativeKeywords(); len(got) != 2 || got[0] != "test" || got[1] != "mock" {
+		t.Errorf("Expected stemmed negative keywords [test mock], got %v", got)
+	}
+
+	// A negative keyword in the path excludes the file however well it matches
+	for _, path := range []string{"auth/auth_test.go", "mocks/auth.go"} {
+		if !scorer.Negated(path) || scorer.ScoreFile(path, "auth auth") != 0 {
+			t.Errorf("Expected %s excluded by a negative keyword", path)
+		}
+	}
+
+	// Mentions in the content take off up to half the score
+	b := scorer.Explain("auth.go", "auth\n")
+	if b.Penalty != 0 || b.Total() != FilenameWeight+ContentWeight {
+		t.Errorf("Expected no penalty without mentions, got %+v", b)
+	}
+	b = scorer.Explain("auth.go", "auth with a mock\n")
+	if b.Penalty != -ContentWeight || b.Total() != FilenameWeight {
+		t.Errorf("Expected one mention to cost %.1f, got %+v", ContentWeight, b)
+	}
+	b = scorer.Explain("auth.go", strings.Repeat("mock test ", 20))
+	if b.Penalty != -b.Filename/2 || b.Total() != FilenameWeight/2 {
+		t.Errorf("Expected the penalty capped at half the score, got %+v", b)
+	}
+
+	// Negative keywords alone keep every other file
+	only := NewScorer("-test")
+	if !only.HasKeywords() || only.ScoreFile("main.go", "package main\n") <= 0 || only.ScoreFile("main_test.go", "") != 0 {
+		t.Errorf("Expected negative keywords alone to exclude only matching files")
+	}
+}
@@ -0,0 +1,261 @@
+// Package service implements the operations promptext exposes to other
+// programs (the MCP and HTTP servers): extraction, relevance search and
+// project summaries, confined to a root directory.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/info"
+	"github.com/1broseidon/promptext/internal/relevance"
+	"github.com/1broseidon/promptext/pkg/promptext"
+)
+
+// DefaultSearchLimit caps search results when no limit is given
+const DefaultSearchLimit = 20
+
+// ErrOutsideRoot is returned when a requested directory escapes the service root
+var ErrOutsideRoot = errors.New("directory is outside the server root")
+
+// Formats lists the built-in output format names accepted by Extract
+var Formats = format.Names()
+
+// Service runs promptext operations on projects under a root directory
+type Service struct {
+	root string
+}
+
+// New creates a service whose operations may only read directories under root
+func New(root string) *Service {
+	return &Service{root: root}
+}
+
+// ExtractRequest selects what Extract includes and how it renders it
+type ExtractRequest struct {
+	Directory  string   `json:"directory"`
+	Extensions []string `json:"extensions"`
+	Excludes   []string `json:"excludes"`
+	Includes   []string `json:"includes"`
+	Keywords   []string `json:"keywords"`
+	MaxTokens  int      `json:"max_tokens"`
+	Format     string   `json:"format"`
+}
+
+// SearchRequest describes a relevance search
+type SearchRequest struct {
+	Directory  string   `json:"directory"`
+	Keywords   []string `json:"keywords"`
+	Extensions []string `json:"extensions"`
+	Excludes   []string `json:"excludes"`
+	Limit      int      `json:"limit"`
+}
+
+// SearchResult is one ranked file returned by Search
+type SearchResult struct {
+	Path   string  `json:"path"`
+	Score  float64 `json:"score"`
+	Tokens int     `json:"tokens"`
+}
+
+// ProjectSummary describes a project without its file contents
+type ProjectSummary struct {
+	Name         string              `json:"name"`
+	Language     string              `json:"language,omitempty"`
+	Version      string              `json:"version,omitempty"`
+	Dependencies []DependencySummary `json:"dependencies,omitempty"`
+	Git          *GitSummary         `json:"git,omitempty"`
+	Tree         string              `json:"tree"`
+}
+
+// DependencySummary is a manifest dependency reported in a ProjectSummary
+type DependencySummary struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Scope   string `json:"scope"`
+}
+
+// GitSummary is the repository state reported in a ProjectSummary
+type GitSummary struct {
+	Branch  string `json:"branch"`
+	Commit  string `json:"commit"`
+	Message string `json:"message"`
+}
+
+// Extract runs a full extraction for the request
+func (s *Service) Extract(req ExtractRequest) (*promptext.Result, error) {
+	dir, err := s.Resolve(req.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := filterOptions(req.Extensions, req.Excludes)
+	if len(req.Includes) > 0 {
+		opts = append(opts, promptext.WithIncludes(req.Includes...))
+	}
+	if len(req.Keywords) > 0 {
+		opts = append(opts, promptext.WithRelevance(req.Keywords...))
+	}
+	if req.MaxTokens > 0 {
+		opts = append(opts, promptext.WithTokenBudget(req.MaxTokens))
+	}
+	if req.Format != "" {
+		opts = append(opts, promptext.WithFormat(promptext.Format(req.Format)))
+	}
+
+	return promptext.Extract(dir, opts...)
+}
+
+// Search ranks the project's files by relevance to the request keywords,
+// best first. Files that match no keyword are left out.
+func (s *Service) Search(req SearchRequest) ([]SearchResult, error) {
+	if len(req.Keywords) == 0 {
+		return nil, fmt.Errorf("keywords is required")
+	}
+	dir, err := s.Resolve(req.Directory)
+	if err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	files, err := promptext.ExtractStream(dir, filterOptions(req.Extensions, req.Excludes)...)
+	if err != nil {
+		return nil, err
+	}
+
+	scorer := relevance.NewScorer(strings.Join(req.Keywords, ","))
+	results := []SearchResult{}
+	for fr := range files {
+		if fr.Err != nil {
+			return nil, fr.Err
+		}
+		if score := scorer.ScoreFile(fr.File.Path, fr.File.Content); score > 0 {
+			results = append(results, SearchResult{Path: fr.File.Path, Score: score, Tokens: fr.File.Tokens})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// ProjectInfo summarizes the project in dir
+func (s *Service) ProjectInfo(dir string) (*ProjectSummary, error) {
+	path, err := s.Resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	f := filter.New(filter.Options{UseDefaultRules: true})
+	projectInfo, err := info.GetProjectInfo(path, f)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &ProjectSummary{Name: filepath.Base(path)}
+	if md := projectInfo.Metadata; md != nil {
+		if md.Name != "" {
+			summary.Name = md.Name
+		}
+		summary.Language = md.Language
+		summary.Version = md.Version
+		for _, dep := range md.Dependencies {
+			summary.Dependencies = append(summary.Dependencies, DependencySummary(dep))
+		}
+	}
+	if gi := projectInfo.GitInfo; gi != nil {
+		summary.Git = &GitSummary{Branch: gi.Branch, Commit: gi.CommitHash, Message: gi.CommitMessage}
+	}
+	if projectInfo.DirectoryTree != nil {
+		var tree strings.Builder
+		for _, child := range projectInfo.DirectoryTree.Children {
+			tree.WriteString(child.ToMarkdown(1))
+		}
+		summary.Tree = tree.String()
+	}
+	return summary, nil
+}
+
+// Resolve maps a directory argument onto the root, refusing paths that would
+// escape it, also by way of a symlink. An empty dir is the root itself.
+func (s *Service) Resolve(dir string) (string, error) {
+	root, err := filepath.Abs(s.root)
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		return root, nil
+	}
+
+	path := dir
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	path = filepath.Clean(path)
+	if !within(root, path) {
+		return "", fmt.Errorf("%w: %q (root %s)", ErrOutsideRoot, dir, root)
+	}
+
+	// Compare where symlinks lead as well
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	realPath, err := evalExisting(path)
+	if err != nil {
+		return "", err
+	}
+	if !within(realRoot, realPath) {
+		return "", fmt.Errorf("%w: %q leads to %s (root %s)", ErrOutsideRoot, dir, realPath, root)
+	}
+	return path, nil
+}
+
+// evalExisting evaluates the symlinks of the part of path that exists and
+// appends the rest unchanged
+func evalExisting(path string) (string, error) {
+	real, err := filepath.EvalSymlinks(path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return real, err
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	real, err = evalExisting(parent)
+	return filepath.Join(real, filepath.Base(path)), err
+}
+
+// within reports whether path is root or lies below it
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// filterOptions builds the extension and exclude options shared by the operations
+func filterOptions(extensions, excludes []string) []promptext.Option {
+	var opts []promptext.Option
+	if len(extensions) > 0 {
+		opts = append(opts, promptext.WithExtensions(extensions...))
+	}
+	if len(excludes) > 0 {
+		opts = append(opts, promptext.WithExcludes(excludes...))
+	}
+	return opts
+}
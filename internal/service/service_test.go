@@ -0,0 +1,34 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src"), 0755))
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+	require.NoError(t, os.Symlink(filepath.Join(root, "src"), filepath.Join(root, "alias")))
+	s := New(root)
+
+	path, err := s.Resolve("src")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "src"), path)
+	_, err = s.Resolve("alias")
+	assert.NoError(t, err, "a link that stays inside the root is allowed")
+	_, err = s.Resolve("missing")
+	assert.NoError(t, err, "a path that doesn't exist is left for the operation to report")
+
+	for _, dir := range []string{"..", "src/../..", outside, "escape", "escape/sub"} {
+		_, err := s.Resolve(dir)
+		assert.ErrorIs(t, err, ErrOutsideRoot, dir)
+	}
+}
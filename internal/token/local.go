@@ -0,0 +1,228 @@
+package token
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/promptext/internal/log"
+)
+
+// Prefixes of tokenizer names that count with a model on a local server,
+// e.g. "ollama:llama3" or "lmstudio:qwen2.5-coder-7b-instruct"
+const (
+	TokenizerOllamaPrefix   = "ollama:"
+	TokenizerLMStudioPrefix = "lmstudio:"
+)
+
+// Default local server endpoints, overridden by OLLAMA_HOST and LMSTUDIO_HOST
+const (
+	DefaultOllamaURL   = "http://localhost:11434"
+	DefaultLMStudioURL = "http://localhost:1234"
+)
+
+// localTimeout bounds one count request; the first may wait for the server
+// to load the model
+const localTimeout = 2 * time.Minute
+
+// localClient sends the count requests
+var localClient = &http.Client{Timeout: localTimeout}
+
+// localCounters holds a counter per local tokenizer name and server, so the
+// server is probed once per process and repeated texts are counted once
+var localCounters sync.Map
+
+// IsLocalTokenizer reports whether name selects a model on a local server:
+// one of the Tokenizer*Prefix prefixes followed by a model name
+func IsLocalTokenizer(name string) bool {
+	for _, prefix := range []string{TokenizerOllamaPrefix, TokenizerLMStudioPrefix} {
+		if model, ok := strings.CutPrefix(name, prefix); ok && model != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidTokenizer reports whether NewTokenCounterFor accepts name, without
+// contacting a local server
+func ValidTokenizer(name string) bool {
+	if name == "" || IsLocalTokenizer(name) {
+		return true
+	}
+	for _, known := range Tokenizers() {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+// localTokenizer counts tokens by asking a local server to tokenize text
+// with the model it has loaded
+type localTokenizer struct {
+	count func(text string) (int, error)
+
+	mu     sync.Mutex
+	counts map[[sha256.Size]byte]int // Counts by text hash
+	failed bool                      // A count failed; later texts are approximated
+}
+
+// newLocalCounter returns the counter for a local tokenizer name, probing
+// the server the first time the name is used
+func newLocalCounter(name string) (*TokenCounter, error) {
+	var count func(string) (int, error)
+	var server string
+	switch {
+	case strings.HasPrefix(name, TokenizerOllamaPrefix):
+		server = serverURL("OLLAMA_HOST", DefaultOllamaURL)
+		count = ollamaCount(server, strings.TrimPrefix(name, TokenizerOllamaPrefix))
+	default:
+		server = serverURL("LMSTUDIO_HOST", DefaultLMStudioURL)
+		count = lmStudioCount(server, strings.TrimPrefix(name, TokenizerLMStudioPrefix))
+	}
+
+	key := name + " " + server
+	if tc, ok := localCounters.Load(key); ok && !tc.(*TokenCounter).local.hasFailed() {
+		return tc.(*TokenCounter), nil
+	}
+	if _, err := count("hello"); err != nil {
+		return nil, fmt.Errorf("tokenizer %s: %w", name, err)
+	}
+	log.Debug("Counting tokens with %s at %s", name, server)
+	tc := &TokenCounter{
+		encodingName: name,
+		local:        &localTokenizer{count: count, counts: map[[sha256.Size]byte]int{}},
+	}
+	localCounters.Store(key, tc)
+	return tc, nil
+}
+
+// hasFailed reports whether a count failed, so the server is probed again
+// before the next run counts with it
+func (l *localTokenizer) hasFailed() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.failed
+}
+
+// estimate counts text on the server, falling back to approximate for
+// the rest of the run once the server stops answering
+func (l *localTokenizer) estimate(text string, approximate func(string) int) int {
+	sum := sha256.Sum256([]byte(text))
+	l.mu.Lock()
+	count, ok := l.counts[sum]
+	failed := l.failed
+	l.mu.Unlock()
+	if ok {
+		return count
+	}
+	if failed {
+		return approximate(text)
+	}
+
+	count, err := l.count(text)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err != nil {
+		if !l.failed {
+			log.Warn("Token counting with the local model failed, approximating from here on: %v", err)
+			l.failed = true
+		}
+		return approximate(text)
+	}
+	l.counts[sum] = count
+	return count
+}
+
+// serverURL returns the server named by the environment variable env, with
+// http:// added to a bare host:port, or fallback when it is unset
+func serverURL(env, fallback string) string {
+	url := os.Getenv(env)
+	if url == "" {
+		return fallback
+	}
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+	return strings.TrimRight(url, "/")
+}
+
+// errNotFound marks an endpoint the server doesn't have
+var errNotFound = errors.New("endpoint not found")
+
+// ollamaCount counts with Ollama's /api/tokenize endpoint. Servers without
+// it count through /api/generate instead: a raw prompt's evaluation count,
+// with a single token generated.
+func ollamaCount(server, model string) func(string) (int, error) {
+	return func(text string) (int, error) {
+		var tokenized struct {
+			Tokens []int `json:"tokens"`
+		}
+		err := postJSON(server+"/api/tokenize", map[string]any{"model": model, "text": text}, &tokenized)
+		if err == nil {
+			return len(tokenized.Tokens), nil
+		}
+		if !errors.Is(err, errNotFound) {
+			return 0, err
+		}
+
+		var generated struct {
+			PromptEvalCount int `json:"prompt_eval_count"`
+		}
+		body := map[string]any{"model": model, "prompt": text, "raw": true, "stream": false, "options": map[string]any{"num_predict": 1}}
+		if err := postJSON(server+"/api/generate", body, &generated); err != nil {
+			return 0, err
+		}
+		return generated.PromptEvalCount, nil
+	}
+}
+
+// lmStudioCount counts with LM Studio's OpenAI-compatible completions
+// endpoint: the prompt tokens it reports for a one-token completion
+func lmStudioCount(server, model string) func(string) (int, error) {
+	return func(text string) (int, error) {
+		var completion struct {
+			Usage struct {
+				PromptTokens int `json:"prompt_tokens"`
+			} `json:"usage"`
+		}
+		body := map[string]any{"model": model, "prompt": text, "max_tokens": 1, "temperature": 0}
+		if err := postJSON(server+"/v1/completions", body, &completion); err != nil {
+			return 0, err
+		}
+		return completion.Usage.PromptTokens, nil
+	}
+}
+
+// postJSON posts body to url and decodes the JSON response into out
+func postJSON(url string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := localClient.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		// Ollama answers 404 for a missing model as well as a missing endpoint
+		if resp.StatusCode == http.StatusNotFound && !strings.Contains(string(detail), "model") {
+			return fmt.Errorf("%s: %w", url, errNotFound)
+		}
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s: decoding response: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package token
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeOllama serves /api/tokenize with one token per word, counting the
+// requests it answers
+func fakeOllama(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+			Text  string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/api/tokenize" || req.Model != "llama3" {
+			http.Error(w, `{"error":"model 'x' not found"}`, http.StatusNotFound)
+			return
+		}
+		requests.Add(1)
+		tokens := make([]int, len(strings.Fields(req.Text)))
+		json.NewEncoder(w).Encode(map[string]any{"tokens": tokens})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestValidTokenizer(t *testing.T) {
+	for _, name := range []string{"", "cl100k", "chars", "ollama:llama3", "ollama:llama3:8b", "lmstudio:qwen2.5-coder"} {
+		if !ValidTokenizer(name) {
+			t.Errorf("ValidTokenizer(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"gpt-2", "ollama:", "lmstudio:", "vllm:llama3"} {
+		if ValidTokenizer(name) {
+			t.Errorf("ValidTokenizer(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestOllamaTokenizer(t *testing.T) {
+	var requests atomic.Int32
+	server := fakeOllama(t, &requests)
+	t.Setenv("OLLAMA_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+	tc, err := NewTokenCounterFor("ollama:llama3")
+	if err != nil {
+		t.Fatalf("NewTokenCounterFor failed: %v", err)
+	}
+	if got := tc.EstimateTokens("func main() { run() }"); got != 5 {
+		t.Errorf("EstimateTokens = %d, want 5 words counted by the server", got)
+	}
+	if tc.GetEncodingName() != "ollama:llama3" {
+		t.Errorf("GetEncodingName = %q", tc.GetEncodingName())
+	}
+
+	// Repeated texts and counters are counted once
+	again, err := NewTokenCounterFor("ollama:llama3")
+	if err != nil {
+		t.Fatalf("NewTokenCounterFor failed: %v", err)
+	}
+	again.EstimateTokens("func main() { run() }")
+	if got := requests.Load(); got != 2 {
+		t.Errorf("expected the probe and one count, got %d requests", got)
+	}
+
+	if _, err := NewTokenCounterFor("ollama:mistral"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a missing model error, got %v", err)
+	}
+}
+
+func TestOllamaTokenizerGenerateFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/generate" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Prompt string `json:"prompt"`
+			Raw    bool   `json:"raw"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Raw {
+			t.Error("expected a raw prompt, without the model's template")
+		}
+		json.NewEncoder(w).Encode(map[string]any{"prompt_eval_count": len(req.Prompt)})
+	}))
+	defer server.Close()
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	tc, err := NewTokenCounterFor("ollama:llama3")
+	if err != nil {
+		t.Fatalf("NewTokenCounterFor failed: %v", err)
+	}
+	if got := tc.EstimateTokens("abcdef"); got != 6 {
+		t.Errorf("EstimateTokens = %d, want the prompt evaluation count 6", got)
+	}
+}
+
+func TestLMStudioTokenizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model     string `json:"model"`
+			Prompt    string `json:"prompt"`
+			MaxTokens int    `json:"max_tokens"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/v1/completions" || req.Model != "qwen2.5-coder" || req.MaxTokens != 1 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"usage": map[string]int{"prompt_tokens": len(req.Prompt) / 2}})
+	}))
+	defer server.Close()
+	t.Setenv("LMSTUDIO_HOST", server.URL)
+
+	tc, err := NewTokenCounterFor("lmstudio:qwen2.5-coder")
+	if err != nil {
+		t.Fatalf("NewTokenCounterFor failed: %v", err)
+	}
+	if got := tc.EstimateTokens("abcdefgh"); got != 4 {
+		t.Errorf("EstimateTokens = %d, want 4", got)
+	}
+}
+
+func TestLocalTokenizerFailures(t *testing.T) {
+	var requests atomic.Int32
+	server := fakeOllama(t, &requests)
+	t.Setenv("OLLAMA_HOST", server.URL)
+
+	tc, err := NewTokenCounterFor("ollama:llama3")
+	if err != nil {
+		t.Fatalf("NewTokenCounterFor failed: %v", err)
+	}
+
+	// A server that goes away leaves the rest of the run approximated
+	server.Close()
+	if got := tc.EstimateTokens("one two three four five six"); got == 0 {
+		t.Error("expected an approximate count once the server is gone")
+	}
+
+	if _, err := NewTokenCounterFor("ollama:llama3"); err == nil {
+		t.Error("expected the next counter to probe the server again and fail")
+	}
+}
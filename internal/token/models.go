@@ -0,0 +1,90 @@
+package token
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Model describes a target LLM: its context window and the tokenizer that
+// best approximates its token counts
+type Model struct {
+	Name          string
+	ContextWindow int    // Total tokens the model accepts, prompt plus response
+	Tokenizer     string // One of Tokenizers()
+}
+
+// ErrUnknownModel is returned for a model name not in the built-in table
+var ErrUnknownModel = errors.New("unknown model")
+
+// maxDefaultReserve caps the response tokens reserved by default
+const maxDefaultReserve = 8192
+
+// models is the built-in model table. Dated or suffixed variants
+// (claude-sonnet-4-20250514, gpt-4o-2024-08-06) resolve by longest prefix.
+var models = []Model{
+	{Name: "claude-opus-4", ContextWindow: 200000, Tokenizer: TokenizerClaude},
+	{Name: "claude-sonnet-4", ContextWindow: 200000, Tokenizer: TokenizerClaude},
+	{Name: "claude-3-7-sonnet", ContextWindow: 200000, Tokenizer: TokenizerClaude},
+	{Name: "claude-3-5-sonnet", ContextWindow: 200000, Tokenizer: TokenizerClaude},
+	{Name: "claude-3-5-haiku", ContextWindow: 200000, Tokenizer: TokenizerClaude},
+	{Name: "gpt-4.1", ContextWindow: 1047576, Tokenizer: TokenizerO200K},
+	{Name: "gpt-4o", ContextWindow: 128000, Tokenizer: TokenizerO200K},
+	{Name: "gpt-4-turbo", ContextWindow: 128000, Tokenizer: TokenizerCL100K},
+	{Name: "gpt-4", ContextWindow: 8192, Tokenizer: TokenizerCL100K},
+	{Name: "gpt-3.5-turbo", ContextWindow: 16385, Tokenizer: TokenizerCL100K},
+	{Name: "o3", ContextWindow: 200000, Tokenizer: TokenizerO200K},
+	{Name: "o4-mini", ContextWindow: 200000, Tokenizer: TokenizerO200K},
+	{Name: "gemini-2.5-pro", ContextWindow: 1048576, Tokenizer: TokenizerChars},
+	{Name: "gemini-2.5-flash", ContextWindow: 1048576, Tokenizer: TokenizerChars},
+	{Name: "gemini-1.5-pro", ContextWindow: 2097152, Tokenizer: TokenizerChars},
+	{Name: "llama-3.1", ContextWindow: 131072, Tokenizer: TokenizerChars},
+	{Name: "llama-3.3", ContextWindow: 131072, Tokenizer: TokenizerChars},
+}
+
+// Models lists the built-in models sorted by name
+func Models() []Model {
+	list := append([]Model(nil), models...)
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// LookupModel finds a model by exact name or, failing that, by the longest
+// table entry the name starts with followed by a "-" (e.g. a date suffix)
+func LookupModel(name string) (Model, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	var best Model
+	for _, m := range models {
+		if name == m.Name {
+			return m, nil
+		}
+		if strings.HasPrefix(name, m.Name+"-") && len(m.Name) > len(best.Name) {
+			best = m
+		}
+	}
+	if best.Name != "" {
+		return best, nil
+	}
+
+	names := make([]string, 0, len(models))
+	for _, m := range Models() {
+		names = append(names, m.Name)
+	}
+	return Model{}, fmt.Errorf("%w %q (known: %s)", ErrUnknownModel, name, strings.Join(names, ", "))
+}
+
+// DefaultResponseReserve is the response allowance used when none is given:
+// a quarter of the context window, at most 8192 tokens
+func (m Model) DefaultResponseReserve() int {
+	return min(m.ContextWindow/4, maxDefaultReserve)
+}
+
+// Budget is the prompt token budget for the model: the context window minus
+// reserve response tokens (0 = DefaultResponseReserve)
+func (m Model) Budget(reserve int) int {
+	if reserve <= 0 {
+		reserve = m.DefaultResponseReserve()
+	}
+	return max(m.ContextWindow-reserve, 1)
+}
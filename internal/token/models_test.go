@@ -0,0 +1,51 @@
+package token
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLookupModel(t *testing.T) {
+	tests := []struct {
+		name      string
+		want      string
+		tokenizer string
+	}{
+		{"claude-sonnet-4", "claude-sonnet-4", TokenizerClaude},
+		{"Claude-Sonnet-4-20250514", "claude-sonnet-4", TokenizerClaude},
+		{"gpt-4o-mini", "gpt-4o", TokenizerO200K},
+		{"gpt-4-0613", "gpt-4", TokenizerCL100K},
+		{"gpt-4-turbo-preview", "gpt-4-turbo", TokenizerCL100K},
+	}
+	for _, tt := range tests {
+		m, err := LookupModel(tt.name)
+		if err != nil {
+			t.Fatalf("LookupModel(%q) failed: %v", tt.name, err)
+		}
+		if m.Name != tt.want || m.Tokenizer != tt.tokenizer {
+			t.Errorf("LookupModel(%q) = %s/%s, want %s/%s", tt.name, m.Name, m.Tokenizer, tt.want, tt.tokenizer)
+		}
+	}
+
+	if _, err := LookupModel("gpt-5000"); !errors.Is(err, ErrUnknownModel) {
+		t.Errorf("expected ErrUnknownModel, got %v", err)
+	}
+}
+
+func TestModelBudget(t *testing.T) {
+	m := Model{Name: "big", ContextWindow: 200000}
+	if got := m.Budget(0); got != 200000-8192 {
+		t.Errorf("default reserve: got %d", got)
+	}
+	if got := m.Budget(50000); got != 150000 {
+		t.Errorf("explicit reserve: got %d", got)
+	}
+
+	small := Model{Name: "small", ContextWindow: 8192}
+	if got := small.Budget(0); got != 8192-2048 {
+		t.Errorf("small window reserve: got %d", got)
+	}
+	if got := small.Budget(10000); got != 1 {
+		t.Errorf("reserve above window should leave a minimal budget, got %d", got)
+	}
+}
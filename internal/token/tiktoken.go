@@ -1,6 +1,9 @@
 package token
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -35,18 +38,70 @@ func ensureCacheDir() {
 	log.Debug("Set tiktoken cache to: %s", cacheDir)
 }
 
+// Tokenizer names accepted by NewTokenCounterFor
+const (
+	TokenizerCL100K = "cl100k" // tiktoken cl100k_base (GPT-4, GPT-3.5-turbo), the default
+	TokenizerO200K  = "o200k"  // tiktoken o200k_base (GPT-4o and later)
+	TokenizerClaude = "claude" // Approximation of Anthropic's tokenizer
+	TokenizerChars  = "chars"  // Model-agnostic len/4 estimate, no tokenizer data needed
+)
+
+// claudeScale approximates Claude token counts from cl100k_base counts.
+// Anthropic doesn't publish its tokenizer; on source code Claude models
+// report roughly 10% more tokens than cl100k_base for the same text.
+const claudeScale = 1.1
+
+// ErrUnknownTokenizer is returned for a tokenizer name not in Tokenizers()
+var ErrUnknownTokenizer = errors.New("unknown tokenizer")
+
+// Tokenizers lists the supported tokenizer names
+func Tokenizers() []string {
+	return []string{TokenizerCL100K, TokenizerO200K, TokenizerClaude, TokenizerChars}
+}
+
 type TokenCounter struct {
 	encoding     *tiktoken.Tiktoken
 	fallbackMode bool
 	encodingName string
+	scale        float64         // Multiplier applied to counts (0 = none)
+	charsOnly    bool            // Count len/4 instead of encoding
+	local        *localTokenizer // Count on a local model server instead
 }
 
-// NewTokenCounter creates a token counter with proper fallback
+// NewTokenCounter creates a cl100k_base token counter with proper fallback
 func NewTokenCounter() *TokenCounter {
-	// Try cl100k_base (GPT-4, GPT-3.5-turbo)
-	enc, err := tiktoken.GetEncoding("cl100k_base")
+	return newTiktokenCounter("cl100k_base")
+}
+
+// NewTokenCounterFor creates a token counter for the named tokenizer. An
+// empty name selects the default (cl100k). A local tokenizer, such as
+// "ollama:llama3", fails when its server can't count tokens with the model.
+func NewTokenCounterFor(name string) (*TokenCounter, error) {
+	if IsLocalTokenizer(name) {
+		return newLocalCounter(name)
+	}
+	switch name {
+	case "", TokenizerCL100K:
+		return NewTokenCounter(), nil
+	case TokenizerO200K:
+		return newTiktokenCounter("o200k_base"), nil
+	case TokenizerClaude:
+		tc := newTiktokenCounter("cl100k_base")
+		tc.scale = claudeScale
+		tc.encodingName = "claude-approx"
+		return tc, nil
+	case TokenizerChars:
+		return &TokenCounter{fallbackMode: true, encodingName: "chars", charsOnly: true}, nil
+	}
+	return nil, fmt.Errorf("%w %q (supported: %s, %sMODEL, %sMODEL)", ErrUnknownTokenizer, name, strings.Join(Tokenizers(), ", "), TokenizerOllamaPrefix, TokenizerLMStudioPrefix)
+}
+
+// newTiktokenCounter loads a tiktoken encoding, falling back to approximation
+// when the encoding data can't be loaded (e.g. offline without a cache)
+func newTiktokenCounter(encoding string) *TokenCounter {
+	enc, err := tiktoken.GetEncoding(encoding)
 	if err != nil {
-		log.Debug("Failed to load cl100k_base encoding: %v", err)
+		log.Debug("Failed to load %s encoding: %v", encoding, err)
 		log.Info("Token counting using approximation (tiktoken unavailable)")
 		return &TokenCounter{
 			encoding:     nil,
@@ -55,11 +110,11 @@ func NewTokenCounter() *TokenCounter {
 		}
 	}
 
-	log.Debug("Initialized tiktoken with cl100k_base encoding")
+	log.Debug("Initialized tiktoken with %s encoding", encoding)
 	return &TokenCounter{
 		encoding:     enc,
 		fallbackMode: false,
-		encodingName: "cl100k_base",
+		encodingName: encoding,
 	}
 }
 
@@ -69,13 +124,25 @@ func (tc *TokenCounter) EstimateTokens(text string) int {
 		return 0
 	}
 
+	if tc.charsOnly {
+		return (len(text) + 3) / 4
+	}
+	if tc.local != nil {
+		return tc.local.estimate(text, tc.approximateTokens)
+	}
+
+	var count int
 	if tc.fallbackMode || tc.encoding == nil {
-		return tc.approximateTokens(text)
+		count = tc.approximateTokens(text)
+	} else {
+		// Use tiktoken for accurate count
+		count = len(tc.encoding.Encode(text, nil, nil))
 	}
 
-	// Use tiktoken for accurate count
-	tokens := tc.encoding.Encode(text, nil, nil)
-	return len(tokens)
+	if tc.scale > 0 {
+		return int(math.Ceil(float64(count) * tc.scale))
+	}
+	return count
 }
 
 // approximateTokens provides a fallback when tiktoken is unavailable
@@ -1,6 +1,7 @@
 package token
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"os"
@@ -344,3 +345,39 @@ func TestTokenCounter_IsFallbackMode(t *testing.T) {
 		t.Error("IsFallbackMode is false but encoding name is not 'cl100k_base'")
 	}
 }
+
+func TestNewTokenCounterFor(t *testing.T) {
+	text := strings.Repeat("func main() { fmt.Println(\"hello\") }\n", 20)
+	base := NewTokenCounter().EstimateTokens(text)
+
+	for _, name := range Tokenizers() {
+		tc, err := NewTokenCounterFor(name)
+		if err != nil {
+			t.Fatalf("NewTokenCounterFor(%q) failed: %v", name, err)
+		}
+		if tc.EstimateTokens(text) == 0 {
+			t.Errorf("%s: expected >0 tokens", name)
+		}
+	}
+
+	if tc, _ := NewTokenCounterFor(""); tc.EstimateTokens(text) != base {
+		t.Error("empty tokenizer name should select the default")
+	}
+
+	chars, _ := NewTokenCounterFor(TokenizerChars)
+	if got, want := chars.EstimateTokens(text), (len(text)+3)/4; got != want {
+		t.Errorf("chars tokenizer: got %d, want %d", got, want)
+	}
+
+	claude, _ := NewTokenCounterFor(TokenizerClaude)
+	if got := claude.EstimateTokens(text); got <= base {
+		t.Errorf("claude approximation should exceed cl100k (%d), got %d", base, got)
+	}
+	if claude.GetEncodingName() == NewTokenCounter().GetEncodingName() {
+		t.Error("claude tokenizer needs a distinct encoding name for cache keys")
+	}
+
+	if _, err := NewTokenCounterFor("gpt-2"); !errors.Is(err, ErrUnknownTokenizer) {
+		t.Errorf("expected ErrUnknownTokenizer, got %v", err)
+	}
+}
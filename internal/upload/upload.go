@@ -0,0 +1,202 @@
+// Package upload sends generated context to a model provider's files API,
+// so later prompts can reference the uploaded file by its id instead of
+// carrying megabytes of text. Like package llm, it keeps no credentials of
+// its own: keys and endpoints come from the environment on every call to
+// FromEnv.
+package upload
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/llm"
+)
+
+// anthropicFilesBeta is the beta the Anthropic Files API is served under
+const anthropicFilesBeta = "files-api-2025-04-14"
+
+// anthropicVersion is the API version requests are made against
+const anthropicVersion = "2023-06-01"
+
+// openAIPurpose marks uploads as files for use in model inputs
+const openAIPurpose = "user_data"
+
+// ErrNotConfigured is returned by FromEnv when the environment has no key
+// for the provider
+var ErrNotConfigured = errors.New("no file upload provider configured")
+
+// File is an uploaded file as the provider reports it
+type File struct {
+	ID       string // What prompts reference the file by
+	Name     string
+	Bytes    int64
+	Provider string
+}
+
+// Uploader uploads a file to one provider's files API
+type Uploader interface {
+	// Upload sends content as a file called name, of the given media type
+	Upload(ctx context.Context, name, mediaType string, content []byte) (File, error)
+}
+
+// providers builds the uploader of each provider from the environment
+var providers = map[string]func(getenv func(string) string) (Uploader, error){
+	llm.ProviderAnthropic: func(getenv func(string) string) (Uploader, error) {
+		if getenv("ANTHROPIC_API_KEY") == "" {
+			return nil, fmt.Errorf("%w: ANTHROPIC_API_KEY is not set", ErrNotConfigured)
+		}
+		return &Anthropic{BaseURL: getenv("ANTHROPIC_BASE_URL"), APIKey: getenv("ANTHROPIC_API_KEY")}, nil
+	},
+	llm.ProviderOpenAI: func(getenv func(string) string) (Uploader, error) {
+		if getenv("OPENAI_API_KEY") == "" {
+			return nil, fmt.Errorf("%w: OPENAI_API_KEY is not set", ErrNotConfigured)
+		}
+		return &OpenAI{BaseURL: getenv("OPENAI_BASE_URL"), APIKey: getenv("OPENAI_API_KEY")}, nil
+	},
+}
+
+// Providers lists the provider names FromEnv accepts
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromEnv returns the uploader for provider, configured from the
+// environment. An empty provider picks the first of ANTHROPIC_API_KEY and
+// OPENAI_API_KEY that is set. It returns the provider it chose.
+func FromEnv(provider string, getenv func(string) string) (Uploader, string, error) {
+	provider = strings.ToLower(strings.TrimSpace(provider))
+	if provider == "" {
+		switch {
+		case getenv("ANTHROPIC_API_KEY") != "":
+			provider = llm.ProviderAnthropic
+		case getenv("OPENAI_API_KEY") != "":
+			provider = llm.ProviderOpenAI
+		default:
+			return nil, "", fmt.Errorf("%w: set ANTHROPIC_API_KEY or OPENAI_API_KEY", ErrNotConfigured)
+		}
+	}
+	newUploader, ok := providers[provider]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: unknown provider %q (use %s)", ErrNotConfigured, provider, strings.Join(Providers(), " or "))
+	}
+	uploader, err := newUploader(getenv)
+	return uploader, provider, err
+}
+
+// Anthropic uploads to the Anthropic Files API
+type Anthropic struct {
+	BaseURL string // API root; empty means llm.DefaultAnthropicURL
+	APIKey  string
+	HTTP    *http.Client // nil means http.DefaultClient
+}
+
+func (a *Anthropic) Upload(ctx context.Context, name, mediaType string, content []byte) (File, error) {
+	headers := map[string]string{
+		"x-api-key":         a.APIKey,
+		"anthropic-version": anthropicVersion,
+		"anthropic-beta":    anthropicFilesBeta,
+	}
+	var reply struct {
+		ID        string `json:"id"`
+		Filename  string `json:"filename"`
+		SizeBytes int64  `json:"size_bytes"`
+	}
+	url := strings.TrimRight(baseURL(a.BaseURL, llm.DefaultAnthropicURL), "/") + "/v1/files"
+	if err := postFile(ctx, a.HTTP, url, headers, nil, name, mediaType, content, &reply); err != nil {
+		return File{}, err
+	}
+	return File{ID: reply.ID, Name: reply.Filename, Bytes: reply.SizeBytes, Provider: llm.ProviderAnthropic}, nil
+}
+
+// OpenAI uploads to the OpenAI Files API, for use in model inputs
+type OpenAI struct {
+	BaseURL string // API root; empty means llm.DefaultOpenAIURL
+	APIKey  string
+	HTTP    *http.Client // nil means http.DefaultClient
+}
+
+func (o *OpenAI) Upload(ctx context.Context, name, mediaType string, content []byte) (File, error) {
+	headers := map[string]string{"Authorization": "Bearer " + o.APIKey}
+	var reply struct {
+		ID       string `json:"id"`
+		Filename string `json:"filename"`
+		Bytes    int64  `json:"bytes"`
+	}
+	url := strings.TrimRight(baseURL(o.BaseURL, llm.DefaultOpenAIURL), "/") + "/files"
+	fields := map[string]string{"purpose": openAIPurpose}
+	if err := postFile(ctx, o.HTTP, url, headers, fields, name, mediaType, content, &reply); err != nil {
+		return File{}, err
+	}
+	return File{ID: reply.ID, Name: reply.Filename, Bytes: reply.Bytes, Provider: llm.ProviderOpenAI}, nil
+}
+
+func baseURL(url, fallback string) string {
+	if url != "" {
+		return url
+	}
+	return fallback
+}
+
+// postFile posts content as the multipart "file" field, after fields, and
+// decodes the JSON reply into out
+func postFile(ctx context.Context, client *http.Client, url string, headers, fields map[string]string, name, mediaType string, content []byte, out any) error {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for key, value := range fields {
+		if err := form.WriteField(key, value); err != nil {
+			return err
+		}
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, name))
+	header.Set("Content-Type", mediaType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := part.Write(content); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s: decoding response: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/1broseidon/promptext/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestFromEnv(t *testing.T) {
+	uploader, provider, err := FromEnv("", env(map[string]string{"ANTHROPIC_API_KEY": "sk-ant", "OPENAI_API_KEY": "sk"}))
+	require.NoError(t, err)
+	assert.Equal(t, llm.ProviderAnthropic, provider)
+	assert.Equal(t, &Anthropic{APIKey: "sk-ant"}, uploader)
+
+	uploader, provider, err = FromEnv("OpenAI", env(map[string]string{"OPENAI_API_KEY": "sk", "OPENAI_BASE_URL": "http://localhost:1234/v1"}))
+	require.NoError(t, err)
+	assert.Equal(t, llm.ProviderOpenAI, provider)
+	assert.Equal(t, &OpenAI{APIKey: "sk", BaseURL: "http://localhost:1234/v1"}, uploader)
+
+	assert.Equal(t, []string{llm.ProviderAnthropic, llm.ProviderOpenAI}, Providers())
+
+	for provider, vars := range map[string]map[string]string{
+		"":          {},
+		"anthropic": {"OPENAI_API_KEY": "sk"},
+		"gemini":    {"OPENAI_API_KEY": "sk"},
+	} {
+		_, _, err := FromEnv(provider, env(vars))
+		assert.ErrorIs(t, err, ErrNotConfigured, "%q %v", provider, vars)
+	}
+}
+
+// uploadServer replies to path with reply, recording the request headers,
+// form fields and uploaded file
+func uploadServer(t *testing.T, path string, reply map[string]any, headers *http.Header, fields map[string]string, file *[]byte) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, path, r.URL.Path)
+		*headers = r.Header.Clone()
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		for key, values := range r.MultipartForm.Value {
+			fields[key] = values[0]
+		}
+		part, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		fields["filename"] = header.Filename
+		fields["content-type"] = header.Header.Get("Content-Type")
+		*file, _ = io.ReadAll(part)
+		json.NewEncoder(w).Encode(reply)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAnthropicUpload(t *testing.T) {
+	var headers http.Header
+	fields := map[string]string{}
+	var content []byte
+	server := uploadServer(t, "/v1/files", map[string]any{"id": "file_011", "filename": "app-context.ptx", "size_bytes": 12}, &headers, fields, &content)
+
+	uploader := &Anthropic{BaseURL: server.URL, APIKey: "sk-ant"}
+	file, err := uploader.Upload(context.Background(), "app-context.ptx", "text/plain", []byte("package main"))
+	require.NoError(t, err)
+	assert.Equal(t, File{ID: "file_011", Name: "app-context.ptx", Bytes: 12, Provider: llm.ProviderAnthropic}, file)
+
+	assert.Equal(t, "sk-ant", headers.Get("x-api-key"))
+	assert.Equal(t, anthropicVersion, headers.Get("anthropic-version"))
+	assert.Equal(t, anthropicFilesBeta, headers.Get("anthropic-beta"))
+	assert.Equal(t, "app-context.ptx", fields["filename"])
+	assert.Equal(t, "text/plain", fields["content-type"])
+	assert.Equal(t, "package main", string(content))
+}
+
+func TestOpenAIUpload(t *testing.T) {
+	var headers http.Header
+	fields := map[string]string{}
+	var content []byte
+	server := uploadServer(t, "/v1/files", map[string]any{"id": "file-abc", "filename": "app-context.md", "bytes": 7}, &headers, fields, &content)
+
+	uploader := &OpenAI{BaseURL: server.URL + "/v1", APIKey: "sk"}
+	file, err := uploader.Upload(context.Background(), "app-context.md", "text/plain", []byte("# App\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "file-abc", file.ID)
+	assert.Equal(t, int64(7), file.Bytes)
+
+	assert.Equal(t, "Bearer sk", headers.Get("Authorization"))
+	assert.Equal(t, openAIPurpose, fields["purpose"])
+	assert.Equal(t, "# App\n", string(content))
+}
+
+func TestUploadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"invalid x-api-key"}}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := (&Anthropic{BaseURL: server.URL, APIKey: "bad"}).Upload(context.Background(), "a.ptx", "text/plain", []byte("x"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401 Unauthorized")
+	assert.Contains(t, err.Error(), "invalid x-api-key")
+}
@@ -0,0 +1,51 @@
+package promptext
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/internal/archive"
+)
+
+// ExtractArchive extracts a project from a zip, tar, or gzip-compressed tar
+// archive read from r, without unpacking it to disk. This is a convenience
+// function that creates a temporary Extractor.
+//
+// The format is detected from the data. When every entry sits below one
+// top-level directory, as in source archives downloaded from GitHub, that
+// directory is the project root and names the tree; otherwise the root is
+// named "archive". Filters, relevance, budgets and formatting work as for a
+// directory, but .gitignore and .promptextinclude files inside the archive are
+// not read, git info and project metadata are unavailable, and the file cache
+// is not used. Archives holding more than 1 GiB, compressed or not, are
+// refused.
+//
+// Example:
+//
+//	f, _ := os.Open("client-project.zip")
+//	defer f.Close()
+//	result, err := promptext.ExtractArchive(f, promptext.WithExtensions(".go"))
+func ExtractArchive(r io.Reader, opts ...Option) (*Result, error) {
+	return NewExtractor(opts...).ExtractArchive(r)
+}
+
+// ExtractArchive extracts a project from an archive read from r.
+// See the package-level ExtractArchive for details.
+func (e *Extractor) ExtractArchive(r io.Reader) (*Result, error) {
+	fsys, name, err := archive.Open(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if name == "" {
+		name = "archive"
+	}
+
+	// The root only names the project; every file is read from fsys
+	defer e.useLogger()()
+	procConfig, formatter, warnings, err := e.prepareAt(filepath.Join(string(filepath.Separator), name), fsys)
+	if err != nil {
+		return nil, err
+	}
+	return e.extract(procConfig, formatter, warnings)
+}
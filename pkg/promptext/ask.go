@@ -0,0 +1,135 @@
+package promptext
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/llm"
+	"github.com/1broseidon/promptext/internal/relevance"
+)
+
+// DefaultAskTokens is the context budget Ask uses when neither WithTokenBudget
+// nor WithModel sets one
+const DefaultAskTokens = 32000
+
+// LLM is a language model Ask sends its prompt to. LLMFromEnv returns one for
+// OpenAI, Anthropic, or Ollama; implement it to use any other.
+type LLM interface {
+	// Stream sends prompt and writes the answer to w as it arrives
+	Stream(ctx context.Context, prompt string, w io.Writer) error
+}
+
+// LLMFromEnv returns the language model the environment configures.
+// PROMPTEXT_LLM_PROVIDER (openai, anthropic, or ollama) picks the provider;
+// without it, the first of ANTHROPIC_API_KEY, OPENAI_API_KEY, and OLLAMA_HOST
+// that is set does. PROMPTEXT_LLM_MODEL overrides the provider's default
+// model, and OPENAI_BASE_URL, ANTHROPIC_BASE_URL, and OLLAMA_HOST its
+// endpoint, so OpenAI-compatible servers such as LM Studio work too.
+//
+// Keys are read from the environment on each call and never stored.
+// Returns ErrNoLLM if no provider is configured.
+func LLMFromEnv() (LLM, error) {
+	client, err := llm.FromEnv(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// Answer is the result of Ask
+type Answer struct {
+	Question string
+	Text     string   // The model's complete answer
+	Keywords []string // Relevance keywords derived from the question
+	Context  *Result  // The extraction sent as context
+}
+
+// WithLLM sets the language model Ask sends its prompt to, instead of the
+// one LLMFromEnv configures. It has no effect on Extract.
+func WithLLM(model LLM) Option {
+	return func(c *config) {
+		c.llm = model
+	}
+}
+
+// WithAnswerWriter makes Ask write the answer to w as it streams in, for
+// showing it while the model is still generating. It has no effect on Extract.
+func WithAnswerWriter(w io.Writer) Option {
+	return func(c *config) {
+		c.answerWriter = w
+	}
+}
+
+// Ask answers a question about the code in the current directory with a
+// language model: it selects the files relevant to the question, wraps them
+// in the "ask" prompt template, and sends the prompt to the model set with
+// WithLLM or, by default, LLMFromEnv. This is a convenience function that
+// creates a temporary Extractor; see Extractor.Ask for other directories.
+//
+// Example:
+//
+//	answer, err := promptext.Ask(ctx, "where is auth handled?",
+//	    promptext.WithExtensions(".go"),
+//	    promptext.WithAnswerWriter(os.Stdout),
+//	)
+func Ask(ctx context.Context, question string, opts ...Option) (*Answer, error) {
+	return NewExtractor(opts...).Ask(ctx, ".", question)
+}
+
+// Ask answers a question about the code in dir, see the package-level Ask.
+//
+// Relevance keywords are derived from the question unless WithRelevance or
+// WithCustomScorer selects files already; if no file matches them, the
+// extraction falls back to the whole project within the budget. The budget
+// is DefaultAskTokens unless WithTokenBudget or WithModel sets one.
+func (e *Extractor) Ask(ctx context.Context, dir, question string) (*Answer, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, errors.New("empty question")
+	}
+	model := e.config.llm
+	if model == nil {
+		var err error
+		if model, err = LLMFromEnv(); err != nil {
+			return nil, err
+		}
+	}
+
+	answer := &Answer{Question: question}
+	cfg := *e.config
+	if cfg.tokenBudget == 0 && cfg.model == "" {
+		cfg.tokenBudget = DefaultAskTokens
+	}
+	derived := cfg.relevanceKeywords == "" && cfg.scorer == nil
+	if derived {
+		answer.Keywords = relevance.QuestionKeywords(question)
+		cfg.relevanceKeywords = strings.Join(answer.Keywords, " ")
+	}
+
+	extractor := &Extractor{config: &cfg, treeCache: e.treeCache}
+	result, err := extractor.Extract(dir)
+	if errors.Is(err, ErrNoFilesMatched) && derived && cfg.relevanceKeywords != "" {
+		cfg.relevanceKeywords = ""
+		result, err = extractor.Extract(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	answer.Context = result
+
+	prompt, err := result.RenderPrompt("ask", map[string]string{"question": question})
+	if err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	var w io.Writer = &text
+	if cfg.answerWriter != nil {
+		w = io.MultiWriter(&text, cfg.answerWriter)
+	}
+	err = model.Stream(ctx, prompt, w)
+	answer.Text = text.String() // What arrived, even if the stream failed
+	return answer, err
+}
@@ -0,0 +1,54 @@
+package promptext
+
+import (
+	"github.com/1broseidon/promptext/internal/chunk"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// Chunk is a window of one file's content, sized for embedding.
+type Chunk struct {
+	Path      string // File the chunk came from
+	StartLine int    // First line in the chunk (1-based, inclusive)
+	EndLine   int    // Last line in the chunk (inclusive)
+	Content   string
+	Tokens    int
+}
+
+// Chunks splits the content of every included file into line-aligned windows
+// of at most maxTokens tokens, each repeating up to overlap tokens from the end
+// of the previous window of the same file. Tokens are counted with the
+// extraction's tokenizer. Chunks never span files, and a single line longer
+// than maxTokens forms a chunk of its own.
+//
+// overlap is clamped to half of maxTokens. Chunks returns nil if maxTokens is
+// not positive.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithExtensions(".go"))
+//	for _, c := range result.Chunks(512, 64) {
+//	    store.Add(c.Path, c.StartLine, c.EndLine, c.Content)
+//	}
+func (r *Result) Chunks(maxTokens, overlap int) []Chunk {
+	if maxTokens <= 0 || r.ProjectOutput == nil {
+		return nil
+	}
+	counter, err := token.NewTokenCounterFor(r.tokenizer)
+	if err != nil {
+		counter = token.NewTokenCounter()
+	}
+
+	var chunks []Chunk
+	for _, file := range r.ProjectOutput.Files {
+		for _, w := range chunk.Split(file.Content, maxTokens, overlap, counter.EstimateTokens) {
+			chunks = append(chunks, Chunk{
+				Path:      file.Path,
+				StartLine: w.StartLine,
+				EndLine:   w.EndLine,
+				Content:   w.Content,
+				Tokens:    w.Tokens,
+			})
+		}
+	}
+	return chunks
+}
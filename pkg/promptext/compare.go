@@ -0,0 +1,274 @@
+package promptext
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/diff"
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// ChangeStatus says how a file differs between the two sides of a Comparison.
+type ChangeStatus string
+
+const (
+	ChangeAdded    ChangeStatus = "added"    // Only on the "to" side
+	ChangeRemoved  ChangeStatus = "removed"  // Only on the "from" side
+	ChangeModified ChangeStatus = "modified" // On both sides, with different content
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// FileChange is a file that differs between the two sides of a Comparison.
+type FileChange struct {
+	Path       string
+	Status     ChangeStatus
+	FromTokens int // 0 for added files
+	ToTokens   int // 0 for removed files
+	TokenDelta int // ToTokens - FromTokens
+
+	// Diff is a unified diff of the file's content (see WithCompareDiffs)
+	Diff string
+}
+
+// Comparison reports how a project changed between two directories or git
+// refs (see Compare).
+type Comparison struct {
+	From, To string       // The sides as given to Compare
+	Changes  []FileChange // Added, removed and modified files, sorted by path
+
+	Added, Removed, Modified, Unchanged int
+
+	// Token totals of every file on each side, and their difference
+	FromTokens, ToTokens, TokenDelta int
+
+	// FormattedOutput is the report in the configured format: PTX (the
+	// default) or Markdown
+	FormattedOutput string
+}
+
+// Compare reports the files added, removed and modified between from and to,
+// with their token deltas and, with WithCompareDiffs, unified diffs of their
+// content. This is a convenience function that creates a temporary Extractor.
+//
+// Each side is a directory or a git ref (a branch, tag, or commit). A side
+// naming an existing directory is read from disk; anything else is read as a
+// git ref of the repository in the current directory, see CompareAt for
+// another repository. Filters and content options such as WithExtensions or
+// WithStripComments apply to both sides alike; token budgets don't apply, as
+// every matching file is compared. The report is rendered in PTX, or in
+// Markdown with WithFormat(FormatMarkdown); other formats fail with
+// ErrInvalidFormat.
+//
+// Example:
+//
+//	comparison, err := promptext.Compare("v1.0.0", "v1.1.0", promptext.WithCompareDiffs(true))
+//	for _, change := range comparison.Changes {
+//	    fmt.Printf("%s %s (%+d tokens)\n", change.Status, change.Path, change.TokenDelta)
+//	}
+func Compare(from, to string, opts ...Option) (*Comparison, error) {
+	return NewExtractor(opts...).Compare(from, to)
+}
+
+// Compare compares two directories or git refs of the repository in the
+// current directory. See the package-level Compare.
+func (e *Extractor) Compare(from, to string) (*Comparison, error) {
+	return e.CompareAt(".", from, to)
+}
+
+// CompareAt is Compare with git refs read from the repository holding dir,
+// and only from dir when it is a subdirectory of the repository.
+//
+// Example:
+//
+//	comparison, err := promptext.NewExtractor().CompareAt("services/api", "main", "feature/auth")
+func (e *Extractor) CompareAt(dir, from, to string) (*Comparison, error) {
+	reportFormat := e.config.format
+	switch reportFormat {
+	case FormatPTX, FormatTOON, FormatMarkdown, "md":
+	default:
+		return nil, &FormatError{Format: string(reportFormat), Err: ErrInvalidFormat}
+	}
+
+	fromFiles, err := e.compareSide(dir, from)
+	if err != nil {
+		return nil, err
+	}
+	toFiles, err := e.compareSide(dir, to)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &Comparison{From: from, To: to}
+	paths := make(map[string]bool, len(fromFiles)+len(toFiles))
+	for path, file := range fromFiles {
+		paths[path] = true
+		comparison.FromTokens += file.Tokens
+	}
+	for path, file := range toFiles {
+		paths[path] = true
+		comparison.ToTokens += file.Tokens
+	}
+	comparison.TokenDelta = comparison.ToTokens - comparison.FromTokens
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	for _, path := range sorted {
+		before, inFrom := fromFiles[path]
+		after, inTo := toFiles[path]
+		change := FileChange{Path: filepath.ToSlash(path), FromTokens: before.Tokens, ToTokens: after.Tokens}
+		fromName, toName := "a/"+change.Path, "b/"+change.Path
+		switch {
+		case !inFrom:
+			change.Status, fromName = ChangeAdded, "/dev/null"
+			comparison.Added++
+		case !inTo:
+			change.Status, toName = ChangeRemoved, "/dev/null"
+			comparison.Removed++
+		case before.Content != after.Content:
+			change.Status = ChangeModified
+			comparison.Modified++
+		default:
+			comparison.Unchanged++
+			continue
+		}
+		change.TokenDelta = change.ToTokens - change.FromTokens
+		if e.config.compareDiffs {
+			change.Diff = diff.Unified(fromName, toName, before.Content, after.Content, diffContext)
+		}
+		comparison.Changes = append(comparison.Changes, change)
+	}
+
+	if reportFormat == FormatMarkdown || reportFormat == "md" {
+		comparison.FormattedOutput = comparison.markdown()
+	} else if comparison.FormattedOutput, err = comparison.ptx(); err != nil {
+		return nil, &FormatError{Format: string(reportFormat), Err: err}
+	}
+	return comparison, nil
+}
+
+// compareSide extracts one side of a comparison, keyed by path: side itself
+// when it is a directory, else dir as committed at the git ref side
+func (e *Extractor) compareSide(dir, side string) (map[string]FileInfo, error) {
+	cfg := *e.config
+	if cfg.model != "" {
+		// Keep the model's tokenizer; its budget is dropped below
+		model, err := applyModel(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		cfg = *model
+		cfg.model = ""
+	}
+	cfg.tokenBudget, cfg.maxOutputTokens = 0, 0
+	cfg.gitRef = ""
+	target := side
+	if info, err := os.Stat(side); err != nil || !info.IsDir() {
+		cfg.gitRef, target = side, dir
+	}
+
+	result, err := (&Extractor{config: &cfg}).Extract(target)
+	if errors.Is(err, ErrNoFilesMatched) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error extracting %s: %w", side, err)
+	}
+	files := make(map[string]FileInfo, len(result.ProjectOutput.Files))
+	for _, file := range result.ProjectOutput.Files {
+		files[file.Path] = file
+	}
+	return files, nil
+}
+
+// ptx renders the comparison as a PTX document
+func (c *Comparison) ptx() (string, error) {
+	changes := make([]map[string]interface{}, 0, len(c.Changes))
+	diffs := make(map[string]interface{})
+	for _, change := range c.Changes {
+		changes = append(changes, map[string]interface{}{
+			"path":        change.Path,
+			"status":      string(change.Status),
+			"from_tokens": change.FromTokens,
+			"to_tokens":   change.ToTokens,
+			"token_delta": change.TokenDelta,
+		})
+		if change.Diff != "" {
+			diffs[change.Path] = change.Diff
+		}
+	}
+
+	data := map[string]interface{}{
+		"compare": map[string]interface{}{"from": c.From, "to": c.To},
+		"summary": map[string]interface{}{
+			"added":       c.Added,
+			"removed":     c.Removed,
+			"modified":    c.Modified,
+			"unchanged":   c.Unchanged,
+			"from_tokens": c.FromTokens,
+			"to_tokens":   c.ToTokens,
+			"token_delta": c.TokenDelta,
+		},
+	}
+	if len(changes) > 0 {
+		data["changes"] = changes
+	}
+	if len(diffs) > 0 {
+		data["diffs"] = diffs
+	}
+	return format.NewTOONEncoder().Encode(data)
+}
+
+// markdown renders the comparison as a Markdown document
+func (c *Comparison) markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Changes from %s to %s\n\n", c.From, c.To)
+	fmt.Fprintf(&sb, "- **Files:** %d added, %d removed, %d modified, %d unchanged\n", c.Added, c.Removed, c.Modified, c.Unchanged)
+	fmt.Fprintf(&sb, "- **Tokens:** %d → %d (%+d)\n", c.FromTokens, c.ToTokens, c.TokenDelta)
+	if len(c.Changes) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("\n## Changed Files\n\n| File | Status | Tokens | Delta |\n|------|--------|--------|-------|\n")
+	for _, change := range c.Changes {
+		fmt.Fprintf(&sb, "| `%s` | %s | %d → %d | %+d |\n", change.Path, change.Status, change.FromTokens, change.ToTokens, change.TokenDelta)
+	}
+
+	var diffs strings.Builder
+	for _, change := range c.Changes {
+		if change.Diff == "" {
+			continue
+		}
+		fence := codeFence(change.Diff)
+		fmt.Fprintf(&diffs, "\n### %s\n\n%sdiff\n%s%s\n", change.Path, fence, change.Diff, fence)
+	}
+	if diffs.Len() > 0 {
+		sb.WriteString("\n## Diffs\n")
+		sb.WriteString(diffs.String())
+	}
+	return sb.String()
+}
+
+// codeFence returns a backtick fence longer than any backtick run in content,
+// so diffs of Markdown files can't close their code block early
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
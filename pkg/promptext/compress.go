@@ -0,0 +1,152 @@
+package promptext
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// Compression is how Result.WriteTo compresses the formatted output.
+type Compression string
+
+const (
+	// CompressionNone writes the output as is.
+	CompressionNone Compression = ""
+
+	// CompressionGzip gzips the output, as gunzip and zcat read it.
+	CompressionGzip Compression = "gzip"
+)
+
+// Encoding is how Result.WriteTo encodes the output, after compressing it,
+// for systems that only pass plain ASCII text through intact.
+type Encoding string
+
+const (
+	// EncodingNone writes the output bytes as they are.
+	EncodingNone Encoding = ""
+
+	// EncodingBase64 armors the output in standard base64, wrapped at 76
+	// columns as in MIME, which base64 --decode reads back.
+	EncodingBase64 Encoding = "base64"
+)
+
+// base64LineWidth is the column base64 armor wraps at
+const base64LineWidth = 76
+
+// validEncoding reports whether the library can write output compressed
+// with compression and encoded with encoding
+func validEncoding(compression Compression, encoding Encoding) error {
+	if compression != CompressionNone && compression != CompressionGzip {
+		return fmt.Errorf("%w: compression %q", ErrUnknownEncoding, compression)
+	}
+	if encoding != EncodingNone && encoding != EncodingBase64 {
+		return fmt.Errorf("%w: encoding %q", ErrUnknownEncoding, encoding)
+	}
+	return nil
+}
+
+// WriteTo writes the formatted output to w, compressed and encoded as
+// WithCompression and WithEncoding configured the extraction; without them it
+// writes FormattedOutput unchanged. FormattedOutput itself always holds the
+// text, and TokenCount counts its tokens, not those of the compressed bytes.
+// Gzip output carries no file name or time, so identical output compresses
+// to identical bytes.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithCompression(promptext.CompressionGzip))
+//	f, _ := os.Create("context.ptx.gz")
+//	defer f.Close()
+//	if _, err := result.WriteTo(f); err != nil {
+//	    log.Fatal(err)
+//	}
+func (r *Result) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	var out io.Writer = counter
+
+	var armor io.WriteCloser
+	if r.encoding == EncodingBase64 {
+		armor = base64.NewEncoder(base64.StdEncoding, &lineWrapper{w: counter, width: base64LineWidth})
+		out = armor
+	}
+	var gz *gzip.Writer
+	if r.compression == CompressionGzip {
+		gz = gzip.NewWriter(out)
+		out = gz
+	}
+
+	if _, err := io.WriteString(out, r.FormattedOutput); err != nil {
+		return counter.n, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return counter.n, err
+		}
+	}
+	if armor != nil {
+		if err := armor.Close(); err != nil {
+			return counter.n, err
+		}
+		if _, err := io.WriteString(counter, "\n"); err != nil {
+			return counter.n, err
+		}
+	}
+	return counter.n, nil
+}
+
+// gunzipOutput returns data uncompressed if it is gzipped, as WriteTo
+// writes it with CompressionGzip, and unchanged otherwise
+func gunzipOutput(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data, nil
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	return io.ReadAll(gz)
+}
+
+// countingWriter counts the bytes written through it, for WriteTo's result
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// lineWrapper breaks the text written through it into lines of width
+// columns
+type lineWrapper struct {
+	w      io.Writer
+	width  int
+	column int
+}
+
+func (l *lineWrapper) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if l.column == l.width {
+			if _, err := l.w.Write([]byte{'\n'}); err != nil {
+				return written, err
+			}
+			l.column = 0
+		}
+		chunk := p[:min(len(p), l.width-l.column)]
+		n, err := l.w.Write(chunk)
+		written += n
+		l.column += n
+		if err != nil {
+			return written, err
+		}
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
@@ -42,15 +42,28 @@
 //	// PTX v2.0 (recommended for AI assistants)
 //	result, _ := promptext.Extract(".", promptext.WithFormat(promptext.FormatPTX))
 //
-//	// JSONL (machine-friendly, one JSON object per line)
+//	// JSONL (machine-friendly, one typed JSON object per line; result.WriteJSONL streams it)
 //	result, _ := promptext.Extract(".", promptext.WithFormat(promptext.FormatJSONL))
 //
+//	// JSON (one document; validate it with promptext.Schema(promptext.FormatJSON))
+//	result, _ := promptext.Extract(".", promptext.WithFormat(promptext.FormatJSON))
+//
 //	// Markdown (human-readable)
 //	result, _ := promptext.Extract(".", promptext.WithFormat(promptext.FormatMarkdown))
 //
 //	// XML (machine-parseable)
 //	result, _ := promptext.Extract(".", promptext.WithFormat(promptext.FormatXML))
 //
+//	// HTML (self-contained page for sharing with reviewers)
+//	result, _ := promptext.Extract(".", promptext.WithFormat(promptext.FormatHTML))
+//
+//	// PDF (paginated archival document; write FormattedOutput to a file)
+//	result, _ := promptext.Extract(".", promptext.WithFormat(promptext.FormatPDF))
+//
+//	// Your own Go template, with the helpers from TemplateFuncs
+//	tmpl := template.Must(template.New("context").Funcs(promptext.TemplateFuncs()).ParseFiles("context.tmpl"))
+//	result, _ := promptext.Extract(".", promptext.WithTemplate(tmpl))
+//
 // # Format Conversion
 //
 // Convert results to different formats without re-processing:
@@ -59,6 +72,24 @@
 //	markdownOutput, _ := result.As(promptext.FormatMarkdown)
 //	jsonlOutput, _ := result.As(promptext.FormatJSONL)
 //
+// Save writes the whole result to a versioned JSON file and LoadResult reads
+// it back, so the extraction can be formatted or chunked later, elsewhere:
+//
+//	_ = result.Save("context.result.json")
+//	loaded, _ := promptext.LoadResult("context.result.json")
+//	chunks := loaded.Chunks(512, 64)
+//
+// WithHashes records each file's SHA-256 and a content hash in the PTX,
+// JSONL, JSON or XML manifest; Verify reports which files changed since:
+//
+//	v, _ := promptext.Verify("context.ptx", ".")
+//	fmt.Println(v.Changed, v.Missing)
+//
+// Plan suggests the excludes that bring a project within a token budget:
+//
+//	plan, _ := promptext.Plan(".", promptext.WithTokenBudget(50000))
+//	fmt.Println(plan.Excludes(), plan.Fits())
+//
 // # Reusable Extractors
 //
 // Create an extractor to process multiple directories with the same configuration:
@@ -84,6 +115,24 @@
 //	promptext.RegisterFormatter("myformat", &MyFormatter{})
 //	result, _ := promptext.Extract(".", promptext.WithFormat("myformat"))
 //
+// RegisterFormatterInfo also records a description, file extensions and MIME
+// type, which Formatters lists alongside the built-in formats. A formatter
+// that implements ContextFormatter renders a FormatContext instead, with the
+// excluded files, relevance scores and token budget of the extraction.
+//
+// # Custom Filter Rules
+//
+// RegisterFilterRule adds a rule that includes, excludes, or adjusts the
+// relevance score of files for every extraction. Content is read only when
+// the rule asks for it:
+//
+//	promptext.RegisterFilterRule(promptext.NewFilterRule("phi", func(f promptext.FilterFile) promptext.FilterDecision {
+//	    if content, _ := f.Content(); strings.Contains(content, "PHI-RESTRICTED") {
+//	        return promptext.FilterDecision{Action: promptext.FilterExclude, Reason: "PHI marker"}
+//	    }
+//	    return promptext.FilterDecision{}
+//	}))
+//
 // # Error Handling
 //
 // The library provides typed errors for common cases:
@@ -119,19 +168,128 @@
 //	    fmt.Printf("%s: %d tokens\n", file.Path, file.Tokens)
 //	}
 //
+// # Chunking for Retrieval
+//
+// Split file contents into overlapping, line-aligned windows for embedding:
+//
+//	for _, c := range result.Chunks(512, 64) {
+//	    fmt.Printf("%s:%d-%d (%d tokens)\n", c.Path, c.StartLine, c.EndLine, c.Tokens)
+//	}
+//
+// # Explaining Selection
+//
+// Find out which rule kept a file out, or that it made it in:
+//
+//	fmt.Println(result.Explain("web/dist/app.js"))
+//	// web/dist/app.js: excluded by gitignore (web/.gitignore: dist/)
+//
+// # Previewing an Extraction
+//
+// Preview lists the files an extraction would process, with a token
+// estimate and the effective configuration, without reading any content:
+//
+//	preview, _ := promptext.Preview(".", promptext.WithProfile("review"))
+//	fmt.Printf("%d files, ~%d tokens\n", len(preview.Files), preview.EstimatedTokens)
+//
+// # Comparing Versions
+//
+// Compare lists the files added, removed and modified between two directories
+// or git refs, with token deltas and optional unified diffs:
+//
+//	comparison, _ := promptext.Compare("v1.0.0", "v1.1.0", promptext.WithCompareDiffs(true))
+//	fmt.Println(comparison.FormattedOutput)
+//
+// # Prompt Templates
+//
+// Wrap the output in a built-in (code-review, refactor, document,
+// test-generation, migration) or project-defined prompt template:
+//
+//	prompt, err := result.RenderPrompt("code-review", map[string]string{"focus": "security"})
+//
+// # Asking Questions
+//
+// Ask selects the files relevant to a question and streams a language
+// model's answer; the model comes from the environment (LLMFromEnv) or WithLLM:
+//
+//	answer, err := promptext.Ask(ctx, "where is auth handled?", promptext.WithAnswerWriter(os.Stdout))
+//
 // # Configuration Options
 //
 // Available options:
 //
 //   - WithExtensions(extensions ...string) - Include specific file extensions
 //   - WithExcludes(patterns ...string) - Exclude files matching patterns
+//   - WithIncludes(patterns ...string) - Include only paths matching globs
+//   - WithFileList(paths []string) - Process exactly the listed files instead of walking the directory
+//   - WithContentExcludes(patterns ...string) - Exclude files whose content matches regular expressions
+//   - WithSkipGenerated(enabled bool) - Skip generated and minified files (default: true)
+//   - WithDataSampling(rows int) - Keep only the header and first rows of CSV, TSV and JSON Lines files
+//   - WithSkippedFileStubs(enabled bool) - List skipped binary, oversized, and generated files as stubs
 //   - WithGitIgnore(enabled bool) - Respect .gitignore patterns (default: true)
 //   - WithDefaultRules(enabled bool) - Use built-in filtering rules (default: true)
 //   - WithRelevance(keywords ...string) - Filter by keyword relevance
+//   - WithRelevanceExclude(keywords ...string) - Exclude files whose path has a negative keyword
+//   - WithExactKeywords(enabled bool) - Match keywords without stemming or synonyms
+//   - WithRecencyBoost(enabled bool) - Rank recently and frequently changed files higher
+//   - WithSynonyms(synonyms map[string][]string) - Extra words keywords match
+//   - WithCustomScorer(s Scorer) - Rank files with a custom scorer, such as embedding.New
+//   - WithFollowImports(depth int) - Pull in files imported by highly relevant files
+//   - WithSnippets(contextLines int) - Keep only the definitions matching relevance keywords
+//   - WithExplainSelection(enabled bool) - Report each file's score, factors, and inclusion
+//   - WithTreeOnly(enabled bool) - Output only the directory tree with per-directory token totals
 //   - WithTokenBudget(maxTokens int) - Limit output to token budget
+//   - WithMaxOutputTokens(maxTokens int) - Hard cap on the rendered output size
+//   - WithSplitTokens(maxTokens int) - Token budget per part for ExtractSplit
 //   - WithFormat(format Format) - Set output format
+//   - WithMarkdown(options MarkdownOptions) - Table of contents, anchors and collapsed files in markdown
+//   - WithTemplate(tmpl *template.Template) - Render the output through a Go template
+//   - WithCompression(compression Compression) - Gzip the output Result.WriteTo writes
+//   - WithEncoding(encoding Encoding) - Armor the output Result.WriteTo writes in base64
 //   - WithVerbose(enabled bool) - Enable verbose logging
 //   - WithDebug(enabled bool) - Enable debug logging with timing
+//   - WithLogger(h slog.Handler) - Send log records to a slog handler instead of stderr
+//   - WithTestAssociations(enabled bool) - Pair files with the tests that cover them
+//   - WithIncludeTests(mode TestInclusion) - TestsPaired brings the tests of selected files along
+//   - WithCoreDirs(dirs ...string) - Directories treated as core code for prioritization
+//   - WithEntryPoints(globs ...string) - Globs naming the files that start the project
+//   - WithBudgetWeights(weights map[string]float64) - Per-directory and per-extension shares of the token budget
+//   - WithAnnotations(annotations map[string]string) - Notes rendered with the files matching each glob
+//   - WithOwners(enabled bool) - Attach CODEOWNERS owners to files, with a per-owner rollup
+//   - WithTodoScan(enabled bool) - List TODO, FIXME and HACK comments in an issues section
+//   - WithLicenseScan(enabled bool) - Inventory LICENSE files and SPDX headers, flagging missing or conflicting headers
+//   - WithMetrics(enabled bool) - Add per-file line, function and complexity metrics
+//   - WithAuditDeps(enabled bool) - List known vulnerabilities of the dependencies from OSV
+//   - WithAuditReport(path string) - List vulnerabilities from an osv-scanner JSON report
+//   - WithWorkspacePackage(name string) - Extract one workspace member and the members it depends on
+//   - WithExtraFiles(paths ...string) - Include specific files from outside the directory
+//   - WithSymlinks(policy SymlinkPolicy) - Skip (default) or follow symlinks, with cycle detection
+//   - WithMaxFileSize(bytes int64) - Skip files larger than bytes (default and maximum: 10 MB)
+//   - WithMaxTotalSize(bytes int64) - Fail before reading when the files total more than bytes
+//   - WithDropSizeOutliers(percentile float64) - Drop files above a size percentile
+//   - WithGitLog(n int) - Include the last n commits as context
+//   - WithDeterministic(enabled bool) - Byte-identical output for unchanged files, without git HEAD info
+//   - WithHashes(enabled bool) - File hashes and a content hash in the manifest, for Verify
+//   - WithGitRef(ref string) - Read files as committed at a branch, tag, or commit
+//   - WithCompareDiffs(enabled bool) - Include unified diffs in Compare reports
+//   - WithMaxFileTokens(maxTokens int) - Truncate files above a per-file token limit
+//   - WithTruncationStrategy(strategy TruncationStrategy) - head, head-tail, or signatures
+//   - WithCache(dir string) - Reuse processed files across runs via an on-disk cache
+//   - WithUserCache(enabled bool) - The same cache, kept in the user's cache directory
+//   - WithConcurrency(n int) - Number of files processed in parallel (default: one per CPU)
+//   - WithProgress(fn func(ProgressEvent)) - Report progress as files are scanned and processed
+//   - WithStripImports(enabled bool) - Move import blocks out of file content into the dependencies section
+//   - WithRedaction(enabled bool) - Replace detected secrets with placeholders
+//   - WithStripComments(enabled bool) - Remove comments, leaving strings that contain comment markers intact
+//   - WithSquashBlankLines(enabled bool) - Collapse runs of blank lines into one
+//   - WithCompact(mode CompactMode) - Trim whitespace (CompactWhitespace) and de-indent (CompactIndent)
+//   - WithNotebookOutputs(enabled bool) - Keep cell outputs when flattening Jupyter notebooks
+//   - WithSummarizeLockfiles(enabled bool) - Summarize lockfiles and minified bundles instead of including them raw (default: true)
+//   - WithTransform(fn func(path string, content []byte) ([]byte, error)) - Rewrite file content before the built-in transforms
+//   - WithProfile(name string) - Apply a named profile from .promptext.yml
+//   - WithConfigFiles(enabled bool) - Apply the global config and .promptext.yml
+//   - WithTokenizer(tokenizer Tokenizer) - Count tokens as cl100k, o200k, claude, chars, or with a local model (OllamaTokenizer, LMStudioTokenizer)
+//   - WithModel(name string) - Budget and tokenizer for a target model's context window
+//   - WithResponseReserve(tokens int) - Tokens of the model window kept for the response
 //
 // # Design Principles
 //
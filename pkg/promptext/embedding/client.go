@@ -0,0 +1,124 @@
+package embedding
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default endpoints
+const (
+	DefaultOpenAIURL = "https://api.openai.com/v1"
+	DefaultOllamaURL = "http://localhost:11434"
+)
+
+// defaultClient bounds requests so an unreachable model fails the extraction
+// instead of hanging it
+var defaultClient = &http.Client{Timeout: 2 * time.Minute}
+
+// OpenAIEmbedder calls the /embeddings endpoint of the OpenAI API or any
+// compatible server (LM Studio, vLLM, LocalAI)
+type OpenAIEmbedder struct {
+	BaseURL string // API root, e.g. https://api.openai.com/v1
+	APIKey  string // Sent as a bearer token when set
+	Model   string
+	Client  *http.Client
+}
+
+// NewOpenAIEmbedder creates an embedder for the OpenAI API
+func NewOpenAIEmbedder(apiKey, model string) *OpenAIEmbedder {
+	return &OpenAIEmbedder{BaseURL: DefaultOpenAIURL, APIKey: apiKey, Model: model}
+}
+
+// Embed implements Embedder
+func (o *OpenAIEmbedder) Embed(texts []string) ([][]float64, error) {
+	var response struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	request := map[string]any{"model": o.Model, "input": texts}
+	if err := postJSON(o.Client, endpoint(o.BaseURL, DefaultOpenAIURL, "/embeddings"), o.APIKey, request, &response); err != nil {
+		return nil, err
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, item := range response.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", item.Index)
+		}
+		vectors[item.Index] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// OllamaEmbedder calls a local Ollama server's /api/embed endpoint
+type OllamaEmbedder struct {
+	BaseURL string // Server root, e.g. http://localhost:11434
+	Model   string
+	Client  *http.Client
+}
+
+// NewOllamaEmbedder creates an embedder for an Ollama server; an empty
+// baseURL means DefaultOllamaURL
+func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
+	return &OllamaEmbedder{BaseURL: baseURL, Model: model}
+}
+
+// Embed implements Embedder
+func (o *OllamaEmbedder) Embed(texts []string) ([][]float64, error) {
+	var response struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	request := map[string]any{"model": o.Model, "input": texts}
+	if err := postJSON(o.Client, endpoint(o.BaseURL, DefaultOllamaURL, "/api/embed"), "", request, &response); err != nil {
+		return nil, err
+	}
+	return response.Embeddings, nil
+}
+
+// endpoint joins a base URL, or fallback when it is empty, with path
+func endpoint(base, fallback, path string) string {
+	if base == "" {
+		base = fallback
+	}
+	return strings.TrimRight(base, "/") + path
+}
+
+// postJSON sends body as JSON and decodes a successful response into out
+func postJSON(client *http.Client, url, apiKey string, body, out any) error {
+	if client == nil {
+		client = defaultClient
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, strings.TrimSpace(string(detail)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding response from %s: %w", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package embedding provides a promptext.Scorer that ranks files by the
+// semantic similarity of their content to a query, using an embedding model
+// served by OpenAI (or any OpenAI-compatible API) or a local Ollama.
+//
+// Keyword relevance only finds files that mention the query's words; an
+// embedding scorer also finds files that are about the same thing:
+//
+//	scorer := embedding.New("where are API tokens validated?",
+//	    embedding.NewOpenAIEmbedder(os.Getenv("OPENAI_API_KEY"), "text-embedding-3-small"))
+//	result, err := promptext.Extract(".",
+//	    promptext.WithCustomScorer(scorer),
+//	    promptext.WithTokenBudget(8000),
+//	)
+//
+// The package only uses the standard library and is never imported by
+// promptext itself, so programs that do not use it pay nothing for it.
+package embedding
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/1broseidon/promptext/pkg/promptext"
+)
+
+// Defaults for Scorer
+const (
+	DefaultMinSimilarity = 0.2
+	DefaultMaxChars      = 8000
+	DefaultBatchSize     = 64
+)
+
+// Embedder turns texts into embedding vectors, one per text and in order
+type Embedder interface {
+	Embed(texts []string) ([][]float64, error)
+}
+
+// Scorer scores files by cosine similarity between their embedding and the
+// query's. A file's score is its similarity multiplied by 10, so a similarity
+// of 0.5 reaches promptext.RelevanceThreshold; files below MinSimilarity
+// score 0 and are excluded.
+//
+// Embeddings are cached by content, so scoring the same files again (as
+// promptext does when trimming to an output cap) does not call the model
+// twice. A Scorer is safe for concurrent use.
+type Scorer struct {
+	MinSimilarity float64 // Similarity below which a file scores 0
+	MaxChars      int     // Characters of each file sent to the model
+	BatchSize     int     // Texts per Embed call
+
+	query    string
+	embedder Embedder
+
+	mu       sync.Mutex
+	queryVec []float64
+	cache    map[[sha256.Size]byte][]float64
+}
+
+// New creates a scorer ranking files against query with the given embedder
+func New(query string, embedder Embedder) *Scorer {
+	return &Scorer{
+		MinSimilarity: DefaultMinSimilarity,
+		MaxChars:      DefaultMaxChars,
+		BatchSize:     DefaultBatchSize,
+		query:         query,
+		embedder:      embedder,
+		cache:         make(map[[sha256.Size]byte][]float64),
+	}
+}
+
+// Score implements promptext.Scorer
+func (s *Scorer) Score(files []promptext.ScoreInput) ([]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.queryVec == nil {
+		vectors, err := s.embed([]string{s.query})
+		if err != nil {
+			return nil, fmt.Errorf("embedding query: %w", err)
+		}
+		s.queryVec = vectors[0]
+	}
+
+	// Embed only the files not seen before, a batch at a time
+	texts := make([]string, len(files))
+	keys := make([][sha256.Size]byte, len(files))
+	var pending []int
+	queued := make(map[[sha256.Size]byte]bool)
+	for i, file := range files {
+		texts[i] = s.document(file)
+		keys[i] = sha256.Sum256([]byte(texts[i]))
+		if _, ok := s.cache[keys[i]]; !ok && !queued[keys[i]] {
+			queued[keys[i]] = true
+			pending = append(pending, i)
+		}
+	}
+	batchSize := s.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	for start := 0; start < len(pending); start += batchSize {
+		batch := pending[start:min(start+batchSize, len(pending))]
+		batchTexts := make([]string, len(batch))
+		for j, i := range batch {
+			batchTexts[j] = texts[i]
+		}
+		vectors, err := s.embed(batchTexts)
+		if err != nil {
+			return nil, fmt.Errorf("embedding files: %w", err)
+		}
+		for j, i := range batch {
+			s.cache[keys[i]] = vectors[j]
+		}
+	}
+
+	scores := make([]float64, len(files))
+	for i := range files {
+		similarity := Cosine(s.queryVec, s.cache[keys[i]])
+		if similarity >= s.MinSimilarity {
+			scores[i] = similarity * 10
+		}
+	}
+	return scores, nil
+}
+
+// document is the text embedded for a file: its path, then its content cut
+// to MaxChars
+func (s *Scorer) document(file promptext.ScoreInput) string {
+	content := file.Content
+	if s.MaxChars > 0 && len(content) > s.MaxChars {
+		content = content[:s.MaxChars]
+	}
+	return file.Path + "\n\n" + content
+}
+
+// embed calls the embedder and checks it returned one vector per text
+func (s *Scorer) embed(texts []string) ([][]float64, error) {
+	vectors, err := s.embedder.Embed(texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// Cosine returns the cosine similarity of two vectors, or 0 if either is
+// empty, all zeros, or their lengths differ
+func Cosine(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,155 @@
+package embedding
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/promptext/pkg/promptext"
+)
+
+// fakeEmbedder maps texts to 2-d vectors by the words they contain and counts
+// how many texts it was asked to embed
+type fakeEmbedder struct {
+	calls int
+	texts int
+}
+
+func (f *fakeEmbedder) Embed(texts []string) ([][]float64, error) {
+	f.calls++
+	f.texts += len(texts)
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		switch {
+		case strings.Contains(text, "login"):
+			vectors[i] = []float64{1, 0.1}
+		case strings.Contains(text, "auth"):
+			vectors[i] = []float64{1, 0}
+		default:
+			vectors[i] = []float64{0, 1}
+		}
+	}
+	return vectors, nil
+}
+
+func TestScorer_Score(t *testing.T) {
+	embedder := &fakeEmbedder{}
+	scorer := New("auth", embedder)
+	files := []promptext.ScoreInput{
+		{Path: "session.go", Content: "func login() {}"},
+		{Path: "render.go", Content: "func draw() {}"},
+	}
+
+	scores, err := scorer.Score(files)
+	if err != nil {
+		t.Fatalf("Score failed: %v", err)
+	}
+	if scores[0] < promptext.RelevanceThreshold {
+		t.Errorf("expected session.go above the relevance threshold, got %.2f", scores[0])
+	}
+	if scores[1] != 0 {
+		t.Errorf("expected render.go below MinSimilarity to score 0, got %.2f", scores[1])
+	}
+
+	// A second pass is served from the cache
+	if _, err := scorer.Score(files); err != nil {
+		t.Fatalf("Score failed: %v", err)
+	}
+	if embedder.texts != 3 {
+		t.Errorf("expected the query and two files embedded once, got %d texts", embedder.texts)
+	}
+}
+
+func TestScorer_Batches(t *testing.T) {
+	embedder := &fakeEmbedder{}
+	scorer := New("auth", embedder)
+	scorer.BatchSize = 2
+
+	files := make([]promptext.ScoreInput, 5)
+	for i := range files {
+		files[i] = promptext.ScoreInput{Path: string(rune('a'+i)) + ".go"}
+	}
+	if _, err := scorer.Score(files); err != nil {
+		t.Fatalf("Score failed: %v", err)
+	}
+	if embedder.calls != 4 { // query + 3 batches
+		t.Errorf("expected 4 Embed calls, got %d", embedder.calls)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	if got := Cosine([]float64{1, 0}, []float64{1, 0}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("expected identical vectors to have similarity 1, got %f", got)
+	}
+	if got := Cosine([]float64{1, 0}, []float64{0, 1}); got != 0 {
+		t.Errorf("expected orthogonal vectors to have similarity 0, got %f", got)
+	}
+	if got := Cosine([]float64{1}, []float64{1, 0}); got != 0 {
+		t.Errorf("expected mismatched lengths to give 0, got %f", got)
+	}
+}
+
+func TestOpenAIEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer sk-test" {
+			t.Errorf("unexpected Authorization header %q", got)
+		}
+		var request struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.Model != "small" || len(request.Input) != 2 {
+			t.Errorf("unexpected request %+v", request)
+		}
+		// Out of order, as the API permits
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder := NewOpenAIEmbedder("sk-test", "small")
+	embedder.BaseURL = server.URL + "/v1/"
+	vectors, err := embedder.Embed([]string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Errorf("expected vectors ordered by index, got %v", vectors)
+	}
+}
+
+func TestOllamaEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"embeddings":[[0.5,0.5]]}`))
+	}))
+	defer server.Close()
+
+	vectors, err := NewOllamaEmbedder(server.URL, "nomic-embed-text").Embed([]string{"a"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 1 || len(vectors[0]) != 2 {
+		t.Errorf("unexpected vectors %v", vectors)
+	}
+}
+
+func TestEmbedder_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := New("q", NewOllamaEmbedder(server.URL, "missing")).Score([]promptext.ScoreInput{{Path: "a.go"}})
+	if err == nil || !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("expected the server error to surface, got %v", err)
+	}
+}
@@ -3,6 +3,13 @@ package promptext
 import (
 	"errors"
 	"fmt"
+
+	"github.com/1broseidon/promptext/internal/archive"
+	fileconfig "github.com/1broseidon/promptext/internal/config"
+	"github.com/1broseidon/promptext/internal/llm"
+	"github.com/1broseidon/promptext/internal/processor"
+	"github.com/1broseidon/promptext/internal/token"
+	"github.com/1broseidon/promptext/pkg/promptext/prompts"
 )
 
 // Sentinel errors for common failure cases.
@@ -11,6 +18,9 @@ var (
 	// ErrInvalidDirectory is returned when the provided directory path is invalid or inaccessible.
 	ErrInvalidDirectory = errors.New("invalid or inaccessible directory")
 
+	// ErrInvalidArchive is returned by ExtractArchive when the data is not a readable zip, tar, or tar.gz archive.
+	ErrInvalidArchive = errors.New("invalid or unsupported archive")
+
 	// ErrNoFilesMatched is returned when no files match the specified criteria.
 	ErrNoFilesMatched = errors.New("no files matched the specified criteria")
 
@@ -19,6 +29,43 @@ var (
 
 	// ErrInvalidFormat is returned when an unsupported output format is requested.
 	ErrInvalidFormat = errors.New("invalid or unsupported output format")
+
+	// ErrStreamingUnsupported is returned by ExtractStream when an option needs the
+	// whole file set up front (relevance ranking, token budgets, and similar).
+	ErrStreamingUnsupported = errors.New("option not supported in streaming mode")
+
+	// ErrUnknownProfile is returned when WithProfile names a profile no config file defines.
+	ErrUnknownProfile = fileconfig.ErrUnknownProfile
+
+	// ErrUnknownTokenizer is returned when WithTokenizer names an unsupported tokenizer.
+	ErrUnknownTokenizer = token.ErrUnknownTokenizer
+
+	// ErrUnknownModel is returned when WithModel names a model not in the built-in table.
+	ErrUnknownModel = token.ErrUnknownModel
+
+	// ErrUnknownPrompt is returned when RenderPrompt names a template that is neither built in nor in .promptext/prompts.
+	ErrUnknownPrompt = prompts.ErrUnknownTemplate
+
+	// ErrUnknownGitRef is returned when WithGitRef names no commit of the repository.
+	ErrUnknownGitRef = archive.ErrUnknownRef
+
+	// ErrUnsupportedResultFile is returned by LoadResult when the file is not a saved result or was written by a newer, incompatible version.
+	ErrUnsupportedResultFile = errors.New("unsupported saved result file")
+
+	// ErrNoHashes is returned by Verify when the context wasn't written with WithHashes, or in a format that records no hashes.
+	ErrNoHashes = errors.New("context has no hashes")
+
+	// ErrNoTokenBudget is returned by Plan when neither WithTokenBudget nor WithModel sets a budget to plan for.
+	ErrNoTokenBudget = errors.New("no token budget to plan for")
+
+	// ErrTotalSizeExceeded is returned when the files to extract add up to more than WithMaxTotalSize allows.
+	ErrTotalSizeExceeded = processor.ErrTotalSizeExceeded
+
+	// ErrUnknownEncoding is returned when WithCompression or WithEncoding names an unsupported scheme.
+	ErrUnknownEncoding = errors.New("unknown output compression or encoding")
+
+	// ErrNoLLM is returned by Ask and LLMFromEnv when no language model is configured in the environment.
+	ErrNoLLM = llm.ErrNotConfigured
 )
 
 // DirectoryError wraps directory-related errors with additional context.
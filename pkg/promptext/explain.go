@@ -0,0 +1,189 @@
+package promptext
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/processor"
+)
+
+// Explanation says why a file is or isn't part of a Result (see Result.Explain).
+type Explanation struct {
+	Path     string // Relative to the extracted directory
+	Included bool
+
+	// Rule names what left the file out:
+	//   - a filter rule: "exclude", "gitignore", "default", "extension",
+	//     "allowlist", "include-path", "binary", "lockfile", "generated",
+	//     "content-pattern", or "too-large"
+	//   - a selection step: "relevance", "negative-keyword", "token-budget",
+	//     "output-cap", or "size-outlier"
+	//   - "custom-rule", a rule added with RegisterFilterRule; Detail holds
+	//     its name and reason
+	//   - "not-found", "directory", "not-listed" (outside the extracted roots
+	//     or file list), or "unreadable"
+	//
+	// It is empty for included files, and for files that pass every rule yet
+	// are not in this Result's files (a part of a split extraction, for example).
+	Rule string
+
+	// Detail is what matched: the exclude or default pattern, the ignore file
+	// and pattern ("packages/web/.gitignore: dist/"), or the extension
+	Detail string
+
+	// Dir is the parent directory the rule excluded, when it matched that
+	// directory rather than the file itself
+	Dir string
+
+	// Truncated is set for an included file whose content was shortened to
+	// fit the token budget
+	Truncated bool
+}
+
+// ruleDescriptions phrase rules for Explanation.String
+var ruleDescriptions = map[string]string{
+	"exclude":          "exclude pattern",
+	"default":          "default rule",
+	"extension":        "extension filter",
+	"include-path":     "include paths",
+	"binary":           "binary detection",
+	"lockfile":         "lockfile detection",
+	"generated":        "generated-file detection",
+	"content-pattern":  "content exclude",
+	"too-large":        "size limit",
+	"relevance":        "relevance filter",
+	"negative-keyword": "negative relevance keyword",
+	"token-budget":     "token budget",
+	"output-cap":       "output cap",
+	"size-outlier":     "size outlier filter",
+	"custom-rule":      "custom filter rule",
+}
+
+// String describes the explanation in one line, e.g.
+// "dist/app.js: excluded by gitignore (.gitignore: dist/)".
+func (e Explanation) String() string {
+	switch {
+	case e.Included && e.Truncated:
+		return e.Path + ": included (truncated to fit the token budget)"
+	case e.Included:
+		return e.Path + ": included"
+	case e.Rule == "":
+		return e.Path + ": passes all filters but is not in this output"
+	case e.Rule == processor.ExplainNotFound:
+		return e.Path + ": not found in the project"
+	case e.Rule == processor.ExplainDirectory:
+		return e.Path + ": is a directory"
+	case e.Rule == processor.ExplainNotListed:
+		return e.Path + ": outside the extracted directories or file list"
+	case e.Rule == processor.ExplainUnreadable:
+		return e.Path + ": not readable"
+	}
+
+	rule := e.Rule
+	if description, ok := ruleDescriptions[rule]; ok {
+		rule = description
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: excluded by %s", e.Path, rule)
+	if e.Dir != "" {
+		fmt.Fprintf(&b, " on parent directory %s", filepath.ToSlash(e.Dir))
+	}
+	if e.Detail != "" {
+		fmt.Fprintf(&b, " (%s)", e.Detail)
+	}
+	return b.String()
+}
+
+// Explain traces why path is or isn't in the result: which filter rule
+// matched (an exclude, a .gitignore pattern, a default rule, the extension list,
+// binary detection, ...) or which selection step dropped it (relevance, the
+// token budget). path is relative to the extracted directory, or absolute.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithExtensions(".go"))
+//	fmt.Println(result.Explain("web/dist/app.js"))
+//	// web/dist/app.js: excluded by gitignore (web/.gitignore: dist/)
+func (r *Result) Explain(path string) Explanation {
+	if r.config != nil && filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(r.config.DirPath, path); err == nil {
+			path = rel
+		}
+	}
+	path = filepath.Clean(path)
+	explanation := Explanation{Path: filepath.ToSlash(path)}
+	if strings.HasPrefix(explanation.Path, "../") || explanation.Path == ".." {
+		explanation.Rule = processor.ExplainNotFound
+		return explanation
+	}
+
+	if r.ProjectOutput != nil {
+		for _, file := range r.ProjectOutput.Files {
+			if filepath.Clean(file.Path) == path {
+				explanation.Included = true
+				explanation.Truncated = file.Truncation != nil
+				return explanation
+			}
+		}
+		for _, stub := range r.ProjectOutput.SkippedFiles {
+			if filepath.Clean(stub.Path) == path {
+				explanation.Rule = stub.Reason
+				return explanation
+			}
+		}
+	}
+	for _, excluded := range r.ExcludedFileList {
+		if filepath.Clean(excluded.Path) == path {
+			explanation.Rule = excluded.Reason
+			return explanation
+		}
+	}
+
+	if r.config != nil {
+		decision := processor.ExplainFile(*r.config, path)
+		explanation.Rule, explanation.Detail, explanation.Dir = decision.Rule, decision.Detail, filepath.ToSlash(decision.Dir)
+	}
+	return explanation
+}
+
+// Explain extracts dir and explains each of paths against the result, see
+// Result.Explain. Unlike Extract it also works when no file matches, which
+// is often exactly what needs explaining. This is a convenience function that
+// creates a temporary Extractor.
+//
+// Example:
+//
+//	explanations, err := promptext.Explain(".", []string{"vendor/lib.go"}, promptext.WithExtensions(".go"))
+func Explain(dir string, paths []string, opts ...Option) ([]Explanation, error) {
+	return NewExtractor(opts...).Explain(dir, paths...)
+}
+
+// Explain extracts dir and explains each of paths against the result.
+// See the package-level Explain.
+func (e *Extractor) Explain(dir string, paths ...string) ([]Explanation, error) {
+	defer e.useLogger()()
+	procConfig, formatter, warnings, err := e.prepare(dir)
+	if err != nil {
+		return nil, err
+	}
+	result, err := e.extract(procConfig, formatter, warnings)
+	if errors.Is(err, ErrNoFilesMatched) {
+		// Nothing was kept, but processing still records what selection dropped
+		procResult, err := processor.ProcessDirectory(procConfig, false)
+		if err != nil {
+			return nil, fmt.Errorf("error processing directory: %w", err)
+		}
+		result = fromInternalProcessResult(procResult, "")
+		result.config = &procConfig
+	} else if err != nil {
+		return nil, err
+	}
+
+	explanations := make([]Explanation, len(paths))
+	for i, path := range paths {
+		explanations[i] = result.Explain(path)
+	}
+	return explanations, nil
+}
@@ -0,0 +1,124 @@
+package promptext
+
+import (
+	"sync"
+
+	"github.com/1broseidon/promptext/internal/processor"
+)
+
+// FilterAction is what a FilterRule decides to do with a file.
+type FilterAction int
+
+const (
+	FilterPass    FilterAction = iota // No opinion; the built-in rules decide
+	FilterInclude                     // Keep the file even if generated-file detection or WithContentExcludes would skip it
+	FilterExclude                     // Leave the file out
+)
+
+// FilterDecision is a FilterRule's verdict on one file.
+type FilterDecision struct {
+	Action FilterAction
+
+	// ScoreAdjustment is added to the file's relevance score when relevance
+	// scoring is on (WithRelevance or WithCustomScorer). A file whose score
+	// drops to 0 is excluded as irrelevant; RelevanceThreshold ranks it first.
+	ScoreAdjustment float64
+
+	// Reason says why the file was excluded, for Explain
+	Reason string
+}
+
+// FilterFile is the file a FilterRule decides on.
+type FilterFile struct {
+	Path string // Relative to the extracted directory, with the OS separator
+
+	content func() (string, error)
+}
+
+// Content reads the file's content on first call, so rules that decide by
+// path alone never cause a read.
+func (f FilterFile) Content() (string, error) {
+	if f.content == nil {
+		return "", nil
+	}
+	return f.content()
+}
+
+// FilterRule is a custom filter rule, added to every extraction with
+// RegisterFilterRule. It sees each file that passes the built-in path,
+// size and binary checks, before content-based rules.
+type FilterRule interface {
+	// Name identifies the rule in explanations
+	Name() string
+	// Apply decides on a file. It is called from several goroutines at once.
+	Apply(file FilterFile) FilterDecision
+}
+
+// funcRule is a FilterRule made of a function (see NewFilterRule)
+type funcRule struct {
+	name  string
+	apply func(FilterFile) FilterDecision
+}
+
+func (r funcRule) Name() string                         { return r.name }
+func (r funcRule) Apply(file FilterFile) FilterDecision { return r.apply(file) }
+
+// NewFilterRule returns a FilterRule named name that decides with apply.
+func NewFilterRule(name string, apply func(FilterFile) FilterDecision) FilterRule {
+	return funcRule{name: name, apply: apply}
+}
+
+var (
+	filterRulesMu sync.RWMutex
+	filterRules   []FilterRule
+)
+
+// RegisterFilterRule adds a custom filter rule to every extraction that
+// starts after the call, for organization-wide policies such as skipping
+// files that carry PHI markers. Rules run in registration order; the first
+// to include or exclude a file decides, and the score adjustments of all of
+// them add up. Explain reports exclusions as "custom-rule" with the rule's
+// name and reason. Preview, which reads no content, does not run rules.
+//
+// Example:
+//
+//	promptext.RegisterFilterRule(promptext.NewFilterRule("phi", func(f promptext.FilterFile) promptext.FilterDecision {
+//	    content, err := f.Content()
+//	    if err == nil && strings.Contains(content, "PHI-RESTRICTED") {
+//	        return promptext.FilterDecision{Action: promptext.FilterExclude, Reason: "PHI marker"}
+//	    }
+//	    return promptext.FilterDecision{}
+//	}))
+func RegisterFilterRule(rule FilterRule) {
+	filterRulesMu.Lock()
+	defer filterRulesMu.Unlock()
+	filterRules = append(filterRules, rule)
+}
+
+// registeredFileRules returns the registered filter rules as processor rules
+func registeredFileRules() []processor.FileRule {
+	filterRulesMu.RLock()
+	defer filterRulesMu.RUnlock()
+	if len(filterRules) == 0 {
+		return nil
+	}
+	rules := make([]processor.FileRule, len(filterRules))
+	for i, rule := range filterRules {
+		rule := rule
+		rules[i] = processor.FileRule{
+			Name: rule.Name(),
+			Decide: func(path string, content func() (string, error)) processor.RuleDecision {
+				decision := rule.Apply(FilterFile{Path: path, content: content})
+				action := processor.RulePass
+				switch decision.Action {
+				case FilterInclude:
+					action = processor.RuleInclude
+				case FilterExclude:
+					action = processor.RuleExclude
+				}
+				return processor.RuleDecision{Action: action, ScoreAdjustment: decision.ScoreAdjustment, Reason: decision.Reason}
+			},
+		}
+	}
+	return rules
+}
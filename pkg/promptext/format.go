@@ -1,7 +1,12 @@
 package promptext
 
 import (
+	"fmt"
+	"sort"
+	"text/template"
+
 	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/processor"
 )
 
 // Format represents the output format type for code extraction.
@@ -19,14 +24,30 @@ const (
 	// FormatTOONStrict is the TOON v1.3 strict compliance format with escaped strings.
 	FormatTOONStrict Format = "toon-strict"
 
-	// FormatJSONL is a machine-friendly JSONL format (one JSON object per line).
+	// FormatJSONL is a machine-friendly JSONL format: one JSON object per line,
+	// each with a "type" that Schema(FormatJSONL) describes.
 	FormatJSONL Format = "jsonl"
 
+	// FormatJSON is a single JSON document validated by the schema from Schema(FormatJSON).
+	FormatJSON Format = "json"
+
 	// FormatMarkdown is a human-readable markdown format.
 	FormatMarkdown Format = "markdown"
 
 	// FormatXML is a machine-parseable XML format.
 	FormatXML Format = "xml"
+
+	// FormatHTML is a self-contained HTML page with a collapsible file tree and
+	// highlighted code, for sharing context with people outside the terminal.
+	FormatHTML Format = "html"
+
+	// FormatPDF is a paginated PDF of the markdown output, for archival.
+	// The output is a complete PDF file; write it to disk rather than a prompt.
+	FormatPDF Format = "pdf"
+
+	// FormatTemplate renders the project output through the Go template
+	// given with WithTemplate, for bespoke formats without a Formatter.
+	FormatTemplate Format = "template"
 )
 
 // Formatter is the interface that all output formatters must implement.
@@ -46,7 +67,97 @@ type Formatter interface {
 	Format(output *ProjectOutput) (string, error)
 }
 
-var customFormatters = make(map[string]Formatter)
+// ContextFormatter is a Formatter that also sees the selection and budget
+// data of the extraction, so a custom format can report excluded files,
+// relevance scores and token totals as PTX does. When the registered
+// formatter implements it, FormatWithContext is called instead of Format.
+// Extractions with a ContextFormatter always build the SelectionReport, as
+// WithExplainSelection does.
+//
+// Example:
+//
+//	type SummaryFormatter struct{}
+//
+//	func (f *SummaryFormatter) Format(output *ProjectOutput) (string, error) {
+//	    return f.FormatWithContext(&FormatContext{Output: output})
+//	}
+//
+//	func (f *SummaryFormatter) FormatWithContext(ctx *FormatContext) (string, error) {
+//	    return fmt.Sprintf("%d files, %d/%d tokens, %d excluded\n",
+//	        len(ctx.Output.Files), ctx.TokenCount, ctx.TokenBudget, len(ctx.ExcludedFileList)), nil
+//	}
+type ContextFormatter interface {
+	Formatter
+	FormatWithContext(ctx *FormatContext) (string, error)
+}
+
+// FormatContext is what a ContextFormatter renders: the project output with
+// the selection and budget data of the extraction that produced it.
+//
+// With a token budget or output cap, promptext also renders candidate
+// selections to measure their size. Those renders see the output being
+// measured, so Output.Files and the exclusions may not be final yet.
+type FormatContext struct {
+	Output *ProjectOutput
+
+	// ExcludedFileList lists the files left out by relevance, budget or size
+	ExcludedFileList []ExcludedFileInfo
+
+	// SelectionReport ranks every candidate file with its relevance score
+	SelectionReport *SelectionReport
+
+	TokenCount  int       // Tokens of the included files
+	TotalTokens int       // Tokens of all candidate files
+	TokenBudget int       // The WithTokenBudget budget (0 = unlimited)
+	Tokenizer   Tokenizer // The backend the counts come from
+	Format      Format    // The format name the formatter was selected by
+}
+
+// formatWith renders ctx with formatter, through FormatWithContext when the
+// formatter implements ContextFormatter
+func formatWith(formatter Formatter, ctx *FormatContext) (string, error) {
+	if contextFormatter, ok := formatter.(ContextFormatter); ok {
+		return contextFormatter.FormatWithContext(ctx)
+	}
+	return formatter.Format(ctx.Output)
+}
+
+// newFormatContext returns the format context of an extraction configured
+// by procConfig, in format f, with the exclusions of procResult if not nil
+func newFormatContext(procConfig processor.Config, f Format, procResult *processor.ProcessResult) FormatContext {
+	ctx := FormatContext{
+		TokenBudget: procConfig.MaxTokens,
+		Tokenizer:   Tokenizer(procConfig.Tokenizer),
+		Format:      f,
+	}
+	if ctx.Tokenizer == "" {
+		ctx.Tokenizer = TokenizerCL100K
+	}
+	if procResult != nil {
+		ctx.ExcludedFileList = fromInternalExcludedFiles(procResult.ExcludedFileList)
+		ctx.TokenCount = procResult.TokenCount
+		ctx.TotalTokens = procResult.TotalTokens
+	}
+	return ctx
+}
+
+// FormatterInfo describes an output format, built-in or registered.
+type FormatterInfo struct {
+	Name        string
+	Description string
+	Aliases     []string // Other names WithFormat accepts
+	Extensions  []string // Output file extensions that select the format, preferred first (e.g. ".md")
+	MimeType    string   // e.g. "text/markdown"
+	BuiltIn     bool     // False for formats added with RegisterFormatter
+}
+
+// registeredFormatter is a custom formatter with its metadata
+type registeredFormatter struct {
+	formatter Formatter
+	info      FormatterInfo
+}
+
+var customFormatters = make(map[string]registeredFormatter)
 
 // RegisterFormatter registers a custom formatter that can be used with the library.
 // This allows developers to extend the library with their own output formats.
@@ -60,15 +171,61 @@ var customFormatters = make(map[string]Formatter)
 //	promptext.RegisterFormatter("myformat", &MyFormatter{})
 //	result, _ := promptext.Extract(".", WithFormat("myformat"))
 func RegisterFormatter(name string, formatter Formatter) {
-	customFormatters[name] = formatter
+	RegisterFormatterInfo(FormatterInfo{Name: name}, formatter)
+}
+
+// RegisterFormatterInfo registers a custom formatter under info.Name, with
+// the description, file extensions and MIME type Formatters lists. The CLI
+// picks the format for an output file by its extension. A formatter
+// registered under a built-in name replaces the built-in one.
+//
+// Example:
+//
+//	promptext.RegisterFormatterInfo(promptext.FormatterInfo{
+//	    Name:        "asciidoc",
+//	    Description: "AsciiDoc for documentation sites",
+//	    Extensions:  []string{".adoc"},
+//	    MimeType:    "text/asciidoc",
+//	}, &AsciiDocFormatter{})
+func RegisterFormatterInfo(info FormatterInfo, formatter Formatter) {
+	info.BuiltIn = false
+	customFormatters[info.Name] = registeredFormatter{formatter: formatter, info: info}
+}
+
+// Formatters lists the output formats: the built-in ones, recommended
+// first, then registered ones by name.
+func Formatters() []FormatterInfo {
+	var formatters []FormatterInfo
+	for _, builtin := range format.Builtins {
+		if _, replaced := customFormatters[builtin.Name]; replaced {
+			continue
+		}
+		formatters = append(formatters, FormatterInfo{
+			Name:        builtin.Name,
+			Description: builtin.Description,
+			Aliases:     builtin.Aliases,
+			Extensions:  builtin.Extensions,
+			MimeType:    builtin.MimeType,
+			BuiltIn:     true,
+		})
+	}
+	names := make([]string, 0, len(customFormatters))
+	for name := range customFormatters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		formatters = append(formatters, customFormatters[name].info)
+	}
+	return formatters
 }
 
 // GetFormatter returns the appropriate formatter for the given format string.
 // It first checks custom formatters, then falls back to built-in formatters.
 func GetFormatter(formatStr string) (Formatter, error) {
 	// Check custom formatters first
-	if customFormatter, ok := customFormatters[formatStr]; ok {
-		return customFormatter, nil
+	if custom, ok := customFormatters[formatStr]; ok {
+		return custom.formatter, nil
 	}
 
 	// Fall back to built-in formatters
@@ -84,6 +241,54 @@ func GetFormatter(formatStr string) (Formatter, error) {
 	return &formatterAdapter{internal: internalFormatter}, nil
 }
 
+// MarkdownOptions configures the built-in markdown formatter, set with
+// WithMarkdown. The zero value renders markdown as before.
+type MarkdownOptions struct {
+	// TOC starts the output with a table of contents linking to each file
+	TOC bool `json:"toc,omitempty"`
+
+	// Anchors gives each file an HTML anchor, "file-" and its path as a
+	// slug, to link to from elsewhere. TOC implies it.
+	Anchors bool `json:"anchors,omitempty"`
+
+	// CollapseLines folds files longer than this many lines into a
+	// <details> block, so long files don't bury the rest (0 = never)
+	CollapseLines int `json:"collapse_lines,omitempty"`
+}
+
+// formatterFor returns the formatter for f, configured with the markdown
+// options when f is the built-in markdown format and rendering tmpl when it
+// is FormatTemplate
+func formatterFor(f Format, markdown MarkdownOptions, tmpl *template.Template) (Formatter, error) {
+	if _, custom := customFormatters[string(f)]; f == FormatTemplate && !custom {
+		if tmpl == nil {
+			return nil, &FormatError{Format: string(f), Err: fmt.Errorf("%w: no template given with WithTemplate", ErrInvalidFormat)}
+		}
+		return &templateFormatter{tmpl: tmpl}, nil
+	}
+	if (f == FormatMarkdown || f == "md") && markdown != (MarkdownOptions{}) {
+		if _, custom := customFormatters[string(f)]; !custom {
+			return &formatterAdapter{internal: &format.MarkdownFormatter{
+				TOC:           markdown.TOC,
+				Anchors:       markdown.Anchors,
+				CollapseLines: markdown.CollapseLines,
+			}}, nil
+		}
+	}
+	return GetFormatter(string(f))
+}
+
+// Schema returns the schema describing a format's output, so downstream
+// tools can validate documents they receive: a JSON Schema for FormatJSON, a
+// JSON Schema for each line of FormatJSONL and an XML Schema (XSD) for FormatXML.
+func Schema(f Format) (string, error) {
+	schema, err := format.Schema(string(f))
+	if err != nil {
+		return "", &FormatError{Format: string(f), Err: ErrInvalidFormat}
+	}
+	return schema, nil
+}
+
 // formatterAdapter adapts internal formatters to the public Formatter interface
 type formatterAdapter struct {
 	internal format.Formatter
@@ -120,9 +325,38 @@ func toInternalProjectOutput(output *ProjectOutput) *format.ProjectOutput {
 	// Convert Metadata
 	if output.Metadata != nil {
 		internal.Metadata = &format.Metadata{
-			Language:     output.Metadata.Language,
-			Version:      output.Metadata.Version,
-			Dependencies: output.Metadata.Dependencies,
+			Language: output.Metadata.Language,
+			Version:  output.Metadata.Version,
+		}
+		for _, dep := range output.Metadata.Dependencies {
+			internal.Metadata.Dependencies = append(internal.Metadata.Dependencies, format.Dependency(dep))
+		}
+		for _, framework := range output.Metadata.Frameworks {
+			internal.Metadata.Frameworks = append(internal.Metadata.Frameworks, format.Framework(framework))
+		}
+		for _, lang := range output.Metadata.Languages {
+			internal.Metadata.Languages = append(internal.Metadata.Languages, format.LanguageShare(lang))
+		}
+		if owners := output.Metadata.Owners; owners != nil {
+			internal.Metadata.Owners = &format.OwnerInfo{Unowned: owners.Unowned, Source: owners.Source}
+			for _, owner := range owners.Owners {
+				internal.Metadata.Owners.Owners = append(internal.Metadata.Owners.Owners, format.OwnerSummary(owner))
+			}
+		}
+		if licenses := output.Metadata.Licenses; licenses != nil {
+			internal.Metadata.Licenses = &format.LicenseInfo{Copyrights: licenses.Copyrights}
+			for _, license := range licenses.Licenses {
+				internal.Metadata.Licenses.Licenses = append(internal.Metadata.Licenses.Licenses, format.LicenseSummary(license))
+			}
+			for _, flag := range licenses.Flagged {
+				internal.Metadata.Licenses.Flagged = append(internal.Metadata.Licenses.Flagged, format.LicenseFlag(flag))
+			}
+		}
+		if audit := output.Metadata.Audit; audit != nil {
+			internal.Metadata.Audit = &format.AuditInfo{Source: audit.Source, Checked: audit.Checked, Vulnerabilities: []format.Vulnerability{}}
+			for _, vuln := range audit.Vulnerabilities {
+				internal.Metadata.Audit.Vulnerabilities = append(internal.Metadata.Audit.Vulnerabilities, format.Vulnerability(vuln))
+			}
 		}
 	}
 
@@ -130,9 +364,17 @@ func toInternalProjectOutput(output *ProjectOutput) *format.ProjectOutput {
 	internal.Files = make([]format.FileInfo, len(output.Files))
 	for i, file := range output.Files {
 		internal.Files[i] = format.FileInfo{
-			Path:    file.Path,
-			Content: file.Content,
-			Tokens:  file.Tokens,
+			Path:            file.Path,
+			Content:         file.Content,
+			Tokens:          file.Tokens,
+			AssociatedTests: file.AssociatedTests,
+			Redactions:      file.Redactions,
+			Compaction:      string(file.Compaction),
+			Annotations:     file.Annotations,
+			Owners:          file.Owners,
+			License:         file.License,
+			External:        file.External,
+			Hash:            file.Hash,
 		}
 		if file.Truncation != nil {
 			internal.Files[i].Truncation = &format.TruncationInfo{
@@ -140,6 +382,10 @@ func toInternalProjectOutput(output *ProjectOutput) *format.ProjectOutput {
 				OriginalTokens: file.Truncation.OriginalTokens,
 			}
 		}
+		if file.Sample != nil {
+			sample := format.SampleInfo(*file.Sample)
+			internal.Files[i].Sample = &sample
+		}
 	}
 
 	// Convert FileStats
@@ -168,6 +414,95 @@ func toInternalProjectOutput(output *ProjectOutput) *format.ProjectOutput {
 		}
 	}
 
+	// Convert RecentCommits
+	for _, commit := range output.RecentCommits {
+		internal.RecentCommits = append(internal.RecentCommits, format.CommitInfo{
+			Hash:    commit.Hash,
+			Date:    commit.Date,
+			Subject: commit.Subject,
+			Body:    commit.Body,
+		})
+	}
+
+	// Convert Packages
+	for _, pkg := range output.Packages {
+		internal.Packages = append(internal.Packages, format.PackageInfo{
+			Name:    pkg.Name,
+			Path:    pkg.Path,
+			Files:   pkg.Files,
+			Imports: pkg.Imports,
+		})
+	}
+
+	if output.Workspace != nil {
+		internal.Workspace = &format.WorkspaceInfo{Manifests: output.Workspace.Manifests}
+		for _, pkg := range output.Workspace.Packages {
+			internal.Workspace.Packages = append(internal.Workspace.Packages, format.WorkspacePackage(pkg))
+		}
+	}
+
+	// Convert Dependencies
+	if output.Dependencies != nil {
+		internal.Dependencies = &format.DependencyInfo{
+			Imports:  output.Dependencies.Imports,
+			Packages: output.Dependencies.Packages,
+		}
+	}
+
+	// Convert SkippedFiles
+	for _, file := range output.SkippedFiles {
+		internal.SkippedFiles = append(internal.SkippedFiles, format.SkippedFile{
+			Path:   file.Path,
+			Size:   file.Size,
+			Kind:   file.Kind,
+			Reason: file.Reason,
+		})
+	}
+
+	for _, issue := range output.Issues {
+		internal.Issues = append(internal.Issues, format.Issue(issue))
+	}
+
+	if output.Metrics != nil {
+		internal.Metrics = &format.MetricsInfo{Functions: output.Metrics.Functions, Files: []format.FileMetrics{}}
+		for _, file := range output.Metrics.Files {
+			internal.Metrics.Files = append(internal.Metrics.Files, format.FileMetrics(file))
+		}
+	}
+
+	// Convert Redactions
+	if output.Redactions != nil {
+		internal.Redactions = &format.RedactionInfo{
+			Total: output.Redactions.Total,
+			Files: output.Redactions.Files,
+			Rules: output.Redactions.Rules,
+		}
+	}
+
+	// Convert Split
+	if output.Split != nil {
+		internal.Split = &format.SplitInfo{
+			Part:  output.Split.Part,
+			Total: output.Split.Total,
+			Parts: output.Split.Parts,
+		}
+	}
+
+	if output.Integrity != nil {
+		internal.Integrity = &format.IntegrityInfo{Algorithm: output.Integrity.Algorithm, Content: output.Integrity.Content}
+	}
+
+	// Convert Selection
+	if output.Selection != nil {
+		internal.Selection = &format.SelectionReport{
+			Threshold: output.Selection.Threshold,
+			Files:     make([]format.SelectionEntry, len(output.Selection.Files)),
+		}
+		for i, entry := range output.Selection.Files {
+			internal.Selection.Files[i] = format.SelectionEntry(entry)
+		}
+	}
+
 	return internal
 }
 
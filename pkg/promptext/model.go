@@ -0,0 +1,42 @@
+package promptext
+
+import (
+	"fmt"
+
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// applyModel returns a copy of cfg with the token budget and tokenizer of
+// cfg.model filled in. An explicit WithTokenBudget or WithTokenizer wins.
+func applyModel(cfg *config) (*config, error) {
+	model, err := token.LookupModel(cfg.model)
+	if err != nil {
+		return nil, err
+	}
+	out := *cfg
+	if out.tokenBudget == 0 {
+		out.tokenBudget = model.Budget(out.responseReserve)
+	}
+	if out.tokenizer == "" {
+		out.tokenizer = Tokenizer(model.Tokenizer)
+	}
+	return &out, nil
+}
+
+// contextWindowWarning reports when the rendered output alone would not fit
+// in the context window of cfg.model, or returns "" if it fits
+func contextWindowWarning(cfg *config, tokenizer, output string) string {
+	model, err := token.LookupModel(cfg.model)
+	if err != nil {
+		return ""
+	}
+	counter, err := token.NewTokenCounterFor(tokenizer)
+	if err != nil {
+		return ""
+	}
+	if tokens := counter.EstimateTokens(output); tokens > model.ContextWindow {
+		return fmt.Sprintf("output is ~%d tokens, exceeding the %d-token context window of %s",
+			tokens, model.ContextWindow, model.Name)
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package promptext
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ExtractMulti extracts several directories into one combined result. This is
+// a convenience function that creates a temporary Extractor.
+//
+// The directories are processed relative to their deepest common parent, so
+// every path in the tree and file list is namespaced by its root
+// ("services/auth/handler.go"). Filters, relevance and token budgets apply to
+// the combined file set; git info and project metadata come from the common
+// parent. Directories nested inside another given directory are folded into it.
+//
+// Example:
+//
+//	result, err := promptext.ExtractMulti(
+//	    []string{"services/auth", "services/billing", "libs/shared"},
+//	    promptext.WithExtensions(".go"),
+//	    promptext.WithTokenBudget(20000),
+//	)
+func ExtractMulti(dirs []string, opts ...Option) (*Result, error) {
+	return NewExtractor(opts...).ExtractMulti(dirs)
+}
+
+// ExtractMulti extracts several directories into one combined result.
+// See the package-level ExtractMulti for how roots are combined.
+func (e *Extractor) ExtractMulti(dirs []string) (*Result, error) {
+	if e.config.fileList != nil {
+		return nil, fmt.Errorf("WithFileList is not supported by ExtractMulti")
+	}
+	base, roots, err := resolveRoots(dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	defer e.useLogger()()
+	procConfig, formatter, warnings, err := e.prepare(base)
+	if err != nil {
+		return nil, err
+	}
+	if !(len(roots) == 1 && roots[0] == ".") {
+		procConfig.Roots = roots
+	}
+	return e.extract(procConfig, formatter, warnings)
+}
+
+// resolveRoots validates dirs and returns their common parent along with each
+// directory relative to it, dropping duplicates and directories nested in others
+func resolveRoots(dirs []string) (string, []string, error) {
+	if len(dirs) == 0 {
+		return "", nil, &DirectoryError{Err: fmt.Errorf("%w: no directories given", ErrInvalidDirectory)}
+	}
+
+	abs := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		absPath, err := resolvePath(dir)
+		if err != nil {
+			return "", nil, &DirectoryError{Path: dir, Err: err}
+		}
+		if err := validateDirectory(absPath); err != nil {
+			return "", nil, &DirectoryError{Path: absPath, Err: err}
+		}
+		abs = append(abs, absPath)
+	}
+
+	// Sorted, a directory comes after any directory containing it
+	sort.Strings(abs)
+	var kept []string
+	for _, path := range abs {
+		nested := false
+		for _, parent := range kept {
+			if isWithin(path, parent) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			kept = append(kept, path)
+		}
+	}
+
+	base := kept[0]
+	for _, path := range kept[1:] {
+		for !isWithin(path, base) {
+			base = filepath.Dir(base)
+		}
+	}
+
+	roots := make([]string, len(kept))
+	for i, path := range kept {
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return "", nil, &DirectoryError{Path: path, Err: err}
+		}
+		roots[i] = rel
+	}
+	return base, roots, nil
+}
+
+// isWithin reports whether path is dir or inside it
+func isWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -1,5 +1,15 @@
 package promptext
 
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/1broseidon/promptext/internal/token"
+)
+
 // Option is a functional option for configuring the extraction process.
 type Option func(*config)
 
@@ -8,13 +18,81 @@ type Option func(*config)
 type config struct {
 	extensions        []string
 	excludes          []string
+	includes          []string
 	gitignore         bool
 	useDefaultRules   bool
 	relevanceKeywords string
+	relevanceExclude  []string
+	exactKeywords     bool
+	boostRecent       bool
+	synonyms          map[string][]string
 	tokenBudget       int
 	format            Format
+	formatSet         bool // Format was chosen, so the config files don't replace it
+	markdown          MarkdownOptions
+	template          *template.Template
+	compression       Compression
+	encoding          Encoding
 	verbose           bool
 	debug             bool
+	associateTests    bool
+	includeTests      TestInclusion
+	maxOutputTokens   int
+	coreDirs          []string
+	entryPoints       []string
+	budgetWeights     map[string]float64
+	annotations       map[string]string
+	owners            bool
+	todoScan          bool
+	licenseScan       bool
+	metrics           bool
+	auditDeps         bool
+	auditReport       string
+	workspacePackage  string
+	extraFiles        []string
+	symlinks          SymlinkPolicy
+	maxFileSize       int64
+	maxTotalSize      int64
+	transforms        []func(path string, content []byte) ([]byte, error)
+	dropSizeOutliers  float64
+	gitLog            int
+	deterministic     bool
+	hashes            bool
+	gitRef            string
+	compareDiffs      bool
+	stripImports      bool
+	redact            bool
+	stripComments     bool
+	squashBlankLines  bool
+	compact           CompactMode
+	notebookOutputs   bool
+	summarizeAssets   bool
+	dataSampling      int
+	cacheDir          string
+	userCache         bool
+	maxFileTokens     int
+	truncateStrategy  TruncationStrategy
+	profile           string
+	configFiles       bool
+	tokenizer         Tokenizer
+	model             string
+	responseReserve   int
+	contentExcludes   []string
+	skipGenerated     bool
+	skippedFileStubs  bool
+	splitTokens       int
+	scorer            Scorer
+	followImports     int
+	snippets          bool
+	snippetContext    int
+	explainSelection  bool
+	treeOnly          bool
+	fileList          []string
+	concurrency       int
+	llm               LLM
+	answerWriter      io.Writer
+	progress          func(ProgressEvent)
+	logger            slog.Handler
 }
 
 // newDefaultConfig creates a config with sensible defaults.
@@ -28,6 +106,8 @@ func newDefaultConfig() *config {
 		format:          FormatPTX, // PTX is the default format
 		verbose:         false,     // quiet by default
 		debug:           false,     // no debug logging by default
+		skipGenerated:   true,      // skip generated and minified files by default
+		summarizeAssets: true,      // summarize lockfiles and minified bundles that get through
 	}
 }
 
@@ -55,6 +135,88 @@ func WithExcludes(patterns ...string) Option {
 	}
 }
 
+// WithContentExcludes skips files whose content matches any of the given
+// regular expressions, for files that can't be told apart by path. Patterns
+// use Go's regexp syntax and are matched against the whole file; use (?m)
+// to anchor on lines. Extraction fails with a FilterError for an invalid
+// expression.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithContentExcludes(`(?m)^// vendored from `))
+func WithContentExcludes(patterns ...string) Option {
+	return func(c *config) {
+		c.contentExcludes = patterns
+	}
+}
+
+// WithSkipGenerated controls whether generated files are skipped based on
+// their content: a "Code generated ... DO NOT EDIT." line, protoc headers,
+// @generated and <auto-generated> markers near the top of the file, and
+// minified JS/CSS. Enabled by default.
+//
+// Example:
+//
+//	// Include protobuf and other generated sources
+//	result, _ := promptext.Extract(".", promptext.WithSkipGenerated(false))
+func WithSkipGenerated(enabled bool) Option {
+	return func(c *config) {
+		c.skipGenerated = enabled
+	}
+}
+
+// WithSkippedFileStubs lists files that are skipped as binary (images,
+// archives, fonts, ...), oversized, or generated as stubs with their path,
+// size, kind, and reason, both in ProjectOutput.SkippedFiles and in the
+// directory tree, so the model knows they exist even though their content
+// isn't shipped. Files left out by your own excludes, extensions, or
+// .gitignore are not listed.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithSkippedFileStubs(true))
+//	for _, f := range result.ProjectOutput.SkippedFiles {
+//	    fmt.Printf("%s (%s, %d bytes)\n", f.Path, f.Kind, f.Size)
+//	}
+func WithSkippedFileStubs(enabled bool) Option {
+	return func(c *config) {
+		c.skippedFileStubs = enabled
+	}
+}
+
+// WithIncludes restricts the extraction to paths matching the given globs, using
+// .gitignore syntax: "**" matches any number of directories, patterns with a
+// slash are anchored to the project root, and a leading "!" removes paths matched
+// by earlier patterns. Excludes and .gitignore still apply to included paths,
+// and WithExtensions narrows the result further.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithIncludes("internal/processor/**", "cmd/*/main.go"))
+func WithIncludes(patterns ...string) Option {
+	return func(c *config) {
+		c.includes = patterns
+	}
+}
+
+// WithFileList processes exactly the given paths, relative to the extracted
+// directory or absolute inside it, instead of walking the directory. Listed
+// files still go through filters, binary checks, token counting and
+// formatting, and the directory tree shows only them. Paths that don't exist,
+// such as files deleted in a diff, are skipped with a warning; a path outside
+// the directory is an error. Not supported by ExtractMulti.
+//
+// Example:
+//
+//	// Extract the files changed on a branch
+//	out, _ := exec.Command("git", "diff", "--name-only", "main").Output()
+//	result, _ := promptext.Extract(".", promptext.WithFileList(strings.Fields(string(out))))
+func WithFileList(paths []string) Option {
+	return func(c *config) {
+		c.fileList = paths
+	}
+}
+
 // WithGitIgnore controls whether .gitignore patterns should be respected.
 // By default, .gitignore patterns are used.
 //
@@ -107,12 +269,94 @@ func WithRelevance(keywords ...string) Option {
 	}
 }
 
+// WithRelevanceExclude adds negative relevance keywords, like "-test" in
+// the CLI's --relevant. Files whose path contains one are excluded however
+// well they match, with reason "negative-keyword", and mentions in a file's
+// content lower its score by up to half. Used alone, it keeps every file but
+// those it excludes.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("auth"),
+//	    promptext.WithRelevanceExclude("test", "mock", "fixture"),
+//	)
+func WithRelevanceExclude(keywords ...string) Option {
+	return func(c *config) {
+		c.relevanceExclude = append(c.relevanceExclude, keywords...)
+	}
+}
+
+// keywordQuery joins the relevance keywords and the negated exclude
+// keywords into the query the processor parses
+func (c *config) keywordQuery() string {
+	query := c.relevanceKeywords
+	for _, keyword := range c.relevanceExclude {
+		if keyword = strings.TrimLeft(strings.TrimSpace(keyword), "-"); keyword != "" {
+			query = strings.TrimSpace(query + " -" + keyword)
+		}
+	}
+	return query
+}
+
+// WithExactKeywords controls whether WithRelevance keywords are matched as
+// given. By default each keyword also matches its stem ("authentication"
+// finds authenticate.go) and built-in synonyms ("auth" finds login.go), with
+// synonym matches scoring half as much.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("auth"),
+//	    promptext.WithExactKeywords(true),
+//	)
+func WithExactKeywords(enabled bool) Option {
+	return func(c *config) {
+		c.exactKeywords = enabled
+	}
+}
+
+// WithRecencyBoost ranks files changed recently and often in the git history
+// higher, using the last 300 commits. With WithRelevance only files that
+// match a keyword are boosted, so the boost reorders matches without adding
+// files; with only WithTokenBudget it decides which files fill the budget.
+// Outside a git repository the option has no effect.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("auth"),
+//	    promptext.WithRecencyBoost(true),
+//	)
+func WithRecencyBoost(enabled bool) Option {
+	return func(c *config) {
+		c.boostRecent = enabled
+	}
+}
+
+// WithSynonyms adds words that WithRelevance keywords also match, on top of
+// the built-in synonyms, like relevance.synonyms in .promptext.yml.
+// Keywords are matched case-insensitively.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("auth"),
+//	    promptext.WithSynonyms(map[string][]string{"auth": {"sso", "saml"}}),
+//	)
+func WithSynonyms(synonyms map[string][]string) Option {
+	return func(c *config) {
+		c.synonyms = synonyms
+	}
+}
+
 // WithTokenBudget sets a maximum token budget for the extraction.
 // Files are prioritized by relevance and entry point status, and lower-priority
 // files are excluded when the budget would be exceeded.
 //
-// The budget applies to file contents only; metadata, git info, and directory
-// tree are counted separately.
+// The budget is measured on the rendered output in the selected format, so
+// metadata, git info, the directory tree, and per-file framing all count
+// against it.
 //
 // Example:
 //
@@ -124,7 +368,7 @@ func WithTokenBudget(maxTokens int) Option {
 }
 
 // WithFormat specifies the output format for the extraction.
-// Available formats: FormatPTX, FormatTOON, FormatJSONL, FormatTOONStrict, FormatMarkdown, FormatXML.
+// Available formats: FormatPTX, FormatTOON, FormatJSONL, FormatJSON, FormatTOONStrict, FormatMarkdown, FormatXML, FormatHTML, FormatPDF, FormatTemplate (with WithTemplate).
 //
 // Example:
 //
@@ -132,6 +376,70 @@ func WithTokenBudget(maxTokens int) Option {
 func WithFormat(format Format) Option {
 	return func(c *config) {
 		c.format = format
+		c.formatSet = true
+	}
+}
+
+// WithMarkdown configures the markdown format: a linked table of contents,
+// per-file anchors and collapsing long files into <details> blocks. It applies
+// only to FormatMarkdown, including Result.As(FormatMarkdown).
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithFormat(promptext.FormatMarkdown),
+//	    promptext.WithMarkdown(promptext.MarkdownOptions{TOC: true, CollapseLines: 200}))
+func WithMarkdown(options MarkdownOptions) Option {
+	return func(c *config) {
+		c.markdown = options
+	}
+}
+
+// WithTemplate renders the output through a Go text/template, with the
+// ProjectOutput as dot, for bespoke formats without writing a Formatter. It
+// selects FormatTemplate. Add TemplateFuncs before parsing to use the
+// tokens, wrapCode and rel helpers.
+//
+// Example:
+//
+//	tmpl := template.Must(template.New("context").Funcs(promptext.TemplateFuncs()).Parse(
+//	    "{{range .Files}}// {{.Path}}\n{{.Content}}\n{{end}}"))
+//	result, _ := promptext.Extract(".", promptext.WithTemplate(tmpl))
+func WithTemplate(tmpl *template.Template) Option {
+	return func(c *config) {
+		c.template = tmpl
+		c.format = FormatTemplate
+	}
+}
+
+// WithCompression compresses the output Result.WriteTo writes, for large
+// contexts kept as CI artifacts or on disk. FormattedOutput stays text.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithCompression(promptext.CompressionGzip))
+//	f, _ := os.Create("context.ptx.gz")
+//	result.WriteTo(f)
+func WithCompression(compression Compression) Option {
+	return func(c *config) {
+		c.compression = compression
+	}
+}
+
+// WithEncoding encodes the output Result.WriteTo writes, after any
+// compression, so it survives systems that mangle unicode or binary data.
+// EncodingBase64 with CompressionGzip gives compressed output that is still
+// plain text.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithCompression(promptext.CompressionGzip),
+//	    promptext.WithEncoding(promptext.EncodingBase64))
+//	result.WriteTo(os.Stdout) // decode with: base64 -d | gunzip
+func WithEncoding(encoding Encoding) Option {
+	return func(c *config) {
+		c.encoding = encoding
 	}
 }
 
@@ -147,6 +455,23 @@ func WithVerbose(enabled bool) Option {
 	}
 }
 
+// WithLogger sends promptext's log records (errors, warnings, and the debug
+// output of WithDebug) to h instead of standard error, so a host application
+// can route them into its own pipeline. Records of every level are offered
+// to h, whose Enabled method decides which to keep. The handler is in effect
+// while the Extractor's operations run; the previous one is restored after
+// each, so other extractions keep logging as before.
+//
+// Example:
+//
+//	handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})
+//	result, _ := promptext.Extract(".", promptext.WithLogger(handler))
+func WithLogger(h slog.Handler) Option {
+	return func(c *config) {
+		c.logger = h
+	}
+}
+
 // WithDebug enables debug logging with detailed timing information.
 // This is useful for performance analysis and troubleshooting.
 //
@@ -161,3 +486,942 @@ func WithDebug(enabled bool) Option {
 		}
 	}
 }
+
+// WithTestAssociations annotates each file with the test files that cover it,
+// using naming heuristics such as foo.go ↔ foo_test.go, service.ts ↔ service.spec.ts,
+// parser.py ↔ test_parser.py, and Foo.java ↔ FooTest.java.
+// Associations are available in FileInfo.AssociatedTests and rendered by the
+// PTX and JSONL formats.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithTestAssociations(true))
+func WithTestAssociations(enabled bool) Option {
+	return func(c *config) {
+		c.associateTests = enabled
+	}
+}
+
+// TestInclusion selects how test files are chosen alongside relevant files.
+type TestInclusion string
+
+// Supported test inclusion modes.
+const (
+	// TestsAsSelected selects test files like any other file (default).
+	TestsAsSelected TestInclusion = ""
+
+	// TestsPaired also includes the tests of files that WithRelevance or
+	// WithCustomScorer selects, even when the tests don't match themselves.
+	TestsPaired TestInclusion = "paired"
+)
+
+// WithIncludeTests sets how test files are chosen. With TestsPaired, each
+// selected source file brings its tests along, paired by the same naming
+// heuristics as WithTestAssociations (foo.go -> foo_test.go,
+// src/x.ts -> x.spec.ts), and ranked right behind it for the token budget.
+// A test whose path has a negative keyword stays excluded.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("parser"),
+//	    promptext.WithIncludeTests(promptext.TestsPaired),
+//	)
+func WithIncludeTests(mode TestInclusion) Option {
+	return func(c *config) {
+		c.includeTests = mode
+	}
+}
+
+// WithMaxOutputTokens sets a hard cap on the token count of the final rendered output.
+// WithTokenBudget decides which files to include; the cap is a final check on
+// the exact formatted text returned in Result.FormattedOutput. When the output is
+// over the cap, the lowest-priority files are dropped and the output is
+// re-rendered until it fits. Dropped files are reported in Result.ExcludedFileList with reason "output-cap".
+//
+// If the cap cannot be met even after dropping every file, Extract returns
+// ErrTokenBudgetTooLow.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithMaxOutputTokens(100000))
+func WithMaxOutputTokens(maxTokens int) Option {
+	return func(c *config) {
+		c.maxOutputTokens = maxTokens
+	}
+}
+
+// WithSplitTokens sets the token budget for each part produced by ExtractSplit.
+// Files are packed in order into as many parts as needed, and every part
+// repeats the project manifest and lists the files held by the other parts.
+// Extract ignores this option.
+//
+// Example:
+//
+//	parts, _ := promptext.ExtractSplit(".", promptext.WithSplitTokens(100000))
+func WithSplitTokens(maxTokens int) Option {
+	return func(c *config) {
+		c.splitTokens = maxTokens
+	}
+}
+
+// WithCoreDirs sets the directories that hold a project's core code, replacing the
+// defaults (internal, pkg, src, lib, core). Files under these directories are
+// classified as core implementation and rank ahead of other supporting files when
+// a token budget forces a choice. Entries match a path segment at any depth, so
+// "domain" covers both domain/ and app/domain/; nested entries like "app/services"
+// are allowed.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithCoreDirs("app", "domain", "services"))
+func WithCoreDirs(dirs ...string) Option {
+	return func(c *config) {
+		c.coreDirs = dirs
+	}
+}
+
+// WithEntryPoints names the files that start the project with gitignore-style
+// globs, replacing the built-in names (main.go, index.js, app.py and the like).
+// Entry points rank first when a token budget forces a choice and are listed
+// in the file analysis, so serverless and plugin layouts can name their
+// handlers. A glob with a slash is anchored to the project root; one without
+// matches a file name at any depth.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithEntryPoints("cmd/*/main.go", "functions/*/handler.ts"))
+func WithEntryPoints(globs ...string) Option {
+	return func(c *config) {
+		c.entryPoints = globs
+	}
+}
+
+// WithBudgetWeights splits the token budget between areas of the project
+// instead of filling it greedily in priority order. Keys are directories
+// ("internal/", matched as a path prefix) or extensions (".md" or "*.md");
+// values are fractions of the budget. Files in no weighted area share the
+// fraction left over. Weights adding up to more than 1 are scaled down.
+//
+// Each area is first filled up to its share, highest-priority files first.
+// Tokens an area leaves unused then go to the remaining files in priority
+// order, so weights never leave budget unspent. Without WithTokenBudget the
+// weights have no effect.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//		promptext.WithTokenBudget(50000),
+//		promptext.WithBudgetWeights(map[string]float64{"internal/": 0.6, "docs/": 0.1}),
+//	)
+func WithBudgetWeights(weights map[string]float64) Option {
+	return func(c *config) {
+		c.budgetWeights = weights
+	}
+}
+
+// WithAnnotations attaches notes to files so they travel with the context,
+// such as "this module is being deprecated". Keys are gitignore-style globs
+// like those of WithIncludes ("internal/legacy/**", "*.proto"); every
+// format renders the notes of the globs a file matches with the file, in
+// glob order. WithConfigFiles and WithProfile add the annotations block of
+// .promptext.yml; notes given here win for the same glob.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithAnnotations(map[string]string{
+//		"internal/legacy/**": "Being deprecated; don't build on it",
+//	}))
+func WithAnnotations(annotations map[string]string) Option {
+	return func(c *config) {
+		c.annotations = annotations
+	}
+}
+
+// WithOwners attaches the owners that the repository's CODEOWNERS file
+// assigns to each file, read from .github/CODEOWNERS, CODEOWNERS,
+// docs/CODEOWNERS or .gitlab/CODEOWNERS, whichever comes first. GitHub and
+// GitLab formats are understood, including GitLab sections and their
+// default owners. Owners are set in FileInfo.Owners, and Metadata.Owners
+// counts the included files and tokens of each owner. Without a CODEOWNERS
+// file, nothing is attached.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithOwners(true))
+//	for _, owner := range result.ProjectOutput.Metadata.Owners.Owners {
+//		fmt.Printf("%s: %d files\n", owner.Owner, owner.Files)
+//	}
+func WithOwners(enabled bool) Option {
+	return func(c *config) {
+		c.owners = enabled
+	}
+}
+
+// WithTodoScan collects the TODO, FIXME and HACK comments of the included
+// files into ProjectOutput.Issues, with their path, line and text, for
+// tech-debt review. Every format renders them as an issues section; the
+// markdown format as a table. Markers are matched in upper case, in line
+// and block comments.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithTodoScan(true))
+//	for _, issue := range result.ProjectOutput.Issues {
+//		fmt.Printf("%s:%d %s %s\n", issue.Path, issue.Line, issue.Kind, issue.Text)
+//	}
+func WithTodoScan(enabled bool) Option {
+	return func(c *config) {
+		c.todoScan = enabled
+	}
+}
+
+// WithLicenseScan builds a license inventory for compliance reviews in
+// Metadata.Licenses: the licenses declared by LICENSE and COPYING files in
+// the directories of the included files and above them, the SPDX headers
+// (SPDX-License-Identifier) of the included files, counted per license, and
+// the distinct copyright notices. Headers are read before comments are
+// stripped. When some source files carry SPDX headers, source files without
+// one are flagged, and so are files whose header names none of the licenses
+// of the nearest LICENSE file. Each file's header is set in FileInfo.License.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithLicenseScan(true))
+//	for _, flag := range result.ProjectOutput.Metadata.Licenses.Flagged {
+//		fmt.Println(flag.Path, flag.Reason, flag.Detail)
+//	}
+func WithLicenseScan(enabled bool) Option {
+	return func(c *config) {
+		c.licenseScan = enabled
+	}
+}
+
+// WithMetrics adds static metrics of the included source files to
+// ProjectOutput.Metrics: each file's line count, function count and
+// cyclomatic complexity, most complex first, so reviewers can spot hotspots.
+// Go files are measured from their syntax tree; other languages the snippet
+// extractor knows are estimated from their branch keywords, and files in
+// other languages are left out. The text formats list the top hotspots.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithMetrics(true))
+//	for _, file := range result.ProjectOutput.Metrics.Files {
+//		fmt.Println(file.Path, file.Complexity, file.MaxFunction)
+//	}
+func WithMetrics(enabled bool) Option {
+	return func(c *config) {
+		c.metrics = enabled
+	}
+}
+
+// WithAuditDeps looks the dependencies of the project manifest up in the
+// OSV database (https://osv.dev) and lists their known vulnerabilities in
+// Metadata.Audit. Only dependencies pinned to an exact version, such as
+// go.mod requirements or "==" Python pins, can be looked up; ranges are
+// skipped. This sends dependency names and versions to api.osv.dev, or to
+// the server named by the PROMPTEXT_OSV_URL environment variable, and
+// extraction fails when the lookup does. See WithAuditReport to work
+// offline.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithAuditDeps(true))
+//	for _, vuln := range result.ProjectOutput.Metadata.Audit.Vulnerabilities {
+//		fmt.Println(vuln.ID, vuln.Package, vuln.Version, vuln.Fixed)
+//	}
+func WithAuditDeps(enabled bool) Option {
+	return func(c *config) {
+		c.auditDeps = enabled
+	}
+}
+
+// WithAuditReport lists the vulnerabilities of an osv-scanner JSON report
+// (osv-scanner --format json) in Metadata.Audit instead of querying the
+// OSV API, so audits work offline and in CI pipelines that already run the
+// scanner. It implies WithAuditDeps.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".", promptext.WithAuditReport("osv-report.json"))
+func WithAuditReport(path string) Option {
+	return func(c *config) {
+		c.auditReport = path
+	}
+}
+
+// WithWorkspacePackage extracts one member of a monorepo workspace and
+// every member it depends on, transitively, along with the workspace
+// manifests. Members are read from go.work, pnpm-workspace.yaml, the
+// package.json workspaces field, a Cargo.toml [workspace] table or the
+// project.json files of an Nx workspace, and name matches a member's name,
+// its directory, or the last element of its name (the "auth" of
+// "example.com/mono/auth"). Extraction fails when the project isn't a
+// workspace or no member matches. ProjectOutput.Workspace lists every member
+// either way.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".", promptext.WithWorkspacePackage("auth-service"))
+func WithWorkspacePackage(name string) Option {
+	return func(c *config) {
+		c.workspacePackage = name
+	}
+}
+
+// WithExtraFiles includes specific files from outside the extracted
+// directory, such as a shared proto definition or a sibling service's
+// interface. Relative paths are resolved against the working directory.
+// Each must be a readable text file, or extraction fails. Extra files are
+// labeled relative to the extracted directory ("../shared/api.proto"),
+// marked External, listed under "(external)" in the tree, and ranked and
+// budgeted like the project's own files; filters and excludes don't apply
+// to them. Repeated calls add to the list.
+//
+// Example:
+//
+//	result, _ := promptext.Extract("./service",
+//		promptext.WithExtraFiles("../shared/api.proto", "../docs/ARCHITECTURE.md"),
+//	)
+func WithExtraFiles(paths ...string) Option {
+	return func(c *config) {
+		for _, path := range paths {
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+			c.extraFiles = append(c.extraFiles, path)
+		}
+	}
+}
+
+// SymlinkPolicy selects what WithSymlinks does with symbolic links.
+type SymlinkPolicy string
+
+// Supported symlink policies.
+const (
+	// SymlinksSkip leaves symlinks out, the default: a link can pull in a
+	// huge tree from elsewhere on the disk, or loop back into the project.
+	SymlinksSkip SymlinkPolicy = "skip"
+
+	// SymlinksFollow reads the files and directories symlinks point to,
+	// wherever they are, listing them under the symlink's path.
+	SymlinksFollow SymlinkPolicy = "follow"
+
+	// SymlinksFollowInRoot follows only symlinks whose target is inside the
+	// extracted directory, such as a shared config linked into each package.
+	SymlinksFollowInRoot SymlinkPolicy = "follow-in-root"
+)
+
+// WithSymlinks sets how symlinks found walking the directory are handled.
+// Without it they are skipped. Following policies never follow a link back
+// into a directory that contains it, so cycles end. A symlink given as the
+// directory itself, or named with WithFileList, is always followed. Preview
+// lists the symlinks left out, and why, in SkippedSymlinks.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".", promptext.WithSymlinks(promptext.SymlinksFollowInRoot))
+func WithSymlinks(policy SymlinkPolicy) Option {
+	return func(c *config) {
+		c.symlinks = policy
+	}
+}
+
+// WithMaxFileSize skips files larger than bytes, before reading them, as
+// too large; WithSkippedFileStubs lists them with the reason "too-large".
+// Without it, or with 0, the cap is 10 MB, above which every file counts as
+// binary, and higher values don't raise it.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".", promptext.WithMaxFileSize(1<<20)) // 1 MB
+func WithMaxFileSize(bytes int64) Option {
+	return func(c *config) {
+		c.maxFileSize = bytes
+	}
+}
+
+// WithMaxTotalSize fails the extraction with ErrTotalSizeExceeded, before
+// any file is read, when the files it would read add up to more than bytes.
+// The error names the largest of them, to exclude or to raise the limit for.
+// It guards against pointing the extraction at a directory of huge build
+// artifacts or datasets. Streaming extractions fail when the files streamed
+// so far go over. The default, 0, sets no limit.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".", promptext.WithMaxTotalSize(200<<20)) // 200 MB
+//	if errors.Is(err, promptext.ErrTotalSizeExceeded) {
+//	    log.Fatal(err)
+//	}
+func WithMaxTotalSize(bytes int64) Option {
+	return func(c *config) {
+		c.maxTotalSize = bytes
+	}
+}
+
+// WithTransform rewrites each file's content before the built-in content
+// transforms (WithStripComments, WithRedaction and the like) and token
+// counting, for custom redaction, trimming, or snippet extraction. path is
+// relative to the extracted directory and content is the file as read;
+// the returned bytes replace it. An error fails the extraction and can be
+// matched with errors.Is. transform is called from several goroutines at
+// once. Repeated calls chain, in order. WithCache is ignored while a
+// transform is set, since its output can't be keyed.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithTransform(func(path string, content []byte) ([]byte, error) {
+//		return internalHosts.ReplaceAll(content, []byte("internal.example")), nil
+//	}))
+func WithTransform(transform func(path string, content []byte) ([]byte, error)) Option {
+	return func(c *config) {
+		c.transforms = append(c.transforms, transform)
+	}
+}
+
+// WithFollowImports pulls in the files that highly relevant files import,
+// following imports up to depth hops, and ranks them right behind the files
+// that import them, ahead of weaker keyword matches. Imports are resolved
+// within the project for Go (via go.mod), JavaScript/TypeScript (relative
+// specifiers) and Python. It needs WithRelevance or WithCustomScorer.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("auth"),
+//	    promptext.WithFollowImports(2),
+//	    promptext.WithTokenBudget(8000),
+//	)
+func WithFollowImports(depth int) Option {
+	return func(c *config) {
+		c.followImports = depth
+	}
+}
+
+// WithSnippets cuts files that match WithRelevance keywords down to the
+// definitions whose name contains a keyword, keeping contextLines lines
+// around each, so a search for "ParseConfig" returns that function rather
+// than its whole file. Functions, methods, types and classes are found in Go,
+// JavaScript/TypeScript, Python, Rust, Java, Kotlin, C#, PHP, Swift and C/C++.
+// Cut files carry a Truncation with mode "symbols:<names>"; files with no
+// matching definition are kept whole.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("ParseConfig"),
+//	    promptext.WithSnippets(3),
+//	)
+func WithSnippets(contextLines int) Option {
+	return func(c *config) {
+		c.snippets = true
+		c.snippetContext = max(contextLines, 0)
+	}
+}
+
+// WithExplainSelection attaches a report explaining file selection to the
+// result (Result.SelectionReport) and renders it as a section of the output:
+// every candidate file in priority order with its relevance score, the
+// factors behind it (filename, directory, imports, content), and whether it
+// was included or why it was excluded. The section counts toward token budgets.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("auth"),
+//	    promptext.WithTokenBudget(8000),
+//	    promptext.WithExplainSelection(true),
+//	)
+//	for _, entry := range result.SelectionReport.Files {
+//	    fmt.Println(entry.Path, entry.Score, entry.Included, entry.Reason)
+//	}
+func WithExplainSelection(enabled bool) Option {
+	return func(c *config) {
+		c.explainSelection = enabled
+	}
+}
+
+// WithTreeOnly outputs only the directory structure, annotated with the file
+// count and token total of every directory and the tokens of every file, so
+// you can decide which subtrees to extract before spending budget on them.
+// Filters and relevance apply as usual; token budgets and output caps do not.
+// FormattedOutput holds the rendered tree whatever the format, Result.Tree
+// the same data, TokenCount the size of the rendered tree, and TotalTokens
+// what extracting every listed file would cost.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithTreeOnly(true))
+//	fmt.Print(result.FormattedOutput)
+//	// myproject/ (42 files, ~18,300 tokens)
+//	// ├── cmd/ (3 files, ~2,100 tokens)
+//	// ...
+func WithTreeOnly(enabled bool) Option {
+	return func(c *config) {
+		c.treeOnly = enabled
+	}
+}
+
+// WithDropSizeOutliers drops files whose size is above the given percentile (0-100)
+// of the candidate file set. Instead of a fixed size limit, this removes the few
+// unusually large files (fixtures, generated code, data dumps) that would otherwise
+// dominate the token count. Dropped files are reported in Result.ExcludedFileList
+// with reason "size-outlier". A value of 0 disables the check.
+//
+// Example:
+//
+//	// Drop anything larger than 95% of the project's files
+//	result, _ := promptext.Extract(".", promptext.WithDropSizeOutliers(95))
+func WithDropSizeOutliers(percentile float64) Option {
+	return func(c *config) {
+		c.dropSizeOutliers = percentile
+	}
+}
+
+// WithGitLog includes the last n commits (subject and body) in the output as a
+// dedicated recent-commits section, newest first. The history gives context for
+// release notes, migrations, and reviews. It is skipped silently when the
+// directory is not a git repository. A value of 0 disables it.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithGitLog(10))
+//	for _, c := range result.ProjectOutput.RecentCommits {
+//	    fmt.Println(c.Hash, c.Subject)
+//	}
+func WithGitLog(n int) Option {
+	return func(c *config) {
+		c.gitLog = n
+	}
+}
+
+// WithDeterministic makes the output depend only on the extracted files, so an
+// unchanged tree renders to byte-identical output, ready to commit or cache.
+// Sections are always rendered in a fixed order; this also leaves out the git
+// branch, commit and message, which change with every commit whether the
+// files did or not. Explicitly requested history, such as WithGitLog, is kept.
+// The CLI turns it on when writing to a file.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithDeterministic(true))
+//	os.WriteFile("context.ptx", []byte(result.FormattedOutput), 0644)
+func WithDeterministic(enabled bool) Option {
+	return func(c *config) {
+		c.deterministic = enabled
+	}
+}
+
+// WithHashes records the SHA-256 of each included file as read from disk and
+// a content hash over them in ProjectOutput.Integrity. The PTX, JSONL, JSON
+// and XML formats add them to their manifest, and Verify checks a context
+// written with them against the files it came from.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithHashes(true))
+//	fmt.Println(result.ProjectOutput.Integrity.Content)
+func WithHashes(enabled bool) Option {
+	return func(c *config) {
+		c.hashes = enabled
+	}
+}
+
+// WithGitRef reads file contents as committed at ref (a branch, tag, or
+// commit) instead of from the working tree, so context for a released version
+// can be generated while the working directory has uncommitted changes. The
+// directory must be inside a git repository with git installed. When it is a
+// subdirectory of the repository, only that subdirectory is extracted.
+//
+// Git info and WithGitLog describe ref rather than HEAD. As for archives,
+// .gitignore and .promptextinclude files are not read (the ref holds only
+// tracked files anyway), project metadata is unavailable, and the file cache
+// is not used. Extraction fails with ErrUnknownGitRef if ref names no commit.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".", promptext.WithGitRef("v1.2.0"))
+func WithGitRef(ref string) Option {
+	return func(c *config) {
+		c.gitRef = ref
+	}
+}
+
+// WithCompareDiffs adds a unified diff of each added, removed and modified
+// file to Compare reports (FileChange.Diff and the rendered output). Without
+// it a comparison lists only the changed files and their token deltas.
+// Extraction ignores this option.
+//
+// Example:
+//
+//	comparison, _ := promptext.Compare("v1.0.0", "v1.1.0", promptext.WithCompareDiffs(true))
+func WithCompareDiffs(enabled bool) Option {
+	return func(c *config) {
+		c.compareDiffs = enabled
+	}
+}
+
+// WithStripImports removes import statements and blocks from Go, JS/TS, and
+// Python file content before token counting and output, for a leaner,
+// architecture-level view. The removed imports are summarized once in the
+// output's dependencies section (ProjectOutput.Dependencies) instead of being
+// repeated in every file.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithStripImports(true))
+//	fmt.Println(result.ProjectOutput.Dependencies.Packages)
+func WithStripImports(enabled bool) Option {
+	return func(c *config) {
+		c.stripImports = enabled
+	}
+}
+
+// WithStripComments removes comments from source files before token
+// counting and output, for prompts about what the code does rather than how
+// it is documented; comments are often a fifth or more of a file's tokens.
+//
+// Stripping is language-aware: comment markers inside string literals (a URL
+// with //, a "#" in a Python string) are left alone. It covers C-style
+// languages (Go, C/C++, Java, Kotlin, C#, Rust, Swift, JS/TS, CSS), Python,
+// Ruby, shell, YAML, TOML, SQL, Lua, Haskell, and HTML/XML; files in other
+// languages are unchanged. A #! line and directives such as //go:build and
+// // @ts-ignore are kept. Combine with WithSquashBlankLines to also drop the
+// blank lines left behind.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithStripComments(true))
+func WithStripComments(enabled bool) Option {
+	return func(c *config) {
+		c.stripComments = enabled
+	}
+}
+
+// WithSquashBlankLines collapses runs of blank lines in file content into a
+// single blank line and drops blank lines at the start and end of each file,
+// before token counting and output.
+func WithSquashBlankLines(enabled bool) Option {
+	return func(c *config) {
+		c.squashBlankLines = enabled
+	}
+}
+
+// CompactMode selects how WithCompact shrinks whitespace.
+type CompactMode string
+
+// Supported compaction modes.
+const (
+	// CompactWhitespace trims trailing whitespace and collapses runs of blank
+	// lines into one.
+	CompactWhitespace CompactMode = "whitespace"
+
+	// CompactIndent also replaces space indentation with one space per
+	// nesting level. Relative indentation is kept, so Python and YAML stay
+	// valid; tab-indented lines and Markdown, reStructuredText, and plain
+	// text files are not re-indented.
+	CompactIndent CompactMode = "indent"
+)
+
+// WithCompact rewrites the whitespace of file content before token counting
+// and output, for languages where indentation and blank lines take a large
+// share of the tokens. Each file it changes records the mode in
+// FileInfo.Compaction, and the manifest of the PTX, TOON, JSON, JSONL, XML,
+// and HTML formats lists it, so consumers know the content is not byte-exact.
+// An empty mode disables compaction.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithCompact(promptext.CompactIndent))
+func WithCompact(mode CompactMode) Option {
+	return func(c *config) {
+		c.compact = mode
+	}
+}
+
+// WithNotebookOutputs keeps the text outputs of code cells when Jupyter
+// notebooks (.ipynb) are flattened. Notebooks are always flattened from
+// their JSON into cells in the jupytext percent format: code as is and
+// markdown as comments, each after a "# %%" marker. Outputs are dropped by
+// default; with this option they follow their cell as comments, with
+// images and other binary data left out.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithNotebookOutputs(true))
+func WithNotebookOutputs(enabled bool) Option {
+	return func(c *config) {
+		c.notebookOutputs = enabled
+	}
+}
+
+// WithSummarizeLockfiles controls whether lockfiles and minified bundles
+// are replaced by summaries (enabled by default). Default rules and
+// WithSkipGenerated leave them out anyway; the summaries apply to those that
+// get through, as with WithDefaultRules(false) or WithExtraFiles. A
+// lockfile summary gives the ecosystem, the number of locked packages, and
+// the direct dependencies with their versions, or the first 50 packages
+// when the lockfile doesn't record which are direct. It covers
+// package-lock.json, yarn.lock, pnpm-lock.yaml, composer.lock, Pipfile.lock,
+// poetry.lock, Cargo.lock, Gemfile.lock and go.sum. A minified JS or CSS
+// summary gives the size, the license banner, and the source map.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithSummarizeLockfiles(false))
+func WithSummarizeLockfiles(enabled bool) Option {
+	return func(c *config) {
+		c.summarizeAssets = enabled
+	}
+}
+
+// WithDataSampling keeps only the header and the first rows records of CSV,
+// TSV and JSON Lines (.jsonl, .ndjson) files, so a large data file shows its
+// shape without spending the budget on its rows. Sampled files report the
+// kept and total rows and the column count in FileInfo.Sample, which the
+// output formats record in the file's manifest entry. Files with no more
+// than rows records are kept whole. A value of 0 disables sampling.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithDataSampling(20))
+func WithDataSampling(rows int) Option {
+	return func(c *config) {
+		c.dataSampling = rows
+	}
+}
+
+// WithRedaction scans file content for secrets (private keys, cloud and API
+// tokens, JWTs, password and secret assignments, .env values) and replaces each
+// match with a placeholder such as [REDACTED:aws-access-key] before token
+// counting and output. The number of redactions per rule is reported in
+// ProjectOutput.Redactions and per file in FileInfo.Redactions.
+//
+// Detection is pattern-based and errs on the side of redacting; it is a safety
+// net for sharing context, not a replacement for a dedicated secret scanner.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithRedaction(true))
+//	fmt.Println(result.ProjectOutput.Redactions.Total)
+func WithRedaction(enabled bool) Option {
+	return func(c *config) {
+		c.redact = enabled
+	}
+}
+
+// WithCache enables an on-disk cache of processed file contents and token
+// counts in dir. On later extractions, files whose size and modification time
+// are unchanged are served from the cache instead of being re-read and
+// re-tokenized, which speeds up repeated runs on large repositories. A relative
+// dir is resolved against the extracted directory; the conventional location is
+// ".promptext-cache". An empty dir disables caching (the default).
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithCache(".promptext-cache"))
+func WithCache(dir string) Option {
+	return func(c *config) {
+		c.cacheDir = dir
+	}
+}
+
+// WithUserCache enables the file cache of WithCache in a directory of the
+// user's cache directory (os.UserCacheDir) kept for each extracted directory,
+// so nothing is written into the project. This is what the prx command uses.
+// A dir given to WithCache takes precedence.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithUserCache(true))
+func WithUserCache(enabled bool) Option {
+	return func(c *config) {
+		c.userCache = enabled
+	}
+}
+
+// WithConcurrency sets how many files are read, filtered and tokenized in
+// parallel. The default of 0 uses one worker per CPU; 1 processes files one
+// at a time. Output is identical for any setting.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithConcurrency(4))
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		c.concurrency = n
+	}
+}
+
+// TruncationStrategy selects how files above WithMaxFileTokens are shortened.
+type TruncationStrategy string
+
+// Supported truncation strategies.
+const (
+	// TruncateHead keeps the beginning of the file (default).
+	TruncateHead TruncationStrategy = "head"
+
+	// TruncateHeadTail keeps the beginning and the end, omitting the middle.
+	TruncateHeadTail TruncationStrategy = "head-tail"
+
+	// TruncateSignatures keeps only declaration lines: package and import
+	// statements, type, class, and function signatures.
+	TruncateSignatures TruncationStrategy = "signatures"
+)
+
+// WithMaxFileTokens truncates any file larger than maxTokens instead of letting
+// it crowd out (or be dropped from) the token budget. Truncated files keep a
+// marker where lines were cut, report the original size in FileInfo.Truncation,
+// and are counted in Budget.FileTruncations. A value of 0 disables truncation.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithTokenBudget(8000),
+//	    promptext.WithMaxFileTokens(1500),
+//	    promptext.WithTruncationStrategy(promptext.TruncateHeadTail),
+//	)
+func WithMaxFileTokens(maxTokens int) Option {
+	return func(c *config) {
+		c.maxFileTokens = maxTokens
+	}
+}
+
+// WithTruncationStrategy sets how files above WithMaxFileTokens are shortened.
+// The default is TruncateHead.
+func WithTruncationStrategy(strategy TruncationStrategy) Option {
+	return func(c *config) {
+		c.truncateStrategy = strategy
+	}
+}
+
+// WithProfile applies a named profile from the project's .promptext.yml (or the
+// global config), for example:
+//
+//	profiles:
+//	  docs:
+//	    extensions: [.md, .mdx]
+//	  review:
+//	    excludes: [testdata/]
+//	    format: markdown
+//
+// The project file's top-level settings apply as well, with the profile on top.
+// Settings passed as options take precedence over the file wherever they
+// differ from the defaults; excludes from both are combined. Extraction fails
+// with ErrUnknownProfile if no config defines the profile.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".", promptext.WithProfile("review"))
+func WithProfile(name string) Option {
+	return func(c *config) {
+		c.profile = name
+	}
+}
+
+// WithConfigFiles applies the settings of the global config and the
+// project's .promptext.yml, as the prx command does, without selecting a
+// profile. Settings passed as options take precedence where they differ from
+// the defaults; excludes, annotations and synonyms from the files are
+// combined with those given. WithProfile reads the files as well.
+func WithConfigFiles(enabled bool) Option {
+	return func(c *config) {
+		c.configFiles = enabled
+	}
+}
+
+// Tokenizer selects how tokens are counted for budgets and reported totals.
+type Tokenizer string
+
+// Supported tokenizers.
+const (
+	// TokenizerCL100K counts with tiktoken's cl100k_base encoding (GPT-4,
+	// GPT-3.5-turbo). This is the default.
+	TokenizerCL100K Tokenizer = token.TokenizerCL100K
+
+	// TokenizerO200K counts with tiktoken's o200k_base encoding (GPT-4o and later).
+	TokenizerO200K Tokenizer = token.TokenizerO200K
+
+	// TokenizerClaude approximates Anthropic's tokenizer, which is not public,
+	// by scaling cl100k_base counts.
+	TokenizerClaude Tokenizer = token.TokenizerClaude
+
+	// TokenizerChars estimates one token per four bytes. It needs no tokenizer
+	// data and suits models without a dedicated backend, such as Llama.
+	TokenizerChars Tokenizer = token.TokenizerChars
+)
+
+// OllamaTokenizer counts tokens with model on the local Ollama server at
+// OLLAMA_HOST (default http://localhost:11434), so budgets match a local
+// model exactly. Extraction fails when the server can't tokenize with model;
+// if it stops answering partway, the remaining counts are approximated.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".",
+//	    promptext.WithTokenizer(promptext.OllamaTokenizer("llama3")),
+//	    promptext.WithTokenBudget(8000),
+//	)
+func OllamaTokenizer(model string) Tokenizer {
+	return Tokenizer(token.TokenizerOllamaPrefix + model)
+}
+
+// LMStudioTokenizer counts tokens with model on the local LM Studio server
+// at LMSTUDIO_HOST (default http://localhost:1234), like OllamaTokenizer.
+func LMStudioTokenizer(model string) Tokenizer {
+	return Tokenizer(token.TokenizerLMStudioPrefix + model)
+}
+
+// WithTokenizer sets the tokenizer used for token budgets and counts, so
+// limits match the model the output is meant for. Extraction fails with
+// ErrUnknownTokenizer for an unsupported name.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithTokenizer(promptext.TokenizerClaude),
+//	    promptext.WithTokenBudget(100000),
+//	)
+func WithTokenizer(tokenizer Tokenizer) Option {
+	return func(c *config) {
+		c.tokenizer = tokenizer
+	}
+}
+
+// validTokenizer reports whether t names a supported tokenizer ("" selects the default)
+func validTokenizer(t Tokenizer) bool {
+	return token.ValidTokenizer(string(t))
+}
+
+// WithModel targets a known model, such as "claude-sonnet-4", "gpt-4o", or
+// "gemini-2.5-pro". Unless set explicitly, the token budget becomes the model's
+// context window minus the reserved response tokens (see WithResponseReserve),
+// and the tokenizer becomes the one matching the model. Dated variants like
+// "claude-sonnet-4-20250514" resolve to their base entry.
+//
+// Result.Warnings notes when the output exceeds the model's context window,
+// which can happen with an explicit WithTokenBudget above it. Extraction fails
+// with ErrUnknownModel for a model not in the built-in table.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".",
+//	    promptext.WithRelevance("auth"),
+//	    promptext.WithModel("claude-sonnet-4"),
+//	)
+func WithModel(name string) Option {
+	return func(c *config) {
+		c.model = name
+	}
+}
+
+// WithResponseReserve sets how many tokens of the WithModel context window
+// are kept free for the model's response. The default of 0 reserves a quarter
+// of the window, at most 8192 tokens.
+func WithResponseReserve(tokens int) Option {
+	return func(c *config) {
+		c.responseReserve = tokens
+	}
+}
@@ -0,0 +1,286 @@
+package promptext
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/filter/rules"
+	"github.com/1broseidon/promptext/internal/filter/types"
+)
+
+// BudgetPlan suggests excludes that bring a project within a token budget
+// (see Plan).
+type BudgetPlan struct {
+	Target int // Token budget planned for
+	Tokens int // Tokens of the output with every file included
+	Files  int // Files of that output
+
+	// Suggestions are the excludes to add, in the order they were chosen
+	Suggestions []PlanSuggestion
+
+	// Remaining and RemainingFiles estimate the output once the suggested
+	// excludes apply; Remaining is over Target when no cut of low-value
+	// files and directories gets there
+	Remaining      int
+	RemainingFiles int
+}
+
+// PlanSuggestion is one exclude of a BudgetPlan
+type PlanSuggestion struct {
+	Pattern string // Exclude pattern, as taken by WithExcludes and .promptext.yml
+	Kind    string // "tests", "test data", "docs", "examples", "vendored", "assets", "data" or "directory"
+	Files   int    // Files it excludes that no earlier suggestion did
+	Tokens  int    // Tokens those files have
+}
+
+// Fits reports whether the output fits the budget with the suggested excludes.
+func (p *BudgetPlan) Fits() bool {
+	return p.Remaining <= p.Target
+}
+
+// Excludes returns the suggested exclude patterns.
+func (p *BudgetPlan) Excludes() []string {
+	patterns := make([]string, len(p.Suggestions))
+	for i, suggestion := range p.Suggestions {
+		patterns[i] = suggestion.Pattern
+	}
+	return patterns
+}
+
+// lowValueDirs are directory names whose files are rarely needed to
+// understand a project, by the kind of files they hold
+var lowValueDirs = map[string]string{
+	"testdata": "test data", "fixtures": "test data", "__fixtures__": "test data", "__snapshots__": "test data",
+	"test": "tests", "tests": "tests", "__tests__": "tests", "spec": "tests", "e2e": "tests", "mocks": "tests", "__mocks__": "tests",
+	"benchmarks": "tests", "bench": "tests",
+	"examples": "examples", "example": "examples", "samples": "examples",
+	"docs": "docs", "doc": "docs",
+	"vendor": "vendored", "third_party": "vendored",
+	"assets": "assets", "static": "assets",
+}
+
+// testGlobs are the test file names of common ecosystems
+var testGlobs = []string{"*_test.go", "*.test.ts", "*.test.tsx", "*.test.js", "*.test.jsx", "*.spec.ts", "*.spec.tsx", "*.spec.js", "*.spec.jsx", "test_*.py", "*_test.py", "*_spec.rb", "*Test.java", "*Tests.cs"}
+
+// bulkExtensions are the documentation and data file types a budget can
+// usually do without
+var bulkExtensions = map[string]string{
+	".md": "docs", ".mdx": "docs", ".rst": "docs", ".txt": "docs", ".adoc": "docs",
+	".json": "data", ".yaml": "data", ".yml": "data", ".csv": "data", ".tsv": "data", ".xml": "data", ".svg": "assets", ".snap": "test data",
+}
+
+// planCandidate is an exclude Plan may suggest, cheapest to lose first by tier
+type planCandidate struct {
+	pattern string
+	kind    string
+	tier    int
+	match   func(path string) bool
+}
+
+// Plan extracts dir with every file and suggests a small set of excludes that
+// brings the output within the token budget of WithTokenBudget or WithModel.
+// Low-value files go first: tests, test data, examples, docs and vendored
+// directories, then documentation and data file types, then the largest
+// directories without an entry point. A cut that alone closes the gap is
+// preferred to a bigger one, and cuts a later one made unnecessary are
+// dropped. Other options apply as in Extract.
+//
+// Returns ErrNoTokenBudget without a budget to plan for.
+//
+// Example:
+//
+//	plan, err := promptext.Plan(".", promptext.WithTokenBudget(50000))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, s := range plan.Suggestions {
+//	    fmt.Printf("exclude %s to save %d tokens\n", s.Pattern, s.Tokens)
+//	}
+func Plan(dir string, opts ...Option) (*BudgetPlan, error) {
+	return NewExtractor(opts...).Plan(dir)
+}
+
+// Plan suggests excludes that bring dir within the extractor's token budget.
+// See the package-level Plan.
+func (e *Extractor) Plan(dir string) (*BudgetPlan, error) {
+	cfg := *e.config
+	if cfg.model != "" {
+		model, err := applyModel(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		cfg = *model
+		cfg.model = ""
+	}
+	if cfg.tokenBudget <= 0 {
+		return nil, ErrNoTokenBudget
+	}
+	target := cfg.tokenBudget
+	cfg.tokenBudget, cfg.maxOutputTokens = 0, 0
+
+	result, err := (&Extractor{config: &cfg}).Extract(dir)
+	if err != nil {
+		return nil, err
+	}
+	entryPoints := filter.New(filter.Options{EntryPoints: cfg.entryPoints})
+	return planBudget(result, target, entryPoints.IsEntryPoint), nil
+}
+
+// planBudget chooses the excludes for result, rendered with every file, to
+// fit target. Savings are counted in file tokens, so the output's manifest
+// is assumed to stay the same size.
+func planBudget(result *Result, target int, isEntryPoint func(path string) bool) *BudgetPlan {
+	files := result.ProjectOutput.Files
+	plan := &BudgetPlan{Target: target, Tokens: result.TokenCount, Files: len(files)}
+	candidates := planCandidates(files, isEntryPoint)
+
+	excluded := make([]bool, len(files))
+	savings := func(c planCandidate) (int, int) {
+		n, tokens := 0, 0
+		for i, file := range files {
+			if !excluded[i] && c.match(file.Path) {
+				n++
+				tokens += file.Tokens
+			}
+		}
+		return n, tokens
+	}
+
+	var chosen []planCandidate
+	remaining, remainingFiles := plan.Tokens, len(files)
+	for remaining > target {
+		gap := remaining - target
+		best, bestFiles, bestTokens := -1, 0, 0
+		for i, c := range candidates {
+			// A cut that leaves no files is no plan
+			if n, tokens := savings(c); tokens > 0 && n < remainingFiles {
+				switch {
+				case best >= 0 && c.tier > candidates[best].tier:
+				case best < 0 || c.tier < candidates[best].tier:
+					best, bestFiles, bestTokens = i, n, tokens
+				case tokens >= gap && (bestTokens < gap || tokens < bestTokens):
+					// The smallest cut that closes the gap keeps the most
+					best, bestFiles, bestTokens = i, n, tokens
+				case bestTokens < gap && tokens > bestTokens:
+					best, bestFiles, bestTokens = i, n, tokens
+				}
+			}
+		}
+		if best < 0 {
+			break
+		}
+		c := candidates[best]
+		for i, file := range files {
+			if c.match(file.Path) {
+				excluded[i] = true
+			}
+		}
+		remaining -= bestTokens
+		remainingFiles -= bestFiles
+		chosen = append(chosen, c)
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+
+	// Drop cuts the later ones made unnecessary, latest first
+	total := func(cuts []planCandidate) int {
+		tokens := plan.Tokens
+		for _, file := range files {
+			for _, c := range cuts {
+				if c.match(file.Path) {
+					tokens -= file.Tokens
+					break
+				}
+			}
+		}
+		return tokens
+	}
+	if remaining <= target {
+		for i := len(chosen) - 1; i >= 0; i-- {
+			without := append(append([]planCandidate{}, chosen[:i]...), chosen[i+1:]...)
+			if total(without) <= target {
+				chosen = without
+			}
+		}
+	}
+
+	plan.Remaining, plan.RemainingFiles = plan.Tokens, len(files)
+	claimed := make([]bool, len(files))
+	for _, c := range chosen {
+		suggestion := PlanSuggestion{Pattern: c.pattern, Kind: c.kind}
+		for i, file := range files {
+			if !claimed[i] && c.match(file.Path) {
+				claimed[i] = true
+				suggestion.Files++
+				suggestion.Tokens += file.Tokens
+			}
+		}
+		plan.Remaining -= suggestion.Tokens
+		plan.RemainingFiles -= suggestion.Files
+		plan.Suggestions = append(plan.Suggestions, suggestion)
+	}
+	return plan
+}
+
+// planCandidates lists the excludes worth considering for files: low-value
+// directories and test files (tier 0), documentation and data file types
+// (tier 1), and directories up to two levels deep without an entry point
+// (tier 2)
+func planCandidates(files []FileInfo, isEntryPoint func(path string) bool) []planCandidate {
+	var candidates []planCandidate
+	seen := make(map[string]bool)
+	add := func(pattern, kind string, tier int) {
+		if seen[pattern] {
+			return
+		}
+		seen[pattern] = true
+		rule := rules.NewPatternRule([]string{pattern}, types.Exclude)
+		candidates = append(candidates, planCandidate{pattern: pattern, kind: kind, tier: tier, match: rule.Match})
+	}
+
+	entryDirs := make(map[string]bool)
+	for _, file := range files {
+		if file.External {
+			continue
+		}
+		segments := strings.Split(file.Path, "/")
+		for _, segment := range segments[:len(segments)-1] {
+			if kind, ok := lowValueDirs[strings.ToLower(segment)]; ok {
+				add(segment+"/", kind, 0)
+			}
+		}
+		base := path.Base(file.Path)
+		for _, glob := range testGlobs {
+			if matched, _ := path.Match(glob, base); matched {
+				add(glob, "tests", 0)
+			}
+		}
+		if kind, ok := bulkExtensions[strings.ToLower(path.Ext(base))]; ok {
+			add("*"+strings.ToLower(path.Ext(base)), kind, 1)
+		}
+		if isEntryPoint(file.Path) {
+			for dir := path.Dir(file.Path); dir != "."; dir = path.Dir(dir) {
+				entryDirs[dir] = true
+			}
+		}
+	}
+
+	var dirs []string
+	for _, file := range files {
+		if file.External {
+			continue
+		}
+		segments := strings.Split(file.Path, "/")
+		for depth := 1; depth <= 2 && depth < len(segments); depth++ {
+			dirs = append(dirs, strings.Join(segments[:depth], "/"))
+		}
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if !entryDirs[dir] {
+			add(dir+"/", "directory", 2)
+		}
+	}
+	return candidates
+}
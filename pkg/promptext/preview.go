@@ -0,0 +1,124 @@
+package promptext
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/internal/processor"
+)
+
+// PreviewResult lists the files an extraction would read, without reading
+// them (see Preview).
+type PreviewResult struct {
+	Files           []string // Relative paths of the files that pass the filters, in walk order
+	EstimatedTokens int      // Rough estimate from file sizes (4 bytes per token), plus the directory tree
+	Config          EffectiveConfig
+	Warnings        []string
+	SkippedSymlinks []SkippedSymlink // Symlinks WithSymlinks leaves out, in walk order
+
+	result *processor.DryRunResult
+	dir    string
+}
+
+// SkippedSymlink is a symlink an extraction leaves out under its WithSymlinks
+// policy.
+type SkippedSymlink struct {
+	Path   string // Relative to the directory, with forward slashes
+	Target string // What the symlink points to, as written in it
+	Reason string // "policy", "outside-root", "cycle" or "broken"
+}
+
+// EffectiveConfig is the configuration an extraction runs with, after the
+// profile (WithProfile) and the target model (WithModel) are applied.
+type EffectiveConfig struct {
+	Extensions        []string // Normalized to ".ext"; empty means all supported types
+	Excludes          []string
+	Includes          []string
+	GitIgnore         bool
+	DefaultRules      bool
+	Format            Format
+	TokenBudget       int // 0 = unlimited
+	Tokenizer         Tokenizer
+	RelevanceKeywords string
+	GitRef            string
+}
+
+// Preview reports the files an extraction of dir would process, an estimate
+// of their tokens, and the effective configuration, without reading any file
+// content. It is the library's dry run: use it to show what will be extracted
+// before committing to the full read. This is a convenience function that
+// creates a temporary Extractor.
+//
+// Files are those that pass the filters; relevance filtering and the token
+// budget are applied only once content is read, so compare EstimatedTokens
+// with Config.TokenBudget to see whether the budget will drop files.
+//
+// Example:
+//
+//	preview, err := promptext.Preview(".", promptext.WithExtensions(".go"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Printf("%d files, ~%d tokens\n", len(preview.Files), preview.EstimatedTokens)
+func Preview(dir string, opts ...Option) (*PreviewResult, error) {
+	return NewExtractor(opts...).Preview(dir)
+}
+
+// Preview reports what extracting dir would process. See the package-level
+// Preview.
+func (e *Extractor) Preview(dir string) (*PreviewResult, error) {
+	defer e.useLogger()()
+	procConfig, _, warnings, err := e.prepare(dir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := e.effectiveConfig(procConfig.DirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := processor.PreviewDirectory(procConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error previewing directory: %w", err)
+	}
+	result.ConfigSummary.UseDefaultRules = cfg.useDefaultRules
+	result.ConfigSummary.Format = string(cfg.format)
+
+	files := make([]string, len(result.FilePaths))
+	for i, path := range result.FilePaths {
+		files[i] = filepath.ToSlash(path)
+	}
+	var symlinks []SkippedSymlink
+	for _, link := range result.SkippedSymlinks {
+		symlinks = append(symlinks, SkippedSymlink{Path: filepath.ToSlash(link.Path), Target: link.Target, Reason: link.Reason})
+	}
+	return &PreviewResult{
+		Files:           files,
+		EstimatedTokens: result.EstimatedTokens,
+		Config: EffectiveConfig{
+			Extensions:        procConfig.Extensions,
+			Excludes:          cfg.excludes,
+			Includes:          cfg.includes,
+			GitIgnore:         cfg.gitignore,
+			DefaultRules:      cfg.useDefaultRules,
+			Format:            cfg.format,
+			TokenBudget:       cfg.tokenBudget,
+			Tokenizer:         cfg.tokenizer,
+			RelevanceKeywords: cfg.keywordQuery(),
+			GitRef:            cfg.gitRef,
+		},
+		Warnings:        append(warnings, result.Warnings...),
+		SkippedSymlinks: symlinks,
+		result:          result,
+		dir:             procConfig.DirPath,
+	}, nil
+}
+
+// String renders the preview as the boxed, ANSI-colored report of the CLI's
+// --dry-run
+func (p *PreviewResult) String() string {
+	if p.result == nil {
+		return ""
+	}
+	return processor.FormatDryRunOutput(p.result, processor.Config{DirPath: p.dir})
+}
@@ -0,0 +1,63 @@
+package promptext
+
+import (
+	"fmt"
+
+	fileconfig "github.com/1broseidon/promptext/internal/config"
+)
+
+// applyConfigFiles returns a copy of cfg with the settings of the global and
+// project config files in dir filled in, and those of cfg.profile on top when
+// a profile is selected. Settings cfg has changed from the defaults are kept,
+// as is a format chosen with WithFormat, and excludes are combined.
+func applyConfigFiles(cfg *config, dir string) (*config, error) {
+	globalConfig, err := fileconfig.LoadGlobalConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global config: %w", err)
+	}
+	projectConfig, err := fileconfig.LoadConfig(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load .promptext.yml: %w", err)
+	}
+	settings := globalConfig.Overlay(projectConfig)
+	if cfg.profile != "" {
+		profile, err := fileconfig.FindProfile(cfg.profile, projectConfig, globalConfig)
+		if err != nil {
+			return nil, err
+		}
+		settings = settings.Overlay(profile)
+	}
+
+	defaults := newDefaultConfig()
+	out := *cfg
+	if len(out.extensions) == 0 {
+		out.extensions = settings.Extensions
+	}
+	if len(settings.Excludes) > 0 {
+		out.excludes = append(append([]string{}, settings.Excludes...), out.excludes...)
+	}
+	if !out.formatSet && out.format == defaults.format && settings.Format != "" {
+		out.format = Format(settings.Format)
+	}
+	if out.gitignore == defaults.gitignore && settings.GitIgnore != nil {
+		out.gitignore = *settings.GitIgnore
+	}
+	if out.useDefaultRules == defaults.useDefaultRules && settings.UseDefaultRules != nil {
+		out.useDefaultRules = *settings.UseDefaultRules
+	}
+	if !out.debug && settings.Debug != nil {
+		out.debug = *settings.Debug
+	}
+	if len(out.coreDirs) == 0 {
+		out.coreDirs = settings.CoreDirs
+	}
+	if len(out.entryPoints) == 0 {
+		out.entryPoints = settings.EntryPoints
+	}
+	if len(out.budgetWeights) == 0 {
+		out.budgetWeights = settings.BudgetWeights
+	}
+	out.annotations = fileconfig.MergeAnnotations(settings, &fileconfig.FileConfig{Annotations: out.annotations})
+	out.synonyms = fileconfig.MergeSynonyms(settings, &fileconfig.FileConfig{Relevance: fileconfig.RelevanceConfig{Synonyms: out.synonyms}})
+	return &out, nil
+}
@@ -0,0 +1,56 @@
+package promptext
+
+import "github.com/1broseidon/promptext/internal/processor"
+
+// ProgressStage is the step of an extraction a ProgressEvent reports on.
+type ProgressStage string
+
+const (
+	ProgressScanning   ProgressStage = processor.StageScanning   // Walking the directory for candidate files
+	ProgressProcessing ProgressStage = processor.StageProcessing // Reading, filtering and tokenizing them
+	ProgressDone       ProgressStage = processor.StageDone       // All files are loaded; selection and formatting follow
+)
+
+// ProgressEvent reports how far an extraction has got (see WithProgress).
+type ProgressEvent struct {
+	Stage          ProgressStage
+	FilesScanned   int // Candidate files found so far
+	FilesProcessed int // Candidates read or skipped so far, out of FilesScanned
+	FilesIncluded  int // Processed files that passed the filters
+	Tokens         int // Tokens counted in the included files so far
+}
+
+// WithProgress calls fn as files are found and then loaded, once per file,
+// so long extractions can show a progress bar. FilesScanned grows while
+// scanning; once processing starts it is the total to expect. A final
+// ProgressDone event follows the last file. Calls never overlap, but come
+// from worker goroutines, and should return quickly. ExtractStream reports
+// no progress, as its files arrive one by one anyway.
+//
+// Example:
+//
+//	result, err := promptext.Extract(".", promptext.WithProgress(func(p promptext.ProgressEvent) {
+//	    fmt.Fprintf(os.Stderr, "\r%d/%d files, %d tokens", p.FilesProcessed, p.FilesScanned, p.Tokens)
+//	}))
+func WithProgress(fn func(ProgressEvent)) Option {
+	return func(c *config) {
+		c.progress = fn
+	}
+}
+
+// progressFunc adapts fn to the processor's progress events, or returns nil
+// when fn is nil
+func progressFunc(fn func(ProgressEvent)) func(processor.ProgressEvent) {
+	if fn == nil {
+		return nil
+	}
+	return func(event processor.ProgressEvent) {
+		fn(ProgressEvent{
+			Stage:          ProgressStage(event.Stage),
+			FilesScanned:   event.FilesScanned,
+			FilesProcessed: event.FilesProcessed,
+			FilesIncluded:  event.FilesIncluded,
+			Tokens:         event.Tokens,
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package promptext
+
+import (
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/pkg/promptext/prompts"
+)
+
+// RenderPrompt wraps the formatted output in the prompt template called name:
+// a built-in one (code-review, refactor, document, test-generation, migration)
+// or one the project defines in .promptext/prompts/NAME.tmpl. vars fills the
+// template's variables, such as "focus" for code-review or "from" and "to" for
+// migration; vars may be nil.
+//
+// Returns ErrUnknownPrompt if no template has that name.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithRelevance("auth"))
+//	prompt, err := result.RenderPrompt("code-review", map[string]string{"focus": "security"})
+func (r *Result) RenderPrompt(name string, vars map[string]string) (string, error) {
+	data := prompts.Data{Context: r.FormattedOutput, Tokens: r.TokenCount, Vars: vars}
+	projectDir := ""
+	if r.config != nil {
+		projectDir = r.config.DirPath
+		data.Project = filepath.Base(projectDir)
+	}
+	if r.ProjectOutput != nil {
+		for _, file := range r.ProjectOutput.Files {
+			data.Files = append(data.Files, file.Path)
+		}
+	}
+	return prompts.Render(projectDir, name, data)
+}
@@ -2,10 +2,17 @@ package promptext
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"time"
 
+	"github.com/1broseidon/promptext/internal/archive"
+	"github.com/1broseidon/promptext/internal/cache"
 	"github.com/1broseidon/promptext/internal/filter"
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/info"
 	"github.com/1broseidon/promptext/internal/log"
 	"github.com/1broseidon/promptext/internal/processor"
 )
@@ -60,6 +67,9 @@ func Extract(dir string, opts ...Option) (*Result, error) {
 // with the same configuration. This is useful when you need to extract code
 // from multiple projects with consistent settings.
 //
+// An Extractor caches each directory's tree and reuses it on later extractions
+// as long as no directories were added, removed, or renamed in the meantime.
+//
 // Example:
 //
 //	extractor := promptext.NewExtractor(
@@ -70,6 +80,10 @@ func Extract(dir string, opts ...Option) (*Result, error) {
 //	result2, _ := extractor.Extract("/path/to/project2")
 type Extractor struct {
 	config *config
+
+	// treeCache reuses directory trees when the same unchanged directory is
+	// extracted repeatedly (watch modes, multi-budget reports)
+	treeCache *info.TreeCache
 }
 
 // NewExtractor creates a new Extractor with the given options.
@@ -87,7 +101,7 @@ func NewExtractor(opts ...Option) *Extractor {
 	for _, opt := range opts {
 		opt(cfg)
 	}
-	return &Extractor{config: cfg}
+	return &Extractor{config: cfg, treeCache: info.NewTreeCache(nil)}
 }
 
 // Extract processes the specified directory and returns the extraction result.
@@ -102,10 +116,94 @@ func NewExtractor(opts ...Option) *Extractor {
 //	}
 //	fmt.Println(result.FormattedOutput)
 func (e *Extractor) Extract(dir string) (*Result, error) {
+	defer e.useLogger()()
+	procConfig, formatter, warnings, err := e.prepare(dir)
+	if err != nil {
+		return nil, err
+	}
+	return e.extract(procConfig, formatter, warnings)
+}
+
+// useLogger routes log records to the handler of WithLogger, if any, until
+// the returned func restores the previous one
+func (e *Extractor) useLogger() (restore func()) {
+	if e.config.logger == nil {
+		return func() {}
+	}
+	return log.UseHandler(e.config.logger)
+}
+
+// extract runs the prepared configuration and formats the result
+func (e *Extractor) extract(procConfig processor.Config, formatter Formatter, warnings []string) (result *Result, err error) {
+	started := time.Now()
+	defer func() {
+		if result != nil {
+			result.format = e.config.format
+			result.markdown = e.config.markdown
+			result.template = e.config.template
+			result.compression = e.config.compression
+			result.encoding = e.config.encoding
+			result.duration = time.Since(started)
+		}
+	}()
+
+	if e.config.treeOnly {
+		return e.extractTree(procConfig, warnings)
+	}
+
+	// Process directory
+	procResult, err := processor.ProcessDirectory(procConfig, e.config.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error processing directory: %w", err)
+	}
+
+	// Check if any files were processed
+	if len(procResult.ProjectOutput.Files) == 0 {
+		return nil, ErrNoFilesMatched
+	}
+
+	// Format output
+	formatContext := newFormatContext(procConfig, e.config.format, procResult)
+	formatContext.Output = fromInternalProjectOutput(procResult.ProjectOutput)
+	formatContext.SelectionReport = formatContext.Output.Selection
+	formattedOutput, err := formatWith(formatter, &formatContext)
+	if err != nil {
+		return nil, &FormatError{
+			Format: string(e.config.format),
+			Err:    err,
+		}
+	}
+
+	// Enforce the hard output cap by dropping low-priority files and re-rendering
+	if e.config.maxOutputTokens > 0 {
+		if formattedOutput, err = e.enforceOutputCap(procResult, procConfig, formatter); err != nil {
+			return nil, err
+		}
+	}
+
+	// Warn when the output can't fit the target model even without a response
+	if e.config.model != "" {
+		if warning := contextWindowWarning(e.config, procConfig.Tokenizer, formattedOutput); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	// Convert to public Result type
+	result = fromInternalProcessResult(procResult, formattedOutput)
+	result.Warnings = append(warnings, procResult.Warnings...)
+	result.tokenizer = procConfig.Tokenizer
+	result.config = &procConfig
+
+	return result, nil
+}
+
+// prepare resolves dir and builds the processor configuration, formatter, and
+// configuration warnings shared by Extract and ExtractStream
+func (e *Extractor) prepare(dir string) (processor.Config, Formatter, []string, error) {
 	// Validate and resolve directory path
 	absPath, err := resolvePath(dir)
 	if err != nil {
-		return nil, &DirectoryError{
+		return processor.Config{}, nil, nil, &DirectoryError{
 			Path: dir,
 			Err:  err,
 		}
@@ -113,70 +211,273 @@ func (e *Extractor) Extract(dir string) (*Result, error) {
 
 	// Check if directory exists and is accessible
 	if err := validateDirectory(absPath); err != nil {
-		return nil, &DirectoryError{
+		return processor.Config{}, nil, nil, &DirectoryError{
 			Path: absPath,
 			Err:  err,
 		}
 	}
+	if e.config.gitRef == "" {
+		return e.prepareAt(absPath, nil)
+	}
+
+	// Read the committed files of the ref in place of the working tree
+	if e.config.fileList != nil {
+		return processor.Config{}, nil, nil, fmt.Errorf("WithFileList is not supported with WithGitRef")
+	}
+	fsys, err := archive.OpenGitRef(absPath, e.config.gitRef)
+	if err != nil {
+		return processor.Config{}, nil, nil, err
+	}
+	procConfig, formatter, warnings, err := e.prepareAt(absPath, fsys)
+	procConfig.GitRef = e.config.gitRef
+	return procConfig, formatter, warnings, err
+}
+
+// effectiveConfig returns the configuration for the project rooted at
+// absPath, with the selected profile and target model applied
+func (e *Extractor) effectiveConfig(absPath string) (*config, error) {
+	var err error
+	cfg := e.config
+
+	// Apply the config files, with the selected profile if any
+	if cfg.profile != "" || cfg.configFiles {
+		if cfg, err = applyConfigFiles(e.config, absPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fill in the budget and tokenizer of the target model
+	if cfg.model != "" {
+		if cfg, err = applyModel(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// prepareAt builds the configuration for the project rooted at absPath, read
+// from fsys instead of the disk when fsys is not nil
+func (e *Extractor) prepareAt(absPath string, fsys fs.FS) (processor.Config, Formatter, []string, error) {
+	cfg, err := e.effectiveConfig(absPath)
+	if err != nil {
+		return processor.Config{}, nil, nil, err
+	}
 
 	// Configure logging
-	if e.config.debug {
+	if cfg.debug {
 		log.Enable()
 		log.SetColorEnabled(true)
 	}
 
+	// Normalize extensions so "go" behaves like ".go"
+	extensions, warnings := processor.NormalizeExtensions(cfg.extensions)
+
+	// A .promptextinclude file in the project root switches to allowlist mode
+	var allowlist []string
+	if fsys == nil {
+		if allowlist, err = filter.ParseIncludeFile(absPath); err != nil {
+			warnings = append(warnings, fmt.Sprintf("could not read %s: %v", filter.IncludeFileName, err))
+		}
+	}
+
 	// Create filter options
 	filterOpts := filter.Options{
-		Includes:        e.config.extensions,
-		Excludes:        e.config.excludes,
-		UseDefaultRules: e.config.useDefaultRules,
-		UseGitIgnore:    e.config.gitignore,
+		Includes:        extensions,
+		Excludes:        cfg.excludes,
+		IncludePaths:    cfg.includes,
+		Allowlist:       allowlist,
+		ContentExcludes: cfg.contentExcludes,
+		SkipGenerated:   cfg.skipGenerated,
+		UseDefaultRules: cfg.useDefaultRules,
+		UseGitIgnore:    cfg.gitignore && fsys == nil, // Only the disk has a .gitignore to read
+		Root:            absPath,
+		EntryPoints:     cfg.entryPoints,
+	}
+	if fsys == nil {
+		filterOpts.Frameworks = info.FrameworkNames(info.DetectFrameworks(absPath))
+	}
+
+	// Reject invalid content patterns up front rather than silently ignoring them
+	for _, pattern := range cfg.contentExcludes {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return processor.Config{}, nil, nil, &FilterError{Pattern: pattern, Err: err}
+		}
 	}
 
 	// Create filter
 	f := filter.New(filterOpts)
 
+	// Get formatter; the token budget is measured in the rendered target format
+	formatter, err := formatterFor(cfg.format, cfg.markdown, cfg.template)
+	if err != nil {
+		return processor.Config{}, nil, nil, err
+	}
+	if err := validEncoding(cfg.compression, cfg.encoding); err != nil {
+		return processor.Config{}, nil, nil, err
+	}
+	if !validTokenizer(cfg.tokenizer) {
+		return processor.Config{}, nil, nil, fmt.Errorf("%w %q", ErrUnknownTokenizer, cfg.tokenizer)
+	}
+	if err := processor.ValidSymlinkPolicy(string(cfg.symlinks)); err != nil {
+		return processor.Config{}, nil, nil, err
+	}
+
 	// Create processor configuration
 	procConfig := processor.Config{
 		DirPath:           absPath,
-		Extensions:        e.config.extensions,
-		Excludes:          e.config.excludes,
-		GitIgnore:         e.config.gitignore,
+		Extensions:        extensions,
+		Excludes:          cfg.excludes,
+		GitIgnore:         cfg.gitignore,
 		Filter:            f,
-		RelevanceKeywords: e.config.relevanceKeywords,
-		MaxTokens:         e.config.tokenBudget,
+		RelevanceKeywords: cfg.keywordQuery(),
+		ExactKeywords:     cfg.exactKeywords,
+		BoostRecent:       cfg.boostRecent,
+		Synonyms:          cfg.synonyms,
+		MaxTokens:         cfg.tokenBudget,
+		AssociateTests:    cfg.associateTests,
+		IncludeTests:      string(cfg.includeTests),
+		Owners:            cfg.owners,
+		TodoScan:          cfg.todoScan,
+		LicenseScan:       cfg.licenseScan,
+		Metrics:           cfg.metrics,
+		AuditDeps:         cfg.auditDeps,
+		AuditReport:       cfg.auditReport,
+		WorkspacePackage:  cfg.workspacePackage,
+		CoreDirs:          cfg.coreDirs,
+		BudgetWeights:     cfg.budgetWeights,
+		Annotations:       cfg.annotations,
+		ExtraFiles:        cfg.extraFiles,
+		Symlinks:          string(cfg.symlinks),
+		MaxFileSize:       cfg.maxFileSize,
+		MaxTotalSize:      cfg.maxTotalSize,
+		DropSizeOutliers:  cfg.dropSizeOutliers,
+		FollowImports:     cfg.followImports,
+		Snippets:          cfg.snippets,
+		SnippetContext:    cfg.snippetContext,
+		Concurrency:       cfg.concurrency,
+		ExplainSelection:  cfg.explainSelection,
+		GitLog:            cfg.gitLog,
+		Deterministic:     cfg.deterministic,
+		Hashes:            cfg.hashes,
+		StripImports:      cfg.stripImports,
+		Redact:            cfg.redact,
+		StripComments:     cfg.stripComments,
+		SquashBlankLines:  cfg.squashBlankLines,
+		Compact:           string(cfg.compact),
+		NotebookOutputs:   cfg.notebookOutputs,
+		SummarizeAssets:   cfg.summarizeAssets,
+		SampleRows:        cfg.dataSampling,
+		Tokenizer:         string(cfg.tokenizer),
+		SkippedFileStubs:  cfg.skippedFileStubs,
+		MaxFileTokens:     cfg.maxFileTokens,
+		TruncateStrategy:  string(cfg.truncateStrategy),
+		Progress:          progressFunc(cfg.progress),
+		FS:                fsys,
+	}
+	procConfig.Render = renderWith(formatter, newFormatContext(procConfig, cfg.format, nil))
+	if _, ok := formatter.(ContextFormatter); ok {
+		procConfig.ExplainSelection = true // Relevance scores are part of the format context
+	}
+	if fsys == nil {
+		procConfig.TreeCache = e.treeCache
+	}
+	if cacheDir := cfg.cacheDir; cacheDir != "" && fsys == nil {
+		if !filepath.IsAbs(cacheDir) {
+			cacheDir = filepath.Join(absPath, cacheDir)
+		}
+		procConfig.Cache = cache.Open(cacheDir, absPath)
+	} else if cfg.userCache && fsys == nil {
+		if cacheDir, err := cache.UserDir(absPath); err == nil {
+			procConfig.Cache = cache.Open(cacheDir, absPath)
+		} else {
+			warnings = append(warnings, fmt.Sprintf("file cache disabled: %v", err))
+		}
+	}
+	if cfg.scorer != nil {
+		procConfig.Scorer = batchScorer{scorer: cfg.scorer}
+	}
+	if len(cfg.transforms) > 0 {
+		procConfig.Transform = chainTransforms(cfg.transforms)
+	}
+	procConfig.FileRules = registeredFileRules()
+	if cfg.fileList != nil && fsys != nil {
+		return processor.Config{}, nil, nil, fmt.Errorf("WithFileList is not supported for archives")
+	}
+	if cfg.fileList != nil {
+		files, missing, err := processor.ResolveFileList(absPath, cfg.fileList)
+		if err != nil {
+			return processor.Config{}, nil, nil, fmt.Errorf("invalid file list: %w", err)
+		}
+		for _, path := range missing {
+			warnings = append(warnings, fmt.Sprintf("listed file %s does not exist", path))
+		}
+		if len(files) == 0 {
+			return processor.Config{}, nil, nil, fmt.Errorf("%w: none of the listed files exist", ErrNoFilesMatched)
+		}
+		procConfig.Roots = files
 	}
 
-	// Process directory
-	procResult, err := processor.ProcessDirectory(procConfig, e.config.verbose)
-	if err != nil {
-		return nil, fmt.Errorf("error processing directory: %w", err)
+	// Validate configuration; problems are reported as warnings, not errors.
+	// Unmatched excludes are found by the walk and added to the result.
+	warnings = append(warnings, processor.ValidateConfig(procConfig)...)
+	for _, warning := range warnings {
+		log.Debug("Config warning: %s", warning)
 	}
 
-	// Check if any files were processed
-	if len(procResult.ProjectOutput.Files) == 0 {
-		return nil, ErrNoFilesMatched
+	return procConfig, formatter, warnings, nil
+}
+
+// renderWith adapts a public formatter to the processor's internal render
+// hook, rendering each output in base
+func renderWith(formatter Formatter, base FormatContext) processor.RenderFunc {
+	return func(output *format.ProjectOutput) (string, error) {
+		ctx := base
+		ctx.Output = fromInternalProjectOutput(output)
+		ctx.SelectionReport = ctx.Output.Selection
+		return formatWith(formatter, &ctx)
 	}
+}
 
-	// Get formatter
-	formatter, err := GetFormatter(string(e.config.format))
-	if err != nil {
-		return nil, err
+// chainTransforms returns a transform applying transforms in order
+func chainTransforms(transforms []func(path string, content []byte) ([]byte, error)) processor.TransformFunc {
+	return func(path string, content []byte) ([]byte, error) {
+		for _, transform := range transforms {
+			var err error
+			if content, err = transform(path, content); err != nil {
+				return nil, err
+			}
+		}
+		return content, nil
 	}
+}
 
-	// Format output
-	formattedOutput, err := formatter.Format(fromInternalProjectOutput(procResult.ProjectOutput))
+// enforceOutputCap re-renders the result until it fits within the configured
+// output cap, recording dropped files as exclusions on the process result.
+func (e *Extractor) enforceOutputCap(procResult *processor.ProcessResult, procConfig processor.Config, formatter Formatter) (string, error) {
+	formattedOutput, outputTokens, dropped, err := processor.EnforceOutputCap(
+		procResult.ProjectOutput, e.config.maxOutputTokens, procConfig,
+		renderWith(formatter, newFormatContext(procConfig, e.config.format, procResult)))
 	if err != nil {
-		return nil, &FormatError{
+		return "", &FormatError{
 			Format: string(e.config.format),
 			Err:    err,
 		}
 	}
 
-	// Convert to public Result type
-	result := fromInternalProcessResult(procResult, formattedOutput)
+	// The header alone can be over the cap, with no files left to drop
+	if outputTokens > e.config.maxOutputTokens {
+		return "", ErrTokenBudgetTooLow
+	}
+	if len(dropped) > 0 {
+		if len(procResult.ProjectOutput.Files) == 0 {
+			return "", ErrTokenBudgetTooLow
+		}
+		procResult.TokenCount = outputTokens
+		procResult.ExcludedFiles += len(dropped)
+		procResult.ExcludedFileList = append(procResult.ExcludedFileList, dropped...)
+	}
 
-	return result, nil
+	return formattedOutput, nil
 }
 
 // WithExtensions is a convenience method to add extensions to the extractor.
@@ -209,6 +510,7 @@ func (e *Extractor) WithExcludes(patterns ...string) *Extractor {
 //	extractor := promptext.NewExtractor().WithFormat(promptext.FormatJSONL)
 func (e *Extractor) WithFormat(format Format) *Extractor {
 	e.config.format = format
+	e.config.formatSet = true
 	return e
 }
 
@@ -0,0 +1,122 @@
+// Package prompts wraps extracted code context in ready-made prompt
+// templates, so programs don't each hand-roll the instructions around it.
+//
+// Built-in templates cover common tasks: code-review, refactor, document,
+// test-generation, and migration, plus ask, which promptext.Ask sends with
+// a "question" variable. A project adds its own, or overrides a
+// built-in, with text/template files in .promptext/prompts/NAME.tmpl.
+//
+// Most callers render through promptext.Result:
+//
+//	result, _ := promptext.Extract(".", promptext.WithExtensions(".go"))
+//	prompt, err := result.RenderPrompt("code-review", map[string]string{"focus": "error handling"})
+//
+// Templates see a Data value: {{.Context}} is the formatted extraction and
+// {{.Vars.name}} a caller-supplied variable (empty when not given).
+package prompts
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Dir is where a project keeps its own templates, relative to its root
+const Dir = ".promptext/prompts"
+
+// ErrUnknownTemplate is returned when no built-in or project template has the requested name
+var ErrUnknownTemplate = errors.New("unknown prompt template")
+
+//go:embed templates/*.tmpl
+var builtin embed.FS
+
+// Data is what a template is rendered with
+type Data struct {
+	Context string            // The formatted extraction output
+	Project string            // Name of the project directory
+	Files   []string          // Paths of the included files
+	Tokens  int               // Estimated tokens of Context
+	Vars    map[string]string // Caller-supplied variables, e.g. "focus" for code-review
+}
+
+// Builtin returns the names of the built-in templates, sorted
+func Builtin() []string {
+	entries, _ := fs.ReadDir(builtin, "templates")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".tmpl"))
+	}
+	return names
+}
+
+// Names returns the templates available to the project in projectDir: the
+// built-in ones and those in its .promptext/prompts directory, sorted. An
+// empty projectDir lists only the built-in templates.
+func Names(projectDir string) []string {
+	seen := make(map[string]bool)
+	names := Builtin()
+	for _, name := range names {
+		seen[name] = true
+	}
+	if projectDir != "" {
+		files, _ := filepath.Glob(filepath.Join(projectDir, Dir, "*.tmpl"))
+		for _, file := range files {
+			if name := strings.TrimSuffix(filepath.Base(file), ".tmpl"); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render executes the template called name with data. A template in the
+// project's .promptext/prompts directory takes precedence over a built-in
+// one of the same name; an empty projectDir uses only the built-ins.
+func Render(projectDir, name string, data Data) (string, error) {
+	text, source, err := load(projectDir, name)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", source, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", source, err)
+	}
+	return b.String(), nil
+}
+
+// load returns the text of the template called name and where it came from
+func load(projectDir, name string) (text, source string, err error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", "", fmt.Errorf("%w: %q", ErrUnknownTemplate, name)
+	}
+
+	if projectDir != "" {
+		path := filepath.Join(projectDir, Dir, name+".tmpl")
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return string(data), path, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", "", fmt.Errorf("prompt template %s: %w", path, err)
+		}
+	}
+
+	data, err := builtin.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %q (available: %s)", ErrUnknownTemplate, name, strings.Join(Names(projectDir), ", "))
+	}
+	return string(data), name, nil
+}
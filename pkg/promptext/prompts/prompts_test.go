@@ -0,0 +1,90 @@
+package prompts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBuiltin(t *testing.T) {
+	want := []string{"ask", "code-review", "document", "migration", "refactor", "test-generation"}
+	if got := Builtin(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Builtin() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderBuiltin(t *testing.T) {
+	for _, name := range Builtin() {
+		out, err := Render("", name, Data{Context: "<code>", Project: "shop"})
+		if err != nil {
+			t.Fatalf("Render(%q) failed: %v", name, err)
+		}
+		if !strings.HasSuffix(strings.TrimSpace(out), "<code>") || !strings.Contains(out, "shop") {
+			t.Errorf("Render(%q) should name the project and end with the context, got:\n%s", name, out)
+		}
+		if strings.Contains(out, "<no value>") || strings.HasPrefix(out, "\n") {
+			t.Errorf("Render(%q) left template residue:\n%s", name, out)
+		}
+	}
+}
+
+func TestRenderVars(t *testing.T) {
+	out, err := Render("", "code-review", Data{Project: "shop", Vars: map[string]string{"focus": "SQL injection"}})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(out, "Concentrate on SQL injection.") {
+		t.Errorf("expected the focus variable in the prompt, got:\n%s", out)
+	}
+
+	out, err = Render("", "migration", Data{Project: "shop", Vars: map[string]string{"from": "Python 2", "to": "Python 3"}})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(out, "migration of shop from Python 2 to Python 3.") {
+		t.Errorf("expected from and to in the prompt, got:\n%s", out)
+	}
+}
+
+func TestRenderProjectTemplate(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, Dir), 0755)
+	os.WriteFile(filepath.Join(dir, Dir, "audit.tmpl"), []byte("Audit {{.Vars.area}} in {{len .Files}} files:\n{{.Context}}"), 0644)
+	os.WriteFile(filepath.Join(dir, Dir, "code-review.tmpl"), []byte("Our review checklist\n{{.Context}}"), 0644)
+
+	out, err := Render(dir, "audit", Data{Context: "ctx", Files: []string{"a.go", "b.go"}, Vars: map[string]string{"area": "payments"}})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if out != "Audit payments in 2 files:\nctx" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	// A project template overrides the built-in of the same name
+	out, err = Render(dir, "code-review", Data{Context: "ctx"})
+	if err != nil || out != "Our review checklist\nctx" {
+		t.Errorf("expected the project's code-review template, got %q (%v)", out, err)
+	}
+
+	if got := Names(dir); !reflect.DeepEqual(got, []string{"ask", "audit", "code-review", "document", "migration", "refactor", "test-generation"}) {
+		t.Errorf("Names() = %q", got)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	for _, name := range []string{"missing", "", "../secrets", ".hidden"} {
+		if _, err := Render(t.TempDir(), name, Data{}); !errors.Is(err, ErrUnknownTemplate) {
+			t.Errorf("Render(%q) error = %v, want ErrUnknownTemplate", name, err)
+		}
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, Dir), 0755)
+	os.WriteFile(filepath.Join(dir, Dir, "broken.tmpl"), []byte("{{.Context"), 0644)
+	if _, err := Render(dir, "broken", Data{}); err == nil || !strings.Contains(err.Error(), "broken.tmpl") {
+		t.Errorf("expected a parse error naming the file, got %v", err)
+	}
+}
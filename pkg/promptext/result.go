@@ -1,6 +1,10 @@
 package promptext
 
 import (
+	"io"
+	"text/template"
+	"time"
+
 	"github.com/1broseidon/promptext/internal/format"
 	"github.com/1broseidon/promptext/internal/processor"
 )
@@ -25,12 +29,50 @@ type Result struct {
 
 	// ExcludedFileList contains details about excluded files
 	ExcludedFileList []ExcludedFileInfo
+
+	// Tree is the annotated directory tree of a tree-only extraction (see
+	// WithTreeOnly); nil otherwise
+	Tree *TreeNode
+
+	// SelectionReport explains each file's ranking and inclusion. It is set by
+	// WithExplainSelection and is the same report as ProjectOutput.Selection.
+	SelectionReport *SelectionReport
+
+	// Warnings lists likely configuration mistakes detected during extraction,
+	// such as extensions without a leading dot or exclude patterns that match nothing
+	Warnings []string
+
+	// tokenizer is the backend the extraction counted with, reused by Chunks
+	tokenizer string
+
+	// config is the configuration the extraction ran with, reused by Explain
+	config *processor.Config
+
+	// budget is the token budget of a result read by LoadResult, which has no config
+	budget int
+
+	// format and duration are reported by Summary
+	format   Format
+	duration time.Duration
+
+	// markdown and template configure As(FormatMarkdown) and
+	// As(FormatTemplate) like the extraction
+	markdown MarkdownOptions
+	template *template.Template
+
+	// compression and encoding are applied by WriteTo
+	compression Compression
+	encoding    Encoding
 }
 
 // ExcludedFileInfo contains information about an excluded file.
 type ExcludedFileInfo struct {
 	Path   string
 	Tokens int
+
+	// Reason describes why the file was excluded: "relevance",
+	// "negative-keyword", "token-budget", "output-cap", or "size-outlier"
+	Reason string
 }
 
 // ProjectOutput represents the complete structured output of a project extraction.
@@ -56,6 +98,85 @@ type ProjectOutput struct {
 
 	// FilterConfig describes the filter configuration used
 	FilterConfig *FilterConfig
+
+	// Packages maps the Go packages and JS/TS module directories among the
+	// included files, with the project-internal packages each one imports
+	Packages []PackageInfo
+
+	// Workspace lists the member packages of a monorepo workspace, the
+	// members each depends on, and their included files and tokens
+	Workspace *WorkspaceInfo
+
+	// RecentCommits lists the latest commits, newest first (see WithGitLog)
+	RecentCommits []CommitInfo
+
+	// Dependencies summarizes the imports removed from file content (see WithStripImports)
+	Dependencies *DependencyInfo
+
+	// Redactions counts the secrets replaced with placeholders (see WithRedaction)
+	Redactions *RedactionInfo
+
+	// SkippedFiles lists binary, oversized, and generated files whose content
+	// was left out (see WithSkippedFileStubs)
+	SkippedFiles []SkippedFileInfo
+
+	// Issues lists the TODO, FIXME and HACK comments of the included files
+	// (see WithTodoScan)
+	Issues []Issue
+
+	// Metrics holds the size and complexity of the included source files
+	// (see WithMetrics)
+	Metrics *MetricsInfo
+
+	// Split places this output among the parts of a split extraction (see ExtractSplit)
+	Split *SplitInfo
+
+	// Selection explains how files were ranked and which were kept (see WithExplainSelection)
+	Selection *SelectionReport
+
+	// Integrity holds the content hash over the files' hashes (see WithHashes)
+	Integrity *IntegrityInfo
+}
+
+// IntegrityInfo records the content hash of an output, which changes when
+// any of its files is changed, added or dropped.
+type IntegrityInfo struct {
+	Algorithm string // Always "sha256"
+
+	// Content is the hex SHA-256 of one "HASH  PATH" line per file, in path
+	// order, as sha256sum prints them
+	Content string
+}
+
+// SelectionReport explains file selection: every candidate file in priority
+// order, with its relevance score, the factors behind it, and whether it was
+// included in the output.
+type SelectionReport struct {
+	Threshold float64 // Score at which a file counts as highly relevant
+	Files     []SelectionEntry
+}
+
+// SelectionEntry is one candidate file in a SelectionReport. The score
+// factors are set for keyword relevance (WithRelevance); with a custom scorer
+// only Score is.
+type SelectionEntry struct {
+	Path      string
+	Tokens    int
+	Score     float64  // Relevance score (0 without keywords or a custom scorer)
+	Filename  float64  // Score from keyword matches in the file name
+	Directory float64  // Score from keyword matches in the directory path
+	Imports   float64  // Score from keyword matches in import statements
+	Content   float64  // Score from keyword occurrences in the content, less negative keyword mentions
+	Traits    []string // Ranking traits: "entry", "config", "core", "test", "imported", "paired", "recent"
+	Included  bool
+	Reason    string // Why the file was excluded (an ExcludeReason* value), when it was
+}
+
+// SplitInfo describes one part of an extraction split across several outputs.
+type SplitInfo struct {
+	Part  int        // 1-based number of this part
+	Total int        // Number of parts
+	Parts [][]string // File paths in each part; Parts[0] is part 1
 }
 
 // DirectoryNode represents a node in the directory tree hierarchy.
@@ -76,7 +197,95 @@ type GitInfo struct {
 type Metadata struct {
 	Language     string
 	Version      string
-	Dependencies []string
+	Dependencies []Dependency    // Declared by the project manifest, direct first, then dev, then indirect
+	Frameworks   []Framework     // Detected from marker files such as next.config.js or manage.py
+	Languages    []LanguageShare // Included files by language, most tokens first; nil when all are in one language
+	Owners       *OwnerInfo      // Included files per CODEOWNERS owner (see WithOwners)
+	Licenses     *LicenseInfo    // License inventory (see WithLicenseScan)
+	Audit        *AuditInfo      // Known vulnerabilities of the dependencies (see WithAuditDeps)
+}
+
+// AuditInfo lists the known vulnerabilities of the project's dependencies.
+type AuditInfo struct {
+	Source          string          // "osv.dev", or the osv-scanner report read
+	Checked         int             // Dependencies looked up
+	Vulnerabilities []Vulnerability // Sorted by package, then ID
+}
+
+// Vulnerability is a known vulnerability affecting a dependency version.
+type Vulnerability struct {
+	ID       string   // OSV identifier, e.g. "GHSA-35jh-r3h4-6jhm" or "GO-2024-2611"
+	Aliases  []string // Other identifiers, such as CVE numbers
+	Package  string
+	Version  string
+	Severity string // Label such as "HIGH" when the database gives one, else the CVSS vector
+	Summary  string
+	Fixed    string // First fixed version above Version, when known
+}
+
+// LanguageShare is one language's part of the included files, like the
+// language bar of a GitHub repository.
+type LanguageShare struct {
+	Name         string // As GitHub's linguist spells it, e.g. "TypeScript", or "Other" for unrecognized files
+	Files        int
+	Lines        int
+	Tokens       int
+	LinePercent  float64 // Share of the included lines, to one decimal place
+	TokenPercent float64 // Share of the included tokens, to one decimal place
+}
+
+// LicenseInfo is the license inventory of an extraction.
+type LicenseInfo struct {
+	Licenses   []LicenseSummary // Sorted by ID
+	Flagged    []LicenseFlag    // Source files whose license header needs review
+	Copyrights []string         // Distinct copyright notices, sorted
+}
+
+// LicenseSummary is one license declared in the project.
+type LicenseSummary struct {
+	ID      string   // SPDX identifier, e.g. "MIT", or "unknown" for an unrecognized LICENSE file
+	Files   int      // Included files whose SPDX header names it
+	Sources []string // LICENSE files declaring it
+}
+
+// LicenseFlag marks a source file whose license header is missing or
+// conflicts with the LICENSE file governing it.
+type LicenseFlag struct {
+	Path   string
+	Reason string // "missing-header" or "conflicting-header"
+	Detail string // e.g. "header says GPL-3.0; LICENSE declares MIT"
+}
+
+// OwnerInfo rolls the included files up by CODEOWNERS owner.
+type OwnerInfo struct {
+	Owners  []OwnerSummary // Most files first
+	Unowned int            // Files no CODEOWNERS rule assigns an owner
+	Source  string         // CODEOWNERS file read, e.g. ".github/CODEOWNERS"
+}
+
+// OwnerSummary is one owner's share of the included files. A file with
+// several owners counts toward each.
+type OwnerSummary struct {
+	Owner  string // User, team, or email, e.g. "@org/team"
+	Files  int
+	Tokens int
+}
+
+// Dependency is a package the project manifest depends on.
+type Dependency struct {
+	Name    string // e.g. "github.com/spf13/pflag", "express" or "org.junit:junit"
+	Version string // Version or constraint as written in the manifest, e.g. "v1.0.5" or "^4.17.1"
+	Scope   string // "direct", "dev" (development, build or test only) or "indirect"
+}
+
+// Framework is a framework, build tool, or language detected in the project.
+// Metadata.Frameworks lists them highest priority first: framework-specific
+// types (Next.js, Django, Angular) at 100, build tools (Vite, Flask) at 90,
+// languages (Go, Rust) at 80, and generic ones (Python, Node.js) below.
+type Framework struct {
+	Name        string // Short name, e.g. "nextjs"
+	Description string // Display name, e.g. "Next.js"
+	Priority    int
 }
 
 // FileInfo represents a single file and its contents.
@@ -85,14 +294,132 @@ type FileInfo struct {
 	Content    string
 	Tokens     int
 	Truncation *TruncationInfo
+
+	// AssociatedTests lists test files that cover this file (see WithTestAssociations)
+	AssociatedTests []string
+
+	// Redactions counts the secrets replaced in this file per rule (see WithRedaction)
+	Redactions map[string]int
+
+	// Compaction is the WithCompact mode that changed Content, which is then
+	// not byte-exact; empty for unchanged files
+	Compaction CompactMode
+
+	// Annotations are the notes attached to this file (see WithAnnotations)
+	Annotations []string
+
+	// Owners are the CODEOWNERS owners of this file (see WithOwners)
+	Owners []string
+
+	// License is the SPDX expression of this file's license header (see
+	// WithLicenseScan)
+	License string
+
+	// External marks a file from outside the extracted directory (see
+	// WithExtraFiles); Path is then relative to the directory, or absolute
+	External bool
+
+	// Sample describes a data file cut down to its first rows (see
+	// WithDataSampling); nil for files kept whole
+	Sample *SampleInfo
+
+	// Hash is the hex SHA-256 of the file as read from disk, before any
+	// transform changed Content
+	Hash string
+}
+
+// CommitInfo describes a single commit from the git history.
+type CommitInfo struct {
+	Hash    string
+	Date    string // YYYY-MM-DD
+	Subject string
+	Body    string
+}
+
+// DependencyInfo summarizes imports stripped from the included files.
+type DependencyInfo struct {
+	Imports  map[string][]string // Imports per file path
+	Packages []string            // Unique imported modules across all files, sorted
+}
+
+// SkippedFileInfo is a stub for a file that exists but whose content isn't included.
+type SkippedFileInfo struct {
+	Path   string
+	Size   int64  // Bytes on disk
+	Kind   string // "image", "archive", "font", "document", ..., or "binary"/"text"
+	Reason string // "binary", "too-large", or "generated"
+}
+
+// Issue is a TODO, FIXME or HACK comment in an included file.
+type Issue struct {
+	Path string
+	Line int    // 1-based line in the file's Content
+	Kind string // "TODO", "FIXME" or "HACK"
+	Text string // The comment after the marker, e.g. "handle retries"
+}
+
+// MetricsInfo holds static metrics of the included source files.
+type MetricsInfo struct {
+	Files     []FileMetrics // Most complex first
+	Functions int           // Functions across all files
+}
+
+// FileMetrics is the size and complexity of one source file. Complexity is
+// cyclomatic: one per function plus one per branch, exact for Go and
+// estimated from branch keywords for other languages.
+type FileMetrics struct {
+	Path          string
+	Lines         int
+	Functions     int
+	Complexity    int    // Sum over the file's functions
+	MaxComplexity int    // Of the most complex function
+	MaxFunction   string // Name of the most complex function, e.g. "Server.Handle"
+}
+
+// RedactionInfo summarizes the secrets replaced across the included files.
+type RedactionInfo struct {
+	Total int            // Redactions across all files
+	Files int            // Files with at least one redaction
+	Rules map[string]int // Redactions per detection rule, e.g. "aws-access-key"
+}
+
+// PackageInfo describes a Go package or JS/TS module directory.
+type PackageInfo struct {
+	Name    string   // Package name (Go package clause, package.json name, or directory name)
+	Path    string   // Directory relative to the project root ("." for the root)
+	Files   []string // Included files belonging to the package
+	Imports []string // Paths of other packages in the project that this one imports
+}
+
+// WorkspaceInfo describes the members of a monorepo workspace.
+type WorkspaceInfo struct {
+	Manifests []string           // Workspace manifests read, e.g. "go.work" or "pnpm-workspace.yaml"
+	Packages  []WorkspacePackage // Sorted by path
+}
+
+// WorkspacePackage is one member of a workspace.
+type WorkspacePackage struct {
+	Name      string   // Go module path, or package.json, Cargo.toml or project.json name
+	Path      string   // Directory relative to the project root
+	Ecosystem string   // "go", "npm", "cargo" or "nx"
+	DependsOn []string // Names of the members it depends on, sorted
+	Files     int      // Included files under Path
+	Tokens    int      // Tokens of those files
 }
 
 // TruncationInfo describes how a file was truncated.
 type TruncationInfo struct {
-	Mode           string
+	Mode           string // e.g. "head:300", "head:200,tail:100", "signatures:40", or "symbols:ParseConfig" for WithSnippets
 	OriginalTokens int
 }
 
+// SampleInfo describes a sampled CSV, TSV or JSON Lines file.
+type SampleInfo struct {
+	Rows      int // Rows kept after the header
+	TotalRows int // Rows in the whole file, not counting the header
+	Columns   int // Header columns, or keys of the first JSON Lines object
+}
+
 // FileStatistics contains statistics about the processed files.
 type FileStatistics struct {
 	TotalFiles   int
@@ -123,11 +450,53 @@ type FilterConfig struct {
 //	markdownOutput, _ := result.As(promptext.FormatMarkdown)
 //	jsonlOutput, _ := result.As(promptext.FormatJSONL)
 func (r *Result) As(format Format) (string, error) {
-	formatter, err := GetFormatter(string(format))
+	formatter, err := formatterFor(format, r.markdown, r.template)
 	if err != nil {
 		return "", err
 	}
-	return formatter.Format(r.ProjectOutput)
+	ctx := FormatContext{
+		Output:           r.ProjectOutput,
+		ExcludedFileList: r.ExcludedFileList,
+		SelectionReport:  r.SelectionReport,
+		TokenCount:       r.TokenCount,
+		TotalTokens:      r.TotalTokens,
+		Tokenizer:        Tokenizer(r.tokenizer),
+		Format:           format,
+	}
+	if ctx.Tokenizer == "" {
+		ctx.Tokenizer = TokenizerCL100K
+	}
+	ctx.TokenBudget = r.tokenBudget()
+	return formatWith(formatter, &ctx)
+}
+
+// WriteJSONL streams the result to w in the built-in JSONL format, writing
+// each record as it is encoded instead of building the whole output first,
+// so log and ingest pipelines can consume huge projects line by line. Every
+// line is a JSON object with a "type": a metadata record first, then one
+// "file" record per file in path order and a closing "summary" record with
+// the totals. Schema(FormatJSONL) describes each record type.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithFormat(promptext.FormatPTX))
+//	if err := result.WriteJSONL(os.Stdout); err != nil {
+//	    log.Fatal(err)
+//	}
+func (r *Result) WriteJSONL(w io.Writer) error {
+	project := toInternalProjectOutput(r.ProjectOutput)
+	if project == nil {
+		project = &format.ProjectOutput{}
+	}
+	return (&format.JSONLFormatter{}).Write(w, project)
+}
+
+// tokenBudget returns the token budget the extraction ran with, 0 when unlimited
+func (r *Result) tokenBudget() int {
+	if r.config != nil {
+		return r.config.MaxTokens
+	}
+	return r.budget
 }
 
 // fromInternalProcessResult converts internal processor.ProcessResult to public Result
@@ -142,19 +511,29 @@ func fromInternalProcessResult(internal *processor.ProcessResult, formattedOutpu
 		TokenCount:       internal.TokenCount,
 		TotalTokens:      internal.TotalTokens,
 		ExcludedFiles:    internal.ExcludedFiles,
-		ExcludedFileList: make([]ExcludedFileInfo, len(internal.ExcludedFileList)),
+		ExcludedFileList: fromInternalExcludedFiles(internal.ExcludedFileList),
 	}
 
-	for i, excluded := range internal.ExcludedFileList {
-		result.ExcludedFileList[i] = ExcludedFileInfo{
-			Path:   excluded.Path,
-			Tokens: excluded.Tokens,
-		}
+	if result.ProjectOutput != nil {
+		result.SelectionReport = result.ProjectOutput.Selection
 	}
 
 	return result
 }
 
+// fromInternalExcludedFiles converts the processor's excluded files to public ones
+func fromInternalExcludedFiles(internal []processor.ExcludedFileInfo) []ExcludedFileInfo {
+	excluded := make([]ExcludedFileInfo, len(internal))
+	for i, file := range internal {
+		excluded[i] = ExcludedFileInfo{
+			Path:   file.Path,
+			Tokens: file.Tokens,
+			Reason: file.Reason,
+		}
+	}
+	return excluded
+}
+
 // fromInternalProjectOutput converts internal format.ProjectOutput to public ProjectOutput
 func fromInternalProjectOutput(internal *format.ProjectOutput) *ProjectOutput {
 	if internal == nil {
@@ -180,26 +559,45 @@ func fromInternalProjectOutput(internal *format.ProjectOutput) *ProjectOutput {
 	// Convert Metadata
 	if internal.Metadata != nil {
 		output.Metadata = &Metadata{
-			Language:     internal.Metadata.Language,
-			Version:      internal.Metadata.Version,
-			Dependencies: internal.Metadata.Dependencies,
+			Language: internal.Metadata.Language,
+			Version:  internal.Metadata.Version,
+		}
+		for _, dep := range internal.Metadata.Dependencies {
+			output.Metadata.Dependencies = append(output.Metadata.Dependencies, Dependency(dep))
+		}
+		for _, framework := range internal.Metadata.Frameworks {
+			output.Metadata.Frameworks = append(output.Metadata.Frameworks, Framework(framework))
+		}
+		for _, lang := range internal.Metadata.Languages {
+			output.Metadata.Languages = append(output.Metadata.Languages, LanguageShare(lang))
+		}
+		if owners := internal.Metadata.Owners; owners != nil {
+			output.Metadata.Owners = &OwnerInfo{Unowned: owners.Unowned, Source: owners.Source}
+			for _, owner := range owners.Owners {
+				output.Metadata.Owners.Owners = append(output.Metadata.Owners.Owners, OwnerSummary(owner))
+			}
+		}
+		if licenses := internal.Metadata.Licenses; licenses != nil {
+			output.Metadata.Licenses = &LicenseInfo{Copyrights: licenses.Copyrights}
+			for _, license := range licenses.Licenses {
+				output.Metadata.Licenses.Licenses = append(output.Metadata.Licenses.Licenses, LicenseSummary(license))
+			}
+			for _, flag := range licenses.Flagged {
+				output.Metadata.Licenses.Flagged = append(output.Metadata.Licenses.Flagged, LicenseFlag(flag))
+			}
+		}
+		if audit := internal.Metadata.Audit; audit != nil {
+			output.Metadata.Audit = &AuditInfo{Source: audit.Source, Checked: audit.Checked, Vulnerabilities: []Vulnerability{}}
+			for _, vuln := range audit.Vulnerabilities {
+				output.Metadata.Audit.Vulnerabilities = append(output.Metadata.Audit.Vulnerabilities, Vulnerability(vuln))
+			}
 		}
 	}
 
 	// Convert Files
 	output.Files = make([]FileInfo, len(internal.Files))
 	for i, file := range internal.Files {
-		output.Files[i] = FileInfo{
-			Path:    file.Path,
-			Content: file.Content,
-			Tokens:  file.Tokens,
-		}
-		if file.Truncation != nil {
-			output.Files[i].Truncation = &TruncationInfo{
-				Mode:           file.Truncation.Mode,
-				OriginalTokens: file.Truncation.OriginalTokens,
-			}
-		}
+		output.Files[i] = fromInternalFileInfo(file)
 	}
 
 	// Convert FileStats
@@ -228,9 +626,126 @@ func fromInternalProjectOutput(internal *format.ProjectOutput) *ProjectOutput {
 		}
 	}
 
+	// Convert RecentCommits
+	for _, commit := range internal.RecentCommits {
+		output.RecentCommits = append(output.RecentCommits, CommitInfo{
+			Hash:    commit.Hash,
+			Date:    commit.Date,
+			Subject: commit.Subject,
+			Body:    commit.Body,
+		})
+	}
+
+	// Convert Packages
+	for _, pkg := range internal.Packages {
+		output.Packages = append(output.Packages, PackageInfo{
+			Name:    pkg.Name,
+			Path:    pkg.Path,
+			Files:   pkg.Files,
+			Imports: pkg.Imports,
+		})
+	}
+
+	if internal.Workspace != nil {
+		output.Workspace = &WorkspaceInfo{Manifests: internal.Workspace.Manifests}
+		for _, pkg := range internal.Workspace.Packages {
+			output.Workspace.Packages = append(output.Workspace.Packages, WorkspacePackage(pkg))
+		}
+	}
+
+	// Convert Dependencies
+	if internal.Dependencies != nil {
+		output.Dependencies = &DependencyInfo{
+			Imports:  internal.Dependencies.Imports,
+			Packages: internal.Dependencies.Packages,
+		}
+	}
+
+	// Convert SkippedFiles
+	for _, file := range internal.SkippedFiles {
+		output.SkippedFiles = append(output.SkippedFiles, SkippedFileInfo{
+			Path:   file.Path,
+			Size:   file.Size,
+			Kind:   file.Kind,
+			Reason: file.Reason,
+		})
+	}
+
+	for _, issue := range internal.Issues {
+		output.Issues = append(output.Issues, Issue(issue))
+	}
+
+	if internal.Metrics != nil {
+		output.Metrics = &MetricsInfo{Functions: internal.Metrics.Functions}
+		for _, file := range internal.Metrics.Files {
+			output.Metrics.Files = append(output.Metrics.Files, FileMetrics(file))
+		}
+	}
+
+	// Convert Redactions
+	if internal.Redactions != nil {
+		output.Redactions = &RedactionInfo{
+			Total: internal.Redactions.Total,
+			Files: internal.Redactions.Files,
+			Rules: internal.Redactions.Rules,
+		}
+	}
+
+	// Convert Split
+	if internal.Split != nil {
+		output.Split = &SplitInfo{
+			Part:  internal.Split.Part,
+			Total: internal.Split.Total,
+			Parts: internal.Split.Parts,
+		}
+	}
+
+	if internal.Integrity != nil {
+		output.Integrity = &IntegrityInfo{Algorithm: internal.Integrity.Algorithm, Content: internal.Integrity.Content}
+	}
+
+	// Convert Selection
+	if internal.Selection != nil {
+		output.Selection = &SelectionReport{
+			Threshold: internal.Selection.Threshold,
+			Files:     make([]SelectionEntry, len(internal.Selection.Files)),
+		}
+		for i, entry := range internal.Selection.Files {
+			output.Selection.Files[i] = SelectionEntry(entry)
+		}
+	}
+
 	return output
 }
 
+// fromInternalFileInfo converts internal format.FileInfo to public FileInfo
+func fromInternalFileInfo(file format.FileInfo) FileInfo {
+	info := FileInfo{
+		Path:            file.Path,
+		Content:         file.Content,
+		Tokens:          file.Tokens,
+		AssociatedTests: file.AssociatedTests,
+		Redactions:      file.Redactions,
+		Compaction:      CompactMode(file.Compaction),
+		Annotations:     file.Annotations,
+		Owners:          file.Owners,
+		License:         file.License,
+		External:        file.External,
+		Hash:            file.Hash,
+	}
+	if file.Truncation != nil {
+		info.Truncation = &TruncationInfo{
+			Mode:           file.Truncation.Mode,
+			OriginalTokens: file.Truncation.OriginalTokens,
+		}
+	}
+	if file.Sample != nil {
+		sample := SampleInfo(*file.Sample)
+		info.Sample = &sample
+	}
+	return info
+}
+
 // fromInternalDirectoryNode converts internal format.DirectoryNode to public DirectoryNode
 func fromInternalDirectoryNode(internal *format.DirectoryNode) *DirectoryNode {
 	if internal == nil {
@@ -0,0 +1,103 @@
+package promptext
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/1broseidon/promptext/internal/longpath"
+)
+
+// resultFileVersion is the version of the file Result.Save writes. It changes
+// only when a field is removed or changes meaning; LoadResult reads files of
+// this version and earlier.
+const resultFileVersion = 1
+
+// savedResult is the file Result.Save writes: the result's exported fields
+// plus the extraction settings As, Chunks and Summary reuse
+type savedResult struct {
+	FileVersion    int              `json:"promptext_result"`
+	LibraryVersion string           `json:"library_version"`
+	Format         Format           `json:"format"`
+	Markdown       *MarkdownOptions `json:"markdown,omitempty"`
+	Tokenizer      string           `json:"tokenizer,omitempty"`
+	TokenBudget    int              `json:"token_budget,omitempty"`
+	DurationMS     int64            `json:"duration_ms"`
+	Result         *Result          `json:"result"`
+}
+
+// Save writes the result to path as versioned JSON: the structured project
+// output, exclusions, selection report and warnings along with the formatted
+// text, the format, tokenizer and token budget. LoadResult reads it back, so
+// a pipeline can extract once and later re-format with As, re-chunk with
+// Chunks or build prompts without reading the project again.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithTokenBudget(50000))
+//	if err := result.Save("context.result.json"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (r *Result) Save(path string) error {
+	saved := savedResult{
+		FileVersion:    resultFileVersion,
+		LibraryVersion: Version,
+		Format:         r.format,
+		Tokenizer:      r.tokenizer,
+		TokenBudget:    r.tokenBudget(),
+		DurationMS:     r.duration.Milliseconds(),
+		Result:         r,
+	}
+	if r.markdown != (MarkdownOptions{}) {
+		saved.Markdown = &r.markdown
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return fmt.Errorf("encoding result: %w", err)
+	}
+	return os.WriteFile(longpath.Fix(path), data, 0644)
+}
+
+// LoadResult reads a result written by Result.Save. The loaded result has
+// everything the saved one had except what needs the project on disk:
+// Explain reports only what the result records, and RenderPrompt uses the
+// built-in templates.
+//
+// Returns ErrUnsupportedResultFile if path holds no saved result or one
+// written by a newer, incompatible version of promptext.
+//
+// Example:
+//
+//	result, err := promptext.LoadResult("context.result.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	markdown, _ := result.As(promptext.FormatMarkdown)
+func LoadResult(path string) (*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var saved savedResult
+	if err := json.Unmarshal(data, &saved); err != nil || saved.FileVersion == 0 || saved.Result == nil {
+		return nil, fmt.Errorf("%w: %s is not a saved promptext result", ErrUnsupportedResultFile, path)
+	}
+	if saved.FileVersion > resultFileVersion {
+		return nil, fmt.Errorf("%w: %s has version %d, written by promptext %s; this version reads up to %d",
+			ErrUnsupportedResultFile, path, saved.FileVersion, saved.LibraryVersion, resultFileVersion)
+	}
+
+	result := saved.Result
+	result.format = saved.Format
+	if saved.Markdown != nil {
+		result.markdown = *saved.Markdown
+	}
+	result.tokenizer = saved.Tokenizer
+	result.budget = saved.TokenBudget
+	result.duration = time.Duration(saved.DurationMS) * time.Millisecond
+	if result.ProjectOutput != nil {
+		result.SelectionReport = result.ProjectOutput.Selection
+	}
+	return result, nil
+}
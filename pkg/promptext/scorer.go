@@ -0,0 +1,55 @@
+package promptext
+
+import "github.com/1broseidon/promptext/internal/relevance"
+
+// RelevanceThreshold is the score at which a file counts as highly relevant
+// and is ranked ahead of ordinary files. Custom scorers should use the same
+// scale: 0 means unrelated, and anything at or above the threshold is a
+// strong match.
+var RelevanceThreshold = relevance.GetRelevanceThreshold()
+
+// ScoreInput is one file handed to a Scorer
+type ScoreInput struct {
+	Path    string // Path relative to the extracted directory
+	Content string // File content after filtering and redaction
+}
+
+// Scorer ranks files by relevance in place of keyword scoring, for example by
+// embedding similarity to a query (see the embedding sub-package). Score is
+// called with every candidate file and must return one score per file, in the
+// same order. Files scored 0 are excluded; files scored at or above
+// RelevanceThreshold rank first when a token budget forces a choice.
+type Scorer interface {
+	Score(files []ScoreInput) ([]float64, error)
+}
+
+// WithCustomScorer replaces keyword relevance with a caller-supplied scorer.
+// It takes precedence over WithRelevance, and an error from the scorer fails
+// the extraction.
+//
+// Example:
+//
+//	scorer := embedding.New("how are tokens counted?", embedding.NewOllamaEmbedder("", "nomic-embed-text"))
+//	result, err := promptext.Extract(".",
+//	    promptext.WithCustomScorer(scorer),
+//	    promptext.WithTokenBudget(8000),
+//	)
+func WithCustomScorer(s Scorer) Option {
+	return func(c *config) {
+		c.scorer = s
+	}
+}
+
+// batchScorer adapts a public Scorer to the processor's relevance hook
+type batchScorer struct {
+	scorer Scorer
+}
+
+// Score implements relevance.BatchScorer
+func (b batchScorer) Score(files []relevance.FileContent) ([]float64, error) {
+	inputs := make([]ScoreInput, len(files))
+	for i, file := range files {
+		inputs[i] = ScoreInput{Path: file.Path, Content: file.Content}
+	}
+	return b.scorer.Score(inputs)
+}
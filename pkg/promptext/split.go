@@ -0,0 +1,96 @@
+package promptext
+
+import (
+	"fmt"
+
+	"github.com/1broseidon/promptext/internal/processor"
+)
+
+// ExtractSplit extracts a directory into several results, each rendered within
+// the budget set by WithSplitTokens. This is a convenience function that
+// creates a temporary Extractor.
+//
+// Every part repeats the project manifest (directory tree, metadata, git info)
+// and its ProjectOutput.Split lists the files held by the other parts, so each
+// part can be read on its own. Token budgets, relevance and the other options
+// apply before splitting. Without WithSplitTokens the result is a single part.
+//
+// Example:
+//
+//	parts, err := promptext.ExtractSplit(".", promptext.WithSplitTokens(100000))
+//	for _, part := range parts {
+//	    fmt.Printf("part %d: %d tokens\n", part.ProjectOutput.Split.Part, part.TokenCount)
+//	}
+func ExtractSplit(dir string, opts ...Option) ([]Result, error) {
+	return NewExtractor(opts...).ExtractSplit(dir)
+}
+
+// ExtractSplit extracts a directory into several results.
+// See the package-level ExtractSplit for how parts are formed.
+func (e *Extractor) ExtractSplit(dir string) ([]Result, error) {
+	defer e.useLogger()()
+	procConfig, formatter, warnings, err := e.prepare(dir)
+	if err != nil {
+		return nil, err
+	}
+	if e.config.splitTokens <= 0 {
+		result, err := e.extract(procConfig, formatter, warnings)
+		if err != nil {
+			return nil, err
+		}
+		return []Result{*result}, nil
+	}
+
+	procResult, err := processor.ProcessDirectory(procConfig, e.config.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error processing directory: %w", err)
+	}
+	if len(procResult.ProjectOutput.Files) == 0 {
+		return nil, ErrNoFilesMatched
+	}
+
+	parts, err := processor.SplitOutput(procResult.ProjectOutput, e.config.splitTokens, procConfig,
+		renderWith(formatter, newFormatContext(procConfig, e.config.format, procResult)))
+	if err != nil {
+		return nil, &FormatError{
+			Format: string(e.config.format),
+			Err:    err,
+		}
+	}
+
+	results := make([]Result, len(parts))
+	for i, part := range parts {
+		partWarnings := append(append([]string(nil), warnings...), procResult.Warnings...)
+		if part.Tokens > e.config.splitTokens {
+			partWarnings = append(partWarnings, splitOverBudgetWarning(i+1, part, e.config.splitTokens))
+		}
+
+		partResult := *procResult
+		partResult.ProjectOutput = part.Output
+		partResult.TokenCount = 0
+		for _, file := range part.Output.Files {
+			partResult.TokenCount += file.Tokens
+		}
+		results[i] = *fromInternalProcessResult(&partResult, part.Rendered)
+		results[i].Warnings = partWarnings
+		results[i].tokenizer = procConfig.Tokenizer
+		results[i].config = &procConfig
+		results[i].format = e.config.format
+		results[i].markdown = e.config.markdown
+		results[i].template = e.config.template
+		results[i].compression = e.config.compression
+		results[i].encoding = e.config.encoding
+	}
+	return results, nil
+}
+
+// splitOverBudgetWarning describes a part rendered over the split budget: its
+// size, the limit, and the file too large to split into a part that fits
+func splitOverBudgetWarning(n int, part processor.SplitPart, limit int) string {
+	warning := fmt.Sprintf("part %d is %d tokens, over the %d-token split limit", n, part.Tokens, limit)
+	if len(part.Output.Files) == 1 {
+		file := part.Output.Files[0]
+		return fmt.Sprintf("%s: %s (%d tokens) could not be split", warning, file.Path, file.Tokens)
+	}
+	return fmt.Sprintf("%s: repacking its %d files did not bring it under", warning, len(part.Output.Files))
+}
@@ -0,0 +1,113 @@
+package promptext
+
+import (
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/1broseidon/promptext/internal/processor"
+)
+
+// Stats breaks the tokens of the included files down by top-level directory
+// and by file extension (see Result.Stats), ready to encode as JSON.
+type Stats struct {
+	Files       int          `json:"files"`
+	Tokens      int          `json:"tokens"`
+	Directories []StatsGroup `json:"directories"` // Most tokens first
+	Extensions  []StatsGroup `json:"extensions"`  // Most tokens first
+
+	// ExcludedFiles and ExcludedTokens count the files relevance, the
+	// token budget or the output cap left out, which the groups don't include
+	ExcludedFiles  int `json:"excluded_files"`
+	ExcludedTokens int `json:"excluded_tokens"`
+}
+
+// StatsGroup is the share of one directory or extension in Stats
+type StatsGroup struct {
+	// Name is a top-level directory with a trailing slash, "." for files in
+	// the root, or "(external)"; an extension with its dot, or "(none)"
+	Name    string  `json:"name"`
+	Files   int     `json:"files"`
+	Tokens  int     `json:"tokens"`
+	Percent float64 `json:"percent"` // Share of Stats.Tokens, to one decimal place
+}
+
+// Stats returns where the tokens of the included files go: their totals per
+// top-level directory and per extension with each one's share, so the
+// directories and file types worth excluding stand out.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".")
+//	for _, dir := range result.Stats().Directories {
+//	    fmt.Printf("%-20s %6d %5.1f%%\n", dir.Name, dir.Tokens, dir.Percent)
+//	}
+func (r *Result) Stats() Stats {
+	stats := Stats{Directories: []StatsGroup{}, Extensions: []StatsGroup{}}
+	if r.ProjectOutput == nil {
+		return stats
+	}
+	dirs := make(map[string]*StatsGroup)
+	exts := make(map[string]*StatsGroup)
+	add := func(groups map[string]*StatsGroup, name string, tokens int) {
+		group, ok := groups[name]
+		if !ok {
+			group = &StatsGroup{Name: name}
+			groups[name] = group
+		}
+		group.Files++
+		group.Tokens += tokens
+	}
+	for _, file := range r.ProjectOutput.Files {
+		stats.Files++
+		stats.Tokens += file.Tokens
+		add(dirs, topLevelDir(file), file.Tokens)
+		ext := strings.ToLower(path.Ext(file.Path))
+		if ext == "" {
+			ext = "(none)"
+		}
+		add(exts, ext, file.Tokens)
+	}
+	for _, excluded := range r.ExcludedFileList {
+		stats.ExcludedFiles++
+		stats.ExcludedTokens += excluded.Tokens
+	}
+	stats.Directories = statsGroups(dirs, stats.Tokens)
+	stats.Extensions = statsGroups(exts, stats.Tokens)
+	return stats
+}
+
+// topLevelDir returns the Stats directory of file
+func topLevelDir(file FileInfo) string {
+	if file.External {
+		return processor.ExternalDir
+	}
+	if dir, _, ok := strings.Cut(file.Path, "/"); ok {
+		return dir + "/"
+	}
+	return "."
+}
+
+// statsGroups lists groups by tokens, then files, then name, with their
+// shares of total
+func statsGroups(groups map[string]*StatsGroup, total int) []StatsGroup {
+	list := make([]StatsGroup, 0, len(groups))
+	for _, group := range groups {
+		if total > 0 {
+			group.Percent = math.Round(float64(group.Tokens)*1000/float64(total)) / 10
+		}
+		list = append(list, *group)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Tokens != b.Tokens {
+			return a.Tokens > b.Tokens
+		}
+		if a.Files != b.Files {
+			return a.Files > b.Files
+		}
+		return a.Name < b.Name
+	})
+	return list
+}
@@ -0,0 +1,118 @@
+package promptext
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/processor"
+)
+
+// FileResult is one item of an ExtractStream: either a processed file or the
+// error that ended the stream.
+type FileResult struct {
+	File FileInfo
+	Err  error
+}
+
+// ExtractStream processes dir like Extract but emits files one at a time on
+// the returned channel instead of building the whole ProjectOutput in memory.
+// Use it for very large repositories where holding every file at once is not
+// an option.
+//
+// Files are emitted in lexical walk order after filtering (extensions,
+// excludes, includes, .gitignore, default rules) and optional transforms such
+// as WithStripImports. Options that need the whole file set up front
+// (WithRelevance, WithCustomScorer, WithTokenBudget, WithMaxOutputTokens,
+// WithDropSizeOutliers, WithTestAssociations, WithExplainSelection,
+// WithTreeOnly) return ErrStreamingUnsupported.
+//
+// The channel is closed after the last file; a walk failure is delivered as a
+// final FileResult with Err set. Callers must drain the channel, or use
+// Extractor.ExtractStreamContext to stop early.
+//
+// Example:
+//
+//	files, err := promptext.ExtractStream(".", promptext.WithExtensions(".go"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for f := range files {
+//	    if f.Err != nil {
+//	        log.Fatal(f.Err)
+//	    }
+//	    fmt.Printf("%s: %d tokens\n", f.File.Path, f.File.Tokens)
+//	}
+func ExtractStream(dir string, opts ...Option) (<-chan FileResult, error) {
+	return NewExtractor(opts...).ExtractStream(dir)
+}
+
+// ExtractStream streams the files of dir one at a time; see the package-level
+// ExtractStream for details.
+func (e *Extractor) ExtractStream(dir string) (<-chan FileResult, error) {
+	return e.ExtractStreamContext(context.Background(), dir)
+}
+
+// ExtractStreamContext is like ExtractStream, but stops walking and closes the
+// channel when ctx is canceled. The cancellation is delivered as a final
+// FileResult carrying ctx.Err() if the consumer is still reading.
+func (e *Extractor) ExtractStreamContext(ctx context.Context, dir string) (<-chan FileResult, error) {
+	if err := e.checkStreamable(); err != nil {
+		return nil, err
+	}
+
+	restore := e.useLogger()
+	procConfig, _, _, err := e.prepare(dir)
+	if err != nil {
+		restore()
+		return nil, err
+	}
+
+	results := make(chan FileResult)
+	go func() {
+		defer close(results)
+		defer restore()
+		err := processor.StreamFiles(procConfig, func(file format.FileInfo) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			select {
+			case results <- FileResult{File: fromInternalFileInfo(file)}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		if err != nil {
+			select {
+			case results <- FileResult{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return results, nil
+}
+
+// checkStreamable rejects options that need every file before any can be emitted
+func (e *Extractor) checkStreamable() error {
+	checks := []struct {
+		option string
+		set    bool
+	}{
+		{"WithRelevance", e.config.relevanceKeywords != ""},
+		{"WithRelevanceExclude", len(e.config.relevanceExclude) > 0},
+		{"WithCustomScorer", e.config.scorer != nil},
+		{"WithTokenBudget", e.config.tokenBudget > 0},
+		{"WithMaxOutputTokens", e.config.maxOutputTokens > 0},
+		{"WithDropSizeOutliers", e.config.dropSizeOutliers > 0},
+		{"WithTestAssociations", e.config.associateTests},
+		{"WithExplainSelection", e.config.explainSelection},
+		{"WithTreeOnly", e.config.treeOnly},
+	}
+	for _, check := range checks {
+		if check.set {
+			return fmt.Errorf("%w: %s", ErrStreamingUnsupported, check.option)
+		}
+	}
+	return nil
+}
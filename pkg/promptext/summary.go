@@ -0,0 +1,71 @@
+package promptext
+
+import "github.com/1broseidon/promptext/internal/token"
+
+// Summary is a machine-readable account of an extraction (see Result.Summary),
+// written by the CLI's --summary-json. Field names are stable; new fields may
+// be added.
+type Summary struct {
+	Format         string        `json:"format"`
+	Tokenizer      string        `json:"tokenizer"`
+	FilesIncluded  int           `json:"files_included"`
+	FilesExcluded  int           `json:"files_excluded"`  // Dropped by relevance, budget, or output cap
+	FilesSkipped   int           `json:"files_skipped"`   // Binary, oversized, or generated files listed as stubs
+	FilesTruncated int           `json:"files_truncated"` // Included with shortened content
+	Tokens         int           `json:"tokens"`          // Tokens of the included files
+	TotalTokens    int           `json:"total_tokens"`    // Tokens if every candidate file had been included
+	TokenBudget    int           `json:"token_budget"`    // 0 when unlimited
+	Excluded       []SummaryFile `json:"excluded,omitempty"`
+	Warnings       []string      `json:"warnings,omitempty"`
+	DurationMS     int64         `json:"duration_ms"` // Time spent reading, selecting, and formatting files
+
+	// Output is where the formatted output went: "clipboard", "stdout", or a
+	// file path. Extraction doesn't write output, so it is left for the
+	// caller to set.
+	Output string `json:"output,omitempty"`
+}
+
+// SummaryFile is a file excluded from the output, in a Summary
+type SummaryFile struct {
+	Path   string `json:"path"`
+	Tokens int    `json:"tokens"`
+	Reason string `json:"reason"`
+}
+
+// Summary returns counts, token totals, and timing for the extraction, ready
+// to encode as JSON.
+//
+// Example:
+//
+//	result, _ := promptext.Extract(".", promptext.WithTokenBudget(8000))
+//	summary := result.Summary()
+//	summary.Output = "context.ptx"
+//	json.NewEncoder(os.Stdout).Encode(summary)
+func (r *Result) Summary() Summary {
+	summary := Summary{
+		Format:        string(r.format),
+		Tokenizer:     r.tokenizer,
+		FilesExcluded: r.ExcludedFiles,
+		Tokens:        r.TokenCount,
+		TotalTokens:   r.TotalTokens,
+		Warnings:      r.Warnings,
+		DurationMS:    r.duration.Milliseconds(),
+	}
+	if summary.Tokenizer == "" {
+		summary.Tokenizer = token.TokenizerCL100K
+	}
+	summary.TokenBudget = r.tokenBudget()
+	if r.ProjectOutput != nil {
+		summary.FilesIncluded = len(r.ProjectOutput.Files)
+		summary.FilesSkipped = len(r.ProjectOutput.SkippedFiles)
+		for _, file := range r.ProjectOutput.Files {
+			if file.Truncation != nil {
+				summary.FilesTruncated++
+			}
+		}
+	}
+	for _, excluded := range r.ExcludedFileList {
+		summary.Excluded = append(summary.Excluded, SummaryFile{Path: excluded.Path, Tokens: excluded.Tokens, Reason: excluded.Reason})
+	}
+	return summary
+}
@@ -0,0 +1,80 @@
+package promptext
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// TemplateFuncs returns the helper functions templates for WithTemplate can
+// call. Add them before parsing, so the template can refer to them:
+//
+//   - tokens TEXT: the estimated tokens of TEXT with the extraction's tokenizer
+//   - wrapCode PATH CONTENT: CONTENT in a Markdown code block tagged with the
+//     language of PATH, fenced so the content can't close it early
+//   - rel BASE PATH: PATH relative to the directory BASE, with forward
+//     slashes, or PATH unchanged when it has no such relative form
+//
+// Example:
+//
+//	tmpl := template.Must(template.New("context").Funcs(promptext.TemplateFuncs()).Parse(
+//	    "{{range .Files}}## {{.Path}} ({{tokens .Content}} tokens)\n{{wrapCode .Path .Content}}\n{{end}}"))
+func TemplateFuncs() template.FuncMap {
+	return templateFuncs(TokenizerCL100K)
+}
+
+// templateFuncs returns the template helpers, counting tokens with tokenizer
+func templateFuncs(tokenizer Tokenizer) template.FuncMap {
+	var counter *token.TokenCounter
+	return template.FuncMap{
+		"tokens": func(text string) (int, error) {
+			if counter == nil {
+				var err error
+				if counter, err = token.NewTokenCounterFor(string(tokenizer)); err != nil {
+					return 0, err
+				}
+			}
+			return counter.EstimateTokens(text), nil
+		},
+		"wrapCode": format.CodeBlock,
+		"rel": func(base, path string) string {
+			rel, err := filepath.Rel(filepath.FromSlash(base), filepath.FromSlash(path))
+			if err != nil {
+				return path
+			}
+			return filepath.ToSlash(rel)
+		},
+	}
+}
+
+// templateFormatter renders the project output through a user-supplied
+// template, with the project output as dot
+type templateFormatter struct {
+	tmpl *template.Template
+}
+
+func (t *templateFormatter) Format(output *ProjectOutput) (string, error) {
+	return t.render(output, TokenizerCL100K)
+}
+
+func (t *templateFormatter) FormatWithContext(ctx *FormatContext) (string, error) {
+	return t.render(ctx.Output, ctx.Tokenizer)
+}
+
+// render executes a clone of the template, so its helpers count with
+// tokenizer without changing the caller's template
+func (t *templateFormatter) render(output *ProjectOutput, tokenizer Tokenizer) (string, error) {
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", fmt.Errorf("template %s: %w", t.tmpl.Name(), err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Funcs(templateFuncs(tokenizer)).Execute(&sb, output); err != nil {
+		return "", fmt.Errorf("template %s: %w", t.tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
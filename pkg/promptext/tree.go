@@ -0,0 +1,66 @@
+package promptext
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/1broseidon/promptext/internal/format"
+	"github.com/1broseidon/promptext/internal/processor"
+	"github.com/1broseidon/promptext/internal/token"
+)
+
+// TreeNode is a directory or file of a tree-only extraction (see
+// WithTreeOnly). Directories carry the number of files beneath them and the
+// token total of those files; a file carries its own tokens.
+type TreeNode struct {
+	Name     string
+	Path     string // Slash-separated path relative to the project root ("." for the root)
+	Type     string // "dir" or "file"
+	Files    int    // Files at or below this node
+	Tokens   int    // Token total of those files
+	Children []*TreeNode
+}
+
+// extractTree runs a tree-only extraction: the files are selected and
+// counted as usual, but the output is the annotated directory tree
+func (e *Extractor) extractTree(procConfig processor.Config, warnings []string) (*Result, error) {
+	// Every matching file is listed, whatever a budget would have kept
+	procConfig.MaxTokens = 0
+
+	procResult, err := processor.ProcessDirectory(procConfig, e.config.verbose)
+	if err != nil {
+		return nil, fmt.Errorf("error processing directory: %w", err)
+	}
+	if len(procResult.ProjectOutput.Files) == 0 {
+		return nil, ErrNoFilesMatched
+	}
+
+	rollup := processor.BuildTreeRollup(filepath.Base(procConfig.DirPath), procResult.ProjectOutput.Files)
+	rendered := processor.RenderTreeRollup(rollup)
+
+	result := fromInternalProcessResult(procResult, rendered)
+	result.Tree = fromInternalTreeRollup(rollup)
+	result.TotalTokens = rollup.Tokens
+	if counter, err := token.NewTokenCounterFor(procConfig.Tokenizer); err == nil {
+		result.TokenCount = counter.EstimateTokens(rendered)
+	}
+	result.Warnings = append(warnings, procResult.Warnings...)
+	result.tokenizer = procConfig.Tokenizer
+	result.config = &procConfig
+	return result, nil
+}
+
+// fromInternalTreeRollup converts internal format.TreeRollup to public TreeNode
+func fromInternalTreeRollup(internal *format.TreeRollup) *TreeNode {
+	node := &TreeNode{
+		Name:   internal.Name,
+		Path:   internal.Path,
+		Type:   internal.Type,
+		Files:  internal.Files,
+		Tokens: internal.Tokens,
+	}
+	for _, child := range internal.Children {
+		node.Children = append(node.Children, fromInternalTreeRollup(child))
+	}
+	return node
+}
@@ -0,0 +1,98 @@
+package promptext
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/1broseidon/promptext/internal/format"
+)
+
+// Verification compares the files a context was generated from with the
+// files on disk now. Files added to the project since aren't reported, as
+// the context doesn't list them.
+type Verification struct {
+	// Format is the format the context was read as: ptx, jsonl or json
+	Format Format
+
+	// Content is the content hash the context records
+	Content string
+
+	// Unchanged, Changed and Missing list the context's files by state on
+	// disk, sorted by path
+	Unchanged []string
+	Changed   []string
+	Missing   []string
+
+	// Tampered is set when the file hashes the context lists don't add up
+	// to its content hash, so the manifest itself was edited
+	Tampered bool
+}
+
+// OK reports whether every file of the context is unchanged and the
+// manifest is intact.
+func (v *Verification) OK() bool {
+	return len(v.Changed) == 0 && len(v.Missing) == 0 && !v.Tampered
+}
+
+// Verify re-hashes the files listed in the context at contextPath, written
+// in the PTX, JSONL, JSON or XML format with WithHashes, and reports which of
+// them changed in dir since. A gzipped context is read uncompressed. Paths in the context are resolved against dir;
+// those of external files that are absolute are read as they are.
+//
+// Returns ErrNoHashes if the context records no hashes.
+//
+// Example:
+//
+//	v, err := promptext.Verify("context.ptx", ".")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, path := range v.Changed {
+//	    fmt.Println("changed:", path)
+//	}
+func Verify(contextPath, dir string) (*Verification, error) {
+	data, err := os.ReadFile(contextPath)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = gunzipOutput(data); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", contextPath, err)
+	}
+	manifest, err := format.ReadIntegrity(data)
+	if errors.Is(err, format.ErrNoIntegrity) {
+		return nil, fmt.Errorf("%w: %s", ErrNoHashes, contextPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", contextPath, err)
+	}
+
+	v := &Verification{
+		Format:   Format(manifest.Format),
+		Content:  manifest.Content,
+		Tampered: format.ContentHash(manifest.Files) != manifest.Content,
+	}
+	for path, hash := range manifest.Files {
+		fullPath := path
+		if !filepath.IsAbs(path) {
+			fullPath = filepath.Join(dir, filepath.FromSlash(path))
+		}
+		content, err := os.ReadFile(fullPath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			v.Missing = append(v.Missing, path)
+		case err != nil:
+			return nil, err
+		case format.FileHash(content) != hash:
+			v.Changed = append(v.Changed, path)
+		default:
+			v.Unchanged = append(v.Unchanged, path)
+		}
+	}
+	sort.Strings(v.Unchanged)
+	sort.Strings(v.Changed)
+	sort.Strings(v.Missing)
+	return v, nil
+}